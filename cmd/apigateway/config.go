@@ -25,6 +25,7 @@ import "github.com/kelseyhightower/envconfig"
 // CORS_EXPOSED_HEADERS                  (default: Content-Length,Content-Range,Content-Disposition)
 // CORS_ALLOW_CREDENTIALS                (default: false)
 // CORS_PREFLIGHT_MAX_AGE                (default: 600)
+// TRUSTED_PROXIES
 type Config struct {
 	LogLevel               string `envconfig:"LOG_LEVEL"                 default:"debug"`
 	HTTPAddress            string `envconfig:"HTTP_ADDRESS"              default:"0.0.0.0:8080"`
@@ -45,6 +46,7 @@ type Config struct {
 	CORSExposedHeaders     string `envconfig:"CORS_EXPOSED_HEADERS"      default:"Content-Length,Content-Range,Content-Disposition"`
 	CORSAllowCredentials   bool   `envconfig:"CORS_ALLOW_CREDENTIALS"    default:"false"`
 	CORSPreflightMaxAge    int    `envconfig:"CORS_PREFLIGHT_MAX_AGE"    default:"600"`
+	TrustedProxies         string `envconfig:"TRUSTED_PROXIES"`
 }
 
 func loadConfig() (*Config, error) {
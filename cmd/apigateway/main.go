@@ -87,6 +87,7 @@ func main() {
 		downloadTaskService,
 		authMiddleware,
 		authService,
+		logger,
 	)
 
 	// Redis token store — consumed by the /download fallback handler
@@ -147,6 +148,12 @@ func main() {
 		AllowCredentials: config.CORSAllowCredentials,
 		PreflightMaxAge:  config.CORSPreflightMaxAge,
 	})(httpHandler)
+	trustedProxies, err := middleware.ParseTrustedProxies(splitCSV(config.TrustedProxies))
+	if err != nil {
+		level.Error(logger).Log("err", err, "msg", "invalid TRUSTED_PROXIES")
+		os.Exit(1)
+	}
+	httpHandler = middleware.ClientIPHTTPMiddleware(trustedProxies)(httpHandler)
 	httpHandler = middleware.RecoveryHTTPMiddleware(logger)(httpHandler)
 	httpHandler = middleware.LoggingHTTPMiddleware(logger)(httpHandler)
 
@@ -19,22 +19,36 @@ import "github.com/kelseyhightower/envconfig"
 // AUTH_TOKEN_RSA_BITS                  (default: 2048)
 // AUTH_TOKEN_EXPIRES_IN                (default: 24h)
 // AUTH_TOKEN_REGENERATE_BEFORE_EXPIRY  (default: 1h)
+// AUTH_TOKEN_ROTATION_INTERVAL         (default: 720h)
+// AUTH_REFRESH_TOKEN_EXPIRES_IN        (default: 720h)
 // AUTH_SERVICE_GRPC_ADDRESS            (default: 0.0.0.0:8081)
+// OIDC_ISSUER_URL                      (empty disables OpenID Connect login)
+// OIDC_CLIENT_ID
+// OIDC_CLIENT_SECRET
+// OIDC_REDIRECT_URL                    (the gateway's /api/v1/auth/oidc/callback)
+// OIDC_SCOPES                          (default: profile,email)
 type Config struct {
-	LogLevel                        string `envconfig:"LOG_LEVEL"                           default:"debug"`
-	MySQLHost                       string `envconfig:"MYSQL_HOST"                          default:"localhost"`
-	MySQLPort                       int    `envconfig:"MYSQL_PORT"                          default:"3306"`
-	MySQLUsername                   string `envconfig:"MYSQL_USERNAME"                      default:"root"`
-	MySQLPassword                   string `envconfig:"MYSQL_PASSWORD"`
-	MySQLDatabase                   string `envconfig:"MYSQL_DATABASE"                      default:"goload"`
-	RedisAddress                    string `envconfig:"REDIS_ADDRESS"                       default:"localhost:6379"`
-	RedisUsername                   string `envconfig:"REDIS_USERNAME"`
-	RedisPassword                   string `envconfig:"REDIS_PASSWORD"`
-	AuthHashBcryptCost              int    `envconfig:"AUTH_HASH_BCRYPT_COST"               default:"10"`
-	AuthTokenRSABits                int    `envconfig:"AUTH_TOKEN_RSA_BITS"                 default:"2048"`
-	AuthTokenExpiresIn              string `envconfig:"AUTH_TOKEN_EXPIRES_IN"               default:"24h"`
-	AuthTokenRegenerateBeforeExpiry string `envconfig:"AUTH_TOKEN_REGENERATE_BEFORE_EXPIRY" default:"1h"`
-	GRPCAddress                     string `envconfig:"AUTH_SERVICE_GRPC_ADDRESS"           default:"0.0.0.0:8081"`
+	LogLevel                        string   `envconfig:"LOG_LEVEL"                           default:"debug"`
+	MySQLHost                       string   `envconfig:"MYSQL_HOST"                          default:"localhost"`
+	MySQLPort                       int      `envconfig:"MYSQL_PORT"                          default:"3306"`
+	MySQLUsername                   string   `envconfig:"MYSQL_USERNAME"                      default:"root"`
+	MySQLPassword                   string   `envconfig:"MYSQL_PASSWORD"`
+	MySQLDatabase                   string   `envconfig:"MYSQL_DATABASE"                      default:"goload"`
+	RedisAddress                    string   `envconfig:"REDIS_ADDRESS"                       default:"localhost:6379"`
+	RedisUsername                   string   `envconfig:"REDIS_USERNAME"`
+	RedisPassword                   string   `envconfig:"REDIS_PASSWORD"`
+	AuthHashBcryptCost              int      `envconfig:"AUTH_HASH_BCRYPT_COST"               default:"10"`
+	AuthTokenRSABits                int      `envconfig:"AUTH_TOKEN_RSA_BITS"                 default:"2048"`
+	AuthTokenExpiresIn              string   `envconfig:"AUTH_TOKEN_EXPIRES_IN"               default:"24h"`
+	AuthTokenRegenerateBeforeExpiry string   `envconfig:"AUTH_TOKEN_REGENERATE_BEFORE_EXPIRY" default:"1h"`
+	AuthTokenRotationInterval       string   `envconfig:"AUTH_TOKEN_ROTATION_INTERVAL"        default:"720h"`
+	AuthRefreshTokenExpiresIn       string   `envconfig:"AUTH_REFRESH_TOKEN_EXPIRES_IN"       default:"720h"`
+	GRPCAddress                     string   `envconfig:"AUTH_SERVICE_GRPC_ADDRESS"           default:"0.0.0.0:8081"`
+	OIDCIssuerURL                   string   `envconfig:"OIDC_ISSUER_URL"`
+	OIDCClientID                    string   `envconfig:"OIDC_CLIENT_ID"`
+	OIDCClientSecret                string   `envconfig:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL                 string   `envconfig:"OIDC_REDIRECT_URL"`
+	OIDCScopes                      []string `envconfig:"OIDC_SCOPES"                         default:"profile,email"`
 }
 
 func loadConfig() (*Config, error) {
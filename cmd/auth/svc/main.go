@@ -2,8 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
 	"database/sql"
 	"fmt"
 	"net"
@@ -28,6 +26,7 @@ import (
 	rediscache "github.com/yuisofull/goload/pkg/cache/redis"
 	"github.com/yuisofull/goload/pkg/crypto/bcrypt"
 	"github.com/yuisofull/goload/pkg/middleware"
+	"github.com/yuisofull/goload/pkg/oidc"
 )
 
 func main() {
@@ -94,34 +93,26 @@ func main() {
 	}
 
 	var (
-		tokenManager   auth.TokenManager
-		tokenStore     = store.TokenPublicKeyStore
-		publicKeyCache = rediscache.New(
-			redisClient,
-			rediscache.WithKeyEncoder[authcache.TokenPublicKeyCacheKey, []byte](
-				rediscache.PrefixKeyEncoder[authcache.TokenPublicKeyCacheKey]{
-					Prefix: "auth:token_public_key",
-					Inner:  rediscache.DefaultKeyEncoder[authcache.TokenPublicKeyCacheKey]{},
-				},
-			),
-		)
+		tokenManager auth.TokenManager
+		signingKeys  *auth.SigningKeys
 	)
 	{
-		cacheErrorHandler := func(ctx context.Context, err error) {
-			level.Error(logger).Log("err", err)
-		}
-		tokenStore = authcache.NewTokenPublicKeyStore(publicKeyCache, store, cacheErrorHandler)
-		privateKey, err := rsa.GenerateKey(rand.Reader, config.AuthTokenRSABits)
+		tokenExpiresIn, err := time.ParseDuration(config.AuthTokenExpiresIn)
 		if err != nil {
-			level.Error(logger).Log("err", err)
+			level.Error(logger).Log("err", err, "msg", "invalid AUTH_TOKEN_EXPIRES_IN")
 			os.Exit(1)
 		}
-		tokenExpiresIn, err := time.ParseDuration(config.AuthTokenExpiresIn)
+		rotationInterval, err := time.ParseDuration(config.AuthTokenRotationInterval)
 		if err != nil {
-			level.Error(logger).Log("err", err, "msg", "invalid AUTH_TOKEN_EXPIRES_IN")
+			level.Error(logger).Log("err", err, "msg", "invalid AUTH_TOKEN_ROTATION_INTERVAL")
 			os.Exit(1)
 		}
-		tokenManager, err = auth.NewJWTRS512TokenManager(privateKey, tokenExpiresIn, tokenStore)
+		signingKeys = auth.NewSigningKeys(store, tokenExpiresIn,
+			auth.WithKeyRotationInterval(rotationInterval),
+			auth.WithSigningKeyBits(config.AuthTokenRSABits),
+			auth.WithSigningKeysLogger(logger),
+		)
+		tokenManager, err = auth.NewJWTRS512TokenManager(signingKeys, tokenExpiresIn)
 		if err != nil {
 			level.Error(logger).Log("err", err)
 			os.Exit(1)
@@ -144,9 +135,64 @@ func main() {
 			level.Error(logger).Log("err", err)
 		}
 		accountStore = authcache.NewAccountStore(nameCache, store, cacheErrorHandler)
-		service      = auth.NewService(accountStore, store, store, hasher, tokenManager)
-		endpointSet  = authendpoint.New(service)
-		grpcServer   = authtransport.NewGRPCServer(endpointSet, logger)
+		revokedCache = rediscache.New(
+			redisClient,
+			rediscache.WithKeyEncoder[string, int64](
+				rediscache.PrefixKeyEncoder[string]{
+					Prefix: "auth:revoked",
+					Inner:  rediscache.DefaultKeyEncoder[string]{},
+				},
+			),
+		)
+		refreshTokenCache = rediscache.New(
+			redisClient,
+			rediscache.WithKeyEncoder[string, auth.RefreshSession](
+				rediscache.PrefixKeyEncoder[string]{
+					Prefix: "auth:refresh_token",
+					Inner:  rediscache.DefaultKeyEncoder[string]{},
+				},
+			),
+		)
+		oidcLoginCache = rediscache.New(
+			redisClient,
+			rediscache.WithKeyEncoder[string, auth.OIDCLogin](
+				rediscache.PrefixKeyEncoder[string]{
+					Prefix: "auth:oidc_login",
+					Inner:  rediscache.DefaultKeyEncoder[string]{},
+				},
+			),
+		)
+	)
+
+	refreshTokenExpiresIn, err := time.ParseDuration(config.AuthRefreshTokenExpiresIn)
+	if err != nil {
+		level.Error(logger).Log("err", err, "msg", "invalid AUTH_REFRESH_TOKEN_EXPIRES_IN")
+		os.Exit(1)
+	}
+
+	serviceOptions := []auth.ServiceOption{
+		auth.WithRefreshTokens(authcache.NewRefreshTokenStore(refreshTokenCache), refreshTokenExpiresIn),
+		auth.WithRevocationList(authcache.NewRevocationList(revokedCache)),
+		auth.WithAPIKeys(store),
+		auth.WithWorkspaces(store),
+	}
+	if config.OIDCIssuerURL != "" {
+		provider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    config.OIDCIssuerURL,
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			RedirectURL:  config.OIDCRedirectURL,
+			Scopes:       config.OIDCScopes,
+		})
+		serviceOptions = append(serviceOptions,
+			auth.WithOIDC(provider, authcache.NewOIDCLoginStore(oidcLoginCache), store))
+		level.Info(logger).Log("msg", "oidc login enabled", "issuer", config.OIDCIssuerURL)
+	}
+
+	var (
+		service     = auth.NewService(accountStore, store, store, hasher, tokenManager, serviceOptions...)
+		endpointSet = authendpoint.New(service)
+		grpcServer  = authtransport.NewGRPCServer(endpointSet, logger)
 	)
 
 	var g run.Group
@@ -181,6 +227,15 @@ func main() {
 		})
 	}
 
+	{
+		keysCtx, keysCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return signingKeys.Run(keysCtx)
+		}, func(error) {
+			keysCancel()
+		})
+	}
+
 	{
 		g.Add(func() error {
 			<-ctx.Done()
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Config holds the environment variables of the dead-letter tool.
+//
+// KAFKA_BROKERS                (comma-separated, required)
+// KAFKA_VERSION                (default: 4.0.0)
+// KAFKA_CONSUMER_GROUP         (default: goload-dlq; replay commits its progress in this group)
+// DLQ_IDLE_TIMEOUT             (default: 5s; a dead-letter topic is drained once no message arrived for it)
+type Config struct {
+	KafkaBrokers       []string      `envconfig:"KAFKA_BROKERS"        required:"true"`
+	KafkaVersion       string        `envconfig:"KAFKA_VERSION"        default:"4.0.0"`
+	KafkaConsumerGroup string        `envconfig:"KAFKA_CONSUMER_GROUP" default:"goload-dlq"`
+	IdleTimeout        time.Duration `envconfig:"DLQ_IDLE_TIMEOUT"     default:"5s"`
+}
+
+func loadConfig() (*Config, error) {
+	cfg := &Config{}
+	return cfg, envconfig.Process("", cfg)
+}
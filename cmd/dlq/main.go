@@ -0,0 +1,150 @@
+// Command dlq inspects and replays the dead-letter topics of the services.
+//
+// Usage:
+//
+//	dlq [-limit n] list <topic>
+//	dlq [-limit n] replay <topic>
+//
+// list prints the messages forwarded from topic to its dead-letter topic as
+// JSON lines, reading through a consumer group of its own so that nothing is
+// consumed. replay publishes them to topic again and commits them in
+// KAFKA_CONSUMER_GROUP, so each message is replayed once.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/go-kit/log"
+	"github.com/google/uuid"
+
+	"github.com/yuisofull/goload/pkg/message"
+	kafkapkg "github.com/yuisofull/goload/pkg/message/kafka"
+)
+
+// deadLetter is the printed form of a message.DeadLetter.
+type deadLetter struct {
+	UUID       string            `json:"uuid"`
+	Topic      string            `json:"topic"`
+	Reason     string            `json:"reason"`
+	Deliveries int               `json:"deliveries"`
+	FailedAt   time.Time         `json:"failed_at"`
+	Metadata   map[string]string `json:"metadata"`
+	Payload    string            `json:"payload"`
+}
+
+func main() {
+	limit := flag.Int("limit", 0, "most messages to list or replay; 0 for all")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: dlq [-limit n] list|replay <topic>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, topic := flag.Arg(0), flag.Arg(1)
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	switch command {
+	case "list":
+		err = list(ctx, config, topic, *limit)
+	case "replay":
+		err = replay(ctx, config, topic, *limit)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func list(ctx context.Context, config *Config, topic string, limit int) error {
+	// A fresh consumer group reads the topic from its oldest message and
+	// leaves the offsets of the replaying group alone.
+	dl, closeFn, err := newDeadLetters(config, "goload-dlq-inspect-"+uuid.NewString(), nil)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	letters, err := dl.Inspect(ctx, topic, limit)
+	enc := json.NewEncoder(os.Stdout)
+	for _, l := range letters {
+		if encErr := enc.Encode(deadLetter{
+			UUID:       l.Message.UUID,
+			Topic:      l.Topic,
+			Reason:     l.Reason,
+			Deliveries: l.Deliveries,
+			FailedAt:   l.FailedAt,
+			Metadata:   l.Message.Metadata,
+			Payload:    string(l.Message.Payload),
+		}); encErr != nil {
+			return encErr
+		}
+	}
+	return err
+}
+
+func replay(ctx context.Context, config *Config, topic string, limit int) error {
+	pub, err := kafkapkg.NewPublisher(&kafkapkg.PublisherConfig{
+		BrokerHosts: config.KafkaBrokers,
+		Version:     kafkaVersion(config),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create kafka publisher: %w", err)
+	}
+	defer pub.Close()
+
+	dl, closeFn, err := newDeadLetters(config, config.KafkaConsumerGroup, pub)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	n, err := dl.Replay(ctx, topic, limit)
+	fmt.Fprintf(os.Stderr, "replayed %d messages to %s\n", n, topic)
+	return err
+}
+
+func newDeadLetters(
+	config *Config,
+	consumerGroup string,
+	pub message.Publisher,
+) (*message.DeadLetters, func(), error) {
+	sub, err := kafkapkg.NewSubscriber(&kafkapkg.SubscriberConfig{
+		Brokers:       config.KafkaBrokers,
+		ConsumerGroup: consumerGroup,
+		Version:       kafkaVersion(config),
+	}, kafkapkg.WithLog(log.NewNopLogger()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create kafka subscriber: %w", err)
+	}
+	return message.NewDeadLetters(sub, pub, config.IdleTimeout), func() { sub.Close() }, nil
+}
+
+func kafkaVersion(config *Config) sarama.KafkaVersion {
+	kv, err := sarama.ParseKafkaVersion(config.KafkaVersion)
+	if err != nil {
+		return sarama.V3_6_0_0
+	}
+	return kv
+}
@@ -14,24 +14,46 @@ import (
 // KAFKA_BROKERS                (comma-separated, required)
 // KAFKA_VERSION                (default: 4.0.0)
 // KAFKA_CONSUMER_GROUP         (default: download-service-group)
+// KAFKA_MAX_DELIVERIES         (default: 10; nacked messages go to <topic>.dlq after it, 0 redelivers forever)
 // MINIO_ENDPOINT               (required)
 // MINIO_ACCESS_KEY             (required)
 // MINIO_SECRET_KEY             (required)
 // MINIO_BUCKET                 (default: goload)
 // MINIO_USE_SSL                (default: false)
 // MINIO_FILE_EXPIRY            (optional, e.g. "720h" for 30 days; 0 means no expiry)
+// MINIO_DEDUP                  (default: false; stores identical content once under its SHA-256 digest)
+// MYSQL_HOST                   (required; database of the durable download queue)
+// MYSQL_PORT                   (default: 3306)
+// MYSQL_USERNAME               (default: root)
+// MYSQL_PASSWORD
+// MYSQL_DATABASE               (default: goload)
+// DOWNLOAD_PARTIAL_DIR         (default: /tmp/goload/partial; empty disables persisted partial downloads)
+// DOWNLOAD_WORKER_ID           (default: host name; must differ between workers sharing the queue)
+// DOWNLOAD_LEASE_TTL           (default: 30s; tasks of a worker that stopped renewing are taken over after it)
+// DOWNLOAD_QUEUE_KEY           (base64 AES key of 16, 24 or 32 bytes sealing source credentials in the queue)
 // TASK_SERVICE_GRPC_ADDRESS    (required; used to fetch SourceURL for large payloads)
 type Config struct {
 	LogLevel           string        `envconfig:"LOG_LEVEL"            default:"debug"`
 	KafkaBrokers       []string      `envconfig:"KAFKA_BROKERS"`
 	KafkaVersion       string        `envconfig:"KAFKA_VERSION"        default:"4.0.0"`
 	KafkaConsumerGroup string        `envconfig:"KAFKA_CONSUMER_GROUP" default:"download-service-group"`
+	KafkaMaxDeliveries int           `envconfig:"KAFKA_MAX_DELIVERIES" default:"10"`
 	MinioEndpoint      string        `envconfig:"MINIO_ENDPOINT"`
 	MinioAccessKey     string        `envconfig:"MINIO_ACCESS_KEY"`
 	MinioSecretKey     string        `envconfig:"MINIO_SECRET_KEY"`
 	MinioBucket        string        `envconfig:"MINIO_BUCKET"         default:"goload"`
 	MinioUseSSL        bool          `envconfig:"MINIO_USE_SSL"        default:"false"`
 	MinioFileExpiry    time.Duration `envconfig:"MINIO_FILE_EXPIRY"    default:"0"`
+	MinioDedup         bool          `envconfig:"MINIO_DEDUP"          default:"false"`
+	MySQLHost          string        `envconfig:"MYSQL_HOST"`
+	MySQLPort          int           `envconfig:"MYSQL_PORT"           default:"3306"`
+	MySQLUsername      string        `envconfig:"MYSQL_USERNAME"       default:"root"`
+	MySQLPassword      string        `envconfig:"MYSQL_PASSWORD"`
+	MySQLDatabase      string        `envconfig:"MYSQL_DATABASE"       default:"goload"`
+	PartialDir         string        `envconfig:"DOWNLOAD_PARTIAL_DIR" default:"/tmp/goload/partial"`
+	WorkerID           string        `envconfig:"DOWNLOAD_WORKER_ID"`
+	LeaseTTL           time.Duration `envconfig:"DOWNLOAD_LEASE_TTL"   default:"30s"`
+	QueueKey           string        `envconfig:"DOWNLOAD_QUEUE_KEY"`
 }
 
 func loadConfig() (*Config, error) {
@@ -45,6 +45,13 @@ func (m *loggingMiddleware) logErr(method string, duration time.Duration, err er
 	}
 }
 
+func (m *loggingMiddleware) SubmitTask(ctx context.Context, req download.TaskRequest) error {
+	start := time.Now()
+	err := m.next.SubmitTask(ctx, req)
+	m.logErr("SubmitTask", time.Since(start), err)
+	return err
+}
+
 func (m *loggingMiddleware) ExecuteTask(ctx context.Context, req download.TaskRequest) error {
 	start := time.Now()
 	err := m.next.ExecuteTask(ctx, req)
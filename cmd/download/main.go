@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,6 +20,7 @@ import (
 
 	"github.com/yuisofull/goload/internal/download"
 	"github.com/yuisofull/goload/internal/download/downloader"
+	downloadmysql "github.com/yuisofull/goload/internal/download/mysql"
 	downloadtransport "github.com/yuisofull/goload/internal/download/transport"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/pkg/message"
@@ -50,6 +54,10 @@ func main() {
 				minioOpts = append(minioOpts, storage.WithMinioExpiry(config.MinioFileExpiry))
 				level.Info(logger).Log("msg", "minio file expiry configured", "expiry", config.MinioFileExpiry)
 			}
+			if config.MinioDedup {
+				minioOpts = append(minioOpts, storage.WithMinioDedup(true))
+				level.Info(logger).Log("msg", "minio content deduplication enabled")
+			}
 			if m, err := storage.NewMinioBackend(
 				config.MinioEndpoint,
 				config.MinioAccessKey,
@@ -88,9 +96,11 @@ func main() {
 			os.Exit(1)
 		}
 		subCfg := &kafkapkg.SubscriberConfig{
-			Brokers:       config.KafkaBrokers,
-			ConsumerGroup: config.KafkaConsumerGroup,
-			Version:       kv,
+			Brokers:             config.KafkaBrokers,
+			ConsumerGroup:       config.KafkaConsumerGroup,
+			Version:             kv,
+			MaxDeliveries:       config.KafkaMaxDeliveries,
+			DeadLetterPublisher: pub,
 		}
 		sub, err = kafkapkg.NewSubscriber(subCfg, kafkapkg.WithErrorHandler(func(_ context.Context, e error) {
 			// Ignore benign context cancellation errors (happen during shutdown) and log them at debug level.
@@ -110,8 +120,64 @@ func main() {
 		os.Exit(1)
 	}
 
+	// durable download queue (MySQL). The Kafka subscriber delivers the next
+	// message of a partition once the previous one is acked, so tasks are
+	// acked once recorded in the queue rather than once downloaded.
+	if config.MySQLHost == "" {
+		level.Error(logger).Log("msg", "MYSQL_HOST not set: the download service needs the durable download queue")
+		os.Exit(1)
+	}
+	var queue download.Queue
+	{
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+			config.MySQLUsername,
+			config.MySQLPassword,
+			config.MySQLHost,
+			config.MySQLPort,
+			config.MySQLDatabase)
+		db, err := sql.Open("mysql", dsn)
+		if err != nil {
+			level.Error(logger).Log("msg", "failed to open mysql", "err", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+		// simple ping retry
+		for range 5 {
+			if err = db.Ping(); err == nil {
+				break
+			}
+			time.Sleep(2 * time.Second)
+		}
+		if err != nil {
+			level.Error(logger).Log("msg", "cannot connect to mysql", "err", err)
+			os.Exit(1)
+		}
+		queueKey, err := base64.StdEncoding.DecodeString(config.QueueKey)
+		if err != nil {
+			level.Error(logger).Log("msg", "invalid DOWNLOAD_QUEUE_KEY", "err", err)
+			os.Exit(1)
+		}
+		codec, err := download.NewRequestCodec(queueKey)
+		if err != nil {
+			level.Error(logger).Log("msg", "invalid DOWNLOAD_QUEUE_KEY", "err", err)
+			os.Exit(1)
+		}
+		if len(queueKey) == 0 {
+			level.Warn(logger).Log(
+				"msg", "DOWNLOAD_QUEUE_KEY not set: source credentials are not kept in the download queue",
+			)
+		}
+		queue = downloadmysql.NewQueue(db, codec)
+	}
+
 	dep := download.NewDownloadEventPublisher(pub)
-	svc := download.NewService(storageBackend, dep, download.WithStorageType(storage.TypeMinio))
+	opts := []download.Option{
+		download.WithStorageType(storage.TypeMinio),
+		download.WithPartialDir(config.PartialDir),
+		download.WithLease(config.WorkerID, config.LeaseTTL),
+		download.WithQueue(queue),
+	}
+	svc := download.NewService(storageBackend, dep, opts...)
 
 	// Register concrete downloaders for each supported source type.
 	httpDL := downloader.NewHTTPDownloader(nil, downloader.WithHTTPLogger(logger))
@@ -132,7 +198,7 @@ func main() {
 	)
 
 	// loggingSvc := &loggingMiddleware{next: svc, logger: logger}
-	consumer := downloadtransport.NewEventConsumer(svc, sub, logger)
+	consumer := downloadtransport.NewEventConsumer(svc, sub, logger, downloadtransport.WithControlAcks(dep))
 
 	// readiness flag for health endpoint
 	var ready int32
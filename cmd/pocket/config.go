@@ -1,6 +1,10 @@
 package main
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 // Config holds all environment variables for the API gateway service.
 //
@@ -25,18 +29,50 @@ import "github.com/kelseyhightower/envconfig"
 // CORS_EXPOSED_HEADERS                  (default: Content-Length,Content-Range,Content-Disposition)
 // CORS_ALLOW_CREDENTIALS                (default: false)
 // CORS_PREFLIGHT_MAX_AGE                (default: 600)
+// TRUSTED_PROXIES
+// SCHEDULER_INTERVAL                    (default: 30s)
+// WEBHOOK_INTERVAL                      (default: 5s)
+// WEBHOOK_MAX_ATTEMPTS                  (default: 8)
+// WEBHOOK_RETRY_DELAY                   (default: 10s)
+// WEBHOOK_ALLOW_PRIVATE                 (default: false)
+// MAX_BATCH_SIZE                        (default: 500)
+// STORAGE_RETENTION                     (default: 24h)
+// STORAGE_REAP_INTERVAL                 (default: 1h)
+// STORAGE_ACCOUNT_RETENTION
+// STORAGE_DEDUP                         (default: false)
+// TASK_EXPIRATION_DAYS                  (default: 30, 0 never expires)
+// TASK_PURGE_EXPIRED                    (default: false)
+// EXPIRY_SWEEP_INTERVAL                 (default: 1h)
+// DOWNLOAD_QUEUE_KEY                    (base64 AES key sealing source credentials in the download queue)
 type Config struct {
-	LogLevel             string `envconfig:"LOG_LEVEL"              default:"debug"`
-	HTTPAddress          string `envconfig:"HTTP_ADDRESS"           default:"0.0.0.0:8080"`
-	PocketDBPath         string `envconfig:"POCKET_DB_PATH"         default:"./goload.db"`
-	PocketDataDir        string `envconfig:"POCKET_DATA_DIR"        default:"./data"`
-	PocketWebDir         string `envconfig:"POCKET_WEB_DIR"         default:"./public/dist"`
-	CORSAllowedOrigins   string `envconfig:"CORS_ALLOWED_ORIGINS"   default:"*"`
-	CORSAllowedMethods   string `envconfig:"CORS_ALLOWED_METHODS"   default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
-	CORSAllowedHeaders   string `envconfig:"CORS_ALLOWED_HEADERS"   default:"Authorization,Content-Type,Accept,Origin"`
-	CORSExposedHeaders   string `envconfig:"CORS_EXPOSED_HEADERS"   default:"Content-Length,Content-Range,Content-Disposition"`
-	CORSAllowCredentials bool   `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false"`
-	CORSPreflightMaxAge  int    `envconfig:"CORS_PREFLIGHT_MAX_AGE" default:"600"`
+	LogLevel                string        `envconfig:"LOG_LEVEL"              default:"debug"`
+	HTTPAddress             string        `envconfig:"HTTP_ADDRESS"           default:"0.0.0.0:8080"`
+	PocketDBPath            string        `envconfig:"POCKET_DB_PATH"         default:"./goload.db"`
+	PocketDataDir           string        `envconfig:"POCKET_DATA_DIR"        default:"./data"`
+	PocketWebDir            string        `envconfig:"POCKET_WEB_DIR"         default:"./public/dist"`
+	CORSAllowedOrigins      string        `envconfig:"CORS_ALLOWED_ORIGINS"   default:"*"`
+	CORSAllowedMethods      string        `envconfig:"CORS_ALLOWED_METHODS"   default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSAllowedHeaders      string        `envconfig:"CORS_ALLOWED_HEADERS"   default:"Authorization,Content-Type,Accept,Origin"`
+	CORSExposedHeaders      string        `envconfig:"CORS_EXPOSED_HEADERS"   default:"Content-Length,Content-Range,Content-Disposition"`
+	CORSAllowCredentials    bool          `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	CORSPreflightMaxAge     int           `envconfig:"CORS_PREFLIGHT_MAX_AGE" default:"600"`
+	TrustedProxies          string        `envconfig:"TRUSTED_PROXIES"`
+	SchedulerInterval       time.Duration `envconfig:"SCHEDULER_INTERVAL"     default:"30s"`
+	WebhookInterval         time.Duration `envconfig:"WEBHOOK_INTERVAL"       default:"5s"`
+	WebhookMaxAttempts      int           `envconfig:"WEBHOOK_MAX_ATTEMPTS"   default:"8"`
+	WebhookRetryDelay       time.Duration `envconfig:"WEBHOOK_RETRY_DELAY"    default:"10s"`
+	WebhookAllowPrivate     bool          `envconfig:"WEBHOOK_ALLOW_PRIVATE"  default:"false"`
+	MaxBatchSize            int           `envconfig:"MAX_BATCH_SIZE"         default:"500"`
+	OutboxInterval          time.Duration `envconfig:"OUTBOX_INTERVAL"        default:"1s"`
+	MaxDeliveries           int           `envconfig:"MAX_DELIVERIES"         default:"10"`
+	DownloadQueueKey        string        `envconfig:"DOWNLOAD_QUEUE_KEY"`
+	StorageRetention        time.Duration `envconfig:"STORAGE_RETENTION"      default:"24h"`
+	StorageReapInterval     time.Duration `envconfig:"STORAGE_REAP_INTERVAL"  default:"1h"`
+	StorageAccountRetention string        `envconfig:"STORAGE_ACCOUNT_RETENTION"`
+	StorageDedup            bool          `envconfig:"STORAGE_DEDUP"          default:"false"`
+	TaskExpirationDays      int32         `envconfig:"TASK_EXPIRATION_DAYS"   default:"30"`
+	TaskPurgeExpired        bool          `envconfig:"TASK_PURGE_EXPIRED"     default:"false"`
+	ExpirySweepInterval     time.Duration `envconfig:"EXPIRY_SWEEP_INTERVAL"  default:"1h"`
 }
 
 func loadConfig() (*Config, error) {
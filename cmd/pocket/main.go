@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"os"
@@ -21,14 +22,18 @@ import (
 
 	"github.com/yuisofull/goload/internal/apigateway"
 	"github.com/yuisofull/goload/internal/auth"
+	authcache "github.com/yuisofull/goload/internal/auth/cache"
 	authsqlite "github.com/yuisofull/goload/internal/auth/sqlite"
 	"github.com/yuisofull/goload/internal/download"
 	"github.com/yuisofull/goload/internal/download/downloader"
+	downloadsqlite "github.com/yuisofull/goload/internal/download/sqlite"
 	downloadtransport "github.com/yuisofull/goload/internal/download/transport"
+	"github.com/yuisofull/goload/internal/pocketdb"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/internal/task"
 	tasksqlite "github.com/yuisofull/goload/internal/task/sqlite"
 	tasktransport "github.com/yuisofull/goload/internal/task/transport"
+	inmemcache "github.com/yuisofull/goload/pkg/cache/inmem"
 	"github.com/yuisofull/goload/pkg/crypto/bcrypt"
 	"github.com/yuisofull/goload/pkg/message/inmem"
 	"github.com/yuisofull/goload/pkg/middleware"
@@ -63,22 +68,31 @@ func main() {
 	defer pool.Close()
 
 	// Run simple migrations (create tables if not exists)
-	must(runMigrations(pool))
+	must(pocketdb.Migrate(pool))
 
-	// Initialize storage backend (local filesystem)
+	// Create in-memory broker
+	b := inmem.NewBroker(100, logger, inmem.WithMaxDeliveries(cfg.MaxDeliveries))
+	pub := inmem.NewPublisher(b)
+	sub := inmem.NewSubscriber(b)
+	downloadPub := download.NewDownloadEventPublisher(pub)
+
+	// Initialize storage backend (local filesystem). Expired objects are
+	// reported so the tasks that produced them can be marked expired.
 	dataDir := cfg.PocketDataDir
+	retentionOpts, err := storage.LocalRetentionOptions(cfg.StorageAccountRetention)
+	must(err)
 	storageBackend, err := storage.NewLocalBackend(
 		dataDir,
-		storage.WithLocalLogger(logger),
-		storage.WithLocalExpiry(24*time.Hour),
+		append([]storage.LocalOption{
+			storage.WithLocalLogger(logger),
+			storage.WithLocalExpiry(cfg.StorageRetention),
+			storage.WithLocalReapInterval(cfg.StorageReapInterval),
+			storage.WithLocalDedup(cfg.StorageDedup),
+			storage.WithLocalExpiryHandler(downloadPub.PublishObjectExpired),
+		}, retentionOpts...)...,
 	)
 	must(err)
 
-	// Create in-memory broker
-	b := inmem.NewBroker(100, logger)
-	pub := inmem.NewPublisher(b)
-	sub := inmem.NewSubscriber(b)
-
 	// Initialize auth and task persistence using direct crawshaw implementations
 	authStore := authsqlite.New(pool)
 	taskRepo := tasksqlite.NewTaskRepo(pool)
@@ -91,17 +105,22 @@ func main() {
 	// Token manager: use a no-op manager for pocket single-user mode
 	tokenManager := auth.NewNoopTokenManager(24 * time.Hour)
 
-	// Auth service
+	// Auth service; sessions and revocations live in memory
 	authSvc := auth.NewService(
 		authStore.AccountStore,
 		authStore.AccountPasswordStore,
 		authStore.TxManager,
 		hasher,
 		tokenManager,
+		auth.WithRefreshTokens(authcache.NewRefreshTokenStore(inmemcache.New[string, auth.RefreshSession](time.Minute)), 0),
+		auth.WithRevocationList(authcache.NewRevocationList(inmemcache.New[string, int64](time.Minute))),
+		auth.WithAPIKeys(authStore.APIKeyStore),
+		auth.WithWorkspaces(authStore.WorkspaceStore),
 	)
 
 	// Task service: use in-memory pubsub publisher
-	taskPub := task.NewEventPublisher(pub)
+	taskOutbox := tasksqlite.NewOutboxRepo(pool)
+	taskPub := task.NewEventPublisher(pub, task.WithOutbox(taskOutbox))
 	tokenStore := task.NewInmemTokenStore()
 	taskSvc := task.NewService(taskRepo, *taskPub, tx,
 		task.WithTokenStore(tokenStore),
@@ -109,6 +128,15 @@ func main() {
 		// open from the web UI. Use the HTTP /download token route in pocket mode.
 		task.WithTaskSourceStore(storageBackend),
 		task.WithTaskSourcePresigner(storageBackend),
+		task.WithScheduleRepository(tasksqlite.NewScheduleRepo(pool)),
+		task.WithWebhookRepository(tasksqlite.NewWebhookRepo(pool)),
+		task.WithAuditRepository(tasksqlite.NewAuditRepo(pool)),
+		task.WithWebhookRetry(cfg.WebhookMaxAttempts, cfg.WebhookRetryDelay),
+		task.WithWebhookPrivateTargets(cfg.WebhookAllowPrivate),
+		task.WithMaxBatchSize(cfg.MaxBatchSize),
+		task.WithFileStore(storageBackend),
+		task.WithDefaultExpirationDays(cfg.TaskExpirationDays),
+		task.WithExpiredTaskPurge(cfg.TaskPurgeExpired),
 	)
 
 	// Task event consumer
@@ -120,11 +148,16 @@ func main() {
 	}
 
 	// Download service
-	downloadPub := download.NewDownloadEventPublisher(pub)
+	queueKey, err := base64.StdEncoding.DecodeString(cfg.DownloadQueueKey)
+	must(err)
+	queueCodec, err := download.NewRequestCodec(queueKey)
+	must(err)
 	dlSvc := download.NewService(
 		storageBackend,
 		downloadPub,
 		download.WithStorageType(storage.TypeLocal),
+		download.WithQueue(downloadsqlite.NewQueue(pool, queueCodec)),
+		download.WithPartialDir(filepath.Join(dataDir, ".partial")),
 		download.WithErrorHandler(func(ctx context.Context, err error) {
 			level.Error(logger).Log("msg", "download failed", "err", err)
 		}),
@@ -140,7 +173,7 @@ func main() {
 	dlSvc.RegisterDownloader("BITTORRENT", btDL)
 
 	// Start event consumer (download service listens for task events)
-	consumer := downloadtransport.NewEventConsumer(dlSvc, sub, logger)
+	consumer := downloadtransport.NewEventConsumer(dlSvc, sub, logger, downloadtransport.WithControlAcks(downloadPub))
 
 	// Create a default pocket account and use a no-auth middleware that
 	// injects this account ID into requests (single-user mode).
@@ -167,7 +200,7 @@ func main() {
 	}
 
 	authMiddleware := apigateway.NewNoAuthMiddleware(defaultAccountID)
-	endpoints := apigateway.NewGatewayEndpoints(taskSvc, authMiddleware, authSvc)
+	endpoints := apigateway.NewGatewayEndpoints(taskSvc, authMiddleware, authSvc, logger)
 	handler := apigateway.NewHTTPHandlerWithDownload(endpoints, logger, storageBackend, tokenStore)
 	handler.HandleFunc("/api/v1/pocket/tasks/reveal", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -235,6 +268,9 @@ func main() {
 		AllowCredentials: cfg.CORSAllowCredentials,
 		PreflightMaxAge:  cfg.CORSPreflightMaxAge,
 	})(httpHandler)
+	trustedProxies, err := middleware.ParseTrustedProxies(splitCSV(cfg.TrustedProxies))
+	must(err)
+	httpHandler = middleware.ClientIPHTTPMiddleware(trustedProxies)(httpHandler)
 	httpHandler = middleware.RecoveryHTTPMiddleware(logger)(httpHandler)
 	httpHandler = middleware.LoggingHTTPMiddleware(logger)(httpHandler)
 
@@ -262,6 +298,72 @@ func main() {
 		b.Close()
 	})
 
+	{
+		relay := task.NewOutboxRelay(taskOutbox, pub,
+			task.WithOutboxRelayInterval(cfg.OutboxInterval),
+			task.WithOutboxRelayLogger(logger),
+		)
+		outboxCtx, outboxCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return relay.Run(outboxCtx)
+		}, func(error) {
+			outboxCancel()
+		})
+	}
+
+	if runner, ok := taskSvc.(task.WebhookRunner); ok {
+		webhookConsumer := tasktransport.NewWebhookConsumer(runner, sub, func(_ context.Context, err error) {
+			level.Error(logger).Log("msg", "webhook consumer error", "err", err)
+		})
+		g.Add(func() error {
+			return webhookConsumer.Start(ctx)
+		}, func(error) {})
+
+		dispatcher := task.NewWebhookDispatcher(runner,
+			task.WithWebhookDispatcherInterval(cfg.WebhookInterval),
+			task.WithWebhookDispatcherLogger(logger),
+		)
+		webhookCtx, webhookCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return dispatcher.Run(webhookCtx)
+		}, func(error) {
+			webhookCancel()
+		})
+	}
+
+	if runner, ok := taskSvc.(task.ScheduleRunner); ok {
+		scheduler := task.NewScheduler(runner,
+			task.WithSchedulerInterval(cfg.SchedulerInterval),
+			task.WithSchedulerLogger(logger),
+		)
+		schedCtx, schedCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return scheduler.Run(schedCtx)
+		}, func(error) {
+			schedCancel()
+		})
+	}
+
+	if runner, ok := taskSvc.(task.ExpiryRunner); ok {
+		sweeper := task.NewExpirySweeper(runner,
+			task.WithExpirySweeperInterval(cfg.ExpirySweepInterval),
+			task.WithExpirySweeperLogger(logger),
+		)
+		expiryCtx, expiryCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return sweeper.Run(expiryCtx)
+		}, func(error) {
+			expiryCancel()
+		})
+	}
+
+	reapCtx, reapCancel := context.WithCancel(ctx)
+	g.Add(func() error {
+		return storageBackend.Run(reapCtx)
+	}, func(error) {
+		reapCancel()
+	})
+
 	g.Add(func() error {
 		<-ctx.Done()
 		return ctx.Err()
@@ -324,69 +426,6 @@ func wslWindowsPath(ctx context.Context, path string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// runMigrations creates minimal tables compatible with sqlc queries used by existing code.
-func runMigrations(pool *sqlitex.Pool) error {
-	conn := pool.Get(context.Background())
-	if conn == nil {
-		return context.DeadlineExceeded
-	}
-	defer pool.Put(conn)
-
-	// Accounts
-	err := sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS accounts (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        account_name TEXT NOT NULL
-    );`, nil)
-	if err != nil {
-		return err
-	}
-	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS account_passwords (
-        of_account_id INTEGER PRIMARY KEY,
-        hashed_password TEXT NOT NULL
-    );`, nil)
-	if err != nil {
-		return err
-	}
-	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS token_public_keys (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        public_key TEXT NOT NULL
-    );`, nil)
-	if err != nil {
-		return err
-	}
-
-	// Tasks table adapted for SQLite
-	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS tasks (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        of_account_id INTEGER NOT NULL,
-        file_name TEXT NOT NULL,
-        source_url TEXT NOT NULL,
-        source_type TEXT NOT NULL,
-        headers TEXT DEFAULT '{}',
-        source_auth TEXT DEFAULT '{}',
-        storage_type TEXT NOT NULL,
-        storage_path TEXT NOT NULL,
-        checksum_type TEXT,
-        checksum_value TEXT,
-        concurrency INTEGER DEFAULT 4,
-        max_speed INTEGER,
-        max_retries INTEGER NOT NULL DEFAULT 3,
-        timeout INTEGER,
-        status TEXT NOT NULL,
-        progress REAL DEFAULT 0.0,
-        downloaded_bytes INTEGER DEFAULT 0,
-        total_bytes INTEGER DEFAULT 0,
-        error_message TEXT,
-        metadata TEXT DEFAULT '{}',
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        completed_at DATETIME,
-        last_accessed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        expiration_days INTEGER DEFAULT 30
-    );`, nil)
-	return err
-}
-
 func splitCSV(value string) []string {
 	parts := strings.Split(value, ",")
 	out := make([]string, 0, len(parts))
@@ -1,23 +1,55 @@
 package main
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 type Config struct {
-	LogLevel             string `envconfig:"LOG_LEVEL"              default:"debug"`
-	HTTPAddress          string `envconfig:"HTTP_ADDRESS"           default:"0.0.0.0:8080"`
-	PocketDBPath         string `envconfig:"POCKET_DB_PATH"         default:"./goload.db"`
-	PocketDataDir        string `envconfig:"POCKET_DATA_DIR"        default:"./data"`
-	PocketWebDir         string `envconfig:"POCKET_WEB_DIR"`
-	TokenHMACSecret      string `envconfig:"TOKEN_HMAC_SECRET"      default:"dev-secret-change-me"`
-	AuthTokenRSABits     int    `envconfig:"AUTH_TOKEN_RSA_BITS"    default:"2048"`
-	AuthTokenExpiresIn   string `envconfig:"AUTH_TOKEN_EXPIRES_IN"  default:"24h"`
-	AuthHashBcryptCost   int    `envconfig:"AUTH_HASH_BCRYPT_COST"  default:"10"`
-	CORSAllowedOrigins   string `envconfig:"CORS_ALLOWED_ORIGINS"   default:"*"`
-	CORSAllowedMethods   string `envconfig:"CORS_ALLOWED_METHODS"   default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
-	CORSAllowedHeaders   string `envconfig:"CORS_ALLOWED_HEADERS"   default:"Authorization,Content-Type,Accept,Origin"`
-	CORSExposedHeaders   string `envconfig:"CORS_EXPOSED_HEADERS"   default:"Content-Length,Content-Range,Content-Disposition"`
-	CORSAllowCredentials bool   `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false"`
-	CORSPreflightMaxAge  int    `envconfig:"CORS_PREFLIGHT_MAX_AGE" default:"600"`
+	LogLevel                  string        `envconfig:"LOG_LEVEL"              default:"debug"`
+	HTTPAddress               string        `envconfig:"HTTP_ADDRESS"           default:"0.0.0.0:8080"`
+	PocketDBPath              string        `envconfig:"POCKET_DB_PATH"         default:"./goload.db"`
+	PocketDataDir             string        `envconfig:"POCKET_DATA_DIR"        default:"./data"`
+	PocketWebDir              string        `envconfig:"POCKET_WEB_DIR"`
+	TokenHMACSecret           string        `envconfig:"TOKEN_HMAC_SECRET"      default:"dev-secret-change-me"`
+	AuthTokenRSABits          int           `envconfig:"AUTH_TOKEN_RSA_BITS"    default:"2048"`
+	AuthTokenExpiresIn        string        `envconfig:"AUTH_TOKEN_EXPIRES_IN"  default:"24h"`
+	AuthRefreshExpiresIn      time.Duration `envconfig:"AUTH_REFRESH_TOKEN_EXPIRES_IN" default:"720h"`
+	AuthTokenRotationInterval time.Duration `envconfig:"AUTH_TOKEN_ROTATION_INTERVAL" default:"720h"`
+	AuthHashBcryptCost        int           `envconfig:"AUTH_HASH_BCRYPT_COST"  default:"10"`
+	CORSAllowedOrigins        string        `envconfig:"CORS_ALLOWED_ORIGINS"   default:"*"`
+	CORSAllowedMethods        string        `envconfig:"CORS_ALLOWED_METHODS"   default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSAllowedHeaders        string        `envconfig:"CORS_ALLOWED_HEADERS"   default:"Authorization,Content-Type,Accept,Origin"`
+	CORSExposedHeaders        string        `envconfig:"CORS_EXPOSED_HEADERS"   default:"Content-Length,Content-Range,Content-Disposition"`
+	CORSAllowCredentials      bool          `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	CORSPreflightMaxAge       int           `envconfig:"CORS_PREFLIGHT_MAX_AGE" default:"600"`
+	TrustedProxies            string        `envconfig:"TRUSTED_PROXIES"`
+	QuotaMaxActiveTasks       int64         `envconfig:"QUOTA_MAX_ACTIVE_TASKS"  default:"0"`
+	QuotaMaxStoredBytes       int64         `envconfig:"QUOTA_MAX_STORED_BYTES"  default:"0"`
+	QuotaMaxBytesPerDay       int64         `envconfig:"QUOTA_MAX_BYTES_PER_DAY" default:"0"`
+	QuotaAccountOverrides     string        `envconfig:"QUOTA_ACCOUNT_OVERRIDES"`
+	SchedulerInterval         time.Duration `envconfig:"SCHEDULER_INTERVAL"     default:"30s"`
+	WebhookInterval           time.Duration `envconfig:"WEBHOOK_INTERVAL"       default:"5s"`
+	WebhookMaxAttempts        int           `envconfig:"WEBHOOK_MAX_ATTEMPTS"   default:"8"`
+	WebhookRetryDelay         time.Duration `envconfig:"WEBHOOK_RETRY_DELAY"    default:"10s"`
+	WebhookAllowPrivate       bool          `envconfig:"WEBHOOK_ALLOW_PRIVATE"  default:"false"`
+	MaxBatchSize              int           `envconfig:"MAX_BATCH_SIZE"         default:"500"`
+	OutboxInterval            time.Duration `envconfig:"OUTBOX_INTERVAL"        default:"1s"`
+	MaxDeliveries             int           `envconfig:"MAX_DELIVERIES"         default:"10"`
+	DownloadQueueKey          string        `envconfig:"DOWNLOAD_QUEUE_KEY"`
+	StorageRetention          time.Duration `envconfig:"STORAGE_RETENTION"      default:"24h"`
+	StorageReapInterval       time.Duration `envconfig:"STORAGE_REAP_INTERVAL"  default:"1h"`
+	StorageAccountRetention   string        `envconfig:"STORAGE_ACCOUNT_RETENTION"`
+	StorageDedup              bool          `envconfig:"STORAGE_DEDUP"          default:"false"`
+	TaskExpirationDays        int32         `envconfig:"TASK_EXPIRATION_DAYS"   default:"30"`
+	TaskPurgeExpired          bool          `envconfig:"TASK_PURGE_EXPIRED"     default:"false"`
+	ExpirySweepInterval       time.Duration `envconfig:"EXPIRY_SWEEP_INTERVAL"  default:"1h"`
+	OIDCIssuerURL             string        `envconfig:"OIDC_ISSUER_URL"`
+	OIDCClientID              string        `envconfig:"OIDC_CLIENT_ID"`
+	OIDCClientSecret          string        `envconfig:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL           string        `envconfig:"OIDC_REDIRECT_URL"`
+	OIDCScopes                []string      `envconfig:"OIDC_SCOPES"            default:"profile,email"`
 }
 
 func loadConfig() (*Config, error) {
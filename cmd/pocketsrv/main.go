@@ -2,15 +2,12 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
-	"fmt"
+	"encoding/base64"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
@@ -25,7 +22,9 @@ import (
 	authsqlite "github.com/yuisofull/goload/internal/auth/sqlite"
 	"github.com/yuisofull/goload/internal/download"
 	"github.com/yuisofull/goload/internal/download/downloader"
+	downloadsqlite "github.com/yuisofull/goload/internal/download/sqlite"
 	downloadtransport "github.com/yuisofull/goload/internal/download/transport"
+	"github.com/yuisofull/goload/internal/pocketdb"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/internal/task"
 	tasksqlite "github.com/yuisofull/goload/internal/task/sqlite"
@@ -34,6 +33,7 @@ import (
 	"github.com/yuisofull/goload/pkg/crypto/bcrypt"
 	"github.com/yuisofull/goload/pkg/message/inmem"
 	"github.com/yuisofull/goload/pkg/middleware"
+	"github.com/yuisofull/goload/pkg/oidc"
 )
 
 func must(err error) {
@@ -42,41 +42,6 @@ func must(err error) {
 	}
 }
 
-// inmemTokenPublicKeyStore is a thread-safe in-memory implementation of auth.TokenPublicKeyStore.
-// RSA public keys are ephemeral and regenerated on each restart, so SQLite persistence is unnecessary.
-type inmemTokenPublicKeyStore struct {
-	mu   sync.RWMutex
-	keys map[uint64]auth.TokenPublicKey
-	seq  uint64
-}
-
-func newInmemTokenPublicKeyStore() *inmemTokenPublicKeyStore {
-	return &inmemTokenPublicKeyStore{
-		keys: make(map[uint64]auth.TokenPublicKey),
-		seq:  1,
-	}
-}
-
-func (s *inmemTokenPublicKeyStore) CreateTokenPublicKey(_ context.Context, k *auth.TokenPublicKey) (uint64, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	id := s.seq
-	s.seq++
-	k.Id = id
-	s.keys[id] = *k
-	return id, nil
-}
-
-func (s *inmemTokenPublicKeyStore) GetTokenPublicKey(_ context.Context, kid uint64) (auth.TokenPublicKey, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	k, ok := s.keys[kid]
-	if !ok {
-		return auth.TokenPublicKey{}, fmt.Errorf("token public key not found: %d", kid)
-	}
-	return k, nil
-}
-
 func main() {
 	cfg, err := loadConfig()
 	must(err)
@@ -96,78 +61,125 @@ func main() {
 	must(err)
 	defer pool.Close()
 
-	must(runMigrations(pool))
+	must(pocketdb.Migrate(pool))
 
+	b := inmem.NewBroker(100, logger, inmem.WithMaxDeliveries(cfg.MaxDeliveries))
+	pub := inmem.NewPublisher(b)
+	sub := inmem.NewSubscriber(b)
+	downloadPub := download.NewDownloadEventPublisher(pub)
+
+	retentionOpts, err := storage.LocalRetentionOptions(cfg.StorageAccountRetention)
+	must(err)
 	storageBackend, err := storage.NewLocalBackend(
 		cfg.PocketDataDir,
-		storage.WithLocalLogger(logger),
-		storage.WithLocalExpiry(24*time.Hour),
+		append([]storage.LocalOption{
+			storage.WithLocalLogger(logger),
+			storage.WithLocalExpiry(cfg.StorageRetention),
+			storage.WithLocalReapInterval(cfg.StorageReapInterval),
+			storage.WithLocalDedup(cfg.StorageDedup),
+			storage.WithLocalExpiryHandler(downloadPub.PublishObjectExpired),
+		}, retentionOpts...)...,
 	)
 	must(err)
 
-	b := inmem.NewBroker(100, logger)
-	pub := inmem.NewPublisher(b)
-	sub := inmem.NewSubscriber(b)
-
 	authStore := authsqlite.New(pool)
 	taskRepo := tasksqlite.NewTaskRepo(pool)
 	tx := tasksqlite.NewTxManager(pool)
 
-	var tokenManager auth.TokenManager
-	{
-		privateKey, err := rsa.GenerateKey(rand.Reader, cfg.AuthTokenRSABits)
-		must(err)
-
-		tokenExpiresIn, err := time.ParseDuration(cfg.AuthTokenExpiresIn)
-		must(err)
-
-		cacheErrorHandler := func(_ context.Context, err error) {
-			level.Error(logger).Log("msg", "token public key cache error", "err", err)
-		}
-		cachedPKStore := authcache.NewTokenPublicKeyStore(
-			inmemcache.New[authcache.TokenPublicKeyCacheKey, []byte](5*time.Minute),
-			newInmemTokenPublicKeyStore(),
-			cacheErrorHandler,
-		)
-
-		tokenManager, err = auth.NewJWTRS512TokenManager(privateKey, tokenExpiresIn, cachedPKStore)
-		must(err)
-	}
+	tokenExpiresIn, err := time.ParseDuration(cfg.AuthTokenExpiresIn)
+	must(err)
+	signingKeys := auth.NewSigningKeys(authStore.SigningKeyStore, tokenExpiresIn,
+		auth.WithKeyRotationInterval(cfg.AuthTokenRotationInterval),
+		auth.WithSigningKeyBits(cfg.AuthTokenRSABits),
+		auth.WithSigningKeysLogger(logger),
+	)
+	tokenManager, err := auth.NewJWTRS512TokenManager(signingKeys, tokenExpiresIn)
+	must(err)
 
 	bcryptHasher := bcrypt.NewHasher(cfg.AuthHashBcryptCost)
 	hasher := auth.NewPasswordHasher(bcryptHasher)
 
+	authOptions := []auth.ServiceOption{
+		auth.WithRefreshTokens(
+			authcache.NewRefreshTokenStore(inmemcache.New[string, auth.RefreshSession](time.Minute)),
+			cfg.AuthRefreshExpiresIn,
+		),
+		auth.WithRevocationList(authcache.NewRevocationList(inmemcache.New[string, int64](time.Minute))),
+		auth.WithAPIKeys(authStore.APIKeyStore),
+		auth.WithWorkspaces(authStore.WorkspaceStore),
+	}
+	if cfg.OIDCIssuerURL != "" {
+		provider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		})
+		authOptions = append(authOptions, auth.WithOIDC(
+			provider,
+			authcache.NewOIDCLoginStore(inmemcache.New[string, auth.OIDCLogin](time.Minute)),
+			authStore.AccountIdentityStore,
+		))
+	}
 	authSvc := auth.NewService(
 		authStore.AccountStore,
 		authStore.AccountPasswordStore,
 		authStore.TxManager,
 		hasher,
 		tokenManager,
+		authOptions...,
 	)
 
 	authMiddleware := apigateway.NewAuthMiddleware(authSvc)
 
-	taskPub := task.NewEventPublisher(pub)
+	taskOutbox := tasksqlite.NewOutboxRepo(pool)
+	taskPub := task.NewEventPublisher(pub, task.WithOutbox(taskOutbox))
 	secret := []byte(cfg.TokenHMACSecret)
 	tokenStore := task.NewTokenStore(
 		inmemcache.New[string, storage.TokenMetadata](5*time.Minute),
 		secret,
 	)
+	quotaOpts, err := task.QuotaOptions(task.Quota{
+		MaxActiveTasks: cfg.QuotaMaxActiveTasks,
+		MaxStoredBytes: cfg.QuotaMaxStoredBytes,
+		MaxBytesPerDay: cfg.QuotaMaxBytesPerDay,
+	}, cfg.QuotaAccountOverrides)
+	if err != nil {
+		level.Error(logger).Log("msg", "invalid quota configuration", "err", err)
+		os.Exit(1)
+	}
 	taskSvc := task.NewService(taskRepo, *taskPub, tx,
-		task.WithTokenStore(tokenStore),
-		task.WithTaskSourceStore(storageBackend),
-		task.WithTaskSourcePresigner(storageBackend),
+		append([]task.ServiceOption{
+			task.WithTokenStore(tokenStore),
+			task.WithTaskSourceStore(storageBackend),
+			task.WithTaskSourcePresigner(storageBackend),
+			task.WithScheduleRepository(tasksqlite.NewScheduleRepo(pool)),
+			task.WithWebhookRepository(tasksqlite.NewWebhookRepo(pool)),
+			task.WithAuditRepository(tasksqlite.NewAuditRepo(pool)),
+			task.WithWebhookRetry(cfg.WebhookMaxAttempts, cfg.WebhookRetryDelay),
+			task.WithWebhookPrivateTargets(cfg.WebhookAllowPrivate),
+			task.WithMaxBatchSize(cfg.MaxBatchSize),
+			task.WithFileStore(storageBackend),
+			task.WithDefaultExpirationDays(cfg.TaskExpirationDays),
+			task.WithExpiredTaskPurge(cfg.TaskPurgeExpired),
+		}, quotaOpts...)...,
 	)
 
 	taskEventConsumer := tasktransport.NewEventConsumer(taskSvc, sub, func(_ context.Context, err error) {
 		level.Error(logger).Log("msg", "task event consumer error", "err", err)
 	})
 
-	downloadPub := download.NewDownloadEventPublisher(pub)
+	queueKey, err := base64.StdEncoding.DecodeString(cfg.DownloadQueueKey)
+	must(err)
+	queueCodec, err := download.NewRequestCodec(queueKey)
+	must(err)
 	dlSvc := download.NewService(
 		storageBackend,
 		downloadPub,
 		download.WithStorageType(storage.TypeLocal),
+		download.WithQueue(downloadsqlite.NewQueue(pool, queueCodec)),
+		download.WithPartialDir(filepath.Join(cfg.PocketDataDir, ".partial")),
 		download.WithErrorHandler(func(_ context.Context, err error) {
 			level.Error(logger).Log("msg", "download failed", "err", err)
 		}),
@@ -181,9 +193,9 @@ func main() {
 	dlSvc.RegisterDownloader("FTP", ftpDL)
 	dlSvc.RegisterDownloader("BITTORRENT", btDL)
 
-	consumer := downloadtransport.NewEventConsumer(dlSvc, sub, logger)
+	consumer := downloadtransport.NewEventConsumer(dlSvc, sub, logger, downloadtransport.WithControlAcks(downloadPub))
 
-	endpoints := apigateway.NewGatewayEndpoints(taskSvc, authMiddleware, authSvc)
+	endpoints := apigateway.NewGatewayEndpoints(taskSvc, authMiddleware, authSvc, logger)
 	handler := apigateway.NewHTTPHandlerWithDownload(endpoints, logger, storageBackend, tokenStore)
 
 	if cfg.PocketWebDir != "" {
@@ -208,6 +220,9 @@ func main() {
 		AllowCredentials: cfg.CORSAllowCredentials,
 		PreflightMaxAge:  cfg.CORSPreflightMaxAge,
 	})(httpHandler)
+	trustedProxies, err := middleware.ParseTrustedProxies(splitCSV(cfg.TrustedProxies))
+	must(err)
+	httpHandler = middleware.ClientIPHTTPMiddleware(trustedProxies)(httpHandler)
 	httpHandler = middleware.RecoveryHTTPMiddleware(logger)(httpHandler)
 	httpHandler = middleware.LoggingHTTPMiddleware(logger)(httpHandler)
 
@@ -224,6 +239,14 @@ func main() {
 			srv.Shutdown(ctxSh)
 		})
 	}
+	{
+		keysCtx, keysCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return signingKeys.Run(keysCtx)
+		}, func(error) {
+			keysCancel()
+		})
+	}
 	g.Add(func() error {
 		return taskEventConsumer.Start(ctx)
 	}, func(error) {})
@@ -234,64 +257,78 @@ func main() {
 		b.Close()
 	})
 
-	g.Add(func() error {
-		<-ctx.Done()
-		return ctx.Err()
-	}, func(error) {})
+	{
+		relay := task.NewOutboxRelay(taskOutbox, pub,
+			task.WithOutboxRelayInterval(cfg.OutboxInterval),
+			task.WithOutboxRelayLogger(logger),
+		)
+		outboxCtx, outboxCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return relay.Run(outboxCtx)
+		}, func(error) {
+			outboxCancel()
+		})
+	}
 
-	level.Info(logger).Log("exit", g.Run())
-}
+	if runner, ok := taskSvc.(task.WebhookRunner); ok {
+		webhookConsumer := tasktransport.NewWebhookConsumer(runner, sub, func(_ context.Context, err error) {
+			level.Error(logger).Log("msg", "webhook consumer error", "err", err)
+		})
+		g.Add(func() error {
+			return webhookConsumer.Start(ctx)
+		}, func(error) {})
 
-func runMigrations(pool *sqlitex.Pool) error {
-	conn := pool.Get(context.Background())
-	if conn == nil {
-		return context.DeadlineExceeded
+		dispatcher := task.NewWebhookDispatcher(runner,
+			task.WithWebhookDispatcherInterval(cfg.WebhookInterval),
+			task.WithWebhookDispatcherLogger(logger),
+		)
+		webhookCtx, webhookCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return dispatcher.Run(webhookCtx)
+		}, func(error) {
+			webhookCancel()
+		})
 	}
-	defer pool.Put(conn)
 
-	err := sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS accounts (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        account_name TEXT NOT NULL
-    );`, nil)
-	if err != nil {
-		return err
+	if runner, ok := taskSvc.(task.ScheduleRunner); ok {
+		scheduler := task.NewScheduler(runner,
+			task.WithSchedulerInterval(cfg.SchedulerInterval),
+			task.WithSchedulerLogger(logger),
+		)
+		schedCtx, schedCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return scheduler.Run(schedCtx)
+		}, func(error) {
+			schedCancel()
+		})
 	}
-	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS account_passwords (
-        of_account_id INTEGER PRIMARY KEY,
-        hashed_password TEXT NOT NULL
-    );`, nil)
-	if err != nil {
-		return err
+
+	if runner, ok := taskSvc.(task.ExpiryRunner); ok {
+		sweeper := task.NewExpirySweeper(runner,
+			task.WithExpirySweeperInterval(cfg.ExpirySweepInterval),
+			task.WithExpirySweeperLogger(logger),
+		)
+		expiryCtx, expiryCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return sweeper.Run(expiryCtx)
+		}, func(error) {
+			expiryCancel()
+		})
 	}
-	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS tasks (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        of_account_id INTEGER NOT NULL,
-        file_name TEXT NOT NULL,
-        source_url TEXT NOT NULL,
-        source_type TEXT NOT NULL,
-        headers TEXT DEFAULT '{}',
-        source_auth TEXT DEFAULT '{}',
-        storage_type TEXT NOT NULL,
-        storage_path TEXT NOT NULL,
-        checksum_type TEXT,
-        checksum_value TEXT,
-        concurrency INTEGER DEFAULT 4,
-        max_speed INTEGER,
-        max_retries INTEGER NOT NULL DEFAULT 3,
-        timeout INTEGER,
-        status TEXT NOT NULL,
-        progress REAL DEFAULT 0.0,
-        downloaded_bytes INTEGER DEFAULT 0,
-        total_bytes INTEGER DEFAULT 0,
-        error_message TEXT,
-        metadata TEXT DEFAULT '{}',
-        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        completed_at DATETIME,
-        last_accessed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-        expiration_days INTEGER DEFAULT 30
-    );`, nil)
-	return err
+
+	reapCtx, reapCancel := context.WithCancel(ctx)
+	g.Add(func() error {
+		return storageBackend.Run(reapCtx)
+	}, func(error) {
+		reapCancel()
+	})
+
+	g.Add(func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, func(error) {})
+
+	level.Info(logger).Log("exit", g.Run())
 }
 
 func splitCSV(value string) []string {
@@ -1,6 +1,10 @@
 package main
 
-import "github.com/kelseyhightower/envconfig"
+import (
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
 
 // Config holds all environment variables for the task service.
 //
@@ -29,30 +33,58 @@ import "github.com/kelseyhightower/envconfig"
 // MINIO_PRESIGN_PUBLIC_ENDPOINT
 // MINIO_PRESIGN_ACCESS_KEY
 // MINIO_PRESIGN_SECRET_KEY
+// QUOTA_MAX_ACTIVE_TASKS                         (default: 0, unlimited)
+// QUOTA_MAX_STORED_BYTES                         (default: 0, unlimited)
+// QUOTA_MAX_BYTES_PER_DAY                        (default: 0, unlimited)
+// QUOTA_ACCOUNT_OVERRIDES                        (JSON object keyed by account id)
+// SCHEDULER_INTERVAL                             (default: 30s)
+// WEBHOOK_INTERVAL                               (default: 5s)
+// WEBHOOK_MAX_ATTEMPTS                           (default: 8)
+// WEBHOOK_RETRY_DELAY                            (default: 10s)
+// WEBHOOK_ALLOW_PRIVATE                          (default: false)
+// MAX_BATCH_SIZE                                 (default: 500)
+// TASK_EXPIRATION_DAYS                           (default: 30, 0 never expires)
+// TASK_PURGE_EXPIRED                             (default: false)
+// EXPIRY_SWEEP_INTERVAL                          (default: 1h)
 type Config struct {
-	LogLevel                   string   `envconfig:"LOG_LEVEL"                     default:"debug"`
-	MySQLHost                  string   `envconfig:"MYSQL_HOST"                    default:"localhost"`
-	MySQLPort                  int      `envconfig:"MYSQL_PORT"                    default:"3306"`
-	MySQLUsername              string   `envconfig:"MYSQL_USERNAME"                default:"root"`
-	MySQLPassword              string   `envconfig:"MYSQL_PASSWORD"`
-	MySQLDatabase              string   `envconfig:"MYSQL_DATABASE"                default:"goload"`
-	RedisAddress               string   `envconfig:"REDIS_ADDRESS"                 default:"localhost:6379"`
-	RedisUsername              string   `envconfig:"REDIS_USERNAME"`
-	RedisPassword              string   `envconfig:"REDIS_PASSWORD"`
-	KafkaBrokers               []string `envconfig:"KAFKA_BROKERS"`
-	KafkaVersion               string   `envconfig:"KAFKA_VERSION"                 default:"4.0.0"`
-	KafkaMaxRetry              int      `envconfig:"KAFKA_MAX_RETRY"               default:"3"`
-	GRPCAddress                string   `envconfig:"GRPC_ADDRESS"                  default:"0.0.0.0:8082"`
-	TokenHMACSecret            string   `envconfig:"TOKEN_HMAC_SECRET"             default:"dev-secret-change-me"`
-	MinioEndpoint              string   `envconfig:"MINIO_ENDPOINT"`
-	MinioAccessKey             string   `envconfig:"MINIO_ACCESS_KEY"`
-	MinioSecretKey             string   `envconfig:"MINIO_SECRET_KEY"`
-	MinioBucket                string   `envconfig:"MINIO_BUCKET"                  default:"goload"`
-	MinioTaskSourcesBucket     string   `envconfig:"MINIO_TASK_SOURCES_BUCKET"     default:"task-sources"`
-	MinioUseSSL                bool     `envconfig:"MINIO_USE_SSL"                 default:"false"`
-	MinioPresignPublicEndpoint string   `envconfig:"MINIO_PRESIGN_PUBLIC_ENDPOINT"`
-	MinioPresignAccessKey      string   `envconfig:"MINIO_PRESIGN_ACCESS_KEY"`
-	MinioPresignSecretKey      string   `envconfig:"MINIO_PRESIGN_SECRET_KEY"`
+	LogLevel                   string        `envconfig:"LOG_LEVEL"                     default:"debug"`
+	MySQLHost                  string        `envconfig:"MYSQL_HOST"                    default:"localhost"`
+	MySQLPort                  int           `envconfig:"MYSQL_PORT"                    default:"3306"`
+	MySQLUsername              string        `envconfig:"MYSQL_USERNAME"                default:"root"`
+	MySQLPassword              string        `envconfig:"MYSQL_PASSWORD"`
+	MySQLDatabase              string        `envconfig:"MYSQL_DATABASE"                default:"goload"`
+	RedisAddress               string        `envconfig:"REDIS_ADDRESS"                 default:"localhost:6379"`
+	RedisUsername              string        `envconfig:"REDIS_USERNAME"`
+	RedisPassword              string        `envconfig:"REDIS_PASSWORD"`
+	KafkaBrokers               []string      `envconfig:"KAFKA_BROKERS"`
+	KafkaVersion               string        `envconfig:"KAFKA_VERSION"                 default:"4.0.0"`
+	KafkaMaxRetry              int           `envconfig:"KAFKA_MAX_RETRY"               default:"3"`
+	KafkaMaxDeliveries         int           `envconfig:"KAFKA_MAX_DELIVERIES"          default:"10"`
+	GRPCAddress                string        `envconfig:"GRPC_ADDRESS"                  default:"0.0.0.0:8082"`
+	TokenHMACSecret            string        `envconfig:"TOKEN_HMAC_SECRET"             default:"dev-secret-change-me"`
+	MinioEndpoint              string        `envconfig:"MINIO_ENDPOINT"`
+	MinioAccessKey             string        `envconfig:"MINIO_ACCESS_KEY"`
+	MinioSecretKey             string        `envconfig:"MINIO_SECRET_KEY"`
+	MinioBucket                string        `envconfig:"MINIO_BUCKET"                  default:"goload"`
+	MinioTaskSourcesBucket     string        `envconfig:"MINIO_TASK_SOURCES_BUCKET"     default:"task-sources"`
+	MinioUseSSL                bool          `envconfig:"MINIO_USE_SSL"                 default:"false"`
+	MinioPresignPublicEndpoint string        `envconfig:"MINIO_PRESIGN_PUBLIC_ENDPOINT"`
+	MinioPresignAccessKey      string        `envconfig:"MINIO_PRESIGN_ACCESS_KEY"`
+	MinioPresignSecretKey      string        `envconfig:"MINIO_PRESIGN_SECRET_KEY"`
+	QuotaMaxActiveTasks        int64         `envconfig:"QUOTA_MAX_ACTIVE_TASKS"        default:"0"`
+	QuotaMaxStoredBytes        int64         `envconfig:"QUOTA_MAX_STORED_BYTES"        default:"0"`
+	QuotaMaxBytesPerDay        int64         `envconfig:"QUOTA_MAX_BYTES_PER_DAY"       default:"0"`
+	QuotaAccountOverrides      string        `envconfig:"QUOTA_ACCOUNT_OVERRIDES"`
+	SchedulerInterval          time.Duration `envconfig:"SCHEDULER_INTERVAL"        default:"30s"`
+	WebhookInterval            time.Duration `envconfig:"WEBHOOK_INTERVAL"          default:"5s"`
+	WebhookMaxAttempts         int           `envconfig:"WEBHOOK_MAX_ATTEMPTS"      default:"8"`
+	WebhookRetryDelay          time.Duration `envconfig:"WEBHOOK_RETRY_DELAY"       default:"10s"`
+	WebhookAllowPrivate        bool          `envconfig:"WEBHOOK_ALLOW_PRIVATE"     default:"false"`
+	MaxBatchSize               int           `envconfig:"MAX_BATCH_SIZE"            default:"500"`
+	OutboxInterval             time.Duration `envconfig:"OUTBOX_INTERVAL"           default:"1s"`
+	TaskExpirationDays         int32         `envconfig:"TASK_EXPIRATION_DAYS"      default:"30"`
+	TaskPurgeExpired           bool          `envconfig:"TASK_PURGE_EXPIRED"        default:"false"`
+	ExpirySweepInterval        time.Duration `envconfig:"EXPIRY_SWEEP_INTERVAL"     default:"1h"`
 }
 
 func loadConfig() (*Config, error) {
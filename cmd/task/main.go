@@ -96,8 +96,10 @@ func main() {
 		}
 	}
 
-	// event publisher wrapper
-	dep := taskpkg.NewEventPublisher(pub)
+	// event publisher wrapper; events are stored in the outbox with the task
+	// writes they report and published by the outbox relay.
+	outbox := taskmysql.NewOutboxRepo(db)
+	dep := taskpkg.NewEventPublisher(pub, taskpkg.WithOutbox(outbox))
 
 	// Optional: presigner (MinIO) and token store (Redis) for GenerateDownloadURL
 	var svcOpts []taskpkg.ServiceOption
@@ -123,6 +125,20 @@ func main() {
 		}
 		svcOpts = append(svcOpts, taskpkg.WithTaskSourceStore(sourceStore))
 
+		// The stored files of expired tasks are deleted with them.
+		fileStore, err := storagepkg.NewMinioBackend(
+			config.MinioEndpoint,
+			config.MinioAccessKey,
+			config.MinioSecretKey,
+			config.MinioUseSSL,
+			config.MinioBucket,
+		)
+		if err != nil {
+			level.Error(logger).Log("msg", "failed to create minio backend", "bucket", config.MinioBucket, "err", err)
+			os.Exit(1)
+		}
+		svcOpts = append(svcOpts, taskpkg.WithFileStore(fileStore))
+
 		presignAccessKey := config.MinioAccessKey
 		presignSecretKey := config.MinioSecretKey
 		if config.MinioPresignAccessKey != "" {
@@ -172,6 +188,27 @@ func main() {
 	} else {
 		level.Warn(logger).Log("msg", "taskservice.storage.minio.endpoint not configured — presign disabled")
 	}
+
+	quotaOpts, err := taskpkg.QuotaOptions(taskpkg.Quota{
+		MaxActiveTasks: config.QuotaMaxActiveTasks,
+		MaxStoredBytes: config.QuotaMaxStoredBytes,
+		MaxBytesPerDay: config.QuotaMaxBytesPerDay,
+	}, config.QuotaAccountOverrides)
+	if err != nil {
+		level.Error(logger).Log("msg", "invalid quota configuration", "err", err)
+		os.Exit(1)
+	}
+	svcOpts = append(svcOpts, quotaOpts...)
+	svcOpts = append(svcOpts, taskpkg.WithScheduleRepository(taskmysql.NewScheduleRepo(db)))
+	svcOpts = append(svcOpts,
+		taskpkg.WithWebhookRepository(taskmysql.NewWebhookRepo(db)),
+		taskpkg.WithAuditRepository(taskmysql.NewAuditRepo(db)),
+		taskpkg.WithWebhookRetry(config.WebhookMaxAttempts, config.WebhookRetryDelay),
+		taskpkg.WithWebhookPrivateTargets(config.WebhookAllowPrivate),
+		taskpkg.WithMaxBatchSize(config.MaxBatchSize),
+		taskpkg.WithDefaultExpirationDays(config.TaskExpirationDays),
+		taskpkg.WithExpiredTaskPurge(config.TaskPurgeExpired),
+	)
 	{
 		redisClient := redis.NewClient(&redis.Options{
 			Addr:     config.RedisAddress,
@@ -201,16 +238,21 @@ func main() {
 	taskpb.RegisterTaskServiceServer(grpcServer, pbServer)
 
 	// Kafka subscriber for consuming download-service events (task.completed, task.failed, progress)
-	var taskEventConsumer *tasktransport.EventConsumer
+	var (
+		taskEventConsumer *tasktransport.EventConsumer
+		webhookConsumer   *tasktransport.WebhookConsumer
+	)
 	if len(config.KafkaBrokers) > 0 {
 		kv2, err := sarama.ParseKafkaVersion(config.KafkaVersion)
 		if err != nil {
 			kv2 = sarama.V3_6_0_0
 		}
 		subCfg := &kafkapkg.SubscriberConfig{
-			Brokers:       config.KafkaBrokers,
-			ConsumerGroup: "task-service-group",
-			Version:       kv2,
+			Brokers:             config.KafkaBrokers,
+			ConsumerGroup:       "task-service-group",
+			Version:             kv2,
+			MaxDeliveries:       config.KafkaMaxDeliveries,
+			DeadLetterPublisher: pub,
 		}
 		taskSub, err := kafkapkg.NewSubscriber(subCfg, kafkapkg.WithLog(logger))
 		if err != nil {
@@ -220,6 +262,25 @@ func main() {
 				level.Error(logger).Log("msg", "task event consumer error", "err", err)
 			})
 		}
+
+		// Webhooks consume the task events in a group of their own so that
+		// they see every event the task service itself consumes.
+		if runner, ok := svc.(taskpkg.WebhookRunner); ok {
+			webhookSub, err := kafkapkg.NewSubscriber(&kafkapkg.SubscriberConfig{
+				Brokers:             config.KafkaBrokers,
+				ConsumerGroup:       "task-webhooks-group",
+				Version:             kv2,
+				MaxDeliveries:       config.KafkaMaxDeliveries,
+				DeadLetterPublisher: pub,
+			}, kafkapkg.WithLog(logger))
+			if err != nil {
+				level.Error(logger).Log("msg", "failed to create kafka subscriber for webhooks", "err", err)
+			} else {
+				webhookConsumer = tasktransport.NewWebhookConsumer(runner, webhookSub, func(ctx context.Context, err error) {
+					level.Error(logger).Log("msg", "webhook consumer error", "err", err)
+				})
+			}
+		}
 	}
 
 	var g run.Group
@@ -243,7 +304,18 @@ func main() {
 			)
 			return grpcServer.Serve(lis)
 		}, func(error) {
-			grpcServer.GracefulStop()
+			// WatchTasks streams only end when their clients go away; do not
+			// wait for them forever.
+			stopped := make(chan struct{})
+			go func() {
+				grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				grpcServer.Stop()
+			}
 			_ = lis.Close()
 		})
 	}
@@ -256,6 +328,65 @@ func main() {
 		})
 	}
 
+	if webhookConsumer != nil {
+		g.Add(func() error {
+			return webhookConsumer.Start(ctx)
+		}, func(error) {
+		})
+	}
+
+	{
+		relay := taskpkg.NewOutboxRelay(outbox, pub,
+			taskpkg.WithOutboxRelayInterval(config.OutboxInterval),
+			taskpkg.WithOutboxRelayLogger(logger),
+		)
+		outboxCtx, outboxCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return relay.Run(outboxCtx)
+		}, func(error) {
+			outboxCancel()
+		})
+	}
+
+	if runner, ok := svc.(taskpkg.WebhookRunner); ok {
+		dispatcher := taskpkg.NewWebhookDispatcher(runner,
+			taskpkg.WithWebhookDispatcherInterval(config.WebhookInterval),
+			taskpkg.WithWebhookDispatcherLogger(logger),
+		)
+		webhookCtx, webhookCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return dispatcher.Run(webhookCtx)
+		}, func(error) {
+			webhookCancel()
+		})
+	}
+
+	if runner, ok := svc.(taskpkg.ScheduleRunner); ok {
+		scheduler := taskpkg.NewScheduler(runner,
+			taskpkg.WithSchedulerInterval(config.SchedulerInterval),
+			taskpkg.WithSchedulerLogger(logger),
+		)
+		schedCtx, schedCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return scheduler.Run(schedCtx)
+		}, func(error) {
+			schedCancel()
+		})
+	}
+
+	if runner, ok := svc.(taskpkg.ExpiryRunner); ok {
+		sweeper := taskpkg.NewExpirySweeper(runner,
+			taskpkg.WithExpirySweeperInterval(config.ExpirySweepInterval),
+			taskpkg.WithExpirySweeperLogger(logger),
+		)
+		expiryCtx, expiryCancel := context.WithCancel(ctx)
+		g.Add(func() error {
+			return sweeper.Run(expiryCtx)
+		}, func(error) {
+			expiryCancel()
+		})
+	}
+
 	{
 		g.Add(func() error {
 			<-ctx.Done()
@@ -0,0 +1,160 @@
+package apigateway
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/samber/lo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/task"
+)
+
+// auditFn returns the audit events of one call, one per task it acted on.
+// Events without an outcome take the outcome of err.
+type auditFn func(ctx context.Context, request, response any, err error) []*task.AuditEvent
+
+// AuditMiddleware records the actions taken through an endpoint in the audit
+// log of svc. It runs after authentication, so the actor is known, and
+// before authorization, so denied attempts are recorded too. Failures to
+// record are logged; they do not fail the request.
+func AuditMiddleware(svc task.Service, logger log.Logger, eventsFn auditFn) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			// RequireTaskRoleMiddleware fills in the owner of the task, which
+			// cannot be looked up after a delete.
+			target := &task.AuditEvent{}
+			response, err := next(context.WithValue(ctx, auditTargetKey, target), request)
+
+			actorID, _ := UserIDFromContext(ctx)
+			apiKeyID, _ := ctx.Value(apiKeyIDKey).(uint64)
+			sourceIP, _ := ctx.Value(sourceIPKey).(string)
+			outcome, message := auditOutcome(err)
+			for _, e := range eventsFn(ctx, request, response, err) {
+				e.ActorAccountID = actorID
+				e.ActorAPIKeyID = apiKeyID
+				e.SourceIP = sourceIP
+				if e.Outcome == "" {
+					e.Outcome, e.Message = outcome, message
+				}
+				if e.OfAccountID == 0 && e.WorkspaceID == 0 {
+					e.OfAccountID, e.WorkspaceID = target.OfAccountID, target.WorkspaceID
+				}
+				if err := svc.RecordAuditEvent(ctx, e); err != nil {
+					level.Warn(logger).Log("msg", "failed to record audit event", "action", e.Action, "task_id", e.TaskID, "err", err)
+				}
+			}
+			return response, err
+		}
+	}
+}
+
+// auditOutcome maps the error of a call to its outcome and the message the
+// client was given.
+func auditOutcome(err error) (task.AuditOutcome, string) {
+	if err == nil {
+		return task.AuditSucceeded, ""
+	}
+	message := err.Error()
+	if svcErr := errors.AsError(err); svcErr != nil {
+		message = svcErr.Message
+	} else if s, ok := status.FromError(err); ok {
+		message = s.Message()
+	}
+	if errors.IsError(err, errors.ErrCodePermissionDenied) || status.Code(err) == codes.PermissionDenied {
+		return task.AuditDenied, message
+	}
+	return task.AuditFailed, message
+}
+
+// auditTask audits an action on the task idFn(request).
+func auditTask(action task.AuditAction, idFn func(req any) uint64) auditFn {
+	return func(_ context.Context, request, _ any, _ error) []*task.AuditEvent {
+		return []*task.AuditEvent{{Action: action, TaskID: idFn(request)}}
+	}
+}
+
+// auditOwner returns the owner of the tasks a request creates or selects:
+// the workspace when it names one, the caller otherwise.
+func auditOwner(ctx context.Context, workspaceID *uint64) (ofAccountID, wsID uint64) {
+	if lo.FromPtr(workspaceID) != 0 {
+		return 0, *workspaceID
+	}
+	userID, _ := UserIDFromContext(ctx)
+	return userID, 0
+}
+
+func auditCreateTask(ctx context.Context, request, response any, err error) []*task.AuditEvent {
+	e := &task.AuditEvent{Action: task.AuditTaskCreate}
+	e.OfAccountID, e.WorkspaceID = auditOwner(ctx, request.(*CreateTaskRequest).WorkspaceId)
+	if err == nil {
+		e.TaskID = lo.FromPtr(response.(*CreateTaskResponse).Task.Id)
+	}
+	return []*task.AuditEvent{e}
+}
+
+func auditCreateTasks(ctx context.Context, request, response any, err error) []*task.AuditEvent {
+	ofAccountID, workspaceID := auditOwner(ctx, request.(*CreateTasksRequest).WorkspaceId)
+	if err != nil {
+		return []*task.AuditEvent{{Action: task.AuditTaskCreate, OfAccountID: ofAccountID, WorkspaceID: workspaceID}}
+	}
+	var evs []*task.AuditEvent
+	for _, t := range lo.FromPtr(response.(*CreateTasksResponse).Tasks) {
+		evs = append(evs, &task.AuditEvent{
+			Action:      task.AuditTaskCreate,
+			TaskID:      lo.FromPtr(t.Id),
+			OfAccountID: ofAccountID,
+			WorkspaceID: workspaceID,
+		})
+	}
+	return evs
+}
+
+var bulkAuditActions = map[task.BulkAction]task.AuditAction{
+	task.BulkActionPause:  task.AuditTaskPause,
+	task.BulkActionResume: task.AuditTaskResume,
+	task.BulkActionCancel: task.AuditTaskCancel,
+	task.BulkActionDelete: task.AuditTaskDelete,
+}
+
+// auditBulkTasks audits a bulk action per task, with the outcome the task
+// had. Requests with an unknown action are not recorded.
+func auditBulkTasks(ctx context.Context, request, response any, err error) []*task.AuditEvent {
+	r := request.(*BulkTasksRequest)
+	action, ok := bulkAuditActions[task.BulkAction(strings.ToUpper(r.Action))]
+	if !ok {
+		return nil
+	}
+	ofAccountID, workspaceID := auditOwner(ctx, r.WorkspaceId)
+	if err != nil {
+		return []*task.AuditEvent{{Action: action, OfAccountID: ofAccountID, WorkspaceID: workspaceID}}
+	}
+
+	resp := response.(*BulkTasksResponse)
+	var evs []*task.AuditEvent
+	for _, id := range lo.FromPtr(resp.Succeeded) {
+		evs = append(evs, &task.AuditEvent{
+			Action:      action,
+			TaskID:      id,
+			OfAccountID: ofAccountID,
+			WorkspaceID: workspaceID,
+			Outcome:     task.AuditSucceeded,
+		})
+	}
+	for _, f := range lo.FromPtr(resp.Failed) {
+		evs = append(evs, &task.AuditEvent{
+			Action:      action,
+			TaskID:      lo.FromPtr(f.TaskId),
+			OfAccountID: ofAccountID,
+			WorkspaceID: workspaceID,
+			Outcome:     task.AuditFailed,
+			Message:     lo.FromPtr(f.Message),
+		})
+	}
+	return evs
+}
@@ -3,15 +3,20 @@ package apigateway
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/log"
 	"github.com/samber/lo"
 
 	"github.com/yuisofull/goload/internal/apigateway/gen"
 	"github.com/yuisofull/goload/internal/auth"
 	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/pkg/crypto/rsa"
 )
 
 type ListTasksRequest = gen.ListTasksParams
@@ -49,6 +54,8 @@ func taskToAPI(t *task.Task) *Task {
 		ChecksumType:    checksumType,
 		ChecksumValue:   checksumValue,
 		Status:          lo.ToPtr(t.Status.String()),
+		Priority:        lo.ToPtr(t.Priority.String()),
+		ScheduleId:      lo.EmptyableToPtr(t.ScheduleID),
 		Progress:        lo.ToPtr(float32(lo.FromPtr(progress))),
 		DownloadedBytes: downloadedBytes,
 		TotalBytes:      totalBytes,
@@ -57,24 +64,67 @@ func taskToAPI(t *task.Task) *Task {
 		CreatedAt:       &t.CreatedAt,
 		UpdatedAt:       &t.UpdatedAt,
 		CompletedAt:     t.CompletedAt,
+		WorkspaceId:     lo.EmptyableToPtr(t.WorkspaceID),
+		ExpirationDays:  lo.EmptyableToPtr(t.ExpirationDays),
+		ExpiresAt:       t.ExpiresAt(),
 	}
 }
 
 type GatewayEndpoints struct {
-	CreateTaskEndpoint          endpoint.Endpoint
-	GetTaskEndpoint             endpoint.Endpoint
-	ListTasksEndpoint           endpoint.Endpoint
-	DeleteTaskEndpoint          endpoint.Endpoint
-	PauseTaskEndpoint           endpoint.Endpoint
-	ResumeTaskEndpoint          endpoint.Endpoint
-	CancelTaskEndpoint          endpoint.Endpoint
-	RetryTaskEndpoint           endpoint.Endpoint
-	CheckFileExistsEndpoint     endpoint.Endpoint
-	GetTaskProgressEndpoint     endpoint.Endpoint
-	GenerateDownloadURLEndpoint endpoint.Endpoint
+	CreateTaskEndpoint            endpoint.Endpoint
+	GetTaskEndpoint               endpoint.Endpoint
+	ListTasksEndpoint             endpoint.Endpoint
+	DeleteTaskEndpoint            endpoint.Endpoint
+	PauseTaskEndpoint             endpoint.Endpoint
+	ResumeTaskEndpoint            endpoint.Endpoint
+	CancelTaskEndpoint            endpoint.Endpoint
+	RetryTaskEndpoint             endpoint.Endpoint
+	ExtendTaskRetentionEndpoint   endpoint.Endpoint
+	CheckFileExistsEndpoint       endpoint.Endpoint
+	GetTaskProgressEndpoint       endpoint.Endpoint
+	GenerateDownloadURLEndpoint   endpoint.Endpoint
+	GetUsageEndpoint              endpoint.Endpoint
+	ListSchedulesEndpoint         endpoint.Endpoint
+	GetScheduleEndpoint           endpoint.Endpoint
+	UpdateScheduleEndpoint        endpoint.Endpoint
+	DeleteScheduleEndpoint        endpoint.Endpoint
+	WatchTasksEndpoint            endpoint.Endpoint
+	CreateWebhookEndpoint         endpoint.Endpoint
+	ListWebhooksEndpoint          endpoint.Endpoint
+	GetWebhookEndpoint            endpoint.Endpoint
+	UpdateWebhookEndpoint         endpoint.Endpoint
+	DeleteWebhookEndpoint         endpoint.Endpoint
+	ListWebhookDeliveriesEndpoint endpoint.Endpoint
+	CreateTasksEndpoint           endpoint.Endpoint
+	BulkTasksEndpoint             endpoint.Endpoint
+	// Audit log endpoints
+	ListAuditEventsEndpoint   endpoint.Endpoint
+	ExportAuditEventsEndpoint endpoint.Endpoint
+	// RecordAuditEventEndpoint is not routed; the /download handler records
+	// downloads through it.
+	RecordAuditEventEndpoint endpoint.Endpoint
 	// Auth endpoints (public)
 	AuthCreateEndpoint  endpoint.Endpoint
 	AuthSessionEndpoint endpoint.Endpoint
+	AuthRefreshEndpoint endpoint.Endpoint
+	// OpenID Connect login endpoints (public)
+	BeginOIDCLoginEndpoint endpoint.Endpoint
+	OIDCCallbackEndpoint   endpoint.Endpoint
+	// Auth endpoints (authenticated)
+	AuthLogoutEndpoint         endpoint.Endpoint
+	AuthRevokeSessionsEndpoint endpoint.Endpoint
+	// API key endpoints (session tokens only)
+	CreateAPIKeyEndpoint endpoint.Endpoint
+	ListAPIKeysEndpoint  endpoint.Endpoint
+	RevokeAPIKeyEndpoint endpoint.Endpoint
+	// JWKSEndpoint publishes the token signing keys (public)
+	JWKSEndpoint endpoint.Endpoint
+	// Workspace endpoints; membership changes take a session token
+	CreateWorkspaceEndpoint       endpoint.Endpoint
+	ListWorkspacesEndpoint        endpoint.Endpoint
+	ListWorkspaceMembersEndpoint  endpoint.Endpoint
+	SetWorkspaceMemberEndpoint    endpoint.Endpoint
+	RemoveWorkspaceMemberEndpoint endpoint.Endpoint
 }
 
 type CreateTaskRequest = gen.CreateTaskRequest
@@ -110,197 +160,1192 @@ type (
 	RetryTaskResponse = gen.SuccessResponse
 )
 
+type ExtendTaskRetentionRequest = gen.ExtendTaskRetentionRequest
+
+type ExtendTaskRetentionResponse = gen.GetTaskResponse
+
+type (
+	CheckFileExistsRequest  = gen.CheckFileExistsParams
+	CheckFileExistsResponse = gen.CheckFileExistsResponse
+)
+
+type (
+	GetTaskProgressRequest  = gen.GetTaskProgressParams
+	GetTaskProgressResponse = gen.GetTaskProgressResponse
+)
+
+type GenerateDownloadURLRequest = gen.GenerateDownloadURLRequest
+
+type GenerateDownloadURLResponse = gen.GenerateDownloadURLResponse
+
+// MakeGenerateDownloadURLEndpoint calls the task service GenerateDownloadURL,
+// which internally handles presigning (direct MinIO URL) or token fallback.
+// Access to the task is checked by RequireTaskRoleMiddleware.
+func MakeGenerateDownloadURLEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GenerateDownloadURLRequest)
+
+		ttl := time.Duration(req.TtlSeconds) * time.Second
+		if ttl <= 0 {
+			ttl = time.Hour
+		}
+
+		urlStr, direct, err := svc.GenerateDownloadURL(ctx, req.TaskId, ttl, req.OneTime)
+		if err != nil {
+			return nil, err
+		}
+		return &GenerateDownloadURLResponse{Url: &urlStr, Direct: &direct}, nil
+	}
+}
+
+type GetUsageResponse = gen.GetUsageResponse
+
+// MakeGetUsageEndpoint reports the usage and quota of the authenticated account.
+func MakeGetUsageEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		usage, err := svc.GetUsage(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &GetUsageResponse{
+			ActiveTasks:    &usage.ActiveTasks,
+			StoredBytes:    &usage.StoredBytes,
+			BytesToday:     &usage.BytesToday,
+			MaxActiveTasks: &usage.Quota.MaxActiveTasks,
+			MaxStoredBytes: &usage.Quota.MaxStoredBytes,
+			MaxBytesPerDay: &usage.Quota.MaxBytesPerDay,
+		}, nil
+	}
+}
+
+type (
+	WatchTasksRequest = gen.WatchTasksParams
+	TaskEvent         = gen.TaskEvent
+)
+
+// WatchTasksResponse carries the events streamed to the client. The channel
+// is closed when the watch ends.
+type WatchTasksResponse struct {
+	Events <-chan *task.TaskEvent
+}
+
+// MakeWatchTasksEndpoint opens a watch on the personal tasks of the
+// authenticated account, or on the tasks of the requested workspace. The
+// watch ends with the request context.
+func MakeWatchTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*WatchTasksRequest)
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		events, err := svc.WatchTasks(ctx, userID, lo.FromPtr(req.WorkspaceId), lo.FromPtr(req.LastEventID))
+		if err != nil {
+			return nil, err
+		}
+		return &WatchTasksResponse{Events: events}, nil
+	}
+}
+
+// taskEventToAPI maps a task event to the payload of a server-sent event.
+func taskEventToAPI(ev *task.TaskEvent) *TaskEvent {
+	out := &TaskEvent{
+		Id:           &ev.ID,
+		Type:         lo.ToPtr(string(ev.Type)),
+		TaskId:       lo.EmptyableToPtr(ev.TaskID),
+		Status:       lo.EmptyableToPtr(ev.Status.String()),
+		ErrorMessage: ev.ErrorMessage,
+		Time:         &ev.Time,
+	}
+	if ev.Progress != nil {
+		out.Progress = lo.ToPtr(float32(ev.Progress.Progress))
+		out.DownloadedBytes = &ev.Progress.DownloadedBytes
+		out.TotalBytes = &ev.Progress.TotalBytes
+	}
+	return out
+}
+
+func MakeListTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListTasksRequest)
+
+		// Ensure user is authenticated. The auth middleware should have
+		// populated the account ID into the context by the time this
+		// endpoint executes.
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		var offset, limit uint64
+		if req.Offset != nil {
+			offset = *req.Offset
+		}
+		if req.Limit != nil {
+			limit = *req.Limit
+		}
+
+		var sortAsc bool
+		switch strings.ToLower(lo.FromPtr(req.SortOrder)) {
+		case "", "desc":
+		case "asc":
+			sortAsc = true
+		default:
+			return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "sort_order must be asc or desc"}
+		}
+
+		params := task.ListTasksParam{
+			Filter: taskFilterFromAPI(userID, &gen.TaskFilter{
+				Status:      req.Status,
+				Tags:        req.Tags,
+				SourceType:  req.SourceType,
+				CreatedFrom: req.CreatedFrom,
+				CreatedTo:   req.CreatedTo,
+				Search:      req.Search,
+			}),
+			Offset:  int32(offset),
+			Limit:   int32(limit),
+			SortBy:  lo.FromPtr(req.SortBy),
+			SortAsc: sortAsc,
+			Cursor:  lo.FromPtr(req.Cursor),
+		}
+		params.Filter.WorkspaceID = lo.FromPtr(req.WorkspaceId)
+		// Validated here because unknown values do not survive the gRPC enums.
+		if err := params.Filter.Validate(); err != nil {
+			return nil, err
+		}
+
+		result, err := svc.ListTasks(ctx, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		count := result.Total
+		tasks := lo.Map(result.Tasks, func(t *task.Task, _ int) Task { return *taskToAPI(t) })
+		return &ListTasksResponse{
+			NextCursor: lo.EmptyableToPtr(result.NextCursor),
+			Tasks:      &tasks,
+			TotalCount: &count,
+		}, nil
+	}
+}
+
+// MakeCreateTaskEndpoint allows creating a new task. The endpoint enforces that the
+// authenticated user (from context) is set as the task owner.
+func MakeCreateTaskEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateTaskRequest)
+
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		param := createTaskParam(userID, req)
+		t, err := svc.CreateTask(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		return &CreateTaskResponse{Task: taskToAPI(t)}, nil
+	}
+}
+
+// createTaskParam maps an API create request of the user to the service
+// param. Uploaded torrent files are passed on as a data URL.
+func createTaskParam(userID uint64, req *CreateTaskRequest) *task.CreateTaskParam {
+	var metadata map[string]any
+	if req.Metadata != nil {
+		metadata = *req.Metadata
+	}
+
+	param := &task.CreateTaskParam{
+		OfAccountID:    userID,
+		FileName:       req.FileName,
+		SourceURL:      req.SourceUrl,
+		SourceType:     task.ToSourceType(req.SourceType),
+		Priority:       task.Priority(lo.FromPtr(req.Priority)),
+		Metadata:       metadata,
+		WorkspaceID:    lo.FromPtr(req.WorkspaceId),
+		ExpirationDays: lo.FromPtr(req.ExpirationDays),
+		ReuseExisting:  lo.FromPtr(req.ReuseExisting),
+	}
+	if req.Schedule != nil {
+		param.Schedule = &task.ScheduleSpec{
+			Cron:    lo.FromPtr(req.Schedule.Cron),
+			StartAt: req.Schedule.StartAt,
+		}
+	}
+
+	if param.SourceType == task.SourceBitTorrent && metadata != nil {
+		if raw, ok := metadata["torrent_file_base64"]; ok {
+			if s, ok := raw.(string); ok && s != "" {
+				param.SourceURL = fmt.Sprintf("data:application/x-bittorrent;base64,%s", s)
+			}
+			delete(metadata, "torrent_file_base64")
+		}
+	}
+	if req.ChecksumType != nil || req.ChecksumValue != nil {
+		var ctype, cval string
+		if req.ChecksumType != nil {
+			ctype = *req.ChecksumType
+		}
+		if req.ChecksumValue != nil {
+			cval = *req.ChecksumValue
+		}
+		param.Checksum = &task.ChecksumInfo{ChecksumType: ctype, ChecksumValue: cval}
+	}
+	return param
+}
+
+type (
+	CreateTasksRequest  = gen.CreateTasksRequest
+	CreateTasksResponse = gen.CreateTasksResponse
+)
+
+// MakeCreateTasksEndpoint creates a batch of tasks owned by the authenticated
+// user, shared with a workspace when one is given.
+func MakeCreateTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateTasksRequest)
+
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		param := &task.CreateTasksParam{
+			OfAccountID: userID,
+			URLList:     lo.FromPtr(req.UrlList),
+			Metalink:    []byte(lo.FromPtr(req.Metalink)),
+			Priority:    task.Priority(lo.FromPtr(req.Priority)),
+			WorkspaceID: lo.FromPtr(req.WorkspaceId),
+		}
+		for i := range lo.FromPtr(req.Tasks) {
+			param.Tasks = append(param.Tasks, createTaskParam(userID, &(*req.Tasks)[i]))
+		}
+
+		created, err := svc.CreateTasks(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		tasks := lo.Map(created, func(t *task.Task, _ int) Task { return *taskToAPI(t) })
+		return &CreateTasksResponse{Tasks: &tasks}, nil
+	}
+}
+
+type (
+	BulkTasksRequest  = gen.BulkTasksRequest
+	BulkTasksResponse = gen.BulkTasksResponse
+)
+
+// MakeBulkTasksEndpoint applies an action to tasks of the authenticated user,
+// or of a workspace, selected by id or by filter.
+func MakeBulkTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*BulkTasksRequest)
+
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		filter := taskFilterFromAPI(userID, req.Filter)
+		if filter != nil {
+			if err := filter.Validate(); err != nil {
+				return nil, err
+			}
+		}
+		out, err := svc.BulkTasks(ctx, &task.BulkTaskParam{
+			OfAccountID: userID,
+			Action:      task.BulkAction(strings.ToUpper(req.Action)),
+			IDs:         lo.FromPtr(req.Ids),
+			Filter:      filter,
+			WorkspaceID: lo.FromPtr(req.WorkspaceId),
+		})
+		if err != nil {
+			return nil, err
+		}
+		failed := lo.Map(out.Failed, func(f *task.BulkTaskFailure, _ int) gen.BulkTaskFailure {
+			return gen.BulkTaskFailure{TaskId: &f.TaskID, Message: &f.Message}
+		})
+		succeeded := out.Succeeded
+		if succeeded == nil {
+			succeeded = []uint64{}
+		}
+		return &BulkTasksResponse{Succeeded: &succeeded, Failed: &failed}, nil
+	}
+}
+
+// taskFilterFromAPI maps an API task filter of the user to the service filter.
+func taskFilterFromAPI(userID uint64, f *gen.TaskFilter) *task.TaskFilter {
+	if f == nil {
+		return nil
+	}
+	filter := &task.TaskFilter{
+		OfAccountID: userID,
+		Tags:        lo.FromPtr(f.Tags),
+		Search:      lo.FromPtr(f.Search),
+	}
+	for _, st := range lo.FromPtr(f.Status) {
+		filter.Status = append(filter.Status, task.TaskStatus(strings.ToUpper(st)))
+	}
+	for _, st := range lo.FromPtr(f.SourceType) {
+		filter.SourceType = append(filter.SourceType, task.SourceType(strings.ToUpper(st)))
+	}
+	if f.CreatedFrom != nil || f.CreatedTo != nil {
+		filter.CreatedAt = &task.TimeRange{From: f.CreatedFrom, To: f.CreatedTo}
+	}
+	return filter
+}
+
+func MakeGetTaskEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetTaskRequest)
+		t, err := svc.GetTask(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		return &GetTaskResponse{Task: taskToAPI(t)}, nil
+	}
+}
+
+type Schedule = gen.Schedule
+
+// scheduleToAPI maps a domain schedule to the API Schedule response.
+func scheduleToAPI(s *task.Schedule) *Schedule {
+	if s == nil {
+		return nil
+	}
+	out := &Schedule{
+		Id:         &s.ID,
+		Cron:       lo.EmptyableToPtr(s.Cron),
+		StartAt:    s.StartAt,
+		Enabled:    &s.Enabled,
+		NextRunAt:  s.NextRunAt,
+		LastRunAt:  s.LastRunAt,
+		NextTaskId: lo.EmptyableToPtr(s.NextTaskID),
+		CreatedAt:  &s.CreatedAt,
+		UpdatedAt:  &s.UpdatedAt,
+	}
+	if s.Template != nil {
+		out.FileName = &s.Template.FileName
+		out.SourceUrl = &s.Template.SourceURL
+		out.SourceType = lo.ToPtr(s.Template.SourceType.String())
+		out.Priority = lo.ToPtr(s.Template.Priority.String())
+	}
+	return out
+}
+
+type (
+	ListSchedulesRequest  = gen.ListSchedulesParams
+	ListSchedulesResponse = gen.ListSchedulesResponse
+)
+
+type (
+	GetScheduleRequest    = gen.GetScheduleParams
+	UpdateScheduleRequest = gen.UpdateScheduleRequest
+	ScheduleResponse      = gen.ScheduleResponse
+)
+
+type (
+	DeleteScheduleRequest  = gen.DeleteScheduleParams
+	DeleteScheduleResponse = gen.SuccessResponse
+)
+
+// MakeListSchedulesEndpoint lists the schedules of the authenticated account.
+func MakeListSchedulesEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListSchedulesRequest)
+
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		schedules, err := svc.ListSchedules(
+			ctx,
+			userID,
+			int32(lo.FromPtr(req.Limit)),
+			int32(lo.FromPtr(req.Offset)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		out := lo.Map(schedules, func(s *task.Schedule, _ int) Schedule { return *scheduleToAPI(s) })
+		return &ListSchedulesResponse{Schedules: &out}, nil
+	}
+}
+
+func MakeGetScheduleEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetScheduleRequest)
+		s, err := svc.GetSchedule(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		return &ScheduleResponse{Schedule: scheduleToAPI(s)}, nil
+	}
+}
+
+func MakeUpdateScheduleEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*UpdateScheduleRequest)
+		s, err := svc.UpdateSchedule(ctx, &task.UpdateScheduleParam{
+			ID:      req.Id,
+			Cron:    req.Cron,
+			StartAt: req.StartAt,
+			Enabled: req.Enabled,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &ScheduleResponse{Schedule: scheduleToAPI(s)}, nil
+	}
+}
+
+func MakeDeleteScheduleEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*DeleteScheduleRequest)
+		if err := svc.DeleteSchedule(ctx, req.Id); err != nil {
+			return nil, err
+		}
+		return &DeleteScheduleResponse{Success: lo.ToPtr(true)}, nil
+	}
+}
+
+type (
+	Webhook         = gen.Webhook
+	WebhookDelivery = gen.WebhookDelivery
+)
+
+// webhookToAPI maps a domain webhook to the API Webhook response. The secret
+// is only included when withSecret is set.
+func webhookToAPI(w *task.Webhook, withSecret bool) *Webhook {
+	if w == nil {
+		return nil
+	}
+	evs := lo.Map(w.Events, func(e events.EventType, _ int) string { return string(e) })
+	out := &Webhook{
+		Id:        &w.ID,
+		Url:       &w.URL,
+		Events:    &evs,
+		Enabled:   &w.Enabled,
+		CreatedAt: &w.CreatedAt,
+		UpdatedAt: &w.UpdatedAt,
+	}
+	if withSecret {
+		out.Secret = &w.Secret
+	}
+	return out
+}
+
+func webhookDeliveryToAPI(d *task.WebhookDelivery) *WebhookDelivery {
+	return &WebhookDelivery{
+		Id:            &d.ID,
+		WebhookId:     &d.WebhookID,
+		EventId:       &d.EventID,
+		Event:         lo.ToPtr(string(d.Event)),
+		TaskId:        &d.TaskID,
+		Payload:       lo.ToPtr(string(d.Payload)),
+		Status:        lo.ToPtr(string(d.Status)),
+		Attempts:      &d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		ResponseCode:  lo.EmptyableToPtr(d.ResponseCode),
+		LastError:     d.LastError,
+		DeliveredAt:   d.DeliveredAt,
+		CreatedAt:     &d.CreatedAt,
+		UpdatedAt:     &d.UpdatedAt,
+	}
+}
+
+func toWebhookEvents(evs []string) []events.EventType {
+	return lo.Map(evs, func(e string, _ int) events.EventType { return events.EventType(e) })
+}
+
+type (
+	CreateWebhookRequest = gen.CreateWebhookRequest
+	UpdateWebhookRequest = gen.UpdateWebhookRequest
+	WebhookResponse      = gen.WebhookResponse
+)
+
+type (
+	ListWebhooksRequest  = gen.ListWebhooksParams
+	ListWebhooksResponse = gen.ListWebhooksResponse
+)
+
+type (
+	GetWebhookRequest     = gen.GetWebhookParams
+	DeleteWebhookRequest  = gen.DeleteWebhookParams
+	DeleteWebhookResponse = gen.SuccessResponse
+)
+
+type (
+	ListWebhookDeliveriesRequest  = gen.ListWebhookDeliveriesParams
+	ListWebhookDeliveriesResponse = gen.ListWebhookDeliveriesResponse
+)
+
+// MakeCreateWebhookEndpoint registers a webhook for the authenticated account.
+// The response is the only one besides a secret rotation to include the secret.
+func MakeCreateWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateWebhookRequest)
+
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		w, err := svc.CreateWebhook(ctx, &task.CreateWebhookParam{
+			OfAccountID: userID,
+			URL:         req.Url,
+			Events:      toWebhookEvents(lo.FromPtr(req.Events)),
+			Secret:      lo.FromPtr(req.Secret),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookResponse{Webhook: webhookToAPI(w, true)}, nil
+	}
+}
+
+// MakeListWebhooksEndpoint lists the webhooks of the authenticated account.
+func MakeListWebhooksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListWebhooksRequest)
+
+		userID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+
+		webhooks, err := svc.ListWebhooks(
+			ctx,
+			userID,
+			int32(lo.FromPtr(req.Limit)),
+			int32(lo.FromPtr(req.Offset)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		out := lo.Map(webhooks, func(w *task.Webhook, _ int) Webhook { return *webhookToAPI(w, false) })
+		return &ListWebhooksResponse{Webhooks: &out}, nil
+	}
+}
+
+func MakeGetWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetWebhookRequest)
+		w, err := svc.GetWebhook(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookResponse{Webhook: webhookToAPI(w, false)}, nil
+	}
+}
+
+func MakeUpdateWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*UpdateWebhookRequest)
+		param := &task.UpdateWebhookParam{
+			ID:           req.Id,
+			URL:          req.Url,
+			Enabled:      req.Enabled,
+			RotateSecret: lo.FromPtr(req.RotateSecret),
+		}
+		if req.Events != nil {
+			param.Events = lo.ToPtr(toWebhookEvents(*req.Events))
+		}
+		w, err := svc.UpdateWebhook(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookResponse{Webhook: webhookToAPI(w, param.RotateSecret)}, nil
+	}
+}
+
+func MakeDeleteWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*DeleteWebhookRequest)
+		if err := svc.DeleteWebhook(ctx, req.Id); err != nil {
+			return nil, err
+		}
+		return &DeleteWebhookResponse{Success: lo.ToPtr(true)}, nil
+	}
+}
+
+func MakeListWebhookDeliveriesEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListWebhookDeliveriesRequest)
+		deliveries, err := svc.ListWebhookDeliveries(
+			ctx,
+			req.Id,
+			int32(lo.FromPtr(req.Limit)),
+			int32(lo.FromPtr(req.Offset)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		out := lo.Map(deliveries, func(d *task.WebhookDelivery, _ int) WebhookDelivery {
+			return *webhookDeliveryToAPI(d)
+		})
+		return &ListWebhookDeliveriesResponse{Deliveries: &out}, nil
+	}
+}
+
+type (
+	ListAuditEventsRequest   = gen.ListAuditEventsParams
+	ListAuditEventsResponse  = gen.ListAuditEventsResponse
+	ExportAuditEventsRequest = gen.ExportAuditEventsParams
+	AuditEvent               = gen.AuditEvent
+)
+
+// ExportAuditEventsResponse holds the first page of an export. The HTTP
+// transport writes it and fetches the pages behind it with NextPage.
+type ExportAuditEventsResponse struct {
+	Events   []*task.AuditEvent
+	NextPage func(ctx context.Context, beforeID uint64) ([]*task.AuditEvent, error)
+}
+
+// auditExportPageSize is the number of events an export fetches at once.
+const auditExportPageSize = 1000
+
+func auditEventToAPI(e *task.AuditEvent) *AuditEvent {
+	return &AuditEvent{
+		Id:             &e.ID,
+		Time:           &e.Time,
+		ActorAccountId: lo.EmptyableToPtr(e.ActorAccountID),
+		ActorApiKeyId:  lo.EmptyableToPtr(e.ActorAPIKeyID),
+		Action:         lo.ToPtr(string(e.Action)),
+		TaskId:         lo.EmptyableToPtr(e.TaskID),
+		OfAccountId:    lo.EmptyableToPtr(e.OfAccountID),
+		WorkspaceId:    lo.EmptyableToPtr(e.WorkspaceID),
+		SourceIp:       lo.EmptyableToPtr(e.SourceIP),
+		Outcome:        lo.ToPtr(string(e.Outcome)),
+		Message:        lo.EmptyableToPtr(e.Message),
+	}
+}
+
+// auditFilter returns the filter of an audit listing by the authenticated
+// account.
+func auditFilter(
+	ctx context.Context,
+	taskID, workspaceID *uint64,
+	actions *[]string,
+	outcome *string,
+	from, to *time.Time,
+) (task.AuditFilter, error) {
+	userID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return task.AuditFilter{}, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+	}
+	return task.AuditFilter{
+		AccountID:   userID,
+		WorkspaceID: lo.FromPtr(workspaceID),
+		TaskID:      lo.FromPtr(taskID),
+		Actions: lo.Map(lo.FromPtr(actions), func(a string, _ int) task.AuditAction {
+			return task.AuditAction(strings.ToLower(a))
+		}),
+		Outcome: task.AuditOutcome(strings.ToUpper(lo.FromPtr(outcome))),
+		From:    from,
+		To:      to,
+	}, nil
+}
+
+// MakeListAuditEventsEndpoint lists the audit events of the authenticated
+// account, or of a workspace it administers, newest first.
+func MakeListAuditEventsEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListAuditEventsRequest)
+		filter, err := auditFilter(ctx, req.TaskId, req.WorkspaceId, req.Action, req.Outcome, req.From, req.To)
+		if err != nil {
+			return nil, err
+		}
+
+		evs, err := svc.ListAuditEvents(ctx, &task.ListAuditEventsParam{
+			Filter:   filter,
+			BeforeID: lo.FromPtr(req.BeforeId),
+			Limit:    int32(lo.FromPtr(req.Limit)),
+		})
+		if err != nil {
+			return nil, err
+		}
+		out := lo.Map(evs, func(e *task.AuditEvent, _ int) AuditEvent { return *auditEventToAPI(e) })
+		resp := &ListAuditEventsResponse{Events: &out}
+		if len(evs) > 0 {
+			resp.NextBeforeId = &evs[len(evs)-1].ID
+		}
+		return resp, nil
+	}
+}
+
+// MakeExportAuditEventsEndpoint exports every audit event matching the
+// request. The first page is fetched here so that an invalid filter fails
+// the request before the export starts.
+func MakeExportAuditEventsEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ExportAuditEventsRequest)
+		filter, err := auditFilter(ctx, req.TaskId, req.WorkspaceId, req.Action, req.Outcome, req.From, req.To)
+		if err != nil {
+			return nil, err
+		}
+
+		nextPage := func(ctx context.Context, beforeID uint64) ([]*task.AuditEvent, error) {
+			return svc.ListAuditEvents(ctx, &task.ListAuditEventsParam{
+				Filter:   filter,
+				BeforeID: beforeID,
+				Limit:    auditExportPageSize,
+			})
+		}
+		evs, err := nextPage(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &ExportAuditEventsResponse{Events: evs, NextPage: nextPage}, nil
+	}
+}
+
+// MakeRecordAuditEventEndpoint records an event the gateway observed outside
+// of an endpoint, such as a download through a token URL.
+func MakeRecordAuditEventEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		if err := svc.RecordAuditEvent(ctx, request.(*task.AuditEvent)); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+}
+
+type CreateAccountGatewayRequest = gen.CreateAccountGatewayRequest
+
+type CreateAccountGatewayResponse = gen.CreateAccountGatewayResponse
+
+type CreateSessionGatewayRequest = gen.CreateSessionGatewayRequest
+
+type CreateSessionGatewayResponse = gen.CreateSessionGatewayResponse
+
+type AuthAccount = gen.AuthAccount
+
+type RefreshSessionGatewayRequest = gen.RefreshSessionGatewayRequest
+
+type BeginOIDCLoginRequest = gen.BeginOIDCLoginParams
+
+// BeginOIDCLoginResponse holds the URL of the provider. The HTTP transport
+// redirects to it when Redirect is set.
+type BeginOIDCLoginResponse struct {
+	gen.BeginOIDCLoginResponse
+	Redirect bool `json:"-"`
+}
+
+type OIDCCallbackRequest = gen.CompleteOIDCLoginParams
+
+type (
+	LogoutRequest  struct{}
+	LogoutResponse = gen.SuccessResponse
+)
+
+type (
+	RevokeSessionsRequest  = gen.RevokeSessionsRequest
+	RevokeSessionsResponse = gen.SuccessResponse
+)
+
+func MakeCreateAccountEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateAccountGatewayRequest)
+		out, err := svc.CreateAccount(
+			ctx,
+			auth.CreateAccountParams{AccountName: req.AccountName, Password: req.Password},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &CreateAccountGatewayResponse{Id: &out.ID, AccountName: &out.AccountName}, nil
+	}
+}
+
+func MakeCreateSessionEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateSessionGatewayRequest)
+		out, err := svc.CreateSession(
+			ctx,
+			auth.CreateSessionParams{AccountName: req.AccountName, Password: req.Password},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return toCreateSessionGatewayResponse(out), nil
+	}
+}
+
+// MakeRefreshSessionEndpoint exchanges a refresh token for a new token pair.
+func MakeRefreshSessionEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RefreshSessionGatewayRequest)
+		out, err := svc.RefreshSession(ctx, auth.RefreshSessionParams{RefreshToken: req.RefreshToken})
+		if err != nil {
+			return nil, err
+		}
+		return toCreateSessionGatewayResponse(out), nil
+	}
+}
+
+// MakeBeginOIDCLoginEndpoint starts a login at the OpenID Connect provider.
+func MakeBeginOIDCLoginEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*BeginOIDCLoginRequest)
+		out, err := svc.BeginOIDCLogin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &BeginOIDCLoginResponse{
+			BeginOIDCLoginResponse: gen.BeginOIDCLoginResponse{AuthUrl: &out.AuthURL, State: &out.State},
+			Redirect:               lo.FromPtrOr(req.Redirect, true),
+		}, nil
+	}
+}
+
+// MakeOIDCCallbackEndpoint completes a login when the provider sends the
+// user back, and returns a session like MakeCreateSessionEndpoint.
+func MakeOIDCCallbackEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*OIDCCallbackRequest)
+		if req.Error != nil {
+			msg := "oidc login failed: " + *req.Error
+			if req.ErrorDescription != nil {
+				msg += ": " + *req.ErrorDescription
+			}
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: msg}
+		}
+		out, err := svc.CompleteOIDCLogin(ctx, auth.CompleteOIDCLoginParams{
+			State: lo.FromPtr(req.State),
+			Code:  lo.FromPtr(req.Code),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return toCreateSessionGatewayResponse(out), nil
+	}
+}
+
+// MakeLogoutEndpoint revokes the session of the token the request was
+// authenticated with.
+func MakeLogoutEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		token, ok := ctx.Value(tokenKey).(string)
+		if !ok || token == "" {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "missing or invalid token"}
+		}
+		if err := svc.Logout(ctx, auth.LogoutParams{Token: token}); err != nil {
+			return nil, err
+		}
+		return &LogoutResponse{Success: lo.ToPtr(true)}, nil
+	}
+}
+
+// MakeRevokeSessionsEndpoint revokes one or all sessions of the
+// authenticated account.
+func MakeRevokeSessionsEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RevokeSessionsRequest)
+		accountID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+		if err := svc.RevokeSession(ctx, auth.RevokeSessionParams{
+			AccountID: accountID,
+			SessionID: lo.FromPtr(req.SessionId),
+		}); err != nil {
+			return nil, err
+		}
+		return &RevokeSessionsResponse{Success: lo.ToPtr(true)}, nil
+	}
+}
+
 type (
-	CheckFileExistsRequest  = gen.CheckFileExistsParams
-	CheckFileExistsResponse = gen.CheckFileExistsResponse
+	CreateAPIKeyRequest  = gen.CreateAPIKeyRequest
+	CreateAPIKeyResponse = gen.CreateAPIKeyResponse
 )
 
 type (
-	GetTaskProgressRequest  = gen.GetTaskProgressParams
-	GetTaskProgressResponse = gen.GetTaskProgressResponse
+	ListAPIKeysRequest  struct{}
+	ListAPIKeysResponse = gen.ListAPIKeysResponse
 )
 
-type GenerateDownloadURLRequest = gen.GenerateDownloadURLRequest
+type (
+	RevokeAPIKeyRequest  = gen.RevokeAPIKeyParams
+	RevokeAPIKeyResponse = gen.SuccessResponse
+)
 
-type GenerateDownloadURLResponse = gen.GenerateDownloadURLResponse
+type APIKey = gen.APIKey
 
-// MakeGenerateDownloadURLEndpoint calls the task service GenerateDownloadURL,
-// which internally handles presigning (direct MinIO URL) or token fallback.
-func MakeGenerateDownloadURLEndpoint(svc task.Service) endpoint.Endpoint {
-	return func(ctx context.Context, request any) (any, error) {
-		req := request.(*GenerateDownloadURLRequest)
+func apiKeyToAPI(k auth.APIKey) *APIKey {
+	return &APIKey{
+		Id:        &k.Id,
+		Name:      &k.Name,
+		Prefix:    &k.Prefix,
+		Scopes:    &k.Scopes,
+		ExpiresAt: k.ExpiresAt,
+		CreatedAt: &k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
 
-		userID, ok := UserIDFromContext(ctx)
+// MakeCreateAPIKeyEndpoint creates an API key for the authenticated account.
+func MakeCreateAPIKeyEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateAPIKeyRequest)
+		accountID, ok := UserIDFromContext(ctx)
 		if !ok {
 			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
 		}
-
-		t, err := svc.GetTask(ctx, req.TaskId)
+		out, err := svc.CreateAPIKey(ctx, auth.CreateAPIKeyParams{
+			AccountID: accountID,
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+			ExpiresAt: req.ExpiresAt,
+		})
 		if err != nil {
 			return nil, err
 		}
-		if t.OfAccountID != userID {
-			return nil, &errors.Error{Code: errors.ErrCodePermissionDenied, Message: "forbidden"}
-		}
+		return &CreateAPIKeyResponse{ApiKey: apiKeyToAPI(out.APIKey), Key: &out.Key}, nil
+	}
+}
 
-		ttl := time.Duration(req.TtlSeconds) * time.Second
-		if ttl <= 0 {
-			ttl = time.Hour
+func MakeListAPIKeysEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		accountID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
 		}
-
-		urlStr, direct, err := svc.GenerateDownloadURL(ctx, req.TaskId, ttl, req.OneTime)
+		keys, err := svc.ListAPIKeys(ctx, auth.ListAPIKeysParams{AccountID: accountID})
 		if err != nil {
 			return nil, err
 		}
-		return &GenerateDownloadURLResponse{Url: &urlStr, Direct: &direct}, nil
+		out := lo.Map(keys, func(k auth.APIKey, _ int) APIKey {
+			return *apiKeyToAPI(k)
+		})
+		return &ListAPIKeysResponse{ApiKeys: &out}, nil
 	}
 }
 
-func MakeListTasksEndpoint(svc task.Service) endpoint.Endpoint {
+func MakeRevokeAPIKeyEndpoint(svc auth.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
-		req := request.(*ListTasksRequest)
-
-		// Ensure user is authenticated. The auth middleware should have
-		// populated the account ID into the context by the time this
-		// endpoint executes.
-		userID, ok := UserIDFromContext(ctx)
+		req := request.(*RevokeAPIKeyRequest)
+		accountID, ok := UserIDFromContext(ctx)
 		if !ok {
 			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
 		}
-
-		var offset, limit uint64
-		if req.Offset != nil {
-			offset = *req.Offset
-		}
-		if req.Limit != nil {
-			limit = *req.Limit
+		if err := svc.RevokeAPIKey(ctx, auth.RevokeAPIKeyParams{AccountID: accountID, ID: req.Id}); err != nil {
+			return nil, err
 		}
+		return &RevokeAPIKeyResponse{Success: lo.ToPtr(true)}, nil
+	}
+}
 
-		params := task.ListTasksParam{
-			Filter: &task.TaskFilter{
-				OfAccountID: userID,
-			},
-			Offset: int32(offset),
-			Limit:  int32(limit),
-		}
+type (
+	JWKSRequest  struct{}
+	JWKSResponse = gen.JWKS
+)
 
-		result, err := svc.ListTasks(ctx, &params)
+type JWK = gen.JWK
+
+// MakeJWKSEndpoint returns the keys that verify goload access tokens as a
+// JSON Web Key Set, so that other services can validate tokens offline.
+func MakeJWKSEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		keys, err := svc.ListPublicKeys(ctx)
 		if err != nil {
 			return nil, err
 		}
+		out := make([]JWK, 0, len(keys))
+		for _, key := range keys {
+			publicKey, err := rsa.DeserializePublicKey(key.PublicKey)
+			if err != nil {
+				return nil, &errors.Error{
+					Code:    errors.ErrCodeInternal,
+					Message: fmt.Sprintf("invalid public key %d", key.Id),
+					Cause:   err,
+				}
+			}
+			n, e := rsa.JWKParams(publicKey)
+			out = append(out, JWK{
+				Kty: lo.ToPtr("RSA"),
+				Use: lo.ToPtr("sig"),
+				Alg: lo.ToPtr("RS512"),
+				Kid: lo.ToPtr(strconv.FormatUint(key.Id, 10)),
+				N:   &n,
+				E:   &e,
+			})
+		}
+		return &JWKSResponse{Keys: &out}, nil
+	}
+}
 
-		count := result.Total
-		tasks := lo.Map(result.Tasks, func(t *task.Task, _ int) Task { return *taskToAPI(t) })
-		return &ListTasksResponse{
-			Tasks:      &tasks,
-			TotalCount: &count,
-		}, nil
+type (
+	CreateWorkspaceRequest  = gen.CreateWorkspaceRequest
+	CreateWorkspaceResponse = gen.CreateWorkspaceResponse
+)
+
+type (
+	ListWorkspacesRequest  struct{}
+	ListWorkspacesResponse = gen.ListWorkspacesResponse
+)
+
+type (
+	ListWorkspaceMembersRequest  = gen.ListWorkspaceMembersParams
+	ListWorkspaceMembersResponse = gen.ListWorkspaceMembersResponse
+)
+
+type (
+	SetWorkspaceMemberRequest  = gen.SetWorkspaceMemberRequest
+	SetWorkspaceMemberResponse = gen.SetWorkspaceMemberResponse
+)
+
+type (
+	RemoveWorkspaceMemberRequest  = gen.RemoveWorkspaceMemberParams
+	RemoveWorkspaceMemberResponse = gen.SuccessResponse
+)
+
+type (
+	Workspace       = gen.Workspace
+	WorkspaceMember = gen.WorkspaceMember
+)
+
+func workspaceMemberToAPI(m auth.WorkspaceMember) *WorkspaceMember {
+	return &WorkspaceMember{
+		AccountId:   &m.AccountId,
+		AccountName: lo.EmptyableToPtr(m.AccountName),
+		Role:        lo.ToPtr(string(m.Role)),
+		CreatedAt:   &m.CreatedAt,
 	}
 }
 
-// MakeCreateTaskEndpoint allows creating a new task. The endpoint enforces that the
-// authenticated user (from context) is set as the task owner.
-func MakeCreateTaskEndpoint(svc task.Service) endpoint.Endpoint {
+// MakeCreateWorkspaceEndpoint creates a workspace administered by the
+// authenticated account.
+func MakeCreateWorkspaceEndpoint(svc auth.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
-		req := request.(*CreateTaskRequest)
-
-		userID, ok := UserIDFromContext(ctx)
+		req := request.(*CreateWorkspaceRequest)
+		accountID, ok := UserIDFromContext(ctx)
 		if !ok {
 			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
 		}
-
-		var metadata map[string]any
-		if req.Metadata != nil {
-			metadata = *req.Metadata
-		}
-
-		param := task.CreateTaskParam{
-			OfAccountID: userID,
-			FileName:    req.FileName,
-			SourceURL:   req.SourceUrl,
-			SourceType:  task.ToSourceType(req.SourceType),
-			Metadata:    metadata,
+		w, err := svc.CreateWorkspace(ctx, auth.CreateWorkspaceParams{AccountID: accountID, Name: req.Name})
+		if err != nil {
+			return nil, err
 		}
+		return &CreateWorkspaceResponse{Workspace: &Workspace{
+			Id:        &w.Id,
+			Name:      &w.Name,
+			Role:      lo.ToPtr(string(auth.WorkspaceRoleAdmin)),
+			CreatedAt: &w.CreatedAt,
+		}}, nil
+	}
+}
 
-		if param.SourceType == task.SourceBitTorrent && metadata != nil {
-			if raw, ok := metadata["torrent_file_base64"]; ok {
-				if s, ok := raw.(string); ok && s != "" {
-					param.SourceURL = fmt.Sprintf("data:application/x-bittorrent;base64,%s", s)
-				}
-				delete(metadata, "torrent_file_base64")
-			}
-		}
-		if req.ChecksumType != nil || req.ChecksumValue != nil {
-			var ctype, cval string
-			if req.ChecksumType != nil {
-				ctype = *req.ChecksumType
-			}
-			if req.ChecksumValue != nil {
-				cval = *req.ChecksumValue
-			}
-			param.Checksum = &task.ChecksumInfo{ChecksumType: ctype, ChecksumValue: cval}
+// MakeListWorkspacesEndpoint lists the workspaces of the authenticated
+// account with its role in each.
+func MakeListWorkspacesEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		accountID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
 		}
-
-		t, err := svc.CreateTask(ctx, &param)
+		workspaces, err := svc.ListWorkspaces(ctx, auth.ListWorkspacesParams{AccountID: accountID})
 		if err != nil {
 			return nil, err
 		}
-
-		return &CreateTaskResponse{Task: taskToAPI(t)}, nil
+		out := lo.Map(workspaces, func(m auth.WorkspaceMembership, _ int) Workspace {
+			return Workspace{
+				Id:        &m.Workspace.Id,
+				Name:      &m.Workspace.Name,
+				Role:      lo.ToPtr(string(m.Role)),
+				CreatedAt: &m.Workspace.CreatedAt,
+			}
+		})
+		return &ListWorkspacesResponse{Workspaces: &out}, nil
 	}
 }
 
-func MakeGetTaskEndpoint(svc task.Service) endpoint.Endpoint {
+func MakeListWorkspaceMembersEndpoint(svc auth.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
-		req := request.(*GetTaskRequest)
-		t, err := svc.GetTask(ctx, req.Id)
+		req := request.(*ListWorkspaceMembersRequest)
+		accountID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+		members, err := svc.ListWorkspaceMembers(ctx, auth.ListWorkspaceMembersParams{
+			AccountID:   accountID,
+			WorkspaceID: req.Id,
+		})
 		if err != nil {
 			return nil, err
 		}
-
-		return &GetTaskResponse{Task: taskToAPI(t)}, nil
+		out := lo.Map(members, func(m auth.WorkspaceMember, _ int) WorkspaceMember {
+			return *workspaceMemberToAPI(m)
+		})
+		return &ListWorkspaceMembersResponse{Members: &out}, nil
 	}
 }
 
-type CreateAccountGatewayRequest = gen.CreateAccountGatewayRequest
-
-type CreateAccountGatewayResponse = gen.CreateAccountGatewayResponse
-
-type CreateSessionGatewayRequest = gen.CreateSessionGatewayRequest
-
-type CreateSessionGatewayResponse = gen.CreateSessionGatewayResponse
-
-type AuthAccount = gen.AuthAccount
-
-func MakeCreateAccountEndpoint(svc auth.Service) endpoint.Endpoint {
+// MakeSetWorkspaceMemberEndpoint adds an account to a workspace or changes
+// its role. Only admins of the workspace may do so.
+func MakeSetWorkspaceMemberEndpoint(svc auth.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
-		req := request.(*CreateAccountGatewayRequest)
-		out, err := svc.CreateAccount(
-			ctx,
-			auth.CreateAccountParams{AccountName: req.AccountName, Password: req.Password},
-		)
+		req := request.(*SetWorkspaceMemberRequest)
+		accountID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+		}
+		member, err := svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+			AccountID:   accountID,
+			WorkspaceID: req.WorkspaceId,
+			MemberName:  req.AccountName,
+			Role:        auth.WorkspaceRole(req.Role),
+		})
 		if err != nil {
 			return nil, err
 		}
-		return &CreateAccountGatewayResponse{Id: &out.ID, AccountName: &out.AccountName}, nil
+		return &SetWorkspaceMemberResponse{Member: workspaceMemberToAPI(member)}, nil
 	}
 }
 
-func MakeCreateSessionEndpoint(svc auth.Service) endpoint.Endpoint {
+// MakeRemoveWorkspaceMemberEndpoint removes a member from a workspace. Admins
+// may remove anyone; every member may remove itself.
+func MakeRemoveWorkspaceMemberEndpoint(svc auth.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
-		req := request.(*CreateSessionGatewayRequest)
-		out, err := svc.CreateSession(
-			ctx,
-			auth.CreateSessionParams{AccountName: req.AccountName, Password: req.Password},
-		)
-		if err != nil {
-			return nil, err
+		req := request.(*RemoveWorkspaceMemberRequest)
+		accountID, ok := UserIDFromContext(ctx)
+		if !ok {
+			return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
 		}
-		var acct *AuthAccount
-		if out.Account != nil {
-			acct = &AuthAccount{Id: &out.Account.Id, AccountName: &out.Account.AccountName}
+		if err := svc.RemoveWorkspaceMember(ctx, auth.RemoveWorkspaceMemberParams{
+			AccountID:   accountID,
+			WorkspaceID: req.Id,
+			MemberID:    req.MemberId,
+		}); err != nil {
+			return nil, err
 		}
-		return &CreateSessionGatewayResponse{Token: &out.Token, Account: acct}, nil
+		return &RemoveWorkspaceMemberResponse{Success: lo.ToPtr(true)}, nil
+	}
+}
+
+func toCreateSessionGatewayResponse(out auth.CreateSessionOutput) *CreateSessionGatewayResponse {
+	var acct *AuthAccount
+	if out.Account != nil {
+		acct = &AuthAccount{Id: &out.Account.Id, AccountName: &out.Account.AccountName}
+	}
+	return &CreateSessionGatewayResponse{
+		Token:        &out.Token,
+		Account:      acct,
+		ExpiresIn:    lo.ToPtr(int64(out.ExpiresIn / time.Second)),
+		RefreshToken: lo.EmptyableToPtr(out.RefreshToken),
 	}
 }
 
@@ -354,6 +1399,19 @@ func MakeRetryTaskEndpoint(svc task.Service) endpoint.Endpoint {
 	}
 }
 
+// MakeExtendTaskRetentionEndpoint keeps the stored file of a completed task
+// for the requested number of days from now.
+func MakeExtendTaskRetentionEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ExtendTaskRetentionRequest)
+		t, err := svc.ExtendTaskRetention(ctx, &task.ExtendTaskRetentionParam{TaskID: req.TaskId, Days: req.Days})
+		if err != nil {
+			return nil, err
+		}
+		return &ExtendTaskRetentionResponse{Task: taskToAPI(t)}, nil
+	}
+}
+
 func MakeCheckFileExistsEndpoint(svc task.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
 		req := request.(*CheckFileExistsRequest)
@@ -391,90 +1449,323 @@ func NewGatewayEndpoints(
 	downloadTaskSvc task.Service,
 	authMW endpoint.Middleware,
 	authSvc auth.Service,
+	logger log.Logger,
 ) GatewayEndpoints {
-	var authCreate endpoint.Endpoint
-	var authSession endpoint.Endpoint
+	// API keys are limited to their scopes; session tokens pass every check.
+	var (
+		readMW     = endpoint.Chain(authMW, RequireScopeMiddleware(auth.ScopeTasksRead))
+		writeMW    = endpoint.Chain(authMW, RequireScopeMiddleware(auth.ScopeTasksWrite))
+		downloadMW = endpoint.Chain(authMW, RequireScopeMiddleware(auth.ScopeDownload))
+		sessionMW  = endpoint.Chain(authMW, RequireSessionMiddleware())
+	)
+	// audited records the actions taken through mw in the audit log.
+	audited := func(mw endpoint.Middleware, eventsFn auditFn) endpoint.Middleware {
+		return endpoint.Chain(mw, AuditMiddleware(downloadTaskSvc, logger, eventsFn))
+	}
+
+	var (
+		authCreate, authSession, authRefresh, authLogout, authRevokeSessions endpoint.Endpoint
+		beginOIDCLogin, oidcCallback                                         endpoint.Endpoint
+		createAPIKey, listAPIKeys, revokeAPIKey                              endpoint.Endpoint
+		jwks                                                                 endpoint.Endpoint
+		createWorkspace, listWorkspaces, listWorkspaceMembers                endpoint.Endpoint
+		setWorkspaceMember, removeWorkspaceMember                            endpoint.Endpoint
+	)
 	if authSvc != nil {
 		authCreate = MakeCreateAccountEndpoint(authSvc)
 		authSession = MakeCreateSessionEndpoint(authSvc)
+		authRefresh = MakeRefreshSessionEndpoint(authSvc)
+		beginOIDCLogin = MakeBeginOIDCLoginEndpoint(authSvc)
+		oidcCallback = MakeOIDCCallbackEndpoint(authSvc)
+		authLogout = sessionMW(MakeLogoutEndpoint(authSvc))
+		authRevokeSessions = sessionMW(MakeRevokeSessionsEndpoint(authSvc))
+		createAPIKey = sessionMW(MakeCreateAPIKeyEndpoint(authSvc))
+		listAPIKeys = sessionMW(MakeListAPIKeysEndpoint(authSvc))
+		revokeAPIKey = sessionMW(MakeRevokeAPIKeyEndpoint(authSvc))
+		jwks = MakeJWKSEndpoint(authSvc)
+		createWorkspace = sessionMW(MakeCreateWorkspaceEndpoint(authSvc))
+		listWorkspaces = readMW(MakeListWorkspacesEndpoint(authSvc))
+		listWorkspaceMembers = readMW(MakeListWorkspaceMembersEndpoint(authSvc))
+		setWorkspaceMember = sessionMW(MakeSetWorkspaceMemberEndpoint(authSvc))
+		removeWorkspaceMember = sessionMW(MakeRemoveWorkspaceMemberEndpoint(authSvc))
 	}
 
 	return GatewayEndpoints{
-		CreateTaskEndpoint: authMW(MakeCreateTaskEndpoint(downloadTaskSvc)),
-		GetTaskEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		CreateTaskEndpoint: audited(writeMW, auditCreateTask)(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					return lo.FromPtr(req.(*CreateTaskRequest).WorkspaceId), auth.WorkspaceRoleOperator
+				},
+			)(
+				MakeCreateTaskEndpoint(downloadTaskSvc),
+			),
+		),
+		GetTaskEndpoint: readMW(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleViewer,
 				func(req any) uint64 { return req.(*GetTaskRequest).Id },
 			)(
 				MakeGetTaskEndpoint(downloadTaskSvc),
 			),
 		),
-		ListTasksEndpoint: authMW(MakeListTasksEndpoint(downloadTaskSvc)),
-		DeleteTaskEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		ListTasksEndpoint: readMW(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					return lo.FromPtr(req.(*ListTasksRequest).WorkspaceId), auth.WorkspaceRoleViewer
+				},
+			)(
+				MakeListTasksEndpoint(downloadTaskSvc),
+			),
+		),
+		WatchTasksEndpoint: readMW(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					return lo.FromPtr(req.(*WatchTasksRequest).WorkspaceId), auth.WorkspaceRoleViewer
+				},
+			)(
+				MakeWatchTasksEndpoint(downloadTaskSvc),
+			),
+		),
+		DeleteTaskEndpoint: audited(
+			writeMW,
+			auditTask(task.AuditTaskDelete, func(req any) uint64 { return req.(*DeleteTaskRequest).Id }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleAdmin,
 				func(req any) uint64 { return req.(*DeleteTaskRequest).Id },
 			)(
 				MakeDeleteTaskEndpoint(downloadTaskSvc),
 			),
 		),
-		PauseTaskEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		PauseTaskEndpoint: audited(
+			writeMW,
+			auditTask(task.AuditTaskPause, func(req any) uint64 { return req.(*PauseTaskRequest).Id }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleOperator,
 				func(req any) uint64 { return req.(*PauseTaskRequest).Id },
 			)(
 				MakePauseTaskEndpoint(downloadTaskSvc),
 			),
 		),
-		ResumeTaskEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		ResumeTaskEndpoint: audited(
+			writeMW,
+			auditTask(task.AuditTaskResume, func(req any) uint64 { return req.(*ResumeTaskRequest).Id }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleOperator,
 				func(req any) uint64 { return req.(*ResumeTaskRequest).Id },
 			)(
 				MakeResumeTaskEndpoint(downloadTaskSvc),
 			),
 		),
-		CancelTaskEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		CancelTaskEndpoint: audited(
+			writeMW,
+			auditTask(task.AuditTaskCancel, func(req any) uint64 { return req.(*CancelTaskRequest).Id }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleOperator,
 				func(req any) uint64 { return req.(*CancelTaskRequest).Id },
 			)(
 				MakeCancelTaskEndpoint(downloadTaskSvc),
 			),
 		),
-		RetryTaskEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		RetryTaskEndpoint: audited(
+			writeMW,
+			auditTask(task.AuditTaskRetry, func(req any) uint64 { return req.(*RetryTaskRequest).Id }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleOperator,
 				func(req any) uint64 { return req.(*RetryTaskRequest).Id },
 			)(
 				MakeRetryTaskEndpoint(downloadTaskSvc),
 			),
 		),
-		CheckFileExistsEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		ExtendTaskRetentionEndpoint: audited(
+			writeMW,
+			auditTask(task.AuditTaskExtend, func(req any) uint64 { return req.(*ExtendTaskRetentionRequest).TaskId }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleOperator,
+				func(req any) uint64 { return req.(*ExtendTaskRetentionRequest).TaskId },
+			)(
+				MakeExtendTaskRetentionEndpoint(downloadTaskSvc),
+			),
+		),
+		CheckFileExistsEndpoint: readMW(
+			RequireTaskRoleMiddleware(
+				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleViewer,
 				func(req any) uint64 { return req.(*CheckFileExistsRequest).TaskId },
 			)(
 				MakeCheckFileExistsEndpoint(downloadTaskSvc),
 			),
 		),
-		GetTaskProgressEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		GetTaskProgressEndpoint: readMW(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleViewer,
 				func(req any) uint64 { return req.(*GetTaskProgressRequest).TaskId },
 			)(
 				MakeGetTaskProgressEndpoint(downloadTaskSvc),
 			),
 		),
-		GenerateDownloadURLEndpoint: authMW(
-			RequireTaskOwnerMiddleware(
+		GenerateDownloadURLEndpoint: audited(
+			downloadMW,
+			auditTask(task.AuditTaskDownloadURL, func(req any) uint64 { return req.(*GenerateDownloadURLRequest).TaskId }),
+		)(
+			RequireTaskRoleMiddleware(
 				downloadTaskSvc,
+				authSvc,
+				auth.WorkspaceRoleViewer,
 				func(req any) uint64 { return req.(*GenerateDownloadURLRequest).TaskId },
 			)(
 				MakeGenerateDownloadURLEndpoint(downloadTaskSvc),
 			),
 		),
+		GetUsageEndpoint:      readMW(MakeGetUsageEndpoint(downloadTaskSvc)),
+		ListSchedulesEndpoint: readMW(MakeListSchedulesEndpoint(downloadTaskSvc)),
+		GetScheduleEndpoint: readMW(
+			RequireScheduleOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*GetScheduleRequest).Id },
+			)(
+				MakeGetScheduleEndpoint(downloadTaskSvc),
+			),
+		),
+		UpdateScheduleEndpoint: writeMW(
+			RequireScheduleOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*UpdateScheduleRequest).Id },
+			)(
+				MakeUpdateScheduleEndpoint(downloadTaskSvc),
+			),
+		),
+		DeleteScheduleEndpoint: writeMW(
+			RequireScheduleOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*DeleteScheduleRequest).Id },
+			)(
+				MakeDeleteScheduleEndpoint(downloadTaskSvc),
+			),
+		),
+		CreateWebhookEndpoint: writeMW(MakeCreateWebhookEndpoint(downloadTaskSvc)),
+		ListWebhooksEndpoint:  readMW(MakeListWebhooksEndpoint(downloadTaskSvc)),
+		GetWebhookEndpoint: readMW(
+			RequireWebhookOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*GetWebhookRequest).Id },
+			)(
+				MakeGetWebhookEndpoint(downloadTaskSvc),
+			),
+		),
+		UpdateWebhookEndpoint: writeMW(
+			RequireWebhookOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*UpdateWebhookRequest).Id },
+			)(
+				MakeUpdateWebhookEndpoint(downloadTaskSvc),
+			),
+		),
+		DeleteWebhookEndpoint: writeMW(
+			RequireWebhookOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*DeleteWebhookRequest).Id },
+			)(
+				MakeDeleteWebhookEndpoint(downloadTaskSvc),
+			),
+		),
+		ListWebhookDeliveriesEndpoint: readMW(
+			RequireWebhookOwnerMiddleware(
+				downloadTaskSvc,
+				func(req any) uint64 { return req.(*ListWebhookDeliveriesRequest).Id },
+			)(
+				MakeListWebhookDeliveriesEndpoint(downloadTaskSvc),
+			),
+		),
+		CreateTasksEndpoint: audited(writeMW, auditCreateTasks)(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					return lo.FromPtr(req.(*CreateTasksRequest).WorkspaceId), auth.WorkspaceRoleOperator
+				},
+			)(
+				MakeCreateTasksEndpoint(downloadTaskSvc),
+			),
+		),
+		BulkTasksEndpoint: audited(writeMW, auditBulkTasks)(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					r := req.(*BulkTasksRequest)
+					if task.BulkAction(strings.ToUpper(r.Action)) == task.BulkActionDelete {
+						return lo.FromPtr(r.WorkspaceId), auth.WorkspaceRoleAdmin
+					}
+					return lo.FromPtr(r.WorkspaceId), auth.WorkspaceRoleOperator
+				},
+			)(
+				MakeBulkTasksEndpoint(downloadTaskSvc),
+			),
+		),
+		ListAuditEventsEndpoint: readMW(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					return lo.FromPtr(req.(*ListAuditEventsRequest).WorkspaceId), auth.WorkspaceRoleAdmin
+				},
+			)(
+				MakeListAuditEventsEndpoint(downloadTaskSvc),
+			),
+		),
+		ExportAuditEventsEndpoint: readMW(
+			RequireWorkspaceRoleMiddleware(
+				authSvc,
+				func(req any) (uint64, auth.WorkspaceRole) {
+					return lo.FromPtr(req.(*ExportAuditEventsRequest).WorkspaceId), auth.WorkspaceRoleAdmin
+				},
+			)(
+				MakeExportAuditEventsEndpoint(downloadTaskSvc),
+			),
+		),
+		RecordAuditEventEndpoint: MakeRecordAuditEventEndpoint(downloadTaskSvc),
+
 		AuthCreateEndpoint:  authCreate,
 		AuthSessionEndpoint: authSession,
+		AuthRefreshEndpoint: authRefresh,
+
+		BeginOIDCLoginEndpoint: beginOIDCLogin,
+		OIDCCallbackEndpoint:   oidcCallback,
+
+		AuthLogoutEndpoint:         authLogout,
+		AuthRevokeSessionsEndpoint: authRevokeSessions,
+		CreateAPIKeyEndpoint:       createAPIKey,
+		ListAPIKeysEndpoint:        listAPIKeys,
+		RevokeAPIKeyEndpoint:       revokeAPIKey,
+		JWKSEndpoint:               jwks,
+
+		CreateWorkspaceEndpoint:       createWorkspace,
+		ListWorkspacesEndpoint:        listWorkspaces,
+		ListWorkspaceMembersEndpoint:  listWorkspaceMembers,
+		SetWorkspaceMemberEndpoint:    setWorkspaceMember,
+		RemoveWorkspaceMemberEndpoint: removeWorkspaceMember,
 	}
 }
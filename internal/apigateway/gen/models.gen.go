@@ -11,17 +11,96 @@ const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
+// APIKey defines model for APIKey.
+type APIKey struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Id        *uint64    `json:"id,omitempty"`
+	Name      *string    `json:"name,omitempty"`
+
+	// Prefix Public part of the key, shown to tell keys apart.
+	Prefix    *string    `json:"prefix,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Scopes    *[]string  `json:"scopes,omitempty"`
+}
+
+// AuditEvent defines model for AuditEvent.
+type AuditEvent struct {
+	Action *string `json:"action,omitempty"`
+
+	// ActorAccountId Account that acted; omitted for actions the task service takes on its own and for downloads with a token URL.
+	ActorAccountId *uint64 `json:"actor_account_id,omitempty"`
+
+	// ActorApiKeyId Set when the actor authenticated with an API key.
+	ActorApiKeyId *uint64 `json:"actor_api_key_id,omitempty"`
+	Id            *uint64 `json:"id,omitempty"`
+
+	// Message Error of denied and failed actions.
+	Message     *string    `json:"message,omitempty"`
+	OfAccountId *uint64    `json:"of_account_id,omitempty"`
+	Outcome     *string    `json:"outcome,omitempty"`
+	SourceIp    *string    `json:"source_ip,omitempty"`
+	TaskId      *uint64    `json:"task_id,omitempty"`
+	Time        *time.Time `json:"time,omitempty"`
+	WorkspaceId *uint64    `json:"workspace_id,omitempty"`
+}
+
 // AuthAccount defines model for AuthAccount.
 type AuthAccount struct {
 	AccountName *string `json:"account_name,omitempty"`
 	Id          *uint64 `json:"id,omitempty"`
 }
 
+// BeginOIDCLoginResponse defines model for BeginOIDCLoginResponse.
+type BeginOIDCLoginResponse struct {
+	// AuthUrl URL of the OpenID Connect provider the user logs in at.
+	AuthUrl *string `json:"auth_url,omitempty"`
+	State   *string `json:"state,omitempty"`
+}
+
+// BulkTaskFailure defines model for BulkTaskFailure.
+type BulkTaskFailure struct {
+	Message *string `json:"message,omitempty"`
+	TaskId  *uint64 `json:"task_id,omitempty"`
+}
+
+// BulkTasksRequest defines model for BulkTasksRequest.
+type BulkTasksRequest struct {
+	Action string      `json:"action"`
+	Filter *TaskFilter `json:"filter,omitempty"`
+	Ids    *[]uint64   `json:"ids,omitempty"`
+
+	// WorkspaceId Selects tasks of the workspace instead of personal tasks.
+	WorkspaceId *uint64 `json:"workspace_id,omitempty"`
+}
+
+// BulkTasksResponse defines model for BulkTasksResponse.
+type BulkTasksResponse struct {
+	Failed    *[]BulkTaskFailure `json:"failed,omitempty"`
+	Succeeded *[]uint64          `json:"succeeded,omitempty"`
+}
+
 // CheckFileExistsResponse defines model for CheckFileExistsResponse.
 type CheckFileExistsResponse struct {
 	Exists *bool `json:"Exists,omitempty"`
 }
 
+// CreateAPIKeyRequest defines model for CreateAPIKeyRequest.
+type CreateAPIKeyRequest struct {
+	// ExpiresAt Optional expiry; the key is valid until revoked when omitted.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+}
+
+// CreateAPIKeyResponse defines model for CreateAPIKeyResponse.
+type CreateAPIKeyResponse struct {
+	ApiKey *APIKey `json:"api_key,omitempty"`
+
+	// Key Bearer token of the key. It is only returned once.
+	Key *string `json:"key,omitempty"`
+}
+
 // CreateAccountGatewayRequest defines model for CreateAccountGatewayRequest.
 type CreateAccountGatewayRequest struct {
 	AccountName string `json:"account_name"`
@@ -43,17 +122,34 @@ type CreateSessionGatewayRequest struct {
 // CreateSessionGatewayResponse defines model for CreateSessionGatewayResponse.
 type CreateSessionGatewayResponse struct {
 	Account *AuthAccount `json:"account,omitempty"`
-	Token   *string      `json:"token,omitempty"`
+
+	// ExpiresIn Lifetime of token in seconds.
+	ExpiresIn *int64 `json:"expires_in,omitempty"`
+
+	// RefreshToken Single-use token exchanged at /api/v1/auth/refresh for a new token pair.
+	RefreshToken *string `json:"refresh_token,omitempty"`
+	Token        *string `json:"token,omitempty"`
 }
 
 // CreateTaskRequest defines model for CreateTaskRequest.
 type CreateTaskRequest struct {
-	ChecksumType  *string                 `json:"checksum_type,omitempty"`
-	ChecksumValue *string                 `json:"checksum_value,omitempty"`
-	FileName      string                  `json:"file_name"`
-	Metadata      *map[string]interface{} `json:"metadata,omitempty"`
-	SourceType    string                  `json:"source_type"`
-	SourceUrl     string                  `json:"source_url"`
+	ChecksumType  *string `json:"checksum_type,omitempty"`
+	ChecksumValue *string `json:"checksum_value,omitempty"`
+
+	// ExpirationDays Days the stored file is kept after it was last accessed; 0 keeps it until the task is deleted.
+	ExpirationDays *int32                  `json:"expiration_days,omitempty"`
+	FileName       string                  `json:"file_name"`
+	Metadata       *map[string]interface{} `json:"metadata,omitempty"`
+	Priority       *string                 `json:"priority,omitempty"`
+
+	// ReuseExisting Completes the task at once with the stored file of a completed task of the account instead of downloading the source again.
+	ReuseExisting *bool         `json:"reuse_existing,omitempty"`
+	Schedule      *ScheduleSpec `json:"schedule,omitempty"`
+	SourceType    string        `json:"source_type"`
+	SourceUrl     string        `json:"source_url"`
+
+	// WorkspaceId Shares the task with the members of the workspace.
+	WorkspaceId *uint64 `json:"workspace_id,omitempty"`
 }
 
 // CreateTaskResponse defines model for CreateTaskResponse.
@@ -61,11 +157,51 @@ type CreateTaskResponse struct {
 	Task *Task `json:"task,omitempty"`
 }
 
+// CreateTasksRequest defines model for CreateTasksRequest.
+type CreateTasksRequest struct {
+	Metalink *string              `json:"metalink,omitempty"`
+	Priority *string              `json:"priority,omitempty"`
+	Tasks    *[]CreateTaskRequest `json:"tasks,omitempty"`
+	UrlList  *string              `json:"url_list,omitempty"`
+
+	// WorkspaceId Shares the tasks with the members of the workspace.
+	WorkspaceId *uint64 `json:"workspace_id,omitempty"`
+}
+
+// CreateTasksResponse defines model for CreateTasksResponse.
+type CreateTasksResponse struct {
+	Tasks *[]Task `json:"tasks,omitempty"`
+}
+
+// CreateWebhookRequest defines model for CreateWebhookRequest.
+type CreateWebhookRequest struct {
+	Events *[]string `json:"events,omitempty"`
+	Secret *string   `json:"secret,omitempty"`
+	Url    string    `json:"url"`
+}
+
+// CreateWorkspaceRequest defines model for CreateWorkspaceRequest.
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateWorkspaceResponse defines model for CreateWorkspaceResponse.
+type CreateWorkspaceResponse struct {
+	Workspace *Workspace `json:"workspace,omitempty"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Error *string `json:"error,omitempty"`
 }
 
+// ExtendTaskRetentionRequest defines model for ExtendTaskRetentionRequest.
+type ExtendTaskRetentionRequest struct {
+	// Days Days the stored file is kept from now.
+	Days   int32  `json:"days"`
+	TaskId uint64 `json:"task_id"`
+}
+
 // GenerateDownloadURLRequest defines model for GenerateDownloadURLRequest.
 type GenerateDownloadURLRequest struct {
 	OneTime    bool   `json:"one_time"`
@@ -91,12 +227,129 @@ type GetTaskResponse struct {
 	Task *Task `json:"task,omitempty"`
 }
 
+// GetUsageResponse defines model for GetUsageResponse.
+type GetUsageResponse struct {
+	ActiveTasks    *int64 `json:"active_tasks,omitempty"`
+	BytesToday     *int64 `json:"bytes_today,omitempty"`
+	MaxActiveTasks *int64 `json:"max_active_tasks,omitempty"`
+	MaxBytesPerDay *int64 `json:"max_bytes_per_day,omitempty"`
+	MaxStoredBytes *int64 `json:"max_stored_bytes,omitempty"`
+	StoredBytes    *int64 `json:"stored_bytes,omitempty"`
+}
+
+// JWK defines model for JWK.
+type JWK struct {
+	Alg *string `json:"alg,omitempty"`
+	E   *string `json:"e,omitempty"`
+	Kid *string `json:"kid,omitempty"`
+	Kty *string `json:"kty,omitempty"`
+	N   *string `json:"n,omitempty"`
+	Use *string `json:"use,omitempty"`
+}
+
+// JWKS defines model for JWKS.
+type JWKS struct {
+	Keys *[]JWK `json:"keys,omitempty"`
+}
+
+// ListAPIKeysResponse defines model for ListAPIKeysResponse.
+type ListAPIKeysResponse struct {
+	ApiKeys *[]APIKey `json:"api_keys,omitempty"`
+}
+
+// ListAuditEventsResponse defines model for ListAuditEventsResponse.
+type ListAuditEventsResponse struct {
+	Events *[]AuditEvent `json:"events,omitempty"`
+
+	// NextBeforeId Pass as before_id to get the next page; omitted when the page is empty.
+	NextBeforeId *uint64 `json:"next_before_id,omitempty"`
+}
+
+// ListSchedulesResponse defines model for ListSchedulesResponse.
+type ListSchedulesResponse struct {
+	Schedules *[]Schedule `json:"schedules,omitempty"`
+}
+
 // ListTasksResponse defines model for ListTasksResponse.
 type ListTasksResponse struct {
+	NextCursor *string `json:"next_cursor,omitempty"`
 	Tasks      *[]Task `json:"tasks,omitempty"`
 	TotalCount *int32  `json:"total_count,omitempty"`
 }
 
+// ListWebhookDeliveriesResponse defines model for ListWebhookDeliveriesResponse.
+type ListWebhookDeliveriesResponse struct {
+	Deliveries *[]WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+// ListWebhooksResponse defines model for ListWebhooksResponse.
+type ListWebhooksResponse struct {
+	Webhooks *[]Webhook `json:"webhooks,omitempty"`
+}
+
+// ListWorkspaceMembersResponse defines model for ListWorkspaceMembersResponse.
+type ListWorkspaceMembersResponse struct {
+	Members *[]WorkspaceMember `json:"members,omitempty"`
+}
+
+// ListWorkspacesResponse defines model for ListWorkspacesResponse.
+type ListWorkspacesResponse struct {
+	Workspaces *[]Workspace `json:"workspaces,omitempty"`
+}
+
+// RefreshSessionGatewayRequest defines model for RefreshSessionGatewayRequest.
+type RefreshSessionGatewayRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RevokeSessionsRequest defines model for RevokeSessionsRequest.
+type RevokeSessionsRequest struct {
+	// SessionId Session to revoke; every session of the account is revoked when omitted.
+	SessionId *string `json:"session_id,omitempty"`
+}
+
+// Schedule defines model for Schedule.
+type Schedule struct {
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	Cron       *string    `json:"cron,omitempty"`
+	Enabled    *bool      `json:"enabled,omitempty"`
+	FileName   *string    `json:"file_name,omitempty"`
+	Id         *uint64    `json:"id,omitempty"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty"`
+	NextTaskId *uint64    `json:"next_task_id,omitempty"`
+	Priority   *string    `json:"priority,omitempty"`
+	SourceType *string    `json:"source_type,omitempty"`
+	SourceUrl  *string    `json:"source_url,omitempty"`
+	StartAt    *time.Time `json:"start_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// ScheduleResponse defines model for ScheduleResponse.
+type ScheduleResponse struct {
+	Schedule *Schedule `json:"schedule,omitempty"`
+}
+
+// ScheduleSpec defines model for ScheduleSpec.
+type ScheduleSpec struct {
+	Cron    *string    `json:"cron,omitempty"`
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// SetWorkspaceMemberRequest defines model for SetWorkspaceMemberRequest.
+type SetWorkspaceMemberRequest struct {
+	AccountName string `json:"account_name"`
+
+	// Role One of viewer, operator or admin.
+	Role        string `json:"role"`
+	WorkspaceId uint64 `json:"workspace_id"`
+}
+
+// SetWorkspaceMemberResponse defines model for SetWorkspaceMemberResponse.
+type SetWorkspaceMemberResponse struct {
+	Member *WorkspaceMember `json:"member,omitempty"`
+}
+
 // SuccessResponse defines model for SuccessResponse.
 type SuccessResponse struct {
 	Success *bool `json:"Success,omitempty"`
@@ -104,22 +357,183 @@ type SuccessResponse struct {
 
 // Task defines model for Task.
 type Task struct {
-	ChecksumType    *string                 `json:"checksum_type,omitempty"`
-	ChecksumValue   *string                 `json:"checksum_value,omitempty"`
-	CompletedAt     *time.Time              `json:"completed_at,omitempty"`
-	CreatedAt       *time.Time              `json:"created_at,omitempty"`
-	DownloadedBytes *int64                  `json:"downloaded_bytes,omitempty"`
-	ErrorMessage    *string                 `json:"error_message,omitempty"`
-	FileName        *string                 `json:"file_name,omitempty"`
-	Id              *uint64                 `json:"id,omitempty"`
-	Metadata        *map[string]interface{} `json:"metadata,omitempty"`
-	OfAccountId     *uint64                 `json:"of_account_id,omitempty"`
-	Progress        *float32                `json:"progress,omitempty"`
-	SourceType      *string                 `json:"source_type,omitempty"`
-	SourceUrl       *string                 `json:"source_url,omitempty"`
-	Status          *string                 `json:"status,omitempty"`
-	TotalBytes      *int64                  `json:"total_bytes,omitempty"`
-	UpdatedAt       *time.Time              `json:"updated_at,omitempty"`
+	ChecksumType    *string    `json:"checksum_type,omitempty"`
+	ChecksumValue   *string    `json:"checksum_value,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	DownloadedBytes *int64     `json:"downloaded_bytes,omitempty"`
+	ErrorMessage    *string    `json:"error_message,omitempty"`
+	ExpirationDays  *int32     `json:"expiration_days,omitempty"`
+
+	// ExpiresAt When the stored file expires unless it is accessed again.
+	ExpiresAt   *time.Time              `json:"expires_at,omitempty"`
+	FileName    *string                 `json:"file_name,omitempty"`
+	Id          *uint64                 `json:"id,omitempty"`
+	Metadata    *map[string]interface{} `json:"metadata,omitempty"`
+	OfAccountId *uint64                 `json:"of_account_id,omitempty"`
+	Priority    *string                 `json:"priority,omitempty"`
+	Progress    *float32                `json:"progress,omitempty"`
+	ScheduleId  *uint64                 `json:"schedule_id,omitempty"`
+	SourceType  *string                 `json:"source_type,omitempty"`
+	SourceUrl   *string                 `json:"source_url,omitempty"`
+	Status      *string                 `json:"status,omitempty"`
+	TotalBytes  *int64                  `json:"total_bytes,omitempty"`
+	UpdatedAt   *time.Time              `json:"updated_at,omitempty"`
+	WorkspaceId *uint64                 `json:"workspace_id,omitempty"`
+}
+
+// TaskEvent defines model for TaskEvent.
+type TaskEvent struct {
+	DownloadedBytes *int64     `json:"downloaded_bytes,omitempty"`
+	ErrorMessage    *string    `json:"error_message,omitempty"`
+	Id              *uint64    `json:"id,omitempty"`
+	Progress        *float32   `json:"progress,omitempty"`
+	Status          *string    `json:"status,omitempty"`
+	TaskId          *uint64    `json:"task_id,omitempty"`
+	Time            *time.Time `json:"time,omitempty"`
+	TotalBytes      *int64     `json:"total_bytes,omitempty"`
+	Type            *string    `json:"type,omitempty"`
+}
+
+// TaskFilter defines model for TaskFilter.
+type TaskFilter struct {
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+	Search      *string    `json:"search,omitempty"`
+	SourceType  *[]string  `json:"source_type,omitempty"`
+	Status      *[]string  `json:"status,omitempty"`
+	Tags        *[]string  `json:"tags,omitempty"`
+}
+
+// UpdateScheduleRequest defines model for UpdateScheduleRequest.
+type UpdateScheduleRequest struct {
+	Cron    *string    `json:"cron,omitempty"`
+	Enabled *bool      `json:"enabled,omitempty"`
+	Id      uint64     `json:"id"`
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// UpdateWebhookRequest defines model for UpdateWebhookRequest.
+type UpdateWebhookRequest struct {
+	Enabled      *bool     `json:"enabled,omitempty"`
+	Events       *[]string `json:"events,omitempty"`
+	Id           uint64    `json:"id"`
+	RotateSecret *bool     `json:"rotate_secret,omitempty"`
+	Url          *string   `json:"url,omitempty"`
+}
+
+// Webhook defines model for Webhook.
+type Webhook struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Enabled   *bool      `json:"enabled,omitempty"`
+	Events    *[]string  `json:"events,omitempty"`
+	Id        *uint64    `json:"id,omitempty"`
+	Secret    *string    `json:"secret,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Url       *string    `json:"url,omitempty"`
+}
+
+// WebhookDelivery defines model for WebhookDelivery.
+type WebhookDelivery struct {
+	Attempts      *uint32    `json:"attempts,omitempty"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	Event         *string    `json:"event,omitempty"`
+	EventId       *string    `json:"event_id,omitempty"`
+	Id            *uint64    `json:"id,omitempty"`
+	LastError     *string    `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	Payload       *string    `json:"payload,omitempty"`
+	ResponseCode  *int32     `json:"response_code,omitempty"`
+	Status        *string    `json:"status,omitempty"`
+	TaskId        *uint64    `json:"task_id,omitempty"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+	WebhookId     *uint64    `json:"webhook_id,omitempty"`
+}
+
+// WebhookResponse defines model for WebhookResponse.
+type WebhookResponse struct {
+	Webhook *Webhook `json:"webhook,omitempty"`
+}
+
+// Workspace defines model for Workspace.
+type Workspace struct {
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Id        *uint64    `json:"id,omitempty"`
+	Name      *string    `json:"name,omitempty"`
+
+	// Role Role of the caller in the workspace.
+	Role *string `json:"role,omitempty"`
+}
+
+// WorkspaceMember defines model for WorkspaceMember.
+type WorkspaceMember struct {
+	AccountId   *uint64    `json:"account_id,omitempty"`
+	AccountName *string    `json:"account_name,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Role        *string    `json:"role,omitempty"`
+}
+
+// RevokeAPIKeyParams defines parameters for RevokeAPIKey.
+type RevokeAPIKeyParams struct {
+	Id uint64 `form:"id" json:"id"`
+}
+
+// ListAuditEventsParams defines parameters for ListAuditEvents.
+type ListAuditEventsParams struct {
+	TaskId      *uint64    `form:"task_id,omitempty" json:"task_id,omitempty"`
+	Action      *[]string  `form:"action,omitempty" json:"action,omitempty"`
+	Outcome     *string    `form:"outcome,omitempty" json:"outcome,omitempty"`
+	From        *time.Time `form:"from,omitempty" json:"from,omitempty"`
+	To          *time.Time `form:"to,omitempty" json:"to,omitempty"`
+	WorkspaceId *uint64    `form:"workspace_id,omitempty" json:"workspace_id,omitempty"`
+	BeforeId    *uint64    `form:"before_id,omitempty" json:"before_id,omitempty"`
+	Limit       *uint64    `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ExportAuditEventsParams defines parameters for ExportAuditEvents.
+type ExportAuditEventsParams struct {
+	TaskId      *uint64    `form:"task_id,omitempty" json:"task_id,omitempty"`
+	Action      *[]string  `form:"action,omitempty" json:"action,omitempty"`
+	Outcome     *string    `form:"outcome,omitempty" json:"outcome,omitempty"`
+	From        *time.Time `form:"from,omitempty" json:"from,omitempty"`
+	To          *time.Time `form:"to,omitempty" json:"to,omitempty"`
+	WorkspaceId *uint64    `form:"workspace_id,omitempty" json:"workspace_id,omitempty"`
+}
+
+// CompleteOIDCLoginParams defines parameters for CompleteOIDCLogin.
+type CompleteOIDCLoginParams struct {
+	Code             *string `form:"code,omitempty" json:"code,omitempty"`
+	State            *string `form:"state,omitempty" json:"state,omitempty"`
+	Error            *string `form:"error,omitempty" json:"error,omitempty"`
+	ErrorDescription *string `form:"error_description,omitempty" json:"error_description,omitempty"`
+}
+
+// BeginOIDCLoginParams defines parameters for BeginOIDCLogin.
+type BeginOIDCLoginParams struct {
+	// Redirect Redirect to the provider instead of returning its URL.
+	Redirect *bool `form:"redirect,omitempty" json:"redirect,omitempty"`
+}
+
+// DeleteScheduleParams defines parameters for DeleteSchedule.
+type DeleteScheduleParams struct {
+	Id uint64 `form:"id" json:"id"`
+}
+
+// GetScheduleParams defines parameters for GetSchedule.
+type GetScheduleParams struct {
+	Id uint64 `form:"id" json:"id"`
+}
+
+// ListSchedulesParams defines parameters for ListSchedules.
+type ListSchedulesParams struct {
+	Offset *uint64 `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit  *uint64 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// CreateTasksParams defines parameters for CreateTasks.
+type CreateTasksParams struct {
+	Priority *string `form:"priority,omitempty" json:"priority,omitempty"`
 }
 
 // CancelTaskParams defines parameters for CancelTask.
@@ -144,8 +558,18 @@ type GetTaskParams struct {
 
 // ListTasksParams defines parameters for ListTasks.
 type ListTasksParams struct {
-	Offset *uint64 `form:"offset,omitempty" json:"offset,omitempty"`
-	Limit  *uint64 `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset      *uint64    `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit       *uint64    `form:"limit,omitempty" json:"limit,omitempty"`
+	Cursor      *string    `form:"cursor,omitempty" json:"cursor,omitempty"`
+	Status      *[]string  `form:"status,omitempty" json:"status,omitempty"`
+	Tags        *[]string  `form:"tags,omitempty" json:"tags,omitempty"`
+	SourceType  *[]string  `form:"source_type,omitempty" json:"source_type,omitempty"`
+	CreatedFrom *time.Time `form:"created_from,omitempty" json:"created_from,omitempty"`
+	CreatedTo   *time.Time `form:"created_to,omitempty" json:"created_to,omitempty"`
+	Search      *string    `form:"search,omitempty" json:"search,omitempty"`
+	SortBy      *string    `form:"sort_by,omitempty" json:"sort_by,omitempty"`
+	SortOrder   *string    `form:"sort_order,omitempty" json:"sort_order,omitempty"`
+	WorkspaceId *uint64    `form:"workspace_id,omitempty" json:"workspace_id,omitempty"`
 }
 
 // PauseTaskParams defines parameters for PauseTask.
@@ -168,19 +592,89 @@ type RetryTaskParams struct {
 	Id uint64 `form:"id" json:"id"`
 }
 
+// WatchTasksParams defines parameters for WatchTasks.
+type WatchTasksParams struct {
+	WorkspaceId *uint64 `form:"workspace_id,omitempty" json:"workspace_id,omitempty"`
+	LastEventID *uint64 `json:"Last-Event-ID,omitempty"`
+}
+
+// DeleteWebhookParams defines parameters for DeleteWebhook.
+type DeleteWebhookParams struct {
+	Id uint64 `form:"id" json:"id"`
+}
+
+// ListWebhookDeliveriesParams defines parameters for ListWebhookDeliveries.
+type ListWebhookDeliveriesParams struct {
+	Id     uint64  `form:"id" json:"id"`
+	Offset *uint64 `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit  *uint64 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetWebhookParams defines parameters for GetWebhook.
+type GetWebhookParams struct {
+	Id uint64 `form:"id" json:"id"`
+}
+
+// ListWebhooksParams defines parameters for ListWebhooks.
+type ListWebhooksParams struct {
+	Offset *uint64 `form:"offset,omitempty" json:"offset,omitempty"`
+	Limit  *uint64 `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListWorkspaceMembersParams defines parameters for ListWorkspaceMembers.
+type ListWorkspaceMembersParams struct {
+	Id uint64 `form:"id" json:"id"`
+}
+
+// RemoveWorkspaceMemberParams defines parameters for RemoveWorkspaceMember.
+type RemoveWorkspaceMemberParams struct {
+	Id       uint64 `form:"id" json:"id"`
+	MemberId uint64 `form:"member_id" json:"member_id"`
+}
+
 // DownloadFileParams defines parameters for DownloadFile.
 type DownloadFileParams struct {
 	Token string `form:"token" json:"token"`
 }
 
+// CreateAPIKeyJSONRequestBody defines body for CreateAPIKey for application/json ContentType.
+type CreateAPIKeyJSONRequestBody = CreateAPIKeyRequest
+
 // CreateAccountJSONRequestBody defines body for CreateAccount for application/json ContentType.
 type CreateAccountJSONRequestBody = CreateAccountGatewayRequest
 
 // CreateSessionJSONRequestBody defines body for CreateSession for application/json ContentType.
 type CreateSessionJSONRequestBody = CreateSessionGatewayRequest
 
+// RefreshSessionJSONRequestBody defines body for RefreshSession for application/json ContentType.
+type RefreshSessionJSONRequestBody = RefreshSessionGatewayRequest
+
+// RevokeSessionsJSONRequestBody defines body for RevokeSessions for application/json ContentType.
+type RevokeSessionsJSONRequestBody = RevokeSessionsRequest
+
+// CreateTasksJSONRequestBody defines body for CreateTasks for application/json ContentType.
+type CreateTasksJSONRequestBody = CreateTasksRequest
+
+// BulkTasksJSONRequestBody defines body for BulkTasks for application/json ContentType.
+type BulkTasksJSONRequestBody = BulkTasksRequest
+
 // CreateTaskJSONRequestBody defines body for CreateTask for application/json ContentType.
 type CreateTaskJSONRequestBody = CreateTaskRequest
 
 // GenerateDownloadUrlJSONRequestBody defines body for GenerateDownloadUrl for application/json ContentType.
 type GenerateDownloadUrlJSONRequestBody = GenerateDownloadURLRequest
+
+// UpdateScheduleJSONRequestBody defines body for UpdateSchedule for application/json ContentType.
+type UpdateScheduleJSONRequestBody = UpdateScheduleRequest
+
+// CreateWebhookJSONRequestBody defines body for CreateWebhook for application/json ContentType.
+type CreateWebhookJSONRequestBody = CreateWebhookRequest
+
+// UpdateWebhookJSONRequestBody defines body for UpdateWebhook for application/json ContentType.
+type UpdateWebhookJSONRequestBody = UpdateWebhookRequest
+
+// CreateWorkspaceJSONRequestBody defines body for CreateWorkspace for application/json ContentType.
+type CreateWorkspaceJSONRequestBody = CreateWorkspaceRequest
+
+// SetWorkspaceMemberJSONRequestBody defines body for SetWorkspaceMember for application/json ContentType.
+type SetWorkspaceMemberJSONRequestBody = SetWorkspaceMemberRequest
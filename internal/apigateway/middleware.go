@@ -2,6 +2,7 @@ package apigateway
 
 import (
 	"context"
+	"slices"
 
 	"github.com/go-kit/kit/endpoint"
 
@@ -14,6 +15,10 @@ type contextKey int
 const (
 	tokenKey contextKey = iota
 	accountIDKey
+	scopesKey
+	apiKeyIDKey
+	sourceIPKey
+	auditTargetKey
 )
 
 type AuthMiddleware struct {
@@ -35,6 +40,10 @@ func NewAuthMiddleware(tokenValidator auth.SessionValidator) endpoint.Middleware
 			}
 
 			ctx = context.WithValue(ctx, accountIDKey, out.AccountID)
+			if out.APIKeyID != 0 {
+				ctx = context.WithValue(ctx, apiKeyIDKey, out.APIKeyID)
+				ctx = context.WithValue(ctx, scopesKey, out.Scopes)
+			}
 
 			return next(ctx, request)
 		}
@@ -53,6 +62,45 @@ func NewNoAuthMiddleware(accountID uint64) endpoint.Middleware {
 	}
 }
 
+// RequireScopeMiddleware rejects requests authenticated with an API key that
+// lacks scope. Session tokens carry every scope and always pass.
+func RequireScopeMiddleware(scope string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			if scopes, ok := ScopesFromContext(ctx); ok && !slices.Contains(scopes, scope) {
+				return nil, &errors.Error{
+					Code:    errors.ErrCodePermissionDenied,
+					Message: "api key is missing scope " + scope,
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// RequireSessionMiddleware rejects requests authenticated with an API key, so
+// that a leaked key cannot be used to manage credentials.
+func RequireSessionMiddleware() endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			if _, ok := ScopesFromContext(ctx); ok {
+				return nil, &errors.Error{
+					Code:    errors.ErrCodePermissionDenied,
+					Message: "api keys cannot manage credentials",
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// ScopesFromContext returns the scopes of the API key that authenticated the
+// request. ok is false for session tokens.
+func ScopesFromContext(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(scopesKey).([]string)
+	return scopes, ok
+}
+
 func UserIDFromContext(ctx context.Context) (uint64, bool) {
 	userID, ok := ctx.Value(accountIDKey).(uint64)
 	return userID, ok
@@ -4,16 +4,26 @@ import (
 	"context"
 
 	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/yuisofull/goload/internal/auth"
 	"github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/task"
 )
 
-// RequireTaskOwnerMiddleware returns an endpoint middleware that verifies the
-// authenticated user (from context) is the owner of the task identified by
-// idFn(request). It returns Unauthenticated if user not in context, NotFound
-// if svc.GetTask returns not found, or PermissionDenied if owner mismatch.
-func RequireTaskOwnerMiddleware(svc task.Service, idFn func(req any) uint64) endpoint.Middleware {
+// RequireTaskRoleMiddleware returns an endpoint middleware that verifies the
+// authenticated user (from context) may act on the task identified by
+// idFn(request): personal tasks only by their owner, workspace tasks by the
+// members holding at least role. It returns Unauthenticated if user not in
+// context, NotFound if svc.GetTask returns not found, or PermissionDenied
+// otherwise.
+func RequireTaskRoleMiddleware(
+	svc task.Service,
+	authSvc auth.Service,
+	role auth.WorkspaceRole,
+	idFn func(req any) uint64,
+) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request any) (any, error) {
 			userID, ok := UserIDFromContext(ctx)
@@ -26,7 +36,114 @@ func RequireTaskOwnerMiddleware(svc task.Service, idFn func(req any) uint64) end
 			if err != nil {
 				return nil, err
 			}
-			if t.OfAccountID != userID {
+			if target, ok := ctx.Value(auditTargetKey).(*task.AuditEvent); ok {
+				target.OfAccountID, target.WorkspaceID = t.OfAccountID, t.WorkspaceID
+			}
+			if t.WorkspaceID == 0 {
+				if t.OfAccountID != userID {
+					return nil, &errors.Error{Code: errors.ErrCodePermissionDenied, Message: "permission denied"}
+				}
+			} else if err := requireWorkspaceRole(ctx, authSvc, t.WorkspaceID, userID, role); err != nil {
+				return nil, err
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// RequireWorkspaceRoleMiddleware returns an endpoint middleware for requests
+// that name a workspace, such as listing or creating tasks. roleFn returns
+// the workspace of the request and the role it takes; requests without a
+// workspace act on personal tasks and pass.
+func RequireWorkspaceRoleMiddleware(
+	authSvc auth.Service,
+	roleFn func(req any) (workspaceID uint64, role auth.WorkspaceRole),
+) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			workspaceID, role := roleFn(request)
+			if workspaceID == 0 {
+				return next(ctx, request)
+			}
+
+			userID, ok := UserIDFromContext(ctx)
+			if !ok {
+				return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+			}
+			if err := requireWorkspaceRole(ctx, authSvc, workspaceID, userID, role); err != nil {
+				return nil, err
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// requireWorkspaceRole fails with PermissionDenied unless the user holds at
+// least role in the workspace. Without an auth service nobody is a member.
+func requireWorkspaceRole(
+	ctx context.Context,
+	authSvc auth.Service,
+	workspaceID, userID uint64,
+	role auth.WorkspaceRole,
+) error {
+	denied := &errors.Error{Code: errors.ErrCodePermissionDenied, Message: "permission denied"}
+	if authSvc == nil {
+		return denied
+	}
+	got, err := authSvc.GetWorkspaceRole(ctx, auth.GetWorkspaceRoleParams{AccountID: userID, WorkspaceID: workspaceID})
+	// The auth client returns gRPC status errors.
+	if errors.IsError(err, errors.ErrCodeNotFound) || status.Code(err) == codes.NotFound {
+		return denied
+	}
+	if err != nil {
+		return err
+	}
+	if !got.Allows(role) {
+		return denied
+	}
+	return nil
+}
+
+// RequireScheduleOwnerMiddleware checks that the authenticated user
+// owns the schedule identified by idFn(request). Schedules stay personal.
+func RequireScheduleOwnerMiddleware(svc task.Service, idFn func(req any) uint64) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			userID, ok := UserIDFromContext(ctx)
+			if !ok {
+				return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+			}
+
+			s, err := svc.GetSchedule(ctx, idFn(request))
+			if err != nil {
+				return nil, err
+			}
+			if s.OfAccountID != userID {
+				return nil, &errors.Error{Code: errors.ErrCodePermissionDenied, Message: "permission denied"}
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// RequireWebhookOwnerMiddleware checks that the authenticated user
+// owns the webhook identified by idFn(request). Webhooks stay personal.
+func RequireWebhookOwnerMiddleware(svc task.Service, idFn func(req any) uint64) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			userID, ok := UserIDFromContext(ctx)
+			if !ok {
+				return nil, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "unauthenticated"}
+			}
+
+			w, err := svc.GetWebhook(ctx, idFn(request))
+			if err != nil {
+				return nil, err
+			}
+			if w.OfAccountID != userID {
 				return nil, &errors.Error{Code: errors.ErrCodePermissionDenied, Message: "permission denied"}
 			}
 
@@ -3,8 +3,10 @@ package apigateway
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/fs"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,8 +22,10 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/yuisofull/goload/docs"
+	"github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/pkg/middleware"
 )
 
 type HTTPListTasksRequest struct {
@@ -54,7 +58,7 @@ type HTTPTask struct {
 }
 
 // NewHTTPHandler creates HTTP handlers for all gateway endpoints.
-// If authCreate and authSession endpoints are non-nil, register auth handlers.
+// The /api/v1/auth handlers are backed by the Auth* endpoints.
 func NewHTTPHandler(endpoints GatewayEndpoints, logger log.Logger) http.Handler {
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(encodeError),
@@ -70,6 +74,16 @@ func NewHTTPHandler(endpoints GatewayEndpoints, logger log.Logger) http.Handler
 		w.Write([]byte(`{"status":"ok"}`))
 	}).Methods(http.MethodGet)
 
+	// --- /.well-known/jwks.json ----------------------------------------
+	r.Handle("/.well-known/jwks.json", httptransport.NewServer(
+		endpoints.JWKSEndpoint,
+		func(_ context.Context, _ *http.Request) (any, error) {
+			return &JWKSRequest{}, nil
+		},
+		encodeHTTPJWKSResponse,
+		options...,
+	)).Methods(http.MethodGet)
+
 	// --- /docs ----------------------------------------------------------
 	sub, _ := fs.Sub(docs.FS, ".")
 	r.PathPrefix("/docs").Handler(http.StripPrefix("/docs/", http.FileServer(http.FS(sub))))
@@ -91,6 +105,26 @@ func NewHTTPHandler(endpoints GatewayEndpoints, logger log.Logger) http.Handler
 		options...,
 	))).Methods(http.MethodPost)
 
+	tasks.Handle("/batch", addTokenToContext(httptransport.NewServer(
+		endpoints.CreateTasksEndpoint,
+		decodeHTTPCreateTasksRequest,
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	tasks.Handle("/bulk", addTokenToContext(httptransport.NewServer(
+		endpoints.BulkTasksEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req BulkTasksRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
 	tasks.Handle("/get", addTokenToContext(httptransport.NewServer(
 		endpoints.GetTaskEndpoint,
 		decodeHTTPGetRequest,
@@ -147,6 +181,19 @@ func NewHTTPHandler(endpoints GatewayEndpoints, logger log.Logger) http.Handler
 		options...,
 	))).Methods(http.MethodGet)
 
+	tasks.Handle("/extend", addTokenToContext(httptransport.NewServer(
+		endpoints.ExtendTaskRetentionEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req ExtendTaskRetentionRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
 	tasks.Handle("/download-url", addTokenToContext(httptransport.NewServer(
 		endpoints.GenerateDownloadURLEndpoint,
 		func(_ context.Context, r *http.Request) (any, error) {
@@ -160,6 +207,177 @@ func NewHTTPHandler(endpoints GatewayEndpoints, logger log.Logger) http.Handler
 		options...,
 	))).Methods(http.MethodPost)
 
+	tasks.Handle("/events", addTokenToContext(httptransport.NewServer(
+		endpoints.WatchTasksEndpoint,
+		decodeHTTPWatchTasksRequest,
+		encodeHTTPTaskEvents,
+		options...,
+	))).Methods(http.MethodGet)
+
+	// --- /api/v1/usage --------------------------------------------------
+	r.Handle("/api/v1/usage", addTokenToContext(httptransport.NewServer(
+		endpoints.GetUsageEndpoint,
+		httptransport.NopRequestDecoder,
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	// --- /api/v1/schedules ----------------------------------------------
+	schedules := r.PathPrefix("/api/v1/schedules").Subrouter()
+
+	schedules.Handle("/list", addTokenToContext(httptransport.NewServer(
+		endpoints.ListSchedulesEndpoint,
+		decodeHTTPListSchedulesRequest,
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	schedules.Handle("/get", addTokenToContext(httptransport.NewServer(
+		endpoints.GetScheduleEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			return &GetScheduleRequest{Id: id}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	schedules.Handle("/update", addTokenToContext(httptransport.NewServer(
+		endpoints.UpdateScheduleEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req UpdateScheduleRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	schedules.Handle("/delete", addTokenToContext(httptransport.NewServer(
+		endpoints.DeleteScheduleEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			return &DeleteScheduleRequest{Id: id}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodDelete)
+
+	// --- /api/v1/webhooks -----------------------------------------------
+	webhooks := r.PathPrefix("/api/v1/webhooks").Subrouter()
+
+	webhooks.Handle("/create", addTokenToContext(httptransport.NewServer(
+		endpoints.CreateWebhookEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req CreateWebhookRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	webhooks.Handle("/list", addTokenToContext(httptransport.NewServer(
+		endpoints.ListWebhooksEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req ListWebhooksRequest
+			var err error
+			if req.Offset, req.Limit, err = decodeHTTPPage(r); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	webhooks.Handle("/get", addTokenToContext(httptransport.NewServer(
+		endpoints.GetWebhookEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			return &GetWebhookRequest{Id: id}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	webhooks.Handle("/update", addTokenToContext(httptransport.NewServer(
+		endpoints.UpdateWebhookEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req UpdateWebhookRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	webhooks.Handle("/delete", addTokenToContext(httptransport.NewServer(
+		endpoints.DeleteWebhookEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			return &DeleteWebhookRequest{Id: id}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodDelete)
+
+	webhooks.Handle("/deliveries", addTokenToContext(httptransport.NewServer(
+		endpoints.ListWebhookDeliveriesEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			req := ListWebhookDeliveriesRequest{Id: id}
+			if req.Offset, req.Limit, err = decodeHTTPPage(r); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	// --- /api/v1/audit --------------------------------------------------
+	r.Handle("/api/v1/audit", addTokenToContext(httptransport.NewServer(
+		endpoints.ListAuditEventsEndpoint,
+		decodeHTTPListAuditEventsRequest,
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	r.Handle("/api/v1/audit/export", addTokenToContext(httptransport.NewServer(
+		endpoints.ExportAuditEventsEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			filter, err := decodeHTTPAuditFilter(r)
+			if err != nil {
+				return nil, err
+			}
+			return &filter, nil
+		},
+		encodeHTTPAuditExport,
+		options...,
+	))).Methods(http.MethodGet)
+
 	// --- /api/v1/auth ---------------------------------------------------
 	auth := r.PathPrefix("/api/v1/auth").Subrouter()
 
@@ -189,6 +407,180 @@ func NewHTTPHandler(endpoints GatewayEndpoints, logger log.Logger) http.Handler
 		options...,
 	)).Methods(http.MethodPost)
 
+	auth.Handle("/refresh", httptransport.NewServer(
+		endpoints.AuthRefreshEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req RefreshSessionGatewayRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	)).Methods(http.MethodPost)
+
+	auth.Handle("/oidc/login", httptransport.NewServer(
+		endpoints.BeginOIDCLoginEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req BeginOIDCLoginRequest
+			if v := r.URL.Query().Get("redirect"); v != "" {
+				redirect, err := strconv.ParseBool(v)
+				if err != nil {
+					return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "invalid redirect", Cause: err}
+				}
+				req.Redirect = &redirect
+			}
+			return &req, nil
+		},
+		encodeHTTPBeginOIDCLoginResponse,
+		options...,
+	)).Methods(http.MethodGet)
+
+	auth.Handle("/oidc/callback", httptransport.NewServer(
+		endpoints.OIDCCallbackEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			q := r.URL.Query()
+			return &OIDCCallbackRequest{
+				Code:             lo.EmptyableToPtr(q.Get("code")),
+				State:            lo.EmptyableToPtr(q.Get("state")),
+				Error:            lo.EmptyableToPtr(q.Get("error")),
+				ErrorDescription: lo.EmptyableToPtr(q.Get("error_description")),
+			}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	)).Methods(http.MethodGet)
+
+	auth.Handle("/logout", addTokenToContext(httptransport.NewServer(
+		endpoints.AuthLogoutEndpoint,
+		func(_ context.Context, _ *http.Request) (any, error) {
+			return &LogoutRequest{}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	auth.Handle("/sessions/revoke", addTokenToContext(httptransport.NewServer(
+		endpoints.AuthRevokeSessionsEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			// An empty body revokes every session of the account.
+			var req RevokeSessionsRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	// --- /api/v1/api-keys -----------------------------------------------
+	apiKeys := r.PathPrefix("/api/v1/api-keys").Subrouter()
+
+	apiKeys.Handle("/create", addTokenToContext(httptransport.NewServer(
+		endpoints.CreateAPIKeyEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req CreateAPIKeyRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	apiKeys.Handle("/list", addTokenToContext(httptransport.NewServer(
+		endpoints.ListAPIKeysEndpoint,
+		func(_ context.Context, _ *http.Request) (any, error) {
+			return &ListAPIKeysRequest{}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	apiKeys.Handle("/revoke", addTokenToContext(httptransport.NewServer(
+		endpoints.RevokeAPIKeyEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			return &RevokeAPIKeyRequest{Id: id}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodDelete)
+
+	// --- /api/v1/workspaces ---------------------------------------------
+	workspaces := r.PathPrefix("/api/v1/workspaces").Subrouter()
+
+	workspaces.Handle("/create", addTokenToContext(httptransport.NewServer(
+		endpoints.CreateWorkspaceEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req CreateWorkspaceRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	workspaces.Handle("/list", addTokenToContext(httptransport.NewServer(
+		endpoints.ListWorkspacesEndpoint,
+		func(_ context.Context, _ *http.Request) (any, error) {
+			return &ListWorkspacesRequest{}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	workspaces.Handle("/members", addTokenToContext(httptransport.NewServer(
+		endpoints.ListWorkspaceMembersEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			return &ListWorkspaceMembersRequest{Id: id}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodGet)
+
+	workspaces.Handle("/members/set", addTokenToContext(httptransport.NewServer(
+		endpoints.SetWorkspaceMemberEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			var req SetWorkspaceMemberRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodPost)
+
+	workspaces.Handle("/members/remove", addTokenToContext(httptransport.NewServer(
+		endpoints.RemoveWorkspaceMemberEndpoint,
+		func(_ context.Context, r *http.Request) (any, error) {
+			id, err := decodeHTTPQueryUint64(r, "id")
+			if err != nil {
+				return nil, err
+			}
+			memberID, err := decodeHTTPQueryUint64(r, "member_id")
+			if err != nil {
+				return nil, err
+			}
+			return &RemoveWorkspaceMemberRequest{Id: id, MemberId: memberID}, nil
+		},
+		encodeHTTPResponse,
+		options...,
+	))).Methods(http.MethodDelete)
+
 	return r
 }
 
@@ -234,6 +626,24 @@ func NewHTTPHandlerWithDownload(
 
 		level.Info(logger).Log("msg", "token validated", "task_key", meta.Key, "owner", meta.OwnerID)
 
+		// Anyone holding the URL can download, so the event has no actor.
+		// The task service fills in the owner of the task.
+		recordDownload := func(outcome task.AuditOutcome, message string) {
+			if meta.TaskID == 0 || endpoints.RecordAuditEventEndpoint == nil {
+				return
+			}
+			_, err := endpoints.RecordAuditEventEndpoint(ctx, &task.AuditEvent{
+				Action:   task.AuditTaskDownload,
+				TaskID:   meta.TaskID,
+				SourceIP: clientIP(r),
+				Outcome:  outcome,
+				Message:  message,
+			})
+			if err != nil {
+				level.Warn(logger).Log("msg", "failed to record audit event", "task_id", meta.TaskID, "err", err)
+			}
+		}
+
 		// Set response headers from storage metadata
 		if info, err := store.GetInfo(ctx, meta.Key); err == nil && info != nil {
 			if info.ContentType != "" {
@@ -272,6 +682,7 @@ func NewHTTPHandlerWithDownload(
 			}
 			reader, err = store.GetWithRange(ctx, meta.Key, start, end)
 			if err != nil {
+				recordDownload(task.AuditFailed, "failed to fetch range")
 				http.Error(w, "failed to fetch range: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -279,11 +690,13 @@ func NewHTTPHandlerWithDownload(
 		} else {
 			reader, err = store.Get(ctx, meta.Key)
 			if err != nil {
+				recordDownload(task.AuditFailed, "failed to fetch file")
 				http.Error(w, "failed to fetch file: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
 		}
 		defer reader.Close()
+		recordDownload(task.AuditSucceeded, "")
 
 		if _, err := io.Copy(w, reader); err != nil {
 			level.Warn(logger).Log("msg", "stream interrupted", "err", err)
@@ -296,6 +709,15 @@ func NewHTTPHandlerWithDownload(
 // addTokenToContext extracts JWT token from HTTP Authorization header and adds it to context
 func addTokenToContext(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), sourceIPKey, clientIP(r)))
+
+		// Automation clients may send an API key in X-API-Key instead of
+		// the Authorization header.
+		if key := r.Header.Get("X-API-Key"); key != "" && r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey, key)))
+			return
+		}
+
 		// Extract token from Authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -323,6 +745,20 @@ func addTokenToContext(next http.Handler) http.Handler {
 	})
 }
 
+// clientIP returns the address of the client of r as resolved by
+// middleware.ClientIPHTTPMiddleware, which only honors X-Forwarded-For from
+// trusted proxies. Without it, the remote address of the connection is used.
+// It is empty when neither is an IP address.
+func clientIP(r *http.Request) string {
+	if ip, ok := middleware.ClientIPFromContext(r.Context()); ok {
+		return ip
+	}
+	if ip := middleware.RemoteIP(r); ip.IsValid() {
+		return ip.String()
+	}
+	return ""
+}
+
 func decodeHTTPListTaskRequest(_ context.Context, r *http.Request) (any, error) {
 	offsetStr := r.URL.Query().Get("offset")
 	limitStr := r.URL.Query().Get("limit")
@@ -349,10 +785,181 @@ func decodeHTTPListTaskRequest(_ context.Context, r *http.Request) (any, error)
 	// We return a request without the filter populated; the endpoint will
 	// populate the OfAccountID from the context once the auth middleware
 	// has run.
-	return &ListTasksRequest{
-		Offset: lo.ToPtr(offset),
-		Limit:  lo.ToPtr(limit),
-	}, nil
+	req := &ListTasksRequest{
+		Offset:     lo.ToPtr(offset),
+		Limit:      lo.ToPtr(limit),
+		Status:     decodeHTTPQueryList(r, "status"),
+		Tags:       decodeHTTPQueryList(r, "tags"),
+		SourceType: decodeHTTPQueryList(r, "source_type"),
+	}
+	q := r.URL.Query()
+	for name, dst := range map[string]**string{
+		"cursor":     &req.Cursor,
+		"search":     &req.Search,
+		"sort_by":    &req.SortBy,
+		"sort_order": &req.SortOrder,
+	} {
+		if v := q.Get(name); v != "" {
+			*dst = lo.ToPtr(v)
+		}
+	}
+	for name, dst := range map[string]**time.Time{
+		"created_from": &req.CreatedFrom,
+		"created_to":   &req.CreatedTo,
+	} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, &errors.Error{
+					Code:    errors.ErrCodeInvalidInput,
+					Message: fmt.Sprintf("%s must be an RFC 3339 time", name),
+					Cause:   err,
+				}
+			}
+			*dst = &t
+		}
+	}
+	if v := q.Get("workspace_id"); v != "" {
+		workspaceID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		req.WorkspaceId = &workspaceID
+	}
+	return req, nil
+}
+
+// decodeHTTPQueryList reads a list query parameter given either repeatedly
+// or as comma separated values.
+func decodeHTTPQueryList(r *http.Request, name string) *[]string {
+	var out []string
+	for _, v := range r.URL.Query()[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return &out
+}
+
+func decodeHTTPListSchedulesRequest(_ context.Context, r *http.Request) (any, error) {
+	var req ListSchedulesRequest
+	var err error
+	if req.Offset, req.Limit, err = decodeHTTPPage(r); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// decodeHTTPPage reads the optional offset and limit query parameters.
+func decodeHTTPPage(r *http.Request) (offset, limit *uint64, err error) {
+	if r.URL.Query().Get("offset") != "" {
+		v, err := decodeHTTPQueryUint64(r, "offset")
+		if err != nil {
+			return nil, nil, err
+		}
+		offset = &v
+	}
+	if r.URL.Query().Get("limit") != "" {
+		v, err := decodeHTTPQueryUint64(r, "limit")
+		if err != nil {
+			return nil, nil, err
+		}
+		limit = &v
+	}
+	return offset, limit, nil
+}
+
+// decodeHTTPAuditFilter reads the filter query parameters of an audit
+// listing.
+func decodeHTTPAuditFilter(r *http.Request) (ExportAuditEventsRequest, error) {
+	q := r.URL.Query()
+	req := ExportAuditEventsRequest{Action: decodeHTTPQueryList(r, "action")}
+	if v := q.Get("outcome"); v != "" {
+		req.Outcome = &v
+	}
+	for name, dst := range map[string]**uint64{
+		"task_id":      &req.TaskId,
+		"workspace_id": &req.WorkspaceId,
+	} {
+		if q.Get(name) != "" {
+			v, err := decodeHTTPQueryUint64(r, name)
+			if err != nil {
+				return req, err
+			}
+			*dst = &v
+		}
+	}
+	for name, dst := range map[string]**time.Time{
+		"from": &req.From,
+		"to":   &req.To,
+	} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return req, &errors.Error{
+					Code:    errors.ErrCodeInvalidInput,
+					Message: fmt.Sprintf("%s must be an RFC 3339 time", name),
+					Cause:   err,
+				}
+			}
+			*dst = &t
+		}
+	}
+	return req, nil
+}
+
+func decodeHTTPListAuditEventsRequest(_ context.Context, r *http.Request) (any, error) {
+	filter, err := decodeHTTPAuditFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	req := ListAuditEventsRequest{
+		TaskId:      filter.TaskId,
+		Action:      filter.Action,
+		Outcome:     filter.Outcome,
+		From:        filter.From,
+		To:          filter.To,
+		WorkspaceId: filter.WorkspaceId,
+	}
+	for name, dst := range map[string]**uint64{
+		"before_id": &req.BeforeId,
+		"limit":     &req.Limit,
+	} {
+		if r.URL.Query().Get(name) != "" {
+			v, err := decodeHTTPQueryUint64(r, name)
+			if err != nil {
+				return nil, err
+			}
+			*dst = &v
+		}
+	}
+	return &req, nil
+}
+
+// decodeHTTPWatchTasksRequest reads the workspace to watch and the
+// Last-Event-ID header that browsers send when an EventSource reconnects.
+func decodeHTTPWatchTasksRequest(_ context.Context, r *http.Request) (any, error) {
+	var req WatchTasksRequest
+	if v := r.URL.Query().Get("workspace_id"); v != "" {
+		workspaceID, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "invalid workspace_id", Cause: err}
+		}
+		req.WorkspaceId = &workspaceID
+	}
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "invalid Last-Event-ID", Cause: err}
+		}
+		req.LastEventID = &id
+	}
+	return &req, nil
 }
 
 func decodeHTTPCreateRequest(_ context.Context, r *http.Request) (any, error) {
@@ -363,6 +970,39 @@ func decodeHTTPCreateRequest(_ context.Context, r *http.Request) (any, error) {
 	return &req, nil
 }
 
+// maxBatchBodySize bounds the URL lists and metalink documents accepted by
+// /tasks/batch.
+const maxBatchBodySize = 8 << 20
+
+// decodeHTTPCreateTasksRequest reads a JSON batch, or a plain text URL list or
+// metalink document uploaded as is with the priority in the query.
+func decodeHTTPCreateTasksRequest(_ context.Context, r *http.Request) (any, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	body := http.MaxBytesReader(nil, r.Body, maxBatchBodySize)
+
+	var req CreateTasksRequest
+	switch mediaType {
+	case "text/plain", "text/uri-list", "application/metalink4+xml", "application/metalink+xml":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "failed to read batch", Cause: err}
+		}
+		if strings.HasPrefix(mediaType, "text/") {
+			req.UrlList = lo.ToPtr(string(data))
+		} else {
+			req.Metalink = lo.ToPtr(string(data))
+		}
+		if p := r.URL.Query().Get("priority"); p != "" {
+			req.Priority = &p
+		}
+	default:
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return nil, err
+		}
+	}
+	return &req, nil
+}
+
 func decodeHTTPGetRequest(_ context.Context, r *http.Request) (any, error) {
 	idStr := r.URL.Query().Get("id")
 	if idStr == "" {
@@ -454,12 +1094,111 @@ func encodeHTTPResponse(_ context.Context, w http.ResponseWriter, response any)
 	return json.NewEncoder(w).Encode(response)
 }
 
+// jwksMaxAge is how long verifiers may cache the key set. Next keys are
+// published well before they sign, so a cached set stays valid.
+const jwksMaxAge = 5 * time.Minute
+
+func encodeHTTPJWKSResponse(ctx context.Context, w http.ResponseWriter, response any) error {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(jwksMaxAge.Seconds())))
+	return encodeHTTPResponse(ctx, w, response)
+}
+
+// encodeHTTPBeginOIDCLoginResponse sends browsers on to the provider. The
+// body still carries the URL for clients that do not follow redirects.
+func encodeHTTPBeginOIDCLoginResponse(ctx context.Context, w http.ResponseWriter, response any) error {
+	resp := response.(*BeginOIDCLoginResponse)
+	w.Header().Set("Cache-Control", "no-store")
+	if resp.Redirect {
+		w.Header().Set("Location", lo.FromPtr(resp.AuthUrl))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusFound)
+		return json.NewEncoder(w).Encode(resp)
+	}
+	return encodeHTTPResponse(ctx, w, resp)
+}
+
+// sseKeepAliveInterval is how often a comment is sent on an idle event
+// stream so that proxies do not close it.
+const sseKeepAliveInterval = 15 * time.Second
+
+// encodeHTTPTaskEvents writes task events as server-sent events until the
+// watch ends or the client goes away.
+func encodeHTTPTaskEvents(ctx context.Context, w http.ResponseWriter, response any) error {
+	events := response.(*WatchTasksResponse).Events
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		// The status has been sent; there is nothing left to report.
+		return nil
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(taskEventToAPI(ev))
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+		case <-keepAlive.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		case <-ctx.Done():
+			return nil
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+	}
+}
+
+// encodeHTTPAuditExport writes audit events as JSON lines, newest first,
+// fetching page after page until the export is complete.
+func encodeHTTPAuditExport(ctx context.Context, w http.ResponseWriter, response any) error {
+	resp := response.(*ExportAuditEventsResponse)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+
+	enc := json.NewEncoder(w)
+	evs := resp.Events
+	for len(evs) > 0 {
+		for _, e := range evs {
+			if err := enc.Encode(auditEventToAPI(e)); err != nil {
+				return err
+			}
+		}
+		var err error
+		if evs, err = resp.NextPage(ctx, evs[len(evs)-1].ID); err != nil {
+			// The status has been sent. The error is logged and encoded as
+			// the last line, which tells clients the export is incomplete.
+			return err
+		}
+	}
+	return nil
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
 	var statusCode int
 	errMsg := err.Error()
 
+	// Errors from an in-process service have not been through the gRPC
+	// transport yet; give them the same status mapping.
+	if errors.AsError(err) != nil {
+		err = errors.EncodeGRPCError(err)
+	}
+
 	if grpcStatus, ok := status.FromError(err); ok {
 		errMsg = grpcStatus.Message()
 		switch grpcStatus.Code() {
@@ -0,0 +1,329 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// Scopes an API key can be granted. Session tokens carry every scope.
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeDownload   = "download"
+)
+
+// Scopes lists every scope in the order they are documented.
+var Scopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeDownload}
+
+// apiKeyTokenPrefix marks a bearer token as an API key. The full key is
+// "gl_<prefix>_<secret>": the prefix finds the key, the secret proves it.
+const apiKeyTokenPrefix = "gl_"
+
+const maxAPIKeyNameLength = 128
+
+// apiKeyCacheTTL is how long a verified API key is trusted without hashing
+// its secret again. A key revoked through another replica is accepted by
+// this one for at most that long.
+const apiKeyCacheTTL = 30 * time.Second
+
+// APIKey is a long-lived credential of an account for automation clients.
+// Only the hash of its secret is stored.
+type APIKey struct {
+	Id           uint64
+	OfAccountId  uint64
+	Name         string
+	Prefix       string
+	HashedSecret string
+	Scopes       []string
+	ExpiresAt    *time.Time
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+}
+
+// HasScope reports whether the key grants scope.
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *APIKey) (uint64, error)
+	// GetAPIKeyByPrefix returns errors.ErrNotFound for unknown prefixes.
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (APIKey, error)
+	ListAPIKeys(ctx context.Context, ofAccountID uint64) ([]APIKey, error)
+	// RevokeAPIKey returns errors.ErrNotFound unless the account has an
+	// unrevoked key with the id.
+	RevokeAPIKey(ctx context.Context, ofAccountID, id uint64, at time.Time) error
+}
+
+// WithAPIKeys enables API keys kept in store.
+func WithAPIKeys(store APIKeyStore) ServiceOption {
+	return func(s *service) {
+		s.apiKeys = store
+		s.apiKeyCache = &apiKeyCache{entries: make(map[string]cachedAPIKey)}
+	}
+}
+
+// apiKeyCache holds the API keys verified recently, by prefix and the hash
+// of the secret they were presented with, so that the secret is not hashed
+// again on every request.
+type apiKeyCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedAPIKey
+}
+
+type cachedAPIKey struct {
+	key   APIKey
+	until time.Time
+}
+
+func apiKeyCacheKey(prefix, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return prefix + ":" + hex.EncodeToString(sum[:])
+}
+
+func (c *apiKeyCache) get(cacheKey string, now time.Time) (APIKey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[cacheKey]
+	if !ok {
+		return APIKey{}, false
+	}
+	if !now.Before(e.until) {
+		delete(c.entries, cacheKey)
+		return APIKey{}, false
+	}
+	return e.key, true
+}
+
+func (c *apiKeyCache) put(cacheKey string, key APIKey, now time.Time) {
+	until := now.Add(apiKeyCacheTTL)
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(until) {
+		until = *key.ExpiresAt
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey] = cachedAPIKey{key: key, until: until}
+}
+
+// evict forgets the key with the id.
+func (c *apiKeyCache) evict(id uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.key.Id == id {
+			delete(c.entries, k)
+		}
+	}
+}
+
+type CreateAPIKeyParams struct {
+	AccountID uint64
+	Name      string
+	Scopes    []string
+	// ExpiresAt is optional; keys without it are valid until revoked.
+	ExpiresAt *time.Time
+}
+
+type CreateAPIKeyOutput struct {
+	APIKey APIKey
+	// Key is the bearer token of the API key. It is only returned here.
+	Key string
+}
+
+type ListAPIKeysParams struct {
+	AccountID uint64
+}
+
+type RevokeAPIKeyParams struct {
+	AccountID uint64
+	ID        uint64
+}
+
+// ParseScopes validates scopes and returns them without duplicates, in the
+// order of Scopes.
+func ParseScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "at least one scope is required"}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown scope %q", scope)}
+		}
+	}
+	var parsed []string
+	for _, scope := range Scopes {
+		if slices.Contains(scopes, scope) {
+			parsed = append(parsed, scope)
+		}
+	}
+	return parsed, nil
+}
+
+func newAPIKeySecret() (prefix, secret string, err error) {
+	b := make([]byte, 6+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b[:6]), base64.RawURLEncoding.EncodeToString(b[6:]), nil
+}
+
+// splitAPIKey returns the prefix and secret of an API key token.
+func splitAPIKey(token string) (prefix, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(token, apiKeyTokenPrefix)
+	if !ok {
+		return "", "", false
+	}
+	prefix, secret, ok = strings.Cut(rest, "_")
+	return prefix, secret, ok && prefix != "" && secret != ""
+}
+
+func (s *service) CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (CreateAPIKeyOutput, error) {
+	if s.apiKeys == nil {
+		return CreateAPIKeyOutput{}, &errors.Error{Code: errors.ErrCodeInvalidState, Message: "api keys are disabled"}
+	}
+	if params.AccountID == 0 {
+		return CreateAPIKeyOutput{}, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "account id is required"}
+	}
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return CreateAPIKeyOutput{}, &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("name must be 1 to %d characters", maxAPIKeyNameLength),
+		}
+	}
+	scopes, err := ParseScopes(params.Scopes)
+	if err != nil {
+		return CreateAPIKeyOutput{}, err
+	}
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return CreateAPIKeyOutput{}, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "expires_at must be in the future"}
+	}
+
+	prefix, secret, err := newAPIKeySecret()
+	if err != nil {
+		return CreateAPIKeyOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate api key", Cause: err}
+	}
+	hash, err := s.passwordHasher.Hash(ctx, secret)
+	if err != nil {
+		return CreateAPIKeyOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "hashing api key failed", Cause: err}
+	}
+	key := APIKey{
+		OfAccountId:  params.AccountID,
+		Name:         name,
+		Prefix:       prefix,
+		HashedSecret: hash,
+		Scopes:       scopes,
+		ExpiresAt:    params.ExpiresAt,
+		CreatedAt:    time.Now(),
+	}
+	if key.Id, err = s.apiKeys.CreateAPIKey(ctx, &key); err != nil {
+		return CreateAPIKeyOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "creating api key failed", Cause: err}
+	}
+	return CreateAPIKeyOutput{APIKey: key, Key: apiKeyTokenPrefix + prefix + "_" + secret}, nil
+}
+
+func (s *service) ListAPIKeys(ctx context.Context, params ListAPIKeysParams) ([]APIKey, error) {
+	if s.apiKeys == nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidState, Message: "api keys are disabled"}
+	}
+	keys, err := s.apiKeys.ListAPIKeys(ctx, params.AccountID)
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list api keys", Cause: err}
+	}
+	return keys, nil
+}
+
+func (s *service) RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams) error {
+	if s.apiKeys == nil {
+		return &errors.Error{Code: errors.ErrCodeInvalidState, Message: "api keys are disabled"}
+	}
+	err := s.apiKeys.RevokeAPIKey(ctx, params.AccountID, params.ID, time.Now())
+	s.apiKeyCache.evict(params.ID)
+	if stderrors.Is(err, errors.ErrNotFound) {
+		return &errors.Error{Code: errors.ErrCodeNotFound, Message: "api key not found"}
+	}
+	if err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to revoke api key", Cause: err}
+	}
+	return nil
+}
+
+// verifyAPIKey authenticates an API key token. The secret is only hashed
+// for keys that are neither revoked nor expired, and not again while the
+// key is cached. Keys created before all sessions of their account were
+// revoked are rejected as well.
+func (s *service) verifyAPIKey(ctx context.Context, token string) (VerifySessionOutput, error) {
+	invalid := &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid api key"}
+	prefix, secret, ok := splitAPIKey(token)
+	if !ok || s.apiKeys == nil {
+		return VerifySessionOutput{}, invalid
+	}
+
+	now := time.Now()
+	cacheKey := apiKeyCacheKey(prefix, secret)
+	key, cached := s.apiKeyCache.get(cacheKey, now)
+	if !cached {
+		var err error
+		key, err = s.apiKeys.GetAPIKeyByPrefix(ctx, prefix)
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return VerifySessionOutput{}, invalid
+		}
+		if err != nil {
+			return VerifySessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get api key", Cause: err}
+		}
+		if key.RevokedAt != nil {
+			return VerifySessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "api key revoked"}
+		}
+		if key.ExpiresAt != nil && key.ExpiresAt.Before(now) {
+			return VerifySessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "api key expired"}
+		}
+	}
+
+	if revoked, err := s.isRevoked(ctx, "", "", key.OfAccountId, key.CreatedAt); err != nil {
+		return VerifySessionOutput{}, err
+	} else if revoked {
+		return VerifySessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "api key revoked"}
+	}
+
+	if !cached {
+		if err := s.passwordHasher.Verify(ctx, secret, key.HashedSecret); err != nil {
+			return VerifySessionOutput{}, invalid
+		}
+		s.apiKeyCache.put(cacheKey, key, now)
+	}
+	return VerifySessionOutput{AccountID: key.OfAccountId, APIKeyID: key.Id, Scopes: key.Scopes}, nil
+}
+
+// revokeAPIKeys revokes the keys of the account, so that they stay revoked
+// once the revocation of its sessions expired.
+func (s *service) revokeAPIKeys(ctx context.Context, accountID uint64, at time.Time) error {
+	if s.apiKeys == nil {
+		return nil
+	}
+	keys, err := s.apiKeys.ListAPIKeys(ctx, accountID)
+	if err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list api keys", Cause: err}
+	}
+	for _, key := range keys {
+		if key.RevokedAt != nil {
+			continue
+		}
+		err := s.apiKeys.RevokeAPIKey(ctx, accountID, key.Id, at)
+		s.apiKeyCache.evict(key.Id)
+		if err != nil && !stderrors.Is(err, errors.ErrNotFound) {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to revoke api key", Cause: err}
+		}
+	}
+	return nil
+}
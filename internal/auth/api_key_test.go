@@ -0,0 +1,210 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/auth"
+	authcache "github.com/yuisofull/goload/internal/auth/cache"
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/pkg/cache/inmem"
+)
+
+type fakeAPIKeyStore struct {
+	keys map[uint64]*auth.APIKey
+}
+
+func (f *fakeAPIKeyStore) CreateAPIKey(_ context.Context, key *auth.APIKey) (uint64, error) {
+	id := uint64(len(f.keys) + 1)
+	k := *key
+	k.Id = id
+	f.keys[id] = &k
+	return id, nil
+}
+
+func (f *fakeAPIKeyStore) GetAPIKeyByPrefix(_ context.Context, prefix string) (auth.APIKey, error) {
+	for _, k := range f.keys {
+		if k.Prefix == prefix {
+			return *k, nil
+		}
+	}
+	return auth.APIKey{}, apperrors.ErrNotFound
+}
+
+func (f *fakeAPIKeyStore) ListAPIKeys(_ context.Context, ofAccountID uint64) ([]auth.APIKey, error) {
+	var keys []auth.APIKey
+	for _, k := range f.keys {
+		if k.OfAccountId == ofAccountID {
+			keys = append(keys, *k)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeAPIKeyStore) RevokeAPIKey(_ context.Context, ofAccountID, id uint64, at time.Time) error {
+	k, ok := f.keys[id]
+	if !ok || k.OfAccountId != ofAccountID || k.RevokedAt != nil {
+		return apperrors.ErrNotFound
+	}
+	k.RevokedAt = &at
+	return nil
+}
+
+func newAPIKeyService(t *testing.T) (auth.Service, *fakeAPIKeyStore) {
+	t.Helper()
+	store := &fakeAPIKeyStore{keys: map[uint64]*auth.APIKey{}}
+	return auth.NewService(
+		&fakeAccountStore{account: &auth.Account{Id: 7, AccountName: "alice"}},
+		fakePasswordStore{},
+		noTx{},
+		plainHasher{},
+		auth.NewNoopTokenManager(time.Hour),
+		auth.WithAPIKeys(store),
+	), store
+}
+
+func TestAPIKey_VerifyCarriesScopesUntilRevoked(t *testing.T) {
+	ctx := context.Background()
+	svc, store := newAPIKeyService(t)
+
+	out, err := svc.CreateAPIKey(ctx, auth.CreateAPIKeyParams{
+		AccountID: 7,
+		Name:      "ci",
+		Scopes:    []string{auth.ScopeDownload, auth.ScopeTasksRead, auth.ScopeTasksRead},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.Key, "gl_"))
+	assert.Equal(t, []string{auth.ScopeTasksRead, auth.ScopeDownload}, out.APIKey.Scopes)
+	assert.NotContains(t, store.keys[out.APIKey.Id].HashedSecret, "gl_")
+
+	v, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: out.Key})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), v.AccountID)
+	assert.Equal(t, out.APIKey.Id, v.APIKeyID)
+	assert.Equal(t, []string{auth.ScopeTasksRead, auth.ScopeDownload}, v.Scopes)
+
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: out.Key + "x"})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+
+	require.NoError(t, svc.RevokeAPIKey(ctx, auth.RevokeAPIKeyParams{AccountID: 7, ID: out.APIKey.Id}))
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: out.Key})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+
+	err = svc.RevokeAPIKey(ctx, auth.RevokeAPIKeyParams{AccountID: 8, ID: out.APIKey.Id})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeNotFound))
+}
+
+func TestAPIKey_Expired(t *testing.T) {
+	ctx := context.Background()
+	svc, store := newAPIKeyService(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+	out, err := svc.CreateAPIKey(ctx, auth.CreateAPIKeyParams{
+		AccountID: 7,
+		Name:      "nightly",
+		Scopes:    []string{auth.ScopeTasksWrite},
+		ExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	store.keys[out.APIKey.Id].ExpiresAt = &past
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: out.Key})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+}
+
+// countingHasher counts the secrets it verifies.
+type countingHasher struct {
+	plainHasher
+	verified int
+}
+
+func (h *countingHasher) Verify(ctx context.Context, password, hashed string) error {
+	h.verified++
+	return h.plainHasher.Verify(ctx, password, hashed)
+}
+
+func TestAPIKey_RejectedAfterSessionsOfAccountRevoked(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAPIKeyStore{keys: map[uint64]*auth.APIKey{}}
+	hasher := &countingHasher{}
+	svc := auth.NewService(
+		&fakeAccountStore{account: &auth.Account{Id: 7, AccountName: "alice"}},
+		fakePasswordStore{},
+		noTx{},
+		hasher,
+		auth.NewNoopTokenManager(time.Hour),
+		auth.WithAPIKeys(store),
+		auth.WithRevocationList(authcache.NewRevocationList(inmem.New[string, int64](time.Minute))),
+	)
+	create := func() auth.CreateAPIKeyOutput {
+		out, err := svc.CreateAPIKey(ctx, auth.CreateAPIKeyParams{AccountID: 7, Name: "ci", Scopes: []string{auth.ScopeDownload}})
+		require.NoError(t, err)
+		return out
+	}
+
+	before := create()
+	for range 2 {
+		_, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: before.Key})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, hasher.verified, "a verified key is cached")
+
+	require.NoError(t, svc.RevokeSession(ctx, auth.RevokeSessionParams{AccountID: 7}))
+	require.NotNil(t, store.keys[before.APIKey.Id].RevokedAt)
+
+	// The revocation of the sessions rejects the key even while the key
+	// itself is cached or not revoked.
+	store.keys[before.APIKey.Id].RevokedAt = nil
+	_, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: before.Key})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+
+	after := create()
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: after.Key})
+	require.NoError(t, err)
+}
+
+func TestAPIKey_RevokedKeyIsNotVerified(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeAPIKeyStore{keys: map[uint64]*auth.APIKey{}}
+	hasher := &countingHasher{}
+	svc := auth.NewService(
+		&fakeAccountStore{account: &auth.Account{Id: 7, AccountName: "alice"}},
+		fakePasswordStore{},
+		noTx{},
+		hasher,
+		auth.NewNoopTokenManager(time.Hour),
+		auth.WithAPIKeys(store),
+	)
+	out, err := svc.CreateAPIKey(ctx, auth.CreateAPIKeyParams{AccountID: 7, Name: "ci", Scopes: []string{auth.ScopeDownload}})
+	require.NoError(t, err)
+	now := time.Now()
+	store.keys[out.APIKey.Id].RevokedAt = &now
+
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: out.Key})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	assert.Zero(t, hasher.verified)
+}
+
+func TestAPIKey_CreateValidatesInput(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := newAPIKeyService(t)
+	past := time.Now().Add(-time.Hour)
+
+	for name, params := range map[string]auth.CreateAPIKeyParams{
+		"no scopes":     {AccountID: 7, Name: "ci"},
+		"unknown scope": {AccountID: 7, Name: "ci", Scopes: []string{"admin"}},
+		"no name":       {AccountID: 7, Name: " ", Scopes: []string{auth.ScopeDownload}},
+		"past expiry":   {AccountID: 7, Name: "ci", Scopes: []string{auth.ScopeDownload}, ExpiresAt: &past},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.CreateAPIKey(ctx, params)
+			assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+		})
+	}
+}
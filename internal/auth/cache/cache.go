@@ -0,0 +1,5 @@
+package authcache
+
+import "context"
+
+type CacheErrorHandler func(context.Context, error)
@@ -0,0 +1,87 @@
+package authcache
+
+import (
+	"context"
+	stdErrors "errors"
+	"time"
+
+	"github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/pkg/cache"
+)
+
+type revocationList struct {
+	cache cache.Cache[string, int64]
+}
+
+// NewRevocationList returns a revocation list keeping the revocation time of
+// every id in cache, in Unix nanoseconds, until its ttl runs out.
+func NewRevocationList(cache cache.Cache[string, int64]) auth.RevocationList {
+	return &revocationList{cache: cache}
+}
+
+func (r *revocationList) Revoke(ctx context.Context, id string, at time.Time, ttl time.Duration) error {
+	return r.cache.Set(ctx, id, at.UnixNano(), ttl)
+}
+
+func (r *revocationList) RevokedAt(ctx context.Context, id string) (time.Time, bool, error) {
+	v, err := r.cache.Get(ctx, id)
+	if stdErrors.Is(err, cache.Nil) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.Unix(0, v), true, nil
+}
+
+type refreshTokenStore struct {
+	cache cache.Cache[string, auth.RefreshSession]
+}
+
+// NewRefreshTokenStore returns a refresh token store backed by cache. The
+// cache must implement GetAndDelete atomically so that a refresh token can
+// only be exchanged once.
+func NewRefreshTokenStore(cache cache.Cache[string, auth.RefreshSession]) auth.RefreshTokenStore {
+	return &refreshTokenStore{cache: cache}
+}
+
+func (r *refreshTokenStore) SaveRefreshToken(
+	ctx context.Context,
+	tokenHash string,
+	session auth.RefreshSession,
+	ttl time.Duration,
+) error {
+	return r.cache.Set(ctx, tokenHash, session, ttl)
+}
+
+func (r *refreshTokenStore) TakeRefreshToken(ctx context.Context, tokenHash string) (auth.RefreshSession, error) {
+	session, err := r.cache.GetAndDelete(ctx, tokenHash)
+	if stdErrors.Is(err, cache.Nil) {
+		return auth.RefreshSession{}, errors.ErrNotFound
+	}
+	return session, err
+}
+
+type oidcLoginStore struct {
+	cache cache.Cache[string, auth.OIDCLogin]
+}
+
+// NewOIDCLoginStore returns an OpenID Connect login store backed by cache.
+// Like NewRefreshTokenStore, it relies on an atomic GetAndDelete so that a
+// login state can only be completed once.
+func NewOIDCLoginStore(cache cache.Cache[string, auth.OIDCLogin]) auth.OIDCLoginStore {
+	return &oidcLoginStore{cache: cache}
+}
+
+func (o *oidcLoginStore) SaveOIDCLogin(ctx context.Context, state string, login auth.OIDCLogin, ttl time.Duration) error {
+	return o.cache.Set(ctx, state, login, ttl)
+}
+
+func (o *oidcLoginStore) TakeOIDCLogin(ctx context.Context, state string) (auth.OIDCLogin, error) {
+	login, err := o.cache.GetAndDelete(ctx, state)
+	if stdErrors.Is(err, cache.Nil) {
+		return auth.OIDCLogin{}, errors.ErrNotFound
+	}
+	return login, err
+}
@@ -2,10 +2,12 @@ package authendpoint
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/ratelimit"
 	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/yuisofull/goload/internal/auth"
 	pb "github.com/yuisofull/goload/internal/auth/pb"
@@ -25,10 +27,102 @@ type (
 	VerifyTokenResponse pb.VerifySessionResponse
 )
 
+type (
+	RefreshSessionRequest  pb.RefreshSessionRequest
+	RefreshSessionResponse pb.RefreshSessionResponse
+)
+
+type (
+	LogoutRequest  pb.LogoutRequest
+	LogoutResponse pb.LogoutResponse
+)
+
+type (
+	RevokeSessionRequest  pb.RevokeSessionRequest
+	RevokeSessionResponse pb.RevokeSessionResponse
+)
+
+type (
+	CreateAPIKeyRequest  pb.CreateAPIKeyRequest
+	CreateAPIKeyResponse pb.CreateAPIKeyResponse
+)
+
+type (
+	ListAPIKeysRequest  pb.ListAPIKeysRequest
+	ListAPIKeysResponse pb.ListAPIKeysResponse
+)
+
+type (
+	RevokeAPIKeyRequest  pb.RevokeAPIKeyRequest
+	RevokeAPIKeyResponse pb.RevokeAPIKeyResponse
+)
+
+type (
+	ListPublicKeysRequest  pb.ListPublicKeysRequest
+	ListPublicKeysResponse pb.ListPublicKeysResponse
+)
+
+type (
+	CreateWorkspaceRequest  pb.CreateWorkspaceRequest
+	CreateWorkspaceResponse pb.CreateWorkspaceResponse
+)
+
+type (
+	ListWorkspacesRequest  pb.ListWorkspacesRequest
+	ListWorkspacesResponse pb.ListWorkspacesResponse
+)
+
+type (
+	GetWorkspaceRoleRequest  pb.GetWorkspaceRoleRequest
+	GetWorkspaceRoleResponse pb.GetWorkspaceRoleResponse
+)
+
+type (
+	ListWorkspaceMembersRequest  pb.ListWorkspaceMembersRequest
+	ListWorkspaceMembersResponse pb.ListWorkspaceMembersResponse
+)
+
+type (
+	SetWorkspaceMemberRequest  pb.SetWorkspaceMemberRequest
+	SetWorkspaceMemberResponse pb.SetWorkspaceMemberResponse
+)
+
+type (
+	RemoveWorkspaceMemberRequest  pb.RemoveWorkspaceMemberRequest
+	RemoveWorkspaceMemberResponse pb.RemoveWorkspaceMemberResponse
+)
+
+type (
+	BeginOIDCLoginRequest  pb.BeginOIDCLoginRequest
+	BeginOIDCLoginResponse pb.BeginOIDCLoginResponse
+)
+
+type (
+	CompleteOIDCLoginRequest  pb.CompleteOIDCLoginRequest
+	CompleteOIDCLoginResponse pb.CompleteOIDCLoginResponse
+)
+
 type Set struct {
-	CreateAccountEndpoint endpoint.Endpoint
-	CreateSessionEndpoint endpoint.Endpoint
-	VerifyTokenEndpoint   endpoint.Endpoint
+	CreateAccountEndpoint  endpoint.Endpoint
+	CreateSessionEndpoint  endpoint.Endpoint
+	VerifyTokenEndpoint    endpoint.Endpoint
+	RefreshSessionEndpoint endpoint.Endpoint
+	LogoutEndpoint         endpoint.Endpoint
+	RevokeSessionEndpoint  endpoint.Endpoint
+	CreateAPIKeyEndpoint   endpoint.Endpoint
+	ListAPIKeysEndpoint    endpoint.Endpoint
+	RevokeAPIKeyEndpoint   endpoint.Endpoint
+	ListPublicKeysEndpoint endpoint.Endpoint
+
+	CreateWorkspaceEndpoint       endpoint.Endpoint
+	ListWorkspacesEndpoint        endpoint.Endpoint
+	GetWorkspaceRoleEndpoint      endpoint.Endpoint
+	ListWorkspaceMembersEndpoint  endpoint.Endpoint
+	SetWorkspaceMemberEndpoint    endpoint.Endpoint
+	RemoveWorkspaceMemberEndpoint endpoint.Endpoint
+
+	BeginOIDCLoginEndpoint    endpoint.Endpoint
+	CompleteOIDCLoginEndpoint endpoint.Endpoint
 }
 
 // MakeCreateAccountEndpoint creates an endpoint for the CreateAccount service method
@@ -73,6 +167,8 @@ func MakeCreateSessionEndpoint(svc auth.Service) endpoint.Endpoint {
 				Id:          output.Account.Id,
 				AccountName: output.Account.AccountName,
 			},
+			RefreshToken: output.RefreshToken,
+			ExpiresIn:    int64(output.ExpiresIn / time.Second),
 		}, nil
 	}
 }
@@ -90,10 +186,351 @@ func MakeVerifyTokenEndpoint(svc auth.Service) endpoint.Endpoint {
 		}
 		return &VerifyTokenResponse{
 			AccountId: output.AccountID,
+			SessionId: output.SessionID,
+			ApiKeyId:  output.APIKeyID,
+			Scopes:    output.Scopes,
+		}, nil
+	}
+}
+
+// MakeRefreshSessionEndpoint creates an endpoint for the RefreshSession service method.
+func MakeRefreshSessionEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RefreshSessionRequest)
+		output, err := svc.RefreshSession(ctx, auth.RefreshSessionParams{
+			RefreshToken: req.RefreshToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp := &RefreshSessionResponse{
+			Token:        output.Token,
+			RefreshToken: output.RefreshToken,
+			ExpiresIn:    int64(output.ExpiresIn / time.Second),
+		}
+		if output.Account != nil {
+			resp.Account = &pb.Account{
+				Id:          output.Account.Id,
+				AccountName: output.Account.AccountName,
+			}
+		}
+		return resp, nil
+	}
+}
+
+// MakeLogoutEndpoint creates an endpoint for the Logout service method.
+func MakeLogoutEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*LogoutRequest)
+		if err := svc.Logout(ctx, auth.LogoutParams{Token: req.Token}); err != nil {
+			return nil, err
+		}
+		return &LogoutResponse{}, nil
+	}
+}
+
+// MakeRevokeSessionEndpoint creates an endpoint for the RevokeSession service method.
+func MakeRevokeSessionEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RevokeSessionRequest)
+		if err := svc.RevokeSession(ctx, auth.RevokeSessionParams{
+			AccountID: req.AccountId,
+			SessionID: req.SessionId,
+		}); err != nil {
+			return nil, err
+		}
+		return &RevokeSessionResponse{}, nil
+	}
+}
+
+// MakeCreateAPIKeyEndpoint creates an endpoint for the CreateAPIKey service method.
+func MakeCreateAPIKeyEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateAPIKeyRequest)
+		params := auth.CreateAPIKeyParams{
+			AccountID: req.AccountId,
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+		}
+		if req.ExpiresAt != nil {
+			expiresAt := req.ExpiresAt.AsTime()
+			params.ExpiresAt = &expiresAt
+		}
+		output, err := svc.CreateAPIKey(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		return &CreateAPIKeyResponse{
+			ApiKey: toPBAPIKey(output.APIKey),
+			Key:    output.Key,
 		}, nil
 	}
 }
 
+// MakeListAPIKeysEndpoint creates an endpoint for the ListAPIKeys service method.
+func MakeListAPIKeysEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListAPIKeysRequest)
+		keys, err := svc.ListAPIKeys(ctx, auth.ListAPIKeysParams{AccountID: req.AccountId})
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListAPIKeysResponse{ApiKeys: make([]*pb.APIKey, 0, len(keys))}
+		for _, key := range keys {
+			resp.ApiKeys = append(resp.ApiKeys, toPBAPIKey(key))
+		}
+		return resp, nil
+	}
+}
+
+// MakeRevokeAPIKeyEndpoint creates an endpoint for the RevokeAPIKey service method.
+func MakeRevokeAPIKeyEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RevokeAPIKeyRequest)
+		if err := svc.RevokeAPIKey(ctx, auth.RevokeAPIKeyParams{
+			AccountID: req.AccountId,
+			ID:        req.Id,
+		}); err != nil {
+			return nil, err
+		}
+		return &RevokeAPIKeyResponse{}, nil
+	}
+}
+
+// MakeListPublicKeysEndpoint creates an endpoint for the ListPublicKeys service method.
+func MakeListPublicKeysEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		keys, err := svc.ListPublicKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListPublicKeysResponse{Keys: make([]*pb.TokenPublicKey, 0, len(keys))}
+		for _, key := range keys {
+			pbKey := &pb.TokenPublicKey{
+				Kid:       key.Id,
+				PublicKey: key.PublicKey,
+				State:     string(key.State),
+			}
+			if key.ActivatedAt != nil {
+				pbKey.ActivatedAt = timestamppb.New(*key.ActivatedAt)
+			}
+			resp.Keys = append(resp.Keys, pbKey)
+		}
+		return resp, nil
+	}
+}
+
+// MakeCreateWorkspaceEndpoint creates an endpoint for the CreateWorkspace service method.
+func MakeCreateWorkspaceEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateWorkspaceRequest)
+		workspace, err := svc.CreateWorkspace(ctx, auth.CreateWorkspaceParams{
+			AccountID: req.AccountId,
+			Name:      req.Name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &CreateWorkspaceResponse{Workspace: toPBWorkspace(workspace)}, nil
+	}
+}
+
+// MakeListWorkspacesEndpoint creates an endpoint for the ListWorkspaces service method.
+func MakeListWorkspacesEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListWorkspacesRequest)
+		workspaces, err := svc.ListWorkspaces(ctx, auth.ListWorkspacesParams{AccountID: req.AccountId})
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListWorkspacesResponse{Workspaces: make([]*pb.WorkspaceMembership, 0, len(workspaces))}
+		for _, w := range workspaces {
+			resp.Workspaces = append(resp.Workspaces, &pb.WorkspaceMembership{
+				Workspace: toPBWorkspace(w.Workspace),
+				Role:      string(w.Role),
+			})
+		}
+		return resp, nil
+	}
+}
+
+// MakeGetWorkspaceRoleEndpoint creates an endpoint for the GetWorkspaceRole service method.
+func MakeGetWorkspaceRoleEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetWorkspaceRoleRequest)
+		role, err := svc.GetWorkspaceRole(ctx, auth.GetWorkspaceRoleParams{
+			AccountID:   req.AccountId,
+			WorkspaceID: req.WorkspaceId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &GetWorkspaceRoleResponse{Role: string(role)}, nil
+	}
+}
+
+// MakeListWorkspaceMembersEndpoint creates an endpoint for the ListWorkspaceMembers service method.
+func MakeListWorkspaceMembersEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListWorkspaceMembersRequest)
+		members, err := svc.ListWorkspaceMembers(ctx, auth.ListWorkspaceMembersParams{
+			AccountID:   req.AccountId,
+			WorkspaceID: req.WorkspaceId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListWorkspaceMembersResponse{Members: make([]*pb.WorkspaceMember, 0, len(members))}
+		for _, m := range members {
+			resp.Members = append(resp.Members, toPBWorkspaceMember(m))
+		}
+		return resp, nil
+	}
+}
+
+// MakeSetWorkspaceMemberEndpoint creates an endpoint for the SetWorkspaceMember service method.
+func MakeSetWorkspaceMemberEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*SetWorkspaceMemberRequest)
+		member, err := svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+			AccountID:   req.AccountId,
+			WorkspaceID: req.WorkspaceId,
+			MemberName:  req.MemberName,
+			Role:        auth.WorkspaceRole(req.Role),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &SetWorkspaceMemberResponse{Member: toPBWorkspaceMember(member)}, nil
+	}
+}
+
+// MakeRemoveWorkspaceMemberEndpoint creates an endpoint for the RemoveWorkspaceMember service method.
+func MakeRemoveWorkspaceMemberEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RemoveWorkspaceMemberRequest)
+		if err := svc.RemoveWorkspaceMember(ctx, auth.RemoveWorkspaceMemberParams{
+			AccountID:   req.AccountId,
+			WorkspaceID: req.WorkspaceId,
+			MemberID:    req.MemberId,
+		}); err != nil {
+			return nil, err
+		}
+		return &RemoveWorkspaceMemberResponse{}, nil
+	}
+}
+
+// MakeBeginOIDCLoginEndpoint creates an endpoint for the BeginOIDCLogin service method.
+func MakeBeginOIDCLoginEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		output, err := svc.BeginOIDCLogin(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &BeginOIDCLoginResponse{AuthUrl: output.AuthURL, State: output.State}, nil
+	}
+}
+
+// MakeCompleteOIDCLoginEndpoint creates an endpoint for the CompleteOIDCLogin service method.
+func MakeCompleteOIDCLoginEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CompleteOIDCLoginRequest)
+		output, err := svc.CompleteOIDCLogin(ctx, auth.CompleteOIDCLoginParams{
+			State: req.State,
+			Code:  req.Code,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp := &CompleteOIDCLoginResponse{
+			Token:        output.Token,
+			RefreshToken: output.RefreshToken,
+			ExpiresIn:    int64(output.ExpiresIn / time.Second),
+		}
+		if output.Account != nil {
+			resp.Account = &pb.Account{
+				Id:          output.Account.Id,
+				AccountName: output.Account.AccountName,
+			}
+		}
+		return resp, nil
+	}
+}
+
+func toPBWorkspace(w auth.Workspace) *pb.Workspace {
+	return &pb.Workspace{
+		Id:        w.Id,
+		Name:      w.Name,
+		CreatedAt: timestamppb.New(w.CreatedAt),
+	}
+}
+
+func fromPBWorkspace(w *pb.Workspace) auth.Workspace {
+	return auth.Workspace{
+		Id:        w.GetId(),
+		Name:      w.GetName(),
+		CreatedAt: w.GetCreatedAt().AsTime(),
+	}
+}
+
+func toPBWorkspaceMember(m auth.WorkspaceMember) *pb.WorkspaceMember {
+	return &pb.WorkspaceMember{
+		WorkspaceId: m.WorkspaceId,
+		AccountId:   m.AccountId,
+		AccountName: m.AccountName,
+		Role:        string(m.Role),
+		CreatedAt:   timestamppb.New(m.CreatedAt),
+	}
+}
+
+func fromPBWorkspaceMember(m *pb.WorkspaceMember) auth.WorkspaceMember {
+	return auth.WorkspaceMember{
+		WorkspaceId: m.GetWorkspaceId(),
+		AccountId:   m.GetAccountId(),
+		AccountName: m.GetAccountName(),
+		Role:        auth.WorkspaceRole(m.GetRole()),
+		CreatedAt:   m.GetCreatedAt().AsTime(),
+	}
+}
+
+func toPBAPIKey(key auth.APIKey) *pb.APIKey {
+	out := &pb.APIKey{
+		Id:        key.Id,
+		AccountId: key.OfAccountId,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+	if key.ExpiresAt != nil {
+		out.ExpiresAt = timestamppb.New(*key.ExpiresAt)
+	}
+	if key.RevokedAt != nil {
+		out.RevokedAt = timestamppb.New(*key.RevokedAt)
+	}
+	return out
+}
+
+func fromPBAPIKey(key *pb.APIKey) auth.APIKey {
+	out := auth.APIKey{
+		Id:          key.GetId(),
+		OfAccountId: key.GetAccountId(),
+		Name:        key.GetName(),
+		Prefix:      key.GetPrefix(),
+		Scopes:      key.GetScopes(),
+		CreatedAt:   key.GetCreatedAt().AsTime(),
+	}
+	if key.GetExpiresAt() != nil {
+		t := key.GetExpiresAt().AsTime()
+		out.ExpiresAt = &t
+	}
+	if key.GetRevokedAt() != nil {
+		t := key.GetRevokedAt().AsTime()
+		out.RevokedAt = &t
+	}
+	return out
+}
+
 // New creates a new EndpointSet with all endpoints initialized
 func New(svc auth.Service) Set {
 	var createAccountEndpoint endpoint.Endpoint
@@ -115,10 +552,117 @@ func New(svc auth.Service) Set {
 		verifyTokenEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(verifyTokenEndpoint)
 	}
 
+	var refreshSessionEndpoint endpoint.Endpoint
+	{
+		refreshSessionEndpoint = MakeRefreshSessionEndpoint(svc)
+		refreshSessionEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(refreshSessionEndpoint)
+	}
+
+	var logoutEndpoint endpoint.Endpoint
+	{
+		logoutEndpoint = MakeLogoutEndpoint(svc)
+		logoutEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(logoutEndpoint)
+	}
+
+	var revokeSessionEndpoint endpoint.Endpoint
+	{
+		revokeSessionEndpoint = MakeRevokeSessionEndpoint(svc)
+		revokeSessionEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(revokeSessionEndpoint)
+	}
+
+	var createAPIKeyEndpoint endpoint.Endpoint
+	{
+		createAPIKeyEndpoint = MakeCreateAPIKeyEndpoint(svc)
+		createAPIKeyEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(createAPIKeyEndpoint)
+	}
+
+	var listAPIKeysEndpoint endpoint.Endpoint
+	{
+		listAPIKeysEndpoint = MakeListAPIKeysEndpoint(svc)
+		listAPIKeysEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(listAPIKeysEndpoint)
+	}
+
+	var revokeAPIKeyEndpoint endpoint.Endpoint
+	{
+		revokeAPIKeyEndpoint = MakeRevokeAPIKeyEndpoint(svc)
+		revokeAPIKeyEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(revokeAPIKeyEndpoint)
+	}
+
+	var listPublicKeysEndpoint endpoint.Endpoint
+	{
+		listPublicKeysEndpoint = MakeListPublicKeysEndpoint(svc)
+		listPublicKeysEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(listPublicKeysEndpoint)
+	}
+
+	var createWorkspaceEndpoint endpoint.Endpoint
+	{
+		createWorkspaceEndpoint = MakeCreateWorkspaceEndpoint(svc)
+		createWorkspaceEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(createWorkspaceEndpoint)
+	}
+
+	var listWorkspacesEndpoint endpoint.Endpoint
+	{
+		listWorkspacesEndpoint = MakeListWorkspacesEndpoint(svc)
+		listWorkspacesEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(listWorkspacesEndpoint)
+	}
+
+	var getWorkspaceRoleEndpoint endpoint.Endpoint
+	{
+		getWorkspaceRoleEndpoint = MakeGetWorkspaceRoleEndpoint(svc)
+		getWorkspaceRoleEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(getWorkspaceRoleEndpoint)
+	}
+
+	var listWorkspaceMembersEndpoint endpoint.Endpoint
+	{
+		listWorkspaceMembersEndpoint = MakeListWorkspaceMembersEndpoint(svc)
+		listWorkspaceMembersEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(listWorkspaceMembersEndpoint)
+	}
+
+	var setWorkspaceMemberEndpoint endpoint.Endpoint
+	{
+		setWorkspaceMemberEndpoint = MakeSetWorkspaceMemberEndpoint(svc)
+		setWorkspaceMemberEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(setWorkspaceMemberEndpoint)
+	}
+
+	var removeWorkspaceMemberEndpoint endpoint.Endpoint
+	{
+		removeWorkspaceMemberEndpoint = MakeRemoveWorkspaceMemberEndpoint(svc)
+		removeWorkspaceMemberEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(removeWorkspaceMemberEndpoint)
+	}
+
+	var beginOIDCLoginEndpoint endpoint.Endpoint
+	{
+		beginOIDCLoginEndpoint = MakeBeginOIDCLoginEndpoint(svc)
+		beginOIDCLoginEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(beginOIDCLoginEndpoint)
+	}
+
+	var completeOIDCLoginEndpoint endpoint.Endpoint
+	{
+		completeOIDCLoginEndpoint = MakeCompleteOIDCLoginEndpoint(svc)
+		completeOIDCLoginEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Limit(1), 100))(completeOIDCLoginEndpoint)
+	}
+
 	return Set{
-		CreateAccountEndpoint: createAccountEndpoint,
-		CreateSessionEndpoint: createSessionEndpoint,
-		VerifyTokenEndpoint:   verifyTokenEndpoint,
+		CreateAccountEndpoint:  createAccountEndpoint,
+		CreateSessionEndpoint:  createSessionEndpoint,
+		VerifyTokenEndpoint:    verifyTokenEndpoint,
+		RefreshSessionEndpoint: refreshSessionEndpoint,
+		LogoutEndpoint:         logoutEndpoint,
+		RevokeSessionEndpoint:  revokeSessionEndpoint,
+		CreateAPIKeyEndpoint:   createAPIKeyEndpoint,
+		ListAPIKeysEndpoint:    listAPIKeysEndpoint,
+		RevokeAPIKeyEndpoint:   revokeAPIKeyEndpoint,
+		ListPublicKeysEndpoint: listPublicKeysEndpoint,
+
+		CreateWorkspaceEndpoint:       createWorkspaceEndpoint,
+		ListWorkspacesEndpoint:        listWorkspacesEndpoint,
+		GetWorkspaceRoleEndpoint:      getWorkspaceRoleEndpoint,
+		ListWorkspaceMembersEndpoint:  listWorkspaceMembersEndpoint,
+		SetWorkspaceMemberEndpoint:    setWorkspaceMemberEndpoint,
+		RemoveWorkspaceMemberEndpoint: removeWorkspaceMemberEndpoint,
+
+		BeginOIDCLoginEndpoint:    beginOIDCLoginEndpoint,
+		CompleteOIDCLoginEndpoint: completeOIDCLoginEndpoint,
 	}
 }
 
@@ -149,7 +693,9 @@ func (e *Set) CreateSession(ctx context.Context, params auth.CreateSessionParams
 	out := resp.(*CreateSessionResponse)
 
 	return auth.CreateSessionOutput{
-		Token: out.Token,
+		Token:        out.Token,
+		ExpiresIn:    time.Duration(out.ExpiresIn) * time.Second,
+		RefreshToken: out.RefreshToken,
 		Account: &auth.Account{
 			Id:          out.Account.GetId(),
 			AccountName: out.Account.GetAccountName(),
@@ -181,5 +727,215 @@ func (e *Set) VerifySession(ctx context.Context, params auth.VerifySessionParams
 
 	return auth.VerifySessionOutput{
 		AccountID: out.AccountId,
+		SessionID: out.SessionId,
+		APIKeyID:  out.ApiKeyId,
+		Scopes:    out.Scopes,
+	}, nil
+}
+
+func (e *Set) RefreshSession(ctx context.Context, params auth.RefreshSessionParams) (auth.CreateSessionOutput, error) {
+	resp, err := e.RefreshSessionEndpoint(ctx, &RefreshSessionRequest{
+		RefreshToken: params.RefreshToken,
+	})
+	if err != nil {
+		return auth.CreateSessionOutput{}, err
+	}
+	out := resp.(*RefreshSessionResponse)
+
+	return auth.CreateSessionOutput{
+		Token:        out.Token,
+		ExpiresIn:    time.Duration(out.ExpiresIn) * time.Second,
+		RefreshToken: out.RefreshToken,
+		Account: &auth.Account{
+			Id:          out.Account.GetId(),
+			AccountName: out.Account.GetAccountName(),
+		},
+	}, nil
+}
+
+func (e *Set) Logout(ctx context.Context, params auth.LogoutParams) error {
+	_, err := e.LogoutEndpoint(ctx, &LogoutRequest{Token: params.Token})
+	return err
+}
+
+func (e *Set) RevokeSession(ctx context.Context, params auth.RevokeSessionParams) error {
+	_, err := e.RevokeSessionEndpoint(ctx, &RevokeSessionRequest{
+		AccountId: params.AccountID,
+		SessionId: params.SessionID,
+	})
+	return err
+}
+
+func (e *Set) CreateAPIKey(ctx context.Context, params auth.CreateAPIKeyParams) (auth.CreateAPIKeyOutput, error) {
+	req := &CreateAPIKeyRequest{
+		AccountId: params.AccountID,
+		Name:      params.Name,
+		Scopes:    params.Scopes,
+	}
+	if params.ExpiresAt != nil {
+		req.ExpiresAt = timestamppb.New(*params.ExpiresAt)
+	}
+	resp, err := e.CreateAPIKeyEndpoint(ctx, req)
+	if err != nil {
+		return auth.CreateAPIKeyOutput{}, err
+	}
+	out := resp.(*CreateAPIKeyResponse)
+
+	return auth.CreateAPIKeyOutput{
+		APIKey: fromPBAPIKey(out.ApiKey),
+		Key:    out.Key,
+	}, nil
+}
+
+func (e *Set) ListAPIKeys(ctx context.Context, params auth.ListAPIKeysParams) ([]auth.APIKey, error) {
+	resp, err := e.ListAPIKeysEndpoint(ctx, &ListAPIKeysRequest{AccountId: params.AccountID})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*ListAPIKeysResponse)
+
+	keys := make([]auth.APIKey, 0, len(out.ApiKeys))
+	for _, key := range out.ApiKeys {
+		keys = append(keys, fromPBAPIKey(key))
+	}
+	return keys, nil
+}
+
+func (e *Set) RevokeAPIKey(ctx context.Context, params auth.RevokeAPIKeyParams) error {
+	_, err := e.RevokeAPIKeyEndpoint(ctx, &RevokeAPIKeyRequest{
+		AccountId: params.AccountID,
+		Id:        params.ID,
+	})
+	return err
+}
+
+func (e *Set) ListPublicKeys(ctx context.Context) ([]auth.TokenPublicKey, error) {
+	resp, err := e.ListPublicKeysEndpoint(ctx, &ListPublicKeysRequest{})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*ListPublicKeysResponse)
+
+	keys := make([]auth.TokenPublicKey, 0, len(out.Keys))
+	for _, key := range out.Keys {
+		k := auth.TokenPublicKey{
+			Id:        key.GetKid(),
+			PublicKey: key.GetPublicKey(),
+			State:     auth.SigningKeyState(key.GetState()),
+		}
+		if key.GetActivatedAt() != nil {
+			t := key.GetActivatedAt().AsTime()
+			k.ActivatedAt = &t
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (e *Set) CreateWorkspace(ctx context.Context, params auth.CreateWorkspaceParams) (auth.Workspace, error) {
+	resp, err := e.CreateWorkspaceEndpoint(ctx, &CreateWorkspaceRequest{
+		AccountId: params.AccountID,
+		Name:      params.Name,
+	})
+	if err != nil {
+		return auth.Workspace{}, err
+	}
+	return fromPBWorkspace(resp.(*CreateWorkspaceResponse).Workspace), nil
+}
+
+func (e *Set) ListWorkspaces(ctx context.Context, params auth.ListWorkspacesParams) ([]auth.WorkspaceMembership, error) {
+	resp, err := e.ListWorkspacesEndpoint(ctx, &ListWorkspacesRequest{AccountId: params.AccountID})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*ListWorkspacesResponse)
+
+	workspaces := make([]auth.WorkspaceMembership, 0, len(out.Workspaces))
+	for _, w := range out.Workspaces {
+		workspaces = append(workspaces, auth.WorkspaceMembership{
+			Workspace: fromPBWorkspace(w.GetWorkspace()),
+			Role:      auth.WorkspaceRole(w.GetRole()),
+		})
+	}
+	return workspaces, nil
+}
+
+func (e *Set) GetWorkspaceRole(ctx context.Context, params auth.GetWorkspaceRoleParams) (auth.WorkspaceRole, error) {
+	resp, err := e.GetWorkspaceRoleEndpoint(ctx, &GetWorkspaceRoleRequest{
+		AccountId:   params.AccountID,
+		WorkspaceId: params.WorkspaceID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return auth.WorkspaceRole(resp.(*GetWorkspaceRoleResponse).Role), nil
+}
+
+func (e *Set) ListWorkspaceMembers(ctx context.Context, params auth.ListWorkspaceMembersParams) ([]auth.WorkspaceMember, error) {
+	resp, err := e.ListWorkspaceMembersEndpoint(ctx, &ListWorkspaceMembersRequest{
+		AccountId:   params.AccountID,
+		WorkspaceId: params.WorkspaceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*ListWorkspaceMembersResponse)
+
+	members := make([]auth.WorkspaceMember, 0, len(out.Members))
+	for _, m := range out.Members {
+		members = append(members, fromPBWorkspaceMember(m))
+	}
+	return members, nil
+}
+
+func (e *Set) SetWorkspaceMember(ctx context.Context, params auth.SetWorkspaceMemberParams) (auth.WorkspaceMember, error) {
+	resp, err := e.SetWorkspaceMemberEndpoint(ctx, &SetWorkspaceMemberRequest{
+		AccountId:   params.AccountID,
+		WorkspaceId: params.WorkspaceID,
+		MemberName:  params.MemberName,
+		Role:        string(params.Role),
+	})
+	if err != nil {
+		return auth.WorkspaceMember{}, err
+	}
+	return fromPBWorkspaceMember(resp.(*SetWorkspaceMemberResponse).Member), nil
+}
+
+func (e *Set) RemoveWorkspaceMember(ctx context.Context, params auth.RemoveWorkspaceMemberParams) error {
+	_, err := e.RemoveWorkspaceMemberEndpoint(ctx, &RemoveWorkspaceMemberRequest{
+		AccountId:   params.AccountID,
+		WorkspaceId: params.WorkspaceID,
+		MemberId:    params.MemberID,
+	})
+	return err
+}
+
+func (e *Set) BeginOIDCLogin(ctx context.Context) (auth.BeginOIDCLoginOutput, error) {
+	resp, err := e.BeginOIDCLoginEndpoint(ctx, &BeginOIDCLoginRequest{})
+	if err != nil {
+		return auth.BeginOIDCLoginOutput{}, err
+	}
+	out := resp.(*BeginOIDCLoginResponse)
+	return auth.BeginOIDCLoginOutput{AuthURL: out.AuthUrl, State: out.State}, nil
+}
+
+func (e *Set) CompleteOIDCLogin(ctx context.Context, params auth.CompleteOIDCLoginParams) (auth.CreateSessionOutput, error) {
+	resp, err := e.CompleteOIDCLoginEndpoint(ctx, &CompleteOIDCLoginRequest{
+		State: params.State,
+		Code:  params.Code,
+	})
+	if err != nil {
+		return auth.CreateSessionOutput{}, err
+	}
+	out := resp.(*CompleteOIDCLoginResponse)
+
+	return auth.CreateSessionOutput{
+		Token:        out.Token,
+		ExpiresIn:    time.Duration(out.ExpiresIn) * time.Second,
+		RefreshToken: out.RefreshToken,
+		Account: &auth.Account{
+			Id:          out.Account.GetId(),
+			AccountName: out.Account.GetAccountName(),
+		},
 	}, nil
 }
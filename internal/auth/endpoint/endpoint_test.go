@@ -3,6 +3,7 @@ package authendpoint_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,9 +18,26 @@ import (
 // ---------------------------------------------------------------------------
 
 type mockAuthService struct {
-	createAccountFn func(ctx context.Context, params auth.CreateAccountParams) (auth.CreateAccountOutput, error)
-	createSessionFn func(ctx context.Context, params auth.CreateSessionParams) (auth.CreateSessionOutput, error)
-	verifySessionFn func(ctx context.Context, params auth.VerifySessionParams) (auth.VerifySessionOutput, error)
+	createAccountFn  func(ctx context.Context, params auth.CreateAccountParams) (auth.CreateAccountOutput, error)
+	createSessionFn  func(ctx context.Context, params auth.CreateSessionParams) (auth.CreateSessionOutput, error)
+	verifySessionFn  func(ctx context.Context, params auth.VerifySessionParams) (auth.VerifySessionOutput, error)
+	refreshSessionFn func(ctx context.Context, params auth.RefreshSessionParams) (auth.CreateSessionOutput, error)
+	logoutFn         func(ctx context.Context, params auth.LogoutParams) error
+	revokeSessionFn  func(ctx context.Context, params auth.RevokeSessionParams) error
+	createAPIKeyFn   func(ctx context.Context, params auth.CreateAPIKeyParams) (auth.CreateAPIKeyOutput, error)
+	listAPIKeysFn    func(ctx context.Context, params auth.ListAPIKeysParams) ([]auth.APIKey, error)
+	revokeAPIKeyFn   func(ctx context.Context, params auth.RevokeAPIKeyParams) error
+	listPublicKeysFn func(ctx context.Context) ([]auth.TokenPublicKey, error)
+
+	createWorkspaceFn       func(ctx context.Context, params auth.CreateWorkspaceParams) (auth.Workspace, error)
+	listWorkspacesFn        func(ctx context.Context, params auth.ListWorkspacesParams) ([]auth.WorkspaceMembership, error)
+	getWorkspaceRoleFn      func(ctx context.Context, params auth.GetWorkspaceRoleParams) (auth.WorkspaceRole, error)
+	listWorkspaceMembersFn  func(ctx context.Context, params auth.ListWorkspaceMembersParams) ([]auth.WorkspaceMember, error)
+	setWorkspaceMemberFn    func(ctx context.Context, params auth.SetWorkspaceMemberParams) (auth.WorkspaceMember, error)
+	removeWorkspaceMemberFn func(ctx context.Context, params auth.RemoveWorkspaceMemberParams) error
+
+	beginOIDCLoginFn    func(ctx context.Context) (auth.BeginOIDCLoginOutput, error)
+	completeOIDCLoginFn func(ctx context.Context, params auth.CompleteOIDCLoginParams) (auth.CreateSessionOutput, error)
 }
 
 func (m *mockAuthService) CreateAccount(
@@ -43,6 +61,87 @@ func (m *mockAuthService) VerifySession(
 	return m.verifySessionFn(ctx, params)
 }
 
+func (m *mockAuthService) RefreshSession(
+	ctx context.Context,
+	params auth.RefreshSessionParams,
+) (auth.CreateSessionOutput, error) {
+	return m.refreshSessionFn(ctx, params)
+}
+
+func (m *mockAuthService) Logout(ctx context.Context, params auth.LogoutParams) error {
+	return m.logoutFn(ctx, params)
+}
+
+func (m *mockAuthService) RevokeSession(ctx context.Context, params auth.RevokeSessionParams) error {
+	return m.revokeSessionFn(ctx, params)
+}
+
+func (m *mockAuthService) CreateAPIKey(
+	ctx context.Context,
+	params auth.CreateAPIKeyParams,
+) (auth.CreateAPIKeyOutput, error) {
+	return m.createAPIKeyFn(ctx, params)
+}
+
+func (m *mockAuthService) ListAPIKeys(ctx context.Context, params auth.ListAPIKeysParams) ([]auth.APIKey, error) {
+	return m.listAPIKeysFn(ctx, params)
+}
+
+func (m *mockAuthService) RevokeAPIKey(ctx context.Context, params auth.RevokeAPIKeyParams) error {
+	return m.revokeAPIKeyFn(ctx, params)
+}
+
+func (m *mockAuthService) ListPublicKeys(ctx context.Context) ([]auth.TokenPublicKey, error) {
+	return m.listPublicKeysFn(ctx)
+}
+
+func (m *mockAuthService) CreateWorkspace(ctx context.Context, params auth.CreateWorkspaceParams) (auth.Workspace, error) {
+	return m.createWorkspaceFn(ctx, params)
+}
+
+func (m *mockAuthService) ListWorkspaces(
+	ctx context.Context,
+	params auth.ListWorkspacesParams,
+) ([]auth.WorkspaceMembership, error) {
+	return m.listWorkspacesFn(ctx, params)
+}
+
+func (m *mockAuthService) GetWorkspaceRole(
+	ctx context.Context,
+	params auth.GetWorkspaceRoleParams,
+) (auth.WorkspaceRole, error) {
+	return m.getWorkspaceRoleFn(ctx, params)
+}
+
+func (m *mockAuthService) ListWorkspaceMembers(
+	ctx context.Context,
+	params auth.ListWorkspaceMembersParams,
+) ([]auth.WorkspaceMember, error) {
+	return m.listWorkspaceMembersFn(ctx, params)
+}
+
+func (m *mockAuthService) SetWorkspaceMember(
+	ctx context.Context,
+	params auth.SetWorkspaceMemberParams,
+) (auth.WorkspaceMember, error) {
+	return m.setWorkspaceMemberFn(ctx, params)
+}
+
+func (m *mockAuthService) RemoveWorkspaceMember(ctx context.Context, params auth.RemoveWorkspaceMemberParams) error {
+	return m.removeWorkspaceMemberFn(ctx, params)
+}
+
+func (m *mockAuthService) BeginOIDCLogin(ctx context.Context) (auth.BeginOIDCLoginOutput, error) {
+	return m.beginOIDCLoginFn(ctx)
+}
+
+func (m *mockAuthService) CompleteOIDCLogin(
+	ctx context.Context,
+	params auth.CompleteOIDCLoginParams,
+) (auth.CreateSessionOutput, error) {
+	return m.completeOIDCLoginFn(ctx, params)
+}
+
 // ---------------------------------------------------------------------------
 // CreateAccount endpoint
 // ---------------------------------------------------------------------------
@@ -216,6 +315,227 @@ func TestMakeVerifyTokenEndpoint_InvalidToken(t *testing.T) {
 	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
 }
 
+// ---------------------------------------------------------------------------
+// RefreshSession, Logout and RevokeSession endpoints
+// ---------------------------------------------------------------------------
+
+func TestMakeRefreshSessionEndpoint_Success(t *testing.T) {
+	svc := &mockAuthService{
+		refreshSessionFn: func(_ context.Context, params auth.RefreshSessionParams) (auth.CreateSessionOutput, error) {
+			assert.Equal(t, "refresh-1", params.RefreshToken)
+			return auth.CreateSessionOutput{
+				Token:        "tok-2",
+				ExpiresIn:    time.Hour,
+				RefreshToken: "refresh-2",
+				Account:      &auth.Account{Id: 5, AccountName: "bob"},
+			}, nil
+		},
+	}
+
+	ep := authendpoint.MakeRefreshSessionEndpoint(svc)
+	resp, err := ep(context.Background(), &authendpoint.RefreshSessionRequest{RefreshToken: "refresh-1"})
+
+	require.NoError(t, err)
+	out := resp.(*authendpoint.RefreshSessionResponse)
+	assert.Equal(t, "tok-2", out.Token)
+	assert.Equal(t, "refresh-2", out.RefreshToken)
+	assert.Equal(t, int64(3600), out.ExpiresIn)
+	assert.Equal(t, uint64(5), out.Account.GetId())
+}
+
+func TestMakeRefreshSessionEndpoint_ReusedToken(t *testing.T) {
+	svc := &mockAuthService{
+		refreshSessionFn: func(_ context.Context, _ auth.RefreshSessionParams) (auth.CreateSessionOutput, error) {
+			return auth.CreateSessionOutput{}, &apperrors.Error{
+				Code:    auth.ErrCodeInvalidToken,
+				Message: "refresh token reused, session revoked",
+			}
+		},
+	}
+
+	ep := authendpoint.MakeRefreshSessionEndpoint(svc)
+	_, err := ep(context.Background(), &authendpoint.RefreshSessionRequest{RefreshToken: "old"})
+
+	require.Error(t, err)
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+}
+
+func TestSet_LogoutAndRevokeSession_RoundTrip(t *testing.T) {
+	var revoked auth.RevokeSessionParams
+	svc := &mockAuthService{
+		logoutFn: func(_ context.Context, params auth.LogoutParams) error {
+			assert.Equal(t, "tok", params.Token)
+			return nil
+		},
+		revokeSessionFn: func(_ context.Context, params auth.RevokeSessionParams) error {
+			revoked = params
+			return nil
+		},
+	}
+
+	set := authendpoint.New(svc)
+	require.NoError(t, set.Logout(context.Background(), auth.LogoutParams{Token: "tok"}))
+	require.NoError(t, set.RevokeSession(context.Background(), auth.RevokeSessionParams{AccountID: 7, SessionID: "s1"}))
+	assert.Equal(t, auth.RevokeSessionParams{AccountID: 7, SessionID: "s1"}, revoked)
+}
+
+func TestSet_APIKeys_RoundTrip(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	svc := &mockAuthService{
+		createAPIKeyFn: func(_ context.Context, params auth.CreateAPIKeyParams) (auth.CreateAPIKeyOutput, error) {
+			require.NotNil(t, params.ExpiresAt)
+			assert.True(t, expiresAt.Equal(*params.ExpiresAt))
+			return auth.CreateAPIKeyOutput{
+				APIKey: auth.APIKey{
+					Id:          1,
+					OfAccountId: params.AccountID,
+					Name:        params.Name,
+					Scopes:      params.Scopes,
+					ExpiresAt:   params.ExpiresAt,
+				},
+				Key: "gl_abc_secret",
+			}, nil
+		},
+		listAPIKeysFn: func(_ context.Context, params auth.ListAPIKeysParams) ([]auth.APIKey, error) {
+			return []auth.APIKey{{Id: 1, OfAccountId: params.AccountID, Scopes: []string{auth.ScopeTasksRead}}}, nil
+		},
+		revokeAPIKeyFn: func(_ context.Context, params auth.RevokeAPIKeyParams) error {
+			assert.Equal(t, auth.RevokeAPIKeyParams{AccountID: 7, ID: 1}, params)
+			return nil
+		},
+	}
+
+	set := authendpoint.New(svc)
+	created, err := set.CreateAPIKey(context.Background(), auth.CreateAPIKeyParams{
+		AccountID: 7,
+		Name:      "ci",
+		Scopes:    []string{auth.ScopeTasksRead},
+		ExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "gl_abc_secret", created.Key)
+	assert.Equal(t, uint64(7), created.APIKey.OfAccountId)
+	require.NotNil(t, created.APIKey.ExpiresAt)
+	assert.Nil(t, created.APIKey.RevokedAt)
+
+	keys, err := set.ListAPIKeys(context.Background(), auth.ListAPIKeysParams{AccountID: 7})
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, []string{auth.ScopeTasksRead}, keys[0].Scopes)
+
+	require.NoError(t, set.RevokeAPIKey(context.Background(), auth.RevokeAPIKeyParams{AccountID: 7, ID: 1}))
+}
+
+func TestSet_ListPublicKeys_RoundTrip(t *testing.T) {
+	activatedAt := time.Now().UTC().Truncate(time.Second)
+	svc := &mockAuthService{
+		listPublicKeysFn: func(context.Context) ([]auth.TokenPublicKey, error) {
+			return []auth.TokenPublicKey{
+				{Id: 1, PublicKey: []byte("pem-1"), State: auth.SigningKeyStateActive, ActivatedAt: &activatedAt},
+				{Id: 2, PublicKey: []byte("pem-2"), State: auth.SigningKeyStateNext},
+			}, nil
+		},
+	}
+
+	set := authendpoint.New(svc)
+	keys, err := set.ListPublicKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, auth.SigningKeyStateActive, keys[0].State)
+	require.NotNil(t, keys[0].ActivatedAt)
+	assert.True(t, activatedAt.Equal(*keys[0].ActivatedAt))
+	assert.Equal(t, []byte("pem-2"), keys[1].PublicKey)
+	assert.Nil(t, keys[1].ActivatedAt)
+}
+
+func TestSet_Workspaces_RoundTrip(t *testing.T) {
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	svc := &mockAuthService{
+		listWorkspacesFn: func(_ context.Context, params auth.ListWorkspacesParams) ([]auth.WorkspaceMembership, error) {
+			return []auth.WorkspaceMembership{{
+				Workspace: auth.Workspace{Id: 3, Name: "team", CreatedAt: createdAt},
+				Role:      auth.WorkspaceRoleOperator,
+			}}, nil
+		},
+		getWorkspaceRoleFn: func(_ context.Context, params auth.GetWorkspaceRoleParams) (auth.WorkspaceRole, error) {
+			if params.WorkspaceID != 3 {
+				return "", &apperrors.Error{Code: apperrors.ErrCodeNotFound, Message: "not a member of the workspace"}
+			}
+			return auth.WorkspaceRoleOperator, nil
+		},
+		setWorkspaceMemberFn: func(_ context.Context, params auth.SetWorkspaceMemberParams) (auth.WorkspaceMember, error) {
+			return auth.WorkspaceMember{
+				WorkspaceId: params.WorkspaceID,
+				AccountId:   8,
+				AccountName: params.MemberName,
+				Role:        params.Role,
+				CreatedAt:   createdAt,
+			}, nil
+		},
+	}
+
+	set := authendpoint.New(svc)
+	workspaces, err := set.ListWorkspaces(context.Background(), auth.ListWorkspacesParams{AccountID: 7})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	assert.Equal(t, "team", workspaces[0].Workspace.Name)
+	assert.True(t, createdAt.Equal(workspaces[0].Workspace.CreatedAt))
+	assert.Equal(t, auth.WorkspaceRoleOperator, workspaces[0].Role)
+
+	role, err := set.GetWorkspaceRole(context.Background(), auth.GetWorkspaceRoleParams{AccountID: 7, WorkspaceID: 3})
+	require.NoError(t, err)
+	assert.Equal(t, auth.WorkspaceRoleOperator, role)
+
+	_, err = set.GetWorkspaceRole(context.Background(), auth.GetWorkspaceRoleParams{AccountID: 7, WorkspaceID: 4})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeNotFound))
+
+	member, err := set.SetWorkspaceMember(context.Background(), auth.SetWorkspaceMemberParams{
+		AccountID:   7,
+		WorkspaceID: 3,
+		MemberName:  "bob",
+		Role:        auth.WorkspaceRoleViewer,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), member.AccountId)
+	assert.Equal(t, "bob", member.AccountName)
+	assert.Equal(t, auth.WorkspaceRoleViewer, member.Role)
+}
+
+func TestSet_OIDCLogin_RoundTrip(t *testing.T) {
+	svc := &mockAuthService{
+		beginOIDCLoginFn: func(context.Context) (auth.BeginOIDCLoginOutput, error) {
+			return auth.BeginOIDCLoginOutput{AuthURL: "https://idp.example.com/authorize?state=s1", State: "s1"}, nil
+		},
+		completeOIDCLoginFn: func(_ context.Context, params auth.CompleteOIDCLoginParams) (auth.CreateSessionOutput, error) {
+			if params.State != "s1" {
+				return auth.CreateSessionOutput{}, &apperrors.Error{Code: auth.ErrCodeInvalidToken, Message: "unknown or expired login state"}
+			}
+			assert.Equal(t, "code-1", params.Code)
+			return auth.CreateSessionOutput{
+				Token:     "tok",
+				ExpiresIn: time.Hour,
+				Account:   &auth.Account{Id: 9, AccountName: "carol"},
+			}, nil
+		},
+	}
+
+	set := authendpoint.New(svc)
+	begin, err := set.BeginOIDCLogin(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "s1", begin.State)
+	assert.Equal(t, "https://idp.example.com/authorize?state=s1", begin.AuthURL)
+
+	out, err := set.CompleteOIDCLogin(context.Background(), auth.CompleteOIDCLoginParams{State: "s1", Code: "code-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "tok", out.Token)
+	assert.Equal(t, time.Hour, out.ExpiresIn)
+	assert.Equal(t, uint64(9), out.Account.Id)
+	assert.Equal(t, "carol", out.Account.AccountName)
+
+	_, err = set.CompleteOIDCLogin(context.Background(), auth.CompleteOIDCLoginParams{State: "s2", Code: "code-1"})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+}
+
 // ---------------------------------------------------------------------------
 // Set — full endpoint set with rate limiter
 // ---------------------------------------------------------------------------
@@ -262,7 +582,7 @@ func TestSet_CreateSession_RoundTrip(t *testing.T) {
 func TestSet_VerifySession_RoundTrip(t *testing.T) {
 	svc := &mockAuthService{
 		verifySessionFn: func(_ context.Context, params auth.VerifySessionParams) (auth.VerifySessionOutput, error) {
-			return auth.VerifySessionOutput{AccountID: 3}, nil
+			return auth.VerifySessionOutput{AccountID: 3, APIKeyID: 4, Scopes: []string{auth.ScopeDownload}}, nil
 		},
 	}
 
@@ -271,4 +591,6 @@ func TestSet_VerifySession_RoundTrip(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, uint64(3), out.AccountID)
+	assert.Equal(t, uint64(4), out.APIKeyID)
+	assert.Equal(t, []string{auth.ScopeDownload}, out.Scopes)
 }
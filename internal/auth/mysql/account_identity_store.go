@@ -0,0 +1,57 @@
+package authmysql
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/auth/mysql/sqlc"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+type accountIdentityStore struct {
+	queries *sqlc.Queries
+}
+
+func NewAccountIdentityStore(db *sql.DB) auth.AccountIdentityStore {
+	return &accountIdentityStore{
+		queries: sqlc.New(db),
+	}
+}
+
+func (a *accountIdentityStore) CreateAccountIdentity(ctx context.Context, identity *auth.AccountIdentity) error {
+	q := a.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	return q.CreateAccountIdentity(ctx, sqlc.CreateAccountIdentityParams{
+		OfAccountID: identity.OfAccountId,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		CreatedAt:   identity.CreatedAt.UTC(),
+	})
+}
+
+func (a *accountIdentityStore) GetAccountIdentity(ctx context.Context, issuer, subject string) (auth.AccountIdentity, error) {
+	q := a.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	identity, err := q.GetAccountIdentity(ctx, sqlc.GetAccountIdentityParams{
+		Issuer:  issuer,
+		Subject: subject,
+	})
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return auth.AccountIdentity{}, errors.ErrNotFound
+		}
+		return auth.AccountIdentity{}, err
+	}
+	return auth.AccountIdentity{
+		OfAccountId: identity.OfAccountID,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		CreatedAt:   identity.CreatedAt,
+	}, nil
+}
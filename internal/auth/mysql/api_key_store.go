@@ -0,0 +1,111 @@
+package authmysql
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/auth/mysql/sqlc"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+type apiKeyStore struct {
+	queries *sqlc.Queries
+}
+
+func NewAPIKeyStore(db *sql.DB) auth.APIKeyStore {
+	return &apiKeyStore{
+		queries: sqlc.New(db),
+	}
+}
+
+func (a *apiKeyStore) CreateAPIKey(ctx context.Context, key *auth.APIKey) (uint64, error) {
+	q := a.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	var expiresAt sql.NullTime
+	if key.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: key.ExpiresAt.UTC(), Valid: true}
+	}
+	result, err := q.CreateAPIKey(ctx, sqlc.CreateAPIKeyParams{
+		OfAccountID:  key.OfAccountId,
+		Name:         key.Name,
+		Prefix:       key.Prefix,
+		HashedSecret: key.HashedSecret,
+		Scopes:       strings.Join(key.Scopes, " "),
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+func (a *apiKeyStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (auth.APIKey, error) {
+	key, err := a.queries.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return auth.APIKey{}, errors.ErrNotFound
+		}
+		return auth.APIKey{}, err
+	}
+	return toAPIKey(key), nil
+}
+
+func (a *apiKeyStore) ListAPIKeys(ctx context.Context, ofAccountID uint64) ([]auth.APIKey, error) {
+	rows, err := a.queries.ListAPIKeysByAccountID(ctx, ofAccountID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]auth.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toAPIKey(row))
+	}
+	return keys, nil
+}
+
+func (a *apiKeyStore) RevokeAPIKey(ctx context.Context, ofAccountID, id uint64, at time.Time) error {
+	result, err := a.queries.RevokeAPIKey(ctx, sqlc.RevokeAPIKeyParams{
+		RevokedAt:   sql.NullTime{Time: at.UTC(), Valid: true},
+		ID:          id,
+		OfAccountID: ofAccountID,
+	})
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func toAPIKey(k sqlc.ApiKey) auth.APIKey {
+	key := auth.APIKey{
+		Id:           k.ID,
+		OfAccountId:  k.OfAccountID,
+		Name:         k.Name,
+		Prefix:       k.Prefix,
+		HashedSecret: k.HashedSecret,
+		Scopes:       strings.Fields(k.Scopes),
+		CreatedAt:    k.CreatedAt,
+	}
+	if k.ExpiresAt.Valid {
+		key.ExpiresAt = &k.ExpiresAt.Time
+	}
+	if k.RevokedAt.Valid {
+		key.RevokedAt = &k.RevokedAt.Time
+	}
+	return key
+}
@@ -10,7 +10,10 @@ type Store struct {
 	auth.AccountStore
 	auth.AccountPasswordStore
 	auth.TxManager
-	auth.TokenPublicKeyStore
+	auth.SigningKeyStore
+	auth.APIKeyStore
+	auth.WorkspaceStore
+	auth.AccountIdentityStore
 	*sql.DB
 }
 
@@ -19,7 +22,10 @@ func New(db *sql.DB) *Store {
 		AccountStore:         NewAccountStore(db),
 		AccountPasswordStore: NewAccountPasswordStore(db),
 		TxManager:            NewTxManager(db),
-		TokenPublicKeyStore:  NewTokenPublicKeyStore(db),
+		SigningKeyStore:      NewSigningKeyStore(db),
+		APIKeyStore:          NewAPIKeyStore(db),
+		WorkspaceStore:       NewWorkspaceStore(db),
+		AccountIdentityStore: NewAccountIdentityStore(db),
 		DB:                   db,
 	}
 }
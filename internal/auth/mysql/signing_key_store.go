@@ -0,0 +1,113 @@
+package authmysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/auth/mysql/sqlc"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+type signingKeyStore struct {
+	queries *sqlc.Queries
+}
+
+func NewSigningKeyStore(db *sql.DB) auth.SigningKeyStore {
+	return &signingKeyStore{
+		queries: sqlc.New(db),
+	}
+}
+
+func (s *signingKeyStore) CreateSigningKey(ctx context.Context, key *auth.SigningKey) (kid uint64, err error) {
+	q := s.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	result, err := q.CreateTokenPublicKey(ctx, sqlc.CreateTokenPublicKeyParams{
+		PublicKey:  string(key.PublicKey),
+		PrivateKey: sql.NullString{String: string(key.PrivateKey), Valid: key.PrivateKey != nil},
+		State:      string(key.State),
+		CreatedAt:  key.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+func (s *signingKeyStore) ListSigningKeys(ctx context.Context) ([]auth.SigningKey, error) {
+	rows, err := s.queries.ListTokenPublicKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]auth.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toSigningKey(row))
+	}
+	return keys, nil
+}
+
+func (s *signingKeyStore) UpdateSigningKeyState(
+	ctx context.Context,
+	kid uint64,
+	from, to auth.SigningKeyState,
+	at time.Time,
+) error {
+	var (
+		result sql.Result
+		err    error
+	)
+	switch to {
+	case auth.SigningKeyStateActive:
+		result, err = s.queries.ActivateTokenPublicKey(ctx, sqlc.ActivateTokenPublicKeyParams{
+			ActivatedAt: sql.NullTime{Time: at.UTC(), Valid: true},
+			ID:          kid,
+			State:       string(from),
+		})
+	case auth.SigningKeyStateRetired:
+		result, err = s.queries.RetireTokenPublicKey(ctx, sqlc.RetireTokenPublicKeyParams{
+			RetiredAt: sql.NullTime{Time: at.UTC(), Valid: true},
+			ID:        kid,
+			State:     string(from),
+		})
+	default:
+		return fmt.Errorf("cannot move signing key to state %q", to)
+	}
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func toSigningKey(k sqlc.TokenPublicKey) auth.SigningKey {
+	key := auth.SigningKey{
+		Id:        k.ID,
+		PublicKey: []byte(k.PublicKey),
+		State:     auth.SigningKeyState(k.State),
+		CreatedAt: k.CreatedAt,
+	}
+	if k.PrivateKey.Valid {
+		key.PrivateKey = []byte(k.PrivateKey.String)
+	}
+	if k.ActivatedAt.Valid {
+		key.ActivatedAt = &k.ActivatedAt.Time
+	}
+	if k.RetiredAt.Valid {
+		key.RetiredAt = &k.RetiredAt.Time
+	}
+	return key
+}
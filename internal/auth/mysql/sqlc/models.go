@@ -4,17 +4,59 @@
 
 package sqlc
 
+import (
+	"database/sql"
+	"time"
+)
+
 type Account struct {
 	ID          uint64 `json:"id"`
 	AccountName string `json:"account_name"`
 }
 
+type AccountIdentity struct {
+	OfAccountID uint64    `json:"of_account_id"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 type AccountPassword struct {
 	OfAccountID    uint64 `json:"of_account_id"`
 	HashedPassword string `json:"hashed_password"`
 }
 
+type ApiKey struct {
+	ID           uint64       `json:"id"`
+	OfAccountID  uint64       `json:"of_account_id"`
+	Name         string       `json:"name"`
+	Prefix       string       `json:"prefix"`
+	HashedSecret string       `json:"hashed_secret"`
+	Scopes       string       `json:"scopes"`
+	ExpiresAt    sql.NullTime `json:"expires_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	RevokedAt    sql.NullTime `json:"revoked_at"`
+}
+
 type TokenPublicKey struct {
-	ID        uint64 `json:"id"`
-	PublicKey string `json:"public_key"`
+	ID          uint64         `json:"id"`
+	PublicKey   string         `json:"public_key"`
+	PrivateKey  sql.NullString `json:"private_key"`
+	State       string         `json:"state"`
+	CreatedAt   time.Time      `json:"created_at"`
+	ActivatedAt sql.NullTime   `json:"activated_at"`
+	RetiredAt   sql.NullTime   `json:"retired_at"`
+}
+
+type Workspace struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID uint64    `json:"workspace_id"`
+	AccountID   uint64    `json:"account_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
 }
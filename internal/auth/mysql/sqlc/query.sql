@@ -27,10 +27,83 @@ FROM account_passwords
 WHERE of_account_id = ?;
 
 -- name: CreateTokenPublicKey :execresult
-INSERT INTO token_public_keys (public_key)
-VALUES (?);
+INSERT INTO token_public_keys (public_key, private_key, state, created_at)
+VALUES (?, ?, ?, ?);
 
--- name: GetTokenPublicKey :one
-SELECT id, public_key
+-- name: ListTokenPublicKeys :many
+SELECT id, public_key, private_key, state, created_at, activated_at, retired_at
 FROM token_public_keys
-WHERE id = ?;
+WHERE state <> 'retired'
+ORDER BY id;
+
+-- name: ActivateTokenPublicKey :execresult
+UPDATE token_public_keys
+SET state = 'active', activated_at = ?
+WHERE id = ? AND state = ?;
+
+-- name: RetireTokenPublicKey :execresult
+UPDATE token_public_keys
+SET state = 'retired', retired_at = ?
+WHERE id = ? AND state = ?;
+
+-- name: CreateAPIKey :execresult
+INSERT INTO api_keys (of_account_id, name, prefix, hashed_secret, scopes, expires_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetAPIKeyByPrefix :one
+SELECT id, of_account_id, name, prefix, hashed_secret, scopes, expires_at, created_at, revoked_at
+FROM api_keys
+WHERE prefix = ?;
+
+-- name: ListAPIKeysByAccountID :many
+SELECT id, of_account_id, name, prefix, hashed_secret, scopes, expires_at, created_at, revoked_at
+FROM api_keys
+WHERE of_account_id = ?
+ORDER BY id DESC;
+
+-- name: RevokeAPIKey :execresult
+UPDATE api_keys
+SET revoked_at = ?
+WHERE id = ? AND of_account_id = ? AND revoked_at IS NULL;
+
+-- name: CreateWorkspace :execresult
+INSERT INTO workspaces (name, created_at)
+VALUES (?, ?);
+
+-- name: ListWorkspacesByAccountID :many
+SELECT w.id, w.name, w.created_at, m.role
+FROM workspaces w
+         JOIN workspace_members m ON m.workspace_id = w.id
+WHERE m.account_id = ?
+ORDER BY w.id;
+
+-- name: GetWorkspaceMember :one
+SELECT m.workspace_id, m.account_id, a.account_name, m.role, m.created_at
+FROM workspace_members m
+         JOIN accounts a ON a.id = m.account_id
+WHERE m.workspace_id = ? AND m.account_id = ?;
+
+-- name: ListWorkspaceMembers :many
+SELECT m.workspace_id, m.account_id, a.account_name, m.role, m.created_at
+FROM workspace_members m
+         JOIN accounts a ON a.id = m.account_id
+WHERE m.workspace_id = ?
+ORDER BY m.created_at, m.account_id;
+
+-- name: UpsertWorkspaceMember :exec
+INSERT INTO workspace_members (workspace_id, account_id, role, created_at)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE role = VALUES(role);
+
+-- name: DeleteWorkspaceMember :execresult
+DELETE FROM workspace_members
+WHERE workspace_id = ? AND account_id = ?;
+
+-- name: CreateAccountIdentity :exec
+INSERT INTO account_identities (of_account_id, issuer, subject, created_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetAccountIdentity :one
+SELECT of_account_id, issuer, subject, created_at
+FROM account_identities
+WHERE issuer = ? AND subject = ?;
@@ -8,8 +8,50 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const activateTokenPublicKey = `-- name: ActivateTokenPublicKey :execresult
+UPDATE token_public_keys
+SET state = 'active', activated_at = ?
+WHERE id = ? AND state = ?
+`
+
+type ActivateTokenPublicKeyParams struct {
+	ActivatedAt sql.NullTime `json:"activated_at"`
+	ID          uint64       `json:"id"`
+	State       string       `json:"state"`
+}
+
+func (q *Queries) ActivateTokenPublicKey(ctx context.Context, arg ActivateTokenPublicKeyParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, activateTokenPublicKey, arg.ActivatedAt, arg.ID, arg.State)
+}
+
+const createAPIKey = `-- name: CreateAPIKey :execresult
+INSERT INTO api_keys (of_account_id, name, prefix, hashed_secret, scopes, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateAPIKeyParams struct {
+	OfAccountID  uint64       `json:"of_account_id"`
+	Name         string       `json:"name"`
+	Prefix       string       `json:"prefix"`
+	HashedSecret string       `json:"hashed_secret"`
+	Scopes       string       `json:"scopes"`
+	ExpiresAt    sql.NullTime `json:"expires_at"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAPIKey,
+		arg.OfAccountID,
+		arg.Name,
+		arg.Prefix,
+		arg.HashedSecret,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+}
+
 const createAccount = `-- name: CreateAccount :execresult
 INSERT INTO accounts (account_name)
 VALUES (?)
@@ -19,6 +61,28 @@ func (q *Queries) CreateAccount(ctx context.Context, accountName string) (sql.Re
 	return q.db.ExecContext(ctx, createAccount, accountName)
 }
 
+const createAccountIdentity = `-- name: CreateAccountIdentity :exec
+INSERT INTO account_identities (of_account_id, issuer, subject, created_at)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateAccountIdentityParams struct {
+	OfAccountID uint64    `json:"of_account_id"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateAccountIdentity(ctx context.Context, arg CreateAccountIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, createAccountIdentity,
+		arg.OfAccountID,
+		arg.Issuer,
+		arg.Subject,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const createAccountPassword = `-- name: CreateAccountPassword :exec
 INSERT INTO account_passwords (of_account_id, hashed_password)
 VALUES (?, ?)
@@ -35,12 +99,75 @@ func (q *Queries) CreateAccountPassword(ctx context.Context, arg CreateAccountPa
 }
 
 const createTokenPublicKey = `-- name: CreateTokenPublicKey :execresult
-INSERT INTO token_public_keys (public_key)
-VALUES (?)
+INSERT INTO token_public_keys (public_key, private_key, state, created_at)
+VALUES (?, ?, ?, ?)
 `
 
-func (q *Queries) CreateTokenPublicKey(ctx context.Context, publicKey string) (sql.Result, error) {
-	return q.db.ExecContext(ctx, createTokenPublicKey, publicKey)
+type CreateTokenPublicKeyParams struct {
+	PublicKey  string         `json:"public_key"`
+	PrivateKey sql.NullString `json:"private_key"`
+	State      string         `json:"state"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func (q *Queries) CreateTokenPublicKey(ctx context.Context, arg CreateTokenPublicKeyParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createTokenPublicKey,
+		arg.PublicKey,
+		arg.PrivateKey,
+		arg.State,
+		arg.CreatedAt,
+	)
+}
+
+const createWorkspace = `-- name: CreateWorkspace :execresult
+INSERT INTO workspaces (name, created_at)
+VALUES (?, ?)
+`
+
+type CreateWorkspaceParams struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CreateWorkspace(ctx context.Context, arg CreateWorkspaceParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createWorkspace, arg.Name, arg.CreatedAt)
+}
+
+const deleteWorkspaceMember = `-- name: DeleteWorkspaceMember :execresult
+DELETE FROM workspace_members
+WHERE workspace_id = ? AND account_id = ?
+`
+
+type DeleteWorkspaceMemberParams struct {
+	WorkspaceID uint64 `json:"workspace_id"`
+	AccountID   uint64 `json:"account_id"`
+}
+
+func (q *Queries) DeleteWorkspaceMember(ctx context.Context, arg DeleteWorkspaceMemberParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteWorkspaceMember, arg.WorkspaceID, arg.AccountID)
+}
+
+const getAPIKeyByPrefix = `-- name: GetAPIKeyByPrefix :one
+SELECT id, of_account_id, name, prefix, hashed_secret, scopes, expires_at, created_at, revoked_at
+FROM api_keys
+WHERE prefix = ?
+`
+
+func (q *Queries) GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyByPrefix, prefix)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OfAccountID,
+		&i.Name,
+		&i.Prefix,
+		&i.HashedSecret,
+		&i.Scopes,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
 }
 
 const getAccountByAccountName = `-- name: GetAccountByAccountName :one
@@ -69,6 +196,29 @@ func (q *Queries) GetAccountByID(ctx context.Context, id uint64) (Account, error
 	return i, err
 }
 
+const getAccountIdentity = `-- name: GetAccountIdentity :one
+SELECT of_account_id, issuer, subject, created_at
+FROM account_identities
+WHERE issuer = ? AND subject = ?
+`
+
+type GetAccountIdentityParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetAccountIdentity(ctx context.Context, arg GetAccountIdentityParams) (AccountIdentity, error) {
+	row := q.db.QueryRowContext(ctx, getAccountIdentity, arg.Issuer, arg.Subject)
+	var i AccountIdentity
+	err := row.Scan(
+		&i.OfAccountID,
+		&i.Issuer,
+		&i.Subject,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getAccountPassword = `-- name: GetAccountPassword :one
 SELECT of_account_id, hashed_password
 FROM account_passwords
@@ -82,19 +232,237 @@ func (q *Queries) GetAccountPassword(ctx context.Context, ofAccountID uint64) (A
 	return i, err
 }
 
-const getTokenPublicKey = `-- name: GetTokenPublicKey :one
-SELECT id, public_key
-FROM token_public_keys
-WHERE id = ?
+const getWorkspaceMember = `-- name: GetWorkspaceMember :one
+SELECT m.workspace_id, m.account_id, a.account_name, m.role, m.created_at
+FROM workspace_members m
+         JOIN accounts a ON a.id = m.account_id
+WHERE m.workspace_id = ? AND m.account_id = ?
 `
 
-func (q *Queries) GetTokenPublicKey(ctx context.Context, id uint64) (TokenPublicKey, error) {
-	row := q.db.QueryRowContext(ctx, getTokenPublicKey, id)
-	var i TokenPublicKey
-	err := row.Scan(&i.ID, &i.PublicKey)
+type GetWorkspaceMemberParams struct {
+	WorkspaceID uint64 `json:"workspace_id"`
+	AccountID   uint64 `json:"account_id"`
+}
+
+type GetWorkspaceMemberRow struct {
+	WorkspaceID uint64    `json:"workspace_id"`
+	AccountID   uint64    `json:"account_id"`
+	AccountName string    `json:"account_name"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (q *Queries) GetWorkspaceMember(ctx context.Context, arg GetWorkspaceMemberParams) (GetWorkspaceMemberRow, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceMember, arg.WorkspaceID, arg.AccountID)
+	var i GetWorkspaceMemberRow
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.AccountID,
+		&i.AccountName,
+		&i.Role,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
+const listAPIKeysByAccountID = `-- name: ListAPIKeysByAccountID :many
+SELECT id, of_account_id, name, prefix, hashed_secret, scopes, expires_at, created_at, revoked_at
+FROM api_keys
+WHERE of_account_id = ?
+ORDER BY id DESC
+`
+
+func (q *Queries) ListAPIKeysByAccountID(ctx context.Context, ofAccountID uint64) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeysByAccountID, ofAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.OfAccountID,
+			&i.Name,
+			&i.Prefix,
+			&i.HashedSecret,
+			&i.Scopes,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTokenPublicKeys = `-- name: ListTokenPublicKeys :many
+SELECT id, public_key, private_key, state, created_at, activated_at, retired_at
+FROM token_public_keys
+WHERE state <> 'retired'
+ORDER BY id
+`
+
+func (q *Queries) ListTokenPublicKeys(ctx context.Context) ([]TokenPublicKey, error) {
+	rows, err := q.db.QueryContext(ctx, listTokenPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TokenPublicKey
+	for rows.Next() {
+		var i TokenPublicKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.PublicKey,
+			&i.PrivateKey,
+			&i.State,
+			&i.CreatedAt,
+			&i.ActivatedAt,
+			&i.RetiredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkspaceMembers = `-- name: ListWorkspaceMembers :many
+SELECT m.workspace_id, m.account_id, a.account_name, m.role, m.created_at
+FROM workspace_members m
+         JOIN accounts a ON a.id = m.account_id
+WHERE m.workspace_id = ?
+ORDER BY m.created_at, m.account_id
+`
+
+type ListWorkspaceMembersRow struct {
+	WorkspaceID uint64    `json:"workspace_id"`
+	AccountID   uint64    `json:"account_id"`
+	AccountName string    `json:"account_name"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListWorkspaceMembers(ctx context.Context, workspaceID uint64) ([]ListWorkspaceMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkspaceMembers, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWorkspaceMembersRow
+	for rows.Next() {
+		var i ListWorkspaceMembersRow
+		if err := rows.Scan(
+			&i.WorkspaceID,
+			&i.AccountID,
+			&i.AccountName,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkspacesByAccountID = `-- name: ListWorkspacesByAccountID :many
+SELECT w.id, w.name, w.created_at, m.role
+FROM workspaces w
+         JOIN workspace_members m ON m.workspace_id = w.id
+WHERE m.account_id = ?
+ORDER BY w.id
+`
+
+type ListWorkspacesByAccountIDRow struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role"`
+}
+
+func (q *Queries) ListWorkspacesByAccountID(ctx context.Context, accountID uint64) ([]ListWorkspacesByAccountIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkspacesByAccountID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWorkspacesByAccountIDRow
+	for rows.Next() {
+		var i ListWorkspacesByAccountIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execresult
+UPDATE api_keys
+SET revoked_at = ?
+WHERE id = ? AND of_account_id = ? AND revoked_at IS NULL
+`
+
+type RevokeAPIKeyParams struct {
+	RevokedAt   sql.NullTime `json:"revoked_at"`
+	ID          uint64       `json:"id"`
+	OfAccountID uint64       `json:"of_account_id"`
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, revokeAPIKey, arg.RevokedAt, arg.ID, arg.OfAccountID)
+}
+
+const retireTokenPublicKey = `-- name: RetireTokenPublicKey :execresult
+UPDATE token_public_keys
+SET state = 'retired', retired_at = ?
+WHERE id = ? AND state = ?
+`
+
+type RetireTokenPublicKeyParams struct {
+	RetiredAt sql.NullTime `json:"retired_at"`
+	ID        uint64       `json:"id"`
+	State     string       `json:"state"`
+}
+
+func (q *Queries) RetireTokenPublicKey(ctx context.Context, arg RetireTokenPublicKeyParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, retireTokenPublicKey, arg.RetiredAt, arg.ID, arg.State)
+}
+
 const updateAccountPassword = `-- name: UpdateAccountPassword :exec
 UPDATE account_passwords
 SET hashed_password = ?
@@ -110,3 +478,26 @@ func (q *Queries) UpdateAccountPassword(ctx context.Context, arg UpdateAccountPa
 	_, err := q.db.ExecContext(ctx, updateAccountPassword, arg.HashedPassword, arg.OfAccountID)
 	return err
 }
+
+const upsertWorkspaceMember = `-- name: UpsertWorkspaceMember :exec
+INSERT INTO workspace_members (workspace_id, account_id, role, created_at)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE role = VALUES(role)
+`
+
+type UpsertWorkspaceMemberParams struct {
+	WorkspaceID uint64    `json:"workspace_id"`
+	AccountID   uint64    `json:"account_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (q *Queries) UpsertWorkspaceMember(ctx context.Context, arg UpsertWorkspaceMemberParams) error {
+	_, err := q.db.ExecContext(ctx, upsertWorkspaceMember,
+		arg.WorkspaceID,
+		arg.AccountID,
+		arg.Role,
+		arg.CreatedAt,
+	)
+	return err
+}
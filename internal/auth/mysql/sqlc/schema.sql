@@ -15,8 +15,62 @@ CREATE TABLE IF NOT EXISTS account_passwords
 
 
 CREATE TABLE IF NOT EXISTS token_public_keys
+(
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    public_key   TEXT            NOT NULL,
+    private_key  TEXT,
+    state        VARCHAR(16)     NOT NULL DEFAULT 'active',
+    created_at   TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    activated_at DATETIME,
+    retired_at   DATETIME,
+    PRIMARY KEY (id),
+    INDEX idx_token_public_keys_state (state)
+);
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    of_account_id BIGINT UNSIGNED NOT NULL,
+    name          VARCHAR(128)    NOT NULL,
+    prefix        VARCHAR(32)     NOT NULL,
+    hashed_secret VARCHAR(128)    NOT NULL,
+    scopes        VARCHAR(255)    NOT NULL,
+    expires_at    DATETIME,
+    created_at    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at    DATETIME,
+    PRIMARY KEY (id),
+    UNIQUE (prefix),
+    INDEX (of_account_id),
+    FOREIGN KEY (of_account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS workspaces
 (
     id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
-    public_key TEXT            NOT NULL,
+    name       VARCHAR(128)    NOT NULL,
+    created_at TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (id)
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS workspace_members
+(
+    workspace_id BIGINT UNSIGNED NOT NULL,
+    account_id   BIGINT UNSIGNED NOT NULL,
+    role         VARCHAR(16)     NOT NULL,
+    created_at   TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workspace_id, account_id),
+    INDEX (account_id),
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS account_identities
+(
+    of_account_id BIGINT UNSIGNED NOT NULL,
+    issuer        VARCHAR(255)    NOT NULL,
+    subject       VARCHAR(255)    NOT NULL,
+    created_at    TIMESTAMP       NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (issuer, subject),
+    INDEX (of_account_id),
+    FOREIGN KEY (of_account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
@@ -0,0 +1,130 @@
+package authmysql
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/auth/mysql/sqlc"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+type workspaceStore struct {
+	queries *sqlc.Queries
+}
+
+func NewWorkspaceStore(db *sql.DB) auth.WorkspaceStore {
+	return &workspaceStore{
+		queries: sqlc.New(db),
+	}
+}
+
+func (w *workspaceStore) CreateWorkspace(ctx context.Context, workspace *auth.Workspace) (uint64, error) {
+	q := w.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	result, err := q.CreateWorkspace(ctx, sqlc.CreateWorkspaceParams{
+		Name:      workspace.Name,
+		CreatedAt: workspace.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+func (w *workspaceStore) ListWorkspaces(ctx context.Context, accountID uint64) ([]auth.WorkspaceMembership, error) {
+	rows, err := w.queries.ListWorkspacesByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := make([]auth.WorkspaceMembership, 0, len(rows))
+	for _, row := range rows {
+		workspaces = append(workspaces, auth.WorkspaceMembership{
+			Workspace: auth.Workspace{Id: row.ID, Name: row.Name, CreatedAt: row.CreatedAt},
+			Role:      auth.WorkspaceRole(row.Role),
+		})
+	}
+	return workspaces, nil
+}
+
+func (w *workspaceStore) GetWorkspaceMember(ctx context.Context, workspaceID, accountID uint64) (auth.WorkspaceMember, error) {
+	row, err := w.queries.GetWorkspaceMember(ctx, sqlc.GetWorkspaceMemberParams{
+		WorkspaceID: workspaceID,
+		AccountID:   accountID,
+	})
+	if err != nil {
+		if stderrors.Is(err, sql.ErrNoRows) {
+			return auth.WorkspaceMember{}, errors.ErrNotFound
+		}
+		return auth.WorkspaceMember{}, err
+	}
+	return toWorkspaceMember(sqlc.ListWorkspaceMembersRow(row)), nil
+}
+
+func (w *workspaceStore) ListWorkspaceMembers(ctx context.Context, workspaceID uint64) ([]auth.WorkspaceMember, error) {
+	q := w.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	rows, err := q.ListWorkspaceMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]auth.WorkspaceMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, toWorkspaceMember(row))
+	}
+	return members, nil
+}
+
+func (w *workspaceStore) SetWorkspaceMember(ctx context.Context, member *auth.WorkspaceMember) error {
+	q := w.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	return q.UpsertWorkspaceMember(ctx, sqlc.UpsertWorkspaceMemberParams{
+		WorkspaceID: member.WorkspaceId,
+		AccountID:   member.AccountId,
+		Role:        string(member.Role),
+		CreatedAt:   member.CreatedAt.UTC(),
+	})
+}
+
+func (w *workspaceStore) RemoveWorkspaceMember(ctx context.Context, workspaceID, accountID uint64) error {
+	q := w.queries
+	if tx, ok := getTxFrom(ctx); ok {
+		q = q.WithTx(tx)
+	}
+	result, err := q.DeleteWorkspaceMember(ctx, sqlc.DeleteWorkspaceMemberParams{
+		WorkspaceID: workspaceID,
+		AccountID:   accountID,
+	})
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func toWorkspaceMember(m sqlc.ListWorkspaceMembersRow) auth.WorkspaceMember {
+	return auth.WorkspaceMember{
+		WorkspaceId: m.WorkspaceID,
+		AccountId:   m.AccountID,
+		AccountName: m.AccountName,
+		Role:        auth.WorkspaceRole(m.Role),
+		CreatedAt:   m.CreatedAt,
+	}
+}
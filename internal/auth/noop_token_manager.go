@@ -15,36 +15,53 @@ type noopTokenManager struct {
 
 // NewNoopTokenManager returns a simple, non-cryptographic TokenManager
 // intended for pocket/single-user mode. Tokens are plain strings in the
-// format: "pocket:<accountID>:<expiryUnix>".
+// format: "pocket:<accountID>:<expiryUnix>:<sessionID>:<tokenID>:<issuedAtUnixMilli>".
 func NewNoopTokenManager(expiresIn time.Duration) TokenManager {
 	return &noopTokenManager{expiresIn: expiresIn}
 }
 
-func (n *noopTokenManager) Sign(accountID uint64) (string, error) {
-	expiry := time.Now().Add(n.expiresIn).Unix()
-	return fmt.Sprintf("pocket:%d:%d", accountID, expiry), nil
+func (n *noopTokenManager) Sign(accountID uint64, sessionID string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate token id", Cause: err}
+	}
+	now := time.Now()
+	expiry := now.Add(n.expiresIn).Unix()
+	return fmt.Sprintf("pocket:%d:%d:%s:%s:%d", accountID, expiry, sessionID, jti, now.UnixMilli()), nil
+}
+
+// PublicKeys returns no keys; pocket tokens are not signed.
+func (n *noopTokenManager) PublicKeys() []TokenPublicKey {
+	return nil
 }
 
-func (n *noopTokenManager) GetAccountIDFrom(token string) (uint64, error) {
+func (n *noopTokenManager) Parse(token string) (TokenClaims, error) {
 	parts := strings.Split(token, ":")
-	if len(parts) != 3 || parts[0] != "pocket" {
-		return 0, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid token format"}
+	// Three part tokens predate sessions and carry no ids.
+	if (len(parts) != 3 && len(parts) != 6) || parts[0] != "pocket" {
+		return TokenClaims{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid token format"}
 	}
 	id, err := strconv.ParseUint(parts[1], 10, 64)
 	if err != nil {
-		return 0, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid account id"}
-	}
-	return id, nil
-}
-
-func (n *noopTokenManager) GetExpiryFrom(token string) (time.Time, error) {
-	parts := strings.Split(token, ":")
-	if len(parts) != 3 || parts[0] != "pocket" {
-		return time.Time{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid token format"}
+		return TokenClaims{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid account id"}
 	}
 	exp, err := strconv.ParseInt(parts[2], 10, 64)
 	if err != nil {
-		return time.Time{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid expiry"}
+		return TokenClaims{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid expiry"}
+	}
+	claims := TokenClaims{AccountID: id, ExpiresAt: time.Unix(exp, 0)}
+	if len(parts) == 6 {
+		iat, err := strconv.ParseInt(parts[5], 10, 64)
+		if err != nil {
+			return TokenClaims{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid issue time"}
+		}
+		claims.SessionID = parts[3]
+		claims.ID = parts[4]
+		claims.IssuedAt = time.UnixMilli(iat)
 	}
-	return time.Unix(exp, 0), nil
+	return claims, nil
+}
+
+func (n *noopTokenManager) ExpiresIn() time.Duration {
+	return n.expiresIn
 }
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/pkg/oidc"
+)
+
+// OIDCProvider is the OpenID Connect provider accounts log in with.
+// *oidc.Provider implements it.
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems an authorization code and returns the verified ID
+	// token.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.IDToken, error)
+}
+
+// OIDCLogin is a login started by BeginOIDCLogin. It is kept by its state
+// until the provider sends the user back.
+type OIDCLogin struct {
+	CodeVerifier string
+	Nonce        string
+}
+
+type OIDCLoginStore interface {
+	SaveOIDCLogin(ctx context.Context, state string, login OIDCLogin, ttl time.Duration) error
+	// TakeOIDCLogin atomically removes and returns the login of a state. It
+	// returns errors.ErrNotFound for unknown or expired states.
+	TakeOIDCLogin(ctx context.Context, state string) (OIDCLogin, error)
+}
+
+// AccountIdentity links an account to a user of an OpenID Connect provider.
+type AccountIdentity struct {
+	OfAccountId uint64
+	Issuer      string
+	Subject     string
+	CreatedAt   time.Time
+}
+
+type AccountIdentityStore interface {
+	CreateAccountIdentity(ctx context.Context, identity *AccountIdentity) error
+	// GetAccountIdentity returns errors.ErrNotFound when no account is
+	// linked to the user.
+	GetAccountIdentity(ctx context.Context, issuer, subject string) (AccountIdentity, error)
+}
+
+// oidcLoginExpiresIn is how long a user has to log in at the provider.
+const oidcLoginExpiresIn = 10 * time.Minute
+
+// maxOIDCAccountNameLength keeps provisioned account names short enough
+// for a numeric suffix.
+const maxOIDCAccountNameLength = 64
+
+// WithOIDC enables logging in with an OpenID Connect provider. Accounts
+// are provisioned on the first login and linked to the issuer and subject
+// of the user in identities.
+func WithOIDC(provider OIDCProvider, logins OIDCLoginStore, identities AccountIdentityStore) ServiceOption {
+	return func(s *service) {
+		s.oidcProvider = provider
+		s.oidcLogins = logins
+		s.accountIdentities = identities
+	}
+}
+
+type BeginOIDCLoginOutput struct {
+	// AuthURL is the URL of the provider the user logs in at.
+	AuthURL string
+	// State identifies the login when the provider sends the user back.
+	State string
+}
+
+type CompleteOIDCLoginParams struct {
+	State string
+	Code  string
+}
+
+var errOIDCDisabled = &errors.Error{Code: errors.ErrCodeInvalidState, Message: "oidc login is disabled"}
+
+func (s *service) BeginOIDCLogin(ctx context.Context) (BeginOIDCLoginOutput, error) {
+	if s.oidcProvider == nil {
+		return BeginOIDCLoginOutput{}, errOIDCDisabled
+	}
+	state, err := newTokenID()
+	if err != nil {
+		return BeginOIDCLoginOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate state", Cause: err}
+	}
+	nonce, err := newTokenID()
+	if err != nil {
+		return BeginOIDCLoginOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate nonce", Cause: err}
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return BeginOIDCLoginOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate code verifier", Cause: err}
+	}
+
+	authURL, err := s.oidcProvider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return BeginOIDCLoginOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "oidc provider unavailable", Cause: err}
+	}
+	login := OIDCLogin{CodeVerifier: verifier, Nonce: nonce}
+	if err := s.oidcLogins.SaveOIDCLogin(ctx, state, login, oidcLoginExpiresIn); err != nil {
+		return BeginOIDCLoginOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to store oidc login", Cause: err}
+	}
+	return BeginOIDCLoginOutput{AuthURL: authURL, State: state}, nil
+}
+
+func (s *service) CompleteOIDCLogin(ctx context.Context, params CompleteOIDCLoginParams) (CreateSessionOutput, error) {
+	if s.oidcProvider == nil {
+		return CreateSessionOutput{}, errOIDCDisabled
+	}
+	if params.State == "" || params.Code == "" {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "state and code are required"}
+	}
+
+	// The state works once, so that a code cannot be replayed.
+	login, err := s.oidcLogins.TakeOIDCLogin(ctx, params.State)
+	if stderrors.Is(err, errors.ErrNotFound) {
+		return CreateSessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "unknown or expired login state"}
+	}
+	if err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get oidc login", Cause: err}
+	}
+
+	idToken, err := s.oidcProvider.Exchange(ctx, params.Code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		var providerErr *oidc.ErrorResponse
+		if stderrors.As(err, &providerErr) || stderrors.Is(err, oidc.ErrInvalidIDToken) {
+			return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeUnauthenticated, Message: "oidc login failed", Cause: err}
+		}
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "oidc provider unavailable", Cause: err}
+	}
+
+	account, err := s.oidcAccount(ctx, idToken)
+	if err != nil {
+		return CreateSessionOutput{}, err
+	}
+	sessionID, err := newTokenID()
+	if err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate session id", Cause: err}
+	}
+	out, err := s.issueSession(ctx, account.Id, sessionID)
+	if err != nil {
+		return CreateSessionOutput{}, err
+	}
+	out.Account = account
+	return out, nil
+}
+
+// oidcAccount returns the account linked to the user of the ID token,
+// provisioning one on the first login. Provisioned accounts have no
+// password. They are never linked to an existing account by name, so that
+// a provider user cannot take over a local account.
+func (s *service) oidcAccount(ctx context.Context, idToken *oidc.IDToken) (*Account, error) {
+	identity, err := s.accountIdentities.GetAccountIdentity(ctx, idToken.Issuer, idToken.Subject)
+	if err == nil {
+		account, err := s.accountStore.GetAccountByID(ctx, identity.OfAccountId)
+		if err != nil || account == nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get linked account", Cause: err}
+		}
+		return account, nil
+	}
+	if !stderrors.Is(err, errors.ErrNotFound) {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get account identity", Cause: err}
+	}
+
+	account := &Account{}
+	err = s.txManager.DoInTx(ctx, func(ctx context.Context) error {
+		name, err := s.freeAccountName(ctx, oidcAccountName(idToken))
+		if err != nil {
+			return err
+		}
+		account.AccountName = name
+		if account.Id, err = s.accountStore.CreateAccount(ctx, account); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "creating account failed", Cause: err}
+		}
+		if err := s.accountIdentities.CreateAccountIdentity(ctx, &AccountIdentity{
+			OfAccountId: account.Id,
+			Issuer:      idToken.Issuer,
+			Subject:     idToken.Subject,
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "linking account failed", Cause: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// oidcAccountName derives an account name from the claims of the user.
+func oidcAccountName(idToken *oidc.IDToken) string {
+	name := idToken.PreferredUsername
+	if name == "" {
+		name, _, _ = strings.Cut(idToken.Email, "@")
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	// Cut at a rune boundary, so that the name stays valid UTF-8.
+	n := 0
+	for n < len(name) {
+		_, size := utf8.DecodeRuneInString(name[n:])
+		if n+size > maxOIDCAccountNameLength {
+			break
+		}
+		n += size
+	}
+	name = name[:n]
+	if name == "" {
+		name = "user"
+	}
+	return name
+}
+
+// freeAccountName returns name, or name with the lowest numeric suffix
+// that is not taken.
+func (s *service) freeAccountName(ctx context.Context, name string) (string, error) {
+	candidate := name
+	for i := 2; i < 100; i++ {
+		if !s.isAccountNameTaken(ctx, candidate) {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	suffix, err := newTokenID()
+	if err != nil {
+		return "", &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate account name", Cause: err}
+	}
+	return name + "-" + suffix[:8], nil
+}
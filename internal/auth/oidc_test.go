@@ -0,0 +1,182 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/auth"
+	authcache "github.com/yuisofull/goload/internal/auth/cache"
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/pkg/cache/inmem"
+	"github.com/yuisofull/goload/pkg/oidc"
+	"github.com/yuisofull/goload/pkg/oidc/oidctest"
+)
+
+type memAccounts struct{ byName map[string]*auth.Account }
+
+func (m *memAccounts) CreateAccount(_ context.Context, account *auth.Account) (uint64, error) {
+	account.Id = uint64(len(m.byName) + 1)
+	m.byName[account.AccountName] = account
+	return account.Id, nil
+}
+
+func (m *memAccounts) GetAccountByID(_ context.Context, id uint64) (*auth.Account, error) {
+	for _, a := range m.byName {
+		if a.Id == id {
+			return a, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (m *memAccounts) GetAccountByAccountName(_ context.Context, name string) (*auth.Account, error) {
+	if a, ok := m.byName[name]; ok {
+		return a, nil
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+// memPasswords only knows the password of the first account.
+type memPasswords struct{ fakePasswordStore }
+
+func (memPasswords) GetAccountPassword(_ context.Context, id uint64) (auth.AccountPassword, error) {
+	if id != 1 {
+		return auth.AccountPassword{}, apperrors.ErrNotFound
+	}
+	return auth.AccountPassword{OfAccountId: id, HashedPassword: "secret"}, nil
+}
+
+type memIdentities map[[2]string]auth.AccountIdentity
+
+func (m memIdentities) CreateAccountIdentity(_ context.Context, identity *auth.AccountIdentity) error {
+	m[[2]string{identity.Issuer, identity.Subject}] = *identity
+	return nil
+}
+
+func (m memIdentities) GetAccountIdentity(_ context.Context, issuer, subject string) (auth.AccountIdentity, error) {
+	identity, ok := m[[2]string{issuer, subject}]
+	if !ok {
+		return auth.AccountIdentity{}, apperrors.ErrNotFound
+	}
+	return identity, nil
+}
+
+func newOIDCService(t *testing.T) auth.Service {
+	t.Helper()
+	issuer, err := oidctest.NewIssuer("goload", "secret")
+	require.NoError(t, err)
+	srv := httptest.NewServer(issuer)
+	t.Cleanup(srv.Close)
+	issuer.URL = srv.URL
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    srv.URL,
+		ClientID:     "goload",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+	})
+	return auth.NewService(
+		&memAccounts{byName: map[string]*auth.Account{"bob": {Id: 1, AccountName: "bob"}}},
+		memPasswords{},
+		noTx{},
+		plainHasher{},
+		auth.NewNoopTokenManager(time.Hour),
+		auth.WithOIDC(
+			provider,
+			authcache.NewOIDCLoginStore(inmem.New[string, auth.OIDCLogin](time.Minute)),
+			memIdentities{},
+		),
+	)
+}
+
+// oidcLogin logs user in at the stub issuer and returns the state and code
+// it redirects back with.
+func oidcLogin(t *testing.T, svc auth.Service, user string) auth.CompleteOIDCLoginParams {
+	t.Helper()
+	begin, err := svc.BeginOIDCLogin(context.Background())
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(begin.AuthURL + "&login_hint=" + url.QueryEscape(user))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, begin.State, location.Query().Get("state"))
+	return auth.CompleteOIDCLoginParams{State: begin.State, Code: location.Query().Get("code")}
+}
+
+func TestCompleteOIDCLogin_ProvisionsAndLinksAccount(t *testing.T) {
+	ctx := context.Background()
+	svc := newOIDCService(t)
+
+	// A local account named bob exists, the provider user gets another one.
+	first, err := svc.CompleteOIDCLogin(ctx, oidcLogin(t, svc, "bob"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.Token)
+	assert.Equal(t, "bob-2", first.Account.AccountName)
+	assert.NotEqual(t, uint64(1), first.Account.Id)
+
+	verified, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: first.Token})
+	require.NoError(t, err)
+	assert.Equal(t, first.Account.Id, verified.AccountID)
+
+	// The next login finds the linked account.
+	second, err := svc.CompleteOIDCLogin(ctx, oidcLogin(t, svc, "bob"))
+	require.NoError(t, err)
+	assert.Equal(t, first.Account.Id, second.Account.Id)
+
+	// Provisioned accounts have no password.
+	_, err = svc.CreateSession(ctx, auth.CreateSessionParams{AccountName: "bob-2", Password: ""})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidPassword))
+}
+
+func TestCompleteOIDCLogin_TruncatesNonASCIINameAtRuneBoundary(t *testing.T) {
+	svc := newOIDCService(t)
+
+	// 81 bytes; the 64th byte is the first of a two-byte rune.
+	user := "a" + strings.Repeat("é", 40)
+	resp, err := svc.CompleteOIDCLogin(context.Background(), oidcLogin(t, svc, user))
+	require.NoError(t, err)
+	name := resp.Account.AccountName
+	assert.True(t, utf8.ValidString(name))
+	assert.Equal(t, "a"+strings.Repeat("é", 31), name)
+}
+
+func TestCompleteOIDCLogin_RejectsReplayedState(t *testing.T) {
+	ctx := context.Background()
+	svc := newOIDCService(t)
+
+	params := oidcLogin(t, svc, "carol")
+	_, err := svc.CompleteOIDCLogin(ctx, params)
+	require.NoError(t, err)
+
+	_, err = svc.CompleteOIDCLogin(ctx, params)
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+
+	// A login the provider refuses, here a code that was never issued.
+	params = oidcLogin(t, svc, "carol")
+	params.Code = "forged"
+	_, err = svc.CompleteOIDCLogin(ctx, params)
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeUnauthenticated))
+
+	_, err = svc.CompleteOIDCLogin(ctx, auth.CompleteOIDCLoginParams{State: "s"})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestOIDCLogin_Disabled(t *testing.T) {
+	_, err := newSessionService(t).BeginOIDCLogin(context.Background())
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidState))
+}
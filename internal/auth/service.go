@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	stderrors "errors"
+	"strings"
 	"time"
 
 	"github.com/yuisofull/goload/internal/errors"
@@ -24,8 +25,13 @@ type CreateSessionParams struct {
 }
 
 type CreateSessionOutput struct {
-	Token   string
-	Account *Account
+	Token string
+	// ExpiresIn is the lifetime of Token.
+	ExpiresIn time.Duration
+	// RefreshToken is exchanged for a new session output by RefreshSession.
+	// It is empty when refresh tokens are disabled.
+	RefreshToken string
+	Account      *Account
 }
 
 type VerifySessionParams struct {
@@ -34,6 +40,11 @@ type VerifySessionParams struct {
 
 type VerifySessionOutput struct {
 	AccountID uint64
+	SessionID string
+	// APIKeyID is set when the token is an API key, which only grants
+	// Scopes. Session tokens grant every scope.
+	APIKeyID uint64
+	Scopes   []string
 }
 
 type SessionValidator interface {
@@ -43,6 +54,31 @@ type SessionValidator interface {
 type Service interface {
 	CreateAccount(ctx context.Context, params CreateAccountParams) (CreateAccountOutput, error)
 	CreateSession(ctx context.Context, params CreateSessionParams) (CreateSessionOutput, error)
+	// RefreshSession exchanges a refresh token for a new access and refresh
+	// token of the same session.
+	RefreshSession(ctx context.Context, params RefreshSessionParams) (CreateSessionOutput, error)
+	Logout(ctx context.Context, params LogoutParams) error
+	// RevokeSession revokes one or all sessions of an account.
+	RevokeSession(ctx context.Context, params RevokeSessionParams) error
+	CreateAPIKey(ctx context.Context, params CreateAPIKeyParams) (CreateAPIKeyOutput, error)
+	ListAPIKeys(ctx context.Context, params ListAPIKeysParams) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, params RevokeAPIKeyParams) error
+	// ListPublicKeys returns the keys that verify access tokens.
+	ListPublicKeys(ctx context.Context) ([]TokenPublicKey, error)
+	CreateWorkspace(ctx context.Context, params CreateWorkspaceParams) (Workspace, error)
+	ListWorkspaces(ctx context.Context, params ListWorkspacesParams) ([]WorkspaceMembership, error)
+	// GetWorkspaceRole returns the role of an account in a workspace, or
+	// NOT_FOUND when it is not a member.
+	GetWorkspaceRole(ctx context.Context, params GetWorkspaceRoleParams) (WorkspaceRole, error)
+	ListWorkspaceMembers(ctx context.Context, params ListWorkspaceMembersParams) ([]WorkspaceMember, error)
+	SetWorkspaceMember(ctx context.Context, params SetWorkspaceMemberParams) (WorkspaceMember, error)
+	RemoveWorkspaceMember(ctx context.Context, params RemoveWorkspaceMemberParams) error
+	// BeginOIDCLogin starts a login at the OpenID Connect provider.
+	BeginOIDCLogin(ctx context.Context) (BeginOIDCLoginOutput, error)
+	// CompleteOIDCLogin finishes a login when the provider sends the user
+	// back, provisioning an account on the first login.
+	CompleteOIDCLogin(ctx context.Context, params CompleteOIDCLoginParams) (CreateSessionOutput, error)
+	// VerifySession accepts session tokens as well as API keys.
 	SessionValidator
 }
 
@@ -73,6 +109,15 @@ type service struct {
 	passwordHasher       PasswordHasher
 	txManager            TxManager
 	tokenManager         TokenManager
+	refreshTokens        RefreshTokenStore
+	refreshExpiresIn     time.Duration
+	revocations          RevocationList
+	apiKeys              APIKeyStore
+	apiKeyCache          *apiKeyCache
+	workspaces           WorkspaceStore
+	oidcProvider         OIDCProvider
+	oidcLogins           OIDCLoginStore
+	accountIdentities    AccountIdentityStore
 }
 
 func NewService(
@@ -81,14 +126,20 @@ func NewService(
 	txManager TxManager,
 	hasher PasswordHasher,
 	tokenManager TokenManager,
+	opts ...ServiceOption,
 ) Service {
-	return &service{
+	s := &service{
 		accountStore:         accountStore,
 		accountPasswordStore: accountPasswordStore,
 		passwordHasher:       hasher,
 		txManager:            txManager,
 		tokenManager:         tokenManager,
+		refreshExpiresIn:     defaultRefreshTokenExpiresIn,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *service) CreateAccount(ctx context.Context, params CreateAccountParams) (CreateAccountOutput, error) {
@@ -149,6 +200,10 @@ func (s *service) CreateSession(ctx context.Context, params CreateSessionParams)
 	}
 
 	accountPassword, err := s.accountPasswordStore.GetAccountPassword(ctx, account.Id)
+	if stderrors.Is(err, errors.ErrNotFound) {
+		// Accounts provisioned by an OpenID Connect login have no password.
+		return CreateSessionOutput{}, &errors.Error{Code: ErrCodeInvalidPassword, Message: "invalid password"}
+	}
 	if err != nil {
 		return CreateSessionOutput{}, &errors.Error{
 			Code:    errors.ErrCodeInternal,
@@ -165,12 +220,16 @@ func (s *service) CreateSession(ctx context.Context, params CreateSessionParams)
 		}
 	}
 
-	token, err := s.tokenManager.Sign(account.Id)
+	sessionID, err := newTokenID()
+	if err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate session id", Cause: err}
+	}
+	out, err := s.issueSession(ctx, account.Id, sessionID)
 	if err != nil {
 		return CreateSessionOutput{}, err
 	}
-
-	return CreateSessionOutput{Token: token, Account: account}, nil
+	out.Account = account
+	return out, nil
 }
 
 func (s *service) isAccountNameTaken(ctx context.Context, accountName string) bool {
@@ -183,16 +242,31 @@ func (s *service) isAccountNameTaken(ctx context.Context, accountName string) bo
 }
 
 func (s *service) VerifySession(ctx context.Context, params VerifySessionParams) (VerifySessionOutput, error) {
-	accountID, err := s.tokenManager.GetAccountIDFrom(params.Token)
+	if strings.HasPrefix(params.Token, apiKeyTokenPrefix) {
+		return s.verifyAPIKey(ctx, params.Token)
+	}
+	claims, err := s.verifyToken(ctx, params.Token)
 	if err != nil {
 		return VerifySessionOutput{}, err
 	}
-	expiry, err := s.tokenManager.GetExpiryFrom(params.Token)
+	return VerifySessionOutput{AccountID: claims.AccountID, SessionID: claims.SessionID}, nil
+}
+
+// verifyToken parses the token and rejects it once expired or revoked.
+func (s *service) verifyToken(ctx context.Context, token string) (TokenClaims, error) {
+	claims, err := s.tokenManager.Parse(token)
 	if err != nil {
-		return VerifySessionOutput{}, err
+		return TokenClaims{}, err
+	}
+	if claims.ExpiresAt.Before(time.Now()) {
+		return TokenClaims{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "token expired"}
+	}
+	revoked, err := s.isRevoked(ctx, claims.ID, claims.SessionID, claims.AccountID, claims.IssuedAt)
+	if err != nil {
+		return TokenClaims{}, err
 	}
-	if expiry.Before(time.Now()) {
-		return VerifySessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "token expired"}
+	if revoked {
+		return TokenClaims{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "token revoked"}
 	}
-	return VerifySessionOutput{AccountID: accountID}, nil
+	return claims, nil
 }
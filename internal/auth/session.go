@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+const defaultRefreshTokenExpiresIn = 30 * 24 * time.Hour
+
+// RefreshSession is what a refresh token stands for. Every exchange of a
+// refresh token rotates it: the old token is kept as Rotated until it would
+// have expired, and presenting it again revokes the whole session.
+type RefreshSession struct {
+	AccountID uint64
+	SessionID string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	Rotated   bool
+}
+
+// RefreshTokenStore keeps refresh sessions by the hash of their token.
+type RefreshTokenStore interface {
+	SaveRefreshToken(ctx context.Context, tokenHash string, session RefreshSession, ttl time.Duration) error
+	// TakeRefreshToken atomically removes and returns the session of a
+	// token. It returns errors.ErrNotFound for unknown or expired tokens.
+	TakeRefreshToken(ctx context.Context, tokenHash string) (RefreshSession, error)
+}
+
+// RevocationList records revoked ids until the tokens they cover expire.
+// Ids are token ids (jti), session ids scoped to their account and account
+// ids, each with its own prefix; see revokedTokenID, revokedSessionID and
+// revokedAccountID.
+type RevocationList interface {
+	Revoke(ctx context.Context, id string, at time.Time, ttl time.Duration) error
+	// RevokedAt returns when id was revoked and false if it is not revoked.
+	RevokedAt(ctx context.Context, id string) (time.Time, bool, error)
+}
+
+type ServiceOption func(*service)
+
+// WithRefreshTokens enables refresh tokens that expire after expiresIn
+// without being used.
+func WithRefreshTokens(store RefreshTokenStore, expiresIn time.Duration) ServiceOption {
+	return func(s *service) {
+		s.refreshTokens = store
+		if expiresIn > 0 {
+			s.refreshExpiresIn = expiresIn
+		}
+	}
+}
+
+// WithRevocationList enables Logout and RevokeSession and makes
+// VerifySession reject revoked tokens.
+func WithRevocationList(list RevocationList) ServiceOption {
+	return func(s *service) {
+		s.revocations = list
+	}
+}
+
+type RefreshSessionParams struct {
+	RefreshToken string
+}
+
+type LogoutParams struct {
+	Token string
+}
+
+type RevokeSessionParams struct {
+	AccountID uint64
+	// SessionID selects the session to revoke; empty revokes every session
+	// of the account.
+	SessionID string
+}
+
+func revokedTokenID(jti string) string {
+	return "jti:" + jti
+}
+
+func revokedSessionID(accountID uint64, sid string) string {
+	return fmt.Sprintf("sid:%d:%s", accountID, sid)
+}
+
+func revokedAccountID(id uint64) string {
+	return fmt.Sprintf("account:%d", id)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// issueSession signs an access token for the session and, when refresh
+// tokens are enabled, a refresh token.
+func (s *service) issueSession(ctx context.Context, accountID uint64, sessionID string) (CreateSessionOutput, error) {
+	token, err := s.tokenManager.Sign(accountID, sessionID)
+	if err != nil {
+		return CreateSessionOutput{}, err
+	}
+	out := CreateSessionOutput{Token: token, ExpiresIn: s.tokenManager.ExpiresIn()}
+	if s.refreshTokens == nil {
+		return out, nil
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate refresh token", Cause: err}
+	}
+	now := time.Now()
+	if err := s.refreshTokens.SaveRefreshToken(ctx, hashRefreshToken(refreshToken), RefreshSession{
+		AccountID: accountID,
+		SessionID: sessionID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.refreshExpiresIn),
+	}, s.refreshExpiresIn); err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to store refresh token", Cause: err}
+	}
+	out.RefreshToken = refreshToken
+	return out, nil
+}
+
+func (s *service) RefreshSession(ctx context.Context, params RefreshSessionParams) (CreateSessionOutput, error) {
+	if s.refreshTokens == nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInvalidState, Message: "refresh tokens are disabled"}
+	}
+	invalid := &errors.Error{Code: ErrCodeInvalidToken, Message: "invalid refresh token"}
+	if params.RefreshToken == "" {
+		return CreateSessionOutput{}, invalid
+	}
+
+	key := hashRefreshToken(params.RefreshToken)
+	rs, err := s.refreshTokens.TakeRefreshToken(ctx, key)
+	if stderrors.Is(err, errors.ErrNotFound) {
+		return CreateSessionOutput{}, invalid
+	}
+	if err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get refresh token", Cause: err}
+	}
+	if rs.Rotated {
+		// A rotated token is only presented again when it was copied, so
+		// neither copy of the session can be trusted any more.
+		if err := s.revoke(ctx, revokedSessionID(rs.AccountID, rs.SessionID), s.refreshExpiresIn); err != nil {
+			return CreateSessionOutput{}, err
+		}
+		return CreateSessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "refresh token reused, session revoked"}
+	}
+	if revoked, err := s.isRevoked(ctx, "", rs.SessionID, rs.AccountID, rs.IssuedAt); err != nil {
+		return CreateSessionOutput{}, err
+	} else if revoked {
+		return CreateSessionOutput{}, &errors.Error{Code: ErrCodeInvalidToken, Message: "session revoked"}
+	}
+
+	rs.Rotated = true
+	if ttl := time.Until(rs.ExpiresAt); ttl > 0 {
+		if err := s.refreshTokens.SaveRefreshToken(ctx, key, rs, ttl); err != nil {
+			return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to rotate refresh token", Cause: err}
+		}
+	}
+
+	out, err := s.issueSession(ctx, rs.AccountID, rs.SessionID)
+	if err != nil {
+		return CreateSessionOutput{}, err
+	}
+	account, err := s.accountStore.GetAccountByID(ctx, rs.AccountID)
+	if err != nil {
+		return CreateSessionOutput{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get account", Cause: err}
+	}
+	out.Account = account
+	return out, nil
+}
+
+// Logout revokes the session of the token, including its other access
+// tokens and its refresh token.
+func (s *service) Logout(ctx context.Context, params LogoutParams) error {
+	claims, err := s.verifyToken(ctx, params.Token)
+	if err != nil {
+		return err
+	}
+	if claims.SessionID == "" {
+		return &errors.Error{Code: ErrCodeInvalidToken, Message: "token has no session"}
+	}
+	if err := s.revoke(ctx, revokedTokenID(claims.ID), time.Until(claims.ExpiresAt)); err != nil {
+		return err
+	}
+	return s.revoke(ctx, revokedSessionID(claims.AccountID, claims.SessionID), s.sessionTTL())
+}
+
+func (s *service) RevokeSession(ctx context.Context, params RevokeSessionParams) error {
+	if params.AccountID == 0 {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "account id is required"}
+	}
+	if params.SessionID != "" {
+		return s.revoke(ctx, revokedSessionID(params.AccountID, params.SessionID), s.sessionTTL())
+	}
+	if err := s.revoke(ctx, revokedAccountID(params.AccountID), s.sessionTTL()); err != nil {
+		return err
+	}
+	return s.revokeAPIKeys(ctx, params.AccountID, time.Now())
+}
+
+// sessionTTL is how long any token of a session issued now stays usable.
+func (s *service) sessionTTL() time.Duration {
+	if s.refreshTokens != nil {
+		return max(s.refreshExpiresIn, s.tokenManager.ExpiresIn())
+	}
+	return s.tokenManager.ExpiresIn()
+}
+
+func (s *service) revoke(ctx context.Context, id string, ttl time.Duration) error {
+	if s.revocations == nil {
+		return &errors.Error{Code: errors.ErrCodeInvalidState, Message: "session revocation is disabled"}
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.revocations.Revoke(ctx, id, time.Now(), ttl); err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to revoke session", Cause: err}
+	}
+	return nil
+}
+
+// isRevoked reports whether the token id or session was revoked, or whether
+// all sessions of the account were revoked after issuedAt.
+func (s *service) isRevoked(ctx context.Context, jti, sessionID string, accountID uint64, issuedAt time.Time) (bool, error) {
+	if s.revocations == nil {
+		return false, nil
+	}
+	var ids []string
+	if jti != "" {
+		ids = append(ids, revokedTokenID(jti))
+	}
+	if sessionID != "" {
+		ids = append(ids, revokedSessionID(accountID, sessionID))
+	}
+	for _, id := range ids {
+		if _, ok, err := s.revocations.RevokedAt(ctx, id); err != nil {
+			return false, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to check revocation", Cause: err}
+		} else if ok {
+			return true, nil
+		}
+	}
+	at, ok, err := s.revocations.RevokedAt(ctx, revokedAccountID(accountID))
+	if err != nil {
+		return false, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to check revocation", Cause: err}
+	}
+	return ok && issuedAt.Before(at), nil
+}
@@ -0,0 +1,141 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/auth"
+	authcache "github.com/yuisofull/goload/internal/auth/cache"
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/pkg/cache/inmem"
+)
+
+type fakeAccountStore struct{ account *auth.Account }
+
+func (f *fakeAccountStore) CreateAccount(context.Context, *auth.Account) (uint64, error) {
+	return f.account.Id, nil
+}
+
+func (f *fakeAccountStore) GetAccountByID(context.Context, uint64) (*auth.Account, error) {
+	return f.account, nil
+}
+
+func (f *fakeAccountStore) GetAccountByAccountName(context.Context, string) (*auth.Account, error) {
+	return f.account, nil
+}
+
+type fakePasswordStore struct{}
+
+func (fakePasswordStore) CreateAccountPassword(context.Context, *auth.AccountPassword) error {
+	return nil
+}
+
+func (fakePasswordStore) UpdateAccountPassword(context.Context, *auth.AccountPassword) error {
+	return nil
+}
+
+func (fakePasswordStore) GetAccountPassword(_ context.Context, id uint64) (auth.AccountPassword, error) {
+	return auth.AccountPassword{OfAccountId: id, HashedPassword: "secret"}, nil
+}
+
+type plainHasher struct{}
+
+func (plainHasher) Hash(_ context.Context, password string) (string, error) { return password, nil }
+
+func (plainHasher) Verify(_ context.Context, password, hashed string) error {
+	if password != hashed {
+		return auth.ErrInvalidPassword
+	}
+	return nil
+}
+
+type noTx struct{}
+
+func (noTx) DoInTx(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+func newSessionService(t *testing.T) auth.Service {
+	t.Helper()
+	return auth.NewService(
+		&fakeAccountStore{account: &auth.Account{Id: 7, AccountName: "alice"}},
+		fakePasswordStore{},
+		noTx{},
+		plainHasher{},
+		auth.NewNoopTokenManager(time.Hour),
+		auth.WithRefreshTokens(authcache.NewRefreshTokenStore(inmem.New[string, auth.RefreshSession](time.Minute)), 0),
+		auth.WithRevocationList(authcache.NewRevocationList(inmem.New[string, int64](time.Minute))),
+	)
+}
+
+func login(t *testing.T, svc auth.Service) auth.CreateSessionOutput {
+	t.Helper()
+	out, err := svc.CreateSession(context.Background(), auth.CreateSessionParams{AccountName: "alice", Password: "secret"})
+	require.NoError(t, err)
+	require.NotEmpty(t, out.RefreshToken)
+	return out
+}
+
+func TestRefreshSession_RotatesAndDetectsReuse(t *testing.T) {
+	ctx := context.Background()
+	svc := newSessionService(t)
+	first := login(t, svc)
+
+	second, err := svc.RefreshSession(ctx, auth.RefreshSessionParams{RefreshToken: first.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.Equal(t, uint64(7), second.Account.Id)
+
+	v1, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: first.Token})
+	require.NoError(t, err)
+	v2, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: second.Token})
+	require.NoError(t, err)
+	assert.Equal(t, v1.SessionID, v2.SessionID)
+
+	// Replaying the first refresh token revokes the whole session.
+	_, err = svc.RefreshSession(ctx, auth.RefreshSessionParams{RefreshToken: first.RefreshToken})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: second.Token})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	_, err = svc.RefreshSession(ctx, auth.RefreshSessionParams{RefreshToken: second.RefreshToken})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+}
+
+func TestLogout_RevokesOnlyItsSession(t *testing.T) {
+	ctx := context.Background()
+	svc := newSessionService(t)
+	a, b := login(t, svc), login(t, svc)
+
+	require.NoError(t, svc.Logout(ctx, auth.LogoutParams{Token: a.Token}))
+
+	_, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: a.Token})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	_, err = svc.RefreshSession(ctx, auth.RefreshSessionParams{RefreshToken: a.RefreshToken})
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	_, err = svc.VerifySession(ctx, auth.VerifySessionParams{Token: b.Token})
+	assert.NoError(t, err)
+}
+
+func TestRevokeSession_AllSessionsOfAccount(t *testing.T) {
+	ctx := context.Background()
+	svc := newSessionService(t)
+	a, b := login(t, svc), login(t, svc)
+	time.Sleep(2 * time.Millisecond)
+
+	require.NoError(t, svc.RevokeSession(ctx, auth.RevokeSessionParams{AccountID: 7}))
+
+	for _, s := range []auth.CreateSessionOutput{a, b} {
+		_, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: s.Token})
+		assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+		_, err = svc.RefreshSession(ctx, auth.RefreshSessionParams{RefreshToken: s.RefreshToken})
+		assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	}
+
+	// Sessions created after the cutoff are unaffected.
+	time.Sleep(2 * time.Millisecond)
+	c := login(t, svc)
+	_, err := svc.VerifySession(ctx, auth.VerifySessionParams{Token: c.Token})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,332 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/internal/errors"
+	pkgrsa "github.com/yuisofull/goload/pkg/crypto/rsa"
+)
+
+// SigningKey is an RSA key pair used to sign access tokens. Keys are shared
+// by every auth replica through the SigningKeyStore.
+type SigningKey struct {
+	Id          uint64
+	PublicKey   []byte
+	PrivateKey  []byte
+	State       SigningKeyState
+	CreatedAt   time.Time
+	ActivatedAt *time.Time
+	RetiredAt   *time.Time
+}
+
+type SigningKeyStore interface {
+	CreateSigningKey(ctx context.Context, key *SigningKey) (kid uint64, err error)
+	// ListSigningKeys returns the keys that are not retired, oldest first.
+	ListSigningKeys(ctx context.Context) ([]SigningKey, error)
+	// UpdateSigningKeyState moves a key from state from to state to. It
+	// returns errors.ErrNotFound when the key is not in state from, which
+	// happens when another replica moved it first.
+	UpdateSigningKeyState(ctx context.Context, kid uint64, from, to SigningKeyState, at time.Time) error
+}
+
+// minKeyReloadInterval limits how often an unknown kid reloads the keys, so
+// that tokens with made-up kids cannot hammer the store.
+const minKeyReloadInterval = 5 * time.Second
+
+// SigningKeys rotates the signing keys in a SigningKeyStore and keeps the
+// keys that are not retired in memory.
+//
+// A rotation publishes a next key, promotes it to active once the current
+// active key is older than the rotation interval, and retires active keys
+// once no token signed by them can still be valid. Rotate is safe to run on
+// every replica: state changes only apply once.
+type SigningKeys struct {
+	store            SigningKeyStore
+	rotationInterval time.Duration
+	retireAfter      time.Duration
+	checkInterval    time.Duration
+	rsaBits          int
+	logger           log.Logger
+
+	mu         sync.RWMutex
+	keys       []SigningKey
+	publicKeys map[uint64]*rsa.PublicKey
+	signer     *rsa.PrivateKey
+	signerKid  uint64
+	loadedAt   time.Time
+}
+
+// SigningKeysOption configures SigningKeys.
+type SigningKeysOption func(*SigningKeys)
+
+// WithKeyRotationInterval sets how long a key signs tokens before the next
+// key takes over. Defaults to 720h.
+func WithKeyRotationInterval(d time.Duration) SigningKeysOption {
+	return func(s *SigningKeys) {
+		if d > 0 {
+			s.rotationInterval = d
+		}
+	}
+}
+
+// WithKeyCheckInterval sets how often Run rotates and reloads the keys.
+// Defaults to 1m.
+func WithKeyCheckInterval(d time.Duration) SigningKeysOption {
+	return func(s *SigningKeys) {
+		if d > 0 {
+			s.checkInterval = d
+		}
+	}
+}
+
+// WithSigningKeyBits sets the size of generated RSA keys. Defaults to 2048.
+func WithSigningKeyBits(bits int) SigningKeysOption {
+	return func(s *SigningKeys) {
+		if bits > 0 {
+			s.rsaBits = bits
+		}
+	}
+}
+
+// WithSigningKeysLogger configures the logger of Run.
+func WithSigningKeysLogger(l log.Logger) SigningKeysOption {
+	return func(s *SigningKeys) { s.logger = l }
+}
+
+// NewSigningKeys returns signing keys kept in store. retireAfter is how long
+// a key keeps verifying tokens after a newer key took over; it must be at
+// least the lifetime of access tokens.
+func NewSigningKeys(store SigningKeyStore, retireAfter time.Duration, opts ...SigningKeysOption) *SigningKeys {
+	s := &SigningKeys{
+		store:            store,
+		rotationInterval: 720 * time.Hour,
+		retireAfter:      retireAfter,
+		checkInterval:    time.Minute,
+		rsaBits:          2048,
+		logger:           log.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Run rotates the keys until ctx is done.
+func (s *SigningKeys) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if err := s.Rotate(ctx, time.Now()); err != nil {
+			level.Error(s.logger).Log("msg", "failed to rotate signing keys", "err", err)
+		}
+	}
+}
+
+// Rotate brings the keys to their state at now and reloads them.
+func (s *SigningKeys) Rotate(ctx context.Context, now time.Time) error {
+	keys, err := s.store.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var next *SigningKey
+	for i := range keys {
+		if keys[i].State == SigningKeyStateNext {
+			next = &keys[i]
+			break
+		}
+	}
+	if next == nil {
+		if next, err = s.createNextKey(ctx, now); err != nil {
+			return err
+		}
+	}
+
+	current := newestActive(keys)
+	if current == nil || !current.ActivatedAt.Add(s.rotationInterval).After(now) {
+		err := s.store.UpdateSigningKeyState(ctx, next.Id, SigningKeyStateNext, SigningKeyStateActive, now)
+		switch {
+		case err == nil:
+			level.Info(s.logger).Log("msg", "activated signing key", "kid", next.Id)
+		case !stderrors.Is(err, errors.ErrNotFound):
+			return err
+		}
+		// Publish the key after it right away, unless replicas racing at
+		// startup already left a spare one.
+		spare := slices.ContainsFunc(keys, func(k SigningKey) bool {
+			return k.State == SigningKeyStateNext && k.Id != next.Id
+		})
+		if !spare {
+			if _, err := s.createNextKey(ctx, now); err != nil {
+				return err
+			}
+		}
+		if keys, err = s.store.ListSigningKeys(ctx); err != nil {
+			return err
+		}
+		current = newestActive(keys)
+	}
+
+	if current != nil && !current.ActivatedAt.Add(s.retireAfter).After(now) {
+		for _, key := range keys {
+			if key.State != SigningKeyStateActive || key.Id == current.Id {
+				continue
+			}
+			err := s.store.UpdateSigningKeyState(ctx, key.Id, SigningKeyStateActive, SigningKeyStateRetired, now)
+			switch {
+			case err == nil:
+				level.Info(s.logger).Log("msg", "retired signing key", "kid", key.Id)
+			case !stderrors.Is(err, errors.ErrNotFound):
+				return err
+			}
+		}
+	}
+
+	return s.reload(ctx, now)
+}
+
+func (s *SigningKeys) createNextKey(ctx context.Context, now time.Time) (*SigningKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, s.rsaBits)
+	if err != nil {
+		return nil, err
+	}
+	publicPEM, err := pkgrsa.SerializePublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	privatePEM, err := pkgrsa.SerializePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{
+		PublicKey:  publicPEM,
+		PrivateKey: privatePEM,
+		State:      SigningKeyStateNext,
+		CreatedAt:  now,
+	}
+	if key.Id, err = s.store.CreateSigningKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newestActive returns the active key that was activated last. Keys
+// without a private key, such as keys of replicas from before rotation
+// existed, never sign.
+func newestActive(keys []SigningKey) *SigningKey {
+	var newest *SigningKey
+	for i := range keys {
+		key := &keys[i]
+		if key.State != SigningKeyStateActive || key.ActivatedAt == nil || key.PrivateKey == nil {
+			continue
+		}
+		if newest == nil || key.ActivatedAt.After(*newest.ActivatedAt) {
+			newest = key
+		}
+	}
+	return newest
+}
+
+func (s *SigningKeys) reload(ctx context.Context, now time.Time) error {
+	keys, err := s.store.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	publicKeys := make(map[uint64]*rsa.PublicKey, len(keys))
+	for _, key := range keys {
+		publicKey, err := pkgrsa.DeserializePublicKey(key.PublicKey)
+		if err != nil {
+			return fmt.Errorf("signing key %d: %w", key.Id, err)
+		}
+		publicKeys[key.Id] = publicKey
+	}
+	var (
+		signer    *rsa.PrivateKey
+		signerKid uint64
+	)
+	if current := newestActive(keys); current != nil {
+		if signer, err = pkgrsa.DeserializePrivateKey(current.PrivateKey); err != nil {
+			return fmt.Errorf("signing key %d: %w", current.Id, err)
+		}
+		signerKid = current.Id
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	s.publicKeys = publicKeys
+	s.signer = signer
+	s.signerKid = signerKid
+	s.loadedAt = now
+	return nil
+}
+
+// signingKey returns the key new tokens are signed with.
+func (s *SigningKeys) signingKey() (uint64, *rsa.PrivateKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.signer == nil {
+		return 0, nil, stderrors.New("no active signing key")
+	}
+	return s.signerKid, s.signer, nil
+}
+
+// publicKey returns the public key of kid unless the key is retired. Keys
+// activated by other replicas are picked up by reloading the keys.
+func (s *SigningKeys) publicKey(ctx context.Context, kid uint64) (*rsa.PublicKey, error) {
+	s.mu.RLock()
+	publicKey, ok := s.publicKeys[kid]
+	loadedAt := s.loadedAt
+	s.mu.RUnlock()
+	if ok {
+		return publicKey, nil
+	}
+
+	now := time.Now()
+	if now.Sub(loadedAt) < minKeyReloadInterval {
+		return nil, fmt.Errorf("unknown signing key %d", kid)
+	}
+	if err := s.reload(ctx, now); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if publicKey, ok = s.publicKeys[kid]; !ok {
+		return nil, fmt.Errorf("unknown signing key %d", kid)
+	}
+	return publicKey, nil
+}
+
+// PublicKeys returns the keys that verify tokens, oldest first.
+func (s *SigningKeys) PublicKeys() []TokenPublicKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]TokenPublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, TokenPublicKey{
+			Id:          key.Id,
+			PublicKey:   slices.Clone(key.PublicKey),
+			State:       key.State,
+			ActivatedAt: key.ActivatedAt,
+		})
+	}
+	return keys
+}
@@ -0,0 +1,142 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/auth"
+	apperrors "github.com/yuisofull/goload/internal/errors"
+)
+
+type fakeSigningKeyStore struct {
+	keys []*auth.SigningKey
+}
+
+func (f *fakeSigningKeyStore) CreateSigningKey(_ context.Context, key *auth.SigningKey) (uint64, error) {
+	k := *key
+	k.Id = uint64(len(f.keys) + 1)
+	f.keys = append(f.keys, &k)
+	return k.Id, nil
+}
+
+func (f *fakeSigningKeyStore) ListSigningKeys(context.Context) ([]auth.SigningKey, error) {
+	var keys []auth.SigningKey
+	for _, k := range f.keys {
+		if k.State != auth.SigningKeyStateRetired {
+			keys = append(keys, *k)
+		}
+	}
+	return keys, nil
+}
+
+func (f *fakeSigningKeyStore) UpdateSigningKeyState(
+	_ context.Context,
+	kid uint64,
+	from, to auth.SigningKeyState,
+	at time.Time,
+) error {
+	for _, k := range f.keys {
+		if k.Id != kid || k.State != from {
+			continue
+		}
+		k.State = to
+		switch to {
+		case auth.SigningKeyStateActive:
+			k.ActivatedAt = &at
+		case auth.SigningKeyStateRetired:
+			k.RetiredAt = &at
+		}
+		return nil
+	}
+	return apperrors.ErrNotFound
+}
+
+func (f *fakeSigningKeyStore) states() map[uint64]auth.SigningKeyState {
+	states := map[uint64]auth.SigningKeyState{}
+	for _, k := range f.keys {
+		states[k.Id] = k.State
+	}
+	return states
+}
+
+func TestSigningKeys_Rotate(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeSigningKeyStore{}
+	keys := auth.NewSigningKeys(store, time.Hour,
+		auth.WithKeyRotationInterval(24*time.Hour),
+		auth.WithSigningKeyBits(1024),
+	)
+	tm, err := auth.NewJWTRS512TokenManager(keys, time.Hour)
+	require.NoError(t, err)
+
+	// The first key signs right away and the next one is already published.
+	assert.Equal(t, map[uint64]auth.SigningKeyState{
+		1: auth.SigningKeyStateActive,
+		2: auth.SigningKeyStateNext,
+	}, store.states())
+	assert.Len(t, tm.PublicKeys(), 2)
+
+	oldToken, err := tm.Sign(7, "s1")
+	require.NoError(t, err)
+
+	start := *store.keys[0].ActivatedAt
+
+	// Rotating again before the interval changes nothing.
+	require.NoError(t, keys.Rotate(ctx, start.Add(time.Hour)))
+	assert.Len(t, store.keys, 2)
+
+	// After the interval the next key signs and tokens of the old key
+	// still verify.
+	require.NoError(t, keys.Rotate(ctx, start.Add(24*time.Hour)))
+	assert.Equal(t, map[uint64]auth.SigningKeyState{
+		1: auth.SigningKeyStateActive,
+		2: auth.SigningKeyStateActive,
+		3: auth.SigningKeyStateNext,
+	}, store.states())
+
+	newToken, err := tm.Sign(7, "s2")
+	require.NoError(t, err)
+	_, err = tm.Parse(oldToken)
+	require.NoError(t, err)
+
+	// Once tokens of the old key have expired it is retired.
+	require.NoError(t, keys.Rotate(ctx, start.Add(25*time.Hour)))
+	assert.Equal(t, map[uint64]auth.SigningKeyState{
+		1: auth.SigningKeyStateRetired,
+		2: auth.SigningKeyStateActive,
+		3: auth.SigningKeyStateNext,
+	}, store.states())
+	assert.Len(t, tm.PublicKeys(), 2)
+
+	_, err = tm.Parse(oldToken)
+	assert.True(t, apperrors.IsError(err, auth.ErrCodeInvalidToken))
+	claims, err := tm.Parse(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), claims.AccountID)
+}
+
+func TestSigningKeys_ReplicasShareKeys(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeSigningKeyStore{}
+	a := auth.NewSigningKeys(store, time.Hour, auth.WithSigningKeyBits(1024))
+	b := auth.NewSigningKeys(store, time.Hour, auth.WithSigningKeyBits(1024))
+
+	tmA, err := auth.NewJWTRS512TokenManager(a, time.Hour)
+	require.NoError(t, err)
+	tmB, err := auth.NewJWTRS512TokenManager(b, time.Hour)
+	require.NoError(t, err)
+
+	// A restarted replica reuses the stored keys instead of adding its own.
+	assert.Len(t, store.keys, 2)
+
+	token, err := tmA.Sign(7, "s1")
+	require.NoError(t, err)
+	_, err = tmB.Parse(token)
+	require.NoError(t, err)
+	require.NoError(t, b.Rotate(ctx, time.Now()))
+	assert.Len(t, store.keys, 2)
+}
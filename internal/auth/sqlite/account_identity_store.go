@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	auth "github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+func (s *authStore) CreateAccountIdentity(ctx context.Context, identity *auth.AccountIdentity) error {
+	return s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`INSERT INTO account_identities (of_account_id, issuer, subject, created_at) VALUES (?, ?, ?, ?)`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					identity.OfAccountId,
+					identity.Issuer,
+					identity.Subject,
+					identity.CreatedAt.UTC().Format(timestampLayout),
+				},
+			},
+		)
+	})
+}
+
+func (s *authStore) GetAccountIdentity(ctx context.Context, issuer, subject string) (auth.AccountIdentity, error) {
+	var (
+		identity auth.AccountIdentity
+		found    bool
+	)
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT of_account_id, issuer, subject, created_at FROM account_identities WHERE issuer = ? AND subject = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{issuer, subject},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					createdAt, err := time.Parse(timestampLayout, stmt.ColumnText(3))
+					if err != nil {
+						return err
+					}
+					identity = auth.AccountIdentity{
+						OfAccountId: uint64(stmt.ColumnInt64(0)),
+						Issuer:      stmt.ColumnText(1),
+						Subject:     stmt.ColumnText(2),
+						CreatedAt:   createdAt,
+					}
+					found = true
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return auth.AccountIdentity{}, err
+	}
+	if !found {
+		return auth.AccountIdentity{}, errors.ErrNotFound
+	}
+	return identity, nil
+}
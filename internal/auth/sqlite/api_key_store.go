@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	auth "github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// timestampLayout is the format CURRENT_TIMESTAMP writes.
+const timestampLayout = "2006-01-02 15:04:05"
+
+const apiKeyColumns = `id, of_account_id, name, prefix, hashed_secret, scopes, expires_at, created_at, revoked_at`
+
+func (s *authStore) CreateAPIKey(ctx context.Context, key *auth.APIKey) (uint64, error) {
+	var id int64
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`INSERT INTO api_keys (of_account_id, name, prefix, hashed_secret, scopes, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					key.OfAccountId,
+					key.Name,
+					key.Prefix,
+					key.HashedSecret,
+					strings.Join(key.Scopes, " "),
+					formatNullTimestamp(key.ExpiresAt),
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		id = conn.LastInsertRowID()
+		return nil
+	})
+	return uint64(id), err
+}
+
+func (s *authStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (auth.APIKey, error) {
+	var (
+		key   auth.APIKey
+		found bool
+	)
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = ?`, &sqlitex.ExecOptions{
+			Args: []any{prefix},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				var err error
+				key, err = scanAPIKey(stmt)
+				found = true
+				return err
+			},
+		})
+	})
+	if err != nil {
+		return auth.APIKey{}, err
+	}
+	if !found {
+		return auth.APIKey{}, errors.ErrNotFound
+	}
+	return key, nil
+}
+
+func (s *authStore) ListAPIKeys(ctx context.Context, ofAccountID uint64) ([]auth.APIKey, error) {
+	keys := []auth.APIKey{}
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT `+apiKeyColumns+` FROM api_keys WHERE of_account_id = ? ORDER BY id DESC`,
+			&sqlitex.ExecOptions{
+				Args: []any{ofAccountID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					key, err := scanAPIKey(stmt)
+					if err != nil {
+						return err
+					}
+					keys = append(keys, key)
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *authStore) RevokeAPIKey(ctx context.Context, ofAccountID, id uint64, at time.Time) error {
+	var changes int
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`UPDATE api_keys SET revoked_at = ? WHERE id = ? AND of_account_id = ? AND revoked_at IS NULL`,
+			&sqlitex.ExecOptions{
+				Args: []any{at.UTC().Format(timestampLayout), id, ofAccountID},
+			},
+		)
+		changes = conn.Changes()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if changes == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(stmt *sqlite.Stmt) (auth.APIKey, error) {
+	key := auth.APIKey{
+		Id:           uint64(stmt.ColumnInt64(0)),
+		OfAccountId:  uint64(stmt.ColumnInt64(1)),
+		Name:         stmt.ColumnText(2),
+		Prefix:       stmt.ColumnText(3),
+		HashedSecret: stmt.ColumnText(4),
+		Scopes:       strings.Fields(stmt.ColumnText(5)),
+	}
+	var err error
+	if key.ExpiresAt, err = parseNullTimestamp(stmt, 6); err != nil {
+		return auth.APIKey{}, err
+	}
+	if key.CreatedAt, err = time.Parse(timestampLayout, stmt.ColumnText(7)); err != nil {
+		return auth.APIKey{}, err
+	}
+	if key.RevokedAt, err = parseNullTimestamp(stmt, 8); err != nil {
+		return auth.APIKey{}, err
+	}
+	return key, nil
+}
+
+func formatNullTimestamp(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(timestampLayout)
+}
+
+func parseNullTimestamp(stmt *sqlite.Stmt, col int) (*time.Time, error) {
+	if stmt.ColumnType(col) == sqlite.SQLITE_NULL {
+		return nil, nil
+	}
+	t, err := time.Parse(timestampLayout, stmt.ColumnText(col))
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
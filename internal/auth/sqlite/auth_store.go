@@ -18,6 +18,10 @@ type AuthStore struct {
 	AccountStore         auth.AccountStore
 	AccountPasswordStore auth.AccountPasswordStore
 	TxManager            auth.TxManager
+	APIKeyStore          auth.APIKeyStore
+	SigningKeyStore      auth.SigningKeyStore
+	WorkspaceStore       auth.WorkspaceStore
+	AccountIdentityStore auth.AccountIdentityStore
 }
 
 func New(pool *sqlitex.Pool) *AuthStore {
@@ -26,6 +30,10 @@ func New(pool *sqlitex.Pool) *AuthStore {
 		AccountStore:         store,
 		AccountPasswordStore: store,
 		TxManager:            NewTxManager(pool),
+		APIKeyStore:          store,
+		SigningKeyStore:      store,
+		WorkspaceStore:       store,
+		AccountIdentityStore: store,
 	}
 }
 
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	auth "github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+func (s *authStore) CreateSigningKey(ctx context.Context, key *auth.SigningKey) (uint64, error) {
+	var id int64
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		var privateKey any
+		if key.PrivateKey != nil {
+			privateKey = string(key.PrivateKey)
+		}
+		err := sqlitex.Execute(
+			conn,
+			`INSERT INTO token_public_keys (public_key, private_key, state, created_at) VALUES (?, ?, ?, ?)`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					string(key.PublicKey),
+					privateKey,
+					string(key.State),
+					key.CreatedAt.UTC().Format(timestampLayout),
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		id = conn.LastInsertRowID()
+		return nil
+	})
+	return uint64(id), err
+}
+
+func (s *authStore) ListSigningKeys(ctx context.Context) ([]auth.SigningKey, error) {
+	var keys []auth.SigningKey
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT id, public_key, private_key, state, created_at, activated_at, retired_at
+			FROM token_public_keys WHERE state <> 'retired' ORDER BY id`,
+			&sqlitex.ExecOptions{
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					key := auth.SigningKey{
+						Id:        uint64(stmt.ColumnInt64(0)),
+						PublicKey: []byte(stmt.ColumnText(1)),
+						State:     auth.SigningKeyState(stmt.ColumnText(3)),
+					}
+					if stmt.ColumnType(2) != sqlite.SQLITE_NULL {
+						key.PrivateKey = []byte(stmt.ColumnText(2))
+					}
+					var err error
+					if key.CreatedAt, err = time.Parse(timestampLayout, stmt.ColumnText(4)); err != nil {
+						return err
+					}
+					if key.ActivatedAt, err = parseNullTimestamp(stmt, 5); err != nil {
+						return err
+					}
+					if key.RetiredAt, err = parseNullTimestamp(stmt, 6); err != nil {
+						return err
+					}
+					keys = append(keys, key)
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *authStore) UpdateSigningKeyState(
+	ctx context.Context,
+	kid uint64,
+	from, to auth.SigningKeyState,
+	at time.Time,
+) error {
+	var column string
+	switch to {
+	case auth.SigningKeyStateActive:
+		column = "activated_at"
+	case auth.SigningKeyStateRetired:
+		column = "retired_at"
+	default:
+		return fmt.Errorf("cannot move signing key to state %q", to)
+	}
+
+	var changes int
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`UPDATE token_public_keys SET state = ?, `+column+` = ? WHERE id = ? AND state = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{string(to), at.UTC().Format(timestampLayout), kid, string(from)},
+			},
+		)
+		changes = conn.Changes()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if changes == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	auth "github.com/yuisofull/goload/internal/auth"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+const workspaceMemberColumns = `m.workspace_id, m.account_id, a.account_name, m.role, m.created_at`
+
+func (s *authStore) CreateWorkspace(ctx context.Context, workspace *auth.Workspace) (uint64, error) {
+	var id int64
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(conn, `INSERT INTO workspaces (name, created_at) VALUES (?, ?)`, &sqlitex.ExecOptions{
+			Args: []any{workspace.Name, workspace.CreatedAt.UTC().Format(timestampLayout)},
+		})
+		if err != nil {
+			return err
+		}
+		id = conn.LastInsertRowID()
+		return nil
+	})
+	return uint64(id), err
+}
+
+func (s *authStore) ListWorkspaces(ctx context.Context, accountID uint64) ([]auth.WorkspaceMembership, error) {
+	workspaces := []auth.WorkspaceMembership{}
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT w.id, w.name, w.created_at, m.role
+			FROM workspaces w JOIN workspace_members m ON m.workspace_id = w.id
+			WHERE m.account_id = ? ORDER BY w.id`,
+			&sqlitex.ExecOptions{
+				Args: []any{accountID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					createdAt, err := time.Parse(timestampLayout, stmt.ColumnText(2))
+					if err != nil {
+						return err
+					}
+					workspaces = append(workspaces, auth.WorkspaceMembership{
+						Workspace: auth.Workspace{
+							Id:        uint64(stmt.ColumnInt64(0)),
+							Name:      stmt.ColumnText(1),
+							CreatedAt: createdAt,
+						},
+						Role: auth.WorkspaceRole(stmt.ColumnText(3)),
+					})
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (s *authStore) GetWorkspaceMember(ctx context.Context, workspaceID, accountID uint64) (auth.WorkspaceMember, error) {
+	var (
+		member auth.WorkspaceMember
+		found  bool
+	)
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT `+workspaceMemberColumns+`
+			FROM workspace_members m JOIN accounts a ON a.id = m.account_id
+			WHERE m.workspace_id = ? AND m.account_id = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{workspaceID, accountID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					var err error
+					member, err = scanWorkspaceMember(stmt)
+					found = true
+					return err
+				},
+			},
+		)
+	})
+	if err != nil {
+		return auth.WorkspaceMember{}, err
+	}
+	if !found {
+		return auth.WorkspaceMember{}, errors.ErrNotFound
+	}
+	return member, nil
+}
+
+func (s *authStore) ListWorkspaceMembers(ctx context.Context, workspaceID uint64) ([]auth.WorkspaceMember, error) {
+	members := []auth.WorkspaceMember{}
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT `+workspaceMemberColumns+`
+			FROM workspace_members m JOIN accounts a ON a.id = m.account_id
+			WHERE m.workspace_id = ? ORDER BY m.created_at, m.account_id`,
+			&sqlitex.ExecOptions{
+				Args: []any{workspaceID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					member, err := scanWorkspaceMember(stmt)
+					if err != nil {
+						return err
+					}
+					members = append(members, member)
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (s *authStore) SetWorkspaceMember(ctx context.Context, member *auth.WorkspaceMember) error {
+	return s.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`INSERT INTO workspace_members (workspace_id, account_id, role, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (workspace_id, account_id) DO UPDATE SET role = excluded.role`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					member.WorkspaceId,
+					member.AccountId,
+					string(member.Role),
+					member.CreatedAt.UTC().Format(timestampLayout),
+				},
+			},
+		)
+	})
+}
+
+func (s *authStore) RemoveWorkspaceMember(ctx context.Context, workspaceID, accountID uint64) error {
+	var changes int
+	err := s.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`DELETE FROM workspace_members WHERE workspace_id = ? AND account_id = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{workspaceID, accountID},
+			},
+		)
+		changes = conn.Changes()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if changes == 0 {
+		return errors.ErrNotFound
+	}
+	return nil
+}
+
+func scanWorkspaceMember(stmt *sqlite.Stmt) (auth.WorkspaceMember, error) {
+	createdAt, err := time.Parse(timestampLayout, stmt.ColumnText(4))
+	if err != nil {
+		return auth.WorkspaceMember{}, err
+	}
+	return auth.WorkspaceMember{
+		WorkspaceId: uint64(stmt.ColumnInt64(0)),
+		AccountId:   uint64(stmt.ColumnInt64(1)),
+		AccountName: stmt.ColumnText(2),
+		Role:        auth.WorkspaceRole(stmt.ColumnText(3)),
+		CreatedAt:   createdAt,
+	}, nil
+}
@@ -2,72 +2,82 @@ package auth
 
 import (
 	"context"
-	"crypto/rsa"
 	errstderrors "errors"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/yuisofull/goload/internal/errors"
-	pkgrsa "github.com/yuisofull/goload/pkg/crypto/rsa"
 )
 
-type TokenPublicKeyStore interface {
-	CreateTokenPublicKey(ctx context.Context, tokenPublicKey *TokenPublicKey) (kid uint64, err error)
-	GetTokenPublicKey(ctx context.Context, kid uint64) (TokenPublicKey, error)
+// TokenClaims are the verified claims of an access token.
+type TokenClaims struct {
+	// ID is the unique token id (jti) used to revoke a single token.
+	ID        string
+	SessionID string
+	AccountID uint64
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
 type TokenManager interface {
-	Sign(accountID uint64) (string, error)
-	GetAccountIDFrom(token string) (uint64, error)
-	GetExpiryFrom(token string) (time.Time, error)
+	// Sign issues an access token of the account within the session.
+	Sign(accountID uint64, sessionID string) (string, error)
+	// Parse verifies the token and returns its claims.
+	Parse(token string) (TokenClaims, error)
+	// ExpiresIn is the lifetime of the tokens issued by Sign.
+	ExpiresIn() time.Duration
+	// PublicKeys returns the keys that verify tokens, for publication as a
+	// JWKS. Token managers without public keys return none.
+	PublicKeys() []TokenPublicKey
 }
 
 type jwtRS256TokenManager struct {
-	privateKey *rsa.PrivateKey
-	kid        uint64
-	expiresIn  time.Duration
-	store      TokenPublicKeyStore
+	keys      *SigningKeys
+	expiresIn time.Duration
 }
 
-func NewJWTRS512TokenManager(
-	privateKey *rsa.PrivateKey,
-	expiresIn time.Duration,
-	store TokenPublicKeyStore,
-) (TokenManager, error) {
-	publicKey := &privateKey.PublicKey
-	pemBytes, err := pkgrsa.SerializePublicKey(publicKey)
-	if err != nil {
+// NewJWTRS512TokenManager returns a token manager signing with the active
+// key of keys. It rotates keys once, so that there is a key to sign with.
+func NewJWTRS512TokenManager(keys *SigningKeys, expiresIn time.Duration) (TokenManager, error) {
+	if err := keys.Rotate(context.Background(), time.Now()); err != nil {
 		return nil, err
 	}
-
-	kid, err := store.CreateTokenPublicKey(context.Background(), &TokenPublicKey{
-		PublicKey: pemBytes,
-	})
-	if err != nil {
-		return nil, err
-	}
-
 	return &jwtRS256TokenManager{
-		kid:        kid,
-		privateKey: privateKey,
-		expiresIn:  expiresIn,
-		store:      store,
+		keys:      keys,
+		expiresIn: expiresIn,
 	}, nil
 }
 
-func (t *jwtRS256TokenManager) Sign(accountID uint64) (string, error) {
+func (t *jwtRS256TokenManager) Sign(accountID uint64, sessionID string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate token id", Cause: err}
+	}
+	kid, privateKey, err := t.keys.signingKey()
+	if err != nil {
+		return "", &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get signing key", Cause: err}
+	}
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodRS512, jwt.MapClaims{
 		"sub":        accountID,
 		"account_id": accountID,
-		"exp":        time.Now().Add(t.expiresIn).Unix(),
-		"iat":        time.Now().Unix(),
-		"jti":        time.Now().Unix(),
-		"kid":        t.kid,
-		"iss":        "authservice",
+		"exp":        now.Add(t.expiresIn).Unix(),
+		// Milliseconds, so that tokens issued right after revoking all
+		// sessions of an account are told apart from the revoked ones.
+		"iat": float64(now.UnixMilli()) / 1000,
+		"jti": jti,
+		"sid": sessionID,
+		"kid": kid,
+		"iss": "authservice",
 	})
+	// Standard verifiers find the key through the header; the claim is
+	// kept for tokens checked by older replicas.
+	token.Header["kid"] = strconv.FormatUint(kid, 10)
 
-	tokenStr, err := token.SignedString(t.privateKey)
+	tokenStr, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", &errors.Error{
 			Code:    errors.ErrCodeInternal,
@@ -78,35 +88,48 @@ func (t *jwtRS256TokenManager) Sign(accountID uint64) (string, error) {
 	return tokenStr, nil
 }
 
+func (t *jwtRS256TokenManager) ExpiresIn() time.Duration {
+	return t.expiresIn
+}
+
+func (t *jwtRS256TokenManager) PublicKeys() []TokenPublicKey {
+	return t.keys.PublicKeys()
+}
+
 func (t *jwtRS256TokenManager) parseToken(tokenStr string) (*jwt.Token, error) {
 	return jwt.Parse(tokenStr, func(token *jwt.Token) (any, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok || token.Method.Alg() != jwt.SigningMethodRS512.Alg() {
 			return nil, errstderrors.New("unexpected signing method")
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return nil, errstderrors.New("cannot get token's claims")
-		}
-		kid, ok := claims["kid"].(float64)
-		if !ok {
-			return nil, errstderrors.New("cannot get token's kid")
-		}
-		tokenPublicKey, err := t.store.GetTokenPublicKey(context.Background(), uint64(kid))
+		kid, err := tokenKid(token)
 		if err != nil {
 			return nil, err
 		}
-		publicKey, err := pkgrsa.DeserializePublicKey(tokenPublicKey.PublicKey)
-		if err != nil {
-			return nil, err
-		}
-		return publicKey, nil
+		return t.keys.publicKey(context.Background(), kid)
 	})
 }
 
-func (t *jwtRS256TokenManager) GetAccountIDFrom(tokenStr string) (uint64, error) {
+// tokenKid returns the kid of the token header, or of the claims for tokens
+// issued before the header carried it.
+func tokenKid(token *jwt.Token) (uint64, error) {
+	if kid, ok := token.Header["kid"].(string); ok {
+		return strconv.ParseUint(kid, 10, 64)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, errstderrors.New("cannot get token's claims")
+	}
+	kid, ok := claims["kid"].(float64)
+	if !ok {
+		return 0, errstderrors.New("cannot get token's kid")
+	}
+	return uint64(kid), nil
+}
+
+func (t *jwtRS256TokenManager) Parse(tokenStr string) (TokenClaims, error) {
 	parsedToken, err := t.parseToken(tokenStr)
 	if err != nil {
-		return 0, &errors.Error{
+		return TokenClaims{}, &errors.Error{
 			Code:    ErrCodeInvalidToken,
 			Message: "cannot parse token",
 			Cause:   err,
@@ -114,65 +137,46 @@ func (t *jwtRS256TokenManager) GetAccountIDFrom(tokenStr string) (uint64, error)
 	}
 
 	if !parsedToken.Valid {
-		return 0, &errors.Error{
+		return TokenClaims{}, &errors.Error{
 			Code:    ErrCodeInvalidToken,
 			Message: "invalid token",
-			Cause:   err,
 		}
 	}
 
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, &errors.Error{
+		return TokenClaims{}, &errors.Error{
 			Code:    ErrCodeInvalidToken,
 			Message: "cannot get token's claims",
-			Cause:   err,
 		}
 	}
 
 	accountID, ok := claims["account_id"].(float64)
 	if !ok {
-		return 0, &errors.Error{
+		return TokenClaims{}, &errors.Error{
 			Code:    ErrCodeInvalidToken,
 			Message: "cannot get token's account id",
-			Cause:   err,
-		}
-	}
-
-	return uint64(accountID), nil
-}
-
-func (t *jwtRS256TokenManager) GetExpiryFrom(token string) (time.Time, error) {
-	parsedToken, err := t.parseToken(token)
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	if !parsedToken.Valid {
-		return time.Time{}, &errors.Error{
-			Code:    ErrCodeInvalidToken,
-			Message: "invalid token",
-			Cause:   err,
-		}
-	}
-
-	claims, ok := parsedToken.Claims.(jwt.MapClaims)
-	if !ok {
-		return time.Time{}, &errors.Error{
-			Code:    ErrCodeInvalidToken,
-			Message: "cannot get token's claims",
-			Cause:   err,
 		}
 	}
 
 	exp, ok := claims["exp"].(float64)
 	if !ok {
-		return time.Time{}, &errors.Error{
+		return TokenClaims{}, &errors.Error{
 			Code:    ErrCodeInvalidToken,
 			Message: "cannot get token's expiry",
-			Cause:   err,
 		}
 	}
 
-	return time.Unix(int64(exp), 0), nil
+	out := TokenClaims{
+		AccountID: uint64(accountID),
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}
+	// Tokens issued before sessions could be revoked carry a numeric jti
+	// and no sid; they stay valid until they expire.
+	out.ID, _ = claims["jti"].(string)
+	out.SessionID, _ = claims["sid"].(string)
+	if iat, ok := claims["iat"].(float64); ok {
+		out.IssuedAt = time.UnixMilli(int64(math.Round(iat * 1000)))
+	}
+	return out, nil
 }
@@ -1,6 +1,31 @@
 package auth
 
+import (
+	"context"
+	"time"
+)
+
+// SigningKeyState is the stage of a token signing key in its rotation.
+type SigningKeyState string
+
+const (
+	// SigningKeyStateNext keys are published but sign nothing yet, so that
+	// verifiers know them before the first token signed with them arrives.
+	SigningKeyStateNext SigningKeyState = "next"
+	// SigningKeyStateActive keys verify tokens; the newest one signs them.
+	SigningKeyStateActive SigningKeyState = "active"
+	// SigningKeyStateRetired keys are no longer accepted.
+	SigningKeyStateRetired SigningKeyState = "retired"
+)
+
+// TokenPublicKey is the published half of a signing key.
 type TokenPublicKey struct {
-	Id        uint64 `sql:"id"`
-	PublicKey []byte `sql:"public_key"`
+	Id          uint64
+	PublicKey   []byte
+	State       SigningKeyState
+	ActivatedAt *time.Time
+}
+
+func (s *service) ListPublicKeys(context.Context) ([]TokenPublicKey, error) {
+	return s.tokenManager.PublicKeys(), nil
 }
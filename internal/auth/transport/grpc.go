@@ -21,9 +21,26 @@ import (
 type grpcServer struct {
 	pb.UnimplementedAuthServiceServer
 
-	createAccount grpctransport.Handler
-	createSession grpctransport.Handler
-	verifySession grpctransport.Handler
+	createAccount  grpctransport.Handler
+	createSession  grpctransport.Handler
+	verifySession  grpctransport.Handler
+	refreshSession grpctransport.Handler
+	logout         grpctransport.Handler
+	revokeSession  grpctransport.Handler
+	createAPIKey   grpctransport.Handler
+	listAPIKeys    grpctransport.Handler
+	revokeAPIKey   grpctransport.Handler
+	listPublicKeys grpctransport.Handler
+
+	createWorkspace       grpctransport.Handler
+	listWorkspaces        grpctransport.Handler
+	getWorkspaceRole      grpctransport.Handler
+	listWorkspaceMembers  grpctransport.Handler
+	setWorkspaceMember    grpctransport.Handler
+	removeWorkspaceMember grpctransport.Handler
+
+	beginOIDCLogin    grpctransport.Handler
+	completeOIDCLogin grpctransport.Handler
 }
 
 // CreateAccount implements the gRPC CreateAccount method
@@ -62,6 +79,186 @@ func (s *grpcServer) VerifySession(
 	return resp.(*pb.VerifySessionResponse), nil
 }
 
+// RefreshSession implements the gRPC RefreshSession method
+func (s *grpcServer) RefreshSession(
+	ctx context.Context,
+	req *pb.RefreshSessionRequest,
+) (*pb.RefreshSessionResponse, error) {
+	_, resp, err := s.refreshSession.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.RefreshSessionResponse), nil
+}
+
+// Logout implements the gRPC Logout method
+func (s *grpcServer) Logout(
+	ctx context.Context,
+	req *pb.LogoutRequest,
+) (*pb.LogoutResponse, error) {
+	_, resp, err := s.logout.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.LogoutResponse), nil
+}
+
+// RevokeSession implements the gRPC RevokeSession method
+func (s *grpcServer) RevokeSession(
+	ctx context.Context,
+	req *pb.RevokeSessionRequest,
+) (*pb.RevokeSessionResponse, error) {
+	_, resp, err := s.revokeSession.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.RevokeSessionResponse), nil
+}
+
+// CreateAPIKey implements the gRPC CreateAPIKey method
+func (s *grpcServer) CreateAPIKey(
+	ctx context.Context,
+	req *pb.CreateAPIKeyRequest,
+) (*pb.CreateAPIKeyResponse, error) {
+	_, resp, err := s.createAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.CreateAPIKeyResponse), nil
+}
+
+// ListAPIKeys implements the gRPC ListAPIKeys method
+func (s *grpcServer) ListAPIKeys(
+	ctx context.Context,
+	req *pb.ListAPIKeysRequest,
+) (*pb.ListAPIKeysResponse, error) {
+	_, resp, err := s.listAPIKeys.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListAPIKeysResponse), nil
+}
+
+// RevokeAPIKey implements the gRPC RevokeAPIKey method
+func (s *grpcServer) RevokeAPIKey(
+	ctx context.Context,
+	req *pb.RevokeAPIKeyRequest,
+) (*pb.RevokeAPIKeyResponse, error) {
+	_, resp, err := s.revokeAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.RevokeAPIKeyResponse), nil
+}
+
+// ListPublicKeys implements the gRPC ListPublicKeys method
+func (s *grpcServer) ListPublicKeys(
+	ctx context.Context,
+	req *pb.ListPublicKeysRequest,
+) (*pb.ListPublicKeysResponse, error) {
+	_, resp, err := s.listPublicKeys.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListPublicKeysResponse), nil
+}
+
+// CreateWorkspace implements the gRPC CreateWorkspace method
+func (s *grpcServer) CreateWorkspace(
+	ctx context.Context,
+	req *pb.CreateWorkspaceRequest,
+) (*pb.CreateWorkspaceResponse, error) {
+	_, resp, err := s.createWorkspace.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.CreateWorkspaceResponse), nil
+}
+
+// ListWorkspaces implements the gRPC ListWorkspaces method
+func (s *grpcServer) ListWorkspaces(
+	ctx context.Context,
+	req *pb.ListWorkspacesRequest,
+) (*pb.ListWorkspacesResponse, error) {
+	_, resp, err := s.listWorkspaces.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListWorkspacesResponse), nil
+}
+
+// GetWorkspaceRole implements the gRPC GetWorkspaceRole method
+func (s *grpcServer) GetWorkspaceRole(
+	ctx context.Context,
+	req *pb.GetWorkspaceRoleRequest,
+) (*pb.GetWorkspaceRoleResponse, error) {
+	_, resp, err := s.getWorkspaceRole.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.GetWorkspaceRoleResponse), nil
+}
+
+// ListWorkspaceMembers implements the gRPC ListWorkspaceMembers method
+func (s *grpcServer) ListWorkspaceMembers(
+	ctx context.Context,
+	req *pb.ListWorkspaceMembersRequest,
+) (*pb.ListWorkspaceMembersResponse, error) {
+	_, resp, err := s.listWorkspaceMembers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListWorkspaceMembersResponse), nil
+}
+
+// SetWorkspaceMember implements the gRPC SetWorkspaceMember method
+func (s *grpcServer) SetWorkspaceMember(
+	ctx context.Context,
+	req *pb.SetWorkspaceMemberRequest,
+) (*pb.SetWorkspaceMemberResponse, error) {
+	_, resp, err := s.setWorkspaceMember.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.SetWorkspaceMemberResponse), nil
+}
+
+// RemoveWorkspaceMember implements the gRPC RemoveWorkspaceMember method
+func (s *grpcServer) RemoveWorkspaceMember(
+	ctx context.Context,
+	req *pb.RemoveWorkspaceMemberRequest,
+) (*pb.RemoveWorkspaceMemberResponse, error) {
+	_, resp, err := s.removeWorkspaceMember.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.RemoveWorkspaceMemberResponse), nil
+}
+
+// BeginOIDCLogin implements the gRPC BeginOIDCLogin method
+func (s *grpcServer) BeginOIDCLogin(
+	ctx context.Context,
+	req *pb.BeginOIDCLoginRequest,
+) (*pb.BeginOIDCLoginResponse, error) {
+	_, resp, err := s.beginOIDCLogin.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.BeginOIDCLoginResponse), nil
+}
+
+// CompleteOIDCLogin implements the gRPC CompleteOIDCLogin method
+func (s *grpcServer) CompleteOIDCLogin(
+	ctx context.Context,
+	req *pb.CompleteOIDCLoginRequest,
+) (*pb.CompleteOIDCLoginResponse, error) {
+	_, resp, err := s.completeOIDCLogin.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.CompleteOIDCLoginResponse), nil
+}
+
 func encodeError(_ context.Context, err error) error {
 	var svcErr *internalerrors.Error
 	if errors.As(err, &svcErr) {
@@ -103,6 +300,96 @@ func NewGRPCServer(endpoints authendpoint.Set, logger log.Logger) pb.AuthService
 			encodeVerifySessionResponse,
 			options...,
 		),
+		refreshSession: grpctransport.NewServer(
+			endpoints.RefreshSessionEndpoint,
+			decodeRefreshSessionRequest,
+			encodeRefreshSessionResponse,
+			options...,
+		),
+		logout: grpctransport.NewServer(
+			endpoints.LogoutEndpoint,
+			decodeLogoutRequest,
+			encodeLogoutResponse,
+			options...,
+		),
+		revokeSession: grpctransport.NewServer(
+			endpoints.RevokeSessionEndpoint,
+			decodeRevokeSessionRequest,
+			encodeRevokeSessionResponse,
+			options...,
+		),
+		createAPIKey: grpctransport.NewServer(
+			endpoints.CreateAPIKeyEndpoint,
+			decodeCreateAPIKeyRequest,
+			encodeCreateAPIKeyResponse,
+			options...,
+		),
+		listAPIKeys: grpctransport.NewServer(
+			endpoints.ListAPIKeysEndpoint,
+			decodeListAPIKeysRequest,
+			encodeListAPIKeysResponse,
+			options...,
+		),
+		revokeAPIKey: grpctransport.NewServer(
+			endpoints.RevokeAPIKeyEndpoint,
+			decodeRevokeAPIKeyRequest,
+			encodeRevokeAPIKeyResponse,
+			options...,
+		),
+		listPublicKeys: grpctransport.NewServer(
+			endpoints.ListPublicKeysEndpoint,
+			decodeListPublicKeysRequest,
+			encodeListPublicKeysResponse,
+			options...,
+		),
+		createWorkspace: grpctransport.NewServer(
+			endpoints.CreateWorkspaceEndpoint,
+			decodeCreateWorkspaceRequest,
+			encodeCreateWorkspaceResponse,
+			options...,
+		),
+		listWorkspaces: grpctransport.NewServer(
+			endpoints.ListWorkspacesEndpoint,
+			decodeListWorkspacesRequest,
+			encodeListWorkspacesResponse,
+			options...,
+		),
+		getWorkspaceRole: grpctransport.NewServer(
+			endpoints.GetWorkspaceRoleEndpoint,
+			decodeGetWorkspaceRoleRequest,
+			encodeGetWorkspaceRoleResponse,
+			options...,
+		),
+		listWorkspaceMembers: grpctransport.NewServer(
+			endpoints.ListWorkspaceMembersEndpoint,
+			decodeListWorkspaceMembersRequest,
+			encodeListWorkspaceMembersResponse,
+			options...,
+		),
+		setWorkspaceMember: grpctransport.NewServer(
+			endpoints.SetWorkspaceMemberEndpoint,
+			decodeSetWorkspaceMemberRequest,
+			encodeSetWorkspaceMemberResponse,
+			options...,
+		),
+		removeWorkspaceMember: grpctransport.NewServer(
+			endpoints.RemoveWorkspaceMemberEndpoint,
+			decodeRemoveWorkspaceMemberRequest,
+			encodeRemoveWorkspaceMemberResponse,
+			options...,
+		),
+		beginOIDCLogin: grpctransport.NewServer(
+			endpoints.BeginOIDCLoginEndpoint,
+			decodeBeginOIDCLoginRequest,
+			encodeBeginOIDCLoginResponse,
+			options...,
+		),
+		completeOIDCLogin: grpctransport.NewServer(
+			endpoints.CompleteOIDCLoginEndpoint,
+			decodeCompleteOIDCLoginRequest,
+			encodeCompleteOIDCLoginResponse,
+			options...,
+		),
 	}
 }
 
@@ -139,6 +426,141 @@ func NewGRPCClient(conn *grpc.ClientConn, logger log.Logger) auth.Service {
 			pb.VerifySessionResponse{},
 			options...,
 		).Endpoint(),
+		RefreshSessionEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"RefreshSession",
+			encodeRefreshSessionRequest,
+			decodeRefreshSessionResponse,
+			pb.RefreshSessionResponse{},
+			options...,
+		).Endpoint(),
+		LogoutEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"Logout",
+			encodeLogoutRequest,
+			decodeLogoutResponse,
+			pb.LogoutResponse{},
+			options...,
+		).Endpoint(),
+		RevokeSessionEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"RevokeSession",
+			encodeRevokeSessionRequest,
+			decodeRevokeSessionResponse,
+			pb.RevokeSessionResponse{},
+			options...,
+		).Endpoint(),
+		CreateAPIKeyEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"CreateAPIKey",
+			encodeCreateAPIKeyRequest,
+			decodeCreateAPIKeyResponse,
+			pb.CreateAPIKeyResponse{},
+			options...,
+		).Endpoint(),
+		ListAPIKeysEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"ListAPIKeys",
+			encodeListAPIKeysRequest,
+			decodeListAPIKeysResponse,
+			pb.ListAPIKeysResponse{},
+			options...,
+		).Endpoint(),
+		RevokeAPIKeyEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"RevokeAPIKey",
+			encodeRevokeAPIKeyRequest,
+			decodeRevokeAPIKeyResponse,
+			pb.RevokeAPIKeyResponse{},
+			options...,
+		).Endpoint(),
+		ListPublicKeysEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"ListPublicKeys",
+			encodeListPublicKeysRequest,
+			decodeListPublicKeysResponse,
+			pb.ListPublicKeysResponse{},
+			options...,
+		).Endpoint(),
+		CreateWorkspaceEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"CreateWorkspace",
+			encodeCreateWorkspaceRequest,
+			decodeCreateWorkspaceResponse,
+			pb.CreateWorkspaceResponse{},
+			options...,
+		).Endpoint(),
+		ListWorkspacesEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"ListWorkspaces",
+			encodeListWorkspacesRequest,
+			decodeListWorkspacesResponse,
+			pb.ListWorkspacesResponse{},
+			options...,
+		).Endpoint(),
+		GetWorkspaceRoleEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"GetWorkspaceRole",
+			encodeGetWorkspaceRoleRequest,
+			decodeGetWorkspaceRoleResponse,
+			pb.GetWorkspaceRoleResponse{},
+			options...,
+		).Endpoint(),
+		ListWorkspaceMembersEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"ListWorkspaceMembers",
+			encodeListWorkspaceMembersRequest,
+			decodeListWorkspaceMembersResponse,
+			pb.ListWorkspaceMembersResponse{},
+			options...,
+		).Endpoint(),
+		SetWorkspaceMemberEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"SetWorkspaceMember",
+			encodeSetWorkspaceMemberRequest,
+			decodeSetWorkspaceMemberResponse,
+			pb.SetWorkspaceMemberResponse{},
+			options...,
+		).Endpoint(),
+		RemoveWorkspaceMemberEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"RemoveWorkspaceMember",
+			encodeRemoveWorkspaceMemberRequest,
+			decodeRemoveWorkspaceMemberResponse,
+			pb.RemoveWorkspaceMemberResponse{},
+			options...,
+		).Endpoint(),
+		BeginOIDCLoginEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"BeginOIDCLogin",
+			encodeBeginOIDCLoginRequest,
+			decodeBeginOIDCLoginResponse,
+			pb.BeginOIDCLoginResponse{},
+			options...,
+		).Endpoint(),
+		CompleteOIDCLoginEndpoint: grpctransport.NewClient(
+			conn,
+			"auth.v1.AuthService",
+			"CompleteOIDCLogin",
+			encodeCompleteOIDCLoginRequest,
+			decodeCompleteOIDCLoginResponse,
+			pb.CompleteOIDCLoginResponse{},
+			options...,
+		).Endpoint(),
 	}
 }
 
@@ -170,6 +592,134 @@ func decodeVerifySessionRequest(_ context.Context, grpcReq any) (any, error) {
 	}, nil
 }
 
+// decodeRefreshSessionRequest converts protobuf RefreshSessionRequest to endpoint RefreshSessionRequest
+func decodeRefreshSessionRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.RefreshSessionRequest)
+	return &authendpoint.RefreshSessionRequest{
+		RefreshToken: req.GetRefreshToken(),
+	}, nil
+}
+
+// decodeLogoutRequest converts protobuf LogoutRequest to endpoint LogoutRequest
+func decodeLogoutRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.LogoutRequest)
+	return &authendpoint.LogoutRequest{
+		Token: req.GetToken(),
+	}, nil
+}
+
+// decodeRevokeSessionRequest converts protobuf RevokeSessionRequest to endpoint RevokeSessionRequest
+func decodeRevokeSessionRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.RevokeSessionRequest)
+	return &authendpoint.RevokeSessionRequest{
+		AccountId: req.GetAccountId(),
+		SessionId: req.GetSessionId(),
+	}, nil
+}
+
+// decodeCreateAPIKeyRequest converts protobuf CreateAPIKeyRequest to endpoint CreateAPIKeyRequest
+func decodeCreateAPIKeyRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.CreateAPIKeyRequest)
+	return &authendpoint.CreateAPIKeyRequest{
+		AccountId: req.GetAccountId(),
+		Name:      req.GetName(),
+		Scopes:    req.GetScopes(),
+		ExpiresAt: req.GetExpiresAt(),
+	}, nil
+}
+
+// decodeListAPIKeysRequest converts protobuf ListAPIKeysRequest to endpoint ListAPIKeysRequest
+func decodeListAPIKeysRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListAPIKeysRequest)
+	return &authendpoint.ListAPIKeysRequest{
+		AccountId: req.GetAccountId(),
+	}, nil
+}
+
+// decodeRevokeAPIKeyRequest converts protobuf RevokeAPIKeyRequest to endpoint RevokeAPIKeyRequest
+func decodeRevokeAPIKeyRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.RevokeAPIKeyRequest)
+	return &authendpoint.RevokeAPIKeyRequest{
+		AccountId: req.GetAccountId(),
+		Id:        req.GetId(),
+	}, nil
+}
+
+// decodeListPublicKeysRequest converts protobuf ListPublicKeysRequest to endpoint ListPublicKeysRequest
+func decodeListPublicKeysRequest(_ context.Context, _ any) (any, error) {
+	return &authendpoint.ListPublicKeysRequest{}, nil
+}
+
+// decodeCreateWorkspaceRequest converts protobuf CreateWorkspaceRequest to endpoint CreateWorkspaceRequest
+func decodeCreateWorkspaceRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.CreateWorkspaceRequest)
+	return &authendpoint.CreateWorkspaceRequest{
+		AccountId: req.GetAccountId(),
+		Name:      req.GetName(),
+	}, nil
+}
+
+// decodeListWorkspacesRequest converts protobuf ListWorkspacesRequest to endpoint ListWorkspacesRequest
+func decodeListWorkspacesRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListWorkspacesRequest)
+	return &authendpoint.ListWorkspacesRequest{
+		AccountId: req.GetAccountId(),
+	}, nil
+}
+
+// decodeGetWorkspaceRoleRequest converts protobuf GetWorkspaceRoleRequest to endpoint GetWorkspaceRoleRequest
+func decodeGetWorkspaceRoleRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.GetWorkspaceRoleRequest)
+	return &authendpoint.GetWorkspaceRoleRequest{
+		AccountId:   req.GetAccountId(),
+		WorkspaceId: req.GetWorkspaceId(),
+	}, nil
+}
+
+// decodeListWorkspaceMembersRequest converts protobuf ListWorkspaceMembersRequest to endpoint ListWorkspaceMembersRequest
+func decodeListWorkspaceMembersRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListWorkspaceMembersRequest)
+	return &authendpoint.ListWorkspaceMembersRequest{
+		AccountId:   req.GetAccountId(),
+		WorkspaceId: req.GetWorkspaceId(),
+	}, nil
+}
+
+// decodeSetWorkspaceMemberRequest converts protobuf SetWorkspaceMemberRequest to endpoint SetWorkspaceMemberRequest
+func decodeSetWorkspaceMemberRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.SetWorkspaceMemberRequest)
+	return &authendpoint.SetWorkspaceMemberRequest{
+		AccountId:   req.GetAccountId(),
+		WorkspaceId: req.GetWorkspaceId(),
+		MemberName:  req.GetMemberName(),
+		Role:        req.GetRole(),
+	}, nil
+}
+
+// decodeRemoveWorkspaceMemberRequest converts protobuf RemoveWorkspaceMemberRequest to endpoint RemoveWorkspaceMemberRequest
+func decodeRemoveWorkspaceMemberRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.RemoveWorkspaceMemberRequest)
+	return &authendpoint.RemoveWorkspaceMemberRequest{
+		AccountId:   req.GetAccountId(),
+		WorkspaceId: req.GetWorkspaceId(),
+		MemberId:    req.GetMemberId(),
+	}, nil
+}
+
+// decodeBeginOIDCLoginRequest converts protobuf BeginOIDCLoginRequest to endpoint BeginOIDCLoginRequest
+func decodeBeginOIDCLoginRequest(_ context.Context, _ any) (any, error) {
+	return &authendpoint.BeginOIDCLoginRequest{}, nil
+}
+
+// decodeCompleteOIDCLoginRequest converts protobuf CompleteOIDCLoginRequest to endpoint CompleteOIDCLoginRequest
+func decodeCompleteOIDCLoginRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.CompleteOIDCLoginRequest)
+	return &authendpoint.CompleteOIDCLoginRequest{
+		State: req.GetState(),
+		Code:  req.GetCode(),
+	}, nil
+}
+
 // Server-side encode functions (endpoint types -> protobuf)
 
 // encodeCreateAccountResponse converts endpoint CreateAccountResponse to protobuf CreateAccountResponse
@@ -191,8 +741,10 @@ func encodeCreateSessionResponse(_ context.Context, response any) (any, error) {
 		}
 	}
 	return &pb.CreateSessionResponse{
-		Token:   resp.Token,
-		Account: pbAcct,
+		Token:        resp.Token,
+		Account:      pbAcct,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
 	}, nil
 }
 
@@ -201,6 +753,139 @@ func encodeVerifySessionResponse(_ context.Context, response any) (any, error) {
 	resp := response.(*authendpoint.VerifyTokenResponse)
 	return &pb.VerifySessionResponse{
 		AccountId: resp.AccountId,
+		SessionId: resp.SessionId,
+		ApiKeyId:  resp.ApiKeyId,
+		Scopes:    resp.Scopes,
+	}, nil
+}
+
+// encodeRefreshSessionResponse converts endpoint RefreshSessionResponse to protobuf RefreshSessionResponse
+func encodeRefreshSessionResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.RefreshSessionResponse)
+	var pbAcct *pb.Account
+	if resp.Account != nil {
+		pbAcct = &pb.Account{
+			Id:          resp.Account.GetId(),
+			AccountName: resp.Account.GetAccountName(),
+		}
+	}
+	return &pb.RefreshSessionResponse{
+		Token:        resp.Token,
+		Account:      pbAcct,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
+	}, nil
+}
+
+// encodeLogoutResponse converts endpoint LogoutResponse to protobuf LogoutResponse
+func encodeLogoutResponse(_ context.Context, _ any) (any, error) {
+	return &pb.LogoutResponse{}, nil
+}
+
+// encodeRevokeSessionResponse converts endpoint RevokeSessionResponse to protobuf RevokeSessionResponse
+func encodeRevokeSessionResponse(_ context.Context, _ any) (any, error) {
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+// encodeCreateAPIKeyResponse converts endpoint CreateAPIKeyResponse to protobuf CreateAPIKeyResponse
+func encodeCreateAPIKeyResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.CreateAPIKeyResponse)
+	return &pb.CreateAPIKeyResponse{
+		ApiKey: resp.ApiKey,
+		Key:    resp.Key,
+	}, nil
+}
+
+// encodeListAPIKeysResponse converts endpoint ListAPIKeysResponse to protobuf ListAPIKeysResponse
+func encodeListAPIKeysResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.ListAPIKeysResponse)
+	return &pb.ListAPIKeysResponse{
+		ApiKeys: resp.ApiKeys,
+	}, nil
+}
+
+// encodeRevokeAPIKeyResponse converts endpoint RevokeAPIKeyResponse to protobuf RevokeAPIKeyResponse
+func encodeRevokeAPIKeyResponse(_ context.Context, _ any) (any, error) {
+	return &pb.RevokeAPIKeyResponse{}, nil
+}
+
+// encodeListPublicKeysResponse converts endpoint ListPublicKeysResponse to protobuf ListPublicKeysResponse
+func encodeListPublicKeysResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.ListPublicKeysResponse)
+	return &pb.ListPublicKeysResponse{
+		Keys: resp.Keys,
+	}, nil
+}
+
+// encodeCreateWorkspaceResponse converts endpoint CreateWorkspaceResponse to protobuf CreateWorkspaceResponse
+func encodeCreateWorkspaceResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.CreateWorkspaceResponse)
+	return &pb.CreateWorkspaceResponse{
+		Workspace: resp.Workspace,
+	}, nil
+}
+
+// encodeListWorkspacesResponse converts endpoint ListWorkspacesResponse to protobuf ListWorkspacesResponse
+func encodeListWorkspacesResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.ListWorkspacesResponse)
+	return &pb.ListWorkspacesResponse{
+		Workspaces: resp.Workspaces,
+	}, nil
+}
+
+// encodeGetWorkspaceRoleResponse converts endpoint GetWorkspaceRoleResponse to protobuf GetWorkspaceRoleResponse
+func encodeGetWorkspaceRoleResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.GetWorkspaceRoleResponse)
+	return &pb.GetWorkspaceRoleResponse{
+		Role: resp.Role,
+	}, nil
+}
+
+// encodeListWorkspaceMembersResponse converts endpoint ListWorkspaceMembersResponse to protobuf ListWorkspaceMembersResponse
+func encodeListWorkspaceMembersResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.ListWorkspaceMembersResponse)
+	return &pb.ListWorkspaceMembersResponse{
+		Members: resp.Members,
+	}, nil
+}
+
+// encodeSetWorkspaceMemberResponse converts endpoint SetWorkspaceMemberResponse to protobuf SetWorkspaceMemberResponse
+func encodeSetWorkspaceMemberResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.SetWorkspaceMemberResponse)
+	return &pb.SetWorkspaceMemberResponse{
+		Member: resp.Member,
+	}, nil
+}
+
+// encodeRemoveWorkspaceMemberResponse converts endpoint RemoveWorkspaceMemberResponse to protobuf RemoveWorkspaceMemberResponse
+func encodeRemoveWorkspaceMemberResponse(_ context.Context, _ any) (any, error) {
+	return &pb.RemoveWorkspaceMemberResponse{}, nil
+}
+
+// encodeBeginOIDCLoginResponse converts endpoint BeginOIDCLoginResponse to protobuf BeginOIDCLoginResponse
+func encodeBeginOIDCLoginResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.BeginOIDCLoginResponse)
+	return &pb.BeginOIDCLoginResponse{
+		AuthUrl: resp.AuthUrl,
+		State:   resp.State,
+	}, nil
+}
+
+// encodeCompleteOIDCLoginResponse converts endpoint CompleteOIDCLoginResponse to protobuf CompleteOIDCLoginResponse
+func encodeCompleteOIDCLoginResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*authendpoint.CompleteOIDCLoginResponse)
+	var pbAcct *pb.Account
+	if resp.Account != nil {
+		pbAcct = &pb.Account{
+			Id:          resp.Account.GetId(),
+			AccountName: resp.Account.GetAccountName(),
+		}
+	}
+	return &pb.CompleteOIDCLoginResponse{
+		Token:        resp.Token,
+		Account:      pbAcct,
+		RefreshToken: resp.RefreshToken,
+		ExpiresIn:    resp.ExpiresIn,
 	}, nil
 }
 
@@ -232,6 +917,134 @@ func encodeVerifySessionRequest(_ context.Context, request any) (any, error) {
 	}, nil
 }
 
+// encodeRefreshSessionRequest converts endpoint RefreshSessionRequest to protobuf RefreshSessionRequest
+func encodeRefreshSessionRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.RefreshSessionRequest)
+	return &pb.RefreshSessionRequest{
+		RefreshToken: req.RefreshToken,
+	}, nil
+}
+
+// encodeLogoutRequest converts endpoint LogoutRequest to protobuf LogoutRequest
+func encodeLogoutRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.LogoutRequest)
+	return &pb.LogoutRequest{
+		Token: req.Token,
+	}, nil
+}
+
+// encodeRevokeSessionRequest converts endpoint RevokeSessionRequest to protobuf RevokeSessionRequest
+func encodeRevokeSessionRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.RevokeSessionRequest)
+	return &pb.RevokeSessionRequest{
+		AccountId: req.AccountId,
+		SessionId: req.SessionId,
+	}, nil
+}
+
+// encodeCreateAPIKeyRequest converts endpoint CreateAPIKeyRequest to protobuf CreateAPIKeyRequest
+func encodeCreateAPIKeyRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.CreateAPIKeyRequest)
+	return &pb.CreateAPIKeyRequest{
+		AccountId: req.AccountId,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}, nil
+}
+
+// encodeListAPIKeysRequest converts endpoint ListAPIKeysRequest to protobuf ListAPIKeysRequest
+func encodeListAPIKeysRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.ListAPIKeysRequest)
+	return &pb.ListAPIKeysRequest{
+		AccountId: req.AccountId,
+	}, nil
+}
+
+// encodeRevokeAPIKeyRequest converts endpoint RevokeAPIKeyRequest to protobuf RevokeAPIKeyRequest
+func encodeRevokeAPIKeyRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.RevokeAPIKeyRequest)
+	return &pb.RevokeAPIKeyRequest{
+		AccountId: req.AccountId,
+		Id:        req.Id,
+	}, nil
+}
+
+// encodeListPublicKeysRequest converts endpoint ListPublicKeysRequest to protobuf ListPublicKeysRequest
+func encodeListPublicKeysRequest(_ context.Context, _ any) (any, error) {
+	return &pb.ListPublicKeysRequest{}, nil
+}
+
+// encodeCreateWorkspaceRequest converts endpoint CreateWorkspaceRequest to protobuf CreateWorkspaceRequest
+func encodeCreateWorkspaceRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.CreateWorkspaceRequest)
+	return &pb.CreateWorkspaceRequest{
+		AccountId: req.AccountId,
+		Name:      req.Name,
+	}, nil
+}
+
+// encodeListWorkspacesRequest converts endpoint ListWorkspacesRequest to protobuf ListWorkspacesRequest
+func encodeListWorkspacesRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.ListWorkspacesRequest)
+	return &pb.ListWorkspacesRequest{
+		AccountId: req.AccountId,
+	}, nil
+}
+
+// encodeGetWorkspaceRoleRequest converts endpoint GetWorkspaceRoleRequest to protobuf GetWorkspaceRoleRequest
+func encodeGetWorkspaceRoleRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.GetWorkspaceRoleRequest)
+	return &pb.GetWorkspaceRoleRequest{
+		AccountId:   req.AccountId,
+		WorkspaceId: req.WorkspaceId,
+	}, nil
+}
+
+// encodeListWorkspaceMembersRequest converts endpoint ListWorkspaceMembersRequest to protobuf ListWorkspaceMembersRequest
+func encodeListWorkspaceMembersRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.ListWorkspaceMembersRequest)
+	return &pb.ListWorkspaceMembersRequest{
+		AccountId:   req.AccountId,
+		WorkspaceId: req.WorkspaceId,
+	}, nil
+}
+
+// encodeSetWorkspaceMemberRequest converts endpoint SetWorkspaceMemberRequest to protobuf SetWorkspaceMemberRequest
+func encodeSetWorkspaceMemberRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.SetWorkspaceMemberRequest)
+	return &pb.SetWorkspaceMemberRequest{
+		AccountId:   req.AccountId,
+		WorkspaceId: req.WorkspaceId,
+		MemberName:  req.MemberName,
+		Role:        req.Role,
+	}, nil
+}
+
+// encodeRemoveWorkspaceMemberRequest converts endpoint RemoveWorkspaceMemberRequest to protobuf RemoveWorkspaceMemberRequest
+func encodeRemoveWorkspaceMemberRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.RemoveWorkspaceMemberRequest)
+	return &pb.RemoveWorkspaceMemberRequest{
+		AccountId:   req.AccountId,
+		WorkspaceId: req.WorkspaceId,
+		MemberId:    req.MemberId,
+	}, nil
+}
+
+// encodeBeginOIDCLoginRequest converts endpoint BeginOIDCLoginRequest to protobuf BeginOIDCLoginRequest
+func encodeBeginOIDCLoginRequest(_ context.Context, _ any) (any, error) {
+	return &pb.BeginOIDCLoginRequest{}, nil
+}
+
+// encodeCompleteOIDCLoginRequest converts endpoint CompleteOIDCLoginRequest to protobuf CompleteOIDCLoginRequest
+func encodeCompleteOIDCLoginRequest(_ context.Context, request any) (any, error) {
+	req := request.(*authendpoint.CompleteOIDCLoginRequest)
+	return &pb.CompleteOIDCLoginRequest{
+		State: req.State,
+		Code:  req.Code,
+	}, nil
+}
+
 // Client-side decode functions (protobuf -> endpoint types)
 
 // decodeCreateAccountResponse converts protobuf CreateAccountResponse to endpoint CreateAccountResponse
@@ -253,8 +1066,10 @@ func decodeCreateSessionResponse(_ context.Context, grpcResp any) (any, error) {
 		}
 	}
 	return &authendpoint.CreateSessionResponse{
-		Token:   resp.GetToken(),
-		Account: acct,
+		Token:        resp.GetToken(),
+		Account:      acct,
+		RefreshToken: resp.GetRefreshToken(),
+		ExpiresIn:    resp.GetExpiresIn(),
 	}, nil
 }
 
@@ -263,5 +1078,138 @@ func decodeVerifySessionResponse(_ context.Context, grpcResp any) (any, error) {
 	resp := grpcResp.(*pb.VerifySessionResponse)
 	return &authendpoint.VerifyTokenResponse{
 		AccountId: resp.GetAccountId(),
+		SessionId: resp.GetSessionId(),
+		ApiKeyId:  resp.GetApiKeyId(),
+		Scopes:    resp.GetScopes(),
+	}, nil
+}
+
+// decodeRefreshSessionResponse converts protobuf RefreshSessionResponse to endpoint RefreshSessionResponse
+func decodeRefreshSessionResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.RefreshSessionResponse)
+	var acct *pb.Account
+	if resp.GetAccount() != nil {
+		acct = &pb.Account{
+			Id:          resp.GetAccount().GetId(),
+			AccountName: resp.GetAccount().GetAccountName(),
+		}
+	}
+	return &authendpoint.RefreshSessionResponse{
+		Token:        resp.GetToken(),
+		Account:      acct,
+		RefreshToken: resp.GetRefreshToken(),
+		ExpiresIn:    resp.GetExpiresIn(),
+	}, nil
+}
+
+// decodeLogoutResponse converts protobuf LogoutResponse to endpoint LogoutResponse
+func decodeLogoutResponse(_ context.Context, _ any) (any, error) {
+	return &authendpoint.LogoutResponse{}, nil
+}
+
+// decodeRevokeSessionResponse converts protobuf RevokeSessionResponse to endpoint RevokeSessionResponse
+func decodeRevokeSessionResponse(_ context.Context, _ any) (any, error) {
+	return &authendpoint.RevokeSessionResponse{}, nil
+}
+
+// decodeCreateAPIKeyResponse converts protobuf CreateAPIKeyResponse to endpoint CreateAPIKeyResponse
+func decodeCreateAPIKeyResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.CreateAPIKeyResponse)
+	return &authendpoint.CreateAPIKeyResponse{
+		ApiKey: resp.GetApiKey(),
+		Key:    resp.GetKey(),
+	}, nil
+}
+
+// decodeListAPIKeysResponse converts protobuf ListAPIKeysResponse to endpoint ListAPIKeysResponse
+func decodeListAPIKeysResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListAPIKeysResponse)
+	return &authendpoint.ListAPIKeysResponse{
+		ApiKeys: resp.GetApiKeys(),
+	}, nil
+}
+
+// decodeRevokeAPIKeyResponse converts protobuf RevokeAPIKeyResponse to endpoint RevokeAPIKeyResponse
+func decodeRevokeAPIKeyResponse(_ context.Context, _ any) (any, error) {
+	return &authendpoint.RevokeAPIKeyResponse{}, nil
+}
+
+// decodeListPublicKeysResponse converts protobuf ListPublicKeysResponse to endpoint ListPublicKeysResponse
+func decodeListPublicKeysResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListPublicKeysResponse)
+	return &authendpoint.ListPublicKeysResponse{
+		Keys: resp.GetKeys(),
+	}, nil
+}
+
+// decodeCreateWorkspaceResponse converts protobuf CreateWorkspaceResponse to endpoint CreateWorkspaceResponse
+func decodeCreateWorkspaceResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.CreateWorkspaceResponse)
+	return &authendpoint.CreateWorkspaceResponse{
+		Workspace: resp.GetWorkspace(),
+	}, nil
+}
+
+// decodeListWorkspacesResponse converts protobuf ListWorkspacesResponse to endpoint ListWorkspacesResponse
+func decodeListWorkspacesResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListWorkspacesResponse)
+	return &authendpoint.ListWorkspacesResponse{
+		Workspaces: resp.GetWorkspaces(),
+	}, nil
+}
+
+// decodeGetWorkspaceRoleResponse converts protobuf GetWorkspaceRoleResponse to endpoint GetWorkspaceRoleResponse
+func decodeGetWorkspaceRoleResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.GetWorkspaceRoleResponse)
+	return &authendpoint.GetWorkspaceRoleResponse{
+		Role: resp.GetRole(),
+	}, nil
+}
+
+// decodeListWorkspaceMembersResponse converts protobuf ListWorkspaceMembersResponse to endpoint ListWorkspaceMembersResponse
+func decodeListWorkspaceMembersResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListWorkspaceMembersResponse)
+	return &authendpoint.ListWorkspaceMembersResponse{
+		Members: resp.GetMembers(),
+	}, nil
+}
+
+// decodeSetWorkspaceMemberResponse converts protobuf SetWorkspaceMemberResponse to endpoint SetWorkspaceMemberResponse
+func decodeSetWorkspaceMemberResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.SetWorkspaceMemberResponse)
+	return &authendpoint.SetWorkspaceMemberResponse{
+		Member: resp.GetMember(),
+	}, nil
+}
+
+// decodeRemoveWorkspaceMemberResponse converts protobuf RemoveWorkspaceMemberResponse to endpoint RemoveWorkspaceMemberResponse
+func decodeRemoveWorkspaceMemberResponse(_ context.Context, _ any) (any, error) {
+	return &authendpoint.RemoveWorkspaceMemberResponse{}, nil
+}
+
+// decodeBeginOIDCLoginResponse converts protobuf BeginOIDCLoginResponse to endpoint BeginOIDCLoginResponse
+func decodeBeginOIDCLoginResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.BeginOIDCLoginResponse)
+	return &authendpoint.BeginOIDCLoginResponse{
+		AuthUrl: resp.GetAuthUrl(),
+		State:   resp.GetState(),
+	}, nil
+}
+
+// decodeCompleteOIDCLoginResponse converts protobuf CompleteOIDCLoginResponse to endpoint CompleteOIDCLoginResponse
+func decodeCompleteOIDCLoginResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.CompleteOIDCLoginResponse)
+	var acct *pb.Account
+	if resp.GetAccount() != nil {
+		acct = &pb.Account{
+			Id:          resp.GetAccount().GetId(),
+			AccountName: resp.GetAccount().GetAccountName(),
+		}
+	}
+	return &authendpoint.CompleteOIDCLoginResponse{
+		Token:        resp.GetToken(),
+		Account:      acct,
+		RefreshToken: resp.GetRefreshToken(),
+		ExpiresIn:    resp.GetExpiresIn(),
 	}, nil
 }
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// WorkspaceRole is the role of an account in a workspace. Each role grants
+// the permissions of the roles before it.
+type WorkspaceRole string
+
+const (
+	// WorkspaceRoleViewer lists, watches and downloads the tasks of the
+	// workspace.
+	WorkspaceRoleViewer WorkspaceRole = "viewer"
+	// WorkspaceRoleOperator also creates, pauses, resumes, cancels and
+	// retries tasks.
+	WorkspaceRoleOperator WorkspaceRole = "operator"
+	// WorkspaceRoleAdmin also deletes tasks and manages the members.
+	WorkspaceRoleAdmin WorkspaceRole = "admin"
+)
+
+// WorkspaceRoles lists every role from least to most privileged.
+var WorkspaceRoles = []WorkspaceRole{WorkspaceRoleViewer, WorkspaceRoleOperator, WorkspaceRoleAdmin}
+
+func (r WorkspaceRole) rank() int {
+	switch r {
+	case WorkspaceRoleViewer:
+		return 1
+	case WorkspaceRoleOperator:
+		return 2
+	case WorkspaceRoleAdmin:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether r grants the permissions of required.
+func (r WorkspaceRole) Allows(required WorkspaceRole) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// ParseWorkspaceRole validates a role name.
+func ParseWorkspaceRole(s string) (WorkspaceRole, error) {
+	role := WorkspaceRole(strings.ToLower(strings.TrimSpace(s)))
+	if role.rank() == 0 {
+		return "", &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown workspace role %q", s)}
+	}
+	return role, nil
+}
+
+const maxWorkspaceNameLength = 128
+
+// Workspace is a group of accounts sharing tasks.
+type Workspace struct {
+	Id        uint64
+	Name      string
+	CreatedAt time.Time
+}
+
+// WorkspaceMember is the membership of an account in a workspace.
+type WorkspaceMember struct {
+	WorkspaceId uint64
+	AccountId   uint64
+	AccountName string
+	Role        WorkspaceRole
+	CreatedAt   time.Time
+}
+
+// WorkspaceMembership is a workspace together with the role of the account
+// it was listed for.
+type WorkspaceMembership struct {
+	Workspace Workspace
+	Role      WorkspaceRole
+}
+
+type WorkspaceStore interface {
+	CreateWorkspace(ctx context.Context, workspace *Workspace) (uint64, error)
+	// ListWorkspaces returns the workspaces the account is a member of.
+	ListWorkspaces(ctx context.Context, accountID uint64) ([]WorkspaceMembership, error)
+	// GetWorkspaceMember returns errors.ErrNotFound when the account is not a
+	// member of the workspace.
+	GetWorkspaceMember(ctx context.Context, workspaceID, accountID uint64) (WorkspaceMember, error)
+	ListWorkspaceMembers(ctx context.Context, workspaceID uint64) ([]WorkspaceMember, error)
+	// SetWorkspaceMember adds the member or changes its role.
+	SetWorkspaceMember(ctx context.Context, member *WorkspaceMember) error
+	// RemoveWorkspaceMember returns errors.ErrNotFound when the account is
+	// not a member of the workspace.
+	RemoveWorkspaceMember(ctx context.Context, workspaceID, accountID uint64) error
+}
+
+// WithWorkspaces enables workspaces kept in store.
+func WithWorkspaces(store WorkspaceStore) ServiceOption {
+	return func(s *service) {
+		s.workspaces = store
+	}
+}
+
+type CreateWorkspaceParams struct {
+	// AccountID creates the workspace and becomes its first admin.
+	AccountID uint64
+	Name      string
+}
+
+type ListWorkspacesParams struct {
+	AccountID uint64
+}
+
+type GetWorkspaceRoleParams struct {
+	AccountID   uint64
+	WorkspaceID uint64
+}
+
+type ListWorkspaceMembersParams struct {
+	AccountID   uint64
+	WorkspaceID uint64
+}
+
+type SetWorkspaceMemberParams struct {
+	// AccountID is the admin making the change.
+	AccountID   uint64
+	WorkspaceID uint64
+	MemberName  string
+	Role        WorkspaceRole
+}
+
+type RemoveWorkspaceMemberParams struct {
+	// AccountID is an admin of the workspace, or the member leaving it.
+	AccountID   uint64
+	WorkspaceID uint64
+	MemberID    uint64
+}
+
+var errWorkspacesDisabled = &errors.Error{Code: errors.ErrCodeInvalidState, Message: "workspaces are disabled"}
+
+func (s *service) CreateWorkspace(ctx context.Context, params CreateWorkspaceParams) (Workspace, error) {
+	if s.workspaces == nil {
+		return Workspace{}, errWorkspacesDisabled
+	}
+	if params.AccountID == 0 {
+		return Workspace{}, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "account id is required"}
+	}
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len(name) > maxWorkspaceNameLength {
+		return Workspace{}, &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("name must be 1 to %d characters", maxWorkspaceNameLength),
+		}
+	}
+
+	workspace := Workspace{Name: name, CreatedAt: time.Now()}
+	err := s.txManager.DoInTx(ctx, func(ctx context.Context) error {
+		var err error
+		if workspace.Id, err = s.workspaces.CreateWorkspace(ctx, &workspace); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "creating workspace failed", Cause: err}
+		}
+		if err := s.workspaces.SetWorkspaceMember(ctx, &WorkspaceMember{
+			WorkspaceId: workspace.Id,
+			AccountId:   params.AccountID,
+			Role:        WorkspaceRoleAdmin,
+			CreatedAt:   workspace.CreatedAt,
+		}); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "adding workspace admin failed", Cause: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return Workspace{}, err
+	}
+	return workspace, nil
+}
+
+func (s *service) ListWorkspaces(ctx context.Context, params ListWorkspacesParams) ([]WorkspaceMembership, error) {
+	if s.workspaces == nil {
+		return nil, errWorkspacesDisabled
+	}
+	workspaces, err := s.workspaces.ListWorkspaces(ctx, params.AccountID)
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list workspaces", Cause: err}
+	}
+	return workspaces, nil
+}
+
+func (s *service) GetWorkspaceRole(ctx context.Context, params GetWorkspaceRoleParams) (WorkspaceRole, error) {
+	if s.workspaces == nil {
+		return "", errWorkspacesDisabled
+	}
+	member, err := s.workspaces.GetWorkspaceMember(ctx, params.WorkspaceID, params.AccountID)
+	if stderrors.Is(err, errors.ErrNotFound) {
+		return "", &errors.Error{Code: errors.ErrCodeNotFound, Message: "not a member of the workspace"}
+	}
+	if err != nil {
+		return "", &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get workspace member", Cause: err}
+	}
+	return member.Role, nil
+}
+
+// requireWorkspaceRole fails unless the account has at least the role in
+// the workspace.
+func (s *service) requireWorkspaceRole(ctx context.Context, workspaceID, accountID uint64, required WorkspaceRole) error {
+	role, err := s.GetWorkspaceRole(ctx, GetWorkspaceRoleParams{AccountID: accountID, WorkspaceID: workspaceID})
+	if errors.IsError(err, errors.ErrCodeNotFound) || (err == nil && !role.Allows(required)) {
+		return &errors.Error{Code: errors.ErrCodePermissionDenied, Message: "permission denied"}
+	}
+	return err
+}
+
+func (s *service) ListWorkspaceMembers(ctx context.Context, params ListWorkspaceMembersParams) ([]WorkspaceMember, error) {
+	if err := s.requireWorkspaceRole(ctx, params.WorkspaceID, params.AccountID, WorkspaceRoleViewer); err != nil {
+		return nil, err
+	}
+	members, err := s.workspaces.ListWorkspaceMembers(ctx, params.WorkspaceID)
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list workspace members", Cause: err}
+	}
+	return members, nil
+}
+
+func (s *service) SetWorkspaceMember(ctx context.Context, params SetWorkspaceMemberParams) (WorkspaceMember, error) {
+	if err := s.requireWorkspaceRole(ctx, params.WorkspaceID, params.AccountID, WorkspaceRoleAdmin); err != nil {
+		return WorkspaceMember{}, err
+	}
+	role, err := ParseWorkspaceRole(string(params.Role))
+	if err != nil {
+		return WorkspaceMember{}, err
+	}
+	account, err := s.accountStore.GetAccountByAccountName(ctx, params.MemberName)
+	if (err != nil && stderrors.Is(err, errors.ErrNotFound)) || (err == nil && account == nil) {
+		return WorkspaceMember{}, &errors.Error{Code: errors.ErrCodeNotFound, Message: "account not found"}
+	}
+	if err != nil {
+		return WorkspaceMember{}, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get account", Cause: err}
+	}
+
+	member := WorkspaceMember{
+		WorkspaceId: params.WorkspaceID,
+		AccountId:   account.Id,
+		AccountName: account.AccountName,
+		Role:        role,
+		CreatedAt:   time.Now(),
+	}
+	err = s.txManager.DoInTx(ctx, func(ctx context.Context) error {
+		if role != WorkspaceRoleAdmin {
+			if err := s.keepAnAdmin(ctx, params.WorkspaceID, account.Id); err != nil {
+				return err
+			}
+		}
+		if err := s.workspaces.SetWorkspaceMember(ctx, &member); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "setting workspace member failed", Cause: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return WorkspaceMember{}, err
+	}
+	return member, nil
+}
+
+func (s *service) RemoveWorkspaceMember(ctx context.Context, params RemoveWorkspaceMemberParams) error {
+	// Every member may leave; removing others takes an admin.
+	required := WorkspaceRoleAdmin
+	if params.MemberID == params.AccountID {
+		required = WorkspaceRoleViewer
+	}
+	if err := s.requireWorkspaceRole(ctx, params.WorkspaceID, params.AccountID, required); err != nil {
+		return err
+	}
+	return s.txManager.DoInTx(ctx, func(ctx context.Context) error {
+		if err := s.keepAnAdmin(ctx, params.WorkspaceID, params.MemberID); err != nil {
+			return err
+		}
+		err := s.workspaces.RemoveWorkspaceMember(ctx, params.WorkspaceID, params.MemberID)
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return &errors.Error{Code: errors.ErrCodeNotFound, Message: "workspace member not found"}
+		}
+		if err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to remove workspace member", Cause: err}
+		}
+		return nil
+	})
+}
+
+// keepAnAdmin fails when accountID is the last admin of the workspace, so
+// that a workspace cannot be left without anyone managing it.
+func (s *service) keepAnAdmin(ctx context.Context, workspaceID, accountID uint64) error {
+	members, err := s.workspaces.ListWorkspaceMembers(ctx, workspaceID)
+	if err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list workspace members", Cause: err}
+	}
+	for _, m := range members {
+		if m.Role == WorkspaceRoleAdmin && m.AccountId != accountID {
+			return nil
+		}
+	}
+	for _, m := range members {
+		if m.Role == WorkspaceRoleAdmin && m.AccountId == accountID {
+			return &errors.Error{Code: errors.ErrCodeConflict, Message: "a workspace needs at least one admin"}
+		}
+	}
+	return nil
+}
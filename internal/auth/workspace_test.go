@@ -0,0 +1,217 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/auth"
+	apperrors "github.com/yuisofull/goload/internal/errors"
+)
+
+type fakeAccounts map[string]*auth.Account
+
+func (f fakeAccounts) CreateAccount(context.Context, *auth.Account) (uint64, error) { return 0, nil }
+
+func (f fakeAccounts) GetAccountByID(_ context.Context, id uint64) (*auth.Account, error) {
+	for _, a := range f {
+		if a.Id == id {
+			return a, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (f fakeAccounts) GetAccountByAccountName(_ context.Context, name string) (*auth.Account, error) {
+	if a, ok := f[name]; ok {
+		return a, nil
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+type memberKey struct{ workspaceID, accountID uint64 }
+
+type fakeWorkspaceStore struct {
+	workspaces map[uint64]auth.Workspace
+	members    map[memberKey]auth.WorkspaceMember
+}
+
+func (f *fakeWorkspaceStore) CreateWorkspace(_ context.Context, workspace *auth.Workspace) (uint64, error) {
+	id := uint64(len(f.workspaces) + 1)
+	w := *workspace
+	w.Id = id
+	f.workspaces[id] = w
+	return id, nil
+}
+
+func (f *fakeWorkspaceStore) ListWorkspaces(_ context.Context, accountID uint64) ([]auth.WorkspaceMembership, error) {
+	var out []auth.WorkspaceMembership
+	for k, m := range f.members {
+		if k.accountID == accountID {
+			out = append(out, auth.WorkspaceMembership{Workspace: f.workspaces[k.workspaceID], Role: m.Role})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWorkspaceStore) GetWorkspaceMember(_ context.Context, workspaceID, accountID uint64) (auth.WorkspaceMember, error) {
+	m, ok := f.members[memberKey{workspaceID, accountID}]
+	if !ok {
+		return auth.WorkspaceMember{}, apperrors.ErrNotFound
+	}
+	return m, nil
+}
+
+func (f *fakeWorkspaceStore) ListWorkspaceMembers(_ context.Context, workspaceID uint64) ([]auth.WorkspaceMember, error) {
+	var out []auth.WorkspaceMember
+	for k, m := range f.members {
+		if k.workspaceID == workspaceID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWorkspaceStore) SetWorkspaceMember(_ context.Context, member *auth.WorkspaceMember) error {
+	f.members[memberKey{member.WorkspaceId, member.AccountId}] = *member
+	return nil
+}
+
+func (f *fakeWorkspaceStore) RemoveWorkspaceMember(_ context.Context, workspaceID, accountID uint64) error {
+	k := memberKey{workspaceID, accountID}
+	if _, ok := f.members[k]; !ok {
+		return apperrors.ErrNotFound
+	}
+	delete(f.members, k)
+	return nil
+}
+
+func newWorkspaceService(t *testing.T) auth.Service {
+	t.Helper()
+	return auth.NewService(
+		fakeAccounts{
+			"alice": {Id: 1, AccountName: "alice"},
+			"bob":   {Id: 2, AccountName: "bob"},
+			"carol": {Id: 3, AccountName: "carol"},
+		},
+		fakePasswordStore{},
+		noTx{},
+		plainHasher{},
+		auth.NewNoopTokenManager(time.Hour),
+		auth.WithWorkspaces(&fakeWorkspaceStore{
+			workspaces: map[uint64]auth.Workspace{},
+			members:    map[memberKey]auth.WorkspaceMember{},
+		}),
+	)
+}
+
+func TestWorkspace_RolesControlMembership(t *testing.T) {
+	ctx := context.Background()
+	svc := newWorkspaceService(t)
+
+	ws, err := svc.CreateWorkspace(ctx, auth.CreateWorkspaceParams{AccountID: 1, Name: " team "})
+	require.NoError(t, err)
+	assert.Equal(t, "team", ws.Name)
+
+	role, err := svc.GetWorkspaceRole(ctx, auth.GetWorkspaceRoleParams{AccountID: 1, WorkspaceID: ws.Id})
+	require.NoError(t, err)
+	assert.Equal(t, auth.WorkspaceRoleAdmin, role)
+
+	_, err = svc.GetWorkspaceRole(ctx, auth.GetWorkspaceRoleParams{AccountID: 2, WorkspaceID: ws.Id})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeNotFound))
+
+	_, err = svc.ListWorkspaceMembers(ctx, auth.ListWorkspaceMembersParams{AccountID: 2, WorkspaceID: ws.Id})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodePermissionDenied))
+
+	member, err := svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   1,
+		WorkspaceID: ws.Id,
+		MemberName:  "bob",
+		Role:        "Operator",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), member.AccountId)
+	assert.Equal(t, auth.WorkspaceRoleOperator, member.Role)
+
+	// Operators see the members but cannot manage them.
+	members, err := svc.ListWorkspaceMembers(ctx, auth.ListWorkspaceMembersParams{AccountID: 2, WorkspaceID: ws.Id})
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+	_, err = svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   2,
+		WorkspaceID: ws.Id,
+		MemberName:  "carol",
+		Role:        auth.WorkspaceRoleViewer,
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodePermissionDenied))
+	err = svc.RemoveWorkspaceMember(ctx, auth.RemoveWorkspaceMemberParams{AccountID: 2, WorkspaceID: ws.Id, MemberID: 1})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodePermissionDenied))
+
+	// Every member may leave.
+	require.NoError(t, svc.RemoveWorkspaceMember(ctx, auth.RemoveWorkspaceMemberParams{
+		AccountID:   2,
+		WorkspaceID: ws.Id,
+		MemberID:    2,
+	}))
+	workspaces, err := svc.ListWorkspaces(ctx, auth.ListWorkspacesParams{AccountID: 2})
+	require.NoError(t, err)
+	assert.Empty(t, workspaces)
+
+	_, err = svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   1,
+		WorkspaceID: ws.Id,
+		MemberName:  "carol",
+		Role:        "owner",
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+	_, err = svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   1,
+		WorkspaceID: ws.Id,
+		MemberName:  "dave",
+		Role:        auth.WorkspaceRoleViewer,
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeNotFound))
+}
+
+func TestWorkspace_KeepsAnAdmin(t *testing.T) {
+	ctx := context.Background()
+	svc := newWorkspaceService(t)
+
+	ws, err := svc.CreateWorkspace(ctx, auth.CreateWorkspaceParams{AccountID: 1, Name: "team"})
+	require.NoError(t, err)
+
+	_, err = svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   1,
+		WorkspaceID: ws.Id,
+		MemberName:  "alice",
+		Role:        auth.WorkspaceRoleViewer,
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeConflict))
+	err = svc.RemoveWorkspaceMember(ctx, auth.RemoveWorkspaceMemberParams{AccountID: 1, WorkspaceID: ws.Id, MemberID: 1})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeConflict))
+
+	// With a second admin the first one may step down.
+	_, err = svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   1,
+		WorkspaceID: ws.Id,
+		MemberName:  "bob",
+		Role:        auth.WorkspaceRoleAdmin,
+	})
+	require.NoError(t, err)
+	_, err = svc.SetWorkspaceMember(ctx, auth.SetWorkspaceMemberParams{
+		AccountID:   1,
+		WorkspaceID: ws.Id,
+		MemberName:  "alice",
+		Role:        auth.WorkspaceRoleViewer,
+	})
+	require.NoError(t, err)
+
+	role, err := svc.GetWorkspaceRole(ctx, auth.GetWorkspaceRoleParams{AccountID: 1, WorkspaceID: ws.Id})
+	require.NoError(t, err)
+	assert.Equal(t, auth.WorkspaceRoleViewer, role)
+	assert.True(t, role.Allows(auth.WorkspaceRoleViewer))
+	assert.False(t, role.Allows(auth.WorkspaceRoleOperator))
+}
@@ -0,0 +1,57 @@
+package download
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+const defaultChecksumType = "md5"
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// newChecksumHash returns a hash for the given checksum algorithm. An empty
+// algorithm falls back to md5.
+func newChecksumHash(checksumType string) (hash.Hash, string, error) {
+	algo := normalizeChecksumType(checksumType)
+	switch algo {
+	case "md5":
+		return md5.New(), algo, nil
+	case "sha1":
+		return sha1.New(), algo, nil
+	case "sha256":
+		return sha256.New(), algo, nil
+	case "sha512":
+		return sha512.New(), algo, nil
+	case "crc32c":
+		return crc32.New(crc32cTable), algo, nil
+	default:
+		return nil, "", &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("unsupported checksum type %q", checksumType),
+		}
+	}
+}
+
+func normalizeChecksumType(checksumType string) string {
+	algo := strings.ToLower(strings.TrimSpace(checksumType))
+	algo = strings.ReplaceAll(algo, "-", "")
+	if algo == "" {
+		return defaultChecksumType
+	}
+	return algo
+}
+
+// checksumMatches compares a hex encoded digest against the expected value,
+// ignoring case and surrounding whitespace.
+func checksumMatches(expected string, sum []byte) bool {
+	return strings.EqualFold(strings.TrimSpace(expected), hex.EncodeToString(sum))
+}
@@ -16,3 +16,19 @@ type Downloader interface {
 	GetFileInfo(ctx context.Context, url string, sourceAuth *AuthConfig) (metadata *FileMetadata, err error)
 	SupportsResume() bool
 }
+
+// ResumableDownloader is an optional interface implemented by downloaders that
+// can continue a transfer from a byte offset. The validator (an ETag or
+// Last-Modified value) guards against the source changing between requests;
+// implementations return an error with ErrCodeSourceChanged when it no longer
+// matches. The returned total is the size of the whole object.
+type ResumableDownloader interface {
+	DownloadFrom(
+		ctx context.Context,
+		url string,
+		sourceAuth *AuthConfig,
+		opts DownloadOptions,
+		offset int64,
+		validator string,
+	) (reader io.ReadCloser, total int64, err error)
+}
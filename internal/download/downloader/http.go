@@ -19,6 +19,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/yuisofull/goload/internal/download"
+	"github.com/yuisofull/goload/internal/errors"
 )
 
 const defaultUserAgent = "Mozilla/5.0 (compatible; GoLoad/1.0; +https://github.com/yuisofull/goload)"
@@ -72,7 +73,7 @@ func NewHTTPDownloader(client *http.Client, opts ...HTTPDownloaderOption) *HTTPD
 }
 
 // SupportsResume returns true; the downloader will attempt range requests when
-// the server advertises "Accept-Ranges: bytes".
+// the server advertises "Accept-Ranges: bytes". See DownloadFrom.
 func (h *HTTPDownloader) SupportsResume() bool { return true }
 
 // GetFileInfo issues a HEAD request (falling back to a zero-byte GET) to
@@ -240,6 +241,79 @@ func (h *HTTPDownloader) Download(
 	return reader, resp.ContentLength, nil
 }
 
+// DownloadFrom continues a download at offset with a "Range: bytes=<offset>-"
+// request. When validator is set it is sent as If-Range so that a changed
+// source yields a full response, which is reported as ErrCodeSourceChanged
+// instead of silently appending unrelated bytes.
+func (h *HTTPDownloader) DownloadFrom(
+	ctx context.Context,
+	rawURL string,
+	auth *download.AuthConfig,
+	opts download.DownloadOptions,
+	offset int64,
+	validator string,
+) (io.ReadCloser, int64, error) {
+	if offset <= 0 {
+		opts.Concurrency = 1
+		return h.Download(ctx, rawURL, auth, opts)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("build GET request: %w", err)
+	}
+	applyAuth(req, auth)
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	if validator != "" {
+		req.Header.Set("If-Range", validator)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GET %s: %w", rawURL, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK, http.StatusPreconditionFailed:
+		resp.Body.Close()
+		return nil, 0, &errors.Error{
+			Code:    download.ErrCodeSourceChanged,
+			Message: fmt.Sprintf("GET %s: source changed since byte %d was downloaded", rawURL, offset),
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		// The whole object was already downloaded.
+		if total := totalFromContentRange(resp.Header.Get("Content-Range")); total == offset {
+			return io.NopCloser(strings.NewReader("")), total, nil
+		}
+		return nil, 0, &errors.Error{
+			Code:    download.ErrCodeSourceChanged,
+			Message: fmt.Sprintf("GET %s: range starting at %d not satisfiable", rawURL, offset),
+		}
+	default:
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("GET %s: unexpected status %s", rawURL, resp.Status)
+	}
+
+	start, total := parseContentRange(resp.Header.Get("Content-Range"))
+	if start != offset {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("GET %s: server resumed at byte %d, want %d", rawURL, start, offset)
+	}
+	if total <= 0 && resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	level.Debug(h.logger).Log("msg", "resuming download", "offset", offset, "total", total)
+
+	reader := resp.Body
+	if opts.MaxSpeed != nil && *opts.MaxSpeed > 0 {
+		reader = newRateLimitedReader(ctx, resp.Body, *opts.MaxSpeed)
+	}
+	return reader, total, nil
+}
+
 type chunkJob struct {
 	index int
 	start int64
@@ -507,12 +581,8 @@ func buildMetadata(rawURL string, h http.Header) *download.FileMetadata {
 
 	// For partial responses (206) the true total is in Content-Range.
 	// e.g. "bytes 0-0/12345" → total = 12345
-	if cr := h.Get("Content-Range"); cr != "" {
-		if idx := strings.LastIndex(cr, "/"); idx >= 0 {
-			if n, err := strconv.ParseInt(cr[idx+1:], 10, 64); err == nil {
-				meta.FileSize = n
-			}
-		}
+	if total := totalFromContentRange(h.Get("Content-Range")); total > 0 {
+		meta.FileSize = total
 	}
 
 	// Full responses carry the total in Content-Length.
@@ -542,6 +612,33 @@ func buildMetadata(rawURL string, h http.Header) *download.FileMetadata {
 	return meta
 }
 
+// totalFromContentRange returns the complete length from a Content-Range
+// header such as "bytes 0-0/12345" or "bytes */12345", or -1 when unknown.
+func totalFromContentRange(cr string) int64 {
+	idx := strings.LastIndex(cr, "/")
+	if idx < 0 {
+		return -1
+	}
+	n, err := strconv.ParseInt(cr[idx+1:], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// parseContentRange returns the first byte position and the complete length
+// from a Content-Range header. Unknown values are reported as -1.
+func parseContentRange(cr string) (start, total int64) {
+	start, total = -1, totalFromContentRange(cr)
+	spec := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(cr), "bytes"))
+	if dash := strings.Index(spec, "-"); dash > 0 {
+		if n, err := strconv.ParseInt(strings.TrimSpace(spec[:dash]), 10, 64); err == nil {
+			start = n
+		}
+	}
+	return start, total
+}
+
 // filenameFromHeader parses the filename from a Content-Disposition header.
 // e.g. "attachment; filename=\"report.pdf\""
 func filenameFromHeader(header string) string {
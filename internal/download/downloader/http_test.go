@@ -16,6 +16,7 @@ import (
 
 	"github.com/yuisofull/goload/internal/download"
 	"github.com/yuisofull/goload/internal/download/downloader"
+	"github.com/yuisofull/goload/internal/errors"
 )
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -431,3 +432,60 @@ func TestDownload_NoThrottle_WhenMaxSpeedIsNil(t *testing.T) {
 func TestSupportsResume(t *testing.T) {
 	assert.True(t, newDL().SupportsResume())
 }
+
+// ─────────────────────────────────────────────────────────────────────────────
+// DownloadFrom
+// ─────────────────────────────────────────────────────────────────────────────
+
+func TestDownloadFrom_SendsRangeAndValidator(t *testing.T) {
+	const body = "0123456789"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bytes=4-", r.Header.Get("Range"))
+		assert.Equal(t, `"v1"`, r.Header.Get("If-Range"))
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 4-%d/%d", len(body)-1, len(body)))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprint(w, body[4:])
+	}))
+	defer srv.Close()
+
+	rc, total, err := newDL().DownloadFrom(
+		context.Background(), srv.URL+"/file.bin", nil, download.DownloadOptions{}, 4, `"v1"`,
+	)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, body[4:], string(data))
+	assert.Equal(t, int64(len(body)), total)
+}
+
+func TestDownloadFrom_SourceChangedWhenServerSendsFullBody(t *testing.T) {
+	srv := serve(t, http.StatusOK, "new content", "application/octet-stream", nil)
+	defer srv.Close()
+
+	_, _, err := newDL().DownloadFrom(
+		context.Background(), srv.URL+"/file.bin", nil, download.DownloadOptions{}, 4, `"v1"`,
+	)
+	require.Error(t, err)
+	assert.True(t, errors.IsError(err, download.ErrCodeSourceChanged))
+}
+
+func TestDownloadFrom_AlreadyComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes */10")
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer srv.Close()
+
+	rc, total, err := newDL().DownloadFrom(
+		context.Background(), srv.URL+"/file.bin", nil, download.DownloadOptions{}, 10, `"v1"`,
+	)
+	require.NoError(t, err)
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+	assert.Equal(t, int64(10), total)
+}
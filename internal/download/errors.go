@@ -0,0 +1,12 @@
+package download
+
+import "github.com/yuisofull/goload/internal/errors"
+
+const (
+	// ErrCodeChecksumMismatch is reported when the stored object does not match
+	// the checksum requested by the user.
+	ErrCodeChecksumMismatch errors.Code = "CHECKSUM_MISMATCH"
+	// ErrCodeSourceChanged is reported by resumable downloaders when the remote
+	// object no longer matches the validator a partial download was started with.
+	ErrCodeSourceChanged errors.Code = "SOURCE_CHANGED"
+)
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/yuisofull/goload/internal/events"
+	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/pkg/message"
+	"github.com/yuisofull/goload/pkg/message/middleware"
 )
 
 // DownloadEventPublisher publishes download-related events
@@ -42,7 +45,7 @@ func (dep *DownloadEventPublisher) PublishTaskStatusUpdated(
 		},
 	}
 
-	return dep.publisher.Publish("task.status.updated", msg)
+	return dep.publish(ctx, "task.status.updated", msg)
 }
 
 // PublishTaskProgressUpdated publishes a task progress update event
@@ -64,7 +67,7 @@ func (dep *DownloadEventPublisher) PublishTaskProgressUpdated(
 		},
 	}
 
-	return dep.publisher.Publish("task.progress.updated", msg)
+	return dep.publish(ctx, "task.progress.updated", msg)
 }
 
 // PublishTaskCompleted publishes a task completion event
@@ -83,7 +86,7 @@ func (dep *DownloadEventPublisher) PublishTaskCompleted(ctx context.Context, eve
 		},
 	}
 
-	return dep.publisher.Publish("task.completed", msg)
+	return dep.publish(ctx, "task.completed", msg)
 }
 
 // PublishTaskFailed publishes a task failure event
@@ -102,7 +105,7 @@ func (dep *DownloadEventPublisher) PublishTaskFailed(ctx context.Context, event
 		},
 	}
 
-	return dep.publisher.Publish("task.failed", msg)
+	return dep.publish(ctx, "task.failed", msg)
 }
 
 // PublishTaskRetried publishes a task retried event
@@ -121,7 +124,90 @@ func (dep *DownloadEventPublisher) PublishTaskRetried(ctx context.Context, event
 		},
 	}
 
-	return dep.publisher.Publish("task.retried", msg)
+	return dep.publish(ctx, "task.retried", msg)
+}
+
+// PublishTaskFileExpired publishes a task file expired event
+func (dep *DownloadEventPublisher) PublishTaskFileExpired(ctx context.Context, event events.TaskFileExpiredEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := &message.Message{
+		UUID:    generateUUID(),
+		Payload: payload,
+		Metadata: message.Metadata{
+			"eventType": "TaskFileExpired",
+			"taskID":    formatTaskID(event.TaskID),
+		},
+	}
+
+	return dep.publish(ctx, "task.file.expired", msg)
+}
+
+// PublishTaskClaimed publishes a task claimed event
+func (dep *DownloadEventPublisher) PublishTaskClaimed(ctx context.Context, event events.TaskClaimedEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := &message.Message{
+		UUID:    generateUUID(),
+		Payload: payload,
+		Metadata: message.Metadata{
+			"eventType": "TaskClaimed",
+			"taskID":    formatTaskID(event.TaskID),
+		},
+	}
+
+	return dep.publish(ctx, string(events.EventTaskClaimed), msg)
+}
+
+// PublishTaskControlAcked publishes the outcome of a control event handled by
+// the worker
+func (dep *DownloadEventPublisher) PublishTaskControlAcked(
+	ctx context.Context,
+	event events.TaskControlAckedEvent,
+) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := &message.Message{
+		UUID:    generateUUID(),
+		Payload: payload,
+		Metadata: message.Metadata{
+			"eventType": "TaskControlAcked",
+			"taskID":    formatTaskID(event.TaskID),
+		},
+	}
+
+	return dep.publish(ctx, string(events.EventTaskControlAcked), msg)
+}
+
+// PublishObjectExpired publishes a task file expired event for an object the
+// local storage backend reaped. Objects that belong to no task are ignored.
+func (dep *DownloadEventPublisher) PublishObjectExpired(ctx context.Context, obj storage.ExpiredObject) error {
+	if obj.TaskID == 0 {
+		return nil
+	}
+	return dep.PublishTaskFileExpired(ctx, events.TaskFileExpiredEvent{
+		TaskID:      obj.TaskID,
+		OfAccountID: obj.OwnerID,
+		StorageKey:  obj.Key,
+		ExpiredAt:   time.Now(),
+	})
+}
+
+// publish publishes msg with the correlation ID of ctx.
+func (dep *DownloadEventPublisher) publish(ctx context.Context, topic string, msg *message.Message) error {
+	if id := middleware.CorrelationIDFromContext(ctx); id != "" {
+		middleware.SetCorrelationID(id, msg)
+	}
+	return dep.publisher.Publish(topic, msg)
 }
 
 func generateUUID() string {
@@ -0,0 +1,137 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	stderrs "errors"
+	"fmt"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/yuisofull/goload/internal/download"
+	"github.com/yuisofull/goload/internal/download/mysql/sqlc"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+type queue struct {
+	queries *sqlc.Queries
+	codec   *download.RequestCodec
+}
+
+// NewQueue returns a download.Queue backed by the download_queue table,
+// recording the requests encoded by codec.
+func NewQueue(db *sql.DB, codec *download.RequestCodec) download.Queue {
+	return &queue{queries: sqlc.New(db), codec: codec}
+}
+
+func (q *queue) Enqueue(ctx context.Context, req download.TaskRequest) error {
+	request, err := q.codec.Encode(req)
+	if err != nil {
+		return fmt.Errorf("marshal TaskRequest: %w", err)
+	}
+	return q.queries.EnqueueTask(ctx, sqlc.EnqueueTaskParams{
+		TaskID:  req.TaskID,
+		Request: request,
+		State:   string(download.QueueStateQueued),
+	})
+}
+
+func (q *queue) Get(ctx context.Context, taskID uint64) (*download.QueuedTask, error) {
+	row, err := q.queries.GetQueuedTask(ctx, taskID)
+	if err != nil {
+		if stderrs.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return q.toQueuedTask(row)
+}
+
+func (q *queue) UpdateState(ctx context.Context, taskID uint64, state download.QueueState) error {
+	return q.queries.UpdateQueuedTaskState(ctx, sqlc.UpdateQueuedTaskStateParams{
+		State:  string(state),
+		TaskID: taskID,
+	})
+}
+
+func (q *queue) UpdateOffset(ctx context.Context, taskID uint64, offset, totalBytes int64) error {
+	return q.queries.UpdateQueuedTaskOffset(ctx, sqlc.UpdateQueuedTaskOffsetParams{
+		DownloadedBytes: offset,
+		TotalBytes:      totalBytes,
+		TaskID:          taskID,
+	})
+}
+
+func (q *queue) Remove(ctx context.Context, taskID uint64) error {
+	return q.queries.DeleteQueuedTask(ctx, taskID)
+}
+
+func (q *queue) ListUnfinished(ctx context.Context) ([]*download.QueuedTask, error) {
+	rows, err := q.queries.ListQueuedTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*download.QueuedTask, 0, len(rows))
+	for _, row := range rows {
+		qt, err := q.toQueuedTask(row)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, qt)
+	}
+	return tasks, nil
+}
+
+func (q *queue) Claim(ctx context.Context, taskID uint64, owner string, now, expiresAt time.Time) (bool, error) {
+	rows, err := q.queries.ClaimQueuedTask(ctx, sqlc.ClaimQueuedTaskParams{
+		LeaseOwner:     owner,
+		LeaseExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+		TaskID:         taskID,
+		Now:            now,
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows > 0 {
+		return true, nil
+	}
+	// MySQL reports no affected rows when the owner claims its task again
+	// within the precision of lease_expires_at.
+	qt, err := q.Get(ctx, taskID)
+	if err != nil {
+		if stderrs.Is(err, errors.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return qt.LeaseOwner == owner, nil
+}
+
+func (q *queue) RenewLeases(ctx context.Context, owner string, expiresAt time.Time) error {
+	return q.queries.RenewQueuedTaskLeases(ctx, sqlc.RenewQueuedTaskLeasesParams{
+		LeaseExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+		LeaseOwner:     owner,
+	})
+}
+
+func (q *queue) toQueuedTask(row sqlc.DownloadQueue) (*download.QueuedTask, error) {
+	qt := &download.QueuedTask{
+		State:      download.QueueState(row.State),
+		Offset:     row.DownloadedBytes,
+		TotalBytes: row.TotalBytes,
+		EnqueuedAt: row.EnqueuedAt,
+		UpdatedAt:  row.UpdatedAt,
+		LeaseOwner: row.LeaseOwner,
+	}
+	if row.LeaseExpiresAt.Valid {
+		qt.LeaseExpiresAt = row.LeaseExpiresAt.Time
+	}
+	req, err := q.codec.Decode(row.Request)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal TaskRequest: %w", err)
+	}
+	qt.Request = req
+	qt.Request.TaskID = row.TaskID
+	return qt, nil
+}
@@ -0,0 +1,39 @@
+-- name: EnqueueTask :exec
+INSERT IGNORE INTO download_queue (task_id, request, state)
+VALUES (?, ?, ?);
+
+-- name: GetQueuedTask :one
+SELECT *
+FROM download_queue
+WHERE task_id = ?;
+
+-- name: UpdateQueuedTaskState :exec
+UPDATE download_queue
+SET state = ?
+WHERE task_id = ?;
+
+-- name: UpdateQueuedTaskOffset :exec
+UPDATE download_queue
+SET downloaded_bytes = ?, total_bytes = ?
+WHERE task_id = ?;
+
+-- name: DeleteQueuedTask :exec
+DELETE FROM download_queue
+WHERE task_id = ?;
+
+-- name: ListQueuedTasks :many
+SELECT *
+FROM download_queue
+ORDER BY enqueued_at, task_id;
+
+-- name: ClaimQueuedTask :execrows
+UPDATE download_queue
+SET lease_owner = sqlc.arg(lease_owner), lease_expires_at = sqlc.arg(lease_expires_at)
+WHERE task_id = sqlc.arg(task_id)
+  AND (lease_owner = '' OR lease_owner = sqlc.arg(lease_owner)
+    OR lease_expires_at IS NULL OR lease_expires_at < sqlc.arg(now));
+
+-- name: RenewQueuedTaskLeases :exec
+UPDATE download_queue
+SET lease_expires_at = ?
+WHERE lease_owner = ?;
@@ -0,0 +1,16 @@
+CREATE TABLE
+    IF NOT EXISTS download_queue (
+        task_id BIGINT UNSIGNED NOT NULL,
+        request JSON NOT NULL,
+        state VARCHAR(32) NOT NULL,
+        downloaded_bytes BIGINT NOT NULL DEFAULT 0,
+        total_bytes BIGINT NOT NULL DEFAULT 0,
+        enqueued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        -- Worker executing the task and until when, unless it renews the lease
+        lease_owner VARCHAR(255) NOT NULL DEFAULT '',
+        lease_expires_at DATETIME(3) NULL,
+        PRIMARY KEY (task_id),
+        INDEX (enqueued_at),
+        INDEX idx_download_queue_lease_owner (lease_owner)
+    );
@@ -0,0 +1,59 @@
+package download
+
+import (
+	"context"
+	"time"
+)
+
+// QueueState is the state of a task recorded in the download queue.
+type QueueState string
+
+const (
+	// QueueStateQueued means the task was accepted but has not started yet.
+	QueueStateQueued QueueState = "QUEUED"
+	// QueueStateRunning means a worker is downloading or storing the task.
+	QueueStateRunning QueueState = "RUNNING"
+	// QueueStatePaused means the task was paused and waits for a resume.
+	QueueStatePaused QueueState = "PAUSED"
+)
+
+// QueuedTask is a task recorded in the download queue.
+type QueuedTask struct {
+	Request    TaskRequest
+	State      QueueState
+	Offset     int64
+	TotalBytes int64
+	EnqueuedAt time.Time
+	UpdatedAt  time.Time
+	// LeaseOwner is the worker executing the task; empty until a worker
+	// claimed it.
+	LeaseOwner     string
+	LeaseExpiresAt time.Time
+}
+
+// leasedByOther reports whether a worker other than owner holds an unexpired
+// lease on the task at now.
+func (qt *QueuedTask) leasedByOther(owner string, now time.Time) bool {
+	return qt.LeaseOwner != "" && qt.LeaseOwner != owner && qt.LeaseExpiresAt.After(now)
+}
+
+// Queue durably records tasks accepted by the download service until they
+// reach a terminal state, so that a restarted worker can re-adopt them.
+type Queue interface {
+	// Enqueue records a newly accepted task. Enqueueing a task that is already
+	// recorded is a no-op.
+	Enqueue(ctx context.Context, req TaskRequest) error
+	Get(ctx context.Context, taskID uint64) (*QueuedTask, error)
+	UpdateState(ctx context.Context, taskID uint64, state QueueState) error
+	UpdateOffset(ctx context.Context, taskID uint64, offset, totalBytes int64) error
+	// Remove deletes a task once it completed, failed or was cancelled.
+	Remove(ctx context.Context, taskID uint64) error
+	// ListUnfinished returns every recorded task, oldest first.
+	ListUnfinished(ctx context.Context) ([]*QueuedTask, error)
+	// Claim leases a recorded task to owner until expiresAt unless another
+	// owner holds a lease that has not expired at now. It reports whether
+	// owner holds the lease.
+	Claim(ctx context.Context, taskID uint64, owner string, now, expiresAt time.Time) (bool, error)
+	// RenewLeases extends every lease held by owner to expiresAt.
+	RenewLeases(ctx context.Context, owner string, expiresAt time.Time) error
+}
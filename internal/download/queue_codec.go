@@ -0,0 +1,103 @@
+package download
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+)
+
+// RequestCodec encodes the TaskRequests recorded in a Queue. The source
+// credentials of a request are sealed with AES-GCM so that they are never
+// stored in plaintext. A codec without a key leaves them out, in which case a
+// task re-adopted after a restart is downloaded without credentials.
+//
+// A nil *RequestCodec is a codec without a key.
+type RequestCodec struct {
+	aead cipher.AEAD
+}
+
+// NewRequestCodec returns a codec sealing credentials with key, which must be
+// 16, 24 or 32 bytes long. An empty key returns a codec without a key.
+func NewRequestCodec(key []byte) (*RequestCodec, error) {
+	if len(key) == 0 {
+		return &RequestCodec{}, nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("queue key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestCodec{aead: aead}, nil
+}
+
+// queuedRequest is the recorded form of a TaskRequest.
+type queuedRequest struct {
+	TaskRequest
+	// SourceAuth shadows TaskRequest.SourceAuth with the sealed credentials.
+	// Requests recorded before credentials were sealed hold them as an
+	// object.
+	SourceAuth json.RawMessage `json:",omitempty"`
+}
+
+// Encode returns the recorded form of req.
+func (c *RequestCodec) Encode(req TaskRequest) ([]byte, error) {
+	qr := queuedRequest{TaskRequest: req}
+	qr.TaskRequest.SourceAuth = nil
+	if req.SourceAuth != nil && c != nil && c.aead != nil {
+		auth, err := json.Marshal(req.SourceAuth)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, c.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		if qr.SourceAuth, err = json.Marshal(c.aead.Seal(nonce, nonce, auth, nil)); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(qr)
+}
+
+// Decode returns the TaskRequest recorded as data.
+func (c *RequestCodec) Decode(data []byte) (TaskRequest, error) {
+	var qr queuedRequest
+	if err := json.Unmarshal(data, &qr); err != nil {
+		return TaskRequest{}, err
+	}
+	req := qr.TaskRequest
+	switch raw := bytes.TrimSpace(qr.SourceAuth); {
+	case len(raw) == 0 || bytes.Equal(raw, []byte("null")):
+	case raw[0] == '{':
+		if err := json.Unmarshal(raw, &req.SourceAuth); err != nil {
+			return TaskRequest{}, err
+		}
+	default:
+		if c == nil || c.aead == nil {
+			// Sealed with a key this worker was not given.
+			return req, nil
+		}
+		var sealed []byte
+		if err := json.Unmarshal(raw, &sealed); err != nil {
+			return TaskRequest{}, err
+		}
+		if len(sealed) < c.aead.NonceSize() {
+			return TaskRequest{}, fmt.Errorf("sealed source credentials of task %d are too short", req.TaskID)
+		}
+		nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+		auth, err := c.aead.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			return TaskRequest{}, fmt.Errorf("cannot unseal source credentials of task %d: %w", req.TaskID, err)
+		}
+		if err := json.Unmarshal(auth, &req.SourceAuth); err != nil {
+			return TaskRequest{}, err
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,56 @@
+package download
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestCodec_SealsSourceAuth(t *testing.T) {
+	req := TaskRequest{
+		TaskID:     7,
+		SourceURL:  "https://example.com/file.iso",
+		SourceAuth: &AuthConfig{Type: "basic", Username: "user", Password: "s3cret-password"},
+	}
+
+	codec, err := NewRequestCodec(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	data, err := codec.Encode(req)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret-password")
+	assert.NotContains(t, string(data), "user")
+
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, req, decoded)
+
+	// Another key cannot unseal the credentials.
+	other, err := NewRequestCodec(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = other.Decode(data)
+	assert.Error(t, err)
+}
+
+func TestRequestCodec_WithoutKeyLeavesSourceAuthOut(t *testing.T) {
+	req := TaskRequest{TaskID: 7, SourceAuth: &AuthConfig{Token: "s3cret-token"}}
+
+	var codec *RequestCodec
+	data, err := codec.Encode(req)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret-token")
+
+	decoded, err := codec.Decode(data)
+	require.NoError(t, err)
+	assert.Nil(t, decoded.SourceAuth)
+	assert.Equal(t, uint64(7), decoded.TaskID)
+}
+
+func TestRequestCodec_DecodesPlaintextSourceAuth(t *testing.T) {
+	// Requests recorded before the credentials were sealed.
+	data := []byte(`{"TaskID":7,"SourceAuth":{"Type":"bearer","Token":"token"}}`)
+	decoded, err := (&RequestCodec{}).Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, &AuthConfig{Type: "bearer", Token: "token"}, decoded.SourceAuth)
+}
@@ -13,6 +13,7 @@ type TaskRequest struct {
 	DownloadOptions *DownloadOptions
 	Metadata        map[string]any
 	Checksum        *ChecksumInfo
+	Priority        string
 	CreatedAt       time.Time
 }
 
@@ -30,7 +31,9 @@ type DownloadOptions struct {
 	Concurrency int
 	MaxSpeed    *int64
 	MaxRetries  int
-	Timeout     *int
+	// Timeout bounds the whole task in seconds; nil keeps the service's
+	// task timeout.
+	Timeout *int
 }
 
 // ChecksumInfo mirrors checksum info for internal use.
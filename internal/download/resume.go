@@ -0,0 +1,235 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// resumeValidator picks the validator used to resume a download of the
+// described source. Strong ETags are preferred; weak ETags cannot be used with
+// If-Range, so Last-Modified is used instead. An empty string means the
+// source cannot be resumed safely.
+func resumeValidator(metadata *FileMetadata) string {
+	if metadata == nil || metadata.Headers == nil {
+		return ""
+	}
+	if !strings.Contains(strings.ToLower(metadata.Headers["Accept-Ranges"]), "bytes") {
+		return ""
+	}
+	if etag := strings.TrimSpace(metadata.Headers["ETag"]); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return strings.TrimSpace(metadata.Headers["Last-Modified"])
+}
+
+// resumableReader streams a download and transparently reissues a ranged
+// request from the last byte read when the connection fails mid-stream.
+type resumableReader struct {
+	ctx        context.Context
+	downloader ResumableDownloader
+	url        string
+	auth       *AuthConfig
+	opts       DownloadOptions
+	validator  string
+
+	body       io.ReadCloser
+	offset     int64
+	total      int64
+	failures   int
+	maxRetries int
+	backoff    func(attempt int) time.Duration
+	onRetry    func(offset int64, err error)
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			body, total, err := r.downloader.DownloadFrom(r.ctx, r.url, r.auth, r.opts, r.offset, r.validator)
+			if err != nil {
+				if waitErr := r.fail(err); waitErr != nil {
+					return 0, waitErr
+				}
+				continue
+			}
+			r.body = body
+			if total > 0 {
+				r.total = total
+			}
+		}
+
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == nil {
+			return n, nil
+		}
+		if err == io.EOF && (r.total <= 0 || r.offset >= r.total) {
+			return n, io.EOF
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		_ = r.body.Close()
+		r.body = nil
+		if waitErr := r.fail(err); waitErr != nil {
+			return n, waitErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// fail records a failed read or reconnect and waits before the next attempt.
+// It returns a non-nil error when the download should not be retried.
+func (r *resumableReader) fail(err error) error {
+	if r.ctx.Err() != nil {
+		return r.ctx.Err()
+	}
+	if errors.IsError(err, ErrCodeSourceChanged) {
+		return err
+	}
+	r.failures++
+	if r.failures > r.maxRetries {
+		return fmt.Errorf("resuming at byte %d after %d attempts: %w", r.offset, r.maxRetries, err)
+	}
+	if r.onRetry != nil {
+		r.onRetry(r.offset, err)
+	}
+	select {
+	case <-time.After(r.backoff(r.failures)):
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+func (r *resumableReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// partialState is persisted next to a partial download so a later execution
+// of the same task can continue where the previous one stopped.
+type partialState struct {
+	TaskID    uint64    `json:"task_id"`
+	SourceURL string    `json:"source_url"`
+	Validator string    `json:"validator"`
+	TotalSize int64     `json:"total_size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// partialDownload is a partially downloaded object on local disk. The number
+// of bytes committed so far is the size of the data file.
+type partialDownload struct {
+	dataPath  string
+	statePath string
+	state     partialState
+	file      *os.File
+}
+
+// openPartialDownload opens (or starts) the partial download for a task. An
+// existing partial whose source or validator differ is discarded.
+func openPartialDownload(dir string, req TaskRequest, validator string, totalSize int64) (*partialDownload, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create partial download directory: %w", err)
+	}
+	p := &partialDownload{
+		dataPath:  filepath.Join(dir, fmt.Sprintf("%d.part", req.TaskID)),
+		statePath: filepath.Join(dir, fmt.Sprintf("%d.part.json", req.TaskID)),
+		state: partialState{
+			TaskID:    req.TaskID,
+			SourceURL: req.SourceURL,
+			Validator: validator,
+			TotalSize: totalSize,
+		},
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if !p.matchesExisting() {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(p.dataPath, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open partial download: %w", err)
+	}
+	p.file = file
+
+	if err := p.saveState(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *partialDownload) matchesExisting() bool {
+	data, err := os.ReadFile(p.statePath)
+	if err != nil {
+		return false
+	}
+	var existing partialState
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return false
+	}
+	return existing.SourceURL == p.state.SourceURL &&
+		existing.Validator == p.state.Validator &&
+		existing.TotalSize == p.state.TotalSize
+}
+
+func (p *partialDownload) saveState() error {
+	p.state.UpdatedAt = time.Now()
+	data, err := json.Marshal(p.state)
+	if err != nil {
+		return fmt.Errorf("encode partial download state: %w", err)
+	}
+	return os.WriteFile(p.statePath, data, 0o644)
+}
+
+// Offset returns the number of bytes committed to disk.
+func (p *partialDownload) Offset() (int64, error) {
+	stat, err := p.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return stat.Size(), nil
+}
+
+// Write appends downloaded bytes to the partial object.
+func (p *partialDownload) Write(b []byte) (int, error) { return p.file.Write(b) }
+
+// Reset discards all committed bytes.
+func (p *partialDownload) Reset() error {
+	if err := p.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := p.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// Commit flushes the partial object to disk.
+func (p *partialDownload) Commit() error { return p.file.Sync() }
+
+// Open returns a reader over the committed bytes.
+func (p *partialDownload) Open() (*os.File, error) { return os.Open(p.dataPath) }
+
+func (p *partialDownload) Close() error { return p.file.Close() }
+
+// Remove closes and deletes the partial object and its state.
+func (p *partialDownload) Remove() {
+	_ = p.file.Close()
+	_ = os.Remove(p.dataPath)
+	_ = os.Remove(p.statePath)
+}
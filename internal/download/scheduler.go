@@ -0,0 +1,173 @@
+package download
+
+import (
+	"container/heap"
+	"context"
+	"strings"
+	"sync"
+)
+
+// Task priority classes understood by the scheduler.
+const (
+	PriorityLow    = "LOW"
+	PriorityNormal = "NORMAL"
+	PriorityHigh   = "HIGH"
+)
+
+// defaultPriorityWeights is the share of download slots each priority class
+// receives relative to the others when tasks of several classes are waiting.
+var defaultPriorityWeights = map[string]float64{
+	PriorityLow:    1,
+	PriorityNormal: 2,
+	PriorityHigh:   4,
+}
+
+// normalizePriority maps an event priority to one of the known classes;
+// unknown or empty values are treated as NORMAL.
+func normalizePriority(priority string) string {
+	p := strings.ToUpper(strings.TrimSpace(priority))
+	if _, ok := defaultPriorityWeights[p]; ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+type flowKey struct {
+	accountID uint64
+	priority  string
+}
+
+// scheduler hands out a fixed number of download slots using start-time fair
+// queuing. Every (account, priority) pair is a flow with its own FIFO order;
+// flows are served in proportion to the weight of their priority class, so an
+// account with a large backlog cannot starve other accounts and low priority
+// work still makes progress while high priority work is waiting.
+type scheduler struct {
+	mu       sync.Mutex
+	capacity int
+	running  int
+	weights  map[string]float64
+
+	// vtime is the virtual start tag of the most recently dispatched task.
+	vtime float64
+	// finish holds the virtual finish tag of the last task of each flow.
+	finish  map[flowKey]float64
+	seq     uint64
+	waiting waiterHeap
+}
+
+type waiter struct {
+	start float64
+	seq   uint64
+	ready chan struct{}
+	index int
+}
+
+func newScheduler(capacity int) *scheduler {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &scheduler{
+		capacity: capacity,
+		weights:  defaultPriorityWeights,
+		finish:   make(map[flowKey]float64),
+	}
+}
+
+// Acquire blocks until a download slot is granted to the task or ctx is done.
+func (s *scheduler) Acquire(ctx context.Context, accountID uint64, priority string) error {
+	s.mu.Lock()
+	w := s.tag(flowKey{accountID: accountID, priority: normalizePriority(priority)})
+	if s.running < s.capacity && s.waiting.Len() == 0 {
+		s.running++
+		s.vtime = w.start
+		s.mu.Unlock()
+		return nil
+	}
+	heap.Push(&s.waiting, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// The slot was granted concurrently; hand it to the next task.
+			s.running--
+			s.dispatch()
+		default:
+			heap.Remove(&s.waiting, w.index)
+		}
+		return ctx.Err()
+	}
+}
+
+// Release returns a slot obtained by Acquire.
+func (s *scheduler) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.dispatch()
+}
+
+// tag assigns the virtual start tag of a new task of the given flow.
+func (s *scheduler) tag(key flowKey) *waiter {
+	start := max(s.vtime, s.finish[key])
+	s.finish[key] = start + 1/s.weights[key.priority]
+	s.seq++
+	return &waiter{start: start, seq: s.seq, ready: make(chan struct{})}
+}
+
+func (s *scheduler) dispatch() {
+	for s.running < s.capacity && s.waiting.Len() > 0 {
+		w := heap.Pop(&s.waiting).(*waiter)
+		s.running++
+		s.vtime = w.start
+		close(w.ready)
+	}
+	if s.waiting.Len() == 0 {
+		// Flows that finished before the current virtual time no longer
+		// affect scheduling.
+		for key, f := range s.finish {
+			if f <= s.vtime {
+				delete(s.finish, key)
+			}
+		}
+	}
+}
+
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].start != h[j].start {
+		return h[i].start < h[j].start
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
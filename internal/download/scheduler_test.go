@@ -0,0 +1,124 @@
+package download
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dispatchOrder fills the single slot of s, queues the given tasks in order
+// and returns the order in which they are granted the slot.
+func dispatchOrder(t *testing.T, s *scheduler, tasks []flowKey) []int {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.Acquire(ctx, 0, PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Acquire(ctx, task.accountID, task.priority); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			s.Release()
+		}()
+		waitForWaiters(t, s, i+1)
+	}
+	s.Release()
+	wg.Wait()
+	return order
+}
+
+func waitForWaiters(t *testing.T, s *scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		waiting := s.waiting.Len()
+		s.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d waiting tasks", n)
+}
+
+func TestSchedulerSharesSlotsFairlyBetweenAccounts(t *testing.T) {
+	var tasks []flowKey
+	for range 5 {
+		tasks = append(tasks, flowKey{accountID: 1, priority: PriorityNormal})
+	}
+	tasks = append(tasks, flowKey{accountID: 2, priority: PriorityNormal})
+
+	order := dispatchOrder(t, newScheduler(1), tasks)
+
+	// The single task of account 2 must not wait behind account 1's backlog.
+	if order[1] != 5 {
+		t.Fatalf("expected account 2 to be served second, got order %v", order)
+	}
+}
+
+func TestSchedulerWeightsPriorityClasses(t *testing.T) {
+	var tasks []flowKey
+	for range 4 {
+		tasks = append(tasks, flowKey{accountID: 1, priority: PriorityLow})
+	}
+	for range 8 {
+		tasks = append(tasks, flowKey{accountID: 1, priority: PriorityHigh})
+	}
+
+	order := dispatchOrder(t, newScheduler(1), tasks)
+
+	high := 0
+	for _, i := range order[:5] {
+		if tasks[i].priority == PriorityHigh {
+			high++
+		}
+	}
+	if high != 4 {
+		t.Fatalf("expected 4 of the first 5 slots to go to HIGH tasks, got %d (order %v)", high, order)
+	}
+	low := 0
+	for _, i := range order[:10] {
+		if tasks[i].priority == PriorityLow {
+			low++
+		}
+	}
+	if low != 2 {
+		t.Fatalf("expected LOW tasks to keep making progress, got %d of the first 10 slots (order %v)", low, order)
+	}
+}
+
+func TestSchedulerAcquireHonoursContext(t *testing.T) {
+	s := newScheduler(1)
+	if err := s.Acquire(context.Background(), 1, PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Acquire(ctx, 2, PriorityHigh); err == nil {
+		t.Fatal("expected Acquire to fail once the context is done")
+	}
+	if s.waiting.Len() != 0 {
+		t.Fatalf("expected cancelled waiter to be removed, %d left", s.waiting.Len())
+	}
+
+	s.Release()
+	if err := s.Acquire(context.Background(), 3, PriorityLow); err != nil {
+		t.Fatalf("expected slot to be free, got %v", err)
+	}
+}
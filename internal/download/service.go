@@ -7,13 +7,13 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"golang.org/x/sync/semaphore"
-
 	"github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/internal/storage"
@@ -26,9 +26,14 @@ type EventPublisher interface {
 	PublishTaskProgressUpdated(ctx context.Context, event events.TaskProgressUpdatedEvent) error
 	PublishTaskCompleted(ctx context.Context, event events.TaskCompletedEvent) error
 	PublishTaskFailed(ctx context.Context, event events.TaskFailedEvent) error
+	PublishTaskClaimed(ctx context.Context, event events.TaskClaimedEvent) error
 }
 
 type Service interface {
+	// SubmitTask accepts a task and returns once it has been recorded, or
+	// once it reached a terminal state when there is nothing to record it
+	// in. An error means the task has to be submitted again.
+	SubmitTask(ctx context.Context, req TaskRequest) error
 	ExecuteTask(ctx context.Context, req TaskRequest) error
 	PauseTask(ctx context.Context, taskID uint64) error
 	ResumeTask(ctx context.Context, taskID uint64) error
@@ -43,12 +48,45 @@ type Registrar interface {
 	RegisterDownloader(sourceType string, downloader Downloader)
 }
 
+// Recoverer is an optional interface implemented by the concrete service that
+// re-adopts tasks left unfinished in the queue by a previous run.
+type Recoverer interface {
+	Recover(ctx context.Context) error
+}
+
+// Worker is an optional interface implemented by the concrete service that
+// reports the worker ID it claims tasks under. Control events of the tasks it
+// claimed are published to the worker topics of that ID.
+type Worker interface {
+	WorkerID() string
+}
+
 type taskExecution struct {
 	task           TaskRequest
+	parent         context.Context
 	ctx            context.Context
 	cancelFunc     context.CancelFunc
+	cancelled      atomic.Bool
 	progress       Progress
 	progressReader *PausableProgressReader
+	// settled is set once the outcome of the task was published: it
+	// completed, failed or was cancelled.
+	settled atomic.Bool
+}
+
+// waitingTask is a claimed task waiting for a download slot. A pause or
+// cancel stops the wait through cancel.
+type waitingTask struct {
+	cancel    context.CancelFunc
+	paused    bool
+	cancelled bool
+}
+
+// interrupted reports whether the execution stopped because the service is
+// shutting down rather than because the task failed or was cancelled. Such
+// tasks stay in the queue and are re-adopted by Recover.
+func (e *taskExecution) interrupted() bool {
+	return e.parent.Err() != nil && !e.cancelled.Load()
 }
 
 type service struct {
@@ -58,12 +96,18 @@ type service struct {
 	publisher          EventPublisher
 	mu                 sync.RWMutex
 	activeTasks        map[uint64]*taskExecution
+	waitingTasks       map[uint64]*waitingTask
 	maxConcurrent      int
 	taskTimeOut        time.Duration
 	errorHandler       ErrorHandler
-	sem                *semaphore.Weighted
+	scheduler          *scheduler
 	lastProgressUpdate map[uint64]time.Time
 	progressMu         sync.Mutex
+	partialDir         string
+	queue              Queue
+	workerID           string
+	leaseTTL           time.Duration
+	backoff            func(attempt int) time.Duration
 }
 
 type (
@@ -95,46 +139,166 @@ func WithErrorHandler(errorHandler ErrorHandler) Option {
 	}
 }
 
+// WithPartialDir enables persistent partial downloads. Resumable sources are
+// first written to dir so that an interrupted task continues from the last
+// committed byte when it is executed again.
+func WithPartialDir(dir string) Option {
+	return func(s *service) {
+		s.partialDir = dir
+	}
+}
+
+// WithQueue records accepted tasks in a durable queue so that tasks
+// interrupted by a restart are re-adopted by Recover.
+func WithQueue(queue Queue) Option {
+	return func(s *service) {
+		s.queue = queue
+	}
+}
+
+// WithLease names the worker in the leases it holds on queued tasks and sets
+// how long a lease lasts without being renewed. The tasks of a worker that
+// stopped renewing its leases are taken over by the other workers sharing the
+// queue. The worker ID defaults to the host name and must differ between
+// workers.
+func WithLease(workerID string, ttl time.Duration) Option {
+	return func(s *service) {
+		if workerID != "" {
+			s.workerID = workerID
+		}
+		if ttl > 0 {
+			s.leaseTTL = ttl
+		}
+	}
+}
+
 func NewService(storageBackend storage.Backend, publisher EventPublisher, opts ...Option) *service {
 	s := &service{
 		downloaders:        make(map[string]Downloader),
 		storage:            storageBackend,
 		publisher:          publisher,
 		activeTasks:        make(map[uint64]*taskExecution),
+		waitingTasks:       make(map[uint64]*waitingTask),
 		lastProgressUpdate: make(map[uint64]time.Time),
 		taskTimeOut:        30 * time.Minute,
 		errorHandler:       func(ctx context.Context, err error) {},
 		maxConcurrent:      5,
 		storageType:        storage.TypeLocal,
+		workerID:           defaultWorkerID(),
+		leaseTTL:           30 * time.Second,
+		backoff:            retryBackoff,
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
-	s.sem = semaphore.NewWeighted(int64(s.maxConcurrent))
+	s.scheduler = newScheduler(s.maxConcurrent)
 
 	return s
 }
 
+func (s *service) WorkerID() string {
+	return s.workerID
+}
+
 func (s *service) RegisterDownloader(sourceType string, downloader Downloader) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.downloaders[sourceType] = downloader
 }
 
+// SubmitTask records the task in the queue, when one is configured, and
+// executes it in the background. Without a queue a crash would lose the task,
+// so it is executed before SubmitTask returns instead, which holds up
+// subscribers that wait for the ack of a message before delivering the next.
+func (s *service) SubmitTask(ctx context.Context, req TaskRequest) error {
+	if s.queue == nil {
+		settled, err := s.executeTask(ctx, req)
+		if !settled {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "task did not finish", Cause: err}
+		}
+		if err != nil {
+			s.errorHandler(ctx, fmt.Errorf("failed to execute task %d: %w", req.TaskID, err))
+		}
+		return nil
+	}
+
+	if err := s.queue.Enqueue(ctx, req); err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to enqueue task", Cause: err}
+	}
+
+	go func() {
+		if err := s.ExecuteTask(ctx, req); err != nil {
+			s.errorHandler(ctx, fmt.Errorf("failed to execute task %d: %w", req.TaskID, err))
+		}
+	}()
+	return nil
+}
+
 // ExecuteTask starts a download task based on an internal TaskRequest
 func (s *service) ExecuteTask(ctx context.Context, req TaskRequest) error {
-	if err := s.sem.Acquire(ctx, 1); err != nil {
-		return fmt.Errorf("failed to acquire semaphore: %w", err)
+	_, err := s.executeTask(ctx, req)
+	return err
+}
+
+// executeTask executes req and reports whether the task is settled: its
+// outcome was published, or another execution is responsible for it.
+func (s *service) executeTask(ctx context.Context, req TaskRequest) (bool, error) {
+	if s.queue != nil {
+		if err := s.queue.Enqueue(ctx, req); err != nil {
+			return false, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to enqueue task", Cause: err}
+		}
+		now := time.Now()
+		claimed, err := s.queue.Claim(ctx, req.TaskID, s.workerID, now, now.Add(s.leaseTTL))
+		if err != nil {
+			return false, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to claim task", Cause: err}
+		}
+		if !claimed {
+			return true, &errors.Error{Code: errors.ErrCodeConflict, Message: "task is leased by another worker"}
+		}
+	}
+
+	// Control events of the task are routed here from now on, also while it
+	// waits for a download slot.
+	s.publishClaimed(ctx, req.TaskID)
+	return s.runTask(ctx, req)
+}
+
+// runTask executes a task the worker claimed once a download slot is free.
+// A task paused or cancelled while it waits gives up the wait: a paused task
+// stays queued until it is resumed, a cancelled one is removed.
+func (s *service) runTask(ctx context.Context, req TaskRequest) (bool, error) {
+	s.mu.Lock()
+	if _, ok := s.activeTasks[req.TaskID]; ok || s.waitingTasks[req.TaskID] != nil {
+		s.mu.Unlock()
+		return true, &errors.Error{Code: errors.ErrCodeConflict, Message: "task already running"}
 	}
-	defer s.sem.Release(1)
+	waitCtx, stopWaiting := context.WithCancel(ctx)
+	defer stopWaiting()
+	wait := &waitingTask{cancel: stopWaiting}
+	s.waitingTasks[req.TaskID] = wait
+	s.mu.Unlock()
+
+	acquireErr := s.scheduler.Acquire(waitCtx, req.OfAccountID, req.Priority)
 
 	s.mu.Lock()
-	if _, ok := s.activeTasks[req.TaskID]; ok {
+	delete(s.waitingTasks, req.TaskID)
+	if wait.paused || wait.cancelled {
+		s.mu.Unlock()
+		if acquireErr == nil {
+			s.scheduler.Release()
+		}
+		if wait.cancelled {
+			s.dequeue(req.TaskID)
+		}
+		return true, nil
+	}
+	if acquireErr != nil {
 		s.mu.Unlock()
-		return &errors.Error{Code: errors.ErrCodeConflict, Message: "task already running"}
+		return false, fmt.Errorf("failed to acquire download slot: %w", acquireErr)
 	}
+	defer s.scheduler.Release()
 
 	downloader, exists := s.downloaders[req.SourceType]
 	if !exists {
@@ -143,13 +307,19 @@ func (s *service) ExecuteTask(ctx context.Context, req TaskRequest) error {
 			Code:    errors.ErrCodeInvalidInput,
 			Message: fmt.Sprintf("no downloader for source type %s", req.SourceType),
 		}
-		s.markTaskFailed(ctx, req.TaskID, dlErr)
-		return dlErr
+		settled := s.markTaskFailed(ctx, req.TaskID, dlErr)
+		s.dequeue(req.TaskID)
+		return settled, dlErr
 	}
 
-	taskCtx, cancel := context.WithTimeout(ctx, s.taskTimeOut)
+	timeout := s.taskTimeOut
+	if req.DownloadOptions != nil && req.DownloadOptions.Timeout != nil && *req.DownloadOptions.Timeout > 0 {
+		timeout = time.Duration(*req.DownloadOptions.Timeout) * time.Second
+	}
+	taskCtx, cancel := context.WithTimeout(ctx, timeout)
 	execution := &taskExecution{
 		task:       req,
+		parent:     ctx,
 		ctx:        taskCtx,
 		cancelFunc: cancel,
 		progress: Progress{
@@ -168,7 +338,153 @@ func (s *service) ExecuteTask(ctx context.Context, req TaskRequest) error {
 		s.mu.Unlock()
 	}()
 
-	return s.executeDownload(execution, downloader)
+	if s.queue != nil {
+		if err := s.queue.UpdateState(ctx, req.TaskID, QueueStateRunning); err != nil {
+			s.errorHandler(ctx, fmt.Errorf("failed to mark task %d running: %w", req.TaskID, err))
+		}
+	}
+
+	err := s.executeDownload(execution, downloader)
+	if !execution.interrupted() {
+		s.dequeue(req.TaskID)
+	}
+	return execution.settled.Load(), err
+}
+
+// Recover re-adopts the tasks a previous run left in the queue. It republishes
+// their status and executes them again in the background; paused tasks stay
+// paused until they are resumed. Until ctx is done it then renews the leases
+// of the worker and takes over the tasks of workers that stopped renewing
+// theirs.
+func (s *service) Recover(ctx context.Context) error {
+	if s.queue == nil {
+		return nil
+	}
+	if err := s.adopt(ctx, true); err != nil {
+		return err
+	}
+	go s.keepLeases(ctx)
+	return nil
+}
+
+// keepLeases renews the leases of the worker every third of their duration
+// and adopts the tasks whose lease expired.
+func (s *service) keepLeases(ctx context.Context) {
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.queue.RenewLeases(ctx, s.workerID, time.Now().Add(s.leaseTTL)); err != nil {
+			s.errorHandler(ctx, fmt.Errorf("failed to renew task leases: %w", err))
+		}
+		if err := s.adopt(ctx, false); err != nil {
+			s.errorHandler(ctx, err)
+		}
+	}
+}
+
+// adopt claims and executes the queued tasks no other worker holds a lease
+// on. On start-up these include the tasks the worker held before and tasks
+// never claimed; later tasks whose lease expired are taken over, as are the
+// worker's own tasks whose run was interrupted without the worker stopping.
+func (s *service) adopt(ctx context.Context, startup bool) error {
+	queued, err := s.queue.ListUnfinished(ctx)
+	if err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list queued tasks", Cause: err}
+	}
+
+	now := time.Now()
+	for _, qt := range queued {
+		if qt.leasedByOther(s.workerID, now) {
+			continue
+		}
+		// A task without a lease may be between being enqueued and claimed by
+		// the worker that accepted it.
+		if !startup && qt.LeaseExpiresAt.IsZero() {
+			continue
+		}
+		// The worker renews the leases of its own tasks, so one whose run was
+		// interrupted would otherwise never run again. Paused tasks wait for
+		// a resume.
+		if !startup && qt.LeaseOwner == s.workerID && (qt.State == QueueStatePaused || s.executing(qt.Request.TaskID)) {
+			continue
+		}
+		claimed, err := s.queue.Claim(ctx, qt.Request.TaskID, s.workerID, now, now.Add(s.leaseTTL))
+		if err != nil {
+			s.errorHandler(ctx, fmt.Errorf("failed to claim queued task %d: %w", qt.Request.TaskID, err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		// A resume or cancel has to reach this worker, which holds the
+		// partial download and the place of the task in the scheduler.
+		s.publishClaimed(ctx, qt.Request.TaskID)
+
+		status := events.StatusPending
+		if qt.State == QueueStatePaused {
+			status = events.StatusPaused
+		}
+		if err := s.publisher.PublishTaskStatusUpdated(ctx, events.TaskStatusUpdatedEvent{
+			TaskID:    qt.Request.TaskID,
+			Status:    status,
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			s.errorHandler(ctx, fmt.Errorf("failed to republish status of task %d: %w", qt.Request.TaskID, err))
+		}
+		if qt.Offset > 0 {
+			progress := Progress{DownloadedBytes: qt.Offset, TotalBytes: qt.TotalBytes, UpdatedAt: time.Now()}
+			if qt.TotalBytes > 0 {
+				progress.Progress = float64(qt.Offset) / float64(qt.TotalBytes) * 100
+			}
+			s.updateProgress(ctx, qt.Request.TaskID, progress)
+		}
+		if qt.State == QueueStatePaused {
+			continue
+		}
+
+		go func(req TaskRequest) {
+			if _, err := s.runTask(ctx, req); err != nil {
+				s.errorHandler(ctx, fmt.Errorf("failed to execute recovered task %d: %w", req.TaskID, err))
+			}
+		}(qt.Request)
+	}
+	return nil
+}
+
+// executing reports whether the task runs or waits for a download slot on
+// this worker.
+func (s *service) executing(taskID uint64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, active := s.activeTasks[taskID]
+	return active || s.waitingTasks[taskID] != nil
+}
+
+// publishClaimed records the worker as the owner of the task in the task
+// service. A failure is only reported: control events of the task then keep
+// going to the worker recorded before, if any.
+func (s *service) publishClaimed(ctx context.Context, taskID uint64) {
+	if err := s.publisher.PublishTaskClaimed(ctx, events.TaskClaimedEvent{
+		TaskID:    taskID,
+		WorkerID:  s.workerID,
+		ClaimedAt: time.Now(),
+	}); err != nil {
+		s.errorHandler(ctx, fmt.Errorf("failed to publish claim of task %d: %w", taskID, err))
+	}
+}
+
+func (s *service) dequeue(taskID uint64) {
+	if s.queue == nil {
+		return
+	}
+	if err := s.queue.Remove(context.Background(), taskID); err != nil {
+		s.errorHandler(context.Background(), fmt.Errorf("failed to remove task %d from queue: %w", taskID, err))
+	}
 }
 
 // executeDownload performs the actual download and storage
@@ -176,6 +492,16 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 	taskReq := execution.task
 	ctx := execution.ctx
 
+	var requestedChecksumType string
+	if taskReq.Checksum != nil {
+		requestedChecksumType = taskReq.Checksum.ChecksumType
+	}
+	hash, checksumType, err := newChecksumHash(requestedChecksumType)
+	if err != nil {
+		s.markTaskFailed(ctx, taskReq.TaskID, err)
+		return err
+	}
+
 	if err := s.publisher.PublishTaskStatusUpdated(ctx, events.TaskStatusUpdatedEvent{
 		TaskID:    taskReq.TaskID,
 		Status:    events.StatusDownloading,
@@ -207,6 +533,7 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 			Username: taskReq.SourceAuth.Username,
 			Password: taskReq.SourceAuth.Password,
 			Token:    taskReq.SourceAuth.Token,
+			Headers:  taskReq.SourceAuth.Headers,
 		}
 	}
 
@@ -222,12 +549,56 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 		downloadOpts.MaxRetries = maxRetries
 	}
 
+	var (
+		resumer   ResumableDownloader
+		validator string
+		partial   *partialDownload
+		offset    int64
+	)
+	if rd, ok := downloader.(ResumableDownloader); ok && downloader.SupportsResume() {
+		if validator = resumeValidator(metadata); validator != "" {
+			resumer = rd
+		}
+	}
+	if resumer != nil && s.partialDir != "" {
+		partial, err = openPartialDownload(s.partialDir, taskReq, validator, metadata.FileSize)
+		if err != nil {
+			s.errorHandler(ctx, fmt.Errorf("task %d: continuing without partial download: %w", taskReq.TaskID, err))
+			partial = nil
+		} else {
+			defer partial.Close()
+			if offset, err = partial.Offset(); err != nil {
+				offset = 0
+			}
+		}
+	}
+
 	var reader io.ReadCloser
 	var totalSize int64
 	var dlErr error
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		reader, totalSize, dlErr = downloader.Download(ctx, taskReq.SourceURL, sourceAuth, downloadOpts)
+		if offset > 0 {
+			reader, totalSize, dlErr = resumer.DownloadFrom(
+				ctx, taskReq.SourceURL, sourceAuth, downloadOpts, offset, validator,
+			)
+			if errors.IsError(dlErr, ErrCodeSourceChanged) {
+				// The partial object belongs to an older version of the source.
+				s.errorHandler(ctx, fmt.Errorf("task %d: discarding partial download: %w", taskReq.TaskID, dlErr))
+				if resetErr := partial.Reset(); resetErr != nil {
+					s.markTaskFailed(ctx, taskReq.TaskID, fmt.Errorf("failed to reset partial download: %w", resetErr))
+					return &errors.Error{
+						Code:    errors.ErrCodeInternal,
+						Message: "failed to reset partial download",
+						Cause:   resetErr,
+					}
+				}
+				offset = 0
+				reader, totalSize, dlErr = downloader.Download(ctx, taskReq.SourceURL, sourceAuth, downloadOpts)
+			}
+		} else {
+			reader, totalSize, dlErr = downloader.Download(ctx, taskReq.SourceURL, sourceAuth, downloadOpts)
+		}
 		if dlErr == nil {
 			break
 		}
@@ -246,35 +617,86 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 			ctx,
 			fmt.Errorf("downloading %s failed, retry %d/%d: %w", taskReq.SourceURL, attempt, maxRetries, dlErr),
 		)
-		backoff := time.Second * time.Duration(1<<attempt)
-		jitter := time.Duration(time.Now().UnixNano() % int64(time.Second))
 		select {
-		case <-time.After(backoff + jitter):
+		case <-time.After(s.backoff(attempt)):
 		case <-ctx.Done():
 			s.markTaskFailed(ctx, taskReq.TaskID, ctx.Err())
 			return &errors.Error{Code: errors.ErrCodeInternal, Message: "download cancelled", Cause: ctx.Err()}
 		}
 	}
+
+	// Once bytes are flowing, a dropped connection is resumed with a ranged
+	// request from the last byte read instead of failing the whole task.
+	if resumer != nil {
+		reader = &resumableReader{
+			ctx:        ctx,
+			downloader: resumer,
+			url:        taskReq.SourceURL,
+			auth:       sourceAuth,
+			opts:       downloadOpts,
+			validator:  validator,
+			body:       reader,
+			offset:     offset,
+			total:      totalSize,
+			maxRetries: maxRetries,
+			backoff:    s.backoff,
+			onRetry: func(at int64, err error) {
+				s.errorHandler(ctx, fmt.Errorf("downloading %s interrupted at byte %d, resuming: %w",
+					taskReq.SourceURL, at, err))
+			},
+		}
+	}
 	defer reader.Close()
 
 	execution.progress.TotalBytes = totalSize
+	execution.progress.DownloadedBytes = offset
 	s.updateProgress(ctx, taskReq.TaskID, execution.progress)
 
 	progressReader := NewPausableProgressReader(reader, func(bytesRead int64) {
 		p := execution.progress
 		if time.Since(p.UpdatedAt) >= DOWNLOAD_PROGRESS_UPDATE_INTERVAL {
-			p.DownloadedBytes = bytesRead
+			p.DownloadedBytes = offset + bytesRead
 			if totalSize > 0 {
-				p.Progress = float64(bytesRead) / float64(totalSize) * 100
+				p.Progress = float64(p.DownloadedBytes) / float64(totalSize) * 100
 			}
 			p.UpdatedAt = time.Now()
 			execution.progress = p
 			s.updateProgress(ctx, taskReq.TaskID, p)
+			if s.queue != nil {
+				if err := s.queue.UpdateOffset(ctx, taskReq.TaskID, p.DownloadedBytes, totalSize); err != nil {
+					s.errorHandler(ctx, fmt.Errorf("failed to record offset of task %d: %w", taskReq.TaskID, err))
+				}
+			}
 		}
 	})
 
 	execution.progressReader = progressReader
 
+	var storeReader io.Reader = progressReader
+	if partial != nil {
+		// Commit the bytes to the partial object first so that a failure, even
+		// across a retry of the task, continues from the last committed byte.
+		_, copyErr := io.Copy(partial, progressReader)
+		if commitErr := partial.Commit(); copyErr == nil {
+			copyErr = commitErr
+		}
+		if copyErr != nil {
+			if execution.cancelled.Load() || errors.IsError(copyErr, ErrCodeSourceChanged) {
+				partial.Remove()
+			}
+			s.markTaskFailed(ctx, taskReq.TaskID, fmt.Errorf("failed to download file: %w", copyErr))
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to download file", Cause: copyErr}
+		}
+
+		file, err := partial.Open()
+		if err != nil {
+			s.markTaskFailed(ctx, taskReq.TaskID, fmt.Errorf("failed to open partial download: %w", err))
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to open partial download", Cause: err}
+		}
+		defer file.Close()
+		storeReader = file
+	}
+
 	if err := s.publisher.PublishTaskStatusUpdated(ctx, events.TaskStatusUpdatedEvent{
 		TaskID:    taskReq.TaskID,
 		Status:    events.StatusStoring,
@@ -283,8 +705,7 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to publish task status", Cause: err}
 	}
 
-	hash := md5.New()
-	teeReader := io.TeeReader(progressReader, hash)
+	teeReader := io.TeeReader(storeReader, hash)
 
 	storageKey := s.generateStorageKey(taskReq, metadata.FileName)
 	if err := s.storage.Store(ctx, storageKey, teeReader, &storage.FileMetadata{
@@ -292,12 +713,33 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 		FileSize:     metadata.FileSize,
 		ContentType:  metadata.ContentType,
 		LastModified: time.Now(),
+		OwnerID:      taskReq.OfAccountID,
+		TaskID:       taskReq.TaskID,
 	}); err != nil {
+		if execution.cancelled.Load() && partial != nil {
+			partial.Remove()
+		}
 		s.markTaskFailed(ctx, taskReq.TaskID, fmt.Errorf("failed to store file: %w", err))
 		_ = s.storage.Delete(context.Background(), storageKey)
 		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to store file", Cause: err}
 	}
-	md5Hash := hex.EncodeToString(hash.Sum(nil))
+	if partial != nil {
+		partial.Remove()
+	}
+	sum := hash.Sum(nil)
+	if taskReq.Checksum != nil && strings.TrimSpace(taskReq.Checksum.ChecksumValue) != "" &&
+		!checksumMatches(taskReq.Checksum.ChecksumValue, sum) {
+		_ = s.storage.Delete(context.Background(), storageKey)
+		mismatchErr := &errors.Error{
+			Code: ErrCodeChecksumMismatch,
+			Message: fmt.Sprintf(
+				"checksum mismatch: expected %s %s, got %s",
+				checksumType, strings.TrimSpace(taskReq.Checksum.ChecksumValue), hex.EncodeToString(sum),
+			),
+		}
+		s.markTaskFailed(ctx, taskReq.TaskID, mismatchErr)
+		return mismatchErr
+	}
 
 	completedEvent := events.TaskCompletedEvent{
 		TaskID:      taskReq.TaskID,
@@ -305,18 +747,23 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 		FileSize:    totalSize,
 		ContentType: metadata.ContentType,
 		Checksum: &events.ChecksumInfo{
-			ChecksumType:  "md5",
-			ChecksumValue: md5Hash,
+			ChecksumType:  checksumType,
+			ChecksumValue: hex.EncodeToString(sum),
 		},
 		StorageType: s.storageType.String(),
 		StorageKey:  storageKey,
 		CompletedAt: time.Now(),
 	}
+	if metadata.Headers != nil {
+		completedEvent.SourceETag = strings.TrimSpace(metadata.Headers["ETag"])
+		completedEvent.SourceLastModified = strings.TrimSpace(metadata.Headers["Last-Modified"])
+	}
 
 	if err := s.publisher.PublishTaskCompleted(ctx, completedEvent); err != nil {
 		s.markTaskFailed(ctx, taskReq.TaskID, fmt.Errorf("failed to publish completion event: %w", err))
 		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to publish completion event", Cause: err}
 	}
+	execution.settled.Store(true)
 
 	execution.progress.DownloadedBytes = totalSize
 	execution.progress.Progress = 100.0
@@ -325,11 +772,26 @@ func (s *service) executeDownload(execution *taskExecution, downloader Downloade
 	return nil
 }
 
+// retryBackoff waits 2^attempt seconds plus up to a second of jitter.
+func retryBackoff(attempt int) time.Duration {
+	backoff := time.Second * time.Duration(1<<attempt)
+	jitter := time.Duration(time.Now().UnixNano() % int64(time.Second))
+	return backoff + jitter
+}
+
 // PauseTask pauses a running task
 func (s *service) PauseTask(ctx context.Context, taskID uint64) error {
-	s.mu.RLock()
+	s.mu.Lock()
 	execution, exists := s.activeTasks[taskID]
-	s.mu.RUnlock()
+	// Without a queue a task waiting for a slot could not be resumed.
+	if wait := s.waitingTasks[taskID]; wait != nil && s.queue != nil {
+		wait.paused = true
+		wait.cancel()
+		s.mu.Unlock()
+		s.updateQueueState(ctx, taskID, QueueStatePaused)
+		return nil
+	}
+	s.mu.Unlock()
 
 	if !exists {
 		return &errors.Error{Code: errors.ErrCodeNotFound, Message: "task not found in active tasks"}
@@ -340,6 +802,7 @@ func (s *service) PauseTask(ctx context.Context, taskID uint64) error {
 	}
 
 	execution.progressReader.Pause()
+	s.updateQueueState(ctx, taskID, QueueStatePaused)
 	return nil
 }
 
@@ -350,6 +813,10 @@ func (s *service) ResumeTask(ctx context.Context, taskID uint64) error {
 	s.mu.RUnlock()
 
 	if !exists {
+		// A task paused before a restart is only in the queue.
+		if qt := s.queuedTask(ctx, taskID); qt != nil && qt.State == QueueStatePaused {
+			return s.SubmitTask(ctx, qt.Request)
+		}
 		return &errors.Error{Code: errors.ErrCodeNotFound, Message: "task not found in active tasks"}
 	}
 
@@ -358,19 +825,31 @@ func (s *service) ResumeTask(ctx context.Context, taskID uint64) error {
 	}
 
 	execution.progressReader.Resume()
+	s.updateQueueState(ctx, taskID, QueueStateRunning)
 	return nil
 }
 
 // CancelTask cancels a running task
 func (s *service) CancelTask(ctx context.Context, taskID uint64) error {
-	s.mu.RLock()
+	s.mu.Lock()
 	execution, exists := s.activeTasks[taskID]
-	s.mu.RUnlock()
+	if wait := s.waitingTasks[taskID]; wait != nil {
+		wait.cancelled = true
+		wait.cancel()
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
 
 	if !exists {
+		if qt := s.queuedTask(ctx, taskID); qt != nil {
+			s.dequeue(taskID)
+			return nil
+		}
 		return &errors.Error{Code: errors.ErrCodeNotFound, Message: "task not found in active tasks"}
 	}
 
+	execution.cancelled.Store(true)
 	execution.cancelFunc()
 	return nil
 }
@@ -391,21 +870,59 @@ func (s *service) GetActiveTaskCount(ctx context.Context) int {
 	return len(s.activeTasks)
 }
 
-func (s *service) markTaskFailed(ctx context.Context, taskID uint64, err error) {
+func (s *service) queuedTask(ctx context.Context, taskID uint64) *QueuedTask {
+	if s.queue == nil {
+		return nil
+	}
+	qt, err := s.queue.Get(ctx, taskID)
+	if err != nil {
+		return nil
+	}
+	return qt
+}
+
+func (s *service) updateQueueState(ctx context.Context, taskID uint64, state QueueState) {
+	if s.queue == nil {
+		return
+	}
+	if err := s.queue.UpdateState(ctx, taskID, state); err != nil {
+		s.errorHandler(ctx, fmt.Errorf("failed to update queue state of task %d: %w", taskID, err))
+	}
+}
+
+// markTaskFailed publishes the failure of a task and reports whether it was
+// published.
+func (s *service) markTaskFailed(ctx context.Context, taskID uint64, err error) bool {
 	s.errorHandler(ctx, err)
 
+	s.mu.RLock()
+	execution, active := s.activeTasks[taskID]
+	s.mu.RUnlock()
+	if active && execution.interrupted() {
+		// The task stays queued and is re-adopted on the next start.
+		return false
+	}
+
 	failEvent := events.TaskFailedEvent{
 		TaskID:   taskID,
 		Error:    err.Error(),
 		FailedAt: time.Now(),
 	}
+	if svcErr := errors.AsError(err); svcErr != nil {
+		failEvent.ErrorCode = string(svcErr.Code)
+	}
 
 	if publishErr := s.publisher.PublishTaskFailed(context.Background(), failEvent); publishErr != nil {
 		s.errorHandler(
 			context.Background(),
 			fmt.Errorf("failed to publish task failed event for task %d: %w", taskID, publishErr),
 		)
+		return false
+	}
+	if active {
+		execution.settled.Store(true)
 	}
+	return true
 }
 
 func (s *service) updateProgress(ctx context.Context, taskID uint64, progress Progress) {
@@ -535,3 +1052,10 @@ func (pr *PausableProgressReader) Resume() {
 	pr.isPaused = false
 	pr.resumeCond.Signal()
 }
+
+func defaultWorkerID() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "download"
+}
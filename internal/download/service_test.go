@@ -3,10 +3,14 @@ package download
 import (
 	"context"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/internal/storage"
 )
@@ -68,12 +72,16 @@ func (d *fakeDownloader) SupportsResume() bool { return false }
 
 type fakeStorage struct {
 	metadata *storage.FileMetadata
+	data     []byte
+	deleted  []string
 }
 
 func (s *fakeStorage) Store(ctx context.Context, key string, reader io.Reader, metadata *storage.FileMetadata) error {
-	if _, err := io.Copy(io.Discard, reader); err != nil {
+	data, err := io.ReadAll(reader)
+	if err != nil {
 		return err
 	}
+	s.data = data
 	s.metadata = metadata
 	return nil
 }
@@ -91,10 +99,16 @@ func (s *fakeStorage) GetInfo(ctx context.Context, key string) (*storage.FileMet
 }
 
 func (s *fakeStorage) Exists(ctx context.Context, key string) (bool, error) { return false, nil }
-func (s *fakeStorage) Delete(ctx context.Context, key string) error         { return nil }
+func (s *fakeStorage) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return nil
+}
 
 type fakePublisher struct {
+	mu        sync.Mutex
 	completed *events.TaskCompletedEvent
+	failed    *events.TaskFailedEvent
+	claimed   []events.TaskClaimedEvent
 }
 
 func (p *fakePublisher) PublishTaskStatusUpdated(ctx context.Context, event events.TaskStatusUpdatedEvent) error {
@@ -111,9 +125,28 @@ func (p *fakePublisher) PublishTaskCompleted(ctx context.Context, event events.T
 }
 
 func (p *fakePublisher) PublishTaskFailed(ctx context.Context, event events.TaskFailedEvent) error {
+	p.failed = &event
 	return nil
 }
 
+func (p *fakePublisher) PublishTaskClaimed(ctx context.Context, event events.TaskClaimedEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.claimed = append(p.claimed, event)
+	return nil
+}
+
+func (p *fakePublisher) claimedTask(taskID uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.claimed {
+		if e.TaskID == taskID {
+			return true
+		}
+	}
+	return false
+}
+
 func TestExecuteTaskAttemptsDownloadWhenMaxRetriesZero(t *testing.T) {
 	store := &fakeStorage{}
 	pub := &fakePublisher{}
@@ -142,3 +175,651 @@ func TestExecuteTaskAttemptsDownloadWhenMaxRetriesZero(t *testing.T) {
 		t.Fatalf("expected storage type %q, got %q", storage.TypeMinio, pub.completed.StorageType)
 	}
 }
+
+func TestExecuteTaskVerifiesRequestedChecksum(t *testing.T) {
+	store := &fakeStorage{}
+	pub := &fakePublisher{}
+	svc := NewService(store, pub)
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+
+	// sha256("content")
+	want := "ED7002B439E9AC845F22357D822BAC1444730FBDB6016D3EC9432297B9EC9F73"
+	err := svc.ExecuteTask(context.Background(), TaskRequest{
+		TaskID:     12,
+		SourceURL:  "https://example.com/file.txt",
+		SourceType: "HTTP",
+		Checksum:   &ChecksumInfo{ChecksumType: "SHA-256", ChecksumValue: want},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask() error = %v", err)
+	}
+	if pub.completed == nil {
+		t.Fatal("expected completion event")
+	}
+	if pub.completed.Checksum.ChecksumType != "sha256" {
+		t.Fatalf("expected sha256 checksum, got %q", pub.completed.Checksum.ChecksumType)
+	}
+	if !strings.EqualFold(pub.completed.Checksum.ChecksumValue, want) {
+		t.Fatalf("expected checksum %s, got %s", want, pub.completed.Checksum.ChecksumValue)
+	}
+	if len(store.deleted) != 0 {
+		t.Fatalf("expected stored object to be kept, deleted %v", store.deleted)
+	}
+}
+
+func TestExecuteTaskChecksumMismatchDeletesObject(t *testing.T) {
+	store := &fakeStorage{}
+	pub := &fakePublisher{}
+	svc := NewService(store, pub)
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+
+	err := svc.ExecuteTask(context.Background(), TaskRequest{
+		TaskID:     13,
+		SourceURL:  "https://example.com/file.txt",
+		SourceType: "HTTP",
+		Checksum:   &ChecksumInfo{ChecksumType: "crc32c", ChecksumValue: "deadbeef"},
+	})
+	if !errors.IsError(err, ErrCodeChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch error, got %v", err)
+	}
+	if pub.completed != nil {
+		t.Fatal("expected no completion event")
+	}
+	if pub.failed == nil || pub.failed.ErrorCode != string(ErrCodeChecksumMismatch) {
+		t.Fatalf("expected failed event with checksum mismatch code, got %+v", pub.failed)
+	}
+	if len(store.deleted) != 1 {
+		t.Fatalf("expected stored object to be deleted, got %v", store.deleted)
+	}
+}
+
+func TestExecuteTaskRejectsUnsupportedChecksumType(t *testing.T) {
+	store := &fakeStorage{}
+	pub := &fakePublisher{}
+	dl := &fakeDownloader{}
+	svc := NewService(store, pub)
+	svc.RegisterDownloader("HTTP", dl)
+
+	err := svc.ExecuteTask(context.Background(), TaskRequest{
+		TaskID:     14,
+		SourceURL:  "https://example.com/file.txt",
+		SourceType: "HTTP",
+		Checksum:   &ChecksumInfo{ChecksumType: "whirlpool", ChecksumValue: "00"},
+	})
+	if !errors.IsError(err, errors.ErrCodeInvalidInput) {
+		t.Fatalf("expected invalid input error, got %v", err)
+	}
+	if dl.downloads != 0 {
+		t.Fatalf("expected no download attempt, got %d", dl.downloads)
+	}
+}
+
+// flakyReader returns data and then fails as if the connection dropped.
+type flakyReader struct {
+	data []byte
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+type rangeCall struct {
+	offset    int64
+	validator string
+}
+
+// fakeResumableDownloader serves content but drops the first connection after
+// dropAfter bytes.
+type fakeResumableDownloader struct {
+	content    string
+	dropAfter  int
+	rangeCalls []rangeCall
+}
+
+func (d *fakeResumableDownloader) GetFileInfo(ctx context.Context, rawURL string, auth *AuthConfig) (*FileMetadata, error) {
+	return &FileMetadata{
+		FileName:    "file.bin",
+		FileSize:    int64(len(d.content)),
+		ContentType: "application/octet-stream",
+		Headers:     map[string]string{"Accept-Ranges": "bytes", "ETag": `"v1"`},
+	}, nil
+}
+
+func (d *fakeResumableDownloader) Download(
+	ctx context.Context,
+	rawURL string,
+	auth *AuthConfig,
+	opts DownloadOptions,
+) (io.ReadCloser, int64, error) {
+	return io.NopCloser(&flakyReader{data: []byte(d.content[:d.dropAfter])}), int64(len(d.content)), nil
+}
+
+func (d *fakeResumableDownloader) DownloadFrom(
+	ctx context.Context,
+	rawURL string,
+	auth *AuthConfig,
+	opts DownloadOptions,
+	offset int64,
+	validator string,
+) (io.ReadCloser, int64, error) {
+	d.rangeCalls = append(d.rangeCalls, rangeCall{offset: offset, validator: validator})
+	return io.NopCloser(strings.NewReader(d.content[offset:])), int64(len(d.content)), nil
+}
+
+func (d *fakeResumableDownloader) SupportsResume() bool { return true }
+
+func TestExecuteTaskResumesAfterConnectionDrop(t *testing.T) {
+	store := &fakeStorage{}
+	pub := &fakePublisher{}
+	dl := &fakeResumableDownloader{content: "0123456789abcdef", dropAfter: 6}
+	svc := NewService(store, pub)
+	svc.backoff = func(int) time.Duration { return 0 }
+	svc.RegisterDownloader("HTTP", dl)
+
+	err := svc.ExecuteTask(context.Background(), TaskRequest{
+		TaskID:     21,
+		SourceURL:  "https://example.com/file.bin",
+		SourceType: "HTTP",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask() error = %v", err)
+	}
+	if string(store.data) != dl.content {
+		t.Fatalf("expected stored content %q, got %q", dl.content, store.data)
+	}
+	if len(dl.rangeCalls) != 1 || dl.rangeCalls[0] != (rangeCall{offset: 6, validator: `"v1"`}) {
+		t.Fatalf("expected one ranged request from byte 6 with validator, got %+v", dl.rangeCalls)
+	}
+	if pub.completed == nil {
+		t.Fatal("expected completion event")
+	}
+}
+
+func TestExecuteTaskContinuesPartialDownloadAcrossExecutions(t *testing.T) {
+	dir := t.TempDir()
+	content := "0123456789abcdef"
+
+	// The first execution is not allowed to retry, so it fails mid-stream and
+	// leaves the committed bytes behind.
+	first := &fakeResumableDownloader{content: content, dropAfter: 10}
+	svc := NewService(&fakeStorage{}, &fakePublisher{}, WithPartialDir(dir))
+	svc.backoff = func(int) time.Duration { return 0 }
+	svc.RegisterDownloader("HTTP", first)
+	req := TaskRequest{
+		TaskID:          22,
+		SourceURL:       "https://example.com/file.bin",
+		SourceType:      "HTTP",
+		DownloadOptions: &DownloadOptions{MaxRetries: 0},
+	}
+	if err := svc.ExecuteTask(context.Background(), req); err == nil {
+		t.Fatal("expected first execution to fail")
+	}
+
+	store := &fakeStorage{}
+	second := &fakeResumableDownloader{content: content, dropAfter: 0}
+	svc = NewService(store, &fakePublisher{}, WithPartialDir(dir))
+	svc.RegisterDownloader("HTTP", second)
+	if err := svc.ExecuteTask(context.Background(), req); err != nil {
+		t.Fatalf("ExecuteTask() error = %v", err)
+	}
+	if string(store.data) != content {
+		t.Fatalf("expected stored content %q, got %q", content, store.data)
+	}
+	if len(second.rangeCalls) != 1 || second.rangeCalls[0].offset != 10 {
+		t.Fatalf("expected retry to continue from byte 10, got %+v", second.rangeCalls)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected partial download to be removed, found %d entries", len(entries))
+	}
+}
+
+type fakeQueue struct {
+	mu      sync.Mutex
+	tasks   map[uint64]*QueuedTask
+	removed chan uint64
+}
+
+func newFakeQueue() *fakeQueue {
+	return &fakeQueue{tasks: make(map[uint64]*QueuedTask), removed: make(chan uint64, 8)}
+}
+
+func (q *fakeQueue) Enqueue(ctx context.Context, req TaskRequest) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.tasks[req.TaskID]; !ok {
+		q.tasks[req.TaskID] = &QueuedTask{Request: req, State: QueueStateQueued}
+	}
+	return nil
+}
+
+func (q *fakeQueue) Get(ctx context.Context, taskID uint64) (*QueuedTask, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	qt, ok := q.tasks[taskID]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	cp := *qt
+	return &cp, nil
+}
+
+func (q *fakeQueue) UpdateState(ctx context.Context, taskID uint64, state QueueState) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if qt, ok := q.tasks[taskID]; ok {
+		qt.State = state
+	}
+	return nil
+}
+
+func (q *fakeQueue) UpdateOffset(ctx context.Context, taskID uint64, offset, totalBytes int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if qt, ok := q.tasks[taskID]; ok {
+		qt.Offset, qt.TotalBytes = offset, totalBytes
+	}
+	return nil
+}
+
+func (q *fakeQueue) Remove(ctx context.Context, taskID uint64) error {
+	q.mu.Lock()
+	delete(q.tasks, taskID)
+	q.mu.Unlock()
+	q.removed <- taskID
+	return nil
+}
+
+func (q *fakeQueue) ListUnfinished(ctx context.Context) ([]*QueuedTask, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var tasks []*QueuedTask
+	for _, qt := range q.tasks {
+		cp := *qt
+		tasks = append(tasks, &cp)
+	}
+	return tasks, nil
+}
+
+func (q *fakeQueue) Claim(ctx context.Context, taskID uint64, owner string, now, expiresAt time.Time) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	qt, ok := q.tasks[taskID]
+	if !ok || qt.leasedByOther(owner, now) {
+		return false, nil
+	}
+	qt.LeaseOwner, qt.LeaseExpiresAt = owner, expiresAt
+	return true, nil
+}
+
+func (q *fakeQueue) RenewLeases(ctx context.Context, owner string, expiresAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, qt := range q.tasks {
+		if qt.LeaseOwner == owner {
+			qt.LeaseExpiresAt = expiresAt
+		}
+	}
+	return nil
+}
+
+func TestExecuteTaskRemovesCompletedTaskFromQueue(t *testing.T) {
+	queue := newFakeQueue()
+	svc := NewService(&fakeStorage{}, &fakePublisher{}, WithQueue(queue))
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+
+	err := svc.ExecuteTask(context.Background(), TaskRequest{
+		TaskID:     31,
+		SourceURL:  "https://example.com/file.txt",
+		SourceType: "HTTP",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask() error = %v", err)
+	}
+	if _, err := queue.Get(context.Background(), 31); err != errors.ErrNotFound {
+		t.Fatalf("expected task to be removed from queue, got %v", err)
+	}
+}
+
+func TestExecuteTaskKeepsInterruptedTaskQueued(t *testing.T) {
+	queue := newFakeQueue()
+	pub := &fakePublisher{}
+	svc := NewService(&fakeStorage{}, pub, WithQueue(queue))
+	svc.RegisterDownloader("HTTP", &fakeResumableDownloader{content: "0123456789", dropAfter: 4})
+	svc.backoff = func(int) time.Duration { return time.Hour }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	err := svc.ExecuteTask(ctx, TaskRequest{
+		TaskID:          32,
+		SourceURL:       "https://example.com/file.bin",
+		SourceType:      "HTTP",
+		DownloadOptions: &DownloadOptions{MaxRetries: 3},
+	})
+	if err == nil {
+		t.Fatal("expected interrupted execution to fail")
+	}
+	if pub.failed != nil {
+		t.Fatalf("expected no failure event for interrupted task, got %+v", pub.failed)
+	}
+	qt, err := queue.Get(context.Background(), 32)
+	if err != nil {
+		t.Fatalf("expected task to stay queued, got %v", err)
+	}
+	if qt.State != QueueStateRunning {
+		t.Fatalf("expected queue state %s, got %s", QueueStateRunning, qt.State)
+	}
+}
+
+func TestRecoverExecutesUnfinishedTasksAndSkipsPaused(t *testing.T) {
+	queue := newFakeQueue()
+	ctx := context.Background()
+	running := TaskRequest{TaskID: 41, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	paused := TaskRequest{TaskID: 42, SourceURL: "https://example.com/b.txt", SourceType: "HTTP"}
+	_ = queue.Enqueue(ctx, running)
+	_ = queue.UpdateState(ctx, running.TaskID, QueueStateRunning)
+	_ = queue.Enqueue(ctx, paused)
+	_ = queue.UpdateState(ctx, paused.TaskID, QueueStatePaused)
+
+	dl := &fakeDownloader{}
+	svc := NewService(&fakeStorage{}, &fakePublisher{}, WithQueue(queue))
+	svc.RegisterDownloader("HTTP", dl)
+
+	if err := svc.Recover(ctx); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+
+	select {
+	case id := <-queue.removed:
+		if id != running.TaskID {
+			t.Fatalf("expected task %d to complete, got %d", running.TaskID, id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("recovered task did not complete")
+	}
+
+	qt, err := queue.Get(ctx, paused.TaskID)
+	if err != nil {
+		t.Fatalf("expected paused task to stay queued, got %v", err)
+	}
+	if qt.State != QueueStatePaused {
+		t.Fatalf("expected paused task to stay paused, got %s", qt.State)
+	}
+}
+
+func TestExecuteTaskSkipsTaskLeasedByAnotherWorker(t *testing.T) {
+	queue := newFakeQueue()
+	ctx := context.Background()
+	req := TaskRequest{TaskID: 51, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	_ = queue.Enqueue(ctx, req)
+	_, _ = queue.Claim(ctx, req.TaskID, "worker-b", time.Now(), time.Now().Add(time.Minute))
+
+	dl := &fakeDownloader{}
+	pub := &fakePublisher{}
+	svc := NewService(&fakeStorage{}, pub, WithQueue(queue), WithLease("worker-a", time.Minute))
+	svc.RegisterDownloader("HTTP", dl)
+
+	err := svc.ExecuteTask(ctx, req)
+	if !errors.IsError(err, errors.ErrCodeConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if dl.downloads != 0 {
+		t.Fatalf("expected no download, got %d", dl.downloads)
+	}
+	if len(pub.claimed) != 0 {
+		t.Fatalf("expected no claim, got %+v", pub.claimed)
+	}
+}
+
+func TestExecuteTaskPublishesClaimOfTheWorker(t *testing.T) {
+	pub := &fakePublisher{}
+	svc := NewService(&fakeStorage{}, pub, WithQueue(newFakeQueue()), WithLease("worker-a", time.Minute))
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+
+	err := svc.ExecuteTask(context.Background(), TaskRequest{
+		TaskID:     52,
+		SourceURL:  "https://example.com/a.txt",
+		SourceType: "HTTP",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTask() error = %v", err)
+	}
+	if len(pub.claimed) != 1 || pub.claimed[0].TaskID != 52 || pub.claimed[0].WorkerID != "worker-a" {
+		t.Fatalf("expected task 52 to be claimed by worker-a, got %+v", pub.claimed)
+	}
+}
+
+// startWaitingTask executes req on svc, whose only download slot is taken,
+// and returns once the task is claimed and waits for the slot.
+func startWaitingTask(t *testing.T, svc *service, pub *fakePublisher, req TaskRequest) <-chan error {
+	t.Helper()
+	if err := svc.scheduler.Acquire(context.Background(), 0, ""); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	t.Cleanup(svc.scheduler.Release)
+
+	done := make(chan error, 1)
+	go func() { done <- svc.ExecuteTask(context.Background(), req) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		svc.mu.RLock()
+		waiting := svc.waitingTasks[req.TaskID] != nil
+		svc.mu.RUnlock()
+		if waiting {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("task did not wait for a download slot")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !pub.claimedTask(req.TaskID) {
+		t.Fatalf("expected claim of task %d to be published before it gets a slot", req.TaskID)
+	}
+	return done
+}
+
+func TestPauseTaskStopsWaitForSlot(t *testing.T) {
+	queue := newFakeQueue()
+	pub := &fakePublisher{}
+	dl := &fakeDownloader{}
+	svc := NewService(&fakeStorage{}, pub, WithQueue(queue), WithMaxConcurrent(1))
+	svc.RegisterDownloader("HTTP", dl)
+	req := TaskRequest{TaskID: 53, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	done := startWaitingTask(t, svc, pub, req)
+
+	if err := svc.PauseTask(context.Background(), req.TaskID); err != nil {
+		t.Fatalf("PauseTask() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ExecuteTask() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("paused task kept waiting for a slot")
+	}
+	if dl.downloads != 0 {
+		t.Fatalf("expected paused task not to download, got %d downloads", dl.downloads)
+	}
+	qt, err := queue.Get(context.Background(), req.TaskID)
+	if err != nil {
+		t.Fatalf("expected paused task to stay queued, got %v", err)
+	}
+	if qt.State != QueueStatePaused {
+		t.Fatalf("expected queue state %s, got %s", QueueStatePaused, qt.State)
+	}
+}
+
+func TestCancelTaskStopsWaitForSlot(t *testing.T) {
+	queue := newFakeQueue()
+	pub := &fakePublisher{}
+	dl := &fakeDownloader{}
+	svc := NewService(&fakeStorage{}, pub, WithQueue(queue), WithMaxConcurrent(1))
+	svc.RegisterDownloader("HTTP", dl)
+	req := TaskRequest{TaskID: 54, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	done := startWaitingTask(t, svc, pub, req)
+
+	if err := svc.CancelTask(context.Background(), req.TaskID); err != nil {
+		t.Fatalf("CancelTask() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ExecuteTask() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelled task kept waiting for a slot")
+	}
+	if dl.downloads != 0 {
+		t.Fatalf("expected cancelled task not to download, got %d downloads", dl.downloads)
+	}
+	if _, err := queue.Get(context.Background(), req.TaskID); err != errors.ErrNotFound {
+		t.Fatalf("expected cancelled task to be removed from queue, got %v", err)
+	}
+}
+
+func TestRecoverTakesOverExpiredLeasesOnly(t *testing.T) {
+	queue := newFakeQueue()
+	ctx := context.Background()
+	expired := TaskRequest{TaskID: 61, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	live := TaskRequest{TaskID: 62, SourceURL: "https://example.com/b.txt", SourceType: "HTTP"}
+	_ = queue.Enqueue(ctx, expired)
+	_, _ = queue.Claim(ctx, expired.TaskID, "worker-b", time.Now(), time.Now().Add(-time.Second))
+	_ = queue.Enqueue(ctx, live)
+	_, _ = queue.Claim(ctx, live.TaskID, "worker-b", time.Now(), time.Now().Add(time.Minute))
+
+	svc := NewService(&fakeStorage{}, &fakePublisher{}, WithQueue(queue), WithLease("worker-a", time.Minute))
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+
+	recoverCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := svc.Recover(recoverCtx); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+
+	select {
+	case id := <-queue.removed:
+		if id != expired.TaskID {
+			t.Fatalf("expected task %d to complete, got %d", expired.TaskID, id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("task with an expired lease was not taken over")
+	}
+
+	qt, err := queue.Get(ctx, live.TaskID)
+	if err != nil {
+		t.Fatalf("expected leased task to stay queued, got %v", err)
+	}
+	if qt.LeaseOwner != "worker-b" {
+		t.Fatalf("expected lease of worker-b to be kept, got %q", qt.LeaseOwner)
+	}
+}
+
+func TestAdoptReexecutesInterruptedTaskOfTheWorker(t *testing.T) {
+	queue := newFakeQueue()
+	dl := &fakeDownloader{}
+	svc := NewService(&fakeStorage{}, &fakePublisher{}, WithQueue(queue), WithLease("worker-a", time.Minute), WithMaxConcurrent(1))
+	svc.RegisterDownloader("HTTP", dl)
+	req := TaskRequest{TaskID: 55, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+
+	// The run is interrupted while it waits for the only slot.
+	if err := svc.scheduler.Acquire(context.Background(), 0, ""); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := svc.ExecuteTask(ctx, req); err == nil {
+		t.Fatal("expected interrupted execution to fail")
+	}
+	svc.scheduler.Release()
+	qt, err := queue.Get(context.Background(), req.TaskID)
+	if err != nil {
+		t.Fatalf("expected interrupted task to stay queued, got %v", err)
+	}
+	if qt.LeaseOwner != "worker-a" {
+		t.Fatalf("expected task to stay leased by worker-a, got %q", qt.LeaseOwner)
+	}
+
+	if err := svc.adopt(context.Background(), false); err != nil {
+		t.Fatalf("adopt() error = %v", err)
+	}
+	select {
+	case id := <-queue.removed:
+		if id != req.TaskID {
+			t.Fatalf("expected task %d to complete, got %d", req.TaskID, id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("interrupted task was not executed again")
+	}
+	if dl.downloads != 1 {
+		t.Fatalf("expected 1 download, got %d", dl.downloads)
+	}
+}
+
+func TestRecoverRenewsLeasesOfTheWorker(t *testing.T) {
+	queue := newFakeQueue()
+	ctx := context.Background()
+	paused := TaskRequest{TaskID: 71, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	_ = queue.Enqueue(ctx, paused)
+	_ = queue.UpdateState(ctx, paused.TaskID, QueueStatePaused)
+
+	svc := NewService(&fakeStorage{}, &fakePublisher{}, WithQueue(queue), WithLease("worker-a", 30*time.Millisecond))
+
+	recoverCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := svc.Recover(recoverCtx); err != nil {
+		t.Fatalf("Recover() error = %v", err)
+	}
+	qt, _ := queue.Get(ctx, paused.TaskID)
+	if qt.LeaseOwner != "worker-a" {
+		t.Fatalf("expected the recovered task to be leased to worker-a, got %q", qt.LeaseOwner)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	renewed, _ := queue.Get(ctx, paused.TaskID)
+	if !renewed.LeaseExpiresAt.After(qt.LeaseExpiresAt) {
+		t.Fatal("expected the lease to be renewed")
+	}
+}
+
+type failingStatusPublisher struct {
+	fakePublisher
+}
+
+func (p *failingStatusPublisher) PublishTaskStatusUpdated(ctx context.Context, event events.TaskStatusUpdatedEvent) error {
+	return &errors.Error{Code: errors.ErrCodeInternal, Message: "broker unavailable"}
+}
+
+func TestSubmitTaskWithoutQueueReturnsOnceTaskSettled(t *testing.T) {
+	pub := &fakePublisher{}
+	svc := NewService(&fakeStorage{}, pub)
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+
+	req := TaskRequest{TaskID: 81, SourceURL: "https://example.com/a.txt", SourceType: "HTTP"}
+	if err := svc.SubmitTask(context.Background(), req); err != nil {
+		t.Fatalf("SubmitTask() error = %v", err)
+	}
+	if pub.completed == nil {
+		t.Fatal("expected the task to be completed when SubmitTask returns")
+	}
+
+	// A task whose outcome could not be published has to be submitted again.
+	svc = NewService(&fakeStorage{}, &failingStatusPublisher{})
+	svc.RegisterDownloader("HTTP", &fakeDownloader{})
+	if err := svc.SubmitTask(context.Background(), req); err == nil {
+		t.Fatal("expected SubmitTask to fail for an unsettled task")
+	}
+}
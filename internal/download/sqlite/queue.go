@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	"github.com/yuisofull/goload/internal/download"
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// leaseTimeLayout has a fixed width so that lease times compare as text.
+const leaseTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type queue struct {
+	pool  *sqlitex.Pool
+	codec *download.RequestCodec
+}
+
+// NewQueue returns a download.Queue backed by the download_queue table,
+// recording the requests encoded by codec.
+func NewQueue(pool *sqlitex.Pool, codec *download.RequestCodec) download.Queue {
+	return &queue{pool: pool, codec: codec}
+}
+
+func (q *queue) withConn(ctx context.Context, fn func(conn *sqlite.Conn) error) error {
+	conn := q.pool.Get(ctx)
+	if conn == nil {
+		return context.DeadlineExceeded
+	}
+	defer q.pool.Put(conn)
+	return fn(conn)
+}
+
+func (q *queue) Enqueue(ctx context.Context, req download.TaskRequest) error {
+	request, err := q.codec.Encode(req)
+	if err != nil {
+		return err
+	}
+	return q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`INSERT OR IGNORE INTO download_queue (task_id, request, state) VALUES (?, ?, ?)`,
+			&sqlitex.ExecOptions{Args: []any{req.TaskID, request, string(download.QueueStateQueued)}},
+		)
+	})
+}
+
+func (q *queue) Get(ctx context.Context, taskID uint64) (*download.QueuedTask, error) {
+	var qt *download.QueuedTask
+	err := q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT task_id, request, state, downloaded_bytes, total_bytes, enqueued_at, updated_at,
+       lease_owner, lease_expires_at
+FROM download_queue WHERE task_id = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{taskID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					var err error
+					qt, err = q.scanQueuedTask(stmt)
+					return err
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if qt == nil {
+		return nil, errors.ErrNotFound
+	}
+	return qt, nil
+}
+
+func (q *queue) UpdateState(ctx context.Context, taskID uint64, state download.QueueState) error {
+	return q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`UPDATE download_queue SET state = ?, updated_at = CURRENT_TIMESTAMP WHERE task_id = ?`,
+			&sqlitex.ExecOptions{Args: []any{string(state), taskID}},
+		)
+	})
+}
+
+func (q *queue) UpdateOffset(ctx context.Context, taskID uint64, offset, totalBytes int64) error {
+	return q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`UPDATE download_queue SET downloaded_bytes = ?, total_bytes = ?, updated_at = CURRENT_TIMESTAMP
+WHERE task_id = ?`,
+			&sqlitex.ExecOptions{Args: []any{offset, totalBytes, taskID}},
+		)
+	})
+}
+
+func (q *queue) Remove(ctx context.Context, taskID uint64) error {
+	return q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`DELETE FROM download_queue WHERE task_id = ?`,
+			&sqlitex.ExecOptions{Args: []any{taskID}},
+		)
+	})
+}
+
+func (q *queue) ListUnfinished(ctx context.Context) ([]*download.QueuedTask, error) {
+	var tasks []*download.QueuedTask
+	err := q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT task_id, request, state, downloaded_bytes, total_bytes, enqueued_at, updated_at,
+       lease_owner, lease_expires_at
+FROM download_queue ORDER BY enqueued_at, task_id`,
+			&sqlitex.ExecOptions{
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					qt, err := q.scanQueuedTask(stmt)
+					if err != nil {
+						return err
+					}
+					tasks = append(tasks, qt)
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (q *queue) Claim(ctx context.Context, taskID uint64, owner string, now, expiresAt time.Time) (bool, error) {
+	var claimed bool
+	err := q.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`UPDATE download_queue SET lease_owner = ?, lease_expires_at = ?
+WHERE task_id = ? AND (lease_owner = '' OR lease_owner = ? OR lease_expires_at IS NULL OR lease_expires_at < ?)`,
+			&sqlitex.ExecOptions{Args: []any{
+				owner, expiresAt.UTC().Format(leaseTimeLayout), taskID, owner, now.UTC().Format(leaseTimeLayout),
+			}},
+		)
+		claimed = conn.Changes() > 0
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed, nil
+}
+
+func (q *queue) RenewLeases(ctx context.Context, owner string, expiresAt time.Time) error {
+	return q.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`UPDATE download_queue SET lease_expires_at = ? WHERE lease_owner = ?`,
+			&sqlitex.ExecOptions{Args: []any{expiresAt.UTC().Format(leaseTimeLayout), owner}},
+		)
+	})
+}
+
+func (q *queue) scanQueuedTask(stmt *sqlite.Stmt) (*download.QueuedTask, error) {
+	qt := &download.QueuedTask{
+		State:          download.QueueState(stmt.ColumnText(2)),
+		Offset:         stmt.ColumnInt64(3),
+		TotalBytes:     stmt.ColumnInt64(4),
+		EnqueuedAt:     parseSqliteTime(stmt.ColumnText(5)),
+		UpdatedAt:      parseSqliteTime(stmt.ColumnText(6)),
+		LeaseOwner:     stmt.ColumnText(7),
+		LeaseExpiresAt: parseSqliteTime(stmt.ColumnText(8)),
+	}
+
+	request := make([]byte, stmt.ColumnLen(1))
+	stmt.ColumnBytes(1, request)
+	req, err := q.codec.Decode(request)
+	if err != nil {
+		return nil, err
+	}
+	qt.Request = req
+	qt.Request.TaskID = uint64(stmt.ColumnInt64(0))
+	return qt, nil
+}
+
+func parseSqliteTime(s string) time.Time {
+	formats := []string{
+		"2006-01-02 15:04:05",
+		time.RFC3339,
+		"2006-01-02T15:04:05.999999999Z",
+	}
+	for _, f := range formats {
+		t, err := time.ParseInLocation(f, s, time.UTC)
+		if err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
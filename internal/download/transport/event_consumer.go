@@ -2,14 +2,17 @@ package downloadtransport
 
 import (
 	"context"
-	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 
 	"github.com/yuisofull/goload/internal/download"
+	"github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/pkg/message"
+	"github.com/yuisofull/goload/pkg/message/middleware"
 )
 
 // EventConsumer handles incoming events for the download service.
@@ -17,158 +20,252 @@ type EventConsumer struct {
 	service    download.Service
 	subscriber message.Subscriber
 	logger     log.Logger
+	acks       ControlAckPublisher
+}
+
+// ControlAckPublisher publishes the outcome of the control events a worker
+// handled for the tasks it claimed.
+type ControlAckPublisher interface {
+	PublishTaskControlAcked(ctx context.Context, event events.TaskControlAckedEvent) error
+}
+
+// EventConsumerOption configures an EventConsumer.
+type EventConsumerOption func(*EventConsumer)
+
+// WithControlAcks acknowledges the control events published to the worker
+// topics of the service back to the task service.
+func WithControlAcks(publisher ControlAckPublisher) EventConsumerOption {
+	return func(ec *EventConsumer) {
+		ec.acks = publisher
+	}
 }
 
 // NewEventConsumer creates a new event consumer for the download service.
-func NewEventConsumer(service download.Service, subscriber message.Subscriber, logger log.Logger) *EventConsumer {
-	return &EventConsumer{
+func NewEventConsumer(
+	service download.Service,
+	subscriber message.Subscriber,
+	logger log.Logger,
+	opts ...EventConsumerOption,
+) *EventConsumer {
+	ec := &EventConsumer{
 		service:    service,
 		subscriber: subscriber,
 		logger:     logger,
 	}
+	for _, opt := range opts {
+		opt(ec)
+	}
+	return ec
 }
 
 // Start begins consuming events.
 func (ec *EventConsumer) Start(ctx context.Context) error {
-	// Subscribe to task created events
-	taskCreatedCh, err := ec.subscriber.Subscribe(ctx, string(events.EventTaskCreated))
-	if err != nil {
-		return err
-	}
-	level.Info(ec.logger).Log("msg", "subscription started", "topic", "task.created")
+	level.Info(ec.logger).Log("msg", "event consumer running, awaiting context done")
+	err := ec.newRouter().Run(ctx)
+	level.Info(ec.logger).Log("msg", "event consumer stopping", "err", err)
+	return err
+}
 
-	// Subscribe to task control events
-	taskPausedCh, err := ec.subscriber.Subscribe(ctx, string(events.EventTaskPaused))
-	if err != nil {
-		return err
-	}
-	level.Info(ec.logger).Log("msg", "subscription started", "topic", "task.paused")
+// newRouter routes the task created events and the control events to the
+// service.
+func (ec *EventConsumer) newRouter() *message.Router {
+	r := message.NewRouter(ec.subscriber, message.WithRouterLogger(ec.logger))
+	r.Use(middleware.Recoverer, middleware.CorrelationID, ec.logPermanentErrors)
 
-	taskResumedCh, err := ec.subscriber.Subscribe(ctx, string(events.EventTaskResumed))
-	if err != nil {
-		return err
-	}
-	level.Info(ec.logger).Log("msg", "subscription started", "topic", "task.resumed")
+	// Without a durable queue SubmitTask returns once the task finished. The
+	// subscribers delivering messages independently, such as the in-memory
+	// one, then keep handling other tasks meanwhile; the Kafka one waits for
+	// the ack of a message before delivering the next of its partition, so it
+	// is only used with a queue.
+	r.AddHandler(
+		"download.task_created",
+		string(events.EventTaskCreated),
+		message.JSONHandler(ec.handleTaskCreated),
+		message.HandleConcurrently(),
+	)
 
-	taskCancelledCh, err := ec.subscriber.Subscribe(ctx, string(events.EventTaskCancelled))
-	if err != nil {
-		return err
+	// Control events are published to the shared topics, and those of
+	// claimed tasks also to the worker topics of the worker that claimed them.
+	var workerID string
+	if w, ok := ec.service.(download.Worker); ok {
+		workerID = w.WorkerID()
 	}
-	level.Info(ec.logger).Log("msg", "subscription started", "topic", "task.cancelled")
-
-	// Process events in separate goroutines
-	go ec.processTaskCreatedEvents(ctx, taskCreatedCh)
-	go ec.processTaskPausedEvents(ctx, taskPausedCh)
-	go ec.processTaskResumedEvents(ctx, taskResumedCh)
-	go ec.processTaskCancelledEvents(ctx, taskCancelledCh)
-
-	// Block here until context cancellation so Start acts as a long-running process
-	level.Info(ec.logger).Log("msg", "event consumer running, awaiting context done")
-	<-ctx.Done()
-	level.Info(ec.logger).Log("msg", "event consumer stopping: context canceled")
-	return ctx.Err()
-}
-
-func (ec *EventConsumer) processTaskCreatedEvents(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		var event events.TaskCreatedEvent
-		if err := json.Unmarshal(msg.Payload, &event); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to unmarshal TaskCreatedEvent", "err", err)
-			msg.Nack()
-			continue
+	for _, event := range []events.EventType{events.EventTaskPaused, events.EventTaskResumed, events.EventTaskCancelled} {
+		name := "download." + strings.ReplaceAll(string(event), ".", "_")
+		r.AddHandler(name, string(event), ec.controlHandler(event, ""))
+		if workerID != "" {
+			r.AddHandler(name+"_worker", events.WorkerTopic(event, workerID), ec.controlHandler(event, workerID))
 		}
+	}
 
-		// Map event to internal TaskRequest and execute in a separate goroutine.
-		go func(event events.TaskCreatedEvent) {
-			var req download.TaskRequest
-
-			if event.SourceAuth != nil {
-				req.SourceAuth = &download.AuthConfig{
-					Type:     event.SourceAuth.Type,
-					Username: event.SourceAuth.Username,
-					Password: event.SourceAuth.Password,
-					Token:    event.SourceAuth.Token,
-				}
-			}
-			if event.DownloadOptions != nil {
-				req.DownloadOptions = &download.DownloadOptions{
-					Concurrency: event.DownloadOptions.Concurrency,
-					MaxRetries:  event.DownloadOptions.MaxRetries,
-				}
+	// Re-adopt tasks interrupted by a previous run once control events can be
+	// received for them.
+	if rec, ok := ec.service.(download.Recoverer); ok {
+		r.OnSubscribed(func(ctx context.Context) {
+			if err := rec.Recover(ctx); err != nil {
+				level.Error(ec.logger).Log("msg", "failed to recover queued tasks", "err", err)
 			}
+		})
+	}
+	return r
+}
 
-			req.TaskID = event.TaskID
-			req.OfAccountID = event.OfAccountID
-			req.FileName = event.FileName
-			req.SourceURL = event.SourceURL
-			req.SourceType = event.SourceType
-			req.Metadata = event.Metadata
-			req.Checksum = nil
-			req.CreatedAt = event.CreatedAt
-
-			level.Debug(ec.logger).Log(
-				"msg", "executing task",
-				"task_id", req.TaskID,
-				"source_type", req.SourceType,
-				"source_url_len", len(req.SourceURL),
+// logPermanentErrors logs the messages that are nacked because they cannot be
+// handled at all, such as those that do not decode.
+func (ec *EventConsumer) logPermanentErrors(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) error {
+		err := h(msg)
+		if message.IsPermanent(err) {
+			level.Error(ec.logger).Log(
+				"msg", "failed to handle message",
+				"handler", message.HandlerNameFromCtx(msg.Context()),
+				"message_uuid", msg.UUID,
+				"err", err,
 			)
+		}
+		return err
+	}
+}
 
-			if err := ec.service.ExecuteTask(ctx, req); err != nil {
-				level.Error(ec.logger).Log("msg", "failed to execute task", "task_id", event.TaskID, "err", err)
-			}
-		}(event)
-
-		msg.Ack()
+// handleTaskCreated returns once the task was durably recorded or reached a
+// terminal state, so that the message is acked. Otherwise it fails and the
+// task is delivered again, possibly to another worker.
+func (ec *EventConsumer) handleTaskCreated(
+	ctx context.Context,
+	msg *message.Message,
+	event events.TaskCreatedEvent,
+) error {
+	req := taskRequestFromEvent(event)
+	level.Debug(ec.logger).Log(
+		"msg", "submitting task",
+		"task_id", req.TaskID,
+		"source_type", req.SourceType,
+		"source_url_len", len(req.SourceURL),
+	)
+	if err := ec.service.SubmitTask(ctx, req); err != nil {
+		level.Error(ec.logger).Log("msg", "failed to submit task", "task_id", req.TaskID, "err", err)
+		return err
 	}
+	return nil
 }
 
-func (ec *EventConsumer) processTaskPausedEvents(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		var event events.TaskPausedEvent
-		if err := json.Unmarshal(msg.Payload, &event); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to unmarshal TaskPausedEvent", "err", err)
-			msg.Nack()
-			continue
+// taskRequestFromEvent maps a TaskCreatedEvent to the TaskRequest executed by
+// the download service.
+func taskRequestFromEvent(event events.TaskCreatedEvent) download.TaskRequest {
+	req := download.TaskRequest{
+		TaskID:      event.TaskID,
+		OfAccountID: event.OfAccountID,
+		FileName:    event.FileName,
+		SourceURL:   event.SourceURL,
+		SourceType:  event.SourceType,
+		Metadata:    event.Metadata,
+		Priority:    event.Priority,
+		CreatedAt:   event.CreatedAt,
+	}
+	if event.SourceAuth != nil {
+		req.SourceAuth = &download.AuthConfig{
+			Type:     event.SourceAuth.Type,
+			Username: event.SourceAuth.Username,
+			Password: event.SourceAuth.Password,
+			Token:    event.SourceAuth.Token,
+			Headers:  event.SourceAuth.Headers,
 		}
-
-		if err := ec.service.PauseTask(ctx, event.TaskID); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to pause task", "task_id", event.TaskID, "err", err)
+	}
+	if event.DownloadOptions != nil {
+		req.DownloadOptions = &download.DownloadOptions{
+			Concurrency: event.DownloadOptions.Concurrency,
+			MaxSpeed:    event.DownloadOptions.MaxSpeed,
+			MaxRetries:  event.DownloadOptions.MaxRetries,
+			Timeout:     event.DownloadOptions.Timeout,
 		}
-
-		msg.Ack()
 	}
-}
-
-func (ec *EventConsumer) processTaskResumedEvents(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		var event events.TaskResumedEvent
-		if err := json.Unmarshal(msg.Payload, &event); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to unmarshal TaskResumedEvent", "err", err)
-			msg.Nack()
-			continue
+	if event.Checksum != nil {
+		req.Checksum = &download.ChecksumInfo{
+			ChecksumType:  event.Checksum.ChecksumType,
+			ChecksumValue: event.Checksum.ChecksumValue,
 		}
+	}
+	return req
+}
 
-		if err := ec.service.ResumeTask(ctx, event.TaskID); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to resume task", "task_id", event.TaskID, "err", err)
-		}
+// controlEvent is the part of the paused, resumed and cancelled events needed
+// to apply them.
+type controlEvent struct {
+	TaskID uint64 `json:"task_id"`
+}
 
-		msg.Ack()
+// controlHandler handles the control events of type command, received from
+// the shared topic with an empty workerID and from the worker topic of
+// workerID. The worker that claimed the task handles them from its worker
+// topic and acknowledges them to the task service; any worker handles those
+// of unclaimed tasks. The messages are acked whether the control event could
+// be applied or not.
+func (ec *EventConsumer) controlHandler(command events.EventType, workerID string) message.HandlerFunc {
+	var apply func(ctx context.Context, taskID uint64) error
+	switch command {
+	case events.EventTaskPaused:
+		apply = ec.service.PauseTask
+	case events.EventTaskResumed:
+		apply = ec.service.ResumeTask
+	case events.EventTaskCancelled:
+		apply = ec.service.CancelTask
 	}
-}
 
-func (ec *EventConsumer) processTaskCancelledEvents(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		var event events.TaskCancelledEvent
-		if err := json.Unmarshal(msg.Payload, &event); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to unmarshal TaskCancelledEvent", "err", err)
-			msg.Nack()
-			continue
+	return message.JSONHandler(func(ctx context.Context, msg *message.Message, event controlEvent) error {
+		if addressedToWorker(msg, workerID) {
+			return nil
 		}
 
-		if err := ec.service.CancelTask(ctx, event.TaskID); err != nil {
-			level.Error(ec.logger).Log("msg", "failed to cancel task", "task_id", event.TaskID, "err", err)
+		err := apply(ctx, event.TaskID)
+		if err != nil {
+			level.Error(ec.logger).Log(
+				"msg", "failed to apply control event",
+				"task_id", event.TaskID,
+				"command", command,
+				"err", err,
+			)
 		}
+		ec.ackControl(ctx, command, event.TaskID, workerID, err)
+		return nil
+	})
+}
 
-		msg.Ack()
+// addressedToWorker reports whether msg was received from a shared topic while
+// it is also published to the worker topic of the worker that claimed the
+// task, which handles it from there.
+func addressedToWorker(msg *message.Message, workerID string) bool {
+	return workerID == "" && msg.Metadata.Get(events.MetadataWorkerID) != ""
+}
+
+// ackControl publishes the outcome of a control event addressed to workerID.
+func (ec *EventConsumer) ackControl(
+	ctx context.Context,
+	command events.EventType,
+	taskID uint64,
+	workerID string,
+	err error,
+) {
+	if ec.acks == nil || workerID == "" {
+		return
+	}
+	event := events.TaskControlAckedEvent{
+		TaskID:   taskID,
+		Command:  command,
+		WorkerID: workerID,
+		AckedAt:  time.Now(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+		if svcErr := errors.AsError(err); svcErr != nil {
+			event.ErrorCode = string(svcErr.Code)
+		}
+	}
+	if err := ec.acks.PublishTaskControlAcked(ctx, event); err != nil {
+		level.Error(ec.logger).Log(
+			"msg", "failed to acknowledge control event",
+			"task_id", taskID,
+			"command", command,
+			"err", err,
+		)
 	}
 }
@@ -203,6 +203,10 @@ func (s *spyEventPublisher) PublishTaskFailed(ctx context.Context, ev events.Tas
 	return s.inner.PublishTaskFailed(ctx, ev)
 }
 
+func (s *spyEventPublisher) PublishTaskClaimed(ctx context.Context, ev events.TaskClaimedEvent) error {
+	return s.inner.PublishTaskClaimed(ctx, ev)
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Kafka test helpers
 // ──────────────────────────────────────────────────────────────────────────────
@@ -0,0 +1,207 @@
+package downloadtransport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/download"
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+type fakeService struct {
+	download.Service
+	submit func(ctx context.Context, req download.TaskRequest) error
+	pause  func(ctx context.Context, taskID uint64) error
+}
+
+func (s *fakeService) SubmitTask(ctx context.Context, req download.TaskRequest) error {
+	return s.submit(ctx, req)
+}
+
+func (s *fakeService) PauseTask(ctx context.Context, taskID uint64) error {
+	return s.pause(ctx, taskID)
+}
+
+type fakeAckPublisher struct {
+	acked []events.TaskControlAckedEvent
+}
+
+func (p *fakeAckPublisher) PublishTaskControlAcked(ctx context.Context, event events.TaskControlAckedEvent) error {
+	p.acked = append(p.acked, event)
+	return nil
+}
+
+func TestTaskRequestFromEvent(t *testing.T) {
+	maxSpeed := int64(1 << 20)
+	timeout := 600
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := events.TaskCreatedEvent{
+		TaskID:      7,
+		OfAccountID: 3,
+		FileName:    "file.iso",
+		SourceURL:   "https://example.com/file.iso",
+		SourceType:  "HTTPS",
+		SourceAuth: &events.AuthConfig{
+			Type:     "bearer",
+			Username: "user",
+			Password: "secret",
+			Token:    "token",
+			Headers:  map[string]string{"X-Api-Key": "key"},
+		},
+		DownloadOptions: &events.DownloadOptions{
+			Concurrency: 4,
+			MaxSpeed:    &maxSpeed,
+			MaxRetries:  2,
+			Timeout:     &timeout,
+		},
+		Metadata:  map[string]any{"tag": "iso"},
+		Checksum:  &events.ChecksumInfo{ChecksumType: "sha256", ChecksumValue: "abc"},
+		Priority:  "HIGH",
+		CreatedAt: createdAt,
+	}
+
+	assert.Equal(t, download.TaskRequest{
+		TaskID:      7,
+		OfAccountID: 3,
+		FileName:    "file.iso",
+		SourceURL:   "https://example.com/file.iso",
+		SourceType:  "HTTPS",
+		SourceAuth: &download.AuthConfig{
+			Type:     "bearer",
+			Username: "user",
+			Password: "secret",
+			Token:    "token",
+			Headers:  map[string]string{"X-Api-Key": "key"},
+		},
+		DownloadOptions: &download.DownloadOptions{
+			Concurrency: 4,
+			MaxSpeed:    &maxSpeed,
+			MaxRetries:  2,
+			Timeout:     &timeout,
+		},
+		Metadata:  map[string]any{"tag": "iso"},
+		Checksum:  &download.ChecksumInfo{ChecksumType: "sha256", ChecksumValue: "abc"},
+		Priority:  "HIGH",
+		CreatedAt: createdAt,
+	}, taskRequestFromEvent(event))
+
+	assert.Equal(t, download.TaskRequest{TaskID: 8}, taskRequestFromEvent(events.TaskCreatedEvent{TaskID: 8}))
+}
+
+// fakeSubscriber hands out the channels in topics and empty channels for the
+// other topics, closing them once the subscription ends.
+type fakeSubscriber struct {
+	topics map[string]chan *message.Message
+}
+
+func (s *fakeSubscriber) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	ch, ok := s.topics[topic]
+	if !ok {
+		ch = make(chan *message.Message)
+	}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (s *fakeSubscriber) Close() error { return nil }
+
+func newTaskCreatedMessage(t *testing.T, taskID uint64) *message.Message {
+	payload, err := json.Marshal(events.TaskCreatedEvent{TaskID: taskID, SourceType: "HTTP"})
+	require.NoError(t, err)
+	return message.NewMessage("", payload)
+}
+
+func TestTaskCreatedHandler_AcksOnceTaskIsSubmitted(t *testing.T) {
+	release := make(chan struct{})
+	svc := &fakeService{submit: func(ctx context.Context, req download.TaskRequest) error {
+		if req.TaskID == 1 {
+			<-release
+			return nil
+		}
+		return errors.New("queue unavailable")
+	}}
+	slow, failing := newTaskCreatedMessage(t, 1), newTaskCreatedMessage(t, 2)
+	ch := make(chan *message.Message, 2)
+	ch <- slow
+	ch <- failing
+	sub := &fakeSubscriber{topics: map[string]chan *message.Message{string(events.EventTaskCreated): ch}}
+	ec := NewEventConsumer(svc, sub, log.NewNopLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ec.Start(ctx)
+
+	// A task that is still running does not hold up the next message.
+	select {
+	case <-failing.Nacked():
+	case <-time.After(time.Second):
+		t.Fatal("expected the failed submission to be nacked")
+	}
+	select {
+	case <-slow.Acked():
+		t.Fatal("message acked before the task was submitted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-slow.Acked():
+	case <-time.After(time.Second):
+		t.Fatal("expected the message to be acked once the task was submitted")
+	}
+}
+
+func TestControlHandler_AcksEventsOfWorkerTopic(t *testing.T) {
+	var paused []uint64
+	svc := &fakeService{pause: func(ctx context.Context, taskID uint64) error {
+		paused = append(paused, taskID)
+		if taskID == 2 {
+			return &apperrors.Error{Code: apperrors.ErrCodeNotFound, Message: "task not found in active tasks"}
+		}
+		return nil
+	}}
+	acks := &fakeAckPublisher{}
+	ec := NewEventConsumer(svc, nil, log.NewNopLogger(), WithControlAcks(acks))
+
+	newPausedMessage := func(taskID uint64) *message.Message {
+		payload, err := json.Marshal(events.TaskPausedEvent{TaskID: taskID})
+		require.NoError(t, err)
+		return message.NewMessage("", payload)
+	}
+	consume := func(workerID string, msgs ...*message.Message) {
+		handle := ec.controlHandler(events.EventTaskPaused, workerID)
+		for _, msg := range msgs {
+			require.NoError(t, handle(msg))
+		}
+	}
+
+	// Any worker handles the events of unclaimed tasks from the shared topic
+	// and ignores those the worker that claimed the task receives itself.
+	claimed := newPausedMessage(3)
+	claimed.Metadata.Set(events.MetadataWorkerID, "download-2")
+	consume("", newPausedMessage(1), claimed)
+	assert.Equal(t, []uint64{1}, paused)
+	assert.Empty(t, acks.acked)
+
+	consume("download-1", newPausedMessage(1), newPausedMessage(2))
+	require.Len(t, acks.acked, 2)
+	assert.Equal(t, uint64(1), acks.acked[0].TaskID)
+	assert.Equal(t, events.EventTaskPaused, acks.acked[0].Command)
+	assert.Equal(t, "download-1", acks.acked[0].WorkerID)
+	assert.Empty(t, acks.acked[0].Error)
+	assert.Equal(t, uint64(2), acks.acked[1].TaskID)
+	assert.Equal(t, string(apperrors.ErrCodeNotFound), acks.acked[1].ErrorCode)
+	assert.NotEmpty(t, acks.acked[1].Error)
+}
@@ -82,6 +82,12 @@ func EncodeGRPCError(err error) error {
 			return status.Error(codes.Unauthenticated, msg)
 		case ErrCodePermissionDenied:
 			return status.Error(codes.PermissionDenied, msg)
+		case ErrCodeTooManyRequests:
+			return status.Error(codes.ResourceExhausted, msg)
+		case ErrCodeInvalidInput:
+			return status.Error(codes.InvalidArgument, msg)
+		case ErrCodeConflict:
+			return status.Error(codes.Aborted, msg)
 		default:
 			return status.Error(codes.Unknown, msg)
 		}
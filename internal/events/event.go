@@ -1,6 +1,9 @@
 package events
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // TaskCreatedEvent represents events published by the task service
 type TaskCreatedEvent struct {
@@ -13,6 +16,7 @@ type TaskCreatedEvent struct {
 	DownloadOptions *DownloadOptions `json:"download_options,omitempty"`
 	Metadata        map[string]any   `json:"metadata,omitempty"`
 	Checksum        *ChecksumInfo    `json:"checksum,omitempty"`
+	Priority        string           `json:"priority,omitempty"`
 	CreatedAt       time.Time        `json:"created_at"`
 }
 
@@ -42,13 +46,20 @@ type TaskCompletedEvent struct {
 	StorageType string        `json:"storage_type"`
 	StorageKey  string        `json:"storage_key"`
 	CompletedAt time.Time     `json:"completed_at"`
+	// SourceETag and SourceLastModified are the validators the source
+	// reported for the downloaded content, when it reported any.
+	SourceETag         string `json:"source_etag,omitempty"`
+	SourceLastModified string `json:"source_last_modified,omitempty"`
 }
 
 // TaskFailedEvent represents task failure from download service
 type TaskFailedEvent struct {
-	TaskID   uint64    `json:"task_id"`
-	Error    string    `json:"error"`
-	FailedAt time.Time `json:"failed_at"`
+	TaskID uint64 `json:"task_id"`
+	Error  string `json:"error"`
+	// ErrorCode carries the service error code when the failure has one,
+	// e.g. CHECKSUM_MISMATCH.
+	ErrorCode string    `json:"error_code,omitempty"`
+	FailedAt  time.Time `json:"failed_at"`
 }
 
 // TaskRetriedEvent represents a retry attempt for a task
@@ -77,6 +88,36 @@ type TaskCancelledEvent struct {
 	CancelledAt time.Time `json:"cancelled_at"`
 }
 
+// TaskClaimedEvent reports that a download worker picked a task up. Control
+// events of the task are published to the worker topics of that worker.
+type TaskClaimedEvent struct {
+	TaskID    uint64    `json:"task_id"`
+	WorkerID  string    `json:"worker_id"`
+	ClaimedAt time.Time `json:"claimed_at"`
+}
+
+// TaskControlAckedEvent reports the outcome of a pause, resume or cancel
+// request handled by a download worker
+type TaskControlAckedEvent struct {
+	TaskID uint64 `json:"task_id"`
+	// Command is the event type of the request, e.g. task.paused.
+	Command  EventType `json:"command"`
+	WorkerID string    `json:"worker_id"`
+	// Error is empty when the worker applied the request.
+	Error     string    `json:"error,omitempty"`
+	ErrorCode string    `json:"error_code,omitempty"`
+	AckedAt   time.Time `json:"acked_at"`
+}
+
+// TaskFileExpiredEvent reports that the stored file of a task was deleted
+// because its retention ran out
+type TaskFileExpiredEvent struct {
+	TaskID      uint64    `json:"task_id"`
+	OfAccountID uint64    `json:"of_account_id"`
+	StorageKey  string    `json:"storage_key"`
+	ExpiredAt   time.Time `json:"expired_at"`
+}
+
 // EventType enum for task events
 type (
 	EventType  string
@@ -122,6 +163,9 @@ const (
 	EventTaskResumed         EventType = "task.resumed"
 	EventTaskCancelled       EventType = "task.cancelled"
 	EventTaskRetried         EventType = "task.retried"
+	EventTaskFileExpired     EventType = "task.file.expired"
+	EventTaskClaimed         EventType = "task.claimed"
+	EventTaskControlAcked    EventType = "task.control.acked"
 
 	StatusPending     TaskStatus = "PENDING"
 	StatusDownloading TaskStatus = "DOWNLOADING"
@@ -132,6 +176,28 @@ const (
 	StatusPaused      TaskStatus = "PAUSED"
 )
 
+// MetadataRetry is set to "true" on task.created messages that hand a retried
+// task to the download workers again.
+const MetadataRetry = "retry"
+
+// MetadataWorkerID names the download worker that claimed the task of a
+// pause, resume or cancel event. Such events are also published to the worker
+// topic of that worker, so the other workers ignore them.
+const MetadataWorkerID = "workerID"
+
+// WorkerTopic returns the topic of a control event that only the download
+// worker workerID subscribes to, e.g. task.paused.worker-1. Characters Kafka
+// does not allow in topic names are replaced by underscores.
+func WorkerTopic(event EventType, workerID string) string {
+	return string(event) + "." + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, workerID)
+}
+
 // DownloadOptions configures download behavior
 type DownloadOptions struct {
 	Concurrency int    `json:"concurrency"`
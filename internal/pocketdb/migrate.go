@@ -0,0 +1,330 @@
+// Package pocketdb holds the SQLite schema shared by the pocket and pocketsrv
+// binaries.
+package pocketdb
+
+import (
+	"context"
+	"fmt"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+)
+
+// Migrate creates the tables of the pocket editions, which share one SQLite
+// database between all services, and adds the columns of older databases.
+func Migrate(pool *sqlitex.Pool) error {
+	conn := pool.Get(context.Background())
+	if conn == nil {
+		return context.DeadlineExceeded
+	}
+	defer pool.Put(conn)
+
+	// Accounts
+	err := sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS accounts (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        account_name TEXT NOT NULL
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS account_passwords (
+        of_account_id INTEGER PRIMARY KEY,
+        hashed_password TEXT NOT NULL
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS api_keys (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        of_account_id INTEGER NOT NULL,
+        name TEXT NOT NULL,
+        prefix TEXT NOT NULL UNIQUE,
+        hashed_secret TEXT NOT NULL,
+        scopes TEXT NOT NULL,
+        expires_at DATETIME,
+        created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        revoked_at DATETIME
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE INDEX IF NOT EXISTS idx_api_keys_account ON api_keys (of_account_id);`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS token_public_keys (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        public_key TEXT NOT NULL,
+        private_key TEXT,
+        state TEXT NOT NULL DEFAULT 'active',
+        created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        activated_at DATETIME,
+        retired_at DATETIME
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	// Signing key rotation columns; SQLite cannot add a column defaulting
+	// to CURRENT_TIMESTAMP, so older keys get the epoch as creation time.
+	for column, definition := range map[string]string{
+		"private_key":  `TEXT`,
+		"state":        `TEXT NOT NULL DEFAULT 'active'`,
+		"created_at":   `DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00'`,
+		"activated_at": `DATETIME`,
+		"retired_at":   `DATETIME`,
+	} {
+		if err := ensureColumn(conn, "token_public_keys", column, definition); err != nil {
+			return err
+		}
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS workspaces (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT NOT NULL,
+        created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS workspace_members (
+        workspace_id INTEGER NOT NULL,
+        account_id INTEGER NOT NULL,
+        role TEXT NOT NULL,
+        created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (workspace_id, account_id)
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE INDEX IF NOT EXISTS idx_workspace_members_account ON workspace_members (account_id);`, nil)
+	if err != nil {
+		return err
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS account_identities (
+        of_account_id INTEGER NOT NULL,
+        issuer TEXT NOT NULL,
+        subject TEXT NOT NULL,
+        created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (issuer, subject)
+    );`, nil)
+	if err != nil {
+		return err
+	}
+
+	// Tasks table adapted for SQLite
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS tasks (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        of_account_id INTEGER NOT NULL,
+        file_name TEXT NOT NULL,
+        source_url TEXT NOT NULL,
+        source_type TEXT NOT NULL,
+        headers TEXT DEFAULT '{}',
+        source_auth TEXT DEFAULT '{}',
+        storage_type TEXT NOT NULL,
+        storage_path TEXT NOT NULL,
+        checksum_type TEXT,
+        checksum_value TEXT,
+        concurrency INTEGER DEFAULT 4,
+        max_speed INTEGER,
+        max_retries INTEGER NOT NULL DEFAULT 3,
+        timeout INTEGER,
+        status TEXT NOT NULL,
+        progress REAL DEFAULT 0.0,
+        downloaded_bytes INTEGER DEFAULT 0,
+        total_bytes INTEGER DEFAULT 0,
+        error_message TEXT,
+        metadata TEXT DEFAULT '{}',
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        completed_at DATETIME,
+        last_accessed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        expiration_days INTEGER DEFAULT 30,
+        priority TEXT NOT NULL DEFAULT 'NORMAL',
+        schedule_id INTEGER NOT NULL DEFAULT 0,
+        workspace_id INTEGER NOT NULL DEFAULT 0,
+        source_etag TEXT NOT NULL DEFAULT '',
+        source_last_modified TEXT NOT NULL DEFAULT '',
+        worker_id TEXT NOT NULL DEFAULT ''
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	// Columns added after the first release.
+	if err := ensureColumn(conn, "tasks", "priority", `TEXT NOT NULL DEFAULT 'NORMAL'`); err != nil {
+		return err
+	}
+	if err := ensureColumn(conn, "tasks", "schedule_id", `INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return err
+	}
+	if err := ensureColumn(conn, "tasks", "workspace_id", `INTEGER NOT NULL DEFAULT 0`); err != nil {
+		return err
+	}
+	for _, column := range []string{"source_etag", "source_last_modified", "worker_id"} {
+		if err := ensureColumn(conn, "tasks", column, `TEXT NOT NULL DEFAULT ''`); err != nil {
+			return err
+		}
+	}
+	// Task listings filter by account and sort by one of these columns.
+	for _, stmt := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_tasks_account_created ON tasks (of_account_id, created_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_account_updated ON tasks (of_account_id, updated_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_account_size ON tasks (of_account_id, total_bytes, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_account_name ON tasks (of_account_id, file_name, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_account_status ON tasks (of_account_id, status, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_workspace_created ON tasks (workspace_id, created_at, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_status_accessed ON tasks (status, last_accessed_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_tasks_storage_path ON tasks (storage_path)`,
+	} {
+		if err := sqlitex.ExecuteTransient(conn, stmt, nil); err != nil {
+			return err
+		}
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS task_schedules (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        of_account_id INTEGER NOT NULL,
+        cron_expr TEXT NOT NULL DEFAULT '',
+        start_at DATETIME,
+        enabled INTEGER NOT NULL DEFAULT 1,
+        next_run_at DATETIME,
+        last_run_at DATETIME,
+        next_task_id INTEGER NOT NULL DEFAULT 0,
+        template TEXT NOT NULL,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );`, nil)
+	if err != nil {
+		return err
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS task_webhooks (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        of_account_id INTEGER NOT NULL,
+        url TEXT NOT NULL,
+        secret TEXT NOT NULL,
+        events TEXT,
+        enabled INTEGER NOT NULL DEFAULT 1,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    );`, nil)
+	if err != nil {
+		return err
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS task_webhook_deliveries (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        webhook_id INTEGER NOT NULL,
+        event_id TEXT NOT NULL,
+        event TEXT NOT NULL,
+        task_id INTEGER NOT NULL,
+        payload TEXT NOT NULL,
+        status TEXT NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at DATETIME,
+        response_code INTEGER NOT NULL DEFAULT 0,
+        last_error TEXT,
+        delivered_at DATETIME,
+        created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        UNIQUE (webhook_id, event_id)
+    );`, nil)
+	if err != nil {
+		return err
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS task_audit_events (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        time TEXT NOT NULL,
+        actor_account_id INTEGER NOT NULL DEFAULT 0,
+        actor_api_key_id INTEGER NOT NULL DEFAULT 0,
+        action TEXT NOT NULL,
+        task_id INTEGER NOT NULL DEFAULT 0,
+        of_account_id INTEGER NOT NULL DEFAULT 0,
+        workspace_id INTEGER NOT NULL DEFAULT 0,
+        source_ip TEXT NOT NULL DEFAULT '',
+        outcome TEXT NOT NULL,
+        message TEXT NOT NULL DEFAULT ''
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	// Times were once written with trailing zeros of the fraction trimmed,
+	// which does not sort as text.
+	err = sqlitex.ExecuteTransient(conn, `UPDATE task_audit_events
+        SET time = strftime('%Y-%m-%dT%H:%M:%fZ', time)
+        WHERE length(time) != 24;`, nil)
+	if err != nil {
+		return err
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS task_outbox (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        topic TEXT NOT NULL,
+        task_id INTEGER NOT NULL DEFAULT 0,
+        message_uuid TEXT NOT NULL,
+        payload TEXT NOT NULL,
+        metadata TEXT NOT NULL DEFAULT '{}',
+        attempts INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at TEXT,
+        last_error TEXT,
+        dead_at TEXT,
+        created_at TEXT NOT NULL
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	for _, col := range [][2]string{
+		{"attempts", `INTEGER NOT NULL DEFAULT 0`},
+		{"next_attempt_at", `TEXT`},
+		{"last_error", `TEXT`},
+		{"dead_at", `TEXT`},
+	} {
+		if err := ensureColumn(conn, "task_outbox", col[0], col[1]); err != nil {
+			return err
+		}
+	}
+	err = sqlitex.ExecuteTransient(conn, `CREATE INDEX IF NOT EXISTS idx_task_outbox_task ON task_outbox (task_id, next_attempt_at);`, nil)
+	if err != nil {
+		return err
+	}
+
+	err = sqlitex.ExecuteTransient(conn, `CREATE TABLE IF NOT EXISTS download_queue (
+        task_id INTEGER PRIMARY KEY,
+        request TEXT NOT NULL,
+        state TEXT NOT NULL,
+        downloaded_bytes INTEGER NOT NULL DEFAULT 0,
+        total_bytes INTEGER NOT NULL DEFAULT 0,
+        enqueued_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+        lease_owner TEXT NOT NULL DEFAULT '',
+        lease_expires_at TEXT
+    );`, nil)
+	if err != nil {
+		return err
+	}
+	if err := ensureColumn(conn, "download_queue", "lease_owner", `TEXT NOT NULL DEFAULT ''`); err != nil {
+		return err
+	}
+	if err := ensureColumn(conn, "download_queue", "lease_expires_at", `TEXT`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ensureColumn adds a column to an existing table when it is missing.
+func ensureColumn(conn *sqlite.Conn, table, column, definition string) error {
+	exists := false
+	err := sqlitex.ExecuteTransient(conn, fmt.Sprintf("PRAGMA table_info(%s)", table), &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			if stmt.GetText("name") == column {
+				exists = true
+			}
+			return nil
+		},
+	})
+	if err != nil || exists {
+		return err
+	}
+	return sqlitex.ExecuteTransient(conn, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition), nil)
+}
@@ -0,0 +1,18 @@
+package pocketdb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_IsIdempotent(t *testing.T) {
+	pool, err := sqlitex.Open(filepath.Join(t.TempDir(), "pocket.db"), 0, 1)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	require.NoError(t, Migrate(pool))
+	require.NoError(t, Migrate(pool))
+}
@@ -11,10 +11,10 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
-	"github.com/go-kit/log/level"
 )
 
 // Local implements Backend and Presigner using the local filesystem.
@@ -23,16 +23,23 @@ import (
 type Local struct {
 	root string
 
-	defaultExpiry time.Duration // 0 means no expiry policy
-	logger        log.Logger
+	defaultExpiry    time.Duration // 0 means no expiry policy
+	accountRetention map[uint64]time.Duration
+	reapInterval     time.Duration
+	onExpire         func(ctx context.Context, obj ExpiredObject) error
+	logger           log.Logger
+
+	dedup bool
+	// mu guards the reference counts of blobs and the keys referring to them.
+	mu sync.Mutex
 }
 
 // LocalOption configures a Local backend.
 type LocalOption func(*Local)
 
-// WithLocalExpiry sets a default expiry duration for stored objects. When non-zero,
-// the backend will automatically delete objects after the equivalent number of days.
-// Individual Store calls can override this on a per-object basis via FileMetadata.Expiry.
+// WithLocalExpiry sets how long stored objects are retained. When non-zero,
+// Run deletes objects once they are older. Individual Store calls can
+// override this on a per-object basis via FileMetadata.ExpireAt.
 func WithLocalExpiry(d time.Duration) LocalOption {
 	return func(l *Local) { l.defaultExpiry = d }
 }
@@ -47,6 +54,9 @@ type localObjectMetadata struct {
 	FileMetadata
 
 	StoredAt time.Time `json:"stored_at"`
+	// Blob is the SHA-256 digest of the content the object refers to when it
+	// was stored by content; the object itself has no file then.
+	Blob string `json:"blob,omitempty"`
 }
 
 // NewLocalBackend creates a filesystem-backed storage backend rooted at dir.
@@ -62,7 +72,11 @@ func NewLocalBackend(root string, opts ...LocalOption) (*Local, error) {
 	if err != nil {
 		return nil, fmt.Errorf("resolve local storage root: %w", err)
 	}
-	l := &Local{root: absRoot}
+	l := &Local{
+		root:         absRoot,
+		reapInterval: time.Hour,
+		logger:       log.NewNopLogger(),
+	}
 	for _, o := range opts {
 		o(l)
 	}
@@ -77,6 +91,9 @@ func (l *Local) Store(ctx context.Context, key string, reader io.Reader, metadat
 	if err := os.MkdirAll(filepath.Dir(objectPath), 0o755); err != nil {
 		return fmt.Errorf("create local storage directory: %w", err)
 	}
+	if l.dedup {
+		return l.storeByContent(key, objectPath, reader, metadata)
+	}
 
 	tmpPath := objectPath + ".tmp"
 	file, err := os.Create(tmpPath)
@@ -93,12 +110,18 @@ func (l *Local) Store(ctx context.Context, key string, reader io.Reader, metadat
 		_ = os.Remove(tmpPath)
 		return fmt.Errorf("close local storage object: %w", closeErr)
 	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if err := os.Rename(tmpPath, objectPath); err != nil {
 		_ = os.Remove(tmpPath)
 		return fmt.Errorf("commit local storage object: %w", err)
 	}
+	return l.replaceMetadata(objectPath, l.newObjectMetadata(key, metadata, written))
+}
 
-	meta := localObjectMetadata{StoredAt: time.Now()}
+func (l *Local) newObjectMetadata(key string, metadata *FileMetadata, written int64) *localObjectMetadata {
+	meta := &localObjectMetadata{StoredAt: time.Now()}
 	if metadata != nil {
 		meta.FileMetadata = *metadata
 	}
@@ -116,31 +139,11 @@ func (l *Local) Store(ctx context.Context, key string, reader io.Reader, metadat
 	if meta.Headers == nil {
 		meta.Headers = map[string]string{}
 	}
-	if meta.ExpireAt.IsZero() && l.defaultExpiry > 0 {
-		meta.ExpireAt = meta.StoredAt.Add(l.defaultExpiry)
-	}
-
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		for range ticker.C {
-			deletedFiles, err := l.Reap(context.Background())
-			if err != nil {
-				level.Error(l.logger).Log("msg", "error during local storage reap", "err", err)
-			} else {
-				var deletedFilesStr string
-				if len(deletedFiles) > 0 {
-					deletedFilesStr = strings.Join(deletedFiles, ", ")
-				}
-				level.Info(l.logger).Log("msg", "finished reaping expired objects", "deletedFiles", deletedFilesStr)
-			}
-		}
-	}()
-
-	return l.writeMetadata(objectPath, &meta)
+	return meta
 }
 
 func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
-	objectPath, err := l.objectPath(key)
+	objectPath, err := l.contentPath(key)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +154,7 @@ func (l *Local) GetWithRange(ctx context.Context, key string, start, end int64)
 	if start < 0 || (end >= 0 && end < start) {
 		return nil, fmt.Errorf("invalid range %d-%d", start, end)
 	}
-	objectPath, err := l.objectPath(key)
+	objectPath, err := l.contentPath(key)
 	if err != nil {
 		return nil, err
 	}
@@ -183,7 +186,11 @@ func (l *Local) GetInfo(ctx context.Context, key string) (*FileMetadata, error)
 	if err != nil {
 		return nil, err
 	}
-	stat, err := os.Stat(objectPath)
+	contentPath, err := l.contentPath(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(contentPath)
 	if err != nil {
 		return nil, err
 	}
@@ -205,7 +212,7 @@ func (l *Local) GetInfo(ctx context.Context, key string) (*FileMetadata, error)
 }
 
 func (l *Local) Exists(ctx context.Context, key string) (bool, error) {
-	objectPath, err := l.objectPath(key)
+	objectPath, err := l.contentPath(key)
 	if err != nil {
 		return false, err
 	}
@@ -224,13 +231,20 @@ func (l *Local) Delete(ctx context.Context, key string) error {
 	if err != nil {
 		return err
 	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	meta, _ := l.readInternalMetadata(objectPath)
 	_ = os.Remove(objectPath)
 	_ = os.Remove(l.metadataPath(objectPath))
+	if meta != nil && meta.Blob != "" {
+		return l.releaseBlob(meta.Blob)
+	}
 	return nil
 }
 
 func (l *Local) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
-	objectPath, err := l.objectPath(key)
+	objectPath, err := l.contentPath(key)
 	if err != nil {
 		return "", err
 	}
@@ -243,8 +257,9 @@ func (l *Local) PresignGet(ctx context.Context, key string, ttl time.Duration) (
 }
 
 // PathForKey returns the absolute filesystem path for a stored object key.
+// For an object stored by content this is the path of the shared blob.
 func (l *Local) PathForKey(key string) (string, error) {
-	objectPath, err := l.objectPath(key)
+	objectPath, err := l.contentPath(key)
 	if err != nil {
 		return "", err
 	}
@@ -273,77 +288,43 @@ func (r *localRangeReadCloser) Read(p []byte) (int, error) {
 
 func (r *localRangeReadCloser) Close() error { return r.file.Close() }
 
-// Reap traverses the storage root and deletes objects that have expired.
-// It returns the list of deleted files and any error encountered during traversal.
-func (l *Local) Reap(ctx context.Context) ([]string, error) {
-	var deletedFiles []string
-	now := time.Now()
-
-	err := filepath.WalkDir(l.root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories and metadata files
-		if d.IsDir() || strings.HasSuffix(path, ".meta.json") {
-			return nil
-		}
-
-		// Read metadata for the current file
-		// Note: readMetadata expects the object path, which we have here.
-		meta, err := l.readInternalMetadata(path)
-		if err != nil {
-			// If metadata is missing or corrupt, we skip it to avoid
-			// deleting data that might still be valid but orphaned.
-			return nil
-		}
-
-		if meta.ExpireAt.IsZero() && l.defaultExpiry > 0 {
-			meta.ExpireAt = meta.StoredAt.Add(l.defaultExpiry)
-		}
-
-		if now.After(meta.ExpireAt) {
-			// Extract key to use the existing Delete logic
-			relPath, err := filepath.Rel(l.root, path)
-			if err != nil {
-				return nil
-			}
-			key := filepath.ToSlash(relPath)
-
-			if err := l.Delete(ctx, key); err == nil {
-				deletedFiles = append(deletedFiles, key)
-			}
-		}
-
-		return nil
-	})
-
-	return deletedFiles, err
-}
-
-// Internal helper to get the full metadata including StoredAt
-func (l *Local) readInternalMetadata(objectPath string) (*localObjectMetadata, error) {
-	data, err := os.ReadFile(l.metadataPath(objectPath))
+func (l *Local) objectPath(key string) (string, error) {
+	cleaned, err := l.cleanKey(key)
 	if err != nil {
-		return nil, err
-	}
-	var meta localObjectMetadata
-	if err := json.Unmarshal(data, &meta); err != nil {
-		return nil, err
+		return "", err
 	}
-	return &meta, nil
+	return filepath.Join(l.root, filepath.FromSlash(cleaned)), nil
 }
 
-func (l *Local) objectPath(key string) (string, error) {
-	cleaned, err := l.cleanKey(key)
+// contentPath returns the path of the file holding the content of key: the
+// blob it refers to when it was stored by content, otherwise its own file.
+func (l *Local) contentPath(key string) (string, error) {
+	objectPath, err := l.objectPath(key)
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(l.root, filepath.FromSlash(cleaned)), nil
+	meta, err := l.readInternalMetadata(objectPath)
+	if err != nil || meta.Blob == "" {
+		return objectPath, nil
+	}
+	return l.blobPath(meta.Blob)
 }
 
 func (l *Local) metadataPath(objectPath string) string { return objectPath + ".meta.json" }
 
+// replaceMetadata writes the metadata of an object and releases the blob the
+// object referred to before. The caller must hold l.mu.
+func (l *Local) replaceMetadata(objectPath string, meta *localObjectMetadata) error {
+	previous, _ := l.readInternalMetadata(objectPath)
+	if err := l.writeMetadata(objectPath, meta); err != nil {
+		return err
+	}
+	if previous != nil && previous.Blob != "" {
+		return l.releaseBlob(previous.Blob)
+	}
+	return nil
+}
+
 func (l *Local) writeMetadata(objectPath string, meta *localObjectMetadata) error {
 	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
@@ -367,6 +348,7 @@ func (l *Local) readMetadata(objectPath string) (*FileMetadata, error) {
 	if meta.Bucket == "" {
 		meta.Bucket = "local"
 	}
+	meta.ExpireAt = l.expireAt(&meta)
 	return &meta.FileMetadata, nil
 }
 
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// localBlobDir is the directory under the storage root that holds the
+// content of objects stored by content.
+const localBlobDir = ".blobs"
+
+// WithLocalDedup stores objects by content: the content is written once under
+// its SHA-256 digest and every key storing the same content refers to it. A
+// reference count is kept per blob, and the blob is deleted with the last
+// key referring to it. Objects stored before are still read as they are.
+func WithLocalDedup(enabled bool) LocalOption {
+	return func(l *Local) { l.dedup = enabled }
+}
+
+// storeByContent writes reader to the blob of its digest and makes key refer
+// to it.
+func (l *Local) storeByContent(key, objectPath string, reader io.Reader, metadata *FileMetadata) error {
+	blobDir := filepath.Join(l.root, localBlobDir)
+	if err := os.MkdirAll(blobDir, 0o755); err != nil {
+		return fmt.Errorf("create local blob directory: %w", err)
+	}
+
+	// The digest is only known once the content was read, so it is hashed
+	// while streaming to a temporary file.
+	file, err := os.CreateTemp(blobDir, "upload-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create local storage object: %w", err)
+	}
+	tmpPath := file.Name()
+	hash := sha256.New()
+	written, copyErr := io.Copy(file, io.TeeReader(reader, hash))
+	closeErr := file.Close()
+	if copyErr != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write local storage object: %w", copyErr)
+	}
+	if closeErr != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close local storage object: %w", closeErr)
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.commitBlob(tmpPath, digest); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	// A file stored under the key before deduplication was enabled.
+	_ = os.Remove(objectPath)
+
+	meta := l.newObjectMetadata(key, metadata, written)
+	meta.Blob = digest
+	if err := l.replaceMetadata(objectPath, meta); err != nil {
+		_ = l.releaseBlob(digest)
+		return err
+	}
+	return nil
+}
+
+// commitBlob moves the content at tmpPath to the blob of digest, unless the
+// blob already exists, and adds a reference to it. The caller must hold l.mu.
+func (l *Local) commitBlob(tmpPath, digest string) error {
+	blobPath, err := l.blobPath(digest)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(blobPath); err == nil {
+		_ = os.Remove(tmpPath)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(blobPath), 0o755); err != nil {
+			return fmt.Errorf("create local blob directory: %w", err)
+		}
+		if err := os.Rename(tmpPath, blobPath); err != nil {
+			return fmt.Errorf("commit local blob: %w", err)
+		}
+	}
+
+	refs, err := l.blobRefs(blobPath)
+	if err != nil {
+		return err
+	}
+	return l.writeBlobRefs(blobPath, refs+1)
+}
+
+// releaseBlob drops a reference to the blob of digest and deletes the blob
+// when it was the last one. The caller must hold l.mu.
+func (l *Local) releaseBlob(digest string) error {
+	blobPath, err := l.blobPath(digest)
+	if err != nil {
+		return err
+	}
+	refs, err := l.blobRefs(blobPath)
+	if err != nil {
+		return err
+	}
+	if refs > 1 {
+		return l.writeBlobRefs(blobPath, refs-1)
+	}
+	if err := os.Remove(blobPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("delete local blob: %w", err)
+	}
+	_ = os.Remove(l.refsPath(blobPath))
+	return nil
+}
+
+func (l *Local) blobPath(digest string) (string, error) {
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid blob digest %q", digest)
+	}
+	return filepath.Join(l.root, localBlobDir, digest[:2], digest), nil
+}
+
+func (l *Local) refsPath(blobPath string) string { return blobPath + ".refs" }
+
+// blobRefs returns the number of keys referring to a blob; zero when the
+// count was never written.
+func (l *Local) blobRefs(blobPath string) (int, error) {
+	data, err := os.ReadFile(l.refsPath(blobPath))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read local blob references: %w", err)
+	}
+	refs, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("parse local blob references: %w", err)
+	}
+	return refs, nil
+}
+
+func (l *Local) writeBlobRefs(blobPath string, refs int) error {
+	tmpPath := l.refsPath(blobPath) + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strconv.Itoa(refs)), 0o644); err != nil {
+		return fmt.Errorf("write local blob references: %w", err)
+	}
+	if err := os.Rename(tmpPath, l.refsPath(blobPath)); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write local blob references: %w", err)
+	}
+	return nil
+}
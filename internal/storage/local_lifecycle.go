@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+)
+
+// ExpiredObject describes an object the local backend deleted because its
+// retention ran out.
+type ExpiredObject struct {
+	Key      string
+	TaskID   uint64
+	OwnerID  uint64
+	StoredAt time.Time
+	ExpireAt time.Time
+}
+
+// WithLocalReapInterval sets how often Run deletes expired objects.
+// Defaults to 1h.
+func WithLocalReapInterval(d time.Duration) LocalOption {
+	return func(l *Local) {
+		if d > 0 {
+			l.reapInterval = d
+		}
+	}
+}
+
+// WithLocalAccountRetention overrides the retention of the objects owned by
+// an account. A zero retention keeps them until they are deleted.
+func WithLocalAccountRetention(ownerID uint64, d time.Duration) LocalOption {
+	return func(l *Local) {
+		if l.accountRetention == nil {
+			l.accountRetention = make(map[uint64]time.Duration)
+		}
+		l.accountRetention[ownerID] = d
+	}
+}
+
+// WithLocalExpiryHandler sets a function called for every object Reap
+// deletes, e.g. to mark the task that produced it as expired. Failures are
+// logged; the object stays deleted.
+func WithLocalExpiryHandler(fn func(ctx context.Context, obj ExpiredObject) error) LocalOption {
+	return func(l *Local) { l.onExpire = fn }
+}
+
+// LocalRetentionOptions builds the backend options for a JSON object of
+// per-account retentions keyed by account id, e.g. {"42":"168h"}. An empty
+// string is allowed.
+func LocalRetentionOptions(accountRetention string) ([]LocalOption, error) {
+	if accountRetention == "" {
+		return nil, nil
+	}
+	var retentions map[uint64]string
+	if err := json.Unmarshal([]byte(accountRetention), &retentions); err != nil {
+		return nil, fmt.Errorf("parse account retention: %w", err)
+	}
+	var opts []LocalOption
+	for id, s := range retentions {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse retention of account %d: %w", id, err)
+		}
+		opts = append(opts, WithLocalAccountRetention(id, d))
+	}
+	return opts, nil
+}
+
+func (l *Local) retentionOf(ownerID uint64) time.Duration {
+	if d, ok := l.accountRetention[ownerID]; ok {
+		return d
+	}
+	return l.defaultExpiry
+}
+
+// expireAt returns when the object expires under the current policy, or the
+// zero time when it is kept until deleted. The retention is applied when the
+// object is read rather than when it is stored, so policy changes also
+// apply to existing objects.
+func (l *Local) expireAt(meta *localObjectMetadata) time.Time {
+	if !meta.ExpireAt.IsZero() {
+		return meta.ExpireAt
+	}
+	retention := l.retentionOf(meta.OwnerID)
+	if retention <= 0 || meta.StoredAt.IsZero() {
+		return time.Time{}
+	}
+	return meta.StoredAt.Add(retention)
+}
+
+// Run deletes expired objects every reap interval until ctx is done.
+func (l *Local) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.reapInterval)
+	defer ticker.Stop()
+
+	for {
+		if expired, err := l.Reap(ctx); err != nil {
+			level.Error(l.logger).Log("msg", "failed to reap local storage", "err", err)
+		} else if len(expired) > 0 {
+			level.Info(l.logger).Log("msg", "reaped expired objects", "count", len(expired))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reap traverses the storage root and deletes the objects that have expired.
+// It returns the deleted objects and any error encountered during traversal.
+func (l *Local) Reap(ctx context.Context) ([]ExpiredObject, error) {
+	var expired []ExpiredObject
+	now := time.Now()
+
+	err := filepath.WalkDir(l.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		// Blobs are deleted with the last object referring to them.
+		if d.IsDir() && path == filepath.Join(l.root, localBlobDir) {
+			return filepath.SkipDir
+		}
+		// Objects are found by their metadata, as objects stored by content
+		// have no file of their own.
+		if d.IsDir() || !strings.HasSuffix(path, ".meta.json") {
+			return nil
+		}
+		path = strings.TrimSuffix(path, ".meta.json")
+
+		meta, err := l.readInternalMetadata(path)
+		if err != nil {
+			// If metadata is missing or corrupt, we skip it to avoid
+			// deleting data that might still be valid but orphaned.
+			return nil
+		}
+		expireAt := l.expireAt(meta)
+		if expireAt.IsZero() || now.Before(expireAt) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return nil
+		}
+		obj := ExpiredObject{
+			Key:      filepath.ToSlash(relPath),
+			TaskID:   meta.TaskID,
+			OwnerID:  meta.OwnerID,
+			StoredAt: meta.StoredAt,
+			ExpireAt: expireAt,
+		}
+		if err := l.Delete(ctx, obj.Key); err != nil {
+			return nil
+		}
+		expired = append(expired, obj)
+
+		if l.onExpire != nil {
+			if err := l.onExpire(ctx, obj); err != nil {
+				level.Warn(l.logger).Log("msg", "failed to handle expired object", "key", obj.Key, "task_id", obj.TaskID, "err", err)
+			}
+		}
+		return nil
+	})
+
+	return expired, err
+}
+
+// readInternalMetadata reads the full metadata of an object, including StoredAt.
+func (l *Local) readInternalMetadata(objectPath string) (*localObjectMetadata, error) {
+	data, err := os.ReadFile(l.metadataPath(objectPath))
+	if err != nil {
+		return nil, err
+	}
+	var meta localObjectMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
@@ -3,6 +3,8 @@ package storage_test
 import (
 	"context"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -70,3 +72,134 @@ func TestLocalBackend_RejectsTraversalKeys(t *testing.T) {
 	err = backend.Store(context.Background(), "../escape.txt", strings.NewReader("x"), nil)
 	assert.Error(t, err)
 }
+
+func TestLocalBackend_ReapDeletesExpiredObjects(t *testing.T) {
+	var handled []storage.ExpiredObject
+	backend, err := storage.NewLocalBackend(t.TempDir(),
+		storage.WithLocalAccountRetention(7, time.Nanosecond),
+		storage.WithLocalExpiryHandler(func(_ context.Context, obj storage.ExpiredObject) error {
+			handled = append(handled, obj)
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Without a default retention objects are kept until deleted.
+	require.NoError(t, backend.Store(ctx, "1/kept.txt", strings.NewReader("x"), &storage.FileMetadata{OwnerID: 3}))
+	require.NoError(t, backend.Store(ctx, "2/past.txt", strings.NewReader("x"), &storage.FileMetadata{
+		TaskID:   2,
+		OwnerID:  3,
+		ExpireAt: time.Now().Add(-time.Minute),
+	}))
+	require.NoError(t, backend.Store(ctx, "3/future.txt", strings.NewReader("x"), &storage.FileMetadata{
+		ExpireAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, backend.Store(ctx, "4/account.txt", strings.NewReader("x"), &storage.FileMetadata{
+		TaskID:  4,
+		OwnerID: 7,
+	}))
+
+	expired, err := backend.Reap(ctx)
+	require.NoError(t, err)
+	keys := []string{}
+	for _, obj := range expired {
+		keys = append(keys, obj.Key)
+	}
+	assert.ElementsMatch(t, []string{"2/past.txt", "4/account.txt"}, keys)
+	assert.ElementsMatch(t, expired, handled)
+
+	for key, want := range map[string]bool{
+		"1/kept.txt": true, "2/past.txt": false, "3/future.txt": true, "4/account.txt": false,
+	} {
+		exists, err := backend.Exists(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, want, exists, key)
+	}
+}
+
+func TestLocalRetentionOptions(t *testing.T) {
+	opts, err := storage.LocalRetentionOptions(`{"42":"168h"}`)
+	require.NoError(t, err)
+	backend, err := storage.NewLocalBackend(t.TempDir(), append(opts, storage.WithLocalExpiry(time.Hour))...)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, backend.Store(ctx, "a.txt", strings.NewReader("x"), &storage.FileMetadata{OwnerID: 42}))
+	info, err := backend.GetInfo(ctx, "a.txt")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(168*time.Hour), info.ExpireAt, time.Minute)
+
+	_, err = storage.LocalRetentionOptions(`{"42":"a week"}`)
+	assert.Error(t, err)
+}
+
+func TestLocalBackend_DedupSharesContentUntilLastDelete(t *testing.T) {
+	root := t.TempDir()
+	backend, err := storage.NewLocalBackend(root, storage.WithLocalDedup(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, backend.Store(ctx, "1/a.iso", strings.NewReader("same"), &storage.FileMetadata{FileName: "a.iso"}))
+	require.NoError(t, backend.Store(ctx, "2/b.iso", strings.NewReader("same"), &storage.FileMetadata{FileName: "b.iso"}))
+	require.NoError(t, backend.Store(ctx, "3/c.iso", strings.NewReader("other"), nil))
+
+	refs, err := filepath.Glob(filepath.Join(root, ".blobs", "*", "*.refs"))
+	require.NoError(t, err)
+	assert.Len(t, refs, 2, "one blob per distinct content")
+
+	pathA, err := backend.PathForKey("1/a.iso")
+	require.NoError(t, err)
+	pathB, err := backend.PathForKey("2/b.iso")
+	require.NoError(t, err)
+	assert.Equal(t, pathA, pathB)
+
+	info, err := backend.GetInfo(ctx, "2/b.iso")
+	require.NoError(t, err)
+	assert.Equal(t, "b.iso", info.FileName)
+	assert.Equal(t, int64(4), info.FileSize)
+
+	require.NoError(t, backend.Delete(ctx, "1/a.iso"))
+	exists, err := backend.Exists(ctx, "1/a.iso")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	rc, err := backend.GetWithRange(ctx, "2/b.iso", 1, 2)
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	_ = rc.Close()
+	assert.Equal(t, "am", string(got))
+
+	require.NoError(t, backend.Delete(ctx, "2/b.iso"))
+	_, err = os.Stat(pathA)
+	assert.True(t, os.IsNotExist(err))
+	exists, err = backend.Exists(ctx, "3/c.iso")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestLocalBackend_ReapReleasesDedupedContent(t *testing.T) {
+	backend, err := storage.NewLocalBackend(t.TempDir(), storage.WithLocalDedup(true))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, backend.Store(ctx, "1/a.txt", strings.NewReader("x"), &storage.FileMetadata{
+		TaskID:   1,
+		ExpireAt: time.Now().Add(-time.Minute),
+	}))
+	require.NoError(t, backend.Store(ctx, "2/b.txt", strings.NewReader("x"), nil))
+
+	expired, err := backend.Reap(ctx)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "1/a.txt", expired[0].Key)
+	assert.Equal(t, uint64(1), expired[0].TaskID)
+
+	rc, err := backend.Get(ctx, "2/b.txt")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	_ = rc.Close()
+	assert.Equal(t, "x", string(got))
+}
@@ -22,6 +22,7 @@ type Minio struct {
 	client        *minio.Client
 	bucket        string
 	defaultExpiry time.Duration // 0 means no expiry policy
+	dedup         bool
 }
 
 const userMetaExpiryAt = "expiry-at"
@@ -156,12 +157,22 @@ func (m *Minio) Store(ctx context.Context, key string, reader io.Reader, metadat
 		opts.UserMetadata[userMetaExpiryAt] = expiry.UTC().Format(time.RFC3339)
 	}
 
-	_, err := m.client.PutObject(ctx, m.bucket, key, reader, -1, opts)
-	return err
+	if m.dedup {
+		return m.storeByContent(ctx, key, reader, opts)
+	}
+	// The key may have referred to a blob before.
+	previous, _ := m.blobOf(ctx, key)
+	if _, err := m.client.PutObject(ctx, m.bucket, key, reader, -1, opts); err != nil {
+		return err
+	}
+	if previous != "" {
+		return m.releaseBlob(ctx, previous, key)
+	}
+	return nil
 }
 
 func (m *Minio) Get(ctx context.Context, key string) (io.ReadCloser, error) {
-	obj, err := m.client.GetObject(ctx, m.bucket, key, minio.GetObjectOptions{})
+	obj, err := m.client.GetObject(ctx, m.bucket, m.contentKey(ctx, key), minio.GetObjectOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +192,7 @@ func (m *Minio) GetWithRange(ctx context.Context, key string, start, end int64)
 	if err := opts.SetRange(start, end); err != nil {
 		return nil, err
 	}
-	obj, err := m.client.GetObject(ctx, m.bucket, key, opts)
+	obj, err := m.client.GetObject(ctx, m.bucket, m.contentKey(ctx, key), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -231,6 +242,14 @@ func (m *Minio) GetInfo(ctx context.Context, key string) (*FileMetadata, error)
 		Headers:      map[string]string{},
 	}
 
+	if digest := userMetadataValueCaseInsensitive(info.UserMetadata, userMetaBlob); digest != "" {
+		blob, err := m.client.StatObject(ctx, m.bucket, minioBlobKey(digest), minio.StatObjectOptions{})
+		if err != nil {
+			return nil, err
+		}
+		fm.FileSize = blob.Size
+	}
+
 	if storedFileName := userMetadataValueCaseInsensitive(info.UserMetadata, "filename"); storedFileName != "" {
 		fm.FileName = storedFileName
 	}
@@ -257,11 +276,24 @@ func (m *Minio) Exists(ctx context.Context, key string) (bool, error) {
 }
 
 func (m *Minio) Delete(ctx context.Context, key string) error {
-	return m.client.RemoveObject(ctx, m.bucket, key, minio.RemoveObjectOptions{})
+	digest, _ := m.blobOf(ctx, key)
+	if err := m.client.RemoveObject(ctx, m.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return err
+	}
+	if digest != "" {
+		return m.releaseBlob(ctx, digest, key)
+	}
+	return nil
 }
 
 func (m *Minio) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
-	presignedURL, err := m.client.PresignedGetObject(ctx, m.bucket, key, ttl, nil)
+	objectKey := key
+	var reqParams url.Values
+	if digest, info := m.blobOf(ctx, key); digest != "" {
+		objectKey = minioBlobKey(digest)
+		reqParams = blobResponseParams(key, info)
+	}
+	presignedURL, err := m.client.PresignedGetObject(ctx, m.bucket, objectKey, ttl, reqParams)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+
+	"github.com/google/uuid"
+	minio "github.com/minio/minio-go/v7"
+)
+
+const (
+	// userMetaBlob names the blob an object stored by content refers to.
+	userMetaBlob = "blob"
+
+	minioBlobPrefix   = "blobs/sha256/"
+	minioRefPrefix    = "refs/"
+	minioUploadPrefix = "uploads/"
+)
+
+// WithMinioDedup stores objects by content: the content is written once under
+// its SHA-256 digest and every key storing the same content is an empty
+// object referring to it. Each reference is recorded as an object under
+// refs/<digest>/, and the blob is deleted with the last key referring to
+// it. Objects stored before are still read as they are.
+func WithMinioDedup(enabled bool) MinioOption {
+	return func(m *Minio) { m.dedup = enabled }
+}
+
+// storeByContent uploads reader to the blob of its digest and makes key refer
+// to it.
+func (m *Minio) storeByContent(ctx context.Context, key string, reader io.Reader, opts minio.PutObjectOptions) error {
+	// The digest is only known once the content was read, so it is hashed
+	// while streaming to a temporary object.
+	uploadKey := minioUploadPrefix + uuid.NewString()
+	hash := sha256.New()
+	if _, err := m.client.PutObject(ctx, m.bucket, uploadKey, io.TeeReader(reader, hash), -1,
+		minio.PutObjectOptions{ContentType: opts.ContentType}); err != nil {
+		return err
+	}
+	defer m.client.RemoveObject(context.Background(), m.bucket, uploadKey, minio.RemoveObjectOptions{})
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	// Record the reference before the blob is written, so that a concurrent
+	// delete of another key does not remove the blob from under it.
+	if _, err := m.client.PutObject(ctx, m.bucket, minioRefKey(digest, key), bytes.NewReader(nil), 0,
+		minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("record blob reference: %w", err)
+	}
+	// The blob is copied even when it exists, which restarts its age for the
+	// bucket lifecycle rule like a new upload would.
+	if _, err := m.client.ComposeObject(ctx,
+		minio.CopyDestOptions{Bucket: m.bucket, Object: minioBlobKey(digest)},
+		minio.CopySrcOptions{Bucket: m.bucket, Object: uploadKey},
+	); err != nil {
+		_ = m.releaseBlob(context.Background(), digest, key)
+		return fmt.Errorf("commit blob: %w", err)
+	}
+
+	previous, _ := m.blobOf(ctx, key)
+	if opts.UserMetadata == nil {
+		opts.UserMetadata = make(map[string]string)
+	}
+	opts.UserMetadata[userMetaBlob] = digest
+	if _, err := m.client.PutObject(ctx, m.bucket, key, bytes.NewReader(nil), 0, opts); err != nil {
+		_ = m.releaseBlob(context.Background(), digest, key)
+		return err
+	}
+	if previous != "" && previous != digest {
+		return m.releaseBlob(ctx, previous, key)
+	}
+	return nil
+}
+
+// releaseBlob drops the reference of key to the blob of digest and deletes
+// the blob when no other key refers to it.
+func (m *Minio) releaseBlob(ctx context.Context, digest, key string) error {
+	if err := m.client.RemoveObject(ctx, m.bucket, minioRefKey(digest, key), minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("remove blob reference: %w", err)
+	}
+	// Stop the listing once the first reference was seen.
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for obj := range m.client.ListObjects(listCtx, m.bucket, minio.ListObjectsOptions{
+		Prefix:    minioRefPrefix + digest + "/",
+		Recursive: true,
+		MaxKeys:   1,
+	}) {
+		if obj.Err != nil {
+			return fmt.Errorf("list blob references: %w", obj.Err)
+		}
+		return nil
+	}
+	return m.client.RemoveObject(ctx, m.bucket, minioBlobKey(digest), minio.RemoveObjectOptions{})
+}
+
+// blobOf returns the digest of the blob key refers to, or "" when key does
+// not exist or was not stored by content, together with the object of key.
+func (m *Minio) blobOf(ctx context.Context, key string) (string, minio.ObjectInfo) {
+	info, err := m.client.StatObject(ctx, m.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return "", info
+	}
+	return userMetadataValueCaseInsensitive(info.UserMetadata, userMetaBlob), info
+}
+
+// contentKey returns the object holding the content of key: the blob it
+// refers to when it was stored by content, otherwise key itself. Failures
+// are left to the read of the returned object to report.
+func (m *Minio) contentKey(ctx context.Context, key string) string {
+	if digest, _ := m.blobOf(ctx, key); digest != "" {
+		return minioBlobKey(digest)
+	}
+	return key
+}
+
+// blobResponseParams names the file of an object stored by content in the
+// response to a presigned GET, as the blob itself is named by its digest.
+func blobResponseParams(key string, info minio.ObjectInfo) url.Values {
+	fileName := userMetadataValueCaseInsensitive(info.UserMetadata, "filename")
+	if fileName == "" {
+		fileName = extractFileNameFromStorageKey(key)
+	}
+	params := url.Values{}
+	params.Set("response-content-disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	if info.ContentType != "" {
+		params.Set("response-content-type", info.ContentType)
+	}
+	return params
+}
+
+func minioBlobKey(digest string) string { return minioBlobPrefix + digest }
+
+func minioRefKey(digest, key string) string { return minioRefPrefix + digest + "/" + key }
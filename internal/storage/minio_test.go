@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"path"
 	"strings"
 	"testing"
 	"time"
@@ -22,7 +23,7 @@ const (
 )
 
 // startMinio starts a MinIO container and returns a *storage.Minio and a cleanup function.
-func startMinio(t *testing.T) (*storage.Minio, func()) {
+func startMinio(t *testing.T, opts ...storage.MinioOption) (*storage.Minio, func()) {
 	t.Helper()
 	ctx := context.Background()
 	minioContainer, err := tcminio.Run(ctx,
@@ -33,7 +34,7 @@ func startMinio(t *testing.T) (*storage.Minio, func()) {
 	require.NoError(t, err, "failed to start MinIO container")
 	endpoint, err := minioContainer.ConnectionString(ctx)
 	require.NoError(t, err, "failed to get MinIO endpoint")
-	backend, err := storage.NewMinioBackend(endpoint, minioUser, minioPassword, false, testBucket)
+	backend, err := storage.NewMinioBackend(endpoint, minioUser, minioPassword, false, testBucket, opts...)
 	require.NoError(t, err, "failed to create minio backend")
 	return backend, func() {
 		if err := minioContainer.Terminate(ctx); err != nil {
@@ -223,3 +224,43 @@ func TestMinio_OverwriteExistingKey(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "updated", string(got), "overwritten object should return latest content")
 }
+
+func TestMinio_DedupSharesContentUntilLastDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	backend, cleanup := startMinio(t, storage.WithMinioDedup(true))
+	defer cleanup()
+	ctx := context.Background()
+
+	for _, key := range []string{"1/a.iso", "2/b.iso"} {
+		require.NoError(t, backend.Store(ctx, key, strings.NewReader("same content"), &storage.FileMetadata{
+			FileName: path.Base(key),
+		}))
+	}
+
+	info, err := backend.GetInfo(ctx, "2/b.iso")
+	require.NoError(t, err)
+	assert.Equal(t, "b.iso", info.FileName)
+	assert.Equal(t, int64(len("same content")), info.FileSize)
+
+	require.NoError(t, backend.Delete(ctx, "1/a.iso"))
+	exists, err := backend.Exists(ctx, "1/a.iso")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	rc, err := backend.Get(ctx, "2/b.iso")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	_ = rc.Close()
+	assert.Equal(t, "same content", string(got))
+
+	presigned, err := backend.PresignGet(ctx, "2/b.iso", time.Minute)
+	require.NoError(t, err)
+	assert.Contains(t, presigned, "blobs/sha256/")
+
+	require.NoError(t, backend.Delete(ctx, "2/b.iso"))
+	_, err = backend.Get(ctx, "2/b.iso")
+	assert.Error(t, err)
+}
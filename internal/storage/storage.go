@@ -48,12 +48,20 @@ type FileMetadata struct {
 	// be considered expired. Backends that support it will apply this as
 	// object metadata or a lifecycle rule.
 	ExpireAt time.Time `json:"expire_at,omitzero"`
+	// OwnerID and TaskID identify the account and task the object belongs
+	// to, when it belongs to one. The local backend applies the retention
+	// of the owner and reports both when the object expires.
+	OwnerID uint64 `json:"owner_id,omitzero"`
+	TaskID  uint64 `json:"task_id,omitzero"`
 }
 
 // TokenMetadata describes stored information for one-time download tokens.
 type TokenMetadata struct {
 	Key     string
 	OwnerID uint64
+	// TaskID is the task the file belongs to; zero for tokens issued before
+	// it was recorded.
+	TaskID  uint64
 	OneTime bool
 	Expires time.Time
 }
@@ -0,0 +1,208 @@
+package task
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// AuditAction is an action recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditTaskCreate      AuditAction = "task.create"
+	AuditTaskDelete      AuditAction = "task.delete"
+	AuditTaskPause       AuditAction = "task.pause"
+	AuditTaskResume      AuditAction = "task.resume"
+	AuditTaskCancel      AuditAction = "task.cancel"
+	AuditTaskRetry       AuditAction = "task.retry"
+	AuditTaskExtend      AuditAction = "task.extend"
+	AuditTaskStart       AuditAction = "task.start"
+	AuditTaskDownloadURL AuditAction = "task.download_url"
+	AuditTaskDownload    AuditAction = "task.download"
+	AuditTaskExpire      AuditAction = "task.expire"
+	// AuditTaskReject is a completed download rejected over the storage
+	// quota, whose stored file is deleted.
+	AuditTaskReject AuditAction = "task.reject"
+)
+
+// AuditActions are the actions that can be recorded.
+var AuditActions = []AuditAction{
+	AuditTaskCreate,
+	AuditTaskDelete,
+	AuditTaskPause,
+	AuditTaskResume,
+	AuditTaskCancel,
+	AuditTaskRetry,
+	AuditTaskExtend,
+	AuditTaskStart,
+	AuditTaskDownloadURL,
+	AuditTaskDownload,
+	AuditTaskExpire,
+	AuditTaskReject,
+}
+
+type AuditOutcome string
+
+const (
+	AuditSucceeded AuditOutcome = "SUCCEEDED"
+	// AuditDenied is an action the actor was not allowed to take.
+	AuditDenied AuditOutcome = "DENIED"
+	AuditFailed AuditOutcome = "FAILED"
+)
+
+// AuditEvent records who took an action on a task, from where and with
+// which outcome. Events are append-only.
+type AuditEvent struct {
+	ID   uint64
+	Time time.Time
+	// ActorAccountID is the account that acted; zero for actions the task
+	// service takes on its own and for downloads with a token URL, which
+	// anyone holding the URL can use.
+	ActorAccountID uint64
+	// ActorAPIKeyID is set when the actor authenticated with an API key.
+	ActorAPIKeyID uint64
+	Action        AuditAction
+	TaskID        uint64
+	// OfAccountID and WorkspaceID are the owner and workspace of the task.
+	// They are looked up from the task when zero.
+	OfAccountID uint64
+	WorkspaceID uint64
+	SourceIP    string
+	Outcome     AuditOutcome
+	// Message is the error of denied and failed actions.
+	Message string
+}
+
+// AuditFilter selects audit events. Without a WorkspaceID it selects the
+// events AccountID acted in and those on its personal tasks.
+type AuditFilter struct {
+	AccountID   uint64
+	WorkspaceID uint64
+	TaskID      uint64
+	Actions     []AuditAction
+	Outcome     AuditOutcome
+	From        *time.Time
+	To          *time.Time
+}
+
+// AuditQuery selects one page of the events matching Filter, newest first.
+type AuditQuery struct {
+	Filter AuditFilter
+	// BeforeID resumes the listing behind the event with that id.
+	BeforeID uint64
+	Limit    uint32
+}
+
+type ListAuditEventsParam struct {
+	Filter   AuditFilter
+	BeforeID uint64
+	Limit    int32
+}
+
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, e *AuditEvent) error
+	ListAuditEvents(ctx context.Context, query AuditQuery) ([]*AuditEvent, error)
+}
+
+// WithAuditRepository enables the audit log. Without it events are dropped
+// and listings are empty.
+func WithAuditRepository(r AuditRepository) ServiceOption {
+	return func(s *service) { s.audit = r }
+}
+
+// Validate checks the actions and outcome of the filter.
+func (f AuditFilter) Validate() error {
+	for _, a := range f.Actions {
+		if !slices.Contains(AuditActions, a) {
+			return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown audit action %q", a)}
+		}
+	}
+	if f.Outcome != "" && !validAuditOutcome(f.Outcome) {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown audit outcome %q", f.Outcome)}
+	}
+	return nil
+}
+
+func validAuditOutcome(o AuditOutcome) bool {
+	return o == AuditSucceeded || o == AuditDenied || o == AuditFailed
+}
+
+func (s *service) RecordAuditEvent(ctx context.Context, e *AuditEvent) error {
+	if s.audit == nil {
+		return nil
+	}
+	if !slices.Contains(AuditActions, e.Action) {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown audit action %q", e.Action)}
+	}
+	if !validAuditOutcome(e.Outcome) {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown audit outcome %q", e.Outcome)}
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	// Events of tasks that no longer exist keep the owner they came with.
+	if e.TaskID != 0 && e.OfAccountID == 0 {
+		t, err := s.repo.GetByID(ctx, e.TaskID)
+		if err != nil && !stderrors.Is(err, errors.ErrNotFound) {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get task", Cause: err}
+		}
+		if t != nil {
+			e.OfAccountID = t.OfAccountID
+			e.WorkspaceID = t.WorkspaceID
+		}
+	}
+	if err := s.audit.CreateAuditEvent(ctx, e); err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to record audit event", Cause: err}
+	}
+	return nil
+}
+
+func (s *service) ListAuditEvents(ctx context.Context, param *ListAuditEventsParam) ([]*AuditEvent, error) {
+	if s.audit == nil {
+		return nil, nil
+	}
+	if err := param.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	if param.Limit < 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "limit must be non-negative"}
+	}
+	limit := uint32(param.Limit)
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+
+	evs, err := s.audit.ListAuditEvents(ctx, AuditQuery{
+		Filter:   param.Filter,
+		BeforeID: param.BeforeID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list audit events", Cause: err}
+	}
+	return evs, nil
+}
+
+// recordAudit records an action the task service takes on its own, with an
+// optional message. Failures are logged; they do not fail the action.
+func (s *service) recordAudit(ctx context.Context, action AuditAction, t *Task, message string) {
+	err := s.RecordAuditEvent(ctx, &AuditEvent{
+		Action:      action,
+		TaskID:      t.ID,
+		OfAccountID: t.OfAccountID,
+		WorkspaceID: t.WorkspaceID,
+		Outcome:     AuditSucceeded,
+		Message:     message,
+	})
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to record audit event", "action", action, "task_id", t.ID, "err", err)
+	}
+}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+)
+
+type fakeAuditRepo struct {
+	events []*AuditEvent
+	query  AuditQuery
+}
+
+func (r *fakeAuditRepo) CreateAuditEvent(ctx context.Context, e *AuditEvent) error {
+	e.ID = uint64(len(r.events) + 1)
+	stored := *e
+	r.events = append(r.events, &stored)
+	return nil
+}
+
+func (r *fakeAuditRepo) ListAuditEvents(ctx context.Context, query AuditQuery) ([]*AuditEvent, error) {
+	r.query = query
+	return r.events, nil
+}
+
+func TestRecordAuditEvent_FillsInOwnerOfTask(t *testing.T) {
+	audit := &fakeAuditRepo{}
+	repo := &fakeRepo{task: &Task{ID: 7, OfAccountID: 3, WorkspaceID: 9}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithAuditRepository(audit))
+
+	err := svc.RecordAuditEvent(context.Background(), &AuditEvent{
+		ActorAccountID: 5,
+		Action:         AuditTaskCancel,
+		TaskID:         7,
+		SourceIP:       "203.0.113.7",
+		Outcome:        AuditDenied,
+		Message:        "permission denied",
+	})
+	require.NoError(t, err)
+	require.Len(t, audit.events, 1)
+	got := audit.events[0]
+	require.Equal(t, uint64(3), got.OfAccountID)
+	require.Equal(t, uint64(9), got.WorkspaceID)
+	require.False(t, got.Time.IsZero())
+
+	// Events of deleted tasks keep the owner they were recorded with.
+	repo.task = nil
+	err = svc.RecordAuditEvent(context.Background(), &AuditEvent{
+		Action:      AuditTaskDelete,
+		TaskID:      8,
+		OfAccountID: 5,
+		Outcome:     AuditSucceeded,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), audit.events[1].OfAccountID)
+}
+
+func TestRecordAuditEvent_RejectsUnknownActionAndOutcome(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, WithAuditRepository(&fakeAuditRepo{}))
+
+	err := svc.RecordAuditEvent(context.Background(), &AuditEvent{Action: "task.rename", Outcome: AuditSucceeded})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	err = svc.RecordAuditEvent(context.Background(), &AuditEvent{Action: AuditTaskCreate, Outcome: "MAYBE"})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestListAuditEvents_LimitsPage(t *testing.T) {
+	audit := &fakeAuditRepo{}
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, WithAuditRepository(audit))
+	ctx := context.Background()
+
+	_, err := svc.ListAuditEvents(ctx, &ListAuditEventsParam{Filter: AuditFilter{AccountID: 1}, BeforeID: 40})
+	require.NoError(t, err)
+	require.Equal(t, uint32(defaultListLimit), audit.query.Limit)
+	require.Equal(t, uint64(40), audit.query.BeforeID)
+
+	_, err = svc.ListAuditEvents(ctx, &ListAuditEventsParam{Filter: AuditFilter{AccountID: 1}, Limit: 5000})
+	require.NoError(t, err)
+	require.Equal(t, uint32(maxListLimit), audit.query.Limit)
+
+	_, err = svc.ListAuditEvents(ctx, &ListAuditEventsParam{
+		Filter: AuditFilter{AccountID: 1, Actions: []AuditAction{"task.rename"}},
+	})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestAuditLog_Disabled(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{})
+
+	require.NoError(t, svc.RecordAuditEvent(context.Background(), &AuditEvent{Action: AuditTaskCreate}))
+	evs, err := svc.ListAuditEvents(context.Background(), &ListAuditEventsParam{Filter: AuditFilter{AccountID: 1}})
+	require.NoError(t, err)
+	require.Empty(t, evs)
+}
@@ -0,0 +1,420 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+const defaultMaxBatchSize = 500
+
+// WithMaxBatchSize limits how many tasks CreateTasks and BulkTasks handle in
+// one call. Defaults to 500.
+func WithMaxBatchSize(n int) ServiceOption {
+	return func(s *service) {
+		if n > 0 {
+			s.maxBatchSize = n
+		}
+	}
+}
+
+// CreateTasksParam describes a batch of tasks. The sources of Tasks, URLList
+// and Metalink are combined, in that order.
+type CreateTasksParam struct {
+	OfAccountID uint64
+	WorkspaceID uint64
+	// Tasks are created as given, except that they belong to OfAccountID
+	// and WorkspaceID. They cannot be scheduled.
+	Tasks []*CreateTaskParam
+	// URLList holds one source URL per line. Blank lines and lines starting
+	// with # are skipped.
+	URLList string
+	// Metalink is a Metalink 3 or 4 document; a task is created for each
+	// file with its most preferred URL, name and strongest hash.
+	Metalink []byte
+	// Priority applies to the tasks of URLList and Metalink.
+	Priority Priority
+}
+
+// BulkAction is the change BulkTasks applies to each selected task.
+type BulkAction string
+
+const (
+	BulkActionPause  BulkAction = "PAUSE"
+	BulkActionResume BulkAction = "RESUME"
+	BulkActionCancel BulkAction = "CANCEL"
+	BulkActionDelete BulkAction = "DELETE"
+)
+
+// BulkTaskParam selects the tasks of an account either by id or by filter.
+// With a WorkspaceID the tasks of the workspace are selected instead of the
+// personal tasks of the account.
+type BulkTaskParam struct {
+	OfAccountID uint64
+	WorkspaceID uint64
+	Action      BulkAction
+	IDs         []uint64
+	Filter      *TaskFilter
+}
+
+// BulkTaskOutput reports the tasks the action was applied to and the ones it
+// was not, e.g. because they do not exist or are in the wrong status.
+type BulkTaskOutput struct {
+	Succeeded []uint64
+	Failed    []*BulkTaskFailure
+}
+
+type BulkTaskFailure struct {
+	TaskID  uint64
+	Message string
+}
+
+func (s *service) CreateTasks(ctx context.Context, param *CreateTasksParam) ([]*Task, error) {
+	if param == nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "batch is required"}
+	}
+	params := make([]*CreateTaskParam, 0, len(param.Tasks))
+	for _, p := range param.Tasks {
+		if p == nil {
+			continue
+		}
+		item := *p
+		params = append(params, &item)
+	}
+	for _, u := range parseURLList(param.URLList) {
+		params = append(params, &CreateTaskParam{SourceURL: u, Priority: param.Priority})
+	}
+	if len(param.Metalink) > 0 {
+		files, err := parseMetalink(param.Metalink)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range files {
+			p.Priority = param.Priority
+			params = append(params, p)
+		}
+	}
+
+	if len(params) == 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "batch contains no tasks"}
+	}
+	if len(params) > s.maxBatchSize {
+		return nil, &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("batch of %d tasks exceeds the limit of %d", len(params), s.maxBatchSize),
+		}
+	}
+
+	priorities := make([]Priority, len(params))
+	for i, p := range params {
+		p.OfAccountID = param.OfAccountID
+		p.WorkspaceID = param.WorkspaceID
+		if p.Schedule != nil {
+			return nil, &errors.Error{
+				Code:    errors.ErrCodeInvalidInput,
+				Message: fmt.Sprintf("task %d: batch tasks cannot be scheduled", i),
+			}
+		}
+		priority, _, err := s.validateCreateTask(p)
+		if err != nil {
+			return nil, batchTaskError(i, err)
+		}
+		priorities[i] = priority
+	}
+
+	if err := s.checkCreateQuota(ctx, param.OfAccountID, int64(len(params))); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*Task, len(params))
+	for i, p := range params {
+		t, err := s.newTask(ctx, p, priorities[i])
+		if err != nil {
+			return nil, batchTaskError(i, err)
+		}
+		tasks[i] = t
+	}
+
+	return s.createTasks(ctx, tasks)
+}
+
+// batchTaskError prefixes the message of err with the index of the task in
+// the batch.
+func batchTaskError(i int, err error) error {
+	var e *errors.Error
+	if stderrors.As(err, &e) {
+		return &errors.Error{Code: e.Code, Message: fmt.Sprintf("task %d: %s", i, e.Message), Cause: e.Cause}
+	}
+	return fmt.Errorf("task %d: %w", i, err)
+}
+
+func parseURLList(list string) []string {
+	var urls []string
+	sc := bufio.NewScanner(strings.NewReader(list))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls
+}
+
+// metalinkDocument covers both Metalink 4 (RFC 5854) and Metalink 3. XML
+// namespaces are ignored.
+type metalinkDocument struct {
+	Files   []metalinkFile `xml:"file"`
+	V3Files []metalinkFile `xml:"files>file"`
+}
+
+type metalinkFile struct {
+	Name     string         `xml:"name,attr"`
+	Hashes   []metalinkHash `xml:"hash"`
+	V3Hashes []metalinkHash `xml:"verification>hash"`
+	URLs     []metalinkURL  `xml:"url"`
+	V3URLs   []metalinkURL  `xml:"resources>url"`
+}
+
+type metalinkHash struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type metalinkURL struct {
+	// Priority ranks Metalink 4 URLs, lowest first; Preference ranks
+	// Metalink 3 URLs, highest first.
+	Priority   int    `xml:"priority,attr"`
+	Preference int    `xml:"preference,attr"`
+	Type       string `xml:"type,attr"`
+	URL        string `xml:",chardata"`
+}
+
+// metalinkHashTypes lists the supported hash types, strongest first.
+var metalinkHashTypes = []string{"sha512", "sha256", "sha1", "md5"}
+
+func parseMetalink(data []byte) ([]*CreateTaskParam, error) {
+	var doc metalinkDocument
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&doc); err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "invalid metalink document", Cause: err}
+	}
+
+	var params []*CreateTaskParam
+	for i, f := range append(doc.Files, doc.V3Files...) {
+		src := metalinkSource(f)
+		if src == "" {
+			return nil, &errors.Error{
+				Code:    errors.ErrCodeInvalidInput,
+				Message: fmt.Sprintf("metalink file %d has no supported URL", i),
+			}
+		}
+		params = append(params, &CreateTaskParam{
+			FileName:  strings.TrimSpace(f.Name),
+			SourceURL: src,
+			Checksum:  metalinkChecksum(append(f.Hashes, f.V3Hashes...)),
+		})
+	}
+	return params, nil
+}
+
+// metalinkSource returns the most preferred URL of f. Metalink 3 resources
+// that point to other protocols, e.g. torrent files, are skipped.
+func metalinkSource(f metalinkFile) string {
+	var (
+		best     string
+		bestRank int
+	)
+	for _, u := range f.URLs {
+		rank := u.Priority
+		if rank <= 0 {
+			// URLs without a priority come last.
+			rank = 1 << 30
+		}
+		if src := strings.TrimSpace(u.URL); src != "" && (best == "" || rank < bestRank) {
+			best, bestRank = src, rank
+		}
+	}
+	if best != "" {
+		return best
+	}
+	for _, u := range f.V3URLs {
+		switch strings.ToLower(u.Type) {
+		case "", "http", "https", "ftp", "sftp":
+		default:
+			continue
+		}
+		// Higher preferences come first.
+		rank := -u.Preference
+		if src := strings.TrimSpace(u.URL); src != "" && (best == "" || rank < bestRank) {
+			best, bestRank = src, rank
+		}
+	}
+	return best
+}
+
+func metalinkChecksum(hashes []metalinkHash) *ChecksumInfo {
+	for _, want := range metalinkHashTypes {
+		for _, h := range hashes {
+			algo := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(h.Type)), "-", "")
+			if algo == want && strings.TrimSpace(h.Value) != "" {
+				return &ChecksumInfo{ChecksumType: algo, ChecksumValue: strings.TrimSpace(h.Value)}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *service) BulkTasks(ctx context.Context, param *BulkTaskParam) (*BulkTaskOutput, error) {
+	if param == nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "bulk request is required"}
+	}
+	var (
+		check func(*Task) error
+		apply func(context.Context, *Task) error
+	)
+	switch param.Action {
+	case BulkActionPause:
+		check, apply = checkPauseTask, s.pauseTask
+	case BulkActionResume:
+		check, apply = checkResumeTask, s.resumeTask
+	case BulkActionCancel:
+		check, apply = checkCancelTask, s.cancelTask
+	case BulkActionDelete:
+		check, apply = func(*Task) error { return nil }, s.deleteTask
+	default:
+		return nil, &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("unsupported bulk action %q", param.Action),
+		}
+	}
+
+	out := &BulkTaskOutput{}
+	tasks, err := s.selectBulkTasks(ctx, param, out)
+	if err != nil {
+		return nil, err
+	}
+
+	var eligible []*Task
+	for _, t := range tasks {
+		if err := check(t); err != nil {
+			out.Failed = append(out.Failed, &BulkTaskFailure{TaskID: t.ID, Message: errorMessage(err)})
+			continue
+		}
+		eligible = append(eligible, t)
+	}
+	if len(eligible) == 0 {
+		return out, nil
+	}
+
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		for _, t := range eligible {
+			if err := apply(ctx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, t := range eligible {
+		if param.Action == BulkActionDelete {
+			s.releaseDeletedTaskFile(ctx, t)
+			s.emit(&TaskEvent{
+				Type:        TaskEventDeleted,
+				TaskID:      t.ID,
+				OfAccountID: t.OfAccountID,
+				WorkspaceID: t.WorkspaceID,
+			})
+		} else {
+			s.emitStatus(t)
+		}
+		out.Succeeded = append(out.Succeeded, t.ID)
+	}
+	return out, nil
+}
+
+// selectBulkTasks loads the tasks of the account or workspace selected by
+// param. Ids of missing tasks and tasks of others are added to out as
+// failures.
+func (s *service) selectBulkTasks(ctx context.Context, param *BulkTaskParam, out *BulkTaskOutput) ([]*Task, error) {
+	switch {
+	case len(param.IDs) > 0 && param.Filter != nil:
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "select tasks by ids or by filter, not both"}
+	case len(param.IDs) > 0:
+		ids := slices.Clone(param.IDs)
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+		if len(ids) > s.maxBatchSize {
+			return nil, &errors.Error{
+				Code:    errors.ErrCodeInvalidInput,
+				Message: fmt.Sprintf("%d task ids exceed the limit of %d", len(ids), s.maxBatchSize),
+			}
+		}
+		tasks := make([]*Task, 0, len(ids))
+		for _, id := range ids {
+			t, err := s.repo.GetByID(ctx, id)
+			if err != nil && !stderrors.Is(err, errors.ErrNotFound) {
+				return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get task", Cause: err}
+			}
+			if t == nil || !inScope(t, param.OfAccountID, param.WorkspaceID) {
+				out.Failed = append(out.Failed, &BulkTaskFailure{TaskID: id, Message: "Task not found"})
+				continue
+			}
+			tasks = append(tasks, t)
+		}
+		return tasks, nil
+	case param.Filter != nil:
+		if err := param.Filter.Validate(); err != nil {
+			return nil, err
+		}
+		filter := *param.Filter
+		filter.OfAccountID = param.OfAccountID
+		filter.WorkspaceID = param.WorkspaceID
+		// One task more than the batch size tells an oversized selection apart.
+		tasks, err := s.repo.ListByAccountID(ctx, TaskQuery{
+			Filter:  filter,
+			SortBy:  SortByCreatedAt,
+			SortAsc: true,
+			Limit:   uint32(s.maxBatchSize) + 1,
+		})
+		if err != nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to list tasks", Cause: err}
+		}
+		if len(tasks) > s.maxBatchSize {
+			return nil, &errors.Error{
+				Code:    errors.ErrCodeInvalidInput,
+				Message: fmt.Sprintf("more than %d tasks match the filter", s.maxBatchSize),
+			}
+		}
+		return tasks, nil
+	default:
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "task ids or a filter are required"}
+	}
+}
+
+// inScope reports whether t is a task of workspaceID or, when it is zero, a
+// personal task of ofAccountID.
+func inScope(t *Task, ofAccountID, workspaceID uint64) bool {
+	if workspaceID != 0 {
+		return t.WorkspaceID == workspaceID
+	}
+	return t.WorkspaceID == 0 && t.OfAccountID == ofAccountID
+}
+
+func errorMessage(err error) string {
+	var e *errors.Error
+	if stderrors.As(err, &e) {
+		return e.Message
+	}
+	return err.Error()
+}
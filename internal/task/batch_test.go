@@ -0,0 +1,306 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
+)
+
+type countingTxManager struct {
+	calls int
+}
+
+func (m *countingTxManager) DoInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
+}
+
+// bulkRepo keeps tasks by id so that bulk selections can be tested.
+type bulkRepo struct {
+	fakeRepo
+	tasks   map[uint64]*Task
+	deleted []uint64
+}
+
+func (r *bulkRepo) GetByID(ctx context.Context, id uint64) (*Task, error) {
+	t, ok := r.tasks[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	cloned := *t
+	return &cloned, nil
+}
+
+func (r *bulkRepo) ListByAccountID(ctx context.Context, query TaskQuery) ([]*Task, error) {
+	var ids []uint64
+	for id, t := range r.tasks {
+		if inScope(t, query.Filter.OfAccountID, query.Filter.WorkspaceID) && (query.Filter.Search == "" ||
+			strings.Contains(strings.ToLower(t.FileName), strings.ToLower(query.Filter.Search))) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	var out []*Task
+	for _, id := range ids[min(int(query.Offset), len(ids)):min(int(query.Offset+query.Limit), len(ids))] {
+		cloned := *r.tasks[id]
+		out = append(out, &cloned)
+	}
+	return out, nil
+}
+
+func (r *bulkRepo) Delete(ctx context.Context, id uint64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// flatTxManager fails when a transaction is started inside another one, as
+// the MySQL and SQLite managers would start an unrelated transaction then.
+type flatTxManager struct {
+	committed bool
+}
+
+type inTxKey struct{}
+
+func (m *flatTxManager) DoInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(inTxKey{}) != nil {
+		return errors.New("nested transaction")
+	}
+	if err := fn(context.WithValue(ctx, inTxKey{}, true)); err != nil {
+		return err
+	}
+	m.committed = true
+	return nil
+}
+
+const testMetalink4 = `<?xml version="1.0" encoding="UTF-8"?>
+<metalink xmlns="urn:ietf:params:xml:ns:metalink">
+  <file name="example.iso">
+    <hash type="md5">5f4dcc3b5aa765d61d8327deb882cf99</hash>
+    <hash type="sha-256">9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08</hash>
+    <url priority="2">https://mirror2.example.com/example.iso</url>
+    <url priority="1">https://mirror1.example.com/example.iso</url>
+  </file>
+</metalink>`
+
+func TestCreateTasks_CombinesSourcesInOneTransaction(t *testing.T) {
+	repo := &fakeRepo{}
+	tx := &countingTxManager{}
+	fakeMsgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(fakeMsgPub), tx)
+
+	created, err := svc.CreateTasks(context.Background(), &CreateTasksParam{
+		OfAccountID: 7,
+		Tasks: []*CreateTaskParam{
+			{OfAccountID: 99, SourceURL: "https://example.com/a.zip", Priority: PriorityHigh},
+		},
+		URLList:  "https://example.com/b.zip\n\n# mirrors\n  ftp://example.com/c.zip  \n",
+		Metalink: []byte(testMetalink4),
+		Priority: PriorityLow,
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 4)
+	assert.Equal(t, 1, tx.calls)
+	assert.Equal(t, string(events.EventTaskCreated), fakeMsgPub.topic)
+	assert.Len(t, fakeMsgPub.msgs, 4)
+
+	for _, task := range created {
+		assert.Equal(t, uint64(7), task.OfAccountID)
+	}
+	assert.Equal(t, PriorityHigh, created[0].Priority)
+	assert.Equal(t, "https://example.com/b.zip", created[1].SourceURL)
+	assert.Equal(t, PriorityLow, created[1].Priority)
+	assert.Equal(t, "ftp://example.com/c.zip", created[2].SourceURL)
+	assert.Equal(t, SourceFTP, created[2].SourceType)
+
+	assert.Equal(t, "https://mirror1.example.com/example.iso", created[3].SourceURL)
+	assert.Equal(t, "example.iso", created[3].FileName)
+	require.NotNil(t, created[3].Checksum)
+	assert.Equal(t, "sha256", created[3].Checksum.ChecksumType)
+}
+
+func TestCreateTasks_RejectsWholeBatch(t *testing.T) {
+	fakeMsgPub := &fakeMessagePublisher{}
+	svc := NewService(&fakeRepo{}, *NewEventPublisher(fakeMsgPub), fakeTxManager{}, WithMaxBatchSize(2))
+
+	_, err := svc.CreateTasks(context.Background(), &CreateTasksParam{
+		OfAccountID: 7,
+		URLList:     "https://example.com/a.zip\n",
+		Tasks:       []*CreateTaskParam{{SourceURL: "https://example.com/b.zip", Priority: "URGENT"}},
+	})
+	require.Error(t, err)
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+	assert.Contains(t, err.Error(), "task 0:")
+
+	_, err = svc.CreateTasks(context.Background(), &CreateTasksParam{
+		OfAccountID: 7,
+		URLList:     "https://example.com/a.zip\nhttps://example.com/b.zip\nhttps://example.com/c.zip\n",
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	_, err = svc.CreateTasks(context.Background(), &CreateTasksParam{OfAccountID: 7, URLList: "# nothing\n"})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	assert.Empty(t, fakeMsgPub.msgs)
+}
+
+func TestCreateTasks_ChecksActiveTaskQuotaForWholeBatch(t *testing.T) {
+	repo := &fakeRepo{usage: &Usage{ActiveTasks: 8}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithDefaultQuota(Quota{MaxActiveTasks: 10}))
+
+	_, err := svc.CreateTasks(context.Background(), &CreateTasksParam{
+		OfAccountID: 7,
+		URLList:     "https://example.com/a.zip\nhttps://example.com/b.zip\nhttps://example.com/c.zip\n",
+	})
+	require.Error(t, err)
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeTooManyRequests))
+	assert.Nil(t, repo.created)
+}
+
+func TestParseMetalink_Version3(t *testing.T) {
+	params, err := parseMetalink([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metalink version="3.0" xmlns="http://www.metalinker.org/">
+  <files>
+    <file name="example.tar.gz">
+      <verification>
+        <hash type="sha1">a94a8fe5ccb19ba61c4c0873d391e987982fbbd3</hash>
+      </verification>
+      <resources>
+        <url type="bittorrent" preference="100">https://example.com/example.torrent</url>
+        <url type="http" preference="50">https://slow.example.com/example.tar.gz</url>
+        <url type="https" preference="90">https://fast.example.com/example.tar.gz</url>
+      </resources>
+    </file>
+  </files>
+</metalink>`))
+	require.NoError(t, err)
+	require.Len(t, params, 1)
+	assert.Equal(t, "example.tar.gz", params[0].FileName)
+	assert.Equal(t, "https://fast.example.com/example.tar.gz", params[0].SourceURL)
+	require.NotNil(t, params[0].Checksum)
+	assert.Equal(t, "sha1", params[0].Checksum.ChecksumType)
+
+	_, err = parseMetalink([]byte("not xml"))
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestBulkTasks_ByIDsReportsFailures(t *testing.T) {
+	repo := &bulkRepo{tasks: map[uint64]*Task{
+		1: {ID: 1, OfAccountID: 7, Status: StatusDownloading},
+		2: {ID: 2, OfAccountID: 7, Status: StatusPaused},
+		3: {ID: 3, OfAccountID: 8, Status: StatusDownloading},
+		5: {ID: 5, OfAccountID: 7, Status: StatusDownloading},
+	}}
+	tx := &countingTxManager{}
+	fakeMsgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(fakeMsgPub), tx)
+
+	out, err := svc.BulkTasks(context.Background(), &BulkTaskParam{
+		OfAccountID: 7,
+		Action:      BulkActionPause,
+		IDs:         []uint64{5, 1, 2, 3, 4, 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 5}, out.Succeeded)
+	assert.Equal(t, 1, tx.calls)
+	assert.Len(t, fakeMsgPub.msgs, 2)
+	assert.Equal(t, StatusPaused, repo.updated.Status)
+
+	failed := map[uint64]string{}
+	for _, f := range out.Failed {
+		failed[f.TaskID] = f.Message
+	}
+	assert.Equal(t, map[uint64]string{
+		2: "Only downloading tasks can be paused",
+		3: "Task not found",
+		4: "Task not found",
+	}, failed)
+}
+
+func TestBulkTasks_SelectsWorkspaceOrPersonalTasks(t *testing.T) {
+	repo := &bulkRepo{tasks: map[uint64]*Task{
+		1: {ID: 1, OfAccountID: 7, Status: StatusDownloading},
+		2: {ID: 2, OfAccountID: 7, WorkspaceID: 3, Status: StatusDownloading},
+		3: {ID: 3, OfAccountID: 8, WorkspaceID: 3, Status: StatusDownloading},
+		4: {ID: 4, OfAccountID: 8, WorkspaceID: 4, Status: StatusDownloading},
+	}}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{})
+
+	out, err := svc.BulkTasks(context.Background(), &BulkTaskParam{
+		OfAccountID: 7,
+		WorkspaceID: 3,
+		Action:      BulkActionPause,
+		IDs:         []uint64{1, 2, 3, 4},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 3}, out.Succeeded)
+	assert.Len(t, out.Failed, 2)
+
+	// Personal selections leave out the workspace tasks of the account.
+	out, err = svc.BulkTasks(context.Background(), &BulkTaskParam{
+		OfAccountID: 7,
+		Action:      BulkActionDelete,
+		Filter:      &TaskFilter{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, out.Succeeded)
+}
+
+func TestBulkTasks_ByFilter(t *testing.T) {
+	repo := &bulkRepo{tasks: map[uint64]*Task{
+		1: {ID: 1, OfAccountID: 7, FileName: "Nightly.iso", Status: StatusCompleted},
+		2: {ID: 2, OfAccountID: 7, FileName: "release.zip", Status: StatusCompleted},
+		3: {ID: 3, OfAccountID: 7, FileName: "nightly.iso", Status: StatusFailed},
+		4: {ID: 4, OfAccountID: 8, FileName: "nightly.iso", Status: StatusCompleted},
+	}}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{}, WithMaxBatchSize(2))
+
+	out, err := svc.BulkTasks(context.Background(), &BulkTaskParam{
+		OfAccountID: 7,
+		Action:      BulkActionDelete,
+		Filter:      &TaskFilter{Search: "NIGHTLY"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 3}, out.Succeeded)
+	assert.Empty(t, out.Failed)
+	assert.Equal(t, []uint64{1, 3}, repo.deleted)
+
+	// More matching tasks than the batch size are rejected as a whole.
+	_, err = svc.BulkTasks(context.Background(), &BulkTaskParam{
+		OfAccountID: 7,
+		Action:      BulkActionCancel,
+		Filter:      &TaskFilter{},
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	_, err = svc.BulkTasks(context.Background(), &BulkTaskParam{OfAccountID: 7, Action: BulkActionCancel})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestBulkTasks_DeletesInOneTransaction(t *testing.T) {
+	repo := &bulkRepo{tasks: map[uint64]*Task{
+		1: {ID: 1, OfAccountID: 7, Status: StatusCompleted, StoragePath: "a"},
+		2: {ID: 2, OfAccountID: 7, Status: StatusFailed},
+	}}
+	tx := &flatTxManager{}
+	files := &fakeFileStore{}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), tx, WithFileStore(files))
+
+	out, err := svc.BulkTasks(context.Background(), &BulkTaskParam{
+		OfAccountID: 7,
+		Action:      BulkActionDelete,
+		IDs:         []uint64{1, 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, out.Succeeded)
+	assert.Equal(t, []uint64{1, 2}, repo.deleted)
+	assert.True(t, tx.committed)
+	assert.Equal(t, []string{"a"}, files.deleted)
+}
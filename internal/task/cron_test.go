@@ -0,0 +1,61 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_RejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		_, err := parseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
+
+func TestCronSpec_Next(t *testing.T) {
+	from := time.Date(2025, 1, 31, 10, 30, 15, 0, time.UTC) // Friday
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 31, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 1, 31, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, 2, 1, 3, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * MON-FRI", time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 * *", time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches.
+		{"0 0 15 * SUN", time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		spec, err := parseCron(tc.expr)
+		require.NoError(t, err, tc.expr)
+		require.Equal(t, tc.want, spec.Next(from), tc.expr)
+	}
+}
+
+func TestCronSpec_NextNeverMatches(t *testing.T) {
+	spec, err := parseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, spec.Next(time.Now()).IsZero())
+
+	_, err = nextRunAt("0 0 31 2 *", nil, time.Now())
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	stderrors "errors"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
@@ -12,6 +13,7 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/internal/task"
 	pb "github.com/yuisofull/goload/internal/task/pb"
@@ -59,6 +61,8 @@ type RetryTaskRequest pb.RetryTaskRequest
 
 type RetryTaskResponse pb.RetryTaskResponse
 
+type ExtendTaskRetentionRequest pb.ExtendTaskRetentionRequest
+
 type CheckFileExistsRequest pb.CheckFileExistsRequest
 
 type CheckFileExistsResponse pb.CheckFileExistsResponse
@@ -71,6 +75,70 @@ type GenerateDownloadURLRequest pb.GenerateDownloadURLRequest
 
 type GenerateDownloadURLResponse pb.GenerateDownloadURLResponse
 
+type GetUsageRequest pb.GetUsageRequest
+
+type GetUsageResponse pb.GetUsageResponse
+
+type ListSchedulesRequest pb.ListSchedulesRequest
+
+type ListSchedulesResponse pb.ListSchedulesResponse
+
+type GetScheduleRequest pb.GetScheduleRequest
+
+type UpdateScheduleRequest pb.UpdateScheduleRequest
+
+type ScheduleResponse pb.ScheduleResponse
+
+type DeleteScheduleRequest pb.DeleteScheduleRequest
+
+type DeleteScheduleResponse pb.DeleteScheduleResponse
+
+type WatchTasksRequest pb.WatchTasksRequest
+
+type TaskEvent pb.TaskEvent
+
+// WatchTasksResponse carries the event stream of a WatchTasks call. The
+// channel is closed when the watch ends.
+type WatchTasksResponse struct {
+	Events <-chan *TaskEvent
+}
+
+type CreateWebhookRequest pb.CreateWebhookRequest
+
+type ListWebhooksRequest pb.ListWebhooksRequest
+
+type ListWebhooksResponse pb.ListWebhooksResponse
+
+type GetWebhookRequest pb.GetWebhookRequest
+
+type UpdateWebhookRequest pb.UpdateWebhookRequest
+
+type WebhookResponse pb.WebhookResponse
+
+type DeleteWebhookRequest pb.DeleteWebhookRequest
+
+type DeleteWebhookResponse pb.DeleteWebhookResponse
+
+type ListWebhookDeliveriesRequest pb.ListWebhookDeliveriesRequest
+
+type ListWebhookDeliveriesResponse pb.ListWebhookDeliveriesResponse
+
+type CreateTasksRequest pb.CreateTasksRequest
+
+type CreateTasksResponse pb.CreateTasksResponse
+
+type BulkTasksRequest pb.BulkTasksRequest
+
+type BulkTasksResponse pb.BulkTasksResponse
+
+type RecordAuditEventRequest pb.RecordAuditEventRequest
+
+type RecordAuditEventResponse pb.RecordAuditEventResponse
+
+type ListAuditEventsRequest pb.ListAuditEventsRequest
+
+type ListAuditEventsResponse pb.ListAuditEventsResponse
+
 type UpdateTaskChecksumRequest struct {
 	TaskId   uint64
 	Checksum *pb.ChecksumInfo
@@ -94,9 +162,27 @@ type Set struct {
 	CancelTaskEndpoint      endpoint.Endpoint
 	RetryTaskEndpoint       endpoint.Endpoint
 	CheckFileExistsEndpoint endpoint.Endpoint
-	GetTaskProgressEndpoint endpoint.Endpoint
+	// ExtendTaskRetentionEndpoint keeps the stored file of a completed task longer.
+	ExtendTaskRetentionEndpoint endpoint.Endpoint
+	GetTaskProgressEndpoint     endpoint.Endpoint
 	// GenerateDownloadURLEndpoint is optional and may be nil when not supported.
-	GenerateDownloadURLEndpoint endpoint.Endpoint
+	GenerateDownloadURLEndpoint   endpoint.Endpoint
+	GetUsageEndpoint              endpoint.Endpoint
+	ListSchedulesEndpoint         endpoint.Endpoint
+	GetScheduleEndpoint           endpoint.Endpoint
+	UpdateScheduleEndpoint        endpoint.Endpoint
+	DeleteScheduleEndpoint        endpoint.Endpoint
+	WatchTasksEndpoint            endpoint.Endpoint
+	CreateWebhookEndpoint         endpoint.Endpoint
+	ListWebhooksEndpoint          endpoint.Endpoint
+	GetWebhookEndpoint            endpoint.Endpoint
+	UpdateWebhookEndpoint         endpoint.Endpoint
+	DeleteWebhookEndpoint         endpoint.Endpoint
+	ListWebhookDeliveriesEndpoint endpoint.Endpoint
+	CreateTasksEndpoint           endpoint.Endpoint
+	BulkTasksEndpoint             endpoint.Endpoint
+	RecordAuditEventEndpoint      endpoint.Endpoint
+	ListAuditEventsEndpoint       endpoint.Endpoint
 	// Internal endpoints
 	UpdateTaskStoragePathEndpoint endpoint.Endpoint
 	UpdateTaskStatusEndpoint      endpoint.Endpoint
@@ -110,21 +196,7 @@ type Set struct {
 // Implement task.Service on the Set for GRPC client usage
 
 func (e *Set) CreateTask(ctx context.Context, param *task.CreateTaskParam) (*task.Task, error) {
-	req := &CreateTaskRequest{
-		OfAccountId: param.OfAccountID,
-		FileName:    param.FileName,
-		SourceUrl:   param.SourceURL,
-		SourceType:  pb.SourceType(pb.SourceType_value[string(param.SourceType)]),
-		SourceAuth:  toPBAuthConfig(param.SourceAuth),
-		Metadata:    toPBStruct(param.Metadata),
-	}
-	if param.Checksum != nil {
-		req.Checksum = &pb.ChecksumInfo{
-			ChecksumType:  param.Checksum.ChecksumType,
-			ChecksumValue: param.Checksum.ChecksumValue,
-		}
-	}
-	resp, err := e.CreateTaskEndpoint(ctx, req)
+	resp, err := e.CreateTaskEndpoint(ctx, (*CreateTaskRequest)(toPBCreateTaskRequest(param)))
 	if err != nil {
 		return nil, err
 	}
@@ -143,17 +215,18 @@ func (e *Set) GetTask(ctx context.Context, id uint64) (*task.Task, error) {
 
 func (e *Set) ListTasks(ctx context.Context, param *task.ListTasksParam) (*task.ListTasksOutput, error) {
 	resp, err := e.ListTasksEndpoint(ctx, &ListTasksRequest{
-		Filter:  &pb.TaskFilter{OfAccountId: param.Filter.OfAccountID},
+		Filter:  toPBTaskFilter(param.Filter),
 		Offset:  param.Offset,
 		Limit:   param.Limit,
 		SortBy:  param.SortBy,
 		SortAsc: param.SortAsc,
+		Cursor:  param.Cursor,
 	})
 	if err != nil {
 		return nil, err
 	}
 	out := resp.(*ListTasksResponse)
-	res := &task.ListTasksOutput{Total: out.Total}
+	res := &task.ListTasksOutput{Total: out.Total, NextCursor: out.NextCursor}
 	for _, t := range out.Tasks {
 		res.Tasks = append(res.Tasks, fromPBTask(t))
 	}
@@ -185,6 +258,15 @@ func (e *Set) RetryTask(ctx context.Context, taskID uint64) error {
 	return err
 }
 
+func (e *Set) ExtendTaskRetention(ctx context.Context, param *task.ExtendTaskRetentionParam) (*task.Task, error) {
+	resp, err := e.ExtendTaskRetentionEndpoint(ctx, &ExtendTaskRetentionRequest{TaskId: param.TaskID, Days: param.Days})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*TaskResponse)
+	return fromPBTask(out.Task), nil
+}
+
 func (e *Set) UpdateTaskStoragePath(ctx context.Context, id uint64, storagePath string) error {
 	_, err := e.UpdateTaskStoragePathEndpoint(ctx, &UpdateTaskStoragePathRequest{Id: id, StoragePath: storagePath})
 	return err
@@ -266,6 +348,18 @@ func (e *Set) UpdateStorageInfo(ctx context.Context, id uint64, storageType stor
 	return errors.New("UpdateStorageInfo not available via gRPC client proxy")
 }
 
+func (e *Set) UpdateSourceValidators(ctx context.Context, id uint64, etag, lastModified string) error {
+	// UpdateSourceValidators is only called by the task service's own event
+	// consumer and has no gRPC endpoint.
+	return errors.New("UpdateSourceValidators not available via gRPC client proxy")
+}
+
+func (e *Set) UpdateTaskWorker(ctx context.Context, id uint64, workerID string) error {
+	// UpdateTaskWorker is only called by the task service's own event
+	// consumer and has no gRPC endpoint.
+	return errors.New("UpdateTaskWorker not available via gRPC client proxy")
+}
+
 // GenerateDownloadURL forwards to the underlying endpoint if available.
 func (e *Set) GenerateDownloadURL(
 	ctx context.Context,
@@ -288,6 +382,244 @@ func (e *Set) GenerateDownloadURL(
 	return out.Url, out.Direct, nil
 }
 
+func (e *Set) GetUsage(ctx context.Context, ofAccountID uint64) (*task.Usage, error) {
+	resp, err := e.GetUsageEndpoint(ctx, &GetUsageRequest{OfAccountId: ofAccountID})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*GetUsageResponse)
+	return &task.Usage{
+		ActiveTasks: out.ActiveTasks,
+		StoredBytes: out.StoredBytes,
+		BytesToday:  out.BytesToday,
+		Quota: task.Quota{
+			MaxActiveTasks: out.MaxActiveTasks,
+			MaxStoredBytes: out.MaxStoredBytes,
+			MaxBytesPerDay: out.MaxBytesPerDay,
+		},
+	}, nil
+}
+
+func (e *Set) ListSchedules(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*task.Schedule, error) {
+	resp, err := e.ListSchedulesEndpoint(ctx, &ListSchedulesRequest{
+		OfAccountId: ofAccountID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*ListSchedulesResponse)
+	var schedules []*task.Schedule
+	for _, s := range out.Schedules {
+		schedules = append(schedules, fromPBSchedule(s))
+	}
+	return schedules, nil
+}
+
+func (e *Set) GetSchedule(ctx context.Context, id uint64) (*task.Schedule, error) {
+	resp, err := e.GetScheduleEndpoint(ctx, &GetScheduleRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromPBSchedule(resp.(*ScheduleResponse).Schedule), nil
+}
+
+func (e *Set) UpdateSchedule(ctx context.Context, param *task.UpdateScheduleParam) (*task.Schedule, error) {
+	req := &UpdateScheduleRequest{
+		Id:      param.ID,
+		Cron:    param.Cron,
+		Enabled: param.Enabled,
+	}
+	if param.StartAt != nil {
+		req.StartAt = timestamppb.New(*param.StartAt)
+	}
+	resp, err := e.UpdateScheduleEndpoint(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return fromPBSchedule(resp.(*ScheduleResponse).Schedule), nil
+}
+
+func (e *Set) DeleteSchedule(ctx context.Context, id uint64) error {
+	_, err := e.DeleteScheduleEndpoint(ctx, &DeleteScheduleRequest{Id: id})
+	return err
+}
+
+func (e *Set) WatchTasks(
+	ctx context.Context,
+	ofAccountID, workspaceID, lastEventID uint64,
+) (<-chan *task.TaskEvent, error) {
+	resp, err := e.WatchTasksEndpoint(ctx, &WatchTasksRequest{
+		OfAccountId: ofAccountID,
+		WorkspaceId: workspaceID,
+		LastEventId: lastEventID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	in := resp.(*WatchTasksResponse).Events
+	out := make(chan *task.TaskEvent)
+	go func() {
+		defer close(out)
+		for ev := range in {
+			select {
+			case out <- fromPBTaskEvent((*pb.TaskEvent)(ev)):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (e *Set) CreateWebhook(ctx context.Context, param *task.CreateWebhookParam) (*task.Webhook, error) {
+	resp, err := e.CreateWebhookEndpoint(ctx, &CreateWebhookRequest{
+		OfAccountId: param.OfAccountID,
+		Url:         param.URL,
+		Events:      toPBWebhookEvents(param.Events),
+		Secret:      param.Secret,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromPBWebhook(resp.(*WebhookResponse).Webhook), nil
+}
+
+func (e *Set) ListWebhooks(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*task.Webhook, error) {
+	resp, err := e.ListWebhooksEndpoint(ctx, &ListWebhooksRequest{
+		OfAccountId: ofAccountID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var webhooks []*task.Webhook
+	for _, w := range resp.(*ListWebhooksResponse).Webhooks {
+		webhooks = append(webhooks, fromPBWebhook(w))
+	}
+	return webhooks, nil
+}
+
+func (e *Set) GetWebhook(ctx context.Context, id uint64) (*task.Webhook, error) {
+	resp, err := e.GetWebhookEndpoint(ctx, &GetWebhookRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return fromPBWebhook(resp.(*WebhookResponse).Webhook), nil
+}
+
+func (e *Set) UpdateWebhook(ctx context.Context, param *task.UpdateWebhookParam) (*task.Webhook, error) {
+	req := &UpdateWebhookRequest{
+		Id:           param.ID,
+		Url:          param.URL,
+		Enabled:      param.Enabled,
+		RotateSecret: param.RotateSecret,
+	}
+	if param.Events != nil {
+		req.Events = &pb.WebhookEvents{Events: toPBWebhookEvents(*param.Events)}
+	}
+	resp, err := e.UpdateWebhookEndpoint(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return fromPBWebhook(resp.(*WebhookResponse).Webhook), nil
+}
+
+func (e *Set) DeleteWebhook(ctx context.Context, id uint64) error {
+	_, err := e.DeleteWebhookEndpoint(ctx, &DeleteWebhookRequest{Id: id})
+	return err
+}
+
+func (e *Set) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uint64,
+	limit, offset int32,
+) ([]*task.WebhookDelivery, error) {
+	resp, err := e.ListWebhookDeliveriesEndpoint(ctx, &ListWebhookDeliveriesRequest{
+		WebhookId: webhookID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []*task.WebhookDelivery
+	for _, d := range resp.(*ListWebhookDeliveriesResponse).Deliveries {
+		deliveries = append(deliveries, fromPBWebhookDelivery(d))
+	}
+	return deliveries, nil
+}
+
+func (e *Set) CreateTasks(ctx context.Context, param *task.CreateTasksParam) ([]*task.Task, error) {
+	req := &CreateTasksRequest{
+		OfAccountId: param.OfAccountID,
+		WorkspaceId: param.WorkspaceID,
+		UrlList:     param.URLList,
+		Metalink:    param.Metalink,
+		Priority:    toPBPriority(param.Priority),
+	}
+	for _, p := range param.Tasks {
+		req.Tasks = append(req.Tasks, toPBCreateTaskRequest(p))
+	}
+	resp, err := e.CreateTasksEndpoint(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []*task.Task
+	for _, t := range resp.(*CreateTasksResponse).Tasks {
+		tasks = append(tasks, fromPBTask(t))
+	}
+	return tasks, nil
+}
+
+func (e *Set) BulkTasks(ctx context.Context, param *task.BulkTaskParam) (*task.BulkTaskOutput, error) {
+	resp, err := e.BulkTasksEndpoint(ctx, &BulkTasksRequest{
+		OfAccountId: param.OfAccountID,
+		WorkspaceId: param.WorkspaceID,
+		Action:      pb.BulkAction(pb.BulkAction_value[string(param.Action)]),
+		Ids:         param.IDs,
+		Filter:      toPBTaskFilter(param.Filter),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := resp.(*BulkTasksResponse)
+	res := &task.BulkTaskOutput{Succeeded: out.Succeeded}
+	for _, f := range out.Failed {
+		res.Failed = append(res.Failed, &task.BulkTaskFailure{TaskID: f.TaskId, Message: f.Message})
+	}
+	return res, nil
+}
+
+func (e *Set) RecordAuditEvent(ctx context.Context, ev *task.AuditEvent) error {
+	_, err := e.RecordAuditEventEndpoint(ctx, &RecordAuditEventRequest{Event: toPBAuditEvent(ev)})
+	return err
+}
+
+func (e *Set) ListAuditEvents(ctx context.Context, param *task.ListAuditEventsParam) ([]*task.AuditEvent, error) {
+	resp, err := e.ListAuditEventsEndpoint(ctx, &ListAuditEventsRequest{
+		AccountId:   param.Filter.AccountID,
+		WorkspaceId: param.Filter.WorkspaceID,
+		TaskId:      param.Filter.TaskID,
+		Actions:     toPBAuditActions(param.Filter.Actions),
+		Outcome:     string(param.Filter.Outcome),
+		From:        toPBTimestamp(param.Filter.From),
+		To:          toPBTimestamp(param.Filter.To),
+		BeforeId:    param.BeforeID,
+		Limit:       param.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var evs []*task.AuditEvent
+	for _, ev := range resp.(*ListAuditEventsResponse).Events {
+		evs = append(evs, fromPBAuditEvent(ev))
+	}
+	return evs, nil
+}
+
 // fromPBTask converts a protobuf Task to domain Task
 func fromPBTask(pbTask *pb.Task) *task.Task {
 	if pbTask == nil {
@@ -324,6 +656,9 @@ func fromPBTask(pbTask *pb.Task) *task.Task {
 		StorageType:  storage.TypeValue(pbTask.GetStorageType().String()),
 		StoragePath:  pbTask.GetStoragePath(),
 		Status:       task.TaskStatus(pbTask.GetStatus().String()),
+		Priority:     task.Priority(pbTask.GetPriority().String()),
+		ScheduleID:   pbTask.GetScheduleId(),
+		WorkspaceID:  pbTask.GetWorkspaceId(),
 		Checksum:     checksum,
 		Progress:     progress,
 		ErrorMessage: errMsg,
@@ -337,6 +672,8 @@ func fromPBTask(pbTask *pb.Task) *task.Task {
 			}
 			return nil
 		}(),
+		ExpirationDays: pbTask.GetExpirationDays(),
+		LastAccessedAt: fromPBTimestamp(pbTask.GetLastAccessedAt()),
 	}
 }
 
@@ -398,25 +735,7 @@ func fromPBChecksumInfo(pbChecksum *pb.ChecksumInfo) *task.ChecksumInfo {
 func MakeCreateTaskEndpoint(svc task.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
 		req := request.(*CreateTaskRequest)
-
-		params := &task.CreateTaskParam{
-			OfAccountID: req.OfAccountId,
-			FileName:    req.FileName,
-			SourceURL:   req.SourceUrl,
-			SourceType:  task.SourceType(req.SourceType.String()),
-			SourceAuth: &task.AuthConfig{
-				Username: req.SourceAuth.GetUsername(),
-				Password: req.SourceAuth.GetPassword(),
-				Token:    req.SourceAuth.GetToken(),
-				Headers:  req.SourceAuth.GetHeaders(),
-			},
-			Checksum: &task.ChecksumInfo{
-				ChecksumType:  req.Checksum.GetChecksumType(),
-				ChecksumValue: req.Checksum.GetChecksumValue(),
-			},
-			Metadata: req.Metadata.AsMap(),
-		}
-		created, err := svc.CreateTask(ctx, params)
+		created, err := svc.CreateTask(ctx, fromPBCreateTaskRequest((*pb.CreateTaskRequest)(req)))
 		if err != nil {
 			return nil, err
 		}
@@ -517,25 +836,23 @@ func MakeCompleteTaskEndpoint(svc task.Service) endpoint.Endpoint {
 func MakeListTasksEndpoint(svc task.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
 		req := request.(*ListTasksRequest)
+		filter := fromPBTaskFilter(req.Filter)
+		if filter == nil {
+			filter = &task.TaskFilter{}
+		}
 		param := &task.ListTasksParam{
-			Filter: &task.TaskFilter{
-				OfAccountID: func() uint64 {
-					if req.Filter != nil {
-						return req.Filter.GetOfAccountId()
-					}
-					return 0
-				}(),
-			},
+			Filter:  filter,
 			Offset:  req.Offset,
 			Limit:   req.Limit,
 			SortBy:  req.SortBy,
 			SortAsc: req.SortAsc,
+			Cursor:  req.Cursor,
 		}
 		out, err := svc.ListTasks(ctx, param)
 		if err != nil {
 			return nil, err
 		}
-		resp := &ListTasksResponse{Total: out.Total}
+		resp := &ListTasksResponse{Total: out.Total, NextCursor: out.NextCursor}
 		for _, t := range out.Tasks {
 			resp.Tasks = append(resp.Tasks, toPBTask(t))
 		}
@@ -598,6 +915,18 @@ func MakeRetryTaskEndpoint(svc task.Service) endpoint.Endpoint {
 	}
 }
 
+// MakeExtendTaskRetentionEndpoint endpoint for Service.ExtendTaskRetention
+func MakeExtendTaskRetentionEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ExtendTaskRetentionRequest)
+		t, err := svc.ExtendTaskRetention(ctx, &task.ExtendTaskRetentionParam{TaskID: req.TaskId, Days: req.Days})
+		if err != nil {
+			return nil, err
+		}
+		return &TaskResponse{Task: toPBTask(t)}, nil
+	}
+}
+
 // MakeCheckFileExistsEndpoint endpoint for Service.CheckFileExists
 func MakeCheckFileExistsEndpoint(svc task.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
@@ -635,6 +964,298 @@ func MakeGenerateDownloadURLEndpoint(svc task.Service) endpoint.Endpoint {
 	}
 }
 
+// MakeGetUsageEndpoint endpoint for Service.GetUsage
+func MakeGetUsageEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetUsageRequest)
+		usage, err := svc.GetUsage(ctx, req.OfAccountId)
+		if err != nil {
+			return nil, err
+		}
+		return &GetUsageResponse{
+			ActiveTasks:    usage.ActiveTasks,
+			StoredBytes:    usage.StoredBytes,
+			BytesToday:     usage.BytesToday,
+			MaxActiveTasks: usage.Quota.MaxActiveTasks,
+			MaxStoredBytes: usage.Quota.MaxStoredBytes,
+			MaxBytesPerDay: usage.Quota.MaxBytesPerDay,
+		}, nil
+	}
+}
+
+// MakeListSchedulesEndpoint endpoint for Service.ListSchedules
+func MakeListSchedulesEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListSchedulesRequest)
+		schedules, err := svc.ListSchedules(ctx, req.OfAccountId, req.Limit, req.Offset)
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListSchedulesResponse{}
+		for _, s := range schedules {
+			resp.Schedules = append(resp.Schedules, toPBSchedule(s))
+		}
+		return resp, nil
+	}
+}
+
+// MakeGetScheduleEndpoint endpoint for Service.GetSchedule
+func MakeGetScheduleEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetScheduleRequest)
+		s, err := svc.GetSchedule(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		return &ScheduleResponse{Schedule: toPBSchedule(s)}, nil
+	}
+}
+
+// MakeUpdateScheduleEndpoint endpoint for Service.UpdateSchedule
+func MakeUpdateScheduleEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*UpdateScheduleRequest)
+		param := &task.UpdateScheduleParam{
+			ID:      req.Id,
+			Cron:    req.Cron,
+			Enabled: req.Enabled,
+		}
+		if req.StartAt != nil {
+			startAt := req.StartAt.AsTime()
+			param.StartAt = &startAt
+		}
+		s, err := svc.UpdateSchedule(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		return &ScheduleResponse{Schedule: toPBSchedule(s)}, nil
+	}
+}
+
+// MakeDeleteScheduleEndpoint endpoint for Service.DeleteSchedule
+func MakeDeleteScheduleEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*DeleteScheduleRequest)
+		if err := svc.DeleteSchedule(ctx, req.Id); err != nil {
+			return nil, err
+		}
+		return &DeleteScheduleResponse{Message: "deleted"}, nil
+	}
+}
+
+// MakeWatchTasksEndpoint endpoint for Service.WatchTasks. The events are
+// streamed until ctx is done.
+func MakeWatchTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*WatchTasksRequest)
+		events, err := svc.WatchTasks(ctx, req.OfAccountId, req.WorkspaceId, req.LastEventId)
+		if err != nil {
+			return nil, err
+		}
+		out := make(chan *TaskEvent)
+		go func() {
+			defer close(out)
+			for ev := range events {
+				select {
+				case out <- (*TaskEvent)(toPBTaskEvent(ev)):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return &WatchTasksResponse{Events: out}, nil
+	}
+}
+
+// MakeCreateWebhookEndpoint endpoint for Service.CreateWebhook
+func MakeCreateWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateWebhookRequest)
+		w, err := svc.CreateWebhook(ctx, &task.CreateWebhookParam{
+			OfAccountID: req.OfAccountId,
+			URL:         req.Url,
+			Events:      fromPBWebhookEvents(req.Events),
+			Secret:      req.Secret,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookResponse{Webhook: toPBWebhook(w)}, nil
+	}
+}
+
+// MakeListWebhooksEndpoint endpoint for Service.ListWebhooks
+func MakeListWebhooksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListWebhooksRequest)
+		webhooks, err := svc.ListWebhooks(ctx, req.OfAccountId, req.Limit, req.Offset)
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListWebhooksResponse{}
+		for _, w := range webhooks {
+			resp.Webhooks = append(resp.Webhooks, toPBWebhook(w))
+		}
+		return resp, nil
+	}
+}
+
+// MakeGetWebhookEndpoint endpoint for Service.GetWebhook
+func MakeGetWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*GetWebhookRequest)
+		w, err := svc.GetWebhook(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookResponse{Webhook: toPBWebhook(w)}, nil
+	}
+}
+
+// MakeUpdateWebhookEndpoint endpoint for Service.UpdateWebhook
+func MakeUpdateWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*UpdateWebhookRequest)
+		param := &task.UpdateWebhookParam{
+			ID:           req.Id,
+			URL:          req.Url,
+			Enabled:      req.Enabled,
+			RotateSecret: req.RotateSecret,
+		}
+		if req.Events != nil {
+			evs := fromPBWebhookEvents(req.Events.GetEvents())
+			param.Events = &evs
+		}
+		w, err := svc.UpdateWebhook(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookResponse{Webhook: toPBWebhook(w)}, nil
+	}
+}
+
+// MakeDeleteWebhookEndpoint endpoint for Service.DeleteWebhook
+func MakeDeleteWebhookEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*DeleteWebhookRequest)
+		if err := svc.DeleteWebhook(ctx, req.Id); err != nil {
+			return nil, err
+		}
+		return &DeleteWebhookResponse{Message: "deleted"}, nil
+	}
+}
+
+// MakeListWebhookDeliveriesEndpoint endpoint for Service.ListWebhookDeliveries
+func MakeListWebhookDeliveriesEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListWebhookDeliveriesRequest)
+		deliveries, err := svc.ListWebhookDeliveries(ctx, req.WebhookId, req.Limit, req.Offset)
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListWebhookDeliveriesResponse{}
+		for _, d := range deliveries {
+			resp.Deliveries = append(resp.Deliveries, toPBWebhookDelivery(d))
+		}
+		return resp, nil
+	}
+}
+
+// MakeCreateTasksEndpoint endpoint for Service.CreateTasks
+func MakeCreateTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*CreateTasksRequest)
+		param := &task.CreateTasksParam{
+			OfAccountID: req.OfAccountId,
+			WorkspaceID: req.WorkspaceId,
+			URLList:     req.UrlList,
+			Metalink:    req.Metalink,
+			Priority:    task.Priority(req.Priority.String()),
+		}
+		for _, t := range req.Tasks {
+			p := fromPBCreateTaskRequest(t)
+			// Batch tasks are never scheduled.
+			p.Schedule = nil
+			param.Tasks = append(param.Tasks, p)
+		}
+		tasks, err := svc.CreateTasks(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+		resp := &CreateTasksResponse{}
+		for _, t := range tasks {
+			resp.Tasks = append(resp.Tasks, toPBTask(t))
+		}
+		return resp, nil
+	}
+}
+
+// MakeBulkTasksEndpoint endpoint for Service.BulkTasks
+func MakeBulkTasksEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*BulkTasksRequest)
+		out, err := svc.BulkTasks(ctx, &task.BulkTaskParam{
+			OfAccountID: req.OfAccountId,
+			WorkspaceID: req.WorkspaceId,
+			Action:      task.BulkAction(req.Action.String()),
+			IDs:         req.Ids,
+			Filter:      fromPBTaskFilter(req.Filter),
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp := &BulkTasksResponse{Succeeded: out.Succeeded}
+		for _, f := range out.Failed {
+			resp.Failed = append(resp.Failed, &pb.BulkTaskFailure{TaskId: f.TaskID, Message: f.Message})
+		}
+		return resp, nil
+	}
+}
+
+// MakeRecordAuditEventEndpoint endpoint for Service.RecordAuditEvent
+func MakeRecordAuditEventEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*RecordAuditEventRequest)
+		// An empty event fails validation in the service.
+		ev := fromPBAuditEvent(req.Event)
+		if ev == nil {
+			ev = &task.AuditEvent{}
+		}
+		if err := svc.RecordAuditEvent(ctx, ev); err != nil {
+			return nil, err
+		}
+		return &RecordAuditEventResponse{}, nil
+	}
+}
+
+// MakeListAuditEventsEndpoint endpoint for Service.ListAuditEvents
+func MakeListAuditEventsEndpoint(svc task.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*ListAuditEventsRequest)
+		evs, err := svc.ListAuditEvents(ctx, &task.ListAuditEventsParam{
+			Filter: task.AuditFilter{
+				AccountID:   req.AccountId,
+				WorkspaceID: req.WorkspaceId,
+				TaskID:      req.TaskId,
+				Actions:     fromPBAuditActions(req.Actions),
+				Outcome:     task.AuditOutcome(req.Outcome),
+				From:        fromPBTimestamp(req.From),
+				To:          fromPBTimestamp(req.To),
+			},
+			BeforeID: req.BeforeId,
+			Limit:    req.Limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp := &ListAuditEventsResponse{}
+		for _, ev := range evs {
+			resp.Events = append(resp.Events, toPBAuditEvent(ev))
+		}
+		return resp, nil
+	}
+}
+
 // MakeUpdateTaskChecksumEndpoint updates task checksum
 func MakeUpdateTaskChecksumEndpoint(svc task.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
@@ -662,6 +1283,7 @@ func New(svc task.Service) Set {
 		resumeEndpoint            endpoint.Endpoint
 		cancelEndpoint            endpoint.Endpoint
 		retryEndpoint             endpoint.Endpoint
+		extendRetentionEndpoint   endpoint.Endpoint
 		updateStoragePathEndpoint endpoint.Endpoint
 		updateStatusEndpoint      endpoint.Endpoint
 		updateProgressEndpoint    endpoint.Endpoint
@@ -669,6 +1291,22 @@ func New(svc task.Service) Set {
 		completeTaskEndpoint      endpoint.Endpoint
 		checkFileExistsEndpoint   endpoint.Endpoint
 		getTaskProgressEndpoint   endpoint.Endpoint
+		getUsageEndpoint          endpoint.Endpoint
+		listSchedulesEndpoint     endpoint.Endpoint
+		getScheduleEndpoint       endpoint.Endpoint
+		updateScheduleEndpoint    endpoint.Endpoint
+		deleteScheduleEndpoint    endpoint.Endpoint
+		watchTasksEndpoint        endpoint.Endpoint
+		createWebhookEndpoint     endpoint.Endpoint
+		listWebhooksEndpoint      endpoint.Endpoint
+		getWebhookEndpoint        endpoint.Endpoint
+		updateWebhookEndpoint     endpoint.Endpoint
+		deleteWebhookEndpoint     endpoint.Endpoint
+		listDeliveriesEndpoint    endpoint.Endpoint
+		createTasksEndpoint       endpoint.Endpoint
+		bulkTasksEndpoint         endpoint.Endpoint
+		recordAuditEventEndpoint  endpoint.Endpoint
+		listAuditEventsEndpoint   endpoint.Endpoint
 		updateChecksumEndpoint    endpoint.Endpoint
 		updateMetadataEndpoint    endpoint.Endpoint
 	)
@@ -701,12 +1339,46 @@ func New(svc task.Service) Set {
 	cancelEndpoint = limiter(cancelEndpoint)
 	retryEndpoint = MakeRetryTaskEndpoint(svc)
 	retryEndpoint = limiter(retryEndpoint)
+	extendRetentionEndpoint = MakeExtendTaskRetentionEndpoint(svc)
+	extendRetentionEndpoint = limiter(extendRetentionEndpoint)
 	checkFileExistsEndpoint = MakeCheckFileExistsEndpoint(svc)
 	checkFileExistsEndpoint = limiter(checkFileExistsEndpoint)
 	getTaskProgressEndpoint = MakeGetTaskProgressEndpoint(svc)
 	getTaskProgressEndpoint = limiter(getTaskProgressEndpoint)
 	generateDownloadURLEndpoint := MakeGenerateDownloadURLEndpoint(svc)
 	generateDownloadURLEndpoint = limiter(generateDownloadURLEndpoint)
+	getUsageEndpoint = MakeGetUsageEndpoint(svc)
+	getUsageEndpoint = limiter(getUsageEndpoint)
+	listSchedulesEndpoint = MakeListSchedulesEndpoint(svc)
+	listSchedulesEndpoint = limiter(listSchedulesEndpoint)
+	getScheduleEndpoint = MakeGetScheduleEndpoint(svc)
+	getScheduleEndpoint = limiter(getScheduleEndpoint)
+	updateScheduleEndpoint = MakeUpdateScheduleEndpoint(svc)
+	updateScheduleEndpoint = limiter(updateScheduleEndpoint)
+	deleteScheduleEndpoint = MakeDeleteScheduleEndpoint(svc)
+	deleteScheduleEndpoint = limiter(deleteScheduleEndpoint)
+	watchTasksEndpoint = MakeWatchTasksEndpoint(svc)
+	watchTasksEndpoint = limiter(watchTasksEndpoint)
+	createWebhookEndpoint = MakeCreateWebhookEndpoint(svc)
+	createWebhookEndpoint = limiter(createWebhookEndpoint)
+	listWebhooksEndpoint = MakeListWebhooksEndpoint(svc)
+	listWebhooksEndpoint = limiter(listWebhooksEndpoint)
+	getWebhookEndpoint = MakeGetWebhookEndpoint(svc)
+	getWebhookEndpoint = limiter(getWebhookEndpoint)
+	updateWebhookEndpoint = MakeUpdateWebhookEndpoint(svc)
+	updateWebhookEndpoint = limiter(updateWebhookEndpoint)
+	deleteWebhookEndpoint = MakeDeleteWebhookEndpoint(svc)
+	deleteWebhookEndpoint = limiter(deleteWebhookEndpoint)
+	listDeliveriesEndpoint = MakeListWebhookDeliveriesEndpoint(svc)
+	listDeliveriesEndpoint = limiter(listDeliveriesEndpoint)
+	createTasksEndpoint = MakeCreateTasksEndpoint(svc)
+	createTasksEndpoint = limiter(createTasksEndpoint)
+	bulkTasksEndpoint = MakeBulkTasksEndpoint(svc)
+	bulkTasksEndpoint = limiter(bulkTasksEndpoint)
+	recordAuditEventEndpoint = MakeRecordAuditEventEndpoint(svc)
+	recordAuditEventEndpoint = limiter(recordAuditEventEndpoint)
+	listAuditEventsEndpoint = MakeListAuditEventsEndpoint(svc)
+	listAuditEventsEndpoint = limiter(listAuditEventsEndpoint)
 	updateChecksumEndpoint = MakeUpdateTaskChecksumEndpoint(svc)
 	updateChecksumEndpoint = limiter(updateChecksumEndpoint)
 	updateMetadataEndpoint = MakeUpdateTaskMetadataEndpoint(svc)
@@ -721,6 +1393,7 @@ func New(svc task.Service) Set {
 		ResumeTaskEndpoint:            resumeEndpoint,
 		CancelTaskEndpoint:            cancelEndpoint,
 		RetryTaskEndpoint:             retryEndpoint,
+		ExtendTaskRetentionEndpoint:   extendRetentionEndpoint,
 		UpdateTaskStoragePathEndpoint: updateStoragePathEndpoint,
 		UpdateTaskStatusEndpoint:      updateStatusEndpoint,
 		UpdateTaskProgressEndpoint:    updateProgressEndpoint,
@@ -729,6 +1402,22 @@ func New(svc task.Service) Set {
 		CheckFileExistsEndpoint:       checkFileExistsEndpoint,
 		GetTaskProgressEndpoint:       getTaskProgressEndpoint,
 		GenerateDownloadURLEndpoint:   generateDownloadURLEndpoint,
+		GetUsageEndpoint:              getUsageEndpoint,
+		ListSchedulesEndpoint:         listSchedulesEndpoint,
+		GetScheduleEndpoint:           getScheduleEndpoint,
+		UpdateScheduleEndpoint:        updateScheduleEndpoint,
+		DeleteScheduleEndpoint:        deleteScheduleEndpoint,
+		WatchTasksEndpoint:            watchTasksEndpoint,
+		CreateWebhookEndpoint:         createWebhookEndpoint,
+		ListWebhooksEndpoint:          listWebhooksEndpoint,
+		GetWebhookEndpoint:            getWebhookEndpoint,
+		UpdateWebhookEndpoint:         updateWebhookEndpoint,
+		DeleteWebhookEndpoint:         deleteWebhookEndpoint,
+		ListWebhookDeliveriesEndpoint: listDeliveriesEndpoint,
+		CreateTasksEndpoint:           createTasksEndpoint,
+		BulkTasksEndpoint:             bulkTasksEndpoint,
+		RecordAuditEventEndpoint:      recordAuditEventEndpoint,
+		ListAuditEventsEndpoint:       listAuditEventsEndpoint,
 		UpdateTaskChecksumEndpoint:    updateChecksumEndpoint,
 		UpdateTaskMetadataEndpoint:    updateMetadataEndpoint,
 	}
@@ -748,6 +1437,9 @@ func toPBTask(t *task.Task) *pb.Task {
 		StorageType: pb.StorageType(pb.StorageType_value[string(t.StorageType)]),
 		StoragePath: t.StoragePath,
 		Status:      pb.TaskStatus(pb.TaskStatus_value[string(t.Status)]),
+		Priority:    toPBPriority(t.Priority),
+		ScheduleId:  t.ScheduleID,
+		WorkspaceId: t.WorkspaceID,
 		Metadata:    toPBStruct(t.Metadata),
 		OfAccountId: t.OfAccountID,
 		Progress:    toPBProgress(t.Progress),
@@ -759,6 +1451,9 @@ func toPBTask(t *task.Task) *pb.Task {
 			}
 			return nil
 		}(),
+		ExpirationDays: t.ExpirationDays,
+		LastAccessedAt: toPBTimestamp(t.LastAccessedAt),
+		ExpiresAt:      toPBTimestamp(t.ExpiresAt()),
 	}
 	if t.Checksum != nil {
 		pbTask.Checksum = &pb.ChecksumInfo{
@@ -772,6 +1467,286 @@ func toPBTask(t *task.Task) *pb.Task {
 	return pbTask
 }
 
+func toPBScheduleSpec(spec *task.ScheduleSpec) *pb.ScheduleSpec {
+	if spec == nil {
+		return nil
+	}
+	return &pb.ScheduleSpec{Cron: spec.Cron, StartAt: toPBTimestamp(spec.StartAt)}
+}
+
+func fromPBScheduleSpec(spec *pb.ScheduleSpec) *task.ScheduleSpec {
+	if spec == nil || (spec.GetCron() == "" && spec.GetStartAt() == nil) {
+		return nil
+	}
+	return &task.ScheduleSpec{Cron: spec.GetCron(), StartAt: fromPBTimestamp(spec.GetStartAt())}
+}
+
+func toPBSchedule(s *task.Schedule) *pb.Schedule {
+	if s == nil {
+		return nil
+	}
+	pbSchedule := &pb.Schedule{
+		Id:          s.ID,
+		OfAccountId: s.OfAccountID,
+		Cron:        s.Cron,
+		StartAt:     toPBTimestamp(s.StartAt),
+		Enabled:     s.Enabled,
+		NextRunAt:   toPBTimestamp(s.NextRunAt),
+		LastRunAt:   toPBTimestamp(s.LastRunAt),
+		NextTaskId:  s.NextTaskID,
+		CreatedAt:   timestamppb.New(s.CreatedAt),
+		UpdatedAt:   timestamppb.New(s.UpdatedAt),
+	}
+	if s.Template != nil {
+		pbSchedule.FileName = s.Template.FileName
+		pbSchedule.SourceUrl = s.Template.SourceURL
+		pbSchedule.SourceType = pb.SourceType(pb.SourceType_value[string(s.Template.SourceType)])
+		pbSchedule.Priority = toPBPriority(s.Template.Priority)
+	}
+	return pbSchedule
+}
+
+func fromPBSchedule(s *pb.Schedule) *task.Schedule {
+	if s == nil {
+		return nil
+	}
+	return &task.Schedule{
+		ID:          s.GetId(),
+		OfAccountID: s.GetOfAccountId(),
+		Cron:        s.GetCron(),
+		StartAt:     fromPBTimestamp(s.GetStartAt()),
+		Enabled:     s.GetEnabled(),
+		NextRunAt:   fromPBTimestamp(s.GetNextRunAt()),
+		LastRunAt:   fromPBTimestamp(s.GetLastRunAt()),
+		NextTaskID:  s.GetNextTaskId(),
+		Template: &task.CreateTaskParam{
+			OfAccountID: s.GetOfAccountId(),
+			FileName:    s.GetFileName(),
+			SourceURL:   s.GetSourceUrl(),
+			SourceType:  task.SourceType(s.GetSourceType().String()),
+			Priority:    task.Priority(s.GetPriority().String()),
+		},
+		CreatedAt: s.GetCreatedAt().AsTime(),
+		UpdatedAt: s.GetUpdatedAt().AsTime(),
+	}
+}
+
+func toPBWebhookEvents(evs []events.EventType) []string {
+	var out []string
+	for _, ev := range evs {
+		out = append(out, string(ev))
+	}
+	return out
+}
+
+func fromPBWebhookEvents(evs []string) []events.EventType {
+	var out []events.EventType
+	for _, ev := range evs {
+		out = append(out, events.EventType(ev))
+	}
+	return out
+}
+
+func toPBWebhook(w *task.Webhook) *pb.Webhook {
+	if w == nil {
+		return nil
+	}
+	return &pb.Webhook{
+		Id:          w.ID,
+		OfAccountId: w.OfAccountID,
+		Url:         w.URL,
+		Secret:      w.Secret,
+		Events:      toPBWebhookEvents(w.Events),
+		Enabled:     w.Enabled,
+		CreatedAt:   timestamppb.New(w.CreatedAt),
+		UpdatedAt:   timestamppb.New(w.UpdatedAt),
+	}
+}
+
+func fromPBWebhook(w *pb.Webhook) *task.Webhook {
+	if w == nil {
+		return nil
+	}
+	return &task.Webhook{
+		ID:          w.GetId(),
+		OfAccountID: w.GetOfAccountId(),
+		URL:         w.GetUrl(),
+		Secret:      w.GetSecret(),
+		Events:      fromPBWebhookEvents(w.GetEvents()),
+		Enabled:     w.GetEnabled(),
+		CreatedAt:   w.GetCreatedAt().AsTime(),
+		UpdatedAt:   w.GetUpdatedAt().AsTime(),
+	}
+}
+
+func toPBWebhookDelivery(d *task.WebhookDelivery) *pb.WebhookDelivery {
+	if d == nil {
+		return nil
+	}
+	return &pb.WebhookDelivery{
+		Id:            d.ID,
+		WebhookId:     d.WebhookID,
+		EventId:       d.EventID,
+		Event:         string(d.Event),
+		TaskId:        d.TaskID,
+		Payload:       string(d.Payload),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: toPBTimestamp(d.NextAttemptAt),
+		ResponseCode:  d.ResponseCode,
+		LastError:     d.LastError,
+		DeliveredAt:   toPBTimestamp(d.DeliveredAt),
+		CreatedAt:     timestamppb.New(d.CreatedAt),
+		UpdatedAt:     timestamppb.New(d.UpdatedAt),
+	}
+}
+
+func fromPBWebhookDelivery(d *pb.WebhookDelivery) *task.WebhookDelivery {
+	if d == nil {
+		return nil
+	}
+	return &task.WebhookDelivery{
+		ID:            d.GetId(),
+		WebhookID:     d.GetWebhookId(),
+		EventID:       d.GetEventId(),
+		Event:         events.EventType(d.GetEvent()),
+		TaskID:        d.GetTaskId(),
+		Payload:       []byte(d.GetPayload()),
+		Status:        task.WebhookDeliveryStatus(d.GetStatus()),
+		Attempts:      d.GetAttempts(),
+		NextAttemptAt: fromPBTimestamp(d.GetNextAttemptAt()),
+		ResponseCode:  d.GetResponseCode(),
+		LastError:     d.LastError,
+		DeliveredAt:   fromPBTimestamp(d.GetDeliveredAt()),
+		CreatedAt:     d.GetCreatedAt().AsTime(),
+		UpdatedAt:     d.GetUpdatedAt().AsTime(),
+	}
+}
+
+func toPBAuditEvent(ev *task.AuditEvent) *pb.AuditEvent {
+	if ev == nil {
+		return nil
+	}
+	out := &pb.AuditEvent{
+		Id:             ev.ID,
+		ActorAccountId: ev.ActorAccountID,
+		ActorApiKeyId:  ev.ActorAPIKeyID,
+		Action:         string(ev.Action),
+		TaskId:         ev.TaskID,
+		OfAccountId:    ev.OfAccountID,
+		WorkspaceId:    ev.WorkspaceID,
+		SourceIp:       ev.SourceIP,
+		Outcome:        string(ev.Outcome),
+		Message:        ev.Message,
+	}
+	if !ev.Time.IsZero() {
+		out.Time = timestamppb.New(ev.Time)
+	}
+	return out
+}
+
+func fromPBAuditEvent(ev *pb.AuditEvent) *task.AuditEvent {
+	if ev == nil {
+		return nil
+	}
+	out := &task.AuditEvent{
+		ID:             ev.GetId(),
+		ActorAccountID: ev.GetActorAccountId(),
+		ActorAPIKeyID:  ev.GetActorApiKeyId(),
+		Action:         task.AuditAction(ev.GetAction()),
+		TaskID:         ev.GetTaskId(),
+		OfAccountID:    ev.GetOfAccountId(),
+		WorkspaceID:    ev.GetWorkspaceId(),
+		SourceIP:       ev.GetSourceIp(),
+		Outcome:        task.AuditOutcome(ev.GetOutcome()),
+		Message:        ev.GetMessage(),
+	}
+	if ev.GetTime() != nil {
+		out.Time = ev.GetTime().AsTime()
+	}
+	return out
+}
+
+func toPBAuditActions(actions []task.AuditAction) []string {
+	var out []string
+	for _, a := range actions {
+		out = append(out, string(a))
+	}
+	return out
+}
+
+func fromPBAuditActions(actions []string) []task.AuditAction {
+	var out []task.AuditAction
+	for _, a := range actions {
+		out = append(out, task.AuditAction(a))
+	}
+	return out
+}
+
+var taskEventTypes = map[task.TaskEventType]pb.TaskEventType{
+	task.TaskEventStatus:   pb.TaskEventType_STATUS_CHANGED,
+	task.TaskEventProgress: pb.TaskEventType_PROGRESS_UPDATED,
+	task.TaskEventDeleted:  pb.TaskEventType_TASK_DELETED,
+	task.TaskEventReset:    pb.TaskEventType_RESET,
+}
+
+func toPBTaskEvent(ev *task.TaskEvent) *pb.TaskEvent {
+	if ev == nil {
+		return nil
+	}
+	out := &pb.TaskEvent{
+		Id:           ev.ID,
+		Type:         taskEventTypes[ev.Type],
+		TaskId:       ev.TaskID,
+		OfAccountId:  ev.OfAccountID,
+		Progress:     toPBProgress(ev.Progress),
+		ErrorMessage: ev.ErrorMessage,
+		Time:         timestamppb.New(ev.Time),
+	}
+	if ev.Status != "" {
+		out.Status = pb.TaskStatus(pb.TaskStatus_value[string(ev.Status)])
+	}
+	return out
+}
+
+func fromPBTaskEvent(ev *pb.TaskEvent) *task.TaskEvent {
+	if ev == nil {
+		return nil
+	}
+	out := &task.TaskEvent{
+		ID:           ev.GetId(),
+		TaskID:       ev.GetTaskId(),
+		OfAccountID:  ev.GetOfAccountId(),
+		Progress:     fromPBDownloadProgress(ev.GetProgress()),
+		ErrorMessage: ev.ErrorMessage,
+		Time:         ev.GetTime().AsTime(),
+	}
+	for t, pbType := range taskEventTypes {
+		if pbType == ev.GetType() {
+			out.Type = t
+		}
+	}
+	if out.Type == task.TaskEventStatus {
+		out.Status = task.TaskStatus(ev.GetStatus().String())
+	}
+	return out
+}
+
+func toPBTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromPBTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
 func toPBAuthConfig(auth *task.AuthConfig) *pb.AuthConfig {
 	if auth == nil {
 		return nil
@@ -784,6 +1759,116 @@ func toPBAuthConfig(auth *task.AuthConfig) *pb.AuthConfig {
 	}
 }
 
+func toPBCreateTaskRequest(param *task.CreateTaskParam) *pb.CreateTaskRequest {
+	req := &pb.CreateTaskRequest{
+		OfAccountId:    param.OfAccountID,
+		WorkspaceId:    param.WorkspaceID,
+		FileName:       param.FileName,
+		SourceUrl:      param.SourceURL,
+		SourceType:     pb.SourceType(pb.SourceType_value[string(param.SourceType)]),
+		SourceAuth:     toPBAuthConfig(param.SourceAuth),
+		Priority:       toPBPriority(param.Priority),
+		Metadata:       toPBStruct(param.Metadata),
+		Schedule:       toPBScheduleSpec(param.Schedule),
+		ExpirationDays: param.ExpirationDays,
+		ReuseExisting:  param.ReuseExisting,
+	}
+	if param.Checksum != nil {
+		req.Checksum = &pb.ChecksumInfo{
+			ChecksumType:  param.Checksum.ChecksumType,
+			ChecksumValue: param.Checksum.ChecksumValue,
+		}
+	}
+	return req
+}
+
+func fromPBCreateTaskRequest(req *pb.CreateTaskRequest) *task.CreateTaskParam {
+	return &task.CreateTaskParam{
+		OfAccountID: req.OfAccountId,
+		WorkspaceID: req.WorkspaceId,
+		FileName:    req.FileName,
+		SourceURL:   req.SourceUrl,
+		SourceType:  task.SourceType(req.SourceType.String()),
+		SourceAuth: &task.AuthConfig{
+			Username: req.SourceAuth.GetUsername(),
+			Password: req.SourceAuth.GetPassword(),
+			Token:    req.SourceAuth.GetToken(),
+			Headers:  req.SourceAuth.GetHeaders(),
+		},
+		Checksum: &task.ChecksumInfo{
+			ChecksumType:  req.Checksum.GetChecksumType(),
+			ChecksumValue: req.Checksum.GetChecksumValue(),
+		},
+		Priority:       task.Priority(req.Priority.String()),
+		Metadata:       req.Metadata.AsMap(),
+		Schedule:       fromPBScheduleSpec(req.Schedule),
+		ExpirationDays: req.ExpirationDays,
+		ReuseExisting:  req.ReuseExisting,
+	}
+}
+
+func toPBTaskFilter(filter *task.TaskFilter) *pb.TaskFilter {
+	if filter == nil {
+		return nil
+	}
+	out := &pb.TaskFilter{
+		OfAccountId: filter.OfAccountID,
+		WorkspaceId: filter.WorkspaceID,
+		Tags:        filter.Tags,
+		Search:      filter.Search,
+	}
+	for _, st := range filter.Status {
+		out.Status = append(out.Status, pb.TaskStatus(pb.TaskStatus_value[string(st)]))
+	}
+	for _, st := range filter.SourceType {
+		out.SourceType = append(out.SourceType, pb.SourceType(pb.SourceType_value[string(st)]))
+	}
+	if filter.CreatedAt != nil {
+		out.CreatedAt = &pb.TimeRange{}
+		if filter.CreatedAt.From != nil {
+			out.CreatedAt.From = timestamppb.New(*filter.CreatedAt.From)
+		}
+		if filter.CreatedAt.To != nil {
+			out.CreatedAt.To = timestamppb.New(*filter.CreatedAt.To)
+		}
+	}
+	return out
+}
+
+func fromPBTaskFilter(filter *pb.TaskFilter) *task.TaskFilter {
+	if filter == nil {
+		return nil
+	}
+	out := &task.TaskFilter{
+		OfAccountID: filter.OfAccountId,
+		WorkspaceID: filter.WorkspaceId,
+		Tags:        filter.Tags,
+		Search:      filter.Search,
+	}
+	for _, st := range filter.Status {
+		out.Status = append(out.Status, task.TaskStatus(st.String()))
+	}
+	for _, st := range filter.SourceType {
+		out.SourceType = append(out.SourceType, task.SourceType(st.String()))
+	}
+	if filter.CreatedAt != nil {
+		out.CreatedAt = &task.TimeRange{}
+		if filter.CreatedAt.From != nil {
+			from := filter.CreatedAt.From.AsTime()
+			out.CreatedAt.From = &from
+		}
+		if filter.CreatedAt.To != nil {
+			to := filter.CreatedAt.To.AsTime()
+			out.CreatedAt.To = &to
+		}
+	}
+	return out
+}
+
+func toPBPriority(priority task.Priority) pb.TaskPriority {
+	return pb.TaskPriority(pb.TaskPriority_value[strings.ToUpper(string(priority))])
+}
+
 func toPBDownloadOptions(options *task.DownloadOptions) *pb.DownloadOptions {
 	if options == nil {
 		return nil
@@ -11,6 +11,7 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 
 	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/internal/task"
 	taskendpoint "github.com/yuisofull/goload/internal/task/endpoint"
@@ -30,6 +31,7 @@ type mockTaskService struct {
 	resumeTaskFn          func(ctx context.Context, id uint64) error
 	cancelTaskFn          func(ctx context.Context, id uint64) error
 	retryTaskFn           func(ctx context.Context, id uint64) error
+	extendRetentionFn     func(ctx context.Context, param *task.ExtendTaskRetentionParam) (*task.Task, error)
 	updateStoragePathFn   func(ctx context.Context, id uint64, path string) error
 	updateStatusFn        func(ctx context.Context, id uint64, status task.TaskStatus) error
 	updateProgressFn      func(ctx context.Context, id uint64, progress task.DownloadProgress) error
@@ -42,6 +44,14 @@ type mockTaskService struct {
 	updateFileNameFn      func(ctx context.Context, id uint64, fileName string) error
 	updateStorageInfoFn   func(ctx context.Context, id uint64, stype storage.Type, path string) error
 	generateDownloadURLFn func(ctx context.Context, taskID uint64, ttl time.Duration, oneTime bool) (string, bool, error)
+	getUsageFn            func(ctx context.Context, ofAccountID uint64) (*task.Usage, error)
+	updateScheduleFn      func(ctx context.Context, param *task.UpdateScheduleParam) (*task.Schedule, error)
+	watchTasksFn          func(ctx context.Context, ofAccountID, lastEventID uint64) (<-chan *task.TaskEvent, error)
+	updateWebhookFn       func(ctx context.Context, param *task.UpdateWebhookParam) (*task.Webhook, error)
+	createTasksFn         func(ctx context.Context, param *task.CreateTasksParam) ([]*task.Task, error)
+	bulkTasksFn           func(ctx context.Context, param *task.BulkTaskParam) (*task.BulkTaskOutput, error)
+	recordAuditEventFn    func(ctx context.Context, e *task.AuditEvent) error
+	listAuditEventsFn     func(ctx context.Context, param *task.ListAuditEventsParam) ([]*task.AuditEvent, error)
 }
 
 func (m *mockTaskService) CreateTask(ctx context.Context, param *task.CreateTaskParam) (*task.Task, error) {
@@ -76,6 +86,10 @@ func (m *mockTaskService) RetryTask(ctx context.Context, id uint64) error {
 	return m.retryTaskFn(ctx, id)
 }
 
+func (m *mockTaskService) ExtendTaskRetention(ctx context.Context, param *task.ExtendTaskRetentionParam) (*task.Task, error) {
+	return m.extendRetentionFn(ctx, param)
+}
+
 func (m *mockTaskService) UpdateTaskStoragePath(ctx context.Context, id uint64, path string) error {
 	return m.updateStoragePathFn(ctx, id, path)
 }
@@ -126,6 +140,14 @@ func (m *mockTaskService) UpdateStorageInfo(ctx context.Context, id uint64, styp
 	return nil
 }
 
+func (m *mockTaskService) UpdateSourceValidators(ctx context.Context, id uint64, etag, lastModified string) error {
+	return nil
+}
+
+func (m *mockTaskService) UpdateTaskWorker(ctx context.Context, id uint64, workerID string) error {
+	return nil
+}
+
 func (m *mockTaskService) GenerateDownloadURL(
 	ctx context.Context,
 	taskID uint64,
@@ -138,6 +160,104 @@ func (m *mockTaskService) GenerateDownloadURL(
 	return "", false, errors.New("not implemented")
 }
 
+func (m *mockTaskService) GetUsage(ctx context.Context, ofAccountID uint64) (*task.Usage, error) {
+	if m.getUsageFn != nil {
+		return m.getUsageFn(ctx, ofAccountID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) ListSchedules(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*task.Schedule, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) GetSchedule(ctx context.Context, id uint64) (*task.Schedule, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) UpdateSchedule(ctx context.Context, param *task.UpdateScheduleParam) (*task.Schedule, error) {
+	if m.updateScheduleFn != nil {
+		return m.updateScheduleFn(ctx, param)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) DeleteSchedule(ctx context.Context, id uint64) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockTaskService) WatchTasks(
+	ctx context.Context,
+	ofAccountID, workspaceID, lastEventID uint64,
+) (<-chan *task.TaskEvent, error) {
+	if m.watchTasksFn != nil {
+		return m.watchTasksFn(ctx, ofAccountID, lastEventID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) CreateWebhook(ctx context.Context, param *task.CreateWebhookParam) (*task.Webhook, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) ListWebhooks(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*task.Webhook, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) GetWebhook(ctx context.Context, id uint64) (*task.Webhook, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) UpdateWebhook(ctx context.Context, param *task.UpdateWebhookParam) (*task.Webhook, error) {
+	if m.updateWebhookFn != nil {
+		return m.updateWebhookFn(ctx, param)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) DeleteWebhook(ctx context.Context, id uint64) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockTaskService) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uint64,
+	limit, offset int32,
+) ([]*task.WebhookDelivery, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) CreateTasks(ctx context.Context, param *task.CreateTasksParam) ([]*task.Task, error) {
+	if m.createTasksFn != nil {
+		return m.createTasksFn(ctx, param)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) BulkTasks(ctx context.Context, param *task.BulkTaskParam) (*task.BulkTaskOutput, error) {
+	if m.bulkTasksFn != nil {
+		return m.bulkTasksFn(ctx, param)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockTaskService) RecordAuditEvent(ctx context.Context, e *task.AuditEvent) error {
+	if m.recordAuditEventFn != nil {
+		return m.recordAuditEventFn(ctx, e)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockTaskService) ListAuditEvents(
+	ctx context.Context,
+	param *task.ListAuditEventsParam,
+) ([]*task.AuditEvent, error) {
+	if m.listAuditEventsFn != nil {
+		return m.listAuditEventsFn(ctx, param)
+	}
+	return nil, errors.New("not implemented")
+}
+
 // ---------------------------------------------------------------------------
 // helpers
 // ---------------------------------------------------------------------------
@@ -761,6 +881,29 @@ func TestSet_CreateTask_RoundTrip(t *testing.T) {
 	assert.Equal(t, uint64(100), out.ID)
 }
 
+func TestSet_CreateTask_PriorityRoundTrip(t *testing.T) {
+	var got task.Priority
+	svc := svcWithGet(&mockTaskService{
+		createTaskFn: func(_ context.Context, param *task.CreateTaskParam) (*task.Task, error) {
+			got = param.Priority
+			created := stubTask(101)
+			created.Priority = param.Priority
+			return created, nil
+		},
+	})
+
+	set := taskendpoint.New(svc)
+	out, err := set.CreateTask(context.Background(), &task.CreateTaskParam{
+		OfAccountID: 1,
+		SourceURL:   "https://example.com/file.zip",
+		Priority:    task.PriorityHigh,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, task.PriorityHigh, got)
+	assert.Equal(t, task.PriorityHigh, out.Priority)
+}
+
 func TestSet_GetTask_RoundTrip(t *testing.T) {
 	svc := &mockTaskService{
 		getTaskFn: func(_ context.Context, id uint64) (*task.Task, error) {
@@ -796,6 +939,46 @@ func TestSet_ListTasks_RoundTrip(t *testing.T) {
 	assert.Len(t, out.Tasks, 3)
 }
 
+func TestSet_ListTasks_RoundTripsFilterAndCursor(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := &mockTaskService{
+		listTasksFn: func(_ context.Context, param *task.ListTasksParam) (*task.ListTasksOutput, error) {
+			assert.Equal(t, &task.TaskFilter{
+				OfAccountID: 1,
+				Status:      []task.TaskStatus{task.StatusCompleted, task.StatusFailed},
+				Tags:        []string{"nightly"},
+				SourceType:  []task.SourceType{task.SourceFTP},
+				CreatedAt:   &task.TimeRange{From: &from},
+				Search:      "iso",
+			}, param.Filter)
+			assert.Equal(t, "size", param.SortBy)
+			assert.True(t, param.SortAsc)
+			assert.Equal(t, "abc", param.Cursor)
+			return &task.ListTasksOutput{Tasks: []*task.Task{stubTask(1)}, Total: 9, NextCursor: "def"}, nil
+		},
+	}
+
+	set := taskendpoint.New(svc)
+	out, err := set.ListTasks(context.Background(), &task.ListTasksParam{
+		Filter: &task.TaskFilter{
+			OfAccountID: 1,
+			Status:      []task.TaskStatus{task.StatusCompleted, task.StatusFailed},
+			Tags:        []string{"nightly"},
+			SourceType:  []task.SourceType{task.SourceFTP},
+			CreatedAt:   &task.TimeRange{From: &from},
+			Search:      "iso",
+		},
+		Limit:   1,
+		SortBy:  "size",
+		SortAsc: true,
+		Cursor:  "abc",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(9), out.Total)
+	assert.Equal(t, "def", out.NextCursor)
+}
+
 func TestSet_DeleteTask_RoundTrip(t *testing.T) {
 	deleted := false
 	svc := &mockTaskService{
@@ -920,3 +1103,270 @@ func TestSet_GetTaskProgress_RoundTrip(t *testing.T) {
 	require.NotNil(t, progress)
 	assert.Equal(t, int64(4096), progress.TotalBytes)
 }
+
+func TestSet_GetUsage_RoundTrip(t *testing.T) {
+	svc := &mockTaskService{
+		getUsageFn: func(_ context.Context, ofAccountID uint64) (*task.Usage, error) {
+			assert.Equal(t, uint64(7), ofAccountID)
+			return &task.Usage{
+				ActiveTasks: 2,
+				StoredBytes: 2048,
+				BytesToday:  512,
+				Quota:       task.Quota{MaxActiveTasks: 5, MaxStoredBytes: 1 << 20},
+			}, nil
+		},
+	}
+
+	set := taskendpoint.New(svc)
+	usage, err := set.GetUsage(context.Background(), 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), usage.ActiveTasks)
+	assert.Equal(t, int64(2048), usage.StoredBytes)
+	assert.Equal(t, int64(512), usage.BytesToday)
+	assert.Equal(t, task.Quota{MaxActiveTasks: 5, MaxStoredBytes: 1 << 20}, usage.Quota)
+}
+
+func TestSet_UpdateSchedule_RoundTrip(t *testing.T) {
+	next := time.Date(2030, 1, 2, 3, 0, 0, 0, time.UTC)
+	svc := &mockTaskService{
+		updateScheduleFn: func(_ context.Context, param *task.UpdateScheduleParam) (*task.Schedule, error) {
+			assert.Equal(t, uint64(9), param.ID)
+			require.NotNil(t, param.Cron)
+			assert.Equal(t, "0 3 * * *", *param.Cron)
+			assert.Nil(t, param.Enabled)
+			assert.Nil(t, param.StartAt)
+			return &task.Schedule{
+				ID:          9,
+				OfAccountID: 1,
+				Cron:        *param.Cron,
+				Enabled:     true,
+				NextRunAt:   &next,
+				NextTaskID:  42,
+				Template:    &task.CreateTaskParam{SourceURL: "https://example.com/nightly.zip"},
+			}, nil
+		},
+	}
+
+	set := taskendpoint.New(svc)
+	cron := "0 3 * * *"
+	sched, err := set.UpdateSchedule(context.Background(), &task.UpdateScheduleParam{ID: 9, Cron: &cron})
+
+	require.NoError(t, err)
+	assert.Equal(t, "0 3 * * *", sched.Cron)
+	assert.True(t, sched.Enabled)
+	require.NotNil(t, sched.NextRunAt)
+	assert.True(t, next.Equal(*sched.NextRunAt))
+	assert.Nil(t, sched.LastRunAt)
+	assert.Equal(t, uint64(42), sched.NextTaskID)
+	assert.Equal(t, "https://example.com/nightly.zip", sched.Template.SourceURL)
+}
+
+func TestSet_UpdateWebhook_RoundTrip(t *testing.T) {
+	var got []*task.UpdateWebhookParam
+	svc := &mockTaskService{
+		updateWebhookFn: func(_ context.Context, param *task.UpdateWebhookParam) (*task.Webhook, error) {
+			got = append(got, param)
+			return &task.Webhook{
+				ID:          param.ID,
+				OfAccountID: 1,
+				URL:         "https://example.com/hook",
+				Secret:      "s3cret",
+				Enabled:     true,
+			}, nil
+		},
+	}
+	set := taskendpoint.New(svc)
+
+	// Leaving the events unset keeps them; an empty list subscribes to all.
+	_, err := set.UpdateWebhook(context.Background(), &task.UpdateWebhookParam{ID: 3})
+	require.NoError(t, err)
+	empty := []events.EventType{}
+	w, err := set.UpdateWebhook(context.Background(), &task.UpdateWebhookParam{ID: 3, Events: &empty})
+	require.NoError(t, err)
+
+	require.Len(t, got, 2)
+	assert.Nil(t, got[0].Events)
+	require.NotNil(t, got[1].Events)
+	assert.Empty(t, *got[1].Events)
+	assert.Equal(t, "https://example.com/hook", w.URL)
+	assert.Equal(t, "s3cret", w.Secret)
+}
+
+func TestSet_CreateTasks_RoundTrip(t *testing.T) {
+	var got *task.CreateTasksParam
+	svc := &mockTaskService{
+		createTasksFn: func(_ context.Context, param *task.CreateTasksParam) ([]*task.Task, error) {
+			got = param
+			return []*task.Task{stubTask(1), stubTask(2)}, nil
+		},
+	}
+	set := taskendpoint.New(svc)
+
+	tasks, err := set.CreateTasks(context.Background(), &task.CreateTasksParam{
+		OfAccountID: 7,
+		Tasks: []*task.CreateTaskParam{{
+			SourceURL: "https://example.com/a.zip",
+			Priority:  task.PriorityHigh,
+			Schedule:  &task.ScheduleSpec{Cron: "0 * * * *"},
+		}},
+		URLList:  "https://example.com/b.zip\n",
+		Metalink: []byte("<metalink/>"),
+		Priority: task.PriorityLow,
+	})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	require.NotNil(t, got)
+	assert.Equal(t, uint64(7), got.OfAccountID)
+	require.Len(t, got.Tasks, 1)
+	assert.Equal(t, "https://example.com/a.zip", got.Tasks[0].SourceURL)
+	assert.Equal(t, task.PriorityHigh, got.Tasks[0].Priority)
+	assert.Nil(t, got.Tasks[0].Schedule)
+	assert.Equal(t, "https://example.com/b.zip\n", got.URLList)
+	assert.Equal(t, []byte("<metalink/>"), got.Metalink)
+	assert.Equal(t, task.PriorityLow, got.Priority)
+}
+
+func TestSet_BulkTasks_RoundTrip(t *testing.T) {
+	var got *task.BulkTaskParam
+	svc := &mockTaskService{
+		bulkTasksFn: func(_ context.Context, param *task.BulkTaskParam) (*task.BulkTaskOutput, error) {
+			got = param
+			return &task.BulkTaskOutput{
+				Succeeded: []uint64{1},
+				Failed:    []*task.BulkTaskFailure{{TaskID: 2, Message: "Only paused tasks can be resumed"}},
+			}, nil
+		},
+	}
+	set := taskendpoint.New(svc)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out, err := set.BulkTasks(context.Background(), &task.BulkTaskParam{
+		OfAccountID: 7,
+		Action:      task.BulkActionResume,
+		Filter: &task.TaskFilter{
+			Status:     []task.TaskStatus{task.StatusPaused},
+			SourceType: []task.SourceType{task.SourceHTTPS},
+			Tags:       []string{"nightly"},
+			CreatedAt:  &task.TimeRange{From: &from},
+			Search:     "iso",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1}, out.Succeeded)
+	require.Len(t, out.Failed, 1)
+	assert.Equal(t, uint64(2), out.Failed[0].TaskID)
+
+	require.NotNil(t, got)
+	assert.Equal(t, task.BulkActionResume, got.Action)
+	require.NotNil(t, got.Filter)
+	assert.Equal(t, []task.TaskStatus{task.StatusPaused}, got.Filter.Status)
+	assert.Equal(t, []task.SourceType{task.SourceHTTPS}, got.Filter.SourceType)
+	assert.Equal(t, []string{"nightly"}, got.Filter.Tags)
+	require.NotNil(t, got.Filter.CreatedAt)
+	assert.True(t, from.Equal(*got.Filter.CreatedAt.From))
+	assert.Nil(t, got.Filter.CreatedAt.To)
+	assert.Equal(t, "iso", got.Filter.Search)
+}
+
+func TestSet_AuditEvents_RoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var recorded *task.AuditEvent
+	var listed *task.ListAuditEventsParam
+	svc := &mockTaskService{
+		recordAuditEventFn: func(_ context.Context, e *task.AuditEvent) error {
+			recorded = e
+			return nil
+		},
+		listAuditEventsFn: func(_ context.Context, param *task.ListAuditEventsParam) ([]*task.AuditEvent, error) {
+			listed = param
+			return []*task.AuditEvent{recorded}, nil
+		},
+	}
+	set := taskendpoint.New(svc)
+
+	ev := &task.AuditEvent{
+		Time:           at,
+		ActorAccountID: 7,
+		ActorAPIKeyID:  3,
+		Action:         task.AuditTaskDelete,
+		TaskID:         42,
+		SourceIP:       "203.0.113.9",
+		Outcome:        task.AuditDenied,
+		Message:        "permission denied",
+	}
+	require.NoError(t, set.RecordAuditEvent(context.Background(), ev))
+	require.NotNil(t, recorded)
+	assert.Equal(t, *ev, *recorded)
+
+	out, err := set.ListAuditEvents(context.Background(), &task.ListAuditEventsParam{
+		Filter: task.AuditFilter{
+			AccountID: 7,
+			TaskID:    42,
+			Actions:   []task.AuditAction{task.AuditTaskDelete, task.AuditTaskDownload},
+			Outcome:   task.AuditDenied,
+			From:      &at,
+		},
+		BeforeID: 100,
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, *ev, *out[0])
+
+	require.NotNil(t, listed)
+	assert.Equal(t, uint64(7), listed.Filter.AccountID)
+	assert.Equal(t, []task.AuditAction{task.AuditTaskDelete, task.AuditTaskDownload}, listed.Filter.Actions)
+	assert.Equal(t, task.AuditDenied, listed.Filter.Outcome)
+	require.NotNil(t, listed.Filter.From)
+	assert.True(t, at.Equal(*listed.Filter.From))
+	assert.Nil(t, listed.Filter.To)
+	assert.Equal(t, uint64(100), listed.BeforeID)
+	assert.Equal(t, int32(10), listed.Limit)
+}
+
+func TestSet_WatchTasks_RoundTrip(t *testing.T) {
+	errMsg := "boom"
+	svc := &mockTaskService{
+		watchTasksFn: func(_ context.Context, ofAccountID, lastEventID uint64) (<-chan *task.TaskEvent, error) {
+			assert.Equal(t, uint64(1), ofAccountID)
+			assert.Equal(t, uint64(100), lastEventID)
+			ch := make(chan *task.TaskEvent, 2)
+			ch <- &task.TaskEvent{
+				ID:       101,
+				Type:     task.TaskEventProgress,
+				TaskID:   7,
+				Progress: &task.DownloadProgress{Progress: 50, DownloadedBytes: 5, TotalBytes: 10},
+			}
+			ch <- &task.TaskEvent{
+				ID:           102,
+				Type:         task.TaskEventStatus,
+				TaskID:       7,
+				Status:       task.StatusFailed,
+				ErrorMessage: &errMsg,
+			}
+			close(ch)
+			return ch, nil
+		},
+	}
+
+	set := taskendpoint.New(svc)
+	events, err := set.WatchTasks(context.Background(), 1, 0, 100)
+	require.NoError(t, err)
+
+	var got []*task.TaskEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+	require.Len(t, got, 2)
+	assert.Equal(t, uint64(101), got[0].ID)
+	assert.Equal(t, task.TaskEventProgress, got[0].Type)
+	assert.Empty(t, got[0].Status)
+	require.NotNil(t, got[0].Progress)
+	assert.Equal(t, int64(5), got[0].Progress.DownloadedBytes)
+	assert.Equal(t, task.TaskEventStatus, got[1].Type)
+	assert.Equal(t, task.StatusFailed, got[1].Status)
+	require.NotNil(t, got[1].ErrorMessage)
+	assert.Equal(t, "boom", *got[1].ErrorMessage)
+}
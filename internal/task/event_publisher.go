@@ -10,22 +10,98 @@ import (
 
 	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/pkg/message"
+	"github.com/yuisofull/goload/pkg/message/middleware"
 )
 
 // Publisher publishes task-related events
 type Publisher struct {
 	publisher message.Publisher
+	outbox    OutboxRepository
+}
+
+// PublisherOption configures a Publisher.
+type PublisherOption func(*Publisher)
+
+// WithOutbox stores the events in outbox, in the transaction of the
+// repository writes they report, instead of publishing them directly. An
+// OutboxRelay publishes them once the transaction committed.
+func WithOutbox(outbox OutboxRepository) PublisherOption {
+	return func(ep *Publisher) {
+		ep.outbox = outbox
+	}
 }
 
 // NewEventPublisher creates a new event publisher for task service
-func NewEventPublisher(publisher message.Publisher) *Publisher {
-	return &Publisher{
+func NewEventPublisher(publisher message.Publisher, opts ...PublisherOption) *Publisher {
+	ep := &Publisher{
 		publisher: publisher,
 	}
+	for _, opt := range opts {
+		opt(ep)
+	}
+	return ep
+}
+
+// publish publishes the messages of a task, or stores them in the outbox
+// when there is one. The messages carry the correlation ID of ctx.
+func (ep *Publisher) publish(ctx context.Context, topic string, taskID uint64, msgs ...*message.Message) error {
+	if id := middleware.CorrelationIDFromContext(ctx); id != "" {
+		for _, msg := range msgs {
+			middleware.SetCorrelationID(id, msg)
+		}
+	}
+	if ep.outbox != nil {
+		return ep.outbox.AddOutboxMessages(ctx, topic, taskID, msgs...)
+	}
+	return ep.publisher.Publish(topic, msgs...)
 }
 
 // PublishTaskCreated publishes a task created event
 func (ep *Publisher) PublishTaskCreated(ctx context.Context, task *Task) error {
+	msg, err := ep.taskCreatedMessage(task)
+	if err != nil {
+		return err
+	}
+	return ep.publish(ctx, "task.created", task.ID, msg)
+}
+
+// PublishTaskRetried hands a retried task to the download workers again and
+// publishes a task retried event. The task.created message is marked with
+// events.MetadataRetry so that it is not taken for a new task.
+func (ep *Publisher) PublishTaskRetried(ctx context.Context, task *Task, reason string) error {
+	created, err := ep.taskCreatedMessage(task)
+	if err != nil {
+		return err
+	}
+	created.Metadata.Set(events.MetadataRetry, "true")
+	if err := ep.publish(ctx, "task.created", task.ID, created); err != nil {
+		return err
+	}
+
+	event := events.TaskRetriedEvent{
+		TaskID:    task.ID,
+		Reason:    reason,
+		RetriedAt: time.Now(),
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := &message.Message{
+		UUID:    generateUUID(),
+		Payload: payload,
+		Metadata: message.Metadata{
+			"eventType": "TaskRetried",
+			"taskID":    formatTaskID(task.ID),
+		},
+	}
+
+	return ep.publish(ctx, "task.retried", task.ID, msg)
+}
+
+func (ep *Publisher) taskCreatedMessage(task *Task) (*message.Message, error) {
 	event := events.TaskCreatedEvent{
 		TaskID:          task.ID,
 		OfAccountID:     task.OfAccountID,
@@ -35,24 +111,24 @@ func (ep *Publisher) PublishTaskCreated(ctx context.Context, task *Task) error {
 		SourceAuth:      ep.convertAuthConfig(task.SourceAuth),
 		DownloadOptions: ep.convertDownloadOptions(task.DownloadOptions),
 		Metadata:        task.Metadata,
+		Checksum:        ep.convertChecksum(task.Checksum),
+		Priority:        string(task.Priority),
 		CreatedAt:       task.CreatedAt,
 	}
 
 	payload, err := json.Marshal(event)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	msg := &message.Message{
+	return &message.Message{
 		UUID:    generateUUID(),
 		Payload: payload,
 		Metadata: message.Metadata{
 			"eventType": "TaskCreated",
 			"taskID":    formatTaskID(task.ID),
 		},
-	}
-
-	return ep.publisher.Publish("task.created", msg)
+	}, nil
 }
 
 // PublishTaskStatusUpdated publishes a task status update event
@@ -77,11 +153,11 @@ func (ep *Publisher) PublishTaskStatusUpdated(ctx context.Context, taskID uint64
 		},
 	}
 
-	return ep.publisher.Publish("task.status.updated", msg)
+	return ep.publish(ctx, "task.status.updated", taskID, msg)
 }
 
 // PublishTaskPaused publishes a task paused event
-func (ep *Publisher) PublishTaskPaused(ctx context.Context, taskID uint64) error {
+func (ep *Publisher) PublishTaskPaused(ctx context.Context, taskID uint64, workerID string) error {
 	event := events.TaskPausedEvent{
 		TaskID:   taskID,
 		PausedAt: time.Now(),
@@ -101,11 +177,11 @@ func (ep *Publisher) PublishTaskPaused(ctx context.Context, taskID uint64) error
 		},
 	}
 
-	return ep.publisher.Publish("task.paused", msg)
+	return ep.publishControl(ctx, events.EventTaskPaused, taskID, workerID, msg)
 }
 
 // PublishTaskResumed publishes a task resumed event
-func (ep *Publisher) PublishTaskResumed(ctx context.Context, taskID uint64) error {
+func (ep *Publisher) PublishTaskResumed(ctx context.Context, taskID uint64, workerID string) error {
 	event := events.TaskResumedEvent{
 		TaskID:    taskID,
 		ResumedAt: time.Now(),
@@ -125,11 +201,11 @@ func (ep *Publisher) PublishTaskResumed(ctx context.Context, taskID uint64) erro
 		},
 	}
 
-	return ep.publisher.Publish("task.resumed", msg)
+	return ep.publishControl(ctx, events.EventTaskResumed, taskID, workerID, msg)
 }
 
 // PublishTaskCancelled publishes a task cancelled event
-func (ep *Publisher) PublishTaskCancelled(ctx context.Context, taskID uint64) error {
+func (ep *Publisher) PublishTaskCancelled(ctx context.Context, taskID uint64, workerID string) error {
 	event := events.TaskCancelledEvent{
 		TaskID:      taskID,
 		CancelledAt: time.Now(),
@@ -149,7 +225,27 @@ func (ep *Publisher) PublishTaskCancelled(ctx context.Context, taskID uint64) er
 		},
 	}
 
-	return ep.publisher.Publish("task.cancelled", msg)
+	return ep.publishControl(ctx, events.EventTaskCancelled, taskID, workerID, msg)
+}
+
+// publishControl publishes a control event to its shared topic, on which the
+// task lifecycle is observed, e.g. by webhooks. The event of a task claimed by
+// a download worker names the worker in events.MetadataWorkerID and is also
+// published to the worker topic of that worker, which only it subscribes to.
+func (ep *Publisher) publishControl(
+	ctx context.Context,
+	event events.EventType,
+	taskID uint64,
+	workerID string,
+	msg *message.Message,
+) error {
+	if workerID != "" {
+		msg.Metadata.Set(events.MetadataWorkerID, workerID)
+		if err := ep.publish(ctx, events.WorkerTopic(event, workerID), taskID, msg.Copy()); err != nil {
+			return err
+		}
+	}
+	return ep.publish(ctx, string(event), taskID, msg)
 }
 
 // Helper methods for converting task types to event types
@@ -178,6 +274,16 @@ func (ep *Publisher) convertDownloadOptions(opts *DownloadOptions) *events.Downl
 	}
 }
 
+func (ep *Publisher) convertChecksum(checksum *ChecksumInfo) *events.ChecksumInfo {
+	if checksum == nil {
+		return nil
+	}
+	return &events.ChecksumInfo{
+		ChecksumType:  checksum.ChecksumType,
+		ChecksumValue: checksum.ChecksumValue,
+	}
+}
+
 func generateUUID() string {
 	return uuid.New().String()
 }
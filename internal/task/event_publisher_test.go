@@ -0,0 +1,44 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/events"
+)
+
+func TestPauseTask_PublishesToClaimingWorker(t *testing.T) {
+	repo := &fakeRepo{task: &Task{ID: 5, Status: StatusDownloading, WorkerID: "download-1"}}
+	msgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(msgPub), fakeTxManager{})
+
+	require.NoError(t, svc.PauseTask(context.Background(), 5))
+	require.Equal(t, []string{"task.paused.download-1", "task.paused"}, msgPub.topics)
+	for _, msg := range msgPub.msgs {
+		require.Equal(t, "download-1", msg.Metadata.Get(events.MetadataWorkerID))
+	}
+}
+
+func TestWorkerTopic_ReplacesInvalidCharacters(t *testing.T) {
+	require.Equal(t, "task.cancelled.pod_a-1.example", events.WorkerTopic(events.EventTaskCancelled, "pod:a-1.example"))
+}
+
+func TestCancelTask_PublishesToSharedTopicWithoutWorker(t *testing.T) {
+	repo := &fakeRepo{task: &Task{ID: 5, Status: StatusPending}}
+	msgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(msgPub), fakeTxManager{})
+
+	require.NoError(t, svc.CancelTask(context.Background(), 5))
+	require.Equal(t, []string{"task.cancelled"}, msgPub.topics)
+	require.Empty(t, msgPub.msgs[0].Metadata.Get(events.MetadataWorkerID))
+}
+
+func TestUpdateTaskWorker_RecordsWorker(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{})
+
+	require.NoError(t, svc.UpdateTaskWorker(context.Background(), 5, "download-1"))
+	require.Equal(t, &Task{ID: 5, WorkerID: "download-1"}, repo.updated)
+}
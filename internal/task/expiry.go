@@ -0,0 +1,240 @@
+package task
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/storage"
+)
+
+const (
+	defaultExpirationDays = 30
+	maxExpirationDays     = 3650
+	expiredTasksBatch     = 100
+)
+
+// WithDefaultExpirationDays sets how many days the stored file of a task is
+// kept after it was last accessed when the task does not say. Zero keeps
+// files until their task is deleted. Defaults to 30.
+func WithDefaultExpirationDays(days int32) ServiceOption {
+	return func(s *service) {
+		if days >= 0 {
+			s.defaultExpirationDays = days
+		}
+	}
+}
+
+// WithFileStore configures the storage the stored files of tasks live in, so
+// that the files of deleted and expired tasks are deleted with them. Without
+// one, expired tasks are only marked and the storage backend removes the
+// files itself.
+func WithFileStore(w storage.Writer) ServiceOption {
+	return func(s *service) { s.fileStore = w }
+}
+
+// WithExpiredTaskPurge deletes expired tasks instead of keeping them with
+// the EXPIRED status.
+func WithExpiredTaskPurge(purge bool) ServiceOption {
+	return func(s *service) { s.purgeExpired = purge }
+}
+
+// ExpiryRunner expires tasks whose stored file ran out of retention. It is
+// implemented by the service returned from NewService.
+type ExpiryRunner interface {
+	ExpireDueTasks(ctx context.Context, now time.Time) (int, error)
+	// ExpireTask expires a completed task whose stored file was already
+	// removed by the storage backend.
+	ExpireTask(ctx context.Context, id uint64) error
+}
+
+// ExpirySweeper periodically expires tasks whose stored file ran out of
+// retention.
+type ExpirySweeper struct {
+	runner   ExpiryRunner
+	interval time.Duration
+	logger   log.Logger
+}
+
+// ExpirySweeperOption configures an ExpirySweeper.
+type ExpirySweeperOption func(*ExpirySweeper)
+
+// WithExpirySweeperInterval sets how often expired tasks are polled. Defaults to 1h.
+func WithExpirySweeperInterval(d time.Duration) ExpirySweeperOption {
+	return func(e *ExpirySweeper) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// WithExpirySweeperLogger configures the sweeper logger.
+func WithExpirySweeperLogger(l log.Logger) ExpirySweeperOption {
+	return func(e *ExpirySweeper) { e.logger = l }
+}
+
+func NewExpirySweeper(runner ExpiryRunner, opts ...ExpirySweeperOption) *ExpirySweeper {
+	e := &ExpirySweeper{
+		runner:   runner,
+		interval: time.Hour,
+		logger:   log.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+// Run expires due tasks until ctx is done.
+func (e *ExpirySweeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := e.runner.ExpireDueTasks(ctx, time.Now())
+		if err != nil {
+			level.Error(e.logger).Log("msg", "failed to expire tasks", "err", err)
+		} else if n > 0 {
+			level.Info(e.logger).Log("msg", "expired tasks", "count", n)
+		}
+		if n >= expiredTasksBatch {
+			// More tasks may be due; do not wait for the next tick.
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDueTasks deletes the stored files of the tasks that expired at or
+// before now and marks the tasks expired. It returns the number of tasks
+// that were expired.
+func (s *service) ExpireDueTasks(ctx context.Context, now time.Time) (int, error) {
+	due, err := s.repo.ListExpired(ctx, now, expiredTasksBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int
+	for _, t := range due {
+		if err := s.expireTask(ctx, t, true); err != nil {
+			level.Error(s.logger).Log("msg", "failed to expire task", "task_id", t.ID, "err", err)
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *service) ExpireTask(ctx context.Context, id uint64) error {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return &errors.Error{Code: errors.ErrCodeNotFound, Message: "task not found", Cause: err}
+		}
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "expire task failed", Cause: err}
+	}
+	// Only the file of a completed task counts; the task may also have been
+	// expired by the sweeper already.
+	if t.Status != StatusCompleted {
+		return nil
+	}
+	return s.expireTask(ctx, t, false)
+}
+
+// expireTask marks t expired, or deletes it when expired tasks are purged.
+// With deleteFile the stored file is deleted first, so that a failed delete
+// is retried by the next sweep.
+func (s *service) expireTask(ctx context.Context, t *Task, deleteFile bool) error {
+	if deleteFile {
+		if err := s.releaseStoredFile(ctx, t); err != nil {
+			return fmt.Errorf("delete stored file: %w", err)
+		}
+	}
+
+	if s.purgeExpired {
+		if err := s.repo.Delete(ctx, t.ID); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to purge expired task", Cause: err}
+		}
+		s.emit(&TaskEvent{
+			Type:        TaskEventDeleted,
+			TaskID:      t.ID,
+			OfAccountID: t.OfAccountID,
+			WorkspaceID: t.WorkspaceID,
+		})
+		s.recordAudit(ctx, AuditTaskDelete, t, "")
+		return nil
+	}
+
+	t.Status = StatusExpired
+	if _, err := s.repo.Update(ctx, &Task{ID: t.ID, Status: StatusExpired}); err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to expire task", Cause: err}
+	}
+	s.emitStatus(t)
+	s.recordAudit(ctx, AuditTaskExpire, t, "")
+	return nil
+}
+
+func (s *service) ExtendTaskRetention(ctx context.Context, param *ExtendTaskRetentionParam) (*Task, error) {
+	if param.Days <= 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "days must be positive"}
+	}
+	if err := validateExpirationDays(param.Days); err != nil {
+		return nil, err
+	}
+
+	t, err := s.repo.GetByID(ctx, param.TaskID)
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeNotFound, Message: "Task not found", Cause: err}
+	}
+	if t.Status != StatusCompleted {
+		return nil, &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: "Only completed tasks can have their retention extended",
+		}
+	}
+
+	now := time.Now()
+	if _, err := s.repo.Update(ctx, &Task{ID: t.ID, ExpirationDays: param.Days, LastAccessedAt: &now}); err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to extend task retention", Cause: err}
+	}
+	t.ExpirationDays = param.Days
+	t.LastAccessedAt = &now
+	return t, nil
+}
+
+// touchTask records an access to the stored file of t, which restarts its
+// retention. Failures are logged; the access itself goes ahead.
+func (s *service) touchTask(ctx context.Context, t *Task) {
+	if t.ExpirationDays <= 0 {
+		return
+	}
+	now := time.Now()
+	if _, err := s.repo.Update(ctx, &Task{ID: t.ID, LastAccessedAt: &now}); err != nil {
+		level.Warn(s.logger).Log("msg", "failed to record task access", "task_id", t.ID, "err", err)
+		return
+	}
+	t.LastAccessedAt = &now
+}
+
+func validateExpirationDays(days int32) error {
+	if days < 0 || days > maxExpirationDays {
+		return &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("expiration days must be between 0 and %d", maxExpirationDays),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/storage"
+)
+
+type fakeFileStore struct {
+	deleted []string
+	failOn  string
+}
+
+func (f *fakeFileStore) Store(ctx context.Context, key string, r io.Reader, meta *storage.FileMetadata) error {
+	return nil
+}
+
+func (f *fakeFileStore) Exists(ctx context.Context, key string) (bool, error) { return true, nil }
+
+func (f *fakeFileStore) Delete(ctx context.Context, key string) error {
+	f.deleted = append(f.deleted, key)
+	if key == f.failOn {
+		return errors.New("storage unavailable")
+	}
+	return nil
+}
+
+func TestExpireDueTasks_DeletesFilesAndMarksTasksExpired(t *testing.T) {
+	files := &fakeFileStore{failOn: "b"}
+	repo := &fakeRepo{expired: []*Task{
+		{ID: 1, Status: StatusCompleted, StoragePath: "a"},
+		{ID: 2, Status: StatusCompleted, StoragePath: "b"},
+	}}
+	audit := &fakeAuditRepo{}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithFileStore(files), WithAuditRepository(audit)).(*service)
+
+	n, err := svc.ExpireDueTasks(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"a", "b"}, files.deleted)
+	// The task whose file could not be deleted is left for the next sweep.
+	require.Equal(t, uint64(1), repo.updated.ID)
+	require.Equal(t, StatusExpired, repo.updated.Status)
+	require.Empty(t, repo.deleted)
+	require.Len(t, audit.events, 1)
+	require.Equal(t, AuditTaskExpire, audit.events[0].Action)
+	require.Equal(t, uint64(1), audit.events[0].TaskID)
+	require.Zero(t, audit.events[0].ActorAccountID)
+}
+
+func TestExpireDueTasks_PurgesExpiredTasks(t *testing.T) {
+	repo := &fakeRepo{expired: []*Task{{ID: 4, OfAccountID: 3, Status: StatusCompleted, StoragePath: "a"}}}
+	audit := &fakeAuditRepo{}
+	svc := NewService(repo, Publisher{}, fakeTxManager{},
+		WithFileStore(&fakeFileStore{}),
+		WithExpiredTaskPurge(true),
+		WithAuditRepository(audit),
+	).(*service)
+
+	n, err := svc.ExpireDueTasks(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []uint64{4}, repo.deleted)
+	require.Nil(t, repo.updated)
+	require.Len(t, audit.events, 1)
+	require.Equal(t, AuditTaskDelete, audit.events[0].Action)
+	require.Equal(t, uint64(4), audit.events[0].TaskID)
+	require.Equal(t, uint64(3), audit.events[0].OfAccountID)
+}
+
+func TestExpireTask_IgnoresTasksThatAreNotCompleted(t *testing.T) {
+	files := &fakeFileStore{}
+	repo := &fakeRepo{task: &Task{ID: 3, Status: StatusExpired, StoragePath: "a"}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithFileStore(files)).(*service)
+
+	require.NoError(t, svc.ExpireTask(context.Background(), 3))
+	require.Nil(t, repo.updated)
+
+	// The storage backend already removed the file of a completed task.
+	repo.task = &Task{ID: 3, Status: StatusCompleted, StoragePath: "a"}
+	require.NoError(t, svc.ExpireTask(context.Background(), 3))
+	require.Equal(t, StatusExpired, repo.updated.Status)
+	require.Empty(t, files.deleted)
+}
+
+func TestExtendTaskRetention(t *testing.T) {
+	repo := &fakeRepo{task: &Task{ID: 5, Status: StatusDownloading}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{})
+
+	for _, days := range []int32{0, -1, maxExpirationDays + 1} {
+		_, err := svc.ExtendTaskRetention(context.Background(), &ExtendTaskRetentionParam{TaskID: 5, Days: days})
+		require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput), "days %d", days)
+	}
+
+	_, err := svc.ExtendTaskRetention(context.Background(), &ExtendTaskRetentionParam{TaskID: 5, Days: 7})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	repo.task = &Task{ID: 5, Status: StatusCompleted, ExpirationDays: 30}
+	before := time.Now()
+	got, err := svc.ExtendTaskRetention(context.Background(), &ExtendTaskRetentionParam{TaskID: 5, Days: 7})
+	require.NoError(t, err)
+	require.Equal(t, int32(7), got.ExpirationDays)
+	require.Equal(t, int32(7), repo.updated.ExpirationDays)
+	require.NotNil(t, repo.updated.LastAccessedAt)
+	require.False(t, got.ExpiresAt().Before(before.AddDate(0, 0, 7)))
+}
+
+func TestGenerateDownloadURL_RejectsExpiredTasks(t *testing.T) {
+	repo := &fakeRepo{task: &Task{ID: 6, Status: StatusExpired, StoragePath: "a"}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithTokenStore(NewInmemTokenStore()))
+
+	_, _, err := svc.GenerateDownloadURL(context.Background(), 6, time.Minute, false)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestGenerateDownloadURL_RestartsRetention(t *testing.T) {
+	repo := &fakeRepo{task: &Task{ID: 6, Status: StatusCompleted, StoragePath: "a", ExpirationDays: 30}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithTokenStore(NewInmemTokenStore()))
+
+	_, _, err := svc.GenerateDownloadURL(context.Background(), 6, time.Minute, false)
+	require.NoError(t, err)
+	require.NotNil(t, repo.updated)
+	require.NotNil(t, repo.updated.LastAccessedAt)
+}
+
+func TestCreateTask_RejectsInvalidExpirationDays(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{})
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID:    1,
+		FileName:       "file.bin",
+		SourceURL:      "https://example.com/file.bin",
+		SourceType:     SourceHTTPS,
+		ExpirationDays: maxExpirationDays + 1,
+	})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestDeleteTask_DeletesStoredFile(t *testing.T) {
+	files := &fakeFileStore{}
+	repo := &fakeRepo{task: &Task{ID: 7, Status: StatusCompleted, StoragePath: "7/file.iso"}}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{}, WithFileStore(files))
+
+	require.NoError(t, svc.DeleteTask(context.Background(), 7))
+	require.Equal(t, []uint64{7}, repo.deleted)
+	require.Equal(t, []string{"7/file.iso"}, files.deleted)
+}
@@ -0,0 +1,135 @@
+package task
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 1000
+)
+
+// TaskSortField is the order of a task listing. Ties are broken by task id.
+type TaskSortField string
+
+const (
+	SortByCreatedAt TaskSortField = "created_at"
+	SortByUpdatedAt TaskSortField = "updated_at"
+	SortBySize      TaskSortField = "size"
+	SortByName      TaskSortField = "name"
+)
+
+// ParseTaskSortField maps a sort name to a TaskSortField; an empty name
+// sorts by creation time.
+func ParseTaskSortField(s string) (TaskSortField, error) {
+	switch f := TaskSortField(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return SortByCreatedAt, nil
+	case SortByCreatedAt, SortByUpdatedAt, SortBySize, SortByName:
+		return f, nil
+	default:
+		return "", &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("cannot sort tasks by %q", s),
+		}
+	}
+}
+
+// TaskQuery selects one page of the tasks matching Filter.
+type TaskQuery struct {
+	Filter  TaskFilter
+	SortBy  TaskSortField
+	SortAsc bool
+	// After resumes the listing behind the task the cursor was taken from;
+	// Offset is ignored when it is set.
+	After  *TaskCursor
+	Offset uint32
+	Limit  uint32
+}
+
+// TaskCursor is the position of a task in a listing sorted by SortBy. Only
+// the value of the sort field is set besides the task id.
+type TaskCursor struct {
+	SortBy  TaskSortField `json:"s"`
+	SortAsc bool          `json:"a,omitempty"`
+	ID      uint64        `json:"i"`
+	Time    *time.Time    `json:"t,omitempty"`
+	Size    int64         `json:"z,omitempty"`
+	Name    string        `json:"n,omitempty"`
+}
+
+// NewTaskCursor returns the cursor pointing behind t.
+func NewTaskCursor(t *Task, sortBy TaskSortField, sortAsc bool) *TaskCursor {
+	c := &TaskCursor{SortBy: sortBy, SortAsc: sortAsc, ID: t.ID}
+	switch sortBy {
+	case SortByCreatedAt:
+		c.Time = &t.CreatedAt
+	case SortByUpdatedAt:
+		c.Time = &t.UpdatedAt
+	case SortBySize:
+		if t.Progress != nil {
+			c.Size = t.Progress.TotalBytes
+		}
+	case SortByName:
+		c.Name = t.FileName
+	}
+	return c
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (c *TaskCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeTaskCursor parses a cursor returned by Encode.
+func DecodeTaskCursor(s string) (*TaskCursor, error) {
+	invalid := &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "invalid cursor"}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		invalid.Cause = err
+		return nil, invalid
+	}
+	var c TaskCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		invalid.Cause = err
+		return nil, invalid
+	}
+	if _, err := ParseTaskSortField(string(c.SortBy)); err != nil || c.SortBy == "" || c.ID == 0 {
+		return nil, invalid
+	}
+	if (c.SortBy == SortByCreatedAt || c.SortBy == SortByUpdatedAt) && c.Time == nil {
+		return nil, invalid
+	}
+	return &c, nil
+}
+
+// Validate rejects statuses and source types the filter can never match
+// and an empty creation time range.
+func (f TaskFilter) Validate() error {
+	for _, s := range f.Status {
+		switch s {
+		case StatusPending, StatusDownloading, StatusStoring, StatusCompleted,
+			StatusFailed, StatusCancelled, StatusPaused, StatusScheduled, StatusExpired:
+		default:
+			return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown task status %q", s)}
+		}
+	}
+	for _, s := range f.SourceType {
+		switch s {
+		case SourceHTTP, SourceHTTPS, SourceFTP, SourceSFTP, SourceBitTorrent:
+		default:
+			return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown source type %q", s)}
+		}
+	}
+	if r := f.CreatedAt; r != nil && r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "created_from must be before created_to"}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+)
+
+func TestListTasks_ReturnsCursorForFullPage(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{
+		count: 3,
+		listed: []*Task{
+			{ID: 9, OfAccountID: 7, CreatedAt: created.Add(time.Hour)},
+			{ID: 4, OfAccountID: 7, CreatedAt: created},
+		},
+	}
+	svc := NewService(repo, Publisher{}, fakeTxManager{})
+
+	out, err := svc.ListTasks(context.Background(), &ListTasksParam{
+		Filter: &TaskFilter{OfAccountID: 7, Status: []TaskStatus{StatusCompleted}},
+		Limit:  2,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, out.NextCursor)
+	assert.Equal(t, SortByCreatedAt, repo.query.SortBy)
+	assert.False(t, repo.query.SortAsc)
+	assert.Equal(t, []TaskStatus{StatusCompleted}, repo.query.Filter.Status)
+
+	cursor, err := DecodeTaskCursor(out.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), cursor.ID)
+	require.NotNil(t, cursor.Time)
+	assert.True(t, created.Equal(*cursor.Time))
+
+	// The cursor replaces the offset of the next request.
+	repo.listed = repo.listed[1:]
+	out, err = svc.ListTasks(context.Background(), &ListTasksParam{
+		Filter: &TaskFilter{OfAccountID: 7},
+		Offset: 10,
+		Limit:  2,
+		Cursor: out.NextCursor,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, out.NextCursor)
+	require.NotNil(t, repo.query.After)
+	assert.Equal(t, uint64(4), repo.query.After.ID)
+	assert.Zero(t, repo.query.Offset)
+}
+
+func TestListTasks_RejectsInvalidSortAndCursor(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{})
+	ctx := context.Background()
+
+	_, err := svc.ListTasks(ctx, &ListTasksParam{Filter: &TaskFilter{OfAccountID: 7}, SortBy: "priority"})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	_, err = svc.ListTasks(ctx, &ListTasksParam{Filter: &TaskFilter{OfAccountID: 7}, Cursor: "not a cursor"})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	cursor := NewTaskCursor(&Task{ID: 3, FileName: "a.iso"}, SortByName, true).Encode()
+	_, err = svc.ListTasks(ctx, &ListTasksParam{
+		Filter: &TaskFilter{OfAccountID: 7},
+		SortBy: "size",
+		Cursor: cursor,
+	})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	_, err = svc.ListTasks(ctx, &ListTasksParam{Filter: &TaskFilter{OfAccountID: 7}, Limit: maxListLimit + 1})
+	assert.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
@@ -0,0 +1,148 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	task "github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/internal/task/mysql/sqlc"
+)
+
+type auditRepo struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewAuditRepo(db *sql.DB) task.AuditRepository {
+	return &auditRepo{db: db, queries: sqlc.New(db)}
+}
+
+func (r *auditRepo) dbtx(ctx context.Context) sqlc.DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return r.db
+}
+
+func (r *auditRepo) CreateAuditEvent(ctx context.Context, e *task.AuditEvent) error {
+	var message sql.NullString
+	if e.Message != "" {
+		message = sql.NullString{String: e.Message, Valid: true}
+	}
+	result, err := sqlc.New(r.dbtx(ctx)).CreateAuditEvent(ctx, sqlc.CreateAuditEventParams{
+		Time:           e.Time.UTC(),
+		ActorAccountID: e.ActorAccountID,
+		ActorApiKeyID:  e.ActorAPIKeyID,
+		Action:         string(e.Action),
+		TaskID:         e.TaskID,
+		OfAccountID:    e.OfAccountID,
+		WorkspaceID:    e.WorkspaceID,
+		SourceIp:       e.SourceIP,
+		Outcome:        string(e.Outcome),
+		Message:        message,
+	})
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	e.ID = uint64(id)
+	return nil
+}
+
+// Audit listings are filtered at runtime, like task listings. The column
+// list follows sqlc.TaskAuditEvent.
+const auditEventColumns = `id, time, actor_account_id, actor_api_key_id, action, task_id, of_account_id, workspace_id, source_ip, outcome, message`
+
+func (r *auditRepo) ListAuditEvents(ctx context.Context, query task.AuditQuery) ([]*task.AuditEvent, error) {
+	where, args := auditFilterWhere(query.Filter)
+	if query.BeforeID != 0 {
+		where += " AND id < ?"
+		args = append(args, query.BeforeID)
+	}
+	stmt := fmt.Sprintf("SELECT %s FROM task_audit_events WHERE %s ORDER BY id DESC LIMIT ?", auditEventColumns, where)
+	args = append(args, query.Limit)
+
+	rows, err := r.dbtx(ctx).QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var evs []*task.AuditEvent
+	for rows.Next() {
+		var i sqlc.TaskAuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.ActorAccountID,
+			&i.ActorApiKeyID,
+			&i.Action,
+			&i.TaskID,
+			&i.OfAccountID,
+			&i.WorkspaceID,
+			&i.SourceIp,
+			&i.Outcome,
+			&i.Message,
+		); err != nil {
+			return nil, err
+		}
+		evs = append(evs, toAuditEvent(i))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return evs, nil
+}
+
+// auditFilterWhere returns the WHERE clause selecting the events of filter.
+func auditFilterWhere(filter task.AuditFilter) (string, []any) {
+	conds := []string{"(actor_account_id = ? OR (of_account_id = ? AND workspace_id = 0))"}
+	args := []any{filter.AccountID, filter.AccountID}
+	if filter.WorkspaceID != 0 {
+		conds = []string{"workspace_id = ?"}
+		args = []any{filter.WorkspaceID}
+	}
+	if filter.TaskID != 0 {
+		conds = append(conds, "task_id = ?")
+		args = append(args, filter.TaskID)
+	}
+	if len(filter.Actions) > 0 {
+		conds = append(conds, "action IN ("+placeholders(len(filter.Actions))+")")
+		for _, a := range filter.Actions {
+			args = append(args, string(a))
+		}
+	}
+	if filter.Outcome != "" {
+		conds = append(conds, "outcome = ?")
+		args = append(args, string(filter.Outcome))
+	}
+	if filter.From != nil {
+		conds = append(conds, "time >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if filter.To != nil {
+		conds = append(conds, "time < ?")
+		args = append(args, filter.To.UTC())
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func toAuditEvent(i sqlc.TaskAuditEvent) *task.AuditEvent {
+	return &task.AuditEvent{
+		ID:             i.ID,
+		Time:           i.Time,
+		ActorAccountID: i.ActorAccountID,
+		ActorAPIKeyID:  i.ActorApiKeyID,
+		Action:         task.AuditAction(i.Action),
+		TaskID:         i.TaskID,
+		OfAccountID:    i.OfAccountID,
+		WorkspaceID:    i.WorkspaceID,
+		SourceIP:       i.SourceIp,
+		Outcome:        task.AuditOutcome(i.Outcome),
+		Message:        i.Message.String,
+	}
+}
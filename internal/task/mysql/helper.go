@@ -1,6 +1,10 @@
 package mysql
 
-import "encoding/json"
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
 
 func toJSON(v any) ([]byte, error) {
 	if v == nil {
@@ -25,3 +29,17 @@ func getOrEmpty[T any](ptr *T) T {
 	var zero T
 	return zero
 }
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func fromNullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,153 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	task "github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/internal/task/mysql/sqlc"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// outboxLock is the named lock held by the relay that drains the outbox, so
+// that the relays of several task service replicas do not publish the
+// messages of a task out of order.
+const outboxLock = "goload.task_outbox"
+
+type outboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) task.OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+func (r *outboxRepo) dbtx(ctx context.Context) sqlc.DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return r.db
+}
+
+func (r *outboxRepo) AddOutboxMessages(
+	ctx context.Context,
+	topic string,
+	taskID uint64,
+	msgs ...*message.Message,
+) error {
+	q := sqlc.New(r.dbtx(ctx))
+	for _, msg := range msgs {
+		metadata, err := json.Marshal(msg.Metadata)
+		if err != nil {
+			return fmt.Errorf("marshal Metadata: %w", err)
+		}
+		if err := q.CreateOutboxMessage(ctx, sqlc.CreateOutboxMessageParams{
+			Topic:       topic,
+			TaskID:      taskID,
+			MessageUuid: msg.UUID,
+			Payload:     string(msg.Payload),
+			Metadata:    metadata,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DrainOutbox returns at once when the relay of another replica holds the
+// outbox lock.
+func (r *outboxRepo) DrainOutbox(
+	ctx context.Context,
+	limit int,
+	publish func(*task.OutboxMessage) error,
+) (int, error) {
+	// Named locks belong to a connection, so the whole round runs on one.
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, outboxLock).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return 0, nil
+	}
+	defer func() {
+		var released sql.NullInt64
+		_ = conn.QueryRowContext(context.WithoutCancel(ctx), `SELECT RELEASE_LOCK(?)`, outboxLock).Scan(&released)
+	}()
+
+	q := sqlc.New(conn)
+	now := time.Now().UTC()
+	rows, err := q.ListOutboxMessages(ctx, sqlc.ListOutboxMessagesParams{
+		Now:   sql.NullTime{Time: now, Valid: true},
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[uint64]bool)
+	for _, row := range rows {
+		if row.TaskID != 0 && blocked[row.TaskID] {
+			continue
+		}
+		m, err := toOutboxMessage(row)
+		if err != nil {
+			if err := q.DeadLetterOutboxMessage(ctx, sqlc.DeadLetterOutboxMessageParams{
+				DeadAt:    sql.NullTime{Time: now, Valid: true},
+				LastError: sql.NullString{String: err.Error(), Valid: true},
+				ID:        row.ID,
+			}); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := publish(m); err != nil {
+			blocked[row.TaskID] = row.TaskID != 0
+			if err := q.RetryOutboxMessage(ctx, sqlc.RetryOutboxMessageParams{
+				NextAttemptAt: sql.NullTime{Time: now.Add(task.OutboxRetryDelay(row.Attempts + 1)), Valid: true},
+				LastError:     sql.NullString{String: err.Error(), Valid: true},
+				ID:            row.ID,
+			}); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := q.DeleteOutboxMessage(ctx, row.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+func toOutboxMessage(row sqlc.TaskOutbox) (*task.OutboxMessage, error) {
+	msg := message.NewMessage(row.MessageUuid, message.Payload(row.Payload))
+	if len(row.Metadata) > 0 {
+		if err := json.Unmarshal(row.Metadata, &msg.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshal Metadata: %w", err)
+		}
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(message.Metadata)
+	}
+	m := &task.OutboxMessage{
+		ID:       row.ID,
+		Topic:    row.Topic,
+		TaskID:   row.TaskID,
+		Message:  msg,
+		Attempts: row.Attempts,
+	}
+	if row.CreatedAt.Valid {
+		m.CreatedAt = row.CreatedAt.Time
+	}
+	return m, nil
+}
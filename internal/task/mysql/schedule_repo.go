@@ -0,0 +1,165 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	stderrs "errors"
+	"fmt"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+	task "github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/internal/task/mysql/sqlc"
+)
+
+type scheduleRepo struct {
+	queries *sqlc.Queries
+}
+
+func NewScheduleRepo(db *sql.DB) task.ScheduleRepository {
+	return &scheduleRepo{queries: sqlc.New(db)}
+}
+
+func (r *scheduleRepo) q(ctx context.Context) *sqlc.Queries {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return r.queries.WithTx(tx)
+	}
+	return r.queries
+}
+
+func (r *scheduleRepo) CreateSchedule(ctx context.Context, s *task.Schedule) (*task.Schedule, error) {
+	template, err := toJSON(s.Template)
+	if err != nil {
+		return nil, fmt.Errorf("marshal Template: %w", err)
+	}
+	result, err := r.q(ctx).CreateSchedule(ctx, sqlc.CreateScheduleParams{
+		OfAccountID: s.OfAccountID,
+		CronExpr:    s.Cron,
+		StartAt:     toNullTime(s.StartAt),
+		Enabled:     s.Enabled,
+		NextRunAt:   toNullTime(s.NextRunAt),
+		LastRunAt:   toNullTime(s.LastRunAt),
+		NextTaskID:  s.NextTaskID,
+		Template:    template,
+	})
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	s.ID = uint64(id)
+	return s, nil
+}
+
+func (r *scheduleRepo) GetSchedule(ctx context.Context, id uint64) (*task.Schedule, error) {
+	s, err := r.q(ctx).GetScheduleById(ctx, id)
+	if err != nil {
+		if stderrs.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return toSchedule(s)
+}
+
+func (r *scheduleRepo) ListSchedulesOfAccount(
+	ctx context.Context,
+	ofAccountID uint64,
+	limit, offset uint32,
+) ([]*task.Schedule, error) {
+	rows, err := r.q(ctx).ListSchedulesByAccountId(ctx, sqlc.ListSchedulesByAccountIdParams{
+		OfAccountID: ofAccountID,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSchedules(rows)
+}
+
+func (r *scheduleRepo) ListDueSchedules(ctx context.Context, now time.Time, limit uint32) ([]*task.Schedule, error) {
+	rows, err := r.q(ctx).ListDueSchedules(ctx, sqlc.ListDueSchedulesParams{
+		NextRunAt: sql.NullTime{Time: now.UTC(), Valid: true},
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSchedules(rows)
+}
+
+func (r *scheduleRepo) UpdateSchedule(ctx context.Context, s *task.Schedule, prevNextRunAt *time.Time) (bool, error) {
+	q := r.q(ctx)
+	result, err := q.UpdateSchedule(ctx, sqlc.UpdateScheduleParams{
+		CronExpr:    s.Cron,
+		StartAt:     toNullTime(s.StartAt),
+		Enabled:     s.Enabled,
+		NextRunAt:   toNullTime(s.NextRunAt),
+		LastRunAt:   toNullTime(s.LastRunAt),
+		NextTaskID:  s.NextTaskID,
+		ID:          s.ID,
+		NextRunAt_2: toNullTime(prevNextRunAt),
+	})
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+
+	// MySQL reports changed rather than matched rows, so an update that
+	// rewrites identical values affects nothing. It still succeeded if the
+	// stored next run is the one we expected.
+	cur, err := q.GetScheduleById(ctx, s.ID)
+	if err != nil {
+		if stderrs.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	prev := toNullTime(prevNextRunAt)
+	return cur.NextRunAt.Valid == prev.Valid && cur.NextRunAt.Time.Equal(prev.Time), nil
+}
+
+func (r *scheduleRepo) DeleteSchedule(ctx context.Context, id uint64) error {
+	return r.q(ctx).DeleteSchedule(ctx, id)
+}
+
+func toSchedule(s sqlc.TaskSchedule) (*task.Schedule, error) {
+	template, err := fromJSON[task.CreateTaskParam](s.Template)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal Template: %w", err)
+	}
+	return &task.Schedule{
+		ID:          s.ID,
+		OfAccountID: s.OfAccountID,
+		Cron:        s.CronExpr,
+		StartAt:     fromNullTime(s.StartAt),
+		Enabled:     s.Enabled,
+		NextRunAt:   fromNullTime(s.NextRunAt),
+		LastRunAt:   fromNullTime(s.LastRunAt),
+		NextTaskID:  s.NextTaskID,
+		Template:    template,
+		CreatedAt:   s.CreatedAt.Time,
+		UpdatedAt:   s.UpdatedAt.Time,
+	}, nil
+}
+
+func toSchedules(rows []sqlc.TaskSchedule) ([]*task.Schedule, error) {
+	var res []*task.Schedule
+	for _, row := range rows {
+		s, err := toSchedule(row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
@@ -7,33 +7,110 @@ package sqlc
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 )
 
 type Task struct {
-	ID              uint64          `json:"id"`
-	OfAccountID     uint64          `json:"of_account_id"`
-	FileName        string          `json:"file_name"`
-	SourceUrl       string          `json:"source_url"`
-	SourceType      string          `json:"source_type"`
-	Headers         json.RawMessage `json:"headers"`
-	SourceAuth      json.RawMessage `json:"source_auth"`
-	StorageType     string          `json:"storage_type"`
-	StoragePath     string          `json:"storage_path"`
-	ChecksumType    sql.NullString  `json:"checksum_type"`
-	ChecksumValue   sql.NullString  `json:"checksum_value"`
-	Concurrency     sql.NullInt32   `json:"concurrency"`
-	MaxSpeed        sql.NullInt64   `json:"max_speed"`
-	MaxRetries      int32           `json:"max_retries"`
-	Timeout         sql.NullInt32   `json:"timeout"`
-	Status          string          `json:"status"`
-	Progress        sql.NullFloat64 `json:"progress"`
-	DownloadedBytes sql.NullInt64   `json:"downloaded_bytes"`
-	TotalBytes      sql.NullInt64   `json:"total_bytes"`
-	ErrorMessage    sql.NullString  `json:"error_message"`
-	Metadata        json.RawMessage `json:"metadata"`
-	CreatedAt       sql.NullTime    `json:"created_at"`
-	UpdatedAt       sql.NullTime    `json:"updated_at"`
-	CompletedAt     sql.NullTime    `json:"completed_at"`
-	LastAccessedAt  sql.NullTime    `json:"last_accessed_at"`
-	ExpirationDays  sql.NullInt32   `json:"expiration_days"`
+	ID                 uint64          `json:"id"`
+	OfAccountID        uint64          `json:"of_account_id"`
+	FileName           string          `json:"file_name"`
+	SourceUrl          string          `json:"source_url"`
+	SourceType         string          `json:"source_type"`
+	Headers            json.RawMessage `json:"headers"`
+	SourceAuth         json.RawMessage `json:"source_auth"`
+	StorageType        string          `json:"storage_type"`
+	StoragePath        string          `json:"storage_path"`
+	ChecksumType       sql.NullString  `json:"checksum_type"`
+	ChecksumValue      sql.NullString  `json:"checksum_value"`
+	Concurrency        sql.NullInt32   `json:"concurrency"`
+	MaxSpeed           sql.NullInt64   `json:"max_speed"`
+	MaxRetries         int32           `json:"max_retries"`
+	Timeout            sql.NullInt32   `json:"timeout"`
+	Status             string          `json:"status"`
+	Progress           sql.NullFloat64 `json:"progress"`
+	DownloadedBytes    sql.NullInt64   `json:"downloaded_bytes"`
+	TotalBytes         sql.NullInt64   `json:"total_bytes"`
+	ErrorMessage       sql.NullString  `json:"error_message"`
+	Metadata           json.RawMessage `json:"metadata"`
+	CreatedAt          sql.NullTime    `json:"created_at"`
+	UpdatedAt          sql.NullTime    `json:"updated_at"`
+	CompletedAt        sql.NullTime    `json:"completed_at"`
+	LastAccessedAt     sql.NullTime    `json:"last_accessed_at"`
+	ExpirationDays     sql.NullInt32   `json:"expiration_days"`
+	Priority           string          `json:"priority"`
+	ScheduleID         uint64          `json:"schedule_id"`
+	WorkspaceID        uint64          `json:"workspace_id"`
+	SourceEtag         string          `json:"source_etag"`
+	SourceLastModified string          `json:"source_last_modified"`
+	WorkerID           string          `json:"worker_id"`
+}
+
+type TaskAuditEvent struct {
+	ID             uint64         `json:"id"`
+	Time           time.Time      `json:"time"`
+	ActorAccountID uint64         `json:"actor_account_id"`
+	ActorApiKeyID  uint64         `json:"actor_api_key_id"`
+	Action         string         `json:"action"`
+	TaskID         uint64         `json:"task_id"`
+	OfAccountID    uint64         `json:"of_account_id"`
+	WorkspaceID    uint64         `json:"workspace_id"`
+	SourceIp       string         `json:"source_ip"`
+	Outcome        string         `json:"outcome"`
+	Message        sql.NullString `json:"message"`
+}
+
+type TaskOutbox struct {
+	ID            uint64          `json:"id"`
+	Topic         string          `json:"topic"`
+	TaskID        uint64          `json:"task_id"`
+	MessageUuid   string          `json:"message_uuid"`
+	Payload       string          `json:"payload"`
+	Metadata      json.RawMessage `json:"metadata"`
+	Attempts      uint32          `json:"attempts"`
+	NextAttemptAt sql.NullTime    `json:"next_attempt_at"`
+	LastError     sql.NullString  `json:"last_error"`
+	DeadAt        sql.NullTime    `json:"dead_at"`
+	CreatedAt     sql.NullTime    `json:"created_at"`
+}
+
+type TaskSchedule struct {
+	ID          uint64          `json:"id"`
+	OfAccountID uint64          `json:"of_account_id"`
+	CronExpr    string          `json:"cron_expr"`
+	StartAt     sql.NullTime    `json:"start_at"`
+	Enabled     bool            `json:"enabled"`
+	NextRunAt   sql.NullTime    `json:"next_run_at"`
+	LastRunAt   sql.NullTime    `json:"last_run_at"`
+	NextTaskID  uint64          `json:"next_task_id"`
+	Template    json.RawMessage `json:"template"`
+	CreatedAt   sql.NullTime    `json:"created_at"`
+	UpdatedAt   sql.NullTime    `json:"updated_at"`
+}
+
+type TaskWebhook struct {
+	ID          uint64          `json:"id"`
+	OfAccountID uint64          `json:"of_account_id"`
+	Url         string          `json:"url"`
+	Secret      string          `json:"secret"`
+	Events      json.RawMessage `json:"events"`
+	Enabled     bool            `json:"enabled"`
+	CreatedAt   sql.NullTime    `json:"created_at"`
+	UpdatedAt   sql.NullTime    `json:"updated_at"`
+}
+
+type TaskWebhookDelivery struct {
+	ID            uint64         `json:"id"`
+	WebhookID     uint64         `json:"webhook_id"`
+	EventID       string         `json:"event_id"`
+	Event         string         `json:"event"`
+	TaskID        uint64         `json:"task_id"`
+	Payload       string         `json:"payload"`
+	Status        string         `json:"status"`
+	Attempts      uint32         `json:"attempts"`
+	NextAttemptAt sql.NullTime   `json:"next_attempt_at"`
+	ResponseCode  int32          `json:"response_code"`
+	LastError     sql.NullString `json:"last_error"`
+	DeliveredAt   sql.NullTime   `json:"delivered_at"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
 }
@@ -2,8 +2,9 @@
 INSERT INTO tasks (of_account_id, file_name, source_url, source_type, source_auth, headers,
                    storage_type, storage_path, status,
                    checksum_type, checksum_value,
-                   concurrency, max_speed, max_retries, timeout, metadata, expiration_days)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+                   concurrency, max_speed, max_retries, timeout, metadata, expiration_days, priority,
+                   schedule_id, workspace_id, source_etag, source_last_modified)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetTaskById :one
 SELECT *
@@ -30,6 +31,26 @@ UPDATE tasks
 SET error_message = ?
 WHERE id = ?;
 
+-- name: UpdateTaskLastAccessedAt :exec
+UPDATE tasks
+SET last_accessed_at = ?
+WHERE id = ?;
+
+-- name: UpdateTaskExpirationDays :exec
+UPDATE tasks
+SET expiration_days = ?
+WHERE id = ?;
+
+-- name: UpdateTaskSourceValidators :exec
+UPDATE tasks
+SET source_etag = ?, source_last_modified = ?
+WHERE id = ?;
+
+-- name: UpdateTaskWorker :exec
+UPDATE tasks
+SET worker_id = ?
+WHERE id = ?;
+
 -- name: UpdateTaskDownloadedBytes :exec
 UPDATE tasks
 SET downloaded_bytes = ?
@@ -60,19 +81,179 @@ UPDATE tasks
 SET checksum_type = ?, checksum_value = ?
 WHERE id = ?;
 
--- name: ListTasks :many
+-- name: GetAccountUsage :one
+SELECT CAST(COALESCE(SUM(status IN ('PENDING', 'DOWNLOADING', 'STORING', 'PAUSED')), 0) AS SIGNED) AS active_tasks,
+       CAST(COALESCE(SUM(CASE WHEN status = 'COMPLETED' THEN total_bytes ELSE 0 END), 0) AS SIGNED) AS stored_bytes,
+       CAST(COALESCE(SUM(CASE WHEN status = 'COMPLETED' AND completed_at >= ? THEN total_bytes ELSE 0 END), 0) AS SIGNED) AS bytes_today
+FROM tasks
+WHERE of_account_id = ?;
+
+-- name: ListExpiredTasks :many
 SELECT *
 FROM tasks
-WHERE of_account_id = ?
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?;
+WHERE status = 'COMPLETED'
+  AND expiration_days > 0
+  AND last_accessed_at + INTERVAL expiration_days DAY <= sqlc.arg(expire_before)
+ORDER BY id
+LIMIT ?;
 
--- name: GetTaskCountByAccountId :one
+-- name: FindReusableTask :one
+SELECT *
+FROM tasks
+WHERE of_account_id = sqlc.arg(of_account_id)
+  AND status = 'COMPLETED'
+  AND storage_path <> ''
+  AND ((sqlc.arg(checksum_value) <> '' AND LOWER(checksum_value) = sqlc.arg(checksum_value)
+        AND (sqlc.arg(checksum_type) = '' OR REPLACE(LOWER(checksum_type), '-', '') = sqlc.arg(checksum_type)))
+    OR (sqlc.arg(checksum_value) = '' AND source_url = sqlc.arg(source_url)
+        AND (source_etag <> '' OR source_last_modified <> '')))
+ORDER BY completed_at DESC, id DESC
+LIMIT 1;
+
+-- name: CountTasksByStoragePath :one
 SELECT COUNT(*)
 FROM tasks
-WHERE of_account_id = ?;
+WHERE storage_path = ?
+  AND id <> ?
+  AND status <> 'EXPIRED';
 
 -- name: DeleteTask :exec
 DELETE
 FROM tasks
 WHERE id = ?;
+
+-- name: CreateSchedule :execresult
+INSERT INTO task_schedules (of_account_id, cron_expr, start_at, enabled, next_run_at, last_run_at,
+                            next_task_id, template)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetScheduleById :one
+SELECT *
+FROM task_schedules
+WHERE id = ?;
+
+-- name: ListSchedulesByAccountId :many
+SELECT *
+FROM task_schedules
+WHERE of_account_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?;
+
+-- name: ListDueSchedules :many
+SELECT *
+FROM task_schedules
+WHERE enabled = TRUE
+  AND next_run_at <= ?
+ORDER BY next_run_at
+LIMIT ?;
+
+-- name: UpdateSchedule :execresult
+UPDATE task_schedules
+SET cron_expr = ?, start_at = ?, enabled = ?, next_run_at = ?, last_run_at = ?, next_task_id = ?
+WHERE id = ?
+  AND next_run_at <=> ?;
+
+-- name: DeleteSchedule :exec
+DELETE
+FROM task_schedules
+WHERE id = ?;
+
+-- name: CreateWebhook :execresult
+INSERT INTO task_webhooks (of_account_id, url, secret, events, enabled)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetWebhookById :one
+SELECT *
+FROM task_webhooks
+WHERE id = ?;
+
+-- name: ListWebhooksByAccountId :many
+SELECT *
+FROM task_webhooks
+WHERE of_account_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?;
+
+-- name: ListEnabledWebhooksByAccountId :many
+SELECT *
+FROM task_webhooks
+WHERE of_account_id = ?
+  AND enabled = TRUE;
+
+-- name: UpdateWebhook :exec
+UPDATE task_webhooks
+SET url = ?, secret = ?, events = ?, enabled = ?
+WHERE id = ?;
+
+-- name: DeleteWebhook :exec
+DELETE
+FROM task_webhooks
+WHERE id = ?;
+
+-- name: DeleteWebhookDeliveries :exec
+DELETE
+FROM task_webhook_deliveries
+WHERE webhook_id = ?;
+
+-- name: CreateWebhookDelivery :execresult
+INSERT IGNORE INTO task_webhook_deliveries (webhook_id, event_id, event, task_id, payload, status, attempts,
+                                            next_attempt_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM task_webhook_deliveries
+WHERE webhook_id = ?
+ORDER BY id DESC
+LIMIT ? OFFSET ?;
+
+-- name: ListDueWebhookDeliveries :many
+SELECT *
+FROM task_webhook_deliveries
+WHERE status = 'PENDING'
+  AND next_attempt_at <= ?
+ORDER BY next_attempt_at
+LIMIT ?;
+
+-- name: UpdateWebhookDelivery :execresult
+UPDATE task_webhook_deliveries
+SET status = ?, attempts = ?, next_attempt_at = ?, response_code = ?, last_error = ?, delivered_at = ?
+WHERE id = ?
+  AND next_attempt_at <=> ?;
+
+-- name: CreateAuditEvent :execresult
+INSERT INTO task_audit_events (time, actor_account_id, actor_api_key_id, action, task_id, of_account_id,
+                               workspace_id, source_ip, outcome, message)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: CreateOutboxMessage :exec
+INSERT INTO task_outbox (topic, task_id, message_uuid, payload, metadata)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: ListOutboxMessages :many
+SELECT *
+FROM task_outbox o
+WHERE o.dead_at IS NULL
+  AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= sqlc.arg(now))
+  AND (o.task_id = 0 OR NOT EXISTS (SELECT 1
+                                    FROM task_outbox b
+                                    WHERE b.task_id = o.task_id
+                                      AND b.dead_at IS NULL
+                                      AND b.next_attempt_at > sqlc.arg(now)))
+ORDER BY o.id
+LIMIT ?;
+
+-- name: RetryOutboxMessage :exec
+UPDATE task_outbox
+SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?
+WHERE id = ?;
+
+-- name: DeadLetterOutboxMessage :exec
+UPDATE task_outbox
+SET dead_at = ?, last_error = ?
+WHERE id = ?;
+
+-- name: DeleteOutboxMessage :exec
+DELETE
+FROM task_outbox
+WHERE id = ?;
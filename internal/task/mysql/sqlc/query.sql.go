@@ -9,34 +9,149 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 )
 
+const countTasksByStoragePath = `-- name: CountTasksByStoragePath :one
+SELECT COUNT(*)
+FROM tasks
+WHERE storage_path = ?
+  AND id <> ?
+  AND status <> 'EXPIRED'
+`
+
+type CountTasksByStoragePathParams struct {
+	StoragePath string `json:"storage_path"`
+	ID          uint64 `json:"id"`
+}
+
+func (q *Queries) CountTasksByStoragePath(ctx context.Context, arg CountTasksByStoragePathParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTasksByStoragePath, arg.StoragePath, arg.ID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAuditEvent = `-- name: CreateAuditEvent :execresult
+INSERT INTO task_audit_events (time, actor_account_id, actor_api_key_id, action, task_id, of_account_id,
+                               workspace_id, source_ip, outcome, message)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateAuditEventParams struct {
+	Time           time.Time      `json:"time"`
+	ActorAccountID uint64         `json:"actor_account_id"`
+	ActorApiKeyID  uint64         `json:"actor_api_key_id"`
+	Action         string         `json:"action"`
+	TaskID         uint64         `json:"task_id"`
+	OfAccountID    uint64         `json:"of_account_id"`
+	WorkspaceID    uint64         `json:"workspace_id"`
+	SourceIp       string         `json:"source_ip"`
+	Outcome        string         `json:"outcome"`
+	Message        sql.NullString `json:"message"`
+}
+
+func (q *Queries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createAuditEvent,
+		arg.Time,
+		arg.ActorAccountID,
+		arg.ActorApiKeyID,
+		arg.Action,
+		arg.TaskID,
+		arg.OfAccountID,
+		arg.WorkspaceID,
+		arg.SourceIp,
+		arg.Outcome,
+		arg.Message,
+	)
+}
+
+const createOutboxMessage = `-- name: CreateOutboxMessage :exec
+INSERT INTO task_outbox (topic, task_id, message_uuid, payload, metadata)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateOutboxMessageParams struct {
+	Topic       string          `json:"topic"`
+	TaskID      uint64          `json:"task_id"`
+	MessageUuid string          `json:"message_uuid"`
+	Payload     string          `json:"payload"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+func (q *Queries) CreateOutboxMessage(ctx context.Context, arg CreateOutboxMessageParams) error {
+	_, err := q.db.ExecContext(ctx, createOutboxMessage,
+		arg.Topic,
+		arg.TaskID,
+		arg.MessageUuid,
+		arg.Payload,
+		arg.Metadata,
+	)
+	return err
+}
+
+const createSchedule = `-- name: CreateSchedule :execresult
+INSERT INTO task_schedules (of_account_id, cron_expr, start_at, enabled, next_run_at, last_run_at,
+                            next_task_id, template)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateScheduleParams struct {
+	OfAccountID uint64          `json:"of_account_id"`
+	CronExpr    string          `json:"cron_expr"`
+	StartAt     sql.NullTime    `json:"start_at"`
+	Enabled     bool            `json:"enabled"`
+	NextRunAt   sql.NullTime    `json:"next_run_at"`
+	LastRunAt   sql.NullTime    `json:"last_run_at"`
+	NextTaskID  uint64          `json:"next_task_id"`
+	Template    json.RawMessage `json:"template"`
+}
+
+func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createSchedule,
+		arg.OfAccountID,
+		arg.CronExpr,
+		arg.StartAt,
+		arg.Enabled,
+		arg.NextRunAt,
+		arg.LastRunAt,
+		arg.NextTaskID,
+		arg.Template,
+	)
+}
+
 const createTask = `-- name: CreateTask :execresult
 INSERT INTO tasks (of_account_id, file_name, source_url, source_type, source_auth, headers,
                    storage_type, storage_path, status,
                    checksum_type, checksum_value,
-                   concurrency, max_speed, max_retries, timeout, metadata, expiration_days)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                   concurrency, max_speed, max_retries, timeout, metadata, expiration_days, priority,
+                   schedule_id, workspace_id, source_etag, source_last_modified)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateTaskParams struct {
-	OfAccountID    uint64          `json:"of_account_id"`
-	FileName       string          `json:"file_name"`
-	SourceUrl      string          `json:"source_url"`
-	SourceType     string          `json:"source_type"`
-	SourceAuth     json.RawMessage `json:"source_auth"`
-	Headers        json.RawMessage `json:"headers"`
-	StorageType    string          `json:"storage_type"`
-	StoragePath    string          `json:"storage_path"`
-	Status         string          `json:"status"`
-	ChecksumType   sql.NullString  `json:"checksum_type"`
-	ChecksumValue  sql.NullString  `json:"checksum_value"`
-	Concurrency    sql.NullInt32   `json:"concurrency"`
-	MaxSpeed       sql.NullInt64   `json:"max_speed"`
-	MaxRetries     int32           `json:"max_retries"`
-	Timeout        sql.NullInt32   `json:"timeout"`
-	Metadata       json.RawMessage `json:"metadata"`
-	ExpirationDays sql.NullInt32   `json:"expiration_days"`
+	OfAccountID        uint64          `json:"of_account_id"`
+	FileName           string          `json:"file_name"`
+	SourceUrl          string          `json:"source_url"`
+	SourceType         string          `json:"source_type"`
+	SourceAuth         json.RawMessage `json:"source_auth"`
+	Headers            json.RawMessage `json:"headers"`
+	StorageType        string          `json:"storage_type"`
+	StoragePath        string          `json:"storage_path"`
+	Status             string          `json:"status"`
+	ChecksumType       sql.NullString  `json:"checksum_type"`
+	ChecksumValue      sql.NullString  `json:"checksum_value"`
+	Concurrency        sql.NullInt32   `json:"concurrency"`
+	MaxSpeed           sql.NullInt64   `json:"max_speed"`
+	MaxRetries         int32           `json:"max_retries"`
+	Timeout            sql.NullInt32   `json:"timeout"`
+	Metadata           json.RawMessage `json:"metadata"`
+	ExpirationDays     sql.NullInt32   `json:"expiration_days"`
+	Priority           string          `json:"priority"`
+	ScheduleID         uint64          `json:"schedule_id"`
+	WorkspaceID        uint64          `json:"workspace_id"`
+	SourceEtag         string          `json:"source_etag"`
+	SourceLastModified string          `json:"source_last_modified"`
 }
 
 func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (sql.Result, error) {
@@ -58,9 +173,106 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (sql.Res
 		arg.Timeout,
 		arg.Metadata,
 		arg.ExpirationDays,
+		arg.Priority,
+		arg.ScheduleID,
+		arg.WorkspaceID,
+		arg.SourceEtag,
+		arg.SourceLastModified,
 	)
 }
 
+const createWebhook = `-- name: CreateWebhook :execresult
+INSERT INTO task_webhooks (of_account_id, url, secret, events, enabled)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateWebhookParams struct {
+	OfAccountID uint64          `json:"of_account_id"`
+	Url         string          `json:"url"`
+	Secret      string          `json:"secret"`
+	Events      json.RawMessage `json:"events"`
+	Enabled     bool            `json:"enabled"`
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createWebhook,
+		arg.OfAccountID,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.Enabled,
+	)
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :execresult
+INSERT IGNORE INTO task_webhook_deliveries (webhook_id, event_id, event, task_id, payload, status, attempts,
+                                            next_attempt_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID     uint64       `json:"webhook_id"`
+	EventID       string       `json:"event_id"`
+	Event         string       `json:"event"`
+	TaskID        uint64       `json:"task_id"`
+	Payload       string       `json:"payload"`
+	Status        string       `json:"status"`
+	Attempts      uint32       `json:"attempts"`
+	NextAttemptAt sql.NullTime `json:"next_attempt_at"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.WebhookID,
+		arg.EventID,
+		arg.Event,
+		arg.TaskID,
+		arg.Payload,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+	)
+}
+
+const deadLetterOutboxMessage = `-- name: DeadLetterOutboxMessage :exec
+UPDATE task_outbox
+SET dead_at = ?, last_error = ?
+WHERE id = ?
+`
+
+type DeadLetterOutboxMessageParams struct {
+	DeadAt    sql.NullTime   `json:"dead_at"`
+	LastError sql.NullString `json:"last_error"`
+	ID        uint64         `json:"id"`
+}
+
+func (q *Queries) DeadLetterOutboxMessage(ctx context.Context, arg DeadLetterOutboxMessageParams) error {
+	_, err := q.db.ExecContext(ctx, deadLetterOutboxMessage, arg.DeadAt, arg.LastError, arg.ID)
+	return err
+}
+
+const deleteOutboxMessage = `-- name: DeleteOutboxMessage :exec
+DELETE
+FROM task_outbox
+WHERE id = ?
+`
+
+func (q *Queries) DeleteOutboxMessage(ctx context.Context, id uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteOutboxMessage, id)
+	return err
+}
+
+const deleteSchedule = `-- name: DeleteSchedule :exec
+DELETE
+FROM task_schedules
+WHERE id = ?
+`
+
+func (q *Queries) DeleteSchedule(ctx context.Context, id uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteSchedule, id)
+	return err
+}
+
 const deleteTask = `-- name: DeleteTask :exec
 DELETE
 FROM tasks
@@ -72,8 +284,150 @@ func (q *Queries) DeleteTask(ctx context.Context, id uint64) error {
 	return err
 }
 
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE
+FROM task_webhooks
+WHERE id = ?
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhook, id)
+	return err
+}
+
+const deleteWebhookDeliveries = `-- name: DeleteWebhookDeliveries :exec
+DELETE
+FROM task_webhook_deliveries
+WHERE webhook_id = ?
+`
+
+func (q *Queries) DeleteWebhookDeliveries(ctx context.Context, webhookID uint64) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookDeliveries, webhookID)
+	return err
+}
+
+const findReusableTask = `-- name: FindReusableTask :one
+SELECT id, of_account_id, file_name, source_url, source_type, headers, source_auth, storage_type, storage_path, checksum_type, checksum_value, concurrency, max_speed, max_retries, timeout, status, progress, downloaded_bytes, total_bytes, error_message, metadata, created_at, updated_at, completed_at, last_accessed_at, expiration_days, priority, schedule_id, workspace_id, source_etag, source_last_modified, worker_id
+FROM tasks
+WHERE of_account_id = ?
+  AND status = 'COMPLETED'
+  AND storage_path <> ''
+  AND ((? <> '' AND LOWER(checksum_value) = ?
+        AND (? = '' OR REPLACE(LOWER(checksum_type), '-', '') = ?))
+    OR (? = '' AND source_url = ?
+        AND (source_etag <> '' OR source_last_modified <> '')))
+ORDER BY completed_at DESC, id DESC
+LIMIT 1
+`
+
+type FindReusableTaskParams struct {
+	OfAccountID   uint64 `json:"of_account_id"`
+	ChecksumValue string `json:"checksum_value"`
+	ChecksumType  string `json:"checksum_type"`
+	SourceUrl     string `json:"source_url"`
+}
+
+func (q *Queries) FindReusableTask(ctx context.Context, arg FindReusableTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, findReusableTask,
+		arg.OfAccountID,
+		arg.ChecksumValue,
+		arg.ChecksumValue,
+		arg.ChecksumType,
+		arg.ChecksumType,
+		arg.ChecksumValue,
+		arg.SourceUrl,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.OfAccountID,
+		&i.FileName,
+		&i.SourceUrl,
+		&i.SourceType,
+		&i.Headers,
+		&i.SourceAuth,
+		&i.StorageType,
+		&i.StoragePath,
+		&i.ChecksumType,
+		&i.ChecksumValue,
+		&i.Concurrency,
+		&i.MaxSpeed,
+		&i.MaxRetries,
+		&i.Timeout,
+		&i.Status,
+		&i.Progress,
+		&i.DownloadedBytes,
+		&i.TotalBytes,
+		&i.ErrorMessage,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+		&i.LastAccessedAt,
+		&i.ExpirationDays,
+		&i.Priority,
+		&i.ScheduleID,
+		&i.WorkspaceID,
+		&i.SourceEtag,
+		&i.SourceLastModified,
+		&i.WorkerID,
+	)
+	return i, err
+}
+
+const getAccountUsage = `-- name: GetAccountUsage :one
+SELECT CAST(COALESCE(SUM(status IN ('PENDING', 'DOWNLOADING', 'STORING', 'PAUSED')), 0) AS SIGNED) AS active_tasks,
+       CAST(COALESCE(SUM(CASE WHEN status = 'COMPLETED' THEN total_bytes ELSE 0 END), 0) AS SIGNED) AS stored_bytes,
+       CAST(COALESCE(SUM(CASE WHEN status = 'COMPLETED' AND completed_at >= ? THEN total_bytes ELSE 0 END), 0) AS SIGNED) AS bytes_today
+FROM tasks
+WHERE of_account_id = ?
+`
+
+type GetAccountUsageParams struct {
+	CompletedAt sql.NullTime `json:"completed_at"`
+	OfAccountID uint64       `json:"of_account_id"`
+}
+
+type GetAccountUsageRow struct {
+	ActiveTasks int64 `json:"active_tasks"`
+	StoredBytes int64 `json:"stored_bytes"`
+	BytesToday  int64 `json:"bytes_today"`
+}
+
+func (q *Queries) GetAccountUsage(ctx context.Context, arg GetAccountUsageParams) (GetAccountUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountUsage, arg.CompletedAt, arg.OfAccountID)
+	var i GetAccountUsageRow
+	err := row.Scan(&i.ActiveTasks, &i.StoredBytes, &i.BytesToday)
+	return i, err
+}
+
+const getScheduleById = `-- name: GetScheduleById :one
+SELECT id, of_account_id, cron_expr, start_at, enabled, next_run_at, last_run_at, next_task_id, template, created_at, updated_at
+FROM task_schedules
+WHERE id = ?
+`
+
+func (q *Queries) GetScheduleById(ctx context.Context, id uint64) (TaskSchedule, error) {
+	row := q.db.QueryRowContext(ctx, getScheduleById, id)
+	var i TaskSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.OfAccountID,
+		&i.CronExpr,
+		&i.StartAt,
+		&i.Enabled,
+		&i.NextRunAt,
+		&i.LastRunAt,
+		&i.NextTaskID,
+		&i.Template,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getTaskById = `-- name: GetTaskById :one
-SELECT id, of_account_id, file_name, source_url, source_type, headers, source_auth, storage_type, storage_path, checksum_type, checksum_value, concurrency, max_speed, max_retries, timeout, status, progress, downloaded_bytes, total_bytes, error_message, metadata, created_at, updated_at, completed_at, last_accessed_at, expiration_days
+SELECT id, of_account_id, file_name, source_url, source_type, headers, source_auth, storage_type, storage_path, checksum_type, checksum_value, concurrency, max_speed, max_retries, timeout, status, progress, downloaded_bytes, total_bytes, error_message, metadata, created_at, updated_at, completed_at, last_accessed_at, expiration_days, priority, schedule_id, workspace_id, source_etag, source_last_modified, worker_id
 FROM tasks
 WHERE id = ?
 `
@@ -108,39 +462,195 @@ func (q *Queries) GetTaskById(ctx context.Context, id uint64) (Task, error) {
 		&i.CompletedAt,
 		&i.LastAccessedAt,
 		&i.ExpirationDays,
+		&i.Priority,
+		&i.ScheduleID,
+		&i.WorkspaceID,
+		&i.SourceEtag,
+		&i.SourceLastModified,
+		&i.WorkerID,
 	)
 	return i, err
 }
 
-const getTaskCountByAccountId = `-- name: GetTaskCountByAccountId :one
-SELECT COUNT(*)
-FROM tasks
+const getWebhookById = `-- name: GetWebhookById :one
+SELECT id, of_account_id, url, secret, events, enabled, created_at, updated_at
+FROM task_webhooks
+WHERE id = ?
+`
+
+func (q *Queries) GetWebhookById(ctx context.Context, id uint64) (TaskWebhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookById, id)
+	var i TaskWebhook
+	err := row.Scan(
+		&i.ID,
+		&i.OfAccountID,
+		&i.Url,
+		&i.Secret,
+		&i.Events,
+		&i.Enabled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listDueSchedules = `-- name: ListDueSchedules :many
+SELECT id, of_account_id, cron_expr, start_at, enabled, next_run_at, last_run_at, next_task_id, template, created_at, updated_at
+FROM task_schedules
+WHERE enabled = TRUE
+  AND next_run_at <= ?
+ORDER BY next_run_at
+LIMIT ?
+`
+
+type ListDueSchedulesParams struct {
+	NextRunAt sql.NullTime `json:"next_run_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) ListDueSchedules(ctx context.Context, arg ListDueSchedulesParams) ([]TaskSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, listDueSchedules, arg.NextRunAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskSchedule
+	for rows.Next() {
+		var i TaskSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.OfAccountID,
+			&i.CronExpr,
+			&i.StartAt,
+			&i.Enabled,
+			&i.NextRunAt,
+			&i.LastRunAt,
+			&i.NextTaskID,
+			&i.Template,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueWebhookDeliveries = `-- name: ListDueWebhookDeliveries :many
+SELECT id, webhook_id, event_id, event, task_id, payload, status, attempts, next_attempt_at, response_code, last_error, delivered_at, created_at, updated_at
+FROM task_webhook_deliveries
+WHERE status = 'PENDING'
+  AND next_attempt_at <= ?
+ORDER BY next_attempt_at
+LIMIT ?
+`
+
+type ListDueWebhookDeliveriesParams struct {
+	NextAttemptAt sql.NullTime `json:"next_attempt_at"`
+	Limit         int32        `json:"limit"`
+}
+
+func (q *Queries) ListDueWebhookDeliveries(ctx context.Context, arg ListDueWebhookDeliveriesParams) ([]TaskWebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listDueWebhookDeliveries, arg.NextAttemptAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskWebhookDelivery
+	for rows.Next() {
+		var i TaskWebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.Event,
+			&i.TaskID,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseCode,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledWebhooksByAccountId = `-- name: ListEnabledWebhooksByAccountId :many
+SELECT id, of_account_id, url, secret, events, enabled, created_at, updated_at
+FROM task_webhooks
 WHERE of_account_id = ?
+  AND enabled = TRUE
 `
 
-func (q *Queries) GetTaskCountByAccountId(ctx context.Context, ofAccountID uint64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getTaskCountByAccountId, ofAccountID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) ListEnabledWebhooksByAccountId(ctx context.Context, ofAccountID uint64) ([]TaskWebhook, error) {
+	rows, err := q.db.QueryContext(ctx, listEnabledWebhooksByAccountId, ofAccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskWebhook
+	for rows.Next() {
+		var i TaskWebhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OfAccountID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Enabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const listTasks = `-- name: ListTasks :many
-SELECT id, of_account_id, file_name, source_url, source_type, headers, source_auth, storage_type, storage_path, checksum_type, checksum_value, concurrency, max_speed, max_retries, timeout, status, progress, downloaded_bytes, total_bytes, error_message, metadata, created_at, updated_at, completed_at, last_accessed_at, expiration_days
+const listExpiredTasks = `-- name: ListExpiredTasks :many
+SELECT id, of_account_id, file_name, source_url, source_type, headers, source_auth, storage_type, storage_path, checksum_type, checksum_value, concurrency, max_speed, max_retries, timeout, status, progress, downloaded_bytes, total_bytes, error_message, metadata, created_at, updated_at, completed_at, last_accessed_at, expiration_days, priority, schedule_id, workspace_id, source_etag, source_last_modified, worker_id
 FROM tasks
-WHERE of_account_id = ?
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
+WHERE status = 'COMPLETED'
+  AND expiration_days > 0
+  AND last_accessed_at + INTERVAL expiration_days DAY <= ?
+ORDER BY id
+LIMIT ?
 `
 
-type ListTasksParams struct {
-	OfAccountID uint64 `json:"of_account_id"`
-	Limit       int32  `json:"limit"`
-	Offset      int32  `json:"offset"`
+type ListExpiredTasksParams struct {
+	ExpireBefore time.Time `json:"expire_before"`
+	Limit        int32     `json:"limit"`
 }
 
-func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, listTasks, arg.OfAccountID, arg.Limit, arg.Offset)
+func (q *Queries) ListExpiredTasks(ctx context.Context, arg ListExpiredTasksParams) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredTasks, arg.ExpireBefore, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +685,167 @@ func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, e
 			&i.CompletedAt,
 			&i.LastAccessedAt,
 			&i.ExpirationDays,
+			&i.Priority,
+			&i.ScheduleID,
+			&i.WorkspaceID,
+			&i.SourceEtag,
+			&i.SourceLastModified,
+			&i.WorkerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutboxMessages = `-- name: ListOutboxMessages :many
+SELECT id, topic, task_id, message_uuid, payload, metadata, attempts, next_attempt_at, last_error, dead_at, created_at
+FROM task_outbox o
+WHERE o.dead_at IS NULL
+  AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= ?)
+  AND (o.task_id = 0 OR NOT EXISTS (SELECT 1
+                                    FROM task_outbox b
+                                    WHERE b.task_id = o.task_id
+                                      AND b.dead_at IS NULL
+                                      AND b.next_attempt_at > ?))
+ORDER BY o.id
+LIMIT ?
+`
+
+type ListOutboxMessagesParams struct {
+	Now   sql.NullTime `json:"now"`
+	Limit int32        `json:"limit"`
+}
+
+func (q *Queries) ListOutboxMessages(ctx context.Context, arg ListOutboxMessagesParams) ([]TaskOutbox, error) {
+	rows, err := q.db.QueryContext(ctx, listOutboxMessages, arg.Now, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskOutbox
+	for rows.Next() {
+		var i TaskOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Topic,
+			&i.TaskID,
+			&i.MessageUuid,
+			&i.Payload,
+			&i.Metadata,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastError,
+			&i.DeadAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSchedulesByAccountId = `-- name: ListSchedulesByAccountId :many
+SELECT id, of_account_id, cron_expr, start_at, enabled, next_run_at, last_run_at, next_task_id, template, created_at, updated_at
+FROM task_schedules
+WHERE of_account_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type ListSchedulesByAccountIdParams struct {
+	OfAccountID uint64 `json:"of_account_id"`
+	Limit       int32  `json:"limit"`
+	Offset      int32  `json:"offset"`
+}
+
+func (q *Queries) ListSchedulesByAccountId(ctx context.Context, arg ListSchedulesByAccountIdParams) ([]TaskSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, listSchedulesByAccountId, arg.OfAccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskSchedule
+	for rows.Next() {
+		var i TaskSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.OfAccountID,
+			&i.CronExpr,
+			&i.StartAt,
+			&i.Enabled,
+			&i.NextRunAt,
+			&i.LastRunAt,
+			&i.NextTaskID,
+			&i.Template,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT id, webhook_id, event_id, event, task_id, payload, status, attempts, next_attempt_at, response_code, last_error, delivered_at, created_at, updated_at
+FROM task_webhook_deliveries
+WHERE webhook_id = ?
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+type ListWebhookDeliveriesParams struct {
+	WebhookID uint64 `json:"webhook_id"`
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+}
+
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]TaskWebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.WebhookID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskWebhookDelivery
+	for rows.Next() {
+		var i TaskWebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.Event,
+			&i.TaskID,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseCode,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -189,6 +860,69 @@ func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Task, e
 	return items, nil
 }
 
+const listWebhooksByAccountId = `-- name: ListWebhooksByAccountId :many
+SELECT id, of_account_id, url, secret, events, enabled, created_at, updated_at
+FROM task_webhooks
+WHERE of_account_id = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type ListWebhooksByAccountIdParams struct {
+	OfAccountID uint64 `json:"of_account_id"`
+	Limit       int32  `json:"limit"`
+	Offset      int32  `json:"offset"`
+}
+
+func (q *Queries) ListWebhooksByAccountId(ctx context.Context, arg ListWebhooksByAccountIdParams) ([]TaskWebhook, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhooksByAccountId, arg.OfAccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TaskWebhook
+	for rows.Next() {
+		var i TaskWebhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OfAccountID,
+			&i.Url,
+			&i.Secret,
+			&i.Events,
+			&i.Enabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const retryOutboxMessage = `-- name: RetryOutboxMessage :exec
+UPDATE task_outbox
+SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?
+WHERE id = ?
+`
+
+type RetryOutboxMessageParams struct {
+	NextAttemptAt sql.NullTime   `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	ID            uint64         `json:"id"`
+}
+
+func (q *Queries) RetryOutboxMessage(ctx context.Context, arg RetryOutboxMessageParams) error {
+	_, err := q.db.ExecContext(ctx, retryOutboxMessage, arg.NextAttemptAt, arg.LastError, arg.ID)
+	return err
+}
+
 const updateFileChecksum = `-- name: UpdateFileChecksum :exec
 UPDATE tasks
 SET checksum_type = ?, checksum_value = ?
@@ -222,6 +956,37 @@ func (q *Queries) UpdateFileName(ctx context.Context, arg UpdateFileNameParams)
 	return err
 }
 
+const updateSchedule = `-- name: UpdateSchedule :execresult
+UPDATE task_schedules
+SET cron_expr = ?, start_at = ?, enabled = ?, next_run_at = ?, last_run_at = ?, next_task_id = ?
+WHERE id = ?
+  AND next_run_at <=> ?
+`
+
+type UpdateScheduleParams struct {
+	CronExpr    string       `json:"cron_expr"`
+	StartAt     sql.NullTime `json:"start_at"`
+	Enabled     bool         `json:"enabled"`
+	NextRunAt   sql.NullTime `json:"next_run_at"`
+	LastRunAt   sql.NullTime `json:"last_run_at"`
+	NextTaskID  uint64       `json:"next_task_id"`
+	ID          uint64       `json:"id"`
+	NextRunAt_2 sql.NullTime `json:"next_run_at_2"`
+}
+
+func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateSchedule,
+		arg.CronExpr,
+		arg.StartAt,
+		arg.Enabled,
+		arg.NextRunAt,
+		arg.LastRunAt,
+		arg.NextTaskID,
+		arg.ID,
+		arg.NextRunAt_2,
+	)
+}
+
 const updateStorageInfo = `-- name: UpdateStorageInfo :exec
 UPDATE tasks
 SET storage_type = ?, storage_path = ?
@@ -287,6 +1052,38 @@ func (q *Queries) UpdateTaskError(ctx context.Context, arg UpdateTaskErrorParams
 	return err
 }
 
+const updateTaskExpirationDays = `-- name: UpdateTaskExpirationDays :exec
+UPDATE tasks
+SET expiration_days = ?
+WHERE id = ?
+`
+
+type UpdateTaskExpirationDaysParams struct {
+	ExpirationDays sql.NullInt32 `json:"expiration_days"`
+	ID             uint64        `json:"id"`
+}
+
+func (q *Queries) UpdateTaskExpirationDays(ctx context.Context, arg UpdateTaskExpirationDaysParams) error {
+	_, err := q.db.ExecContext(ctx, updateTaskExpirationDays, arg.ExpirationDays, arg.ID)
+	return err
+}
+
+const updateTaskLastAccessedAt = `-- name: UpdateTaskLastAccessedAt :exec
+UPDATE tasks
+SET last_accessed_at = ?
+WHERE id = ?
+`
+
+type UpdateTaskLastAccessedAtParams struct {
+	LastAccessedAt sql.NullTime `json:"last_accessed_at"`
+	ID             uint64       `json:"id"`
+}
+
+func (q *Queries) UpdateTaskLastAccessedAt(ctx context.Context, arg UpdateTaskLastAccessedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateTaskLastAccessedAt, arg.LastAccessedAt, arg.ID)
+	return err
+}
+
 const updateTaskMetadata = `-- name: UpdateTaskMetadata :exec
 UPDATE tasks
 SET metadata = ?
@@ -319,6 +1116,23 @@ func (q *Queries) UpdateTaskProgress(ctx context.Context, arg UpdateTaskProgress
 	return err
 }
 
+const updateTaskSourceValidators = `-- name: UpdateTaskSourceValidators :exec
+UPDATE tasks
+SET source_etag = ?, source_last_modified = ?
+WHERE id = ?
+`
+
+type UpdateTaskSourceValidatorsParams struct {
+	SourceEtag         string `json:"source_etag"`
+	SourceLastModified string `json:"source_last_modified"`
+	ID                 uint64 `json:"id"`
+}
+
+func (q *Queries) UpdateTaskSourceValidators(ctx context.Context, arg UpdateTaskSourceValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, updateTaskSourceValidators, arg.SourceEtag, arg.SourceLastModified, arg.ID)
+	return err
+}
+
 const updateTaskStatus = `-- name: UpdateTaskStatus :exec
 UPDATE tasks
 SET status = ?
@@ -350,3 +1164,75 @@ func (q *Queries) UpdateTaskTotalBytes(ctx context.Context, arg UpdateTaskTotalB
 	_, err := q.db.ExecContext(ctx, updateTaskTotalBytes, arg.TotalBytes, arg.ID)
 	return err
 }
+
+const updateTaskWorker = `-- name: UpdateTaskWorker :exec
+UPDATE tasks
+SET worker_id = ?
+WHERE id = ?
+`
+
+type UpdateTaskWorkerParams struct {
+	WorkerID string `json:"worker_id"`
+	ID       uint64 `json:"id"`
+}
+
+func (q *Queries) UpdateTaskWorker(ctx context.Context, arg UpdateTaskWorkerParams) error {
+	_, err := q.db.ExecContext(ctx, updateTaskWorker, arg.WorkerID, arg.ID)
+	return err
+}
+
+const updateWebhook = `-- name: UpdateWebhook :exec
+UPDATE task_webhooks
+SET url = ?, secret = ?, events = ?, enabled = ?
+WHERE id = ?
+`
+
+type UpdateWebhookParams struct {
+	Url     string          `json:"url"`
+	Secret  string          `json:"secret"`
+	Events  json.RawMessage `json:"events"`
+	Enabled bool            `json:"enabled"`
+	ID      uint64          `json:"id"`
+}
+
+func (q *Queries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhook,
+		arg.Url,
+		arg.Secret,
+		arg.Events,
+		arg.Enabled,
+		arg.ID,
+	)
+	return err
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :execresult
+UPDATE task_webhook_deliveries
+SET status = ?, attempts = ?, next_attempt_at = ?, response_code = ?, last_error = ?, delivered_at = ?
+WHERE id = ?
+  AND next_attempt_at <=> ?
+`
+
+type UpdateWebhookDeliveryParams struct {
+	Status          string         `json:"status"`
+	Attempts        uint32         `json:"attempts"`
+	NextAttemptAt   sql.NullTime   `json:"next_attempt_at"`
+	ResponseCode    int32          `json:"response_code"`
+	LastError       sql.NullString `json:"last_error"`
+	DeliveredAt     sql.NullTime   `json:"delivered_at"`
+	ID              uint64         `json:"id"`
+	NextAttemptAt_2 sql.NullTime   `json:"next_attempt_at_2"`
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateWebhookDelivery,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.ResponseCode,
+		arg.LastError,
+		arg.DeliveredAt,
+		arg.ID,
+		arg.NextAttemptAt_2,
+	)
+}
@@ -26,8 +26,108 @@ CREATE TABLE
         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
         updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
         completed_at DATETIME,
-        last_accessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        last_accessed_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP,
         expiration_days INT UNSIGNED DEFAULT 30, -- days
+        priority VARCHAR(16) NOT NULL DEFAULT 'NORMAL',
+        schedule_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        workspace_id BIGINT UNSIGNED NOT NULL DEFAULT 0, -- 0 for personal tasks
+        -- Validators the source reported for the downloaded content
+        source_etag VARCHAR(255) NOT NULL DEFAULT '',
+        source_last_modified VARCHAR(64) NOT NULL DEFAULT '',
+        -- Download worker that claimed the task last
+        worker_id VARCHAR(255) NOT NULL DEFAULT '',
         INDEX (of_account_id),
-        INDEX (status)
-    );
\ No newline at end of file
+        INDEX (status),
+        INDEX idx_tasks_account_created (of_account_id, created_at, id),
+        INDEX idx_tasks_account_updated (of_account_id, updated_at, id),
+        INDEX idx_tasks_account_size (of_account_id, total_bytes, id),
+        INDEX idx_tasks_account_name (of_account_id, file_name(191), id),
+        INDEX idx_tasks_account_status (of_account_id, status, created_at),
+        INDEX idx_tasks_workspace_created (workspace_id, created_at, id),
+        INDEX idx_tasks_status_accessed (status, last_accessed_at),
+        INDEX idx_tasks_storage_path (storage_path(191))
+    );
+
+CREATE TABLE
+    task_schedules (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        of_account_id BIGINT UNSIGNED NOT NULL,
+        cron_expr VARCHAR(255) NOT NULL DEFAULT '',
+        start_at DATETIME,
+        enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        next_run_at DATETIME,
+        last_run_at DATETIME,
+        next_task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        template JSON NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        INDEX (of_account_id),
+        INDEX (enabled, next_run_at)
+    );
+CREATE TABLE
+    task_webhooks (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        of_account_id BIGINT UNSIGNED NOT NULL,
+        url TEXT NOT NULL,
+        secret VARCHAR(255) NOT NULL,
+        events JSON,
+        enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        INDEX (of_account_id)
+    );
+
+CREATE TABLE
+    task_webhook_deliveries (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        webhook_id BIGINT UNSIGNED NOT NULL,
+        event_id VARCHAR(64) NOT NULL,
+        event VARCHAR(64) NOT NULL,
+        task_id BIGINT UNSIGNED NOT NULL,
+        payload TEXT NOT NULL, -- the exact bytes that are signed
+        status VARCHAR(16) NOT NULL,
+        attempts INT UNSIGNED NOT NULL DEFAULT 0,
+        next_attempt_at DATETIME,
+        response_code INT NOT NULL DEFAULT 0,
+        last_error TEXT,
+        delivered_at DATETIME,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        UNIQUE (webhook_id, event_id),
+        INDEX (status, next_attempt_at)
+    );
+
+CREATE TABLE
+    task_audit_events (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        time DATETIME(3) NOT NULL,
+        actor_account_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        actor_api_key_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        action VARCHAR(64) NOT NULL,
+        task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        of_account_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        workspace_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        source_ip VARCHAR(45) NOT NULL DEFAULT '',
+        outcome VARCHAR(16) NOT NULL,
+        message TEXT,
+        INDEX (actor_account_id, id),
+        INDEX (of_account_id, id),
+        INDEX (workspace_id, id),
+        INDEX (task_id, id)
+    );
+
+CREATE TABLE
+    task_outbox (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        topic VARCHAR(255) NOT NULL,
+        task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        message_uuid VARCHAR(64) NOT NULL,
+        payload MEDIUMTEXT NOT NULL,
+        metadata JSON NOT NULL,
+        attempts INT UNSIGNED NOT NULL DEFAULT 0,
+        next_attempt_at DATETIME,
+        last_error TEXT,
+        dead_at DATETIME,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        INDEX (task_id, next_attempt_at)
+    );
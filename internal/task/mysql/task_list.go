@@ -0,0 +1,173 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	task "github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/internal/task/mysql/sqlc"
+)
+
+// Task listings are filtered and ordered at runtime, which sqlc cannot
+// generate, so they are built here. The column list follows sqlc.Task.
+const taskColumns = `id, of_account_id, file_name, source_url, source_type, headers, source_auth, storage_type, storage_path, checksum_type, checksum_value, concurrency, max_speed, max_retries, timeout, status, progress, downloaded_bytes, total_bytes, error_message, metadata, created_at, updated_at, completed_at, last_accessed_at, expiration_days, priority, schedule_id, workspace_id, source_etag, source_last_modified, worker_id`
+
+var taskSortColumns = map[task.TaskSortField]string{
+	task.SortByCreatedAt: "created_at",
+	task.SortByUpdatedAt: "updated_at",
+	task.SortBySize:      "total_bytes",
+	task.SortByName:      "file_name",
+}
+
+func (r *taskRepo) dbtx(ctx context.Context) sqlc.DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return r.db
+}
+
+func (r *taskRepo) ListByAccountID(ctx context.Context, query task.TaskQuery) ([]*task.Task, error) {
+	column, ok := taskSortColumns[query.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", query.SortBy)
+	}
+	where, args := taskFilterWhere(query.Filter)
+	op, dir := "<", "DESC"
+	if query.SortAsc {
+		op, dir = ">", "ASC"
+	}
+	if c := query.After; c != nil {
+		value := cursorValue(c)
+		where += fmt.Sprintf(" AND (%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, op)
+		args = append(args, value, value, c.ID)
+	}
+	stmt := fmt.Sprintf(
+		"SELECT %s FROM tasks WHERE %s ORDER BY %s %s, id %s LIMIT ? OFFSET ?",
+		taskColumns, where, column, dir, dir,
+	)
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := r.dbtx(ctx).QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sqlc.Task
+	for rows.Next() {
+		var i sqlc.Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.OfAccountID,
+			&i.FileName,
+			&i.SourceUrl,
+			&i.SourceType,
+			&i.Headers,
+			&i.SourceAuth,
+			&i.StorageType,
+			&i.StoragePath,
+			&i.ChecksumType,
+			&i.ChecksumValue,
+			&i.Concurrency,
+			&i.MaxSpeed,
+			&i.MaxRetries,
+			&i.Timeout,
+			&i.Status,
+			&i.Progress,
+			&i.DownloadedBytes,
+			&i.TotalBytes,
+			&i.ErrorMessage,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+			&i.LastAccessedAt,
+			&i.ExpirationDays,
+			&i.Priority,
+			&i.ScheduleID,
+			&i.WorkspaceID,
+			&i.SourceEtag,
+			&i.SourceLastModified,
+			&i.WorkerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return toTasks(items)
+}
+
+func (r *taskRepo) CountByAccountID(ctx context.Context, filter task.TaskFilter) (uint64, error) {
+	where, args := taskFilterWhere(filter)
+	var count uint64
+	err := r.dbtx(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks WHERE "+where, args...).Scan(&count)
+	return count, err
+}
+
+// taskFilterWhere returns the WHERE clause selecting the tasks of filter.
+// Tags must all be present in the metadata.tags array.
+func taskFilterWhere(filter task.TaskFilter) (string, []any) {
+	conds := []string{"of_account_id = ?", "workspace_id = 0"}
+	args := []any{filter.OfAccountID}
+	if filter.WorkspaceID != 0 {
+		conds = []string{"workspace_id = ?"}
+		args = []any{filter.WorkspaceID}
+	}
+	if len(filter.Status) > 0 {
+		conds = append(conds, "status IN ("+placeholders(len(filter.Status))+")")
+		for _, s := range filter.Status {
+			args = append(args, string(s))
+		}
+	}
+	if len(filter.SourceType) > 0 {
+		conds = append(conds, "source_type IN ("+placeholders(len(filter.SourceType))+")")
+		for _, s := range filter.SourceType {
+			args = append(args, string(s))
+		}
+	}
+	if r := filter.CreatedAt; r != nil {
+		if r.From != nil {
+			conds = append(conds, "created_at >= ?")
+			args = append(args, r.From.UTC())
+		}
+		if r.To != nil {
+			conds = append(conds, "created_at < ?")
+			args = append(args, r.To.UTC())
+		}
+	}
+	for _, tag := range filter.Tags {
+		conds = append(conds, "JSON_CONTAINS(metadata, JSON_QUOTE(?), '$.tags')")
+		args = append(args, tag)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		conds = append(conds, "(file_name LIKE ? OR source_url LIKE ?)")
+		args = append(args, pattern, pattern)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func cursorValue(c *task.TaskCursor) any {
+	switch c.SortBy {
+	case task.SortByCreatedAt, task.SortByUpdatedAt:
+		return c.Time.UTC()
+	case task.SortBySize:
+		return c.Size
+	default:
+		return c.Name
+	}
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
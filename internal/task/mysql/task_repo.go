@@ -70,22 +70,28 @@ func (r *taskRepo) Create(ctx context.Context, t *task.Task) (*task.Task, error)
 	}
 
 	result, err := q.CreateTask(ctx, sqlc.CreateTaskParams{
-		OfAccountID:   t.OfAccountID,
-		FileName:      t.FileName,
-		SourceUrl:     t.SourceURL,
-		SourceType:    string(t.SourceType),
-		SourceAuth:    sourceAuth,
-		Headers:       headers,
-		StorageType:   string(t.StorageType),
-		StoragePath:   t.StoragePath,
-		Status:        string(t.Status),
-		ChecksumType:  checksumType,
-		ChecksumValue: checksumValue,
-		Concurrency:   concurrency,
-		MaxSpeed:      maxSpeed,
-		MaxRetries:    maxRetries,
-		Timeout:       timeout,
-		Metadata:      metadata,
+		OfAccountID:        t.OfAccountID,
+		FileName:           t.FileName,
+		SourceUrl:          t.SourceURL,
+		SourceType:         string(t.SourceType),
+		SourceAuth:         sourceAuth,
+		Headers:            headers,
+		StorageType:        string(t.StorageType),
+		StoragePath:        t.StoragePath,
+		Status:             string(t.Status),
+		ChecksumType:       checksumType,
+		ChecksumValue:      checksumValue,
+		Concurrency:        concurrency,
+		MaxSpeed:           maxSpeed,
+		MaxRetries:         maxRetries,
+		Timeout:            timeout,
+		Metadata:           metadata,
+		ExpirationDays:     sql.NullInt32{Int32: t.ExpirationDays, Valid: true},
+		Priority:           string(t.Priority),
+		ScheduleID:         t.ScheduleID,
+		WorkspaceID:        t.WorkspaceID,
+		SourceEtag:         t.SourceETag,
+		SourceLastModified: t.SourceLastModified,
 	})
 	if err != nil {
 		return nil, err
@@ -171,6 +177,24 @@ func (r *taskRepo) Update(ctx context.Context, t *task.Task) (*task.Task, error)
 		}
 	}
 
+	if t.LastAccessedAt != nil {
+		if err = q.UpdateTaskLastAccessedAt(ctx, sqlc.UpdateTaskLastAccessedAtParams{
+			ID:             t.ID,
+			LastAccessedAt: sql.NullTime{Time: *t.LastAccessedAt, Valid: true},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.ExpirationDays > 0 {
+		if err = q.UpdateTaskExpirationDays(ctx, sqlc.UpdateTaskExpirationDaysParams{
+			ID:             t.ID,
+			ExpirationDays: sql.NullInt32{Int32: t.ExpirationDays, Valid: true},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	if t.ErrorMessage != nil && *t.ErrorMessage != "" {
 		if err = q.UpdateTaskError(ctx, sqlc.UpdateTaskErrorParams{
 			ID:           t.ID,
@@ -190,6 +214,35 @@ func (r *taskRepo) Update(ctx context.Context, t *task.Task) (*task.Task, error)
 		}
 	}
 
+	if t.Checksum != nil && t.Checksum.ChecksumValue != "" {
+		if err = q.UpdateFileChecksum(ctx, sqlc.UpdateFileChecksumParams{
+			ID:            t.ID,
+			ChecksumType:  sql.NullString{String: t.Checksum.ChecksumType, Valid: true},
+			ChecksumValue: sql.NullString{String: t.Checksum.ChecksumValue, Valid: true},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.SourceETag != "" || t.SourceLastModified != "" {
+		if err = q.UpdateTaskSourceValidators(ctx, sqlc.UpdateTaskSourceValidatorsParams{
+			ID:                 t.ID,
+			SourceEtag:         t.SourceETag,
+			SourceLastModified: t.SourceLastModified,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.WorkerID != "" {
+		if err = q.UpdateTaskWorker(ctx, sqlc.UpdateTaskWorkerParams{
+			ID:       t.ID,
+			WorkerID: t.WorkerID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	if opentx {
 		if err = tx.Commit(); err != nil {
 			return nil, err
@@ -214,19 +267,33 @@ func (r *taskRepo) GetByID(ctx context.Context, id uint64) (*task.Task, error) {
 	return toTask(t)
 }
 
-func (r *taskRepo) ListByAccountID(
-	ctx context.Context,
-	filter task.TaskFilter,
-	limit, offset uint32,
-) ([]*task.Task, error) {
+func (r *taskRepo) GetUsageOfAccount(ctx context.Context, ofAccountID uint64, since time.Time) (*task.Usage, error) {
 	q := r.queries
 	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
 		q = q.WithTx(tx)
 	}
-	tasks, err := q.ListTasks(ctx, sqlc.ListTasksParams{
-		OfAccountID: filter.OfAccountID,
-		Limit:       int32(limit),
-		Offset:      int32(offset),
+	row, err := q.GetAccountUsage(ctx, sqlc.GetAccountUsageParams{
+		CompletedAt: sql.NullTime{Time: since, Valid: true},
+		OfAccountID: ofAccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task.Usage{
+		ActiveTasks: row.ActiveTasks,
+		StoredBytes: row.StoredBytes,
+		BytesToday:  row.BytesToday,
+	}, nil
+}
+
+func (r *taskRepo) ListExpired(ctx context.Context, now time.Time, limit uint32) ([]*task.Task, error) {
+	q := r.queries
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		q = q.WithTx(tx)
+	}
+	tasks, err := q.ListExpiredTasks(ctx, sqlc.ListExpiredTasksParams{
+		ExpireBefore: now,
+		Limit:        int32(limit),
 	})
 	if err != nil {
 		return nil, err
@@ -234,13 +301,35 @@ func (r *taskRepo) ListByAccountID(
 	return toTasks(tasks)
 }
 
-func (r *taskRepo) GetTaskCountOfAccount(ctx context.Context, ofAccountID uint64) (uint64, error) {
+func (r *taskRepo) FindReusable(ctx context.Context, query task.ReusableQuery) (*task.Task, error) {
 	q := r.queries
 	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
 		q = q.WithTx(tx)
 	}
-	cnt, err := q.GetTaskCountByAccountId(ctx, ofAccountID)
-	return uint64(cnt), err
+	t, err := q.FindReusableTask(ctx, sqlc.FindReusableTaskParams{
+		OfAccountID:   query.OfAccountID,
+		ChecksumValue: query.ChecksumValue,
+		ChecksumType:  query.ChecksumType,
+		SourceUrl:     query.SourceURL,
+	})
+	if err != nil {
+		if stderrs.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return toTask(t)
+}
+
+func (r *taskRepo) CountByStoragePath(ctx context.Context, storagePath string, excludeID uint64) (int64, error) {
+	q := r.queries
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		q = q.WithTx(tx)
+	}
+	return q.CountTasksByStoragePath(ctx, sqlc.CountTasksByStoragePathParams{
+		StoragePath: storagePath,
+		ID:          excludeID,
+	})
 }
 
 func (r *taskRepo) Delete(ctx context.Context, id uint64) error {
@@ -282,6 +371,9 @@ func toTask(t sqlc.Task) (*task.Task, error) {
 		StorageType: storage.TypeValue(t.StorageType),
 		StoragePath: t.StoragePath,
 		Checksum:    checksum,
+		Priority:    task.Priority(t.Priority),
+		ScheduleID:  t.ScheduleID,
+		WorkspaceID: t.WorkspaceID,
 		Status:      task.TaskStatus(t.Status),
 		Progress:    progress,
 		ErrorMessage: func() *string {
@@ -309,6 +401,16 @@ func toTask(t sqlc.Task) (*task.Task, error) {
 			}
 			return nil
 		}(),
+		ExpirationDays: t.ExpirationDays.Int32,
+		LastAccessedAt: func() *time.Time {
+			if t.LastAccessedAt.Valid {
+				return &t.LastAccessedAt.Time
+			}
+			return nil
+		}(),
+		SourceETag:         t.SourceEtag,
+		SourceLastModified: t.SourceLastModified,
+		WorkerID:           t.WorkerID,
 	}, nil
 }
 
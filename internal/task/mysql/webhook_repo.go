@@ -0,0 +1,261 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	stderrs "errors"
+	"fmt"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
+	task "github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/internal/task/mysql/sqlc"
+)
+
+type webhookRepo struct {
+	queries *sqlc.Queries
+}
+
+func NewWebhookRepo(db *sql.DB) task.WebhookRepository {
+	return &webhookRepo{queries: sqlc.New(db)}
+}
+
+func (r *webhookRepo) q(ctx context.Context) *sqlc.Queries {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return r.queries.WithTx(tx)
+	}
+	return r.queries
+}
+
+func (r *webhookRepo) CreateWebhook(ctx context.Context, w *task.Webhook) (*task.Webhook, error) {
+	evs, err := toJSON(w.Events)
+	if err != nil {
+		return nil, fmt.Errorf("marshal Events: %w", err)
+	}
+	result, err := r.q(ctx).CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		OfAccountID: w.OfAccountID,
+		Url:         w.URL,
+		Secret:      w.Secret,
+		Events:      evs,
+		Enabled:     w.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	w.ID = uint64(id)
+	return w, nil
+}
+
+func (r *webhookRepo) GetWebhook(ctx context.Context, id uint64) (*task.Webhook, error) {
+	w, err := r.q(ctx).GetWebhookById(ctx, id)
+	if err != nil {
+		if stderrs.Is(err, sql.ErrNoRows) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+	return toWebhook(w)
+}
+
+func (r *webhookRepo) ListWebhooksOfAccount(
+	ctx context.Context,
+	ofAccountID uint64,
+	limit, offset uint32,
+) ([]*task.Webhook, error) {
+	rows, err := r.q(ctx).ListWebhooksByAccountId(ctx, sqlc.ListWebhooksByAccountIdParams{
+		OfAccountID: ofAccountID,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toWebhooks(rows)
+}
+
+func (r *webhookRepo) ListEnabledWebhooksOfAccount(ctx context.Context, ofAccountID uint64) ([]*task.Webhook, error) {
+	rows, err := r.q(ctx).ListEnabledWebhooksByAccountId(ctx, ofAccountID)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhooks(rows)
+}
+
+func (r *webhookRepo) UpdateWebhook(ctx context.Context, w *task.Webhook) error {
+	evs, err := toJSON(w.Events)
+	if err != nil {
+		return fmt.Errorf("marshal Events: %w", err)
+	}
+	return r.q(ctx).UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
+		Url:     w.URL,
+		Secret:  w.Secret,
+		Events:  evs,
+		Enabled: w.Enabled,
+		ID:      w.ID,
+	})
+}
+
+func (r *webhookRepo) DeleteWebhook(ctx context.Context, id uint64) error {
+	q := r.q(ctx)
+	if err := q.DeleteWebhookDeliveries(ctx, id); err != nil {
+		return err
+	}
+	return q.DeleteWebhook(ctx, id)
+}
+
+func (r *webhookRepo) CreateWebhookDelivery(ctx context.Context, d *task.WebhookDelivery) (bool, error) {
+	result, err := r.q(ctx).CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		WebhookID:     d.WebhookID,
+		EventID:       d.EventID,
+		Event:         string(d.Event),
+		TaskID:        d.TaskID,
+		Payload:       string(d.Payload),
+		Status:        string(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: toNullTime(d.NextAttemptAt),
+	})
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		// INSERT IGNORE skipped a duplicate (webhook_id, event_id).
+		return false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	d.ID = uint64(id)
+	return true, nil
+}
+
+func (r *webhookRepo) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uint64,
+	limit, offset uint32,
+) ([]*task.WebhookDelivery, error) {
+	rows, err := r.q(ctx).ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookDeliveries(rows), nil
+}
+
+func (r *webhookRepo) ListDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit uint32,
+) ([]*task.WebhookDelivery, error) {
+	rows, err := r.q(ctx).ListDueWebhookDeliveries(ctx, sqlc.ListDueWebhookDeliveriesParams{
+		NextAttemptAt: sql.NullTime{Time: now.UTC(), Valid: true},
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookDeliveries(rows), nil
+}
+
+func (r *webhookRepo) UpdateWebhookDelivery(
+	ctx context.Context,
+	d *task.WebhookDelivery,
+	prevNextAttemptAt *time.Time,
+) (bool, error) {
+	var lastError sql.NullString
+	if d.LastError != nil {
+		lastError = sql.NullString{String: *d.LastError, Valid: true}
+	}
+	result, err := r.q(ctx).UpdateWebhookDelivery(ctx, sqlc.UpdateWebhookDeliveryParams{
+		Status:          string(d.Status),
+		Attempts:        d.Attempts,
+		NextAttemptAt:   toNullTime(d.NextAttemptAt),
+		ResponseCode:    d.ResponseCode,
+		LastError:       lastError,
+		DeliveredAt:     toNullTime(d.DeliveredAt),
+		ID:              d.ID,
+		NextAttemptAt_2: toNullTime(prevNextAttemptAt),
+	})
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	// Every update changes at least the attempts or the next attempt time,
+	// so no affected rows means the delivery was claimed by someone else.
+	return n > 0, nil
+}
+
+func toWebhook(w sqlc.TaskWebhook) (*task.Webhook, error) {
+	evs, err := fromJSON[[]events.EventType](w.Events)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal Events: %w", err)
+	}
+	return &task.Webhook{
+		ID:          w.ID,
+		OfAccountID: w.OfAccountID,
+		URL:         w.Url,
+		Secret:      w.Secret,
+		Events:      getOrEmpty(evs),
+		Enabled:     w.Enabled,
+		CreatedAt:   w.CreatedAt.Time,
+		UpdatedAt:   w.UpdatedAt.Time,
+	}, nil
+}
+
+func toWebhooks(rows []sqlc.TaskWebhook) ([]*task.Webhook, error) {
+	var res []*task.Webhook
+	for _, row := range rows {
+		w, err := toWebhook(row)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, w)
+	}
+	return res, nil
+}
+
+func toWebhookDelivery(d sqlc.TaskWebhookDelivery) *task.WebhookDelivery {
+	var lastError *string
+	if d.LastError.Valid {
+		lastError = &d.LastError.String
+	}
+	return &task.WebhookDelivery{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		EventID:       d.EventID,
+		Event:         events.EventType(d.Event),
+		TaskID:        d.TaskID,
+		Payload:       []byte(d.Payload),
+		Status:        task.WebhookDeliveryStatus(d.Status),
+		Attempts:      d.Attempts,
+		NextAttemptAt: fromNullTime(d.NextAttemptAt),
+		ResponseCode:  d.ResponseCode,
+		LastError:     lastError,
+		DeliveredAt:   fromNullTime(d.DeliveredAt),
+		CreatedAt:     d.CreatedAt.Time,
+		UpdatedAt:     d.UpdatedAt.Time,
+	}
+}
+
+func toWebhookDeliveries(rows []sqlc.TaskWebhookDelivery) []*task.WebhookDelivery {
+	var res []*task.WebhookDelivery
+	for _, row := range rows {
+		res = append(res, toWebhookDelivery(row))
+	}
+	return res
+}
@@ -0,0 +1,143 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+const (
+	// outboxBatch is how many outbox messages a relay round publishes at most.
+	outboxBatch = 100
+
+	outboxRetryDelay    = time.Second
+	maxOutboxRetryDelay = 5 * time.Minute
+)
+
+// OutboxMessage is a message stored in the outbox until the relay published
+// it.
+type OutboxMessage struct {
+	ID    uint64
+	Topic string
+	// TaskID is the task the message is about; the messages of a task are
+	// published in the order they were stored. Zero for messages of no task.
+	TaskID  uint64
+	Message *message.Message
+	// Attempts is how often publishing the message failed.
+	Attempts  uint32
+	CreatedAt time.Time
+}
+
+// OutboxRepository stores the messages of the task service together with the
+// repository writes they report, so that a message is published if and only
+// if its transaction committed.
+type OutboxRepository interface {
+	// AddOutboxMessages stores msgs in the transaction of ctx, if any.
+	AddOutboxMessages(ctx context.Context, topic string, taskID uint64, msgs ...*message.Message) error
+	// DrainOutbox hands up to limit stored messages to publish, oldest first,
+	// and deletes those publish returned nil for. It returns how many
+	// messages were deleted.
+	//
+	// A message publish fails for is handed out again after
+	// OutboxRetryDelay; until then, and for the rest of the round, the later
+	// messages of its task are held back while those of other tasks are
+	// still handed out. Messages that cannot be decoded are dead-lettered:
+	// they stay in the store for inspection but are not handed out again.
+	// Concurrent calls sharing a store wait for each other or return at once,
+	// so that the messages of a task are never published out of order.
+	DrainOutbox(ctx context.Context, limit int, publish func(*OutboxMessage) error) (int, error)
+}
+
+// OutboxRetryDelay returns how long a message waits before it is published
+// again after its attempts-th failure: one second, doubled per attempt up
+// to five minutes.
+func OutboxRetryDelay(attempts uint32) time.Duration {
+	delay := outboxRetryDelay
+	for i := uint32(1); i < attempts && delay < maxOutboxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxOutboxRetryDelay)
+}
+
+// OutboxRelay publishes the messages stored in the outbox.
+type OutboxRelay struct {
+	repo      OutboxRepository
+	publisher message.Publisher
+	interval  time.Duration
+	logger    log.Logger
+}
+
+// OutboxRelayOption configures an OutboxRelay.
+type OutboxRelayOption func(*OutboxRelay)
+
+// WithOutboxRelayInterval sets how often the outbox is polled. Defaults to 1s.
+func WithOutboxRelayInterval(d time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// WithOutboxRelayLogger configures the relay logger.
+func WithOutboxRelayLogger(l log.Logger) OutboxRelayOption {
+	return func(r *OutboxRelay) { r.logger = l }
+}
+
+func NewOutboxRelay(repo OutboxRepository, publisher message.Publisher, opts ...OutboxRelayOption) *OutboxRelay {
+	r := &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		interval:  time.Second,
+		logger:    log.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Run publishes the stored messages until ctx is done.
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.Relay(ctx)
+		if err != nil {
+			level.Error(r.logger).Log("msg", "failed to relay outbox messages", "err", err)
+		} else if n > 0 {
+			level.Debug(r.logger).Log("msg", "relayed outbox messages", "count", n)
+		}
+		if n >= outboxBatch {
+			// More messages may be stored; do not wait for the next tick.
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Relay publishes one batch of stored messages and returns how many were
+// published. A message that fails to publish is retried in a later round,
+// see OutboxRepository.DrainOutbox.
+func (r *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	return r.repo.DrainOutbox(ctx, outboxBatch, func(m *OutboxMessage) error {
+		if err := r.publisher.Publish(m.Topic, m.Message); err != nil {
+			level.Warn(r.logger).Log(
+				"msg", "failed to publish outbox message",
+				"id", m.ID, "topic", m.Topic, "attempts", m.Attempts+1, "err", err,
+			)
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,118 @@
+package task
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+type fakeOutbox struct {
+	msgs   []*OutboxMessage
+	nextID uint64
+}
+
+func (o *fakeOutbox) AddOutboxMessages(
+	ctx context.Context,
+	topic string,
+	taskID uint64,
+	msgs ...*message.Message,
+) error {
+	for _, msg := range msgs {
+		o.nextID++
+		o.msgs = append(o.msgs, &OutboxMessage{ID: o.nextID, Topic: topic, TaskID: taskID, Message: msg})
+	}
+	return nil
+}
+
+func (o *fakeOutbox) DrainOutbox(ctx context.Context, limit int, publish func(*OutboxMessage) error) (int, error) {
+	var kept []*OutboxMessage
+	blocked := make(map[uint64]bool)
+	published := 0
+	for i, m := range o.msgs {
+		if i >= limit || (m.TaskID != 0 && blocked[m.TaskID]) {
+			kept = append(kept, m)
+			continue
+		}
+		if publish(m) != nil {
+			m.Attempts++
+			blocked[m.TaskID] = m.TaskID != 0
+			kept = append(kept, m)
+			continue
+		}
+		published++
+	}
+	o.msgs = kept
+	return published, nil
+}
+
+// failingPublisher fails the first publish of each topic in fail.
+type failingPublisher struct {
+	fakeMessagePublisher
+	fail map[string]bool
+}
+
+func (p *failingPublisher) Publish(topic string, messages ...*message.Message) error {
+	if p.fail[topic] {
+		p.fail[topic] = false
+		return stderrors.New("broker unavailable")
+	}
+	return p.fakeMessagePublisher.Publish(topic, messages...)
+}
+
+func TestCreateTask_StoresEventInOutbox(t *testing.T) {
+	outbox := &fakeOutbox{}
+	msgPub := &fakeMessagePublisher{}
+	svc := NewService(&fakeRepo{}, *NewEventPublisher(msgPub, WithOutbox(outbox)), fakeTxManager{})
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID: 7,
+		SourceURL:   "https://example.com/file.iso",
+		SourceType:  SourceHTTPS,
+	})
+	require.NoError(t, err)
+	require.Empty(t, msgPub.msgs)
+	require.Len(t, outbox.msgs, 1)
+	require.Equal(t, "task.created", outbox.msgs[0].Topic)
+
+	n, err := NewOutboxRelay(outbox, msgPub).Relay(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, []string{"task.created"}, msgPub.topics)
+	require.Empty(t, outbox.msgs)
+}
+
+func TestOutboxRelay_KeepsOrderOfTaskAfterFailedPublish(t *testing.T) {
+	outbox := &fakeOutbox{}
+	ctx := context.Background()
+	require.NoError(t, outbox.AddOutboxMessages(ctx, "task.created", 1, message.NewMessage("1", nil)))
+	require.NoError(t, outbox.AddOutboxMessages(ctx, "task.created", 2, message.NewMessage("2", nil)))
+	require.NoError(t, outbox.AddOutboxMessages(ctx, "task.paused", 1, message.NewMessage("3", nil)))
+
+	pub := &failingPublisher{fail: map[string]bool{"task.created": true}}
+	relay := NewOutboxRelay(outbox, pub)
+
+	// The paused event of task 1 waits for its created event.
+	n, err := relay.Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, "2", pub.msgs[0].UUID)
+
+	n, err = relay.Relay(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []string{"task.created", "task.created", "task.paused"}, pub.topics)
+	require.Equal(t, "1", pub.msgs[1].UUID)
+	require.Equal(t, "3", pub.msgs[2].UUID)
+	require.Empty(t, outbox.msgs)
+}
+
+func TestOutboxRetryDelay_BacksOffUpToCap(t *testing.T) {
+	require.Equal(t, time.Second, OutboxRetryDelay(1))
+	require.Equal(t, 4*time.Second, OutboxRetryDelay(3))
+	require.Equal(t, maxOutboxRetryDelay, OutboxRetryDelay(30))
+}
@@ -8,14 +8,35 @@ import (
 )
 
 type CreateTaskParam struct {
-	ID          uint64         `json:"id"`
-	OfAccountID uint64         `json:"of_account_id"`
+	ID          uint64 `json:"id"`
+	OfAccountID uint64 `json:"of_account_id"`
+	// WorkspaceID shares the task with the members of the workspace; zero
+	// keeps it personal to OfAccountID.
+	WorkspaceID uint64         `json:"workspace_id,omitempty"`
 	FileName    string         `json:"file_name"`
 	SourceURL   string         `json:"source_url"`
 	SourceType  SourceType     `json:"source_type"`
 	SourceAuth  *AuthConfig    `json:"source_auth,omitempty"`
 	Checksum    *ChecksumInfo  `json:"checksum,omitempty"`
+	Priority    Priority       `json:"priority,omitempty"`
 	Metadata    map[string]any `json:"metadata,omitempty"`
+	// Schedule defers the task to a start time or repeats it on a cron
+	// expression. Nil starts the task immediately.
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+	// ExpirationDays is how many days the stored file is kept after it was
+	// last accessed. Zero takes the default of the service.
+	ExpirationDays int32 `json:"expiration_days,omitempty"`
+	// ReuseExisting completes the task at once with the stored file of a
+	// completed task of the account that downloaded the same source, when
+	// there is one, instead of downloading it again.
+	ReuseExisting bool `json:"reuse_existing,omitempty"`
+}
+
+// ExtendTaskRetentionParam keeps the stored file of a completed task for
+// Days more days from now.
+type ExtendTaskRetentionParam struct {
+	TaskID uint64
+	Days   int32
 }
 
 type UpdateTaskParam struct {
@@ -32,6 +53,9 @@ type ListTasksParam struct {
 	Limit   int32
 	SortBy  string
 	SortAsc bool
+	// Cursor continues a previous listing with the same sort order and
+	// replaces Offset.
+	Cursor string
 }
 
 type PauseTaskParam struct {
@@ -45,12 +69,16 @@ type TaskOutput struct {
 
 type ListTasksOutput struct {
 	Tasks []*Task
+	// Total counts every task matching the filter.
 	Total int32
+	// NextCursor is set when the page is full and more tasks may follow.
+	NextCursor string
 }
 
 type (
 	SourceType string
 	TaskStatus string
+	Priority   string
 )
 
 const (
@@ -69,6 +97,13 @@ const (
 	StatusFailed      TaskStatus = "FAILED"
 	StatusCancelled   TaskStatus = "CANCELLED"
 	StatusPaused      TaskStatus = "PAUSED"
+	StatusScheduled   TaskStatus = "SCHEDULED"
+	StatusExpired     TaskStatus = "EXPIRED"
+
+	// Priority
+	PriorityLow    Priority = "LOW"
+	PriorityNormal Priority = "NORMAL"
+	PriorityHigh   Priority = "HIGH"
 )
 
 func (s TaskStatus) String() string {
@@ -79,6 +114,10 @@ func (s SourceType) String() string {
 	return string(s)
 }
 
+func (p Priority) String() string {
+	return string(p)
+}
+
 func ToSourceType(src string) SourceType {
 	src = strings.ToUpper(src)
 	switch src {
@@ -109,6 +148,9 @@ type Task struct {
 	StoragePath     string            `json:"storage_path"`
 	Checksum        *ChecksumInfo     `json:"checksum,omitempty"`
 	DownloadOptions *DownloadOptions  `json:"download_options,omitempty"`
+	Priority        Priority          `json:"priority"`
+	ScheduleID      uint64            `json:"schedule_id,omitempty"`
+	WorkspaceID     uint64            `json:"workspace_id,omitempty"`
 	Status          TaskStatus        `json:"status"`
 	Progress        *DownloadProgress `json:"progress,omitempty"`
 	ErrorMessage    *string           `json:"error_message,omitempty"`
@@ -116,6 +158,27 @@ type Task struct {
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
 	CompletedAt     *time.Time        `json:"completed_at,omitempty"`
+	// ExpirationDays is how many days the stored file is kept after
+	// LastAccessedAt; zero keeps it until the task is deleted.
+	ExpirationDays int32      `json:"expiration_days,omitempty"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	// SourceETag and SourceLastModified are the validators the source
+	// reported for the downloaded content.
+	SourceETag         string `json:"source_etag,omitempty"`
+	SourceLastModified string `json:"source_last_modified,omitempty"`
+	// WorkerID is the download worker that claimed the task last. Pause,
+	// resume and cancel requests are routed to it.
+	WorkerID string `json:"worker_id,omitempty"`
+}
+
+// ExpiresAt returns when the stored file of the task expires, or nil when
+// it is kept until the task is deleted.
+func (t *Task) ExpiresAt() *time.Time {
+	if t.ExpirationDays <= 0 || t.LastAccessedAt == nil {
+		return nil
+	}
+	at := t.LastAccessedAt.AddDate(0, 0, int(t.ExpirationDays))
+	return &at
 }
 
 // DownloadProgress tracks download progress
@@ -145,6 +208,9 @@ type AuthConfig struct {
 // TaskFilter TaskFilter for querying tasks
 type TaskFilter struct {
 	OfAccountID uint64
+	// WorkspaceID selects the tasks of the workspace instead of the personal
+	// tasks of OfAccountID.
+	WorkspaceID uint64
 	Status      []TaskStatus `json:"status"`
 	Tags        []string     `json:"tags"`
 	SourceType  []SourceType `json:"source_type"`
@@ -0,0 +1,138 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// Quota limits what a single account may consume. A zero value means the
+// corresponding resource is unlimited.
+type Quota struct {
+	MaxActiveTasks int64 `json:"max_active_tasks"`
+	MaxStoredBytes int64 `json:"max_stored_bytes"`
+	MaxBytesPerDay int64 `json:"max_bytes_per_day"`
+}
+
+// Usage is the current consumption of an account together with its quota.
+type Usage struct {
+	// ActiveTasks counts pending, downloading, storing and paused tasks.
+	ActiveTasks int64
+	// StoredBytes is the total size of completed tasks.
+	StoredBytes int64
+	// BytesToday is the total size of tasks completed since midnight UTC.
+	BytesToday int64
+	Quota      Quota
+}
+
+// WithDefaultQuota configures the quota applied to accounts without an
+// account specific quota.
+func WithDefaultQuota(q Quota) ServiceOption {
+	return func(s *service) { s.defaultQuota = q }
+}
+
+// WithAccountQuota overrides the default quota for a single account.
+func WithAccountQuota(ofAccountID uint64, q Quota) ServiceOption {
+	return func(s *service) {
+		if s.accountQuotas == nil {
+			s.accountQuotas = make(map[uint64]Quota)
+		}
+		s.accountQuotas[ofAccountID] = q
+	}
+}
+
+// QuotaOptions builds the service options for a default quota and a JSON
+// object of per-account overrides keyed by account id, e.g.
+// {"42":{"max_active_tasks":10}}. An empty overrides string is allowed.
+func QuotaOptions(defaultQuota Quota, accountOverrides string) ([]ServiceOption, error) {
+	opts := []ServiceOption{WithDefaultQuota(defaultQuota)}
+	if accountOverrides == "" {
+		return opts, nil
+	}
+	var overrides map[uint64]Quota
+	if err := json.Unmarshal([]byte(accountOverrides), &overrides); err != nil {
+		return nil, fmt.Errorf("parse account quotas: %w", err)
+	}
+	for id, q := range overrides {
+		opts = append(opts, WithAccountQuota(id, q))
+	}
+	return opts, nil
+}
+
+func (s *service) quotaOf(ofAccountID uint64) Quota {
+	if q, ok := s.accountQuotas[ofAccountID]; ok {
+		return q
+	}
+	return s.defaultQuota
+}
+
+func (s *service) GetUsage(ctx context.Context, ofAccountID uint64) (*Usage, error) {
+	usage, err := s.repo.GetUsageOfAccount(ctx, ofAccountID, startOfDay(time.Now()))
+	if err != nil {
+		return nil, &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "failed to get account usage",
+			Cause:   err,
+		}
+	}
+	usage.Quota = s.quotaOf(ofAccountID)
+	return usage, nil
+}
+
+// checkCreateQuota rejects n new tasks when they would exceed the active task
+// limit of the account or when it already reached one of its byte limits. The
+// size of new tasks is not known yet; it is checked again when they complete.
+func (s *service) checkCreateQuota(ctx context.Context, ofAccountID uint64, n int64) error {
+	q := s.quotaOf(ofAccountID)
+	if q == (Quota{}) {
+		return nil
+	}
+	usage, err := s.GetUsage(ctx, ofAccountID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case q.MaxActiveTasks > 0 && usage.ActiveTasks+n > q.MaxActiveTasks:
+		if n > 1 {
+			return quotaExceeded(fmt.Sprintf(
+				"%d new tasks exceed the active task quota of %d tasks", n, q.MaxActiveTasks))
+		}
+		return quotaExceeded(fmt.Sprintf("active task quota of %d tasks reached", q.MaxActiveTasks))
+	case q.MaxStoredBytes > 0 && usage.StoredBytes >= q.MaxStoredBytes:
+		return quotaExceeded(fmt.Sprintf("storage quota of %d bytes reached", q.MaxStoredBytes))
+	case q.MaxBytesPerDay > 0 && usage.BytesToday >= q.MaxBytesPerDay:
+		return quotaExceeded(fmt.Sprintf("daily quota of %d bytes reached", q.MaxBytesPerDay))
+	}
+	return nil
+}
+
+// checkCompleteQuota reports whether storing totalBytes more for the account
+// stays within its storage and daily limits.
+func (s *service) checkCompleteQuota(ctx context.Context, ofAccountID uint64, totalBytes int64) error {
+	q := s.quotaOf(ofAccountID)
+	if (q.MaxStoredBytes == 0 && q.MaxBytesPerDay == 0) || totalBytes <= 0 {
+		return nil
+	}
+	usage, err := s.GetUsage(ctx, ofAccountID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case q.MaxStoredBytes > 0 && usage.StoredBytes+totalBytes > q.MaxStoredBytes:
+		return quotaExceeded(fmt.Sprintf("storage quota of %d bytes exceeded", q.MaxStoredBytes))
+	case q.MaxBytesPerDay > 0 && usage.BytesToday+totalBytes > q.MaxBytesPerDay:
+		return quotaExceeded(fmt.Sprintf("daily quota of %d bytes exceeded", q.MaxBytesPerDay))
+	}
+	return nil
+}
+
+func quotaExceeded(msg string) error {
+	return &errors.Error{Code: errors.ErrCodeTooManyRequests, Message: msg}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,140 @@
+package task
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// ReusableQuery matches the completed tasks of an account whose stored file
+// can be reused for a new task: a task with the same expected checksum or,
+// when no checksum is expected, a task of the same source URL whose source
+// reported an ETag or Last-Modified, i.e. served a versioned object.
+type ReusableQuery struct {
+	OfAccountID uint64
+	SourceURL   string
+	// ChecksumType is normalized by normalizeChecksumType; empty matches any
+	// type.
+	ChecksumType string
+	// ChecksumValue is lower case. When set, tasks only match by checksum;
+	// empty matches by source URL.
+	ChecksumValue string
+}
+
+// reuseExistingTask completes t at once with the stored file of a completed
+// task of the same source, without publishing a TaskCreated event. It
+// returns nil when there is no such task.
+func (s *service) reuseExistingTask(ctx context.Context, t *Task) (*Task, error) {
+	query := ReusableQuery{OfAccountID: t.OfAccountID, SourceURL: t.SourceURL}
+	if t.Checksum != nil && strings.TrimSpace(t.Checksum.ChecksumValue) != "" {
+		query.ChecksumType = normalizeChecksumType(t.Checksum.ChecksumType)
+		query.ChecksumValue = strings.ToLower(strings.TrimSpace(t.Checksum.ChecksumValue))
+	}
+	src, err := s.repo.FindReusable(ctx, query)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to find reusable task", Cause: err}
+	}
+	// The storage backend may have removed the file on its own.
+	if s.fileStore != nil {
+		if ok, err := s.fileStore.Exists(ctx, src.StoragePath); err != nil || !ok {
+			level.Debug(s.logger).Log("msg", "stored file of reusable task is gone", "task_id", src.ID, "err", err)
+			return nil, nil
+		}
+	}
+
+	var size int64
+	if src.Progress != nil {
+		size = src.Progress.TotalBytes
+	}
+	if err := s.checkCompleteQuota(ctx, t.OfAccountID, size); err != nil {
+		return nil, err
+	}
+
+	t.Status = StatusCompleted
+	t.StorageType = src.StorageType
+	t.StoragePath = src.StoragePath
+	if t.FileName == "" {
+		t.FileName = src.FileName
+	}
+	if query.ChecksumValue == "" {
+		t.Checksum = src.Checksum
+	}
+	t.SourceETag = src.SourceETag
+	t.SourceLastModified = src.SourceLastModified
+
+	now := time.Now()
+	var created *Task
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		created, err = s.repo.Create(ctx, t)
+		if err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to create task", Cause: err}
+		}
+		if _, err := s.repo.Update(ctx, &Task{
+			ID:             created.ID,
+			Progress:       &DownloadProgress{Progress: 100, DownloadedBytes: size, TotalBytes: size},
+			CompletedAt:    &now,
+			LastAccessedAt: &now,
+		}); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to create task", Cause: err}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	created.Progress = &DownloadProgress{Progress: 100, DownloadedBytes: size, TotalBytes: size}
+	created.CompletedAt = &now
+	created.LastAccessedAt = &now
+
+	level.Info(s.logger).Log("msg", "reused stored file", "task_id", created.ID, "source_task_id", src.ID)
+	s.emitStatus(created)
+	return created, nil
+}
+
+func (s *service) UpdateSourceValidators(ctx context.Context, id uint64, etag, lastModified string) error {
+	if etag == "" && lastModified == "" {
+		return nil
+	}
+	_, err := s.repo.Update(ctx, &Task{
+		ID:                 id,
+		SourceETag:         etag,
+		SourceLastModified: lastModified,
+	})
+	if err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "update task source validators failed",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
+// releaseStoredFile deletes the stored file of t unless another task still
+// refers to it, as reused tasks share the file of the task they reuse.
+func (s *service) releaseStoredFile(ctx context.Context, t *Task) error {
+	if s.fileStore == nil || t.StoragePath == "" {
+		return nil
+	}
+	refs, err := s.repo.CountByStoragePath(ctx, t.StoragePath, t.ID)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return nil
+	}
+	return s.fileStore.Delete(ctx, t.StoragePath)
+}
+
+// normalizeChecksumType lower-cases a checksum algorithm and drops dashes,
+// so that e.g. SHA-256 and sha256 compare equal.
+func normalizeChecksumType(checksumType string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(checksumType)), "-", "")
+}
@@ -0,0 +1,122 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+)
+
+func TestCreateTask_ReusesStoredFileOfCompletedTask(t *testing.T) {
+	repo := &fakeRepo{reusable: &Task{
+		ID:                 9,
+		OfAccountID:        7,
+		FileName:           "file.iso",
+		StorageType:        "minio",
+		StoragePath:        "9/file.iso",
+		Status:             StatusCompleted,
+		Checksum:           &ChecksumInfo{ChecksumType: "sha256", ChecksumValue: "abc"},
+		Progress:           &DownloadProgress{Progress: 100, DownloadedBytes: 42, TotalBytes: 42},
+		SourceETag:         `"v1"`,
+		SourceLastModified: "Mon, 02 Jan 2006 15:04:05 GMT",
+	}}
+	msgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(msgPub), fakeTxManager{}, WithFileStore(&fakeFileStore{}))
+
+	got, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID:   7,
+		FileName:      "copy.iso",
+		SourceURL:     "https://example.com/file.iso",
+		SourceType:    SourceHTTPS,
+		ReuseExisting: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ReusableQuery{OfAccountID: 7, SourceURL: "https://example.com/file.iso"}, repo.reuseQuery)
+
+	require.Equal(t, StatusCompleted, got.Status)
+	require.Equal(t, "9/file.iso", got.StoragePath)
+	require.Equal(t, "copy.iso", got.FileName)
+	require.Equal(t, "abc", got.Checksum.ChecksumValue)
+	require.Equal(t, int64(42), got.Progress.TotalBytes)
+	require.NotNil(t, got.CompletedAt)
+	require.Equal(t, StatusCompleted, repo.created.Status)
+	require.Equal(t, float64(100), repo.updated.Progress.Progress)
+	// Nothing is left for the download service to do.
+	require.Empty(t, msgPub.msgs)
+}
+
+func TestCreateTask_ReuseMatchesNormalizedChecksum(t *testing.T) {
+	repo := &fakeRepo{}
+	msgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(msgPub), fakeTxManager{}, WithFileStore(&fakeFileStore{}))
+
+	got, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID:   7,
+		SourceURL:     "https://mirror.example.com/file.iso",
+		SourceType:    SourceHTTPS,
+		Checksum:      &ChecksumInfo{ChecksumType: "SHA-256", ChecksumValue: " ABC "},
+		ReuseExisting: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "sha256", repo.reuseQuery.ChecksumType)
+	require.Equal(t, "abc", repo.reuseQuery.ChecksumValue)
+
+	// Without a completed task to reuse the source is downloaded as usual.
+	require.Equal(t, StatusPending, got.Status)
+	require.Len(t, msgPub.msgs, 1)
+}
+
+func TestCreateTask_DoesNotReuseWhenStoredFileIsGone(t *testing.T) {
+	repo := &fakeRepo{reusable: &Task{ID: 9, OfAccountID: 7, Status: StatusCompleted, StoragePath: "9/file.iso"}}
+	msgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(msgPub), fakeTxManager{}, WithFileStore(&fakeWriter{}))
+
+	got, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID:   7,
+		SourceURL:     "https://example.com/file.iso",
+		SourceType:    SourceHTTPS,
+		ReuseExisting: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, got.Status)
+	require.Empty(t, got.StoragePath)
+	require.Len(t, msgPub.msgs, 1)
+}
+
+func TestCreateTask_ReuseCountsAgainstStorageQuota(t *testing.T) {
+	repo := &fakeRepo{
+		usage: &Usage{StoredBytes: 90},
+		reusable: &Task{
+			ID: 9, OfAccountID: 7, Status: StatusCompleted, StoragePath: "9/file.iso",
+			Progress: &DownloadProgress{TotalBytes: 20},
+		},
+	}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{},
+		WithFileStore(&fakeFileStore{}),
+		WithDefaultQuota(Quota{MaxStoredBytes: 100}),
+	)
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID:   7,
+		SourceURL:     "https://example.com/file.iso",
+		SourceType:    SourceHTTPS,
+		ReuseExisting: true,
+	})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeTooManyRequests))
+	require.Nil(t, repo.created)
+}
+
+func TestDeleteTask_KeepsFileSharedWithAnotherTask(t *testing.T) {
+	files := &fakeFileStore{}
+	repo := &fakeRepo{
+		task:        &Task{ID: 10, Status: StatusCompleted, StoragePath: "9/file.iso"},
+		storageRefs: 1,
+	}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{}, WithFileStore(files))
+
+	require.NoError(t, svc.DeleteTask(context.Background(), 10))
+	require.Equal(t, []uint64{10}, repo.deleted)
+	require.Empty(t, files.deleted)
+}
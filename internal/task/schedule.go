@@ -0,0 +1,301 @@
+package task
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// ScheduleSpec describes when a task runs. With only StartAt the task runs
+// once at that time; with Cron it runs on every match of the expression, not
+// before StartAt when that is set as well.
+type ScheduleSpec struct {
+	StartAt *time.Time `json:"start_at,omitempty"`
+	Cron    string     `json:"cron,omitempty"`
+}
+
+// Schedule creates tasks from a template. The upcoming run always exists as a
+// SCHEDULED task (NextTaskID) which is started when NextRunAt is reached.
+type Schedule struct {
+	ID          uint64
+	OfAccountID uint64
+	Cron        string
+	StartAt     *time.Time
+	Enabled     bool
+	// NextRunAt is nil once a one-off schedule has run or the schedule is disabled.
+	NextRunAt  *time.Time
+	LastRunAt  *time.Time
+	NextTaskID uint64
+	Template   *CreateTaskParam
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// UpdateScheduleParam changes the timing of a schedule. Nil fields are kept.
+type UpdateScheduleParam struct {
+	ID      uint64
+	Cron    *string
+	StartAt *time.Time
+	Enabled *bool
+}
+
+type ScheduleRepository interface {
+	CreateSchedule(ctx context.Context, s *Schedule) (*Schedule, error)
+	GetSchedule(ctx context.Context, id uint64) (*Schedule, error)
+	ListSchedulesOfAccount(ctx context.Context, ofAccountID uint64, limit, offset uint32) ([]*Schedule, error)
+	// ListDueSchedules returns enabled schedules whose next run is at or before now.
+	ListDueSchedules(ctx context.Context, now time.Time, limit uint32) ([]*Schedule, error)
+	// UpdateSchedule stores s if the stored next run time still equals
+	// prevNextRunAt and reports whether it did.
+	UpdateSchedule(ctx context.Context, s *Schedule, prevNextRunAt *time.Time) (bool, error)
+	DeleteSchedule(ctx context.Context, id uint64) error
+}
+
+// WithScheduleRepository enables scheduled and recurring tasks.
+func WithScheduleRepository(r ScheduleRepository) ServiceOption {
+	return func(s *service) { s.schedules = r }
+}
+
+// nextRunAt returns the first run of a schedule after the given time, or nil
+// when a one-off schedule has no future run.
+func nextRunAt(cron string, startAt *time.Time, after time.Time) (*time.Time, error) {
+	if cron == "" {
+		if startAt == nil || !startAt.After(after) {
+			return nil, nil
+		}
+		t := startAt.UTC().Truncate(time.Second)
+		return &t, nil
+	}
+
+	spec, err := parseCron(cron)
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "invalid cron expression", Cause: err}
+	}
+	if startAt != nil && startAt.After(after) {
+		after = startAt.Add(-time.Nanosecond)
+	}
+	next := spec.Next(after)
+	if next.IsZero() {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "cron expression never matches"}
+	}
+	return &next, nil
+}
+
+// occurrence builds the SCHEDULED task for the next run of the schedule.
+func (sc *Schedule) occurrence() *Task {
+	tmpl := sc.Template
+	if tmpl == nil {
+		tmpl = &CreateTaskParam{}
+	}
+	return &Task{
+		OfAccountID:     sc.OfAccountID,
+		WorkspaceID:     tmpl.WorkspaceID,
+		FileName:        tmpl.FileName,
+		SourceURL:       tmpl.SourceURL,
+		SourceType:      tmpl.SourceType,
+		SourceAuth:      tmpl.SourceAuth,
+		Checksum:        tmpl.Checksum,
+		DownloadOptions: defaultDownloadOptions(),
+		Priority:        tmpl.Priority,
+		Metadata:        tmpl.Metadata,
+		ScheduleID:      sc.ID,
+		Status:          StatusScheduled,
+	}
+}
+
+// occurrenceDeleted emits and audits the deletion of the occurrence taskID of
+// sched, in the account and workspace the occurrence was created in.
+func (s *service) occurrenceDeleted(ctx context.Context, sched *Schedule, taskID uint64) {
+	occ := sched.occurrence()
+	occ.ID = taskID
+	s.emit(&TaskEvent{
+		Type:        TaskEventDeleted,
+		TaskID:      taskID,
+		OfAccountID: occ.OfAccountID,
+		WorkspaceID: occ.WorkspaceID,
+	})
+	s.recordAudit(ctx, AuditTaskDelete, occ, "")
+}
+
+// createScheduledTask stores the schedule together with its first occurrence.
+func (s *service) createScheduledTask(
+	ctx context.Context,
+	task *Task,
+	spec *ScheduleSpec,
+	next *time.Time,
+) (*Task, error) {
+	sched := &Schedule{
+		OfAccountID: task.OfAccountID,
+		Cron:        strings.TrimSpace(spec.Cron),
+		StartAt:     spec.StartAt,
+		Enabled:     true,
+		NextRunAt:   next,
+		Template: &CreateTaskParam{
+			OfAccountID: task.OfAccountID,
+			WorkspaceID: task.WorkspaceID,
+			FileName:    task.FileName,
+			SourceURL:   task.SourceURL,
+			SourceType:  task.SourceType,
+			SourceAuth:  task.SourceAuth,
+			Checksum:    task.Checksum,
+			Priority:    task.Priority,
+			Metadata:    task.Metadata,
+		},
+	}
+
+	var created *Task
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		var err error
+		if sched, err = s.schedules.CreateSchedule(ctx, sched); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to create schedule", Cause: err}
+		}
+		if created, err = s.repo.Create(ctx, sched.occurrence()); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to create task", Cause: err}
+		}
+		sched.NextTaskID = created.ID
+		if _, err = s.schedules.UpdateSchedule(ctx, sched, sched.NextRunAt); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to create schedule", Cause: err}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	s.emitStatus(created)
+	return created, nil
+}
+
+func (s *service) ListSchedules(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*Schedule, error) {
+	if s.schedules == nil {
+		return nil, nil
+	}
+	if limit < 0 || offset < 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "limit and offset must be non-negative"}
+	}
+	schedules, err := s.schedules.ListSchedulesOfAccount(ctx, ofAccountID, uint32(limit), uint32(offset))
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list schedules", Cause: err}
+	}
+	return schedules, nil
+}
+
+func (s *service) GetSchedule(ctx context.Context, id uint64) (*Schedule, error) {
+	if s.schedules == nil {
+		return nil, &errors.Error{Code: errors.ErrCodeNotFound, Message: "schedule not found"}
+	}
+	sched, err := s.schedules.GetSchedule(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return nil, &errors.Error{Code: errors.ErrCodeNotFound, Message: "schedule not found", Cause: err}
+		}
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get schedule", Cause: err}
+	}
+	return sched, nil
+}
+
+func (s *service) UpdateSchedule(ctx context.Context, param *UpdateScheduleParam) (*Schedule, error) {
+	sched, err := s.GetSchedule(ctx, param.ID)
+	if err != nil {
+		return nil, err
+	}
+	prev := sched.NextRunAt
+
+	if param.Cron != nil {
+		sched.Cron = strings.TrimSpace(*param.Cron)
+	}
+	if param.StartAt != nil {
+		sched.StartAt = param.StartAt
+	}
+	if param.Enabled != nil {
+		sched.Enabled = *param.Enabled
+	}
+
+	sched.NextRunAt = nil
+	if sched.Enabled {
+		next, err := nextRunAt(sched.Cron, sched.StartAt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "schedule has no future run"}
+		}
+		sched.NextRunAt = next
+	}
+
+	var (
+		created   *Task
+		deletedID uint64
+	)
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		switch pending := s.hasScheduledOccurrence(ctx, sched); {
+		case sched.Enabled && !pending:
+			var err error
+			if created, err = s.repo.Create(ctx, sched.occurrence()); err != nil {
+				return &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to create task", Cause: err}
+			}
+			sched.NextTaskID = created.ID
+		case !sched.Enabled && pending:
+			// A disabled schedule has no upcoming run; enabling it again
+			// creates a new one.
+			if err := s.repo.Delete(ctx, sched.NextTaskID); err != nil {
+				return &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to delete task", Cause: err}
+			}
+			deletedID, sched.NextTaskID = sched.NextTaskID, 0
+		}
+		ok, err := s.schedules.UpdateSchedule(ctx, sched, prev)
+		if err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to update schedule", Cause: err}
+		}
+		if !ok {
+			return &errors.Error{Code: errors.ErrCodeConflict, Message: "schedule was changed concurrently"}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if created != nil {
+		s.emitStatus(created)
+	}
+	if deletedID != 0 {
+		s.occurrenceDeleted(ctx, sched, deletedID)
+	}
+	return sched, nil
+}
+
+func (s *service) DeleteSchedule(ctx context.Context, id uint64) error {
+	sched, err := s.GetSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+	var deleted bool
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		// The upcoming run has not been handed to a worker yet; drop it too.
+		if deleted = s.hasScheduledOccurrence(ctx, sched); deleted {
+			if err := s.repo.Delete(ctx, sched.NextTaskID); err != nil {
+				return &errors.Error{Code: errors.ErrCodeInternal, Message: "Failed to delete task", Cause: err}
+			}
+		}
+		if err := s.schedules.DeleteSchedule(ctx, id); err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to delete schedule", Cause: err}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if deleted {
+		s.occurrenceDeleted(ctx, sched, sched.NextTaskID)
+	}
+	return nil
+}
+
+// hasScheduledOccurrence reports whether the next run of sched still exists
+// and has not been started, cancelled or deleted.
+func (s *service) hasScheduledOccurrence(ctx context.Context, sched *Schedule) bool {
+	if sched.NextTaskID == 0 {
+		return false
+	}
+	t, err := s.repo.GetByID(ctx, sched.NextTaskID)
+	return err == nil && t != nil && t.Status == StatusScheduled
+}
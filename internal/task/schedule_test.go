@@ -0,0 +1,244 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+type fakeScheduleRepo struct {
+	schedules map[uint64]*Schedule
+	nextID    uint64
+}
+
+func newFakeScheduleRepo() *fakeScheduleRepo {
+	return &fakeScheduleRepo{schedules: map[uint64]*Schedule{}}
+}
+
+func (r *fakeScheduleRepo) CreateSchedule(ctx context.Context, s *Schedule) (*Schedule, error) {
+	r.nextID++
+	s.ID = r.nextID
+	stored := *s
+	r.schedules[s.ID] = &stored
+	return s, nil
+}
+
+func (r *fakeScheduleRepo) GetSchedule(ctx context.Context, id uint64) (*Schedule, error) {
+	s, ok := r.schedules[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	cloned := *s
+	return &cloned, nil
+}
+
+func (r *fakeScheduleRepo) ListSchedulesOfAccount(
+	ctx context.Context,
+	ofAccountID uint64,
+	limit, offset uint32,
+) ([]*Schedule, error) {
+	var out []*Schedule
+	for _, s := range r.schedules {
+		if s.OfAccountID == ofAccountID {
+			cloned := *s
+			out = append(out, &cloned)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeScheduleRepo) ListDueSchedules(ctx context.Context, now time.Time, limit uint32) ([]*Schedule, error) {
+	var out []*Schedule
+	for _, s := range r.schedules {
+		if s.Enabled && s.NextRunAt != nil && !s.NextRunAt.After(now) {
+			cloned := *s
+			out = append(out, &cloned)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeScheduleRepo) UpdateSchedule(ctx context.Context, s *Schedule, prevNextRunAt *time.Time) (bool, error) {
+	cur, ok := r.schedules[s.ID]
+	if !ok {
+		return false, nil
+	}
+	if (cur.NextRunAt == nil) != (prevNextRunAt == nil) ||
+		(cur.NextRunAt != nil && !cur.NextRunAt.Equal(*prevNextRunAt)) {
+		return false, nil
+	}
+	stored := *s
+	r.schedules[s.ID] = &stored
+	return true, nil
+}
+
+func (r *fakeScheduleRepo) DeleteSchedule(ctx context.Context, id uint64) error {
+	delete(r.schedules, id)
+	return nil
+}
+
+func TestCreateTask_WithStartAtCreatesScheduledTask(t *testing.T) {
+	repo := &fakeRepo{}
+	schedules := newFakeScheduleRepo()
+	fakeMsgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(fakeMsgPub), fakeTxManager{}, WithScheduleRepository(schedules))
+
+	startAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	created, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID: 7,
+		SourceURL:   "https://example.com/file.zip",
+		Schedule:    &ScheduleSpec{StartAt: &startAt},
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusScheduled, created.Status)
+	require.Empty(t, fakeMsgPub.msgs)
+
+	require.Len(t, schedules.schedules, 1)
+	sched := schedules.schedules[created.ScheduleID]
+	require.NotNil(t, sched)
+	require.Equal(t, uint64(123), sched.NextTaskID)
+	require.True(t, startAt.Equal(*sched.NextRunAt))
+	require.Equal(t, "https://example.com/file.zip", sched.Template.SourceURL)
+}
+
+func TestCreateTask_RejectsInvalidCron(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, WithScheduleRepository(newFakeScheduleRepo()))
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		SourceURL: "https://example.com/file.zip",
+		Schedule:  &ScheduleSpec{Cron: "every day"},
+	})
+	require.Error(t, err)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestRunDueSchedules_StartsRunAndQueuesNext(t *testing.T) {
+	due := time.Date(2025, 1, 31, 3, 0, 0, 0, time.UTC)
+	now := due.Add(10 * time.Second)
+
+	repo := &fakeRepo{task: &Task{ID: 50, OfAccountID: 7, Status: StatusScheduled}}
+	schedules := newFakeScheduleRepo()
+	schedules.schedules[1] = &Schedule{
+		ID:          1,
+		OfAccountID: 7,
+		Cron:        "0 3 * * *",
+		Enabled:     true,
+		NextRunAt:   &due,
+		NextTaskID:  50,
+		Template:    &CreateTaskParam{SourceURL: "https://example.com/nightly.zip"},
+	}
+	fakeMsgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(fakeMsgPub), fakeTxManager{}, WithScheduleRepository(schedules))
+	runner := svc.(ScheduleRunner)
+
+	ran, err := runner.RunDueSchedules(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, ran)
+
+	// The due run was handed to the workers.
+	require.Equal(t, "task.created", fakeMsgPub.topic)
+	require.Len(t, fakeMsgPub.msgs, 1)
+	require.Equal(t, uint64(50), repo.updated.ID)
+	require.Equal(t, StatusPending, repo.updated.Status)
+
+	// The next run exists as a new SCHEDULED task.
+	require.Equal(t, StatusScheduled, repo.created.Status)
+	require.Equal(t, uint64(1), repo.created.ScheduleID)
+	sched := schedules.schedules[1]
+	require.Equal(t, uint64(123), sched.NextTaskID)
+	require.Equal(t, due.AddDate(0, 0, 1), *sched.NextRunAt)
+	require.Equal(t, due, *sched.LastRunAt)
+
+	// Nothing is due any more.
+	ran, err = runner.RunDueSchedules(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 0, ran)
+}
+
+func TestRunDueSchedules_DisablesOneOffSchedule(t *testing.T) {
+	due := time.Now().Add(-time.Minute).UTC()
+
+	repo := &fakeRepo{task: &Task{ID: 50, OfAccountID: 7, Status: StatusScheduled}}
+	schedules := newFakeScheduleRepo()
+	schedules.schedules[1] = &Schedule{ID: 1, OfAccountID: 7, StartAt: &due, Enabled: true, NextRunAt: &due, NextTaskID: 50}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{}, WithScheduleRepository(schedules))
+
+	ran, err := svc.(ScheduleRunner).RunDueSchedules(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, ran)
+	require.Nil(t, repo.created)
+	require.False(t, schedules.schedules[1].Enabled)
+	require.Nil(t, schedules.schedules[1].NextRunAt)
+}
+
+func TestUpdateSchedule_DisablingDeletesPendingRun(t *testing.T) {
+	next := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	repo := &fakeRepo{task: &Task{ID: 50, OfAccountID: 7, Status: StatusScheduled}}
+	schedules := newFakeScheduleRepo()
+	schedules.schedules[1] = &Schedule{
+		ID:          1,
+		OfAccountID: 7,
+		Cron:        "0 3 * * *",
+		Enabled:     true,
+		NextRunAt:   &next,
+		NextTaskID:  50,
+	}
+	audit := &fakeAuditRepo{}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithScheduleRepository(schedules), WithAuditRepository(audit))
+
+	disabled := false
+	sched, err := svc.UpdateSchedule(context.Background(), &UpdateScheduleParam{ID: 1, Enabled: &disabled})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{50}, repo.deleted)
+	require.Len(t, audit.events, 1)
+	require.Equal(t, AuditTaskDelete, audit.events[0].Action)
+	require.Equal(t, uint64(50), audit.events[0].TaskID)
+	require.Equal(t, uint64(7), audit.events[0].OfAccountID)
+	require.Zero(t, sched.NextTaskID)
+	require.Nil(t, schedules.schedules[1].NextRunAt)
+	require.Zero(t, schedules.schedules[1].NextTaskID)
+}
+
+// commitTx records whether it is running a transaction.
+type commitTx struct {
+	inTx bool
+}
+
+func (tx *commitTx) DoInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx.inTx = true
+	defer func() { tx.inTx = false }()
+	return fn(ctx)
+}
+
+// txCheckingPublisher records whether messages were published inside tx.
+type txCheckingPublisher struct {
+	fakeMessagePublisher
+	tx          *commitTx
+	publishedTx bool
+}
+
+func (p *txCheckingPublisher) Publish(topic string, messages ...*message.Message) error {
+	p.publishedTx = p.publishedTx || p.tx.inTx
+	return p.fakeMessagePublisher.Publish(topic, messages...)
+}
+
+func TestRunDueSchedules_PublishesStartedRunAfterCommit(t *testing.T) {
+	due := time.Now().Add(-time.Minute).UTC()
+	repo := &fakeRepo{task: &Task{ID: 50, OfAccountID: 7, Status: StatusScheduled}}
+	schedules := newFakeScheduleRepo()
+	schedules.schedules[1] = &Schedule{ID: 1, OfAccountID: 7, StartAt: &due, Enabled: true, NextRunAt: &due, NextTaskID: 50}
+	tx := &commitTx{}
+	pub := &txCheckingPublisher{tx: tx}
+	svc := NewService(repo, *NewEventPublisher(pub), tx, WithScheduleRepository(schedules))
+
+	ran, err := svc.(ScheduleRunner).RunDueSchedules(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, ran)
+	require.Equal(t, []string{"task.created"}, pub.topics)
+	require.False(t, pub.publishedTx)
+}
@@ -0,0 +1,201 @@
+package task
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+// ScheduleRunner starts the runs of schedules that are due. It is implemented
+// by the service returned from NewService.
+type ScheduleRunner interface {
+	RunDueSchedules(ctx context.Context, now time.Time) (int, error)
+}
+
+const dueSchedulesBatch = 100
+
+var errScheduleClaimed = stderrors.New("schedule already run")
+
+// Scheduler periodically starts due scheduled tasks.
+type Scheduler struct {
+	runner   ScheduleRunner
+	interval time.Duration
+	logger   log.Logger
+}
+
+// SchedulerOption configures a Scheduler.
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerInterval sets how often due schedules are polled. Defaults to 30s.
+func WithSchedulerInterval(d time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// WithSchedulerLogger configures the scheduler logger.
+func WithSchedulerLogger(l log.Logger) SchedulerOption {
+	return func(s *Scheduler) { s.logger = l }
+}
+
+func NewScheduler(runner ScheduleRunner, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		runner:   runner,
+		interval: 30 * time.Second,
+		logger:   log.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Run polls for due schedules until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.runner.RunDueSchedules(ctx, time.Now()); err != nil {
+			level.Error(s.logger).Log("msg", "failed to run due schedules", "err", err)
+		} else if n > 0 {
+			level.Info(s.logger).Log("msg", "started scheduled tasks", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDueSchedules starts the pending run of every schedule due at now and
+// queues the following run of recurring schedules. It returns the number of
+// schedules that were run.
+func (s *service) RunDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	if s.schedules == nil {
+		return 0, nil
+	}
+	due, err := s.schedules.ListDueSchedules(ctx, now, dueSchedulesBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var ran int
+	for _, sched := range due {
+		if err := s.runSchedule(ctx, sched, now); err != nil {
+			if !stderrors.Is(err, errScheduleClaimed) {
+				level.Error(s.logger).Log("msg", "failed to run schedule", "schedule_id", sched.ID, "err", err)
+			}
+			continue
+		}
+		ran++
+	}
+	return ran, nil
+}
+
+func (s *service) runSchedule(ctx context.Context, sched *Schedule, now time.Time) error {
+	due := *sched.NextRunAt
+	startTaskID := sched.NextTaskID
+
+	// Missed runs are not caught up: a recurring schedule continues with its
+	// first match after now.
+	var next *time.Time
+	if sched.Cron != "" {
+		var err error
+		if next, err = nextRunAt(sched.Cron, nil, now); err != nil {
+			return err
+		}
+	}
+
+	var created, started *Task
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		sched.LastRunAt = &due
+		sched.NextRunAt = next
+		sched.NextTaskID = 0
+		if next != nil {
+			var err error
+			if created, err = s.repo.Create(ctx, sched.occurrence()); err != nil {
+				return err
+			}
+			sched.NextTaskID = created.ID
+		} else {
+			sched.Enabled = false
+		}
+
+		// Claim the run before starting it so that concurrent schedulers
+		// start it only once.
+		ok, err := s.schedules.UpdateSchedule(ctx, sched, &due)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errScheduleClaimed
+		}
+
+		if started, err = s.startScheduledTask(ctx, startTaskID); err != nil || started == nil {
+			return err
+		}
+		// The outbox stores the event in this transaction.
+		if s.pub.outbox != nil {
+			return s.pub.PublishTaskCreated(ctx, started)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if created != nil {
+		s.emitStatus(created)
+	}
+	if started != nil {
+		// Without an outbox the task is handed to the workers once it was
+		// committed, so that they never receive a task that was rolled back.
+		if s.pub.outbox == nil {
+			if err := s.pub.PublishTaskCreated(ctx, started); err != nil {
+				return err
+			}
+		}
+		s.emitStatus(started)
+		s.recordAudit(ctx, AuditTaskStart, started, "")
+	}
+	return nil
+}
+
+// startScheduledTask moves a SCHEDULED task to PENDING; the caller hands it to
+// the download workers. Tasks that were cancelled or deleted in the meantime
+// are skipped; tasks of accounts over their quota are failed. It returns the
+// started task, if any.
+func (s *service) startScheduledTask(ctx context.Context, id uint64) (*Task, error) {
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if t == nil || t.Status != StatusScheduled {
+		return nil, nil
+	}
+
+	if quotaErr := s.checkCreateQuota(ctx, t.OfAccountID, 1); quotaErr != nil {
+		if !errors.IsError(quotaErr, errors.ErrCodeTooManyRequests) {
+			return nil, quotaErr
+		}
+		return nil, s.UpdateTaskError(ctx, id, quotaErr)
+	}
+
+	t.Status = StatusPending
+	if _, err := s.repo.Update(ctx, &Task{ID: id, Status: StatusPending}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -9,6 +9,7 @@ import (
 	stderrors "errors"
 	"fmt"
 	"math"
+	"net/http"
 	"net/url"
 	"path/filepath"
 	"strings"
@@ -28,11 +29,20 @@ type Service interface {
 	GetTask(ctx context.Context, id uint64) (*Task, error)
 	ListTasks(ctx context.Context, param *ListTasksParam) (*ListTasksOutput, error)
 	DeleteTask(ctx context.Context, id uint64) error
+	// CreateTasks creates a batch of tasks in one transaction; either all of
+	// them are created or none.
+	CreateTasks(ctx context.Context, param *CreateTasksParam) ([]*Task, error)
+	// BulkTasks pauses, resumes, cancels or deletes the selected tasks of an
+	// account and reports the tasks the action did not apply to.
+	BulkTasks(ctx context.Context, param *BulkTaskParam) (*BulkTaskOutput, error)
 
 	PauseTask(ctx context.Context, taskID uint64) error
 	ResumeTask(ctx context.Context, taskID uint64) error
 	CancelTask(ctx context.Context, taskID uint64) error
 	RetryTask(ctx context.Context, taskID uint64) error
+	// ExtendTaskRetention keeps the stored file of a completed task for more
+	// days, counted from now.
+	ExtendTaskRetention(ctx context.Context, param *ExtendTaskRetentionParam) (*Task, error)
 
 	// Task updates (called by workers)
 	UpdateTaskStoragePath(ctx context.Context, id uint64, storagePath string) error
@@ -44,12 +54,39 @@ type Service interface {
 	UpdateTaskMetadata(ctx context.Context, id uint64, metadata map[string]any) error
 	UpdateFileName(ctx context.Context, id uint64, fileName string) error
 	UpdateStorageInfo(ctx context.Context, id uint64, storageType storage.Type, storagePath string) error
+	// UpdateSourceValidators records the ETag and Last-Modified the source
+	// reported for the downloaded content.
+	UpdateSourceValidators(ctx context.Context, id uint64, etag, lastModified string) error
+	// UpdateTaskWorker records the download worker that claimed the task.
+	UpdateTaskWorker(ctx context.Context, id uint64, workerID string) error
 
 	// File info and streaming
 	CheckFileExists(ctx context.Context, taskID uint64) (bool, error)
 
 	// Utility
 	GetTaskProgress(ctx context.Context, taskID uint64) (*DownloadProgress, error)
+	// GetUsage returns the consumption and quota of an account.
+	GetUsage(ctx context.Context, ofAccountID uint64) (*Usage, error)
+
+	// Schedules created by CreateTask with a ScheduleSpec
+	ListSchedules(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*Schedule, error)
+	GetSchedule(ctx context.Context, id uint64) (*Schedule, error)
+	UpdateSchedule(ctx context.Context, param *UpdateScheduleParam) (*Schedule, error)
+	DeleteSchedule(ctx context.Context, id uint64) error
+
+	// WatchTasks streams status and progress changes of the personal tasks
+	// of an account, or of the tasks of workspaceID when it is non-zero,
+	// resuming after lastEventID when it is non-zero.
+	WatchTasks(ctx context.Context, ofAccountID, workspaceID, lastEventID uint64) (<-chan *TaskEvent, error)
+
+	// Webhooks notify an account of task lifecycle events
+	CreateWebhook(ctx context.Context, param *CreateWebhookParam) (*Webhook, error)
+	ListWebhooks(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*Webhook, error)
+	GetWebhook(ctx context.Context, id uint64) (*Webhook, error)
+	UpdateWebhook(ctx context.Context, param *UpdateWebhookParam) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint64) error
+	ListWebhookDeliveries(ctx context.Context, webhookID uint64, limit, offset int32) ([]*WebhookDelivery, error)
+
 	// GenerateDownloadURL returns a URL clients can use to download the stored file.
 	// If direct is true, the URL is a presigned storage URL. If false, the URL
 	// points to a server-side download endpoint that will validate a token.
@@ -59,14 +96,34 @@ type Service interface {
 		ttl time.Duration,
 		oneTime bool,
 	) (url string, direct bool, err error)
+
+	// Audit log of actions taken on tasks
+	RecordAuditEvent(ctx context.Context, e *AuditEvent) error
+	// ListAuditEvents returns one page of the matching events, newest first.
+	ListAuditEvents(ctx context.Context, param *ListAuditEventsParam) ([]*AuditEvent, error)
 }
 
 type Repository interface {
 	Create(ctx context.Context, task *Task) (*Task, error)
 	GetByID(ctx context.Context, id uint64) (*Task, error)
 	Update(ctx context.Context, task *Task) (*Task, error)
-	ListByAccountID(ctx context.Context, filter TaskFilter, limit, offset uint32) ([]*Task, error)
-	GetTaskCountOfAccount(ctx context.Context, ofAccountID uint64) (uint64, error)
+	// ListByAccountID returns one page of the personal tasks of
+	// query.Filter.OfAccountID, or of the tasks of query.Filter.WorkspaceID
+	// when it is set, that match the filter, in the order of the query.
+	ListByAccountID(ctx context.Context, query TaskQuery) ([]*Task, error)
+	CountByAccountID(ctx context.Context, filter TaskFilter) (uint64, error)
+	// GetUsageOfAccount aggregates the tasks of an account; BytesToday counts
+	// tasks completed at or after since.
+	GetUsageOfAccount(ctx context.Context, ofAccountID uint64, since time.Time) (*Usage, error)
+	// ListExpired returns up to limit completed tasks whose stored file
+	// expired at or before now.
+	ListExpired(ctx context.Context, now time.Time, limit uint32) ([]*Task, error)
+	// FindReusable returns the most recently completed task of the account
+	// that matches query, or errors.ErrNotFound.
+	FindReusable(ctx context.Context, query ReusableQuery) (*Task, error)
+	// CountByStoragePath counts the tasks other than excludeID that still
+	// refer to the stored file at storagePath, i.e. that have not expired.
+	CountByStoragePath(ctx context.Context, storagePath string, excludeID uint64) (int64, error)
 	Delete(ctx context.Context, id uint64) error
 }
 
@@ -86,6 +143,28 @@ type service struct {
 	// token store for one-time tokens fallback
 	tokenStore TokenStore
 	logger     log.Logger
+	// quotas; zero values are unlimited
+	defaultQuota  Quota
+	accountQuotas map[uint64]Quota
+	// optional store for scheduled and recurring tasks
+	schedules ScheduleRepository
+	// task events for WatchTasks
+	watch *watchHub
+	// optional store for webhooks and their deliveries
+	webhooks            WebhookRepository
+	webhookClient       *http.Client
+	webhookAllowPrivate bool
+	webhookMaxAttempts  uint32
+	webhookRetryDelay   time.Duration
+	webhookQueued       chan struct{}
+	// limit of CreateTasks and BulkTasks
+	maxBatchSize int
+	// optional append-only audit log
+	audit AuditRepository
+	// retention of stored files; fileStore deletes the files of expired tasks
+	defaultExpirationDays int32
+	fileStore             storage.Writer
+	purgeExpired          bool
 }
 
 const bittorrentDataURLPrefix = "data:application/x-bittorrent;base64,"
@@ -170,10 +249,21 @@ func NewService(repo Repository, pub Publisher, tx TxManager, opts ...ServiceOpt
 		pub:    pub,
 		tx:     tx,
 		logger: log.NewNopLogger(),
+		watch:  newWatchHub(),
+
+		webhookMaxAttempts: defaultWebhookMaxAttempts,
+		webhookRetryDelay:  defaultWebhookRetryDelay,
+		webhookQueued:      make(chan struct{}, 1),
+		maxBatchSize:       defaultMaxBatchSize,
+
+		defaultExpirationDays: defaultExpirationDays,
 	}
 	for _, o := range opts {
 		o(s)
 	}
+	if s.webhookClient == nil {
+		s.webhookClient = newWebhookClient(s.webhookAllowPrivate)
+	}
 	return s
 }
 
@@ -190,9 +280,13 @@ func (s *service) GenerateDownloadURL(
 		return "", false, &errors.Error{Code: errors.ErrCodeNotFound, Message: "task not found", Cause: err}
 	}
 
-	if t.StoragePath == "" {
+	if t.Status == StatusExpired {
+		return "", false, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "stored file of task has expired"}
+	}
+	if t.Status != StatusCompleted || t.StoragePath == "" {
 		return "", false, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "task has no stored file"}
 	}
+	s.touchTask(ctx, t)
 
 	// Try presigner if available and oneTime == false
 	if s.presigner != nil && !oneTime {
@@ -213,6 +307,7 @@ func (s *service) GenerateDownloadURL(
 	meta := storage.TokenMetadata{
 		Key:     t.StoragePath,
 		OwnerID: t.OfAccountID,
+		TaskID:  t.ID,
 		OneTime: oneTime,
 		Expires: time.Now().Add(ttl),
 	}
@@ -230,19 +325,90 @@ func (s *service) GenerateDownloadURL(
 }
 
 func (s *service) CreateTask(ctx context.Context, param *CreateTaskParam) (*Task, error) {
+	priority, nextRun, err := s.validateCreateTask(param)
+	if err != nil {
+		return nil, err
+	}
+
+	if nextRun == nil {
+		if err := s.checkCreateQuota(ctx, param.OfAccountID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := s.newTask(ctx, param, priority)
+	if err != nil {
+		return nil, err
+	}
+
+	if nextRun != nil {
+		return s.createScheduledTask(ctx, task, param.Schedule, nextRun)
+	}
+
+	if param.ReuseExisting {
+		reused, err := s.reuseExistingTask(ctx, task)
+		if err != nil {
+			return nil, err
+		}
+		if reused != nil {
+			return reused, nil
+		}
+	}
+
+	created, err := s.createTasks(ctx, []*Task{task})
+	if err != nil {
+		return nil, err
+	}
+	return created[0], nil
+}
+
+// validateCreateTask checks param and returns the normalized priority of the
+// task. nextRun is set when the schedule of the task defers it.
+func (s *service) validateCreateTask(param *CreateTaskParam) (Priority, *time.Time, error) {
 	if param.SourceURL == "" {
-		return nil, &errors.Error{
+		return "", nil, &errors.Error{
 			Code:    errors.ErrCodeInvalidInput,
 			Message: "SourceURL is required",
 			Cause:   nil,
 		}
 	}
 
-	downloadOptions := &DownloadOptions{
-		Concurrency: 16,
-		MaxRetries:  3,
+	if err := validateChecksum(param.Checksum); err != nil {
+		return "", nil, err
+	}
+
+	if err := validateExpirationDays(param.ExpirationDays); err != nil {
+		return "", nil, err
+	}
+
+	priority, err := normalizePriority(param.Priority)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// A start time in the past without a cron expression starts immediately.
+	var nextRun *time.Time
+	if param.Schedule != nil {
+		if s.schedules == nil {
+			return "", nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "task scheduling not configured"}
+		}
+		if nextRun, err = nextRunAt(strings.TrimSpace(param.Schedule.Cron), param.Schedule.StartAt, time.Now()); err != nil {
+			return "", nil, err
+		}
+		if nextRun != nil && strings.HasPrefix(param.SourceURL, bittorrentDataURLPrefix) {
+			// Uploaded torrents are kept behind a short-lived presigned URL.
+			return "", nil, &errors.Error{
+				Code:    errors.ErrCodeInvalidInput,
+				Message: "uploaded torrent files cannot be scheduled; use a magnet or .torrent URL",
+			}
+		}
 	}
+	return priority, nextRun, nil
+}
 
+// newTask builds the pending task described by a validated param. Uploaded
+// torrent files are stored first and replaced by their presigned URL.
+func (s *service) newTask(ctx context.Context, param *CreateTaskParam, priority Priority) (*Task, error) {
 	if strings.HasPrefix(param.SourceURL, bittorrentDataURLPrefix) {
 		if s.taskSourceStore == nil || s.taskSourcePresigner == nil {
 			return nil, &errors.Error{
@@ -280,46 +446,103 @@ func (s *service) CreateTask(ctx context.Context, param *CreateTaskParam) (*Task
 		param.SourceType = ToSourceType(parseUrl.Scheme)
 	}
 
-	task := &Task{
+	expirationDays := param.ExpirationDays
+	if expirationDays == 0 {
+		expirationDays = s.defaultExpirationDays
+	}
+
+	return &Task{
 		FileName:        param.FileName,
 		OfAccountID:     param.OfAccountID,
+		WorkspaceID:     param.WorkspaceID,
 		SourceURL:       param.SourceURL,
 		SourceType:      param.SourceType,
 		SourceAuth:      param.SourceAuth,
 		Checksum:        param.Checksum,
-		DownloadOptions: downloadOptions,
+		DownloadOptions: defaultDownloadOptions(),
+		Priority:        priority,
 		Metadata:        param.Metadata,
 		Status:          StatusPending,
-	}
+		ExpirationDays:  expirationDays,
+	}, nil
+}
 
-	var createdTask *Task
+// createTasks stores tasks and publishes their TaskCreated events in one
+// transaction.
+func (s *service) createTasks(ctx context.Context, tasks []*Task) ([]*Task, error) {
+	created := make([]*Task, 0, len(tasks))
 	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
-		var err error
-		createdTask, err = s.repo.Create(ctx, task)
-		if err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "Failed to create task",
-				Cause:   err,
+		for _, task := range tasks {
+			createdTask, err := s.repo.Create(ctx, task)
+			if err != nil {
+				return &errors.Error{
+					Code:    errors.ErrCodeInternal,
+					Message: "Failed to create task",
+					Cause:   err,
+				}
 			}
-		}
 
-		// Publish TaskCreated event inside the same transaction. If publishing fails
-		// the transaction should be rolled back by returning an error here.
-		if err := s.pub.PublishTaskCreated(ctx, createdTask); err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "failed to publish task created event",
-				Cause:   err,
+			// Publish TaskCreated event inside the same transaction. If publishing fails
+			// the transaction should be rolled back by returning an error here.
+			if err := s.pub.PublishTaskCreated(ctx, createdTask); err != nil {
+				return &errors.Error{
+					Code:    errors.ErrCodeInternal,
+					Message: "failed to publish task created event",
+					Cause:   err,
+				}
 			}
+			created = append(created, createdTask)
 		}
 
 		return nil
 	}); err != nil {
 		return nil, err
 	}
+	for _, t := range created {
+		s.emitStatus(t)
+	}
+
+	return created, nil
+}
+
+func defaultDownloadOptions() *DownloadOptions {
+	return &DownloadOptions{
+		Concurrency: 16,
+		MaxRetries:  3,
+	}
+}
+
+// validateChecksum rejects checksum algorithms the download workers cannot verify.
+func validateChecksum(checksum *ChecksumInfo) error {
+	if checksum == nil || strings.TrimSpace(checksum.ChecksumValue) == "" {
+		return nil
+	}
+	switch normalizeChecksumType(checksum.ChecksumType) {
+	case "", "md5", "sha1", "sha256", "sha512", "crc32c":
+		return nil
+	default:
+		return &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("unsupported checksum type %q", checksum.ChecksumType),
+		}
+	}
+}
 
-	return createdTask, nil
+// normalizePriority defaults an empty priority to NORMAL and rejects unknown
+// priority classes.
+func normalizePriority(priority Priority) (Priority, error) {
+	p := Priority(strings.ToUpper(strings.TrimSpace(string(priority))))
+	switch p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityLow, PriorityNormal, PriorityHigh:
+		return p, nil
+	default:
+		return "", &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("unsupported priority %q", priority),
+		}
+	}
 }
 
 func (s *service) storeTaskSourceTorrentDataURL(
@@ -370,6 +593,7 @@ func (s *service) storeTaskSourceTorrentDataURL(
 		FileName:    baseName,
 		ContentType: "application/x-bittorrent",
 		ExpireAt:    expiresAt,
+		OwnerID:     ofAccountID,
 	}); err != nil {
 		return "", &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to store torrent source", Cause: err}
 	}
@@ -409,8 +633,45 @@ func (s *service) ListTasks(ctx context.Context, param *ListTasksParam) (*ListTa
 			Message: "limit and offset must be non-negative",
 		}
 	}
+	if param.Limit > maxListLimit {
+		return nil, &errors.Error{
+			Code:    errors.ErrCodeInvalidInput,
+			Message: fmt.Sprintf("limit must not exceed %d", maxListLimit),
+		}
+	}
+	if err := param.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	sortBy, err := ParseTaskSortField(param.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	query := TaskQuery{
+		Filter:  *param.Filter,
+		SortBy:  sortBy,
+		SortAsc: param.SortAsc,
+		Offset:  uint32(param.Offset),
+		Limit:   uint32(param.Limit),
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+	if param.Cursor != "" {
+		cursor, err := DecodeTaskCursor(param.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != sortBy || cursor.SortAsc != param.SortAsc {
+			return nil, &errors.Error{
+				Code:    errors.ErrCodeInvalidInput,
+				Message: "cursor belongs to a listing with a different sort order",
+			}
+		}
+		query.After = cursor
+		query.Offset = 0
+	}
 
-	total, err := s.repo.GetTaskCountOfAccount(ctx, param.Filter.OfAccountID)
+	total, err := s.repo.CountByAccountID(ctx, query.Filter)
 	if err != nil {
 		return nil, &errors.Error{
 			Code:    errors.ErrCodeInternal,
@@ -419,7 +680,7 @@ func (s *service) ListTasks(ctx context.Context, param *ListTasksParam) (*ListTa
 		}
 	}
 
-	tasks, err := s.repo.ListByAccountID(ctx, *param.Filter, uint32(param.Limit), uint32(param.Offset))
+	tasks, err := s.repo.ListByAccountID(ctx, query)
 	if err != nil {
 		return nil, &errors.Error{
 			Code:    errors.ErrCodeInternal,
@@ -435,12 +696,17 @@ func (s *service) ListTasks(ctx context.Context, param *ListTasksParam) (*ListTa
 		}
 	}
 
-	return &ListTasksOutput{Tasks: tasks, Total: int32(total)}, nil
+	out := &ListTasksOutput{Tasks: tasks, Total: int32(total)}
+	if len(tasks) > 0 && uint32(len(tasks)) == query.Limit {
+		out.NextCursor = NewTaskCursor(tasks[len(tasks)-1], sortBy, param.SortAsc).Encode()
+	}
+	return out, nil
 }
 
 func (s *service) DeleteTask(ctx context.Context, id uint64) error {
 	// Verify the task exists before attempting deletion to avoid silent no-ops.
-	if _, err := s.repo.GetByID(ctx, id); err != nil {
+	task, err := s.repo.GetByID(ctx, id)
+	if err != nil {
 		return &errors.Error{
 			Code:    errors.ErrCodeNotFound,
 			Message: "Task not found",
@@ -448,27 +714,59 @@ func (s *service) DeleteTask(ctx context.Context, id uint64) error {
 		}
 	}
 
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		return s.deleteTask(ctx, task)
+	}); err != nil {
+		return err
+	}
+	s.releaseDeletedTaskFile(ctx, task)
+	s.emit(&TaskEvent{
+		Type:        TaskEventDeleted,
+		TaskID:      id,
+		OfAccountID: task.OfAccountID,
+		WorkspaceID: task.WorkspaceID,
+	})
+
+	return nil
+}
+
+// deleteTask deletes the record of task. It runs in the transaction of its
+// caller; the stored file is released with releaseDeletedTaskFile once that
+// transaction committed.
+func (s *service) deleteTask(ctx context.Context, task *Task) error {
 	// We must tell the download service to stop the worker thread.
 	// We do this by publishing a TaskCancelled event.
-	// The download service listens for task.cancelled to stop running tasks.
-	if err := s.pub.PublishTaskCancelled(ctx, id); err != nil {
+	// The download worker that claimed the task listens for it to stop
+	// running tasks.
+	if err := s.pub.PublishTaskCancelled(ctx, task.ID, task.WorkerID); err != nil {
 		level.Warn(s.logger).
-			Log("msg", "Failed to publish task cancelled event during delete", "task_id", id, "err", err)
+			Log("msg", "Failed to publish task cancelled event during delete", "task_id", task.ID, "err", err)
 
 		// We still proceed to delete the record from the DB
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.repo.Delete(ctx, task.ID); err != nil {
 		return &errors.Error{
 			Code:    errors.ErrCodeInternal,
 			Message: "Failed to delete task",
 			Cause:   err,
 		}
 	}
-
 	return nil
 }
 
+// releaseDeletedTaskFile deletes the stored file of a deleted task. Content
+// shared with other tasks is kept by the storage backend until the last task
+// referring to it is deleted.
+func (s *service) releaseDeletedTaskFile(ctx context.Context, task *Task) {
+	if task.StoragePath == "" {
+		return
+	}
+	if err := s.releaseStoredFile(ctx, task); err != nil {
+		level.Warn(s.logger).Log("msg", "failed to delete stored file of task", "task_id", task.ID, "err", err)
+	}
+}
+
 func (s *service) PauseTask(ctx context.Context, taskID uint64) error {
 	task, err := s.repo.GetByID(ctx, taskID)
 	if err != nil {
@@ -479,6 +777,21 @@ func (s *service) PauseTask(ctx context.Context, taskID uint64) error {
 		}
 	}
 
+	if err := checkPauseTask(task); err != nil {
+		return err
+	}
+
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		return s.pauseTask(ctx, task)
+	}); err != nil {
+		return err
+	}
+	s.emitStatus(task)
+
+	return nil
+}
+
+func checkPauseTask(task *Task) error {
 	if task.Status != StatusDownloading {
 		return &errors.Error{
 			Code:    errors.ErrCodeInvalidInput,
@@ -486,30 +799,27 @@ func (s *service) PauseTask(ctx context.Context, taskID uint64) error {
 			Cause:   nil,
 		}
 	}
+	return nil
+}
 
+func (s *service) pauseTask(ctx context.Context, task *Task) error {
 	task.Status = StatusPaused
 
-	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
-		_, err := s.repo.Update(ctx, task)
-		if err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "Failed to pause task",
-				Cause:   err,
-			}
+	_, err := s.repo.Update(ctx, task)
+	if err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "Failed to pause task",
+			Cause:   err,
 		}
+	}
 
-		if err := s.pub.PublishTaskPaused(ctx, taskID); err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "failed to publish task paused event",
-				Cause:   err,
-			}
+	if err := s.pub.PublishTaskPaused(ctx, task.ID, task.WorkerID); err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "failed to publish task paused event",
+			Cause:   err,
 		}
-
-		return nil
-	}); err != nil {
-		return err
 	}
 
 	return nil
@@ -525,6 +835,21 @@ func (s *service) ResumeTask(ctx context.Context, taskID uint64) error {
 		}
 	}
 
+	if err := checkResumeTask(task); err != nil {
+		return err
+	}
+
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		return s.resumeTask(ctx, task)
+	}); err != nil {
+		return err
+	}
+	s.emitStatus(task)
+
+	return nil
+}
+
+func checkResumeTask(task *Task) error {
 	if task.Status != StatusPaused {
 		return &errors.Error{
 			Code:    errors.ErrCodeInvalidInput,
@@ -532,30 +857,27 @@ func (s *service) ResumeTask(ctx context.Context, taskID uint64) error {
 			Cause:   nil,
 		}
 	}
+	return nil
+}
 
+func (s *service) resumeTask(ctx context.Context, task *Task) error {
 	task.Status = StatusDownloading
 
-	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
-		_, err := s.repo.Update(ctx, task)
-		if err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "Failed to resume task",
-				Cause:   err,
-			}
+	_, err := s.repo.Update(ctx, task)
+	if err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "Failed to resume task",
+			Cause:   err,
 		}
+	}
 
-		if err := s.pub.PublishTaskResumed(ctx, taskID); err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "failed to publish task resumed event",
-				Cause:   err,
-			}
+	if err := s.pub.PublishTaskResumed(ctx, task.ID, task.WorkerID); err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "failed to publish task resumed event",
+			Cause:   err,
 		}
-
-		return nil
-	}); err != nil {
-		return err
 	}
 
 	return nil
@@ -571,37 +893,49 @@ func (s *service) CancelTask(ctx context.Context, taskID uint64) error {
 		}
 	}
 
-	if task.Status == StatusCompleted || task.Status == StatusCancelled {
+	if err := checkCancelTask(task); err != nil {
+		return err
+	}
+
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		return s.cancelTask(ctx, task)
+	}); err != nil {
+		return err
+	}
+	s.emitStatus(task)
+
+	return nil
+}
+
+func checkCancelTask(task *Task) error {
+	if task.Status == StatusCompleted || task.Status == StatusCancelled || task.Status == StatusExpired {
 		return &errors.Error{
 			Code:    errors.ErrCodeInvalidInput,
-			Message: "Completed or cancelled tasks cannot be cancelled",
+			Message: "Completed, cancelled or expired tasks cannot be cancelled",
 			Cause:   nil,
 		}
 	}
+	return nil
+}
 
+func (s *service) cancelTask(ctx context.Context, task *Task) error {
 	task.Status = StatusCancelled
 
-	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
-		_, err := s.repo.Update(ctx, task)
-		if err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "Failed to cancel task",
-				Cause:   err,
-			}
+	_, err := s.repo.Update(ctx, task)
+	if err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "Failed to cancel task",
+			Cause:   err,
 		}
+	}
 
-		if err := s.pub.PublishTaskCancelled(ctx, taskID); err != nil {
-			return &errors.Error{
-				Code:    errors.ErrCodeInternal,
-				Message: "failed to publish task cancelled event",
-				Cause:   err,
-			}
+	if err := s.pub.PublishTaskCancelled(ctx, task.ID, task.WorkerID); err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "failed to publish task cancelled event",
+			Cause:   err,
 		}
-
-		return nil
-	}); err != nil {
-		return err
 	}
 
 	return nil
@@ -625,6 +959,10 @@ func (s *service) RetryTask(ctx context.Context, taskID uint64) error {
 		}
 	}
 
+	var reason string
+	if task.ErrorMessage != nil {
+		reason = *task.ErrorMessage
+	}
 	task.Status = StatusPending
 
 	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
@@ -640,7 +978,7 @@ func (s *service) RetryTask(ctx context.Context, taskID uint64) error {
 		// Publish TaskCreated so the download worker re-picks the task.
 		// PublishTaskStatusUpdated is NOT consumed by the download service;
 		// only "task.created" events trigger execution.
-		if err := s.pub.PublishTaskCreated(ctx, task); err != nil {
+		if err := s.pub.PublishTaskRetried(ctx, task, reason); err != nil {
 			return &errors.Error{
 				Code:    errors.ErrCodeInternal,
 				Message: "failed to publish task created event for retry",
@@ -652,6 +990,7 @@ func (s *service) RetryTask(ctx context.Context, taskID uint64) error {
 	}); err != nil {
 		return err
 	}
+	s.emitStatus(task)
 
 	return nil
 }
@@ -707,6 +1046,21 @@ func (s *service) UpdateFileName(ctx context.Context, id uint64, fileName string
 	return nil
 }
 
+func (s *service) UpdateTaskWorker(ctx context.Context, id uint64, workerID string) error {
+	_, err := s.repo.Update(ctx, &Task{
+		ID:       id,
+		WorkerID: workerID,
+	})
+	if err != nil {
+		return &errors.Error{
+			Code:    errors.ErrCodeInternal,
+			Message: "update task worker failed",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
 func (s *service) UpdateTaskStatus(ctx context.Context, id uint64, status TaskStatus) error {
 	_, err := s.repo.Update(ctx, &Task{
 		ID:     id,
@@ -719,6 +1073,7 @@ func (s *service) UpdateTaskStatus(ctx context.Context, id uint64, status TaskSt
 			Cause:   err,
 		}
 	}
+	s.emitUpdate(ctx, &TaskEvent{Type: TaskEventStatus, TaskID: id, Status: status})
 	return nil
 }
 
@@ -734,6 +1089,7 @@ func (s *service) UpdateTaskProgress(ctx context.Context, id uint64, progress Do
 			Cause:   err,
 		}
 	}
+	s.emitUpdate(ctx, &TaskEvent{Type: TaskEventProgress, TaskID: id, Progress: &progress})
 	return nil
 }
 
@@ -754,16 +1110,50 @@ func (s *service) UpdateTaskError(ctx context.Context, id uint64, err error) err
 			Cause:   updateErr,
 		}
 	}
+	s.emitUpdate(ctx, &TaskEvent{Type: TaskEventStatus, TaskID: id, Status: StatusFailed, ErrorMessage: &errMsg})
 	return nil
 }
 
 func (s *service) CompleteTask(ctx context.Context, id uint64) error {
-	completedAt := time.Now()
+	t, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return &errors.Error{Code: errors.ErrCodeNotFound, Message: "task not found", Cause: err}
+		}
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "complete task failed", Cause: err}
+	}
 
-	_, err := s.repo.Update(ctx, &Task{
-		ID:          id,
-		Status:      StatusCompleted,
-		CompletedAt: &completedAt,
+	var totalBytes int64
+	if t.Progress != nil {
+		totalBytes = t.Progress.TotalBytes
+	}
+	if quotaErr := s.checkCompleteQuota(ctx, t.OfAccountID, totalBytes); quotaErr != nil {
+		if !errors.IsError(quotaErr, errors.ErrCodeTooManyRequests) {
+			return quotaErr
+		}
+		if err := s.UpdateTaskError(ctx, id, quotaErr); err != nil {
+			return err
+		}
+		// The bytes of a failed task do not count against the quota, so its
+		// file is not kept.
+		if err := s.releaseStoredFile(ctx, t); err != nil {
+			return &errors.Error{
+				Code:    errors.ErrCodeInternal,
+				Message: "delete stored file of task over quota failed",
+				Cause:   err,
+			}
+		}
+		s.recordAudit(ctx, AuditTaskReject, t, errorMessage(quotaErr))
+		return quotaErr
+	}
+
+	// The retention of the stored file counts from its completion.
+	completedAt := time.Now()
+	_, err = s.repo.Update(ctx, &Task{
+		ID:             id,
+		Status:         StatusCompleted,
+		CompletedAt:    &completedAt,
+		LastAccessedAt: &completedAt,
 	})
 	if err != nil {
 		return &errors.Error{
@@ -772,6 +1162,13 @@ func (s *service) CompleteTask(ctx context.Context, id uint64) error {
 			Cause:   err,
 		}
 	}
+	s.emit(&TaskEvent{
+		Type:        TaskEventStatus,
+		TaskID:      id,
+		OfAccountID: t.OfAccountID,
+		WorkspaceID: t.WorkspaceID,
+		Status:      StatusCompleted,
+	})
 	return nil
 }
 
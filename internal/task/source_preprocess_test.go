@@ -12,6 +12,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	apperrors "github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/pkg/message"
 )
@@ -26,7 +27,16 @@ type fakeRepo struct {
 	created *Task
 	updated *Task
 	listed  []*Task
+	query   TaskQuery
 	count   uint64
+	usage   *Usage
+	task    *Task
+	expired []*Task
+	deleted []uint64
+
+	reusable    *Task
+	reuseQuery  ReusableQuery
+	storageRefs int64
 }
 
 func (r *fakeRepo) Create(ctx context.Context, task *Task) (*Task, error) {
@@ -37,29 +47,59 @@ func (r *fakeRepo) Create(ctx context.Context, task *Task) (*Task, error) {
 	return &cloned, nil
 }
 
-func (r *fakeRepo) GetByID(ctx context.Context, id uint64) (*Task, error) { return nil, nil }
+func (r *fakeRepo) GetByID(ctx context.Context, id uint64) (*Task, error) { return r.task, nil }
 func (r *fakeRepo) Update(ctx context.Context, task *Task) (*Task, error) {
 	r.updated = task
 	return task, nil
 }
 
-func (r *fakeRepo) ListByAccountID(ctx context.Context, filter TaskFilter, limit, offset uint32) ([]*Task, error) {
+func (r *fakeRepo) ListByAccountID(ctx context.Context, query TaskQuery) ([]*Task, error) {
+	r.query = query
 	return r.listed, nil
 }
 
-func (r *fakeRepo) GetTaskCountOfAccount(ctx context.Context, ofAccountID uint64) (uint64, error) {
+func (r *fakeRepo) CountByAccountID(ctx context.Context, filter TaskFilter) (uint64, error) {
 	return r.count, nil
 }
-func (r *fakeRepo) Delete(ctx context.Context, id uint64) error { return nil }
+func (r *fakeRepo) Delete(ctx context.Context, id uint64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *fakeRepo) ListExpired(ctx context.Context, now time.Time, limit uint32) ([]*Task, error) {
+	return r.expired, nil
+}
+
+func (r *fakeRepo) FindReusable(ctx context.Context, query ReusableQuery) (*Task, error) {
+	r.reuseQuery = query
+	if r.reusable == nil {
+		return nil, apperrors.ErrNotFound
+	}
+	return r.reusable, nil
+}
+
+func (r *fakeRepo) CountByStoragePath(ctx context.Context, storagePath string, excludeID uint64) (int64, error) {
+	return r.storageRefs, nil
+}
+
+func (r *fakeRepo) GetUsageOfAccount(ctx context.Context, ofAccountID uint64, since time.Time) (*Usage, error) {
+	if r.usage == nil {
+		return &Usage{}, nil
+	}
+	u := *r.usage
+	return &u, nil
+}
 
 type fakeMessagePublisher struct {
 	topic  string
+	topics []string
 	msgs   []*message.Message
 	closed bool
 }
 
 func (p *fakeMessagePublisher) Publish(topic string, messages ...*message.Message) error {
 	p.topic = topic
+	p.topics = append(p.topics, topic)
 	p.msgs = append(p.msgs, messages...)
 	return nil
 }
@@ -203,3 +243,137 @@ func TestCreateTask_TorrentDataURLRequiresStorageConfig(t *testing.T) {
 	})
 	require.Error(t, err)
 }
+
+func TestCreateTask_DefaultsPriorityToNormal(t *testing.T) {
+	repo := &fakeRepo{}
+	fakeMsgPub := &fakeMessagePublisher{}
+	svc := NewService(repo, *NewEventPublisher(fakeMsgPub), fakeTxManager{})
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID: 7,
+		SourceURL:   "https://example.com/file.zip",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PriorityNormal, repo.created.Priority)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal(fakeMsgPub.msgs[0].Payload, &payload))
+	require.Equal(t, "NORMAL", payload["priority"])
+}
+
+func TestCreateTask_NormalizesAndValidatesPriority(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{})
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		SourceURL: "https://example.com/file.zip",
+		Priority:  "high",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PriorityHigh, repo.created.Priority)
+
+	_, err = svc.CreateTask(context.Background(), &CreateTaskParam{
+		SourceURL: "https://example.com/file.zip",
+		Priority:  "URGENT",
+	})
+	require.Error(t, err)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestCreateTask_RejectedWhenActiveTaskQuotaReached(t *testing.T) {
+	repo := &fakeRepo{usage: &Usage{ActiveTasks: 2}}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{},
+		WithDefaultQuota(Quota{MaxActiveTasks: 5}),
+		WithAccountQuota(7, Quota{MaxActiveTasks: 2}),
+	)
+
+	_, err := svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID: 7,
+		SourceURL:   "https://example.com/file.zip",
+	})
+	require.Error(t, err)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeTooManyRequests))
+	require.Nil(t, repo.created)
+
+	_, err = svc.CreateTask(context.Background(), &CreateTaskParam{
+		OfAccountID: 8,
+		SourceURL:   "https://example.com/file.zip",
+	})
+	require.NoError(t, err)
+}
+
+func TestCompleteTask_OverStorageQuotaMarksTaskFailed(t *testing.T) {
+	repo := &fakeRepo{
+		usage: &Usage{StoredBytes: 900},
+		task: &Task{
+			ID:          42,
+			OfAccountID: 7,
+			Status:      StatusStoring,
+			Progress:    &DownloadProgress{TotalBytes: 200},
+		},
+	}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithDefaultQuota(Quota{MaxStoredBytes: 1000}))
+
+	err := svc.CompleteTask(context.Background(), 42)
+	require.Error(t, err)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeTooManyRequests))
+	require.NotNil(t, repo.updated)
+	require.Equal(t, StatusFailed, repo.updated.Status)
+	require.Contains(t, *repo.updated.ErrorMessage, "storage quota")
+
+	repo.task.Progress.TotalBytes = 100
+	require.NoError(t, svc.CompleteTask(context.Background(), 42))
+	require.Equal(t, StatusCompleted, repo.updated.Status)
+}
+
+func TestCompleteTask_OverStorageQuotaDeletesStoredFile(t *testing.T) {
+	files := &fakeFileStore{}
+	repo := &fakeRepo{
+		usage: &Usage{StoredBytes: 900},
+		task: &Task{
+			ID:          42,
+			OfAccountID: 7,
+			Status:      StatusStoring,
+			StoragePath: "7/file.iso",
+			Progress:    &DownloadProgress{TotalBytes: 200},
+		},
+	}
+	audit := &fakeAuditRepo{}
+	svc := NewService(repo, Publisher{}, fakeTxManager{},
+		WithDefaultQuota(Quota{MaxStoredBytes: 1000}),
+		WithFileStore(files),
+		WithAuditRepository(audit),
+	)
+
+	err := svc.CompleteTask(context.Background(), 42)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeTooManyRequests))
+	require.Equal(t, []string{"7/file.iso"}, files.deleted)
+	require.Len(t, audit.events, 1)
+	require.Equal(t, AuditTaskReject, audit.events[0].Action)
+	require.Equal(t, uint64(42), audit.events[0].TaskID)
+	require.NotEmpty(t, audit.events[0].Message)
+
+	// A file other tasks still refer to is kept.
+	files.deleted = nil
+	repo.storageRefs = 1
+	err = svc.CompleteTask(context.Background(), 42)
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeTooManyRequests))
+	require.Empty(t, files.deleted)
+}
+
+func TestQuotaOptions_ParsesAccountOverrides(t *testing.T) {
+	opts, err := QuotaOptions(Quota{MaxActiveTasks: 3}, `{"42":{"max_stored_bytes":1024}}`)
+	require.NoError(t, err)
+
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, opts...)
+	usage, err := svc.GetUsage(context.Background(), 42)
+	require.NoError(t, err)
+	require.Equal(t, Quota{MaxStoredBytes: 1024}, usage.Quota)
+
+	usage, err = svc.GetUsage(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, Quota{MaxActiveTasks: 3}, usage.Quota)
+
+	_, err = QuotaOptions(Quota{}, `not-json`)
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	task "github.com/yuisofull/goload/internal/task"
+)
+
+// auditTimeLayout is fixed-width UTC with milliseconds, so that times
+// compare as text.
+const auditTimeLayout = "2006-01-02T15:04:05.000Z"
+
+type auditRepo struct {
+	taskRepo
+}
+
+func NewAuditRepo(pool *sqlitex.Pool) task.AuditRepository {
+	return &auditRepo{taskRepo{pool: pool}}
+}
+
+func (r *auditRepo) CreateAuditEvent(ctx context.Context, e *task.AuditEvent) error {
+	return r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`INSERT INTO task_audit_events (time, actor_account_id, actor_api_key_id, action, task_id, of_account_id,
+                               workspace_id, source_ip, outcome, message)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					e.Time.UTC().Format(auditTimeLayout), int64(e.ActorAccountID), int64(e.ActorAPIKeyID),
+					string(e.Action), int64(e.TaskID), int64(e.OfAccountID), int64(e.WorkspaceID), e.SourceIP,
+					string(e.Outcome), e.Message,
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		e.ID = uint64(conn.LastInsertRowID())
+		return nil
+	})
+}
+
+func (r *auditRepo) ListAuditEvents(ctx context.Context, query task.AuditQuery) ([]*task.AuditEvent, error) {
+	where, args := auditFilterWhere(query.Filter)
+	if query.BeforeID != 0 {
+		where += " AND id < ?"
+		args = append(args, int64(query.BeforeID))
+	}
+	args = append(args, int64(query.Limit))
+
+	var evs []*task.AuditEvent
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			fmt.Sprintf(`SELECT * FROM task_audit_events WHERE %s ORDER BY id DESC LIMIT ?`, where),
+			&sqlitex.ExecOptions{
+				Args: args,
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					evs = append(evs, scanAuditEvent(stmt))
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return evs, nil
+}
+
+// auditFilterWhere returns the WHERE clause selecting the events of filter.
+func auditFilterWhere(filter task.AuditFilter) (string, []any) {
+	conds := []string{"(actor_account_id = ? OR (of_account_id = ? AND workspace_id = 0))"}
+	args := []any{int64(filter.AccountID), int64(filter.AccountID)}
+	if filter.WorkspaceID != 0 {
+		conds = []string{"workspace_id = ?"}
+		args = []any{int64(filter.WorkspaceID)}
+	}
+	if filter.TaskID != 0 {
+		conds = append(conds, "task_id = ?")
+		args = append(args, int64(filter.TaskID))
+	}
+	if len(filter.Actions) > 0 {
+		conds = append(conds, "action IN ("+placeholders(len(filter.Actions))+")")
+		for _, a := range filter.Actions {
+			args = append(args, string(a))
+		}
+	}
+	if filter.Outcome != "" {
+		conds = append(conds, "outcome = ?")
+		args = append(args, string(filter.Outcome))
+	}
+	if filter.From != nil {
+		conds = append(conds, "time >= ?")
+		args = append(args, filter.From.UTC().Format(auditTimeLayout))
+	}
+	if filter.To != nil {
+		conds = append(conds, "time < ?")
+		args = append(args, filter.To.UTC().Format(auditTimeLayout))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func scanAuditEvent(stmt *sqlite.Stmt) *task.AuditEvent {
+	e := &task.AuditEvent{}
+	cols := make(map[string]int)
+	for i := range stmt.ColumnCount() {
+		cols[stmt.ColumnName(i)] = i
+	}
+
+	e.ID = uint64(stmt.ColumnInt64(cols["id"]))
+	e.Time, _ = time.Parse(time.RFC3339Nano, stmt.ColumnText(cols["time"]))
+	e.ActorAccountID = uint64(stmt.ColumnInt64(cols["actor_account_id"]))
+	e.ActorAPIKeyID = uint64(stmt.ColumnInt64(cols["actor_api_key_id"]))
+	e.Action = task.AuditAction(stmt.ColumnText(cols["action"]))
+	e.TaskID = uint64(stmt.ColumnInt64(cols["task_id"]))
+	e.OfAccountID = uint64(stmt.ColumnInt64(cols["of_account_id"]))
+	e.WorkspaceID = uint64(stmt.ColumnInt64(cols["workspace_id"]))
+	e.SourceIP = stmt.ColumnText(cols["source_ip"])
+	e.Outcome = task.AuditOutcome(stmt.ColumnText(cols["outcome"]))
+	e.Message = stmt.ColumnText(cols["message"])
+	return e
+}
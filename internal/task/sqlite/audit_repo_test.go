@@ -0,0 +1,54 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/pocketdb"
+	"github.com/yuisofull/goload/internal/task"
+	tasksqlite "github.com/yuisofull/goload/internal/task/sqlite"
+)
+
+func TestListAuditEvents_FiltersByTime(t *testing.T) {
+	pool := newTestPool(t)
+	repo := tasksqlite.NewAuditRepo(pool)
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, offset := range []time.Duration{0, 500 * time.Millisecond, time.Second} {
+		require.NoError(t, repo.CreateAuditEvent(ctx, &task.AuditEvent{
+			Time:        base.Add(offset),
+			Action:      task.AuditTaskDelete,
+			TaskID:      uint64(i + 1),
+			OfAccountID: 7,
+			Outcome:     task.AuditSucceeded,
+		}))
+	}
+
+	list := func(from, to time.Time) []uint64 {
+		evs, err := repo.ListAuditEvents(ctx, task.AuditQuery{
+			Filter: task.AuditFilter{AccountID: 7, From: &from, To: &to},
+			Limit:  10,
+		})
+		require.NoError(t, err)
+		var ids []uint64
+		for _, e := range evs {
+			ids = append(ids, e.TaskID)
+		}
+		return ids
+	}
+	require.Equal(t, []uint64{2, 1}, list(base, base.Add(time.Second)))
+	require.Equal(t, []uint64{3, 2}, list(base.Add(500*time.Millisecond), base.Add(2*time.Second)))
+
+	// Times written before they had a fixed width are rewritten on migration.
+	conn := pool.Get(ctx)
+	require.NoError(t, sqlitex.Execute(conn, `UPDATE task_audit_events SET time = '2026-03-01T12:00:00Z' WHERE task_id = 1`, nil))
+	pool.Put(conn)
+	require.Empty(t, list(base, base.Add(500*time.Millisecond)))
+	require.NoError(t, pocketdb.Migrate(pool))
+	require.Equal(t, []uint64{1}, list(base, base.Add(500*time.Millisecond)))
+}
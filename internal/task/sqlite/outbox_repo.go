@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	task "github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+type outboxRepo struct {
+	taskRepo
+	// drainMu serializes DrainOutbox; the database is not shared between
+	// processes.
+	drainMu sync.Mutex
+}
+
+func NewOutboxRepo(pool *sqlitex.Pool) task.OutboxRepository {
+	return &outboxRepo{taskRepo: taskRepo{pool: pool}}
+}
+
+func (r *outboxRepo) AddOutboxMessages(
+	ctx context.Context,
+	topic string,
+	taskID uint64,
+	msgs ...*message.Message,
+) error {
+	return r.withConn(ctx, func(conn *sqlite.Conn) error {
+		for _, msg := range msgs {
+			metadata, err := json.Marshal(msg.Metadata)
+			if err != nil {
+				return err
+			}
+			if err := sqlitex.Execute(
+				conn,
+				`INSERT INTO task_outbox (topic, task_id, message_uuid, payload, metadata, created_at)
+VALUES (?, ?, ?, ?, ?, ?);`,
+				&sqlitex.ExecOptions{
+					Args: []any{
+						topic, int64(taskID), msg.UUID, string(msg.Payload), string(metadata),
+						time.Now().UTC().Format(time.RFC3339Nano),
+					},
+				},
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DrainOutbox compares next_attempt_at as text; it is stored by formatTime,
+// so the comparison follows time.
+func (r *outboxRepo) DrainOutbox(
+	ctx context.Context,
+	limit int,
+	publish func(*task.OutboxMessage) error,
+) (int, error) {
+	r.drainMu.Lock()
+	defer r.drainMu.Unlock()
+
+	now := time.Now()
+	var (
+		msgs []*task.OutboxMessage
+		dead = make(map[uint64]error)
+	)
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, `SELECT * FROM task_outbox o
+WHERE o.dead_at IS NULL
+  AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= ?)
+  AND (o.task_id = 0 OR NOT EXISTS (
+    SELECT 1 FROM task_outbox b
+    WHERE b.task_id = o.task_id AND b.dead_at IS NULL AND b.next_attempt_at > ?))
+ORDER BY o.id LIMIT ?`, &sqlitex.ExecOptions{
+			Args: []any{formatTime(&now), formatTime(&now), limit},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				m, err := scanOutboxMessage(stmt)
+				if err != nil {
+					dead[m.ID] = err
+					return nil
+				}
+				msgs = append(msgs, m)
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	for id, cause := range dead {
+		if err := r.update(ctx, `UPDATE task_outbox SET dead_at = ?, last_error = ? WHERE id = ?`,
+			formatTime(&now), cause.Error(), int64(id)); err != nil {
+			return 0, err
+		}
+	}
+
+	published := 0
+	blocked := make(map[uint64]bool)
+	for _, m := range msgs {
+		if m.TaskID != 0 && blocked[m.TaskID] {
+			continue
+		}
+		if err := publish(m); err != nil {
+			blocked[m.TaskID] = m.TaskID != 0
+			next := now.Add(task.OutboxRetryDelay(m.Attempts + 1))
+			if err := r.update(ctx,
+				`UPDATE task_outbox SET attempts = attempts + 1, next_attempt_at = ?, last_error = ? WHERE id = ?`,
+				formatTime(&next), err.Error(), int64(m.ID)); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := r.update(ctx, `DELETE FROM task_outbox WHERE id = ?`, int64(m.ID)); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+func (r *outboxRepo) update(ctx context.Context, query string, args ...any) error {
+	return r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, query, &sqlitex.ExecOptions{Args: args})
+	})
+}
+
+func scanOutboxMessage(stmt *sqlite.Stmt) (*task.OutboxMessage, error) {
+	cols := make(map[string]int)
+	for i := range stmt.ColumnCount() {
+		cols[stmt.ColumnName(i)] = i
+	}
+
+	msg := message.NewMessage(stmt.ColumnText(cols["message_uuid"]), message.Payload(stmt.ColumnText(cols["payload"])))
+	m := &task.OutboxMessage{
+		ID:       uint64(stmt.ColumnInt64(cols["id"])),
+		Topic:    stmt.ColumnText(cols["topic"]),
+		TaskID:   uint64(stmt.ColumnInt64(cols["task_id"])),
+		Message:  msg,
+		Attempts: uint32(stmt.ColumnInt64(cols["attempts"])),
+	}
+	m.CreatedAt, _ = time.Parse(time.RFC3339Nano, stmt.ColumnText(cols["created_at"]))
+	if err := json.Unmarshal([]byte(stmt.ColumnText(cols["metadata"])), &msg.Metadata); err != nil {
+		// m still identifies the row, so that it can be dead-lettered.
+		return m, fmt.Errorf("unmarshal Metadata: %w", err)
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(message.Metadata)
+	}
+	return m, nil
+}
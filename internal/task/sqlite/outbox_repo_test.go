@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/task"
+	tasksqlite "github.com/yuisofull/goload/internal/task/sqlite"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+func TestDrainOutbox_DrainsOtherTasksWhileOneFails(t *testing.T) {
+	pool := newTestPool(t)
+	repo := tasksqlite.NewOutboxRepo(pool)
+	ctx := context.Background()
+
+	for _, m := range []struct {
+		taskID uint64
+		uuid   string
+	}{{1, "a1"}, {2, "b"}, {1, "a2"}, {3, "c"}, {4, "poison"}, {4, "d"}} {
+		require.NoError(t, repo.AddOutboxMessages(ctx, "task.created", m.taskID, message.NewMessage(m.uuid, nil)))
+	}
+	conn := pool.Get(ctx)
+	require.NoError(t, sqlitex.Execute(conn, `UPDATE task_outbox SET metadata = 'not json' WHERE message_uuid = 'poison'`, nil))
+	pool.Put(conn)
+
+	var handed []string
+	publish := func(m *task.OutboxMessage) error {
+		handed = append(handed, m.Message.UUID)
+		if m.TaskID == 1 {
+			return stderrors.New("broker unavailable")
+		}
+		return nil
+	}
+
+	published := 0
+	for range 4 {
+		n, err := repo.DrainOutbox(ctx, 2, publish)
+		require.NoError(t, err)
+		published += n
+	}
+	require.Equal(t, 3, published)
+	// a2 waits for a1, which waits for its retry, while the other tasks drain
+	// past it and past the undecodable message.
+	require.Equal(t, []string{"a1", "b", "c", "d"}, handed)
+
+	conn = pool.Get(ctx)
+	defer pool.Put(conn)
+	rows := map[string][2]string{}
+	require.NoError(t, sqlitex.Execute(conn, `SELECT message_uuid, attempts, dead_at FROM task_outbox`, &sqlitex.ExecOptions{
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			rows[stmt.ColumnText(0)] = [2]string{stmt.ColumnText(1), stmt.ColumnText(2)}
+			return nil
+		},
+	}))
+	require.Len(t, rows, 3)
+	require.Equal(t, "1", rows["a1"][0])
+	require.Equal(t, "0", rows["a2"][0])
+	require.NotEmpty(t, rows["poison"][1])
+}
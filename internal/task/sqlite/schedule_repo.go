@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	"github.com/yuisofull/goload/internal/errors"
+	task "github.com/yuisofull/goload/internal/task"
+)
+
+type scheduleRepo struct {
+	taskRepo
+}
+
+func NewScheduleRepo(pool *sqlitex.Pool) task.ScheduleRepository {
+	return &scheduleRepo{taskRepo{pool: pool}}
+}
+
+func (r *scheduleRepo) CreateSchedule(ctx context.Context, s *task.Schedule) (*task.Schedule, error) {
+	template, _ := json.Marshal(s.Template)
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`INSERT INTO task_schedules (of_account_id, cron_expr, start_at, enabled, next_run_at, last_run_at,
+                            next_task_id, template)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					s.OfAccountID, s.Cron, formatTime(s.StartAt), s.Enabled,
+					formatTime(s.NextRunAt), formatTime(s.LastRunAt), s.NextTaskID, template,
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		s.ID = uint64(conn.LastInsertRowID())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *scheduleRepo) GetSchedule(ctx context.Context, id uint64) (*task.Schedule, error) {
+	var s *task.Schedule
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, `SELECT * FROM task_schedules WHERE id = ?`, &sqlitex.ExecOptions{
+			Args: []any{id},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				s = scanSchedule(stmt)
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, errors.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *scheduleRepo) ListSchedulesOfAccount(
+	ctx context.Context,
+	ofAccountID uint64,
+	limit, offset uint32,
+) ([]*task.Schedule, error) {
+	return r.listSchedules(
+		ctx,
+		`SELECT * FROM task_schedules WHERE of_account_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+		ofAccountID, int64(limit), int64(offset),
+	)
+}
+
+func (r *scheduleRepo) ListDueSchedules(ctx context.Context, now time.Time, limit uint32) ([]*task.Schedule, error) {
+	return r.listSchedules(
+		ctx,
+		`SELECT * FROM task_schedules
+WHERE enabled = 1 AND next_run_at IS NOT NULL AND julianday(next_run_at) <= julianday(?)
+ORDER BY julianday(next_run_at) LIMIT ?`,
+		now.UTC().Format(time.RFC3339), int64(limit),
+	)
+}
+
+func (r *scheduleRepo) listSchedules(ctx context.Context, query string, args ...any) ([]*task.Schedule, error) {
+	var schedules []*task.Schedule
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+			Args: args,
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				schedules = append(schedules, scanSchedule(stmt))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+func (r *scheduleRepo) UpdateSchedule(ctx context.Context, s *task.Schedule, prevNextRunAt *time.Time) (bool, error) {
+	var updated bool
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`UPDATE task_schedules
+SET cron_expr = ?, start_at = ?, enabled = ?, next_run_at = ?, last_run_at = ?, next_task_id = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND next_run_at IS ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					s.Cron, formatTime(s.StartAt), s.Enabled, formatTime(s.NextRunAt), formatTime(s.LastRunAt),
+					s.NextTaskID, s.ID, formatTime(prevNextRunAt),
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		updated = conn.Changes() > 0
+		return nil
+	})
+	return updated, err
+}
+
+func (r *scheduleRepo) DeleteSchedule(ctx context.Context, id uint64) error {
+	return r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, `DELETE FROM task_schedules WHERE id = ?`, &sqlitex.ExecOptions{Args: []any{id}})
+	})
+}
+
+func scanSchedule(stmt *sqlite.Stmt) *task.Schedule {
+	s := &task.Schedule{}
+	cols := make(map[string]int)
+	for i := range stmt.ColumnCount() {
+		cols[stmt.ColumnName(i)] = i
+	}
+
+	s.ID = uint64(stmt.ColumnInt64(cols["id"]))
+	s.OfAccountID = uint64(stmt.ColumnInt64(cols["of_account_id"]))
+	s.Cron = stmt.ColumnText(cols["cron_expr"])
+	s.StartAt = scanNullTime(stmt, cols["start_at"])
+	s.Enabled = stmt.ColumnInt64(cols["enabled"]) != 0
+	s.NextRunAt = scanNullTime(stmt, cols["next_run_at"])
+	s.LastRunAt = scanNullTime(stmt, cols["last_run_at"])
+	s.NextTaskID = uint64(stmt.ColumnInt64(cols["next_task_id"]))
+
+	templateBytes := make([]byte, stmt.ColumnLen(cols["template"]))
+	stmt.ColumnBytes(cols["template"], templateBytes)
+	json.Unmarshal(templateBytes, &s.Template)
+
+	s.CreatedAt = parseSqliteTime(stmt.ColumnText(cols["created_at"]))
+	s.UpdatedAt = parseSqliteTime(stmt.ColumnText(cols["updated_at"]))
+	return s
+}
+
+func scanNullTime(stmt *sqlite.Stmt, col int) *time.Time {
+	if stmt.ColumnType(col) == sqlite.SQLITE_NULL {
+		return nil
+	}
+	t := parseSqliteTime(stmt.ColumnText(col))
+	return &t
+}
+
+// formatTime stores t the way the CAS in UpdateSchedule compares it: as
+// second-precision RFC3339 text, or NULL.
+func formatTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
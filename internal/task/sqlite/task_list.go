@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	task "github.com/yuisofull/goload/internal/task"
+)
+
+// timestampLayout is the format CURRENT_TIMESTAMP writes, so that bound
+// times compare as text against created_at and updated_at.
+const timestampLayout = "2006-01-02 15:04:05"
+
+var taskSortColumns = map[task.TaskSortField]string{
+	task.SortByCreatedAt: "created_at",
+	task.SortByUpdatedAt: "updated_at",
+	task.SortBySize:      "total_bytes",
+	task.SortByName:      "file_name",
+}
+
+func (r *taskRepo) ListByAccountID(ctx context.Context, query task.TaskQuery) ([]*task.Task, error) {
+	column, ok := taskSortColumns[query.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", query.SortBy)
+	}
+	where, args := taskFilterWhere(query.Filter)
+	op, dir := "<", "DESC"
+	if query.SortAsc {
+		op, dir = ">", "ASC"
+	}
+	if c := query.After; c != nil {
+		value := cursorValue(c)
+		where += fmt.Sprintf(" AND (%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", column, op)
+		args = append(args, value, value, int64(c.ID))
+	}
+	args = append(args, int64(query.Limit), int64(query.Offset))
+
+	var tasks []*task.Task
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			fmt.Sprintf(`SELECT * FROM tasks WHERE %s ORDER BY %s %s, id %s LIMIT ? OFFSET ?`, where, column, dir, dir),
+			&sqlitex.ExecOptions{
+				Args: args,
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					t, err := scanTask(stmt)
+					if err != nil {
+						return err
+					}
+					tasks = append(tasks, t)
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepo) CountByAccountID(ctx context.Context, filter task.TaskFilter) (uint64, error) {
+	where, args := taskFilterWhere(filter)
+	var count int64
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, `SELECT COUNT(*) FROM tasks WHERE `+where, &sqlitex.ExecOptions{
+			Args: args,
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				count = stmt.ColumnInt64(0)
+				return nil
+			},
+		})
+	})
+	return uint64(count), err
+}
+
+// taskFilterWhere returns the WHERE clause selecting the tasks of filter.
+// Tags must all be present in the metadata.tags array.
+func taskFilterWhere(filter task.TaskFilter) (string, []any) {
+	conds := []string{"of_account_id = ?", "workspace_id = 0"}
+	args := []any{int64(filter.OfAccountID)}
+	if filter.WorkspaceID != 0 {
+		conds = []string{"workspace_id = ?"}
+		args = []any{int64(filter.WorkspaceID)}
+	}
+	if len(filter.Status) > 0 {
+		conds = append(conds, "status IN ("+placeholders(len(filter.Status))+")")
+		for _, s := range filter.Status {
+			args = append(args, string(s))
+		}
+	}
+	if len(filter.SourceType) > 0 {
+		conds = append(conds, "source_type IN ("+placeholders(len(filter.SourceType))+")")
+		for _, s := range filter.SourceType {
+			args = append(args, string(s))
+		}
+	}
+	if r := filter.CreatedAt; r != nil {
+		if r.From != nil {
+			conds = append(conds, "created_at >= ?")
+			args = append(args, formatTimestamp(*r.From))
+		}
+		if r.To != nil {
+			conds = append(conds, "created_at < ?")
+			args = append(args, formatTimestamp(*r.To))
+		}
+	}
+	for _, tag := range filter.Tags {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(tasks.metadata, '$.tags') WHERE value = ?)")
+		args = append(args, tag)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		conds = append(conds, `(file_name LIKE ? ESCAPE '\' OR source_url LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func cursorValue(c *task.TaskCursor) any {
+	switch c.SortBy {
+	case task.SortByCreatedAt, task.SortByUpdatedAt:
+		return formatTimestamp(*c.Time)
+	case task.SortBySize:
+		return c.Size
+	default:
+		return c.Name
+	}
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -55,14 +55,17 @@ func (r *taskRepo) Create(ctx context.Context, t *task.Task) (*task.Task, error)
 			`INSERT INTO tasks (of_account_id, file_name, source_url, source_type, source_auth, headers,
                    storage_type, storage_path, status,
                    checksum_type, checksum_value,
-                   concurrency, max_speed, max_retries, timeout, metadata)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
+                   concurrency, max_speed, max_retries, timeout, metadata, priority,
+                   schedule_id, workspace_id, expiration_days, source_etag, source_last_modified)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`,
 			&sqlitex.ExecOptions{
 				Args: []any{
 					t.OfAccountID, t.FileName, t.SourceURL, string(t.SourceType), sourceAuth, headers,
 					string(t.StorageType), t.StoragePath, string(t.Status),
 					getChecksumType(t), getChecksumValue(t),
 					getConcurrency(t), getMaxSpeed(t), getMaxRetries(t), getTimeout(t), metadata,
+					getPriority(t), t.ScheduleID, t.WorkspaceID, t.ExpirationDays,
+					t.SourceETag, t.SourceLastModified,
 				},
 			},
 		)
@@ -140,6 +143,24 @@ func (r *taskRepo) updateInternal(ctx context.Context, t *task.Task) (*task.Task
 				return err
 			}
 		}
+		if t.LastAccessedAt != nil {
+			if err := sqlitex.Execute(
+				conn,
+				`UPDATE tasks SET last_accessed_at = ? WHERE id = ?`,
+				&sqlitex.ExecOptions{Args: []any{t.LastAccessedAt.UTC().Format(timestampLayout), t.ID}},
+			); err != nil {
+				return err
+			}
+		}
+		if t.ExpirationDays > 0 {
+			if err := sqlitex.Execute(
+				conn,
+				`UPDATE tasks SET expiration_days = ? WHERE id = ?`,
+				&sqlitex.ExecOptions{Args: []any{t.ExpirationDays, t.ID}},
+			); err != nil {
+				return err
+			}
+		}
 		if t.ErrorMessage != nil && *t.ErrorMessage != "" {
 			if err := sqlitex.Execute(
 				conn,
@@ -149,6 +170,33 @@ func (r *taskRepo) updateInternal(ctx context.Context, t *task.Task) (*task.Task
 				return err
 			}
 		}
+		if t.Checksum != nil && t.Checksum.ChecksumValue != "" {
+			if err := sqlitex.Execute(
+				conn,
+				`UPDATE tasks SET checksum_type = ?, checksum_value = ? WHERE id = ?`,
+				&sqlitex.ExecOptions{Args: []any{t.Checksum.ChecksumType, t.Checksum.ChecksumValue, t.ID}},
+			); err != nil {
+				return err
+			}
+		}
+		if t.SourceETag != "" || t.SourceLastModified != "" {
+			if err := sqlitex.Execute(
+				conn,
+				`UPDATE tasks SET source_etag = ?, source_last_modified = ? WHERE id = ?`,
+				&sqlitex.ExecOptions{Args: []any{t.SourceETag, t.SourceLastModified, t.ID}},
+			); err != nil {
+				return err
+			}
+		}
+		if t.WorkerID != "" {
+			if err := sqlitex.Execute(
+				conn,
+				`UPDATE tasks SET worker_id = ? WHERE id = ?`,
+				&sqlitex.ExecOptions{Args: []any{t.WorkerID, t.ID}},
+			); err != nil {
+				return err
+			}
+		}
 		if t.StorageType != "" || t.StoragePath != "" {
 			if err := sqlitex.Execute(
 				conn,
@@ -168,7 +216,12 @@ func (r *taskRepo) updateInternal(ctx context.Context, t *task.Task) (*task.Task
 				return err
 			}
 		}
-		return nil
+		// MySQL bumps updated_at on every change; SQLite needs it spelled out.
+		return sqlitex.Execute(
+			conn,
+			`UPDATE tasks SET updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			&sqlitex.ExecOptions{Args: []any{t.ID}},
+		)
 	})
 	if err != nil {
 		return nil, err
@@ -197,18 +250,45 @@ func (r *taskRepo) GetByID(ctx context.Context, id uint64) (*task.Task, error) {
 	return t, nil
 }
 
-func (r *taskRepo) ListByAccountID(
-	ctx context.Context,
-	filter task.TaskFilter,
-	limit, offset uint32,
-) ([]*task.Task, error) {
+func (r *taskRepo) GetUsageOfAccount(ctx context.Context, ofAccountID uint64, since time.Time) (*task.Usage, error) {
+	usage := &task.Usage{}
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT COALESCE(SUM(status IN ('PENDING', 'DOWNLOADING', 'STORING', 'PAUSED')), 0),
+       COALESCE(SUM(CASE WHEN status = 'COMPLETED' THEN total_bytes ELSE 0 END), 0),
+       COALESCE(SUM(CASE WHEN status = 'COMPLETED' AND julianday(completed_at) >= julianday(?)
+                         THEN total_bytes ELSE 0 END), 0)
+FROM tasks
+WHERE of_account_id = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{since.UTC().Format(time.RFC3339), ofAccountID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					usage.ActiveTasks = stmt.ColumnInt64(0)
+					usage.StoredBytes = stmt.ColumnInt64(1)
+					usage.BytesToday = stmt.ColumnInt64(2)
+					return nil
+				},
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (r *taskRepo) ListExpired(ctx context.Context, now time.Time, limit uint32) ([]*task.Task, error) {
 	var tasks []*task.Task
 	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
 		return sqlitex.Execute(
 			conn,
-			`SELECT * FROM tasks WHERE of_account_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+			`SELECT * FROM tasks
+WHERE status = 'COMPLETED' AND expiration_days > 0 AND last_accessed_at IS NOT NULL
+  AND julianday(last_accessed_at) + expiration_days <= julianday(?)
+ORDER BY id LIMIT ?`,
 			&sqlitex.ExecOptions{
-				Args: []any{filter.OfAccountID, int64(limit), int64(offset)},
+				Args: []any{now.UTC().Format(timestampLayout), int64(limit)},
 				ResultFunc: func(stmt *sqlite.Stmt) error {
 					t, err := scanTask(stmt)
 					if err != nil {
@@ -226,18 +306,58 @@ func (r *taskRepo) ListByAccountID(
 	return tasks, nil
 }
 
-func (r *taskRepo) GetTaskCountOfAccount(ctx context.Context, ofAccountID uint64) (uint64, error) {
-	var count int64
+func (r *taskRepo) FindReusable(ctx context.Context, query task.ReusableQuery) (*task.Task, error) {
+	var t *task.Task
 	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
-		return sqlitex.Execute(conn, `SELECT COUNT(*) FROM tasks WHERE of_account_id = ?`, &sqlitex.ExecOptions{
-			Args: []any{ofAccountID},
-			ResultFunc: func(stmt *sqlite.Stmt) error {
-				count = stmt.ColumnInt64(0)
-				return nil
+		return sqlitex.Execute(
+			conn,
+			`SELECT * FROM tasks
+WHERE of_account_id = ? AND status = 'COMPLETED' AND storage_path <> ''
+  AND ((? <> '' AND lower(checksum_value) = ?
+        AND (? = '' OR replace(lower(checksum_type), '-', '') = ?))
+    OR (? = '' AND source_url = ?
+        AND (source_etag <> '' OR source_last_modified <> '')))
+ORDER BY completed_at DESC, id DESC LIMIT 1`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					query.OfAccountID,
+					query.ChecksumValue, query.ChecksumValue,
+					query.ChecksumType, query.ChecksumType,
+					query.ChecksumValue, query.SourceURL,
+				},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					var err error
+					t, err = scanTask(stmt)
+					return err
+				},
 			},
-		})
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errors.ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *taskRepo) CountByStoragePath(ctx context.Context, storagePath string, excludeID uint64) (int64, error) {
+	var n int64
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`SELECT COUNT(*) FROM tasks WHERE storage_path = ? AND id <> ? AND status <> 'EXPIRED'`,
+			&sqlitex.ExecOptions{
+				Args: []any{storagePath, excludeID},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					n = stmt.ColumnInt64(0)
+					return nil
+				},
+			},
+		)
 	})
-	return uint64(count), err
+	return n, err
 }
 
 func (r *taskRepo) Delete(ctx context.Context, id uint64) error {
@@ -270,6 +390,9 @@ func scanTask(stmt *sqlite.Stmt) (*task.Task, error) {
 	t.StorageType = storage.TypeValue(stmt.ColumnText(cols["storage_type"]))
 	t.StoragePath = stmt.ColumnText(cols["storage_path"])
 	t.Status = task.TaskStatus(stmt.ColumnText(cols["status"]))
+	t.Priority = task.Priority(stmt.ColumnText(cols["priority"]))
+	t.ScheduleID = uint64(stmt.ColumnInt64(cols["schedule_id"]))
+	t.WorkspaceID = uint64(stmt.ColumnInt64(cols["workspace_id"]))
 
 	t.Progress = &task.DownloadProgress{
 		Progress:        stmt.ColumnFloat(cols["progress"]),
@@ -293,6 +416,14 @@ func scanTask(stmt *sqlite.Stmt) (*task.Task, error) {
 		ct := parseSqliteTime(stmt.ColumnText(cols["completed_at"]))
 		t.CompletedAt = &ct
 	}
+	t.ExpirationDays = int32(stmt.ColumnInt64(cols["expiration_days"]))
+	if stmt.ColumnType(cols["last_accessed_at"]) != sqlite.SQLITE_NULL {
+		at := parseSqliteTime(stmt.ColumnText(cols["last_accessed_at"]))
+		t.LastAccessedAt = &at
+	}
+	t.SourceETag = stmt.ColumnText(cols["source_etag"])
+	t.SourceLastModified = stmt.ColumnText(cols["source_last_modified"])
+	t.WorkerID = stmt.ColumnText(cols["worker_id"])
 
 	return t, nil
 }
@@ -347,6 +478,13 @@ func getMaxRetries(t *task.Task) any {
 	return nil
 }
 
+func getPriority(t *task.Task) any {
+	if t.Priority == "" {
+		return string(task.PriorityNormal)
+	}
+	return string(t.Priority)
+}
+
 func getTimeout(t *task.Task) any {
 	if t.DownloadOptions != nil && t.DownloadOptions.Timeout != nil {
 		return *t.DownloadOptions.Timeout
@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/pocketdb"
+	"github.com/yuisofull/goload/internal/task"
+	tasksqlite "github.com/yuisofull/goload/internal/task/sqlite"
+)
+
+func newTestPool(t *testing.T) *sqlitex.Pool {
+	t.Helper()
+	pool, err := sqlitex.Open(filepath.Join(t.TempDir(), "pocket.db"), 0, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { pool.Close() })
+	require.NoError(t, pocketdb.Migrate(pool))
+	return pool
+}
+
+func TestFindReusable_MatchesByChecksumOnlyWhenExpected(t *testing.T) {
+	repo := tasksqlite.NewTaskRepo(newTestPool(t))
+	ctx := context.Background()
+
+	stored, err := repo.Create(ctx, &task.Task{
+		OfAccountID:     7,
+		SourceURL:       "https://example.com/nightly.iso",
+		StoragePath:     "files/nightly",
+		Status:          task.StatusCompleted,
+		Checksum:        &task.ChecksumInfo{ChecksumType: "sha256", ChecksumValue: "aaaa"},
+		SourceETag:      `"v1"`,
+		DownloadOptions: &task.DownloadOptions{Concurrency: 1, MaxRetries: 3},
+	})
+	require.NoError(t, err)
+
+	// Same URL, but the new task expects other content.
+	_, err = repo.FindReusable(ctx, task.ReusableQuery{
+		OfAccountID:   7,
+		SourceURL:     "https://example.com/nightly.iso",
+		ChecksumType:  "sha256",
+		ChecksumValue: "bbbb",
+	})
+	require.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	found, err := repo.FindReusable(ctx, task.ReusableQuery{
+		OfAccountID:   7,
+		SourceURL:     "https://mirror.example.com/nightly.iso",
+		ChecksumType:  "sha256",
+		ChecksumValue: "aaaa",
+	})
+	require.NoError(t, err)
+	require.Equal(t, stored.ID, found.ID)
+
+	found, err = repo.FindReusable(ctx, task.ReusableQuery{
+		OfAccountID: 7,
+		SourceURL:   "https://example.com/nightly.iso",
+	})
+	require.NoError(t, err)
+	require.Equal(t, stored.ID, found.ID)
+}
@@ -0,0 +1,268 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sqlite "github.com/go-llsqlite/crawshaw"
+	"github.com/go-llsqlite/crawshaw/sqlitex"
+
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
+	task "github.com/yuisofull/goload/internal/task"
+)
+
+type webhookRepo struct {
+	taskRepo
+}
+
+func NewWebhookRepo(pool *sqlitex.Pool) task.WebhookRepository {
+	return &webhookRepo{taskRepo{pool: pool}}
+}
+
+func (r *webhookRepo) CreateWebhook(ctx context.Context, w *task.Webhook) (*task.Webhook, error) {
+	evs, _ := json.Marshal(w.Events)
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`INSERT INTO task_webhooks (of_account_id, url, secret, events, enabled) VALUES (?, ?, ?, ?, ?);`,
+			&sqlitex.ExecOptions{
+				Args: []any{w.OfAccountID, w.URL, w.Secret, evs, w.Enabled},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		w.ID = uint64(conn.LastInsertRowID())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *webhookRepo) GetWebhook(ctx context.Context, id uint64) (*task.Webhook, error) {
+	webhooks, err := r.listWebhooks(ctx, `SELECT * FROM task_webhooks WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) == 0 {
+		return nil, errors.ErrNotFound
+	}
+	return webhooks[0], nil
+}
+
+func (r *webhookRepo) ListWebhooksOfAccount(
+	ctx context.Context,
+	ofAccountID uint64,
+	limit, offset uint32,
+) ([]*task.Webhook, error) {
+	return r.listWebhooks(
+		ctx,
+		`SELECT * FROM task_webhooks WHERE of_account_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?`,
+		ofAccountID, int64(limit), int64(offset),
+	)
+}
+
+func (r *webhookRepo) ListEnabledWebhooksOfAccount(ctx context.Context, ofAccountID uint64) ([]*task.Webhook, error) {
+	return r.listWebhooks(ctx, `SELECT * FROM task_webhooks WHERE of_account_id = ? AND enabled = 1`, ofAccountID)
+}
+
+func (r *webhookRepo) listWebhooks(ctx context.Context, query string, args ...any) ([]*task.Webhook, error) {
+	var webhooks []*task.Webhook
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+			Args: args,
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				webhooks = append(webhooks, scanWebhook(stmt))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepo) UpdateWebhook(ctx context.Context, w *task.Webhook) error {
+	evs, _ := json.Marshal(w.Events)
+	return r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(
+			conn,
+			`UPDATE task_webhooks
+SET url = ?, secret = ?, events = ?, enabled = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?`,
+			&sqlitex.ExecOptions{Args: []any{w.URL, w.Secret, evs, w.Enabled, w.ID}},
+		)
+	})
+}
+
+func (r *webhookRepo) DeleteWebhook(ctx context.Context, id uint64) error {
+	return r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`DELETE FROM task_webhook_deliveries WHERE webhook_id = ?`,
+			&sqlitex.ExecOptions{Args: []any{id}},
+		)
+		if err != nil {
+			return err
+		}
+		return sqlitex.Execute(conn, `DELETE FROM task_webhooks WHERE id = ?`, &sqlitex.ExecOptions{Args: []any{id}})
+	})
+}
+
+func (r *webhookRepo) CreateWebhookDelivery(ctx context.Context, d *task.WebhookDelivery) (bool, error) {
+	var created bool
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`INSERT OR IGNORE INTO task_webhook_deliveries (webhook_id, event_id, event, task_id, payload, status,
+                                                attempts, next_attempt_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					d.WebhookID, d.EventID, string(d.Event), d.TaskID, string(d.Payload), string(d.Status),
+					d.Attempts, formatTime(d.NextAttemptAt),
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if conn.Changes() == 0 {
+			return nil
+		}
+		created = true
+		d.ID = uint64(conn.LastInsertRowID())
+		return nil
+	})
+	return created, err
+}
+
+func (r *webhookRepo) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uint64,
+	limit, offset uint32,
+) ([]*task.WebhookDelivery, error) {
+	return r.listDeliveries(
+		ctx,
+		`SELECT * FROM task_webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`,
+		webhookID, int64(limit), int64(offset),
+	)
+}
+
+func (r *webhookRepo) ListDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit uint32,
+) ([]*task.WebhookDelivery, error) {
+	return r.listDeliveries(
+		ctx,
+		`SELECT * FROM task_webhook_deliveries
+WHERE status = 'PENDING' AND next_attempt_at IS NOT NULL AND julianday(next_attempt_at) <= julianday(?)
+ORDER BY julianday(next_attempt_at) LIMIT ?`,
+		now.UTC().Format(time.RFC3339), int64(limit),
+	)
+}
+
+func (r *webhookRepo) listDeliveries(ctx context.Context, query string, args ...any) ([]*task.WebhookDelivery, error) {
+	var deliveries []*task.WebhookDelivery
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+			Args: args,
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				deliveries = append(deliveries, scanWebhookDelivery(stmt))
+				return nil
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepo) UpdateWebhookDelivery(
+	ctx context.Context,
+	d *task.WebhookDelivery,
+	prevNextAttemptAt *time.Time,
+) (bool, error) {
+	var lastError any
+	if d.LastError != nil {
+		lastError = *d.LastError
+	}
+	var updated bool
+	err := r.withConn(ctx, func(conn *sqlite.Conn) error {
+		err := sqlitex.Execute(
+			conn,
+			`UPDATE task_webhook_deliveries
+SET status = ?, attempts = ?, next_attempt_at = ?, response_code = ?, last_error = ?, delivered_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND next_attempt_at IS ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{
+					string(d.Status), d.Attempts, formatTime(d.NextAttemptAt), d.ResponseCode, lastError,
+					formatTime(d.DeliveredAt), d.ID, formatTime(prevNextAttemptAt),
+				},
+			},
+		)
+		if err != nil {
+			return err
+		}
+		updated = conn.Changes() > 0
+		return nil
+	})
+	return updated, err
+}
+
+func scanWebhook(stmt *sqlite.Stmt) *task.Webhook {
+	w := &task.Webhook{}
+	cols := make(map[string]int)
+	for i := range stmt.ColumnCount() {
+		cols[stmt.ColumnName(i)] = i
+	}
+
+	w.ID = uint64(stmt.ColumnInt64(cols["id"]))
+	w.OfAccountID = uint64(stmt.ColumnInt64(cols["of_account_id"]))
+	w.URL = stmt.ColumnText(cols["url"])
+	w.Secret = stmt.ColumnText(cols["secret"])
+
+	eventsBytes := make([]byte, stmt.ColumnLen(cols["events"]))
+	stmt.ColumnBytes(cols["events"], eventsBytes)
+	json.Unmarshal(eventsBytes, &w.Events)
+
+	w.Enabled = stmt.ColumnInt64(cols["enabled"]) != 0
+	w.CreatedAt = parseSqliteTime(stmt.ColumnText(cols["created_at"]))
+	w.UpdatedAt = parseSqliteTime(stmt.ColumnText(cols["updated_at"]))
+	return w
+}
+
+func scanWebhookDelivery(stmt *sqlite.Stmt) *task.WebhookDelivery {
+	d := &task.WebhookDelivery{}
+	cols := make(map[string]int)
+	for i := range stmt.ColumnCount() {
+		cols[stmt.ColumnName(i)] = i
+	}
+
+	d.ID = uint64(stmt.ColumnInt64(cols["id"]))
+	d.WebhookID = uint64(stmt.ColumnInt64(cols["webhook_id"]))
+	d.EventID = stmt.ColumnText(cols["event_id"])
+	d.Event = events.EventType(stmt.ColumnText(cols["event"]))
+	d.TaskID = uint64(stmt.ColumnInt64(cols["task_id"]))
+	d.Payload = []byte(stmt.ColumnText(cols["payload"]))
+	d.Status = task.WebhookDeliveryStatus(stmt.ColumnText(cols["status"]))
+	d.Attempts = uint32(stmt.ColumnInt64(cols["attempts"]))
+	d.NextAttemptAt = scanNullTime(stmt, cols["next_attempt_at"])
+	d.ResponseCode = int32(stmt.ColumnInt64(cols["response_code"]))
+	if stmt.ColumnType(cols["last_error"]) != sqlite.SQLITE_NULL {
+		msg := stmt.ColumnText(cols["last_error"])
+		d.LastError = &msg
+	}
+	d.DeliveredAt = scanNullTime(stmt, cols["delivered_at"])
+	d.CreatedAt = parseSqliteTime(stmt.ColumnText(cols["created_at"]))
+	d.UpdatedAt = parseSqliteTime(stmt.ColumnText(cols["updated_at"]))
+	return d
+}
@@ -2,13 +2,14 @@ package tasktransport
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 
 	"github.com/yuisofull/goload/internal/errors"
 	"github.com/yuisofull/goload/internal/events"
 	"github.com/yuisofull/goload/internal/storage"
 	"github.com/yuisofull/goload/internal/task"
 	"github.com/yuisofull/goload/pkg/message"
+	"github.com/yuisofull/goload/pkg/message/middleware"
 )
 
 // EventConsumer handles events from other services
@@ -33,86 +34,56 @@ func NewEventConsumer(
 
 // Start begins consuming events
 func (ec *EventConsumer) Start(ctx context.Context) error {
-	progressCh, err := ec.subscriber.Subscribe(ctx, "task.progress.updated")
-	if err != nil {
-		return err
-	}
-
-	completedCh, err := ec.subscriber.Subscribe(ctx, "task.completed")
-	if err != nil {
-		return err
-	}
-
-	failedCh, err := ec.subscriber.Subscribe(ctx, "task.failed")
-	if err != nil {
-		return err
-	}
-
-	// Start goroutines to handle each event type
-	go ec.handleProgressUpdates(ctx, progressCh)
-	go ec.handleCompletions(ctx, completedCh)
-	go ec.handleFailures(ctx, failedCh)
-
-	// Block until context is canceled.
-	<-ctx.Done()
-	return ctx.Err()
+	return ec.newRouter().Run(ctx)
 }
 
-// handleProgressUpdates processes progress update messages
-func (ec *EventConsumer) handleProgressUpdates(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		if err := ec.handleTaskProgressUpdated(ctx, msg); err != nil {
-			if errors.IsError(err, errors.ErrCodeNotFound) {
-				msg.Ack()
-				continue
-			}
-			ec.errorHandler(ctx, err)
-			msg.Nack()
-		} else {
-			msg.Ack()
-		}
-	}
+// newRouter routes the events of the download service to the task service.
+func (ec *EventConsumer) newRouter() *message.Router {
+	r := message.NewRouter(ec.subscriber)
+	r.Use(middleware.Recoverer, middleware.CorrelationID, ec.handleErrors)
+
+	r.AddHandler("task.progress_updated", "task.progress.updated", message.JSONHandler(ec.handleTaskProgressUpdated))
+	r.AddHandler("task.completed", "task.completed", message.JSONHandler(ec.handleTaskCompleted))
+	r.AddHandler("task.failed", "task.failed", message.JSONHandler(ec.handleTaskFailed))
+	r.AddHandler("task.claimed", string(events.EventTaskClaimed), message.JSONHandler(ec.handleTaskClaimed))
+	r.AddHandler(
+		"task.control_acked",
+		string(events.EventTaskControlAcked),
+		message.JSONHandler(ec.handleTaskControlAcked),
+	)
+
+	// Files removed by the retention of the storage backend expire their task.
+	if runner, ok := ec.taskService.(task.ExpiryRunner); ok {
+		r.AddHandler(
+			"task.file_expired",
+			string(events.EventTaskFileExpired),
+			message.JSONHandler(func(ctx context.Context, msg *message.Message, event events.TaskFileExpiredEvent) error {
+				return runner.ExpireTask(ctx, event.TaskID)
+			}),
+		)
+	}
+	return r
 }
 
-// handleCompletions processes completion messages
-func (ec *EventConsumer) handleCompletions(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		if err := ec.handleTaskCompleted(ctx, msg); err != nil {
-			if errors.IsError(err, errors.ErrCodeNotFound) {
-				msg.Ack()
-				continue
-			}
-			ec.errorHandler(ctx, err)
-			msg.Nack()
-		} else {
-			msg.Ack()
-		}
-	}
-}
-
-// handleFailures processes failure messages
-func (ec *EventConsumer) handleFailures(ctx context.Context, ch <-chan *message.Message) {
-	for msg := range ch {
-		if err := ec.handleTaskFailed(ctx, msg); err != nil {
-			if errors.IsError(err, errors.ErrCodeNotFound) {
-				msg.Ack()
-				continue
-			}
-			ec.errorHandler(ctx, err)
-			msg.Nack()
-		} else {
-			msg.Ack()
+// handleErrors acks the events of tasks that no longer exist and reports the
+// other errors the events are nacked with.
+func (ec *EventConsumer) handleErrors(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) error {
+		err := h(msg)
+		if err == nil || errors.IsError(err, errors.ErrCodeNotFound) {
+			return nil
 		}
+		ec.errorHandler(msg.Context(), err)
+		return err
 	}
 }
 
 // handleTaskProgressUpdated processes progress updates from download service
-func (ec *EventConsumer) handleTaskProgressUpdated(ctx context.Context, msg *message.Message) error {
-	var event events.TaskProgressUpdatedEvent
-	if err := json.Unmarshal(msg.Payload, &event); err != nil {
-		return err
-	}
-
+func (ec *EventConsumer) handleTaskProgressUpdated(
+	ctx context.Context,
+	msg *message.Message,
+	event events.TaskProgressUpdatedEvent,
+) error {
 	// Update task progress in the database
 	progress := task.DownloadProgress{
 		Progress:        event.Progress,
@@ -128,14 +99,41 @@ func (ec *EventConsumer) handleTaskProgressUpdated(ctx context.Context, msg *mes
 }
 
 // handleTaskCompleted processes task completion events from download service
-func (ec *EventConsumer) handleTaskCompleted(ctx context.Context, msg *message.Message) error {
-	var event events.TaskCompletedEvent
-	if err := json.Unmarshal(msg.Payload, &event); err != nil {
-		return err
+func (ec *EventConsumer) handleTaskCompleted(
+	ctx context.Context,
+	msg *message.Message,
+	event events.TaskCompletedEvent,
+) error {
+	// The size is recorded first so that CompleteTask can check it against the
+	// account's storage quota.
+	if event.FileSize > 0 {
+		if err := ec.taskService.UpdateTaskProgress(ctx, event.TaskID, task.DownloadProgress{
+			TotalBytes: event.FileSize,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// The stored object is recorded before completion so that it can be
+	// deleted when the completion exceeds the quota.
+	if event.StorageType != "" || event.StorageKey != "" {
+		if err := ec.taskService.UpdateStorageInfo(
+			ctx,
+			event.TaskID,
+			storage.TypeValue(event.StorageType),
+			event.StorageKey,
+		); err != nil {
+			return err
+		}
 	}
 
 	if err := ec.taskService.CompleteTask(ctx, event.TaskID); err != nil {
-		ec.errorHandler(ctx, err)
+		// An account over its quota gets the task marked failed and its
+		// stored object deleted.
+		if errors.IsError(err, errors.ErrCodeTooManyRequests) {
+			ec.errorHandler(ctx, err)
+			return nil
+		}
 		return err
 	}
 
@@ -155,36 +153,21 @@ func (ec *EventConsumer) handleTaskCompleted(ctx context.Context, msg *message.M
 		}
 	}
 
-	if event.StorageType != "" || event.StorageKey != "" {
-		if err := ec.taskService.UpdateStorageInfo(
-			ctx,
-			event.TaskID,
-			storage.TypeValue(event.StorageType),
-			event.StorageKey,
-		); err != nil {
-			return err
-		}
-	}
-
-	if event.FileSize > 0 {
-		if err := ec.taskService.UpdateTaskProgress(ctx, event.TaskID, task.DownloadProgress{
-			TotalBytes: event.FileSize,
-		}); err != nil {
-			return err
-		}
+	if err := ec.taskService.UpdateSourceValidators(
+		ctx, event.TaskID, event.SourceETag, event.SourceLastModified,
+	); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 // handleTaskFailed processes task failure events from download service
-func (ec *EventConsumer) handleTaskFailed(ctx context.Context, msg *message.Message) error {
-	var event events.TaskFailedEvent
-	if err := json.Unmarshal(msg.Payload, &event); err != nil {
-		ec.errorHandler(ctx, err)
-		return err
-	}
-
+func (ec *EventConsumer) handleTaskFailed(
+	ctx context.Context,
+	msg *message.Message,
+	event events.TaskFailedEvent,
+) error {
 	// Create error from the event
 	taskErr := &errors.Error{
 		Code:    errors.ErrCodeInternal,
@@ -197,3 +180,38 @@ func (ec *EventConsumer) handleTaskFailed(ctx context.Context, msg *message.Mess
 
 	return nil
 }
+
+// handleTaskClaimed records the download worker that picked a task up, to
+// which the control events of the task are published from then on
+func (ec *EventConsumer) handleTaskClaimed(
+	ctx context.Context,
+	msg *message.Message,
+	event events.TaskClaimedEvent,
+) error {
+	return ec.taskService.UpdateTaskWorker(ctx, event.TaskID, event.WorkerID)
+}
+
+// handleTaskControlAcked reports the pause, resume and cancel requests the
+// owning download worker could not apply. The task keeps the status the
+// request set.
+func (ec *EventConsumer) handleTaskControlAcked(
+	ctx context.Context,
+	msg *message.Message,
+	event events.TaskControlAckedEvent,
+) error {
+	if event.Error == "" {
+		return nil
+	}
+	code := errors.Code(event.ErrorCode)
+	if code == "" {
+		code = errors.ErrCodeInternal
+	}
+	ec.errorHandler(ctx, &errors.Error{
+		Code: code,
+		Message: fmt.Sprintf(
+			"worker %s could not apply %s to task %d: %s",
+			event.WorkerID, event.Command, event.TaskID, event.Error,
+		),
+	})
+	return nil
+}
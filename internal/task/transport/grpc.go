@@ -3,6 +3,7 @@ package tasktransport
 import (
 	"context"
 
+	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/transport"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"github.com/go-kit/log"
@@ -26,6 +27,7 @@ type grpcServer struct {
 	resumeTask            grpctransport.Handler
 	cancelTask            grpctransport.Handler
 	retryTask             grpctransport.Handler
+	extendTaskRetention   grpctransport.Handler
 	updateTaskStoragePath grpctransport.Handler
 	updateTaskStatus      grpctransport.Handler
 	updateTaskProgress    grpctransport.Handler
@@ -36,6 +38,23 @@ type grpcServer struct {
 	checkFileExists       grpctransport.Handler
 	getTaskProgress       grpctransport.Handler
 	generateDownloadURL   grpctransport.Handler
+	getUsage              grpctransport.Handler
+	listSchedules         grpctransport.Handler
+	getSchedule           grpctransport.Handler
+	updateSchedule        grpctransport.Handler
+	deleteSchedule        grpctransport.Handler
+	createWebhook         grpctransport.Handler
+	listWebhooks          grpctransport.Handler
+	getWebhook            grpctransport.Handler
+	updateWebhook         grpctransport.Handler
+	deleteWebhook         grpctransport.Handler
+	listWebhookDeliveries grpctransport.Handler
+	createTasks           grpctransport.Handler
+	bulkTasks             grpctransport.Handler
+	recordAuditEvent      grpctransport.Handler
+	listAuditEvents       grpctransport.Handler
+	// go-kit has no streaming transport; the endpoint returns the event channel.
+	watchTasks endpoint.Endpoint
 }
 
 func (s *grpcServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.TaskResponse, error) {
@@ -154,6 +173,17 @@ func (s *grpcServer) RetryTask(ctx context.Context, req *pb.RetryTaskRequest) (*
 	return resp.(*pb.RetryTaskResponse), nil
 }
 
+func (s *grpcServer) ExtendTaskRetention(
+	ctx context.Context,
+	req *pb.ExtendTaskRetentionRequest,
+) (*pb.TaskResponse, error) {
+	_, resp, err := s.extendTaskRetention.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.TaskResponse), nil
+}
+
 func (s *grpcServer) CheckFileExists(
 	ctx context.Context,
 	req *pb.CheckFileExistsRequest,
@@ -187,6 +217,144 @@ func (s *grpcServer) GenerateDownloadURL(
 	return resp.(*pb.GenerateDownloadURLResponse), nil
 }
 
+func (s *grpcServer) GetUsage(ctx context.Context, req *pb.GetUsageRequest) (*pb.GetUsageResponse, error) {
+	_, resp, err := s.getUsage.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.GetUsageResponse), nil
+}
+
+func (s *grpcServer) ListSchedules(
+	ctx context.Context,
+	req *pb.ListSchedulesRequest,
+) (*pb.ListSchedulesResponse, error) {
+	_, resp, err := s.listSchedules.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListSchedulesResponse), nil
+}
+
+func (s *grpcServer) GetSchedule(ctx context.Context, req *pb.GetScheduleRequest) (*pb.ScheduleResponse, error) {
+	_, resp, err := s.getSchedule.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ScheduleResponse), nil
+}
+
+func (s *grpcServer) UpdateSchedule(ctx context.Context, req *pb.UpdateScheduleRequest) (*pb.ScheduleResponse, error) {
+	_, resp, err := s.updateSchedule.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ScheduleResponse), nil
+}
+
+func (s *grpcServer) DeleteSchedule(
+	ctx context.Context,
+	req *pb.DeleteScheduleRequest,
+) (*pb.DeleteScheduleResponse, error) {
+	_, resp, err := s.deleteSchedule.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.DeleteScheduleResponse), nil
+}
+
+func (s *grpcServer) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.WebhookResponse, error) {
+	_, resp, err := s.createWebhook.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.WebhookResponse), nil
+}
+
+func (s *grpcServer) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.ListWebhooksResponse, error) {
+	_, resp, err := s.listWebhooks.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListWebhooksResponse), nil
+}
+
+func (s *grpcServer) GetWebhook(ctx context.Context, req *pb.GetWebhookRequest) (*pb.WebhookResponse, error) {
+	_, resp, err := s.getWebhook.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.WebhookResponse), nil
+}
+
+func (s *grpcServer) UpdateWebhook(ctx context.Context, req *pb.UpdateWebhookRequest) (*pb.WebhookResponse, error) {
+	_, resp, err := s.updateWebhook.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.WebhookResponse), nil
+}
+
+func (s *grpcServer) DeleteWebhook(
+	ctx context.Context,
+	req *pb.DeleteWebhookRequest,
+) (*pb.DeleteWebhookResponse, error) {
+	_, resp, err := s.deleteWebhook.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.DeleteWebhookResponse), nil
+}
+
+func (s *grpcServer) ListWebhookDeliveries(
+	ctx context.Context,
+	req *pb.ListWebhookDeliveriesRequest,
+) (*pb.ListWebhookDeliveriesResponse, error) {
+	_, resp, err := s.listWebhookDeliveries.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListWebhookDeliveriesResponse), nil
+}
+
+func (s *grpcServer) CreateTasks(ctx context.Context, req *pb.CreateTasksRequest) (*pb.CreateTasksResponse, error) {
+	_, resp, err := s.createTasks.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.CreateTasksResponse), nil
+}
+
+func (s *grpcServer) BulkTasks(ctx context.Context, req *pb.BulkTasksRequest) (*pb.BulkTasksResponse, error) {
+	_, resp, err := s.bulkTasks.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.BulkTasksResponse), nil
+}
+
+func (s *grpcServer) RecordAuditEvent(
+	ctx context.Context,
+	req *pb.RecordAuditEventRequest,
+) (*pb.RecordAuditEventResponse, error) {
+	_, resp, err := s.recordAuditEvent.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.RecordAuditEventResponse), nil
+}
+
+func (s *grpcServer) ListAuditEvents(
+	ctx context.Context,
+	req *pb.ListAuditEventsRequest,
+) (*pb.ListAuditEventsResponse, error) {
+	_, resp, err := s.listAuditEvents.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(ctx, err)
+	}
+	return resp.(*pb.ListAuditEventsResponse), nil
+}
+
 func (s *grpcServer) UpdateTaskChecksum(
 	ctx context.Context,
 	req *pb.UpdateTaskChecksumRequest,
@@ -209,6 +377,20 @@ func (s *grpcServer) UpdateTaskMetadata(
 	return resp.(*pb.UpdateTaskResponse), nil
 }
 
+func (s *grpcServer) WatchTasks(req *pb.WatchTasksRequest, stream grpc.ServerStreamingServer[pb.TaskEvent]) error {
+	ctx := stream.Context()
+	resp, err := s.watchTasks(ctx, (*taskendpoint.WatchTasksRequest)(req))
+	if err != nil {
+		return encodeError(ctx, err)
+	}
+	for ev := range resp.(*taskendpoint.WatchTasksResponse).Events {
+		if err := stream.Send((*pb.TaskEvent)(ev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func encodeError(_ context.Context, err error) error {
 	return errors.EncodeGRPCError(err)
 }
@@ -259,6 +441,11 @@ func NewGRPCServer(endpoints taskendpoint.Set, logger log.Logger) pb.TaskService
 			decodeRetryTaskRequest,
 			encodeRetryTaskResponse,
 			options...),
+		extendTaskRetention: grpctransport.NewServer(
+			endpoints.ExtendTaskRetentionEndpoint,
+			decodeExtendTaskRetentionRequest,
+			encodeTaskResponse,
+			options...),
 		updateTaskStoragePath: grpctransport.NewServer(
 			endpoints.UpdateTaskStoragePathEndpoint,
 			decodeUpdateTaskStoragePathRequest,
@@ -309,6 +496,82 @@ func NewGRPCServer(endpoints taskendpoint.Set, logger log.Logger) pb.TaskService
 			decodeGenerateDownloadURLRequest,
 			encodeGenerateDownloadURLResponse,
 			options...),
+		getUsage: grpctransport.NewServer(
+			endpoints.GetUsageEndpoint,
+			decodeGetUsageRequest,
+			encodeGetUsageResponse,
+			options...),
+		listSchedules: grpctransport.NewServer(
+			endpoints.ListSchedulesEndpoint,
+			decodeListSchedulesRequest,
+			encodeListSchedulesResponse,
+			options...),
+		getSchedule: grpctransport.NewServer(
+			endpoints.GetScheduleEndpoint,
+			decodeGetScheduleRequest,
+			encodeScheduleResponse,
+			options...),
+		updateSchedule: grpctransport.NewServer(
+			endpoints.UpdateScheduleEndpoint,
+			decodeUpdateScheduleRequest,
+			encodeScheduleResponse,
+			options...),
+		deleteSchedule: grpctransport.NewServer(
+			endpoints.DeleteScheduleEndpoint,
+			decodeDeleteScheduleRequest,
+			encodeDeleteScheduleResponse,
+			options...),
+		createWebhook: grpctransport.NewServer(
+			endpoints.CreateWebhookEndpoint,
+			decodeCreateWebhookRequest,
+			encodeWebhookResponse,
+			options...),
+		listWebhooks: grpctransport.NewServer(
+			endpoints.ListWebhooksEndpoint,
+			decodeListWebhooksRequest,
+			encodeListWebhooksResponse,
+			options...),
+		getWebhook: grpctransport.NewServer(
+			endpoints.GetWebhookEndpoint,
+			decodeGetWebhookRequest,
+			encodeWebhookResponse,
+			options...),
+		updateWebhook: grpctransport.NewServer(
+			endpoints.UpdateWebhookEndpoint,
+			decodeUpdateWebhookRequest,
+			encodeWebhookResponse,
+			options...),
+		deleteWebhook: grpctransport.NewServer(
+			endpoints.DeleteWebhookEndpoint,
+			decodeDeleteWebhookRequest,
+			encodeDeleteWebhookResponse,
+			options...),
+		listWebhookDeliveries: grpctransport.NewServer(
+			endpoints.ListWebhookDeliveriesEndpoint,
+			decodeListWebhookDeliveriesRequest,
+			encodeListWebhookDeliveriesResponse,
+			options...),
+		createTasks: grpctransport.NewServer(
+			endpoints.CreateTasksEndpoint,
+			decodeCreateTasksRequest,
+			encodeCreateTasksResponse,
+			options...),
+		bulkTasks: grpctransport.NewServer(
+			endpoints.BulkTasksEndpoint,
+			decodeBulkTasksRequest,
+			encodeBulkTasksResponse,
+			options...),
+		recordAuditEvent: grpctransport.NewServer(
+			endpoints.RecordAuditEventEndpoint,
+			decodeRecordAuditEventRequest,
+			encodeRecordAuditEventResponse,
+			options...),
+		listAuditEvents: grpctransport.NewServer(
+			endpoints.ListAuditEventsEndpoint,
+			decodeListAuditEventsRequest,
+			encodeListAuditEventsResponse,
+			options...),
+		watchTasks: endpoints.WatchTasksEndpoint,
 	}
 }
 
@@ -335,6 +598,8 @@ func NewGRPCClient(conn *grpc.ClientConn, logger log.Logger) task.Service {
 			Endpoint(),
 		RetryTaskEndpoint: grpctransport.NewClient(conn, svcName, "RetryTask", encodeRetryTaskRequest, decodeRetryTaskResponse, pb.RetryTaskResponse{}, options...).
 			Endpoint(),
+		ExtendTaskRetentionEndpoint: grpctransport.NewClient(conn, svcName, "ExtendTaskRetention", encodeExtendTaskRetentionRequest, decodeTaskResponse, pb.TaskResponse{}, options...).
+			Endpoint(),
 		UpdateTaskStoragePathEndpoint: grpctransport.NewClient(conn, svcName, "UpdateTaskStoragePath", encodeUpdateTaskStoragePathRequest, decodeTaskResponse, pb.TaskResponse{}, options...).
 			Endpoint(),
 		UpdateTaskStatusEndpoint: grpctransport.NewClient(conn, svcName, "UpdateTaskStatus", encodeUpdateTaskStatusRequest, decodeTaskResponse, pb.TaskResponse{}, options...).
@@ -351,6 +616,65 @@ func NewGRPCClient(conn *grpc.ClientConn, logger log.Logger) task.Service {
 			Endpoint(),
 		GenerateDownloadURLEndpoint: grpctransport.NewClient(conn, svcName, "GenerateDownloadURL", encodeGenerateDownloadURLRequest, decodeGenerateDownloadURLResponse, pb.GenerateDownloadURLResponse{}, options...).
 			Endpoint(),
+		GetUsageEndpoint: grpctransport.NewClient(conn, svcName, "GetUsage", encodeGetUsageRequest, decodeGetUsageResponse, pb.GetUsageResponse{}, options...).
+			Endpoint(),
+		ListSchedulesEndpoint: grpctransport.NewClient(conn, svcName, "ListSchedules", encodeListSchedulesRequest, decodeListSchedulesResponse, pb.ListSchedulesResponse{}, options...).
+			Endpoint(),
+		GetScheduleEndpoint: grpctransport.NewClient(conn, svcName, "GetSchedule", encodeGetScheduleRequest, decodeScheduleResponse, pb.ScheduleResponse{}, options...).
+			Endpoint(),
+		UpdateScheduleEndpoint: grpctransport.NewClient(conn, svcName, "UpdateSchedule", encodeUpdateScheduleRequest, decodeScheduleResponse, pb.ScheduleResponse{}, options...).
+			Endpoint(),
+		DeleteScheduleEndpoint: grpctransport.NewClient(conn, svcName, "DeleteSchedule", encodeDeleteScheduleRequest, decodeDeleteScheduleResponse, pb.DeleteScheduleResponse{}, options...).
+			Endpoint(),
+		WatchTasksEndpoint: makeWatchTasksClientEndpoint(pb.NewTaskServiceClient(conn)),
+		CreateWebhookEndpoint: grpctransport.NewClient(conn, svcName, "CreateWebhook", encodeCreateWebhookRequest, decodeWebhookResponse, pb.WebhookResponse{}, options...).
+			Endpoint(),
+		ListWebhooksEndpoint: grpctransport.NewClient(conn, svcName, "ListWebhooks", encodeListWebhooksRequest, decodeListWebhooksResponse, pb.ListWebhooksResponse{}, options...).
+			Endpoint(),
+		GetWebhookEndpoint: grpctransport.NewClient(conn, svcName, "GetWebhook", encodeGetWebhookRequest, decodeWebhookResponse, pb.WebhookResponse{}, options...).
+			Endpoint(),
+		UpdateWebhookEndpoint: grpctransport.NewClient(conn, svcName, "UpdateWebhook", encodeUpdateWebhookRequest, decodeWebhookResponse, pb.WebhookResponse{}, options...).
+			Endpoint(),
+		DeleteWebhookEndpoint: grpctransport.NewClient(conn, svcName, "DeleteWebhook", encodeDeleteWebhookRequest, decodeDeleteWebhookResponse, pb.DeleteWebhookResponse{}, options...).
+			Endpoint(),
+		ListWebhookDeliveriesEndpoint: grpctransport.NewClient(conn, svcName, "ListWebhookDeliveries", encodeListWebhookDeliveriesRequest, decodeListWebhookDeliveriesResponse, pb.ListWebhookDeliveriesResponse{}, options...).
+			Endpoint(),
+		CreateTasksEndpoint: grpctransport.NewClient(conn, svcName, "CreateTasks", encodeCreateTasksRequest, decodeCreateTasksResponse, pb.CreateTasksResponse{}, options...).
+			Endpoint(),
+		BulkTasksEndpoint: grpctransport.NewClient(conn, svcName, "BulkTasks", encodeBulkTasksRequest, decodeBulkTasksResponse, pb.BulkTasksResponse{}, options...).
+			Endpoint(),
+		RecordAuditEventEndpoint: grpctransport.NewClient(conn, svcName, "RecordAuditEvent", encodeRecordAuditEventRequest, decodeRecordAuditEventResponse, pb.RecordAuditEventResponse{}, options...).
+			Endpoint(),
+		ListAuditEventsEndpoint: grpctransport.NewClient(conn, svcName, "ListAuditEvents", encodeListAuditEventsRequest, decodeListAuditEventsResponse, pb.ListAuditEventsResponse{}, options...).
+			Endpoint(),
+	}
+}
+
+// makeWatchTasksClientEndpoint opens a WatchTasks stream and forwards its
+// events until the stream ends.
+func makeWatchTasksClientEndpoint(client pb.TaskServiceClient) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req := request.(*taskendpoint.WatchTasksRequest)
+		stream, err := client.WatchTasks(ctx, (*pb.WatchTasksRequest)(req))
+		if err != nil {
+			return nil, err
+		}
+		out := make(chan *taskendpoint.TaskEvent)
+		go func() {
+			defer close(out)
+			for {
+				ev, err := stream.Recv()
+				if err != nil {
+					return
+				}
+				select {
+				case out <- (*taskendpoint.TaskEvent)(ev):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return &taskendpoint.WatchTasksResponse{Events: out}, nil
 	}
 }
 
@@ -420,6 +744,11 @@ func decodeRetryTaskRequest(_ context.Context, grpcReq any) (any, error) {
 	return (*taskendpoint.RetryTaskRequest)(req), nil
 }
 
+func decodeExtendTaskRetentionRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ExtendTaskRetentionRequest)
+	return (*taskendpoint.ExtendTaskRetentionRequest)(req), nil
+}
+
 func decodeCheckFileExistsRequest(_ context.Context, grpcReq any) (any, error) {
 	req := grpcReq.(*pb.CheckFileExistsRequest)
 	return &taskendpoint.CheckFileExistsRequest{TaskId: req.GetTaskId()}, nil
@@ -550,6 +879,11 @@ func encodeRetryTaskRequest(_ context.Context, request any) (any, error) {
 	return (*pb.RetryTaskRequest)(req), nil
 }
 
+func encodeExtendTaskRetentionRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.ExtendTaskRetentionRequest)
+	return (*pb.ExtendTaskRetentionRequest)(req), nil
+}
+
 func encodeCheckFileExistsRequest(_ context.Context, request any) (any, error) {
 	req := request.(*taskendpoint.CheckFileExistsRequest)
 	return (*pb.CheckFileExistsRequest)(req), nil
@@ -627,3 +961,279 @@ func decodeGenerateDownloadURLResponse(_ context.Context, grpcResp any) (any, er
 	resp := grpcResp.(*pb.GenerateDownloadURLResponse)
 	return (*taskendpoint.GenerateDownloadURLResponse)(resp), nil
 }
+
+// GetUsage server-side decoder/encoder
+func decodeGetUsageRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.GetUsageRequest)
+	return (*taskendpoint.GetUsageRequest)(req), nil
+}
+
+func encodeGetUsageResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.GetUsageResponse)
+	return (*pb.GetUsageResponse)(resp), nil
+}
+
+// GetUsage client-side encoder/decoder
+func encodeGetUsageRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.GetUsageRequest)
+	return (*pb.GetUsageRequest)(req), nil
+}
+
+func decodeGetUsageResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.GetUsageResponse)
+	return (*taskendpoint.GetUsageResponse)(resp), nil
+}
+
+// Schedule server-side decoders/encoders
+func decodeListSchedulesRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListSchedulesRequest)
+	return (*taskendpoint.ListSchedulesRequest)(req), nil
+}
+
+func encodeListSchedulesResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.ListSchedulesResponse)
+	return (*pb.ListSchedulesResponse)(resp), nil
+}
+
+func decodeGetScheduleRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.GetScheduleRequest)
+	return (*taskendpoint.GetScheduleRequest)(req), nil
+}
+
+func decodeUpdateScheduleRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.UpdateScheduleRequest)
+	return (*taskendpoint.UpdateScheduleRequest)(req), nil
+}
+
+func encodeScheduleResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.ScheduleResponse)
+	return (*pb.ScheduleResponse)(resp), nil
+}
+
+func decodeDeleteScheduleRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.DeleteScheduleRequest)
+	return (*taskendpoint.DeleteScheduleRequest)(req), nil
+}
+
+func encodeDeleteScheduleResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.DeleteScheduleResponse)
+	return (*pb.DeleteScheduleResponse)(resp), nil
+}
+
+// Schedule client-side encoders/decoders
+func encodeListSchedulesRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.ListSchedulesRequest)
+	return (*pb.ListSchedulesRequest)(req), nil
+}
+
+func decodeListSchedulesResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListSchedulesResponse)
+	return (*taskendpoint.ListSchedulesResponse)(resp), nil
+}
+
+func encodeGetScheduleRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.GetScheduleRequest)
+	return (*pb.GetScheduleRequest)(req), nil
+}
+
+func encodeUpdateScheduleRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.UpdateScheduleRequest)
+	return (*pb.UpdateScheduleRequest)(req), nil
+}
+
+func decodeScheduleResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ScheduleResponse)
+	return (*taskendpoint.ScheduleResponse)(resp), nil
+}
+
+func encodeDeleteScheduleRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.DeleteScheduleRequest)
+	return (*pb.DeleteScheduleRequest)(req), nil
+}
+
+func decodeDeleteScheduleResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.DeleteScheduleResponse)
+	return (*taskendpoint.DeleteScheduleResponse)(resp), nil
+}
+
+// Webhook server-side decoders/encoders
+func decodeCreateWebhookRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.CreateWebhookRequest)
+	return (*taskendpoint.CreateWebhookRequest)(req), nil
+}
+
+func encodeWebhookResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.WebhookResponse)
+	return (*pb.WebhookResponse)(resp), nil
+}
+
+func decodeListWebhooksRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListWebhooksRequest)
+	return (*taskendpoint.ListWebhooksRequest)(req), nil
+}
+
+func encodeListWebhooksResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.ListWebhooksResponse)
+	return (*pb.ListWebhooksResponse)(resp), nil
+}
+
+func decodeGetWebhookRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.GetWebhookRequest)
+	return (*taskendpoint.GetWebhookRequest)(req), nil
+}
+
+func decodeUpdateWebhookRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.UpdateWebhookRequest)
+	return (*taskendpoint.UpdateWebhookRequest)(req), nil
+}
+
+func decodeDeleteWebhookRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.DeleteWebhookRequest)
+	return (*taskendpoint.DeleteWebhookRequest)(req), nil
+}
+
+func encodeDeleteWebhookResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.DeleteWebhookResponse)
+	return (*pb.DeleteWebhookResponse)(resp), nil
+}
+
+func decodeListWebhookDeliveriesRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListWebhookDeliveriesRequest)
+	return (*taskendpoint.ListWebhookDeliveriesRequest)(req), nil
+}
+
+func encodeListWebhookDeliveriesResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.ListWebhookDeliveriesResponse)
+	return (*pb.ListWebhookDeliveriesResponse)(resp), nil
+}
+
+func decodeCreateTasksRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.CreateTasksRequest)
+	return (*taskendpoint.CreateTasksRequest)(req), nil
+}
+
+func encodeCreateTasksResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.CreateTasksResponse)
+	return (*pb.CreateTasksResponse)(resp), nil
+}
+
+func decodeBulkTasksRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.BulkTasksRequest)
+	return (*taskendpoint.BulkTasksRequest)(req), nil
+}
+
+func encodeBulkTasksResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.BulkTasksResponse)
+	return (*pb.BulkTasksResponse)(resp), nil
+}
+
+func decodeRecordAuditEventRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.RecordAuditEventRequest)
+	return (*taskendpoint.RecordAuditEventRequest)(req), nil
+}
+
+func encodeRecordAuditEventResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.RecordAuditEventResponse)
+	return (*pb.RecordAuditEventResponse)(resp), nil
+}
+
+func decodeListAuditEventsRequest(_ context.Context, grpcReq any) (any, error) {
+	req := grpcReq.(*pb.ListAuditEventsRequest)
+	return (*taskendpoint.ListAuditEventsRequest)(req), nil
+}
+
+func encodeListAuditEventsResponse(_ context.Context, response any) (any, error) {
+	resp := response.(*taskendpoint.ListAuditEventsResponse)
+	return (*pb.ListAuditEventsResponse)(resp), nil
+}
+
+// Webhook client-side encoders/decoders
+func encodeCreateWebhookRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.CreateWebhookRequest)
+	return (*pb.CreateWebhookRequest)(req), nil
+}
+
+func decodeWebhookResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.WebhookResponse)
+	return (*taskendpoint.WebhookResponse)(resp), nil
+}
+
+func encodeListWebhooksRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.ListWebhooksRequest)
+	return (*pb.ListWebhooksRequest)(req), nil
+}
+
+func decodeListWebhooksResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListWebhooksResponse)
+	return (*taskendpoint.ListWebhooksResponse)(resp), nil
+}
+
+func encodeGetWebhookRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.GetWebhookRequest)
+	return (*pb.GetWebhookRequest)(req), nil
+}
+
+func encodeUpdateWebhookRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.UpdateWebhookRequest)
+	return (*pb.UpdateWebhookRequest)(req), nil
+}
+
+func encodeDeleteWebhookRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.DeleteWebhookRequest)
+	return (*pb.DeleteWebhookRequest)(req), nil
+}
+
+func decodeDeleteWebhookResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.DeleteWebhookResponse)
+	return (*taskendpoint.DeleteWebhookResponse)(resp), nil
+}
+
+func encodeListWebhookDeliveriesRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.ListWebhookDeliveriesRequest)
+	return (*pb.ListWebhookDeliveriesRequest)(req), nil
+}
+
+func decodeListWebhookDeliveriesResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListWebhookDeliveriesResponse)
+	return (*taskendpoint.ListWebhookDeliveriesResponse)(resp), nil
+}
+
+func encodeCreateTasksRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.CreateTasksRequest)
+	return (*pb.CreateTasksRequest)(req), nil
+}
+
+func decodeCreateTasksResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.CreateTasksResponse)
+	return (*taskendpoint.CreateTasksResponse)(resp), nil
+}
+
+func encodeBulkTasksRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.BulkTasksRequest)
+	return (*pb.BulkTasksRequest)(req), nil
+}
+
+func decodeBulkTasksResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.BulkTasksResponse)
+	return (*taskendpoint.BulkTasksResponse)(resp), nil
+}
+
+func encodeRecordAuditEventRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.RecordAuditEventRequest)
+	return (*pb.RecordAuditEventRequest)(req), nil
+}
+
+func decodeRecordAuditEventResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.RecordAuditEventResponse)
+	return (*taskendpoint.RecordAuditEventResponse)(resp), nil
+}
+
+func encodeListAuditEventsRequest(_ context.Context, request any) (any, error) {
+	req := request.(*taskendpoint.ListAuditEventsRequest)
+	return (*pb.ListAuditEventsRequest)(req), nil
+}
+
+func decodeListAuditEventsResponse(_ context.Context, grpcResp any) (any, error) {
+	resp := grpcResp.(*pb.ListAuditEventsResponse)
+	return (*taskendpoint.ListAuditEventsResponse)(resp), nil
+}
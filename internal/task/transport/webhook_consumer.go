@@ -0,0 +1,129 @@
+package tasktransport
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/yuisofull/goload/internal/events"
+	"github.com/yuisofull/goload/internal/task"
+	"github.com/yuisofull/goload/pkg/message"
+	"github.com/yuisofull/goload/pkg/message/middleware"
+)
+
+// WebhookConsumer queues webhook notifications for the task lifecycle events
+// on the message bus.
+type WebhookConsumer struct {
+	runner       task.WebhookRunner
+	subscriber   message.Subscriber
+	errorHandler func(context.Context, error)
+}
+
+// NewWebhookConsumer creates a consumer that hands task events to runner.
+// The subscriber should not share its consumer group with the EventConsumer.
+func NewWebhookConsumer(
+	runner task.WebhookRunner,
+	subscriber message.Subscriber,
+	errorHandler func(context.Context, error),
+) *WebhookConsumer {
+	return &WebhookConsumer{
+		runner:       runner,
+		subscriber:   subscriber,
+		errorHandler: errorHandler,
+	}
+}
+
+// Start begins consuming events
+func (wc *WebhookConsumer) Start(ctx context.Context) error {
+	r := message.NewRouter(wc.subscriber)
+	r.Use(middleware.Recoverer, middleware.CorrelationID)
+	for _, ev := range task.WebhookEvents {
+		r.AddHandler("task.webhook."+strings.ReplaceAll(string(ev), ".", "_"), string(ev), wc.handler(ev))
+	}
+	return r.Run(ctx)
+}
+
+func (wc *WebhookConsumer) handler(ev events.EventType) message.HandlerFunc {
+	return func(msg *message.Message) error {
+		n, err := decodeWebhookNotification(ev, msg)
+		if err != nil {
+			// A malformed event will not decode on redelivery either.
+			wc.errorHandler(msg.Context(), err)
+			return nil
+		}
+		if n == nil {
+			return nil
+		}
+		if err := wc.runner.QueueWebhooks(msg.Context(), n); err != nil {
+			wc.errorHandler(msg.Context(), err)
+			return err
+		}
+		return nil
+	}
+}
+
+// decodeWebhookNotification builds the notification for a message. It returns
+// nil for messages that are not sent to webhooks.
+func decodeWebhookNotification(ev events.EventType, msg *message.Message) (*task.WebhookNotification, error) {
+	var (
+		taskID      uint64
+		ofAccountID uint64
+		at          time.Time
+		data        any
+	)
+	switch ev {
+	case events.EventTaskCreated:
+		// Retried tasks are handed to the workers as task.created again;
+		// they are reported as task.retried.
+		if msg.Metadata.Get(events.MetadataRetry) == "true" {
+			return nil, nil
+		}
+		var e events.TaskCreatedEvent
+		if err := json.Unmarshal(msg.Payload, &e); err != nil {
+			return nil, err
+		}
+		// Never send source credentials to webhooks.
+		e.SourceAuth = nil
+		taskID, ofAccountID, at, data = e.TaskID, e.OfAccountID, e.CreatedAt, e
+	case events.EventTaskCompleted:
+		var e events.TaskCompletedEvent
+		if err := json.Unmarshal(msg.Payload, &e); err != nil {
+			return nil, err
+		}
+		taskID, at, data = e.TaskID, e.CompletedAt, e
+	case events.EventTaskFailed:
+		var e events.TaskFailedEvent
+		if err := json.Unmarshal(msg.Payload, &e); err != nil {
+			return nil, err
+		}
+		taskID, at, data = e.TaskID, e.FailedAt, e
+	case events.EventTaskCancelled:
+		var e events.TaskCancelledEvent
+		if err := json.Unmarshal(msg.Payload, &e); err != nil {
+			return nil, err
+		}
+		taskID, at, data = e.TaskID, e.CancelledAt, e
+	case events.EventTaskRetried:
+		var e events.TaskRetriedEvent
+		if err := json.Unmarshal(msg.Payload, &e); err != nil {
+			return nil, err
+		}
+		taskID, at, data = e.TaskID, e.RetriedAt, e
+	default:
+		return nil, nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &task.WebhookNotification{
+		EventID:     msg.UUID,
+		Event:       ev,
+		TaskID:      taskID,
+		OfAccountID: ofAccountID,
+		Data:        raw,
+		Time:        at,
+	}, nil
+}
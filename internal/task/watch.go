@@ -0,0 +1,215 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type TaskEventType string
+
+const (
+	TaskEventStatus   TaskEventType = "status"
+	TaskEventProgress TaskEventType = "progress"
+	TaskEventDeleted  TaskEventType = "deleted"
+	// TaskEventReset tells a watcher that the events after its last event ID
+	// are no longer available. Clients should reload their tasks.
+	TaskEventReset TaskEventType = "reset"
+)
+
+// TaskEvent is a change of a task. Status events carry Status (and
+// ErrorMessage for failed tasks), progress events carry Progress.
+type TaskEvent struct {
+	// ID orders the events of a task service instance. It is passed back as
+	// the last event ID to resume a watch.
+	ID           uint64
+	Type         TaskEventType
+	TaskID       uint64
+	OfAccountID  uint64
+	WorkspaceID  uint64
+	Status       TaskStatus
+	Progress     *DownloadProgress
+	ErrorMessage *string
+	Time         time.Time
+}
+
+const (
+	// watchHistorySize is the number of recent events kept for resuming watches.
+	watchHistorySize = 1024
+	// watchBufferSize is the number of events queued per watcher. Watchers
+	// that fall further behind are disconnected and have to resume.
+	watchBufferSize = 256
+)
+
+// watchHub fans task events out to watchers and keeps a short history of them.
+type watchHub struct {
+	mu      sync.Mutex
+	seq     uint64
+	history []*TaskEvent
+	subs    map[chan *TaskEvent]struct{}
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{
+		// IDs start at the current time in microseconds so that they keep
+		// increasing across restarts and stay exact as JavaScript numbers.
+		seq:  uint64(time.Now().UnixMicro()),
+		subs: make(map[chan *TaskEvent]struct{}),
+	}
+}
+
+func (h *watchHub) publish(ev *TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	ev.ID = h.seq
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	if len(h.history) == watchHistorySize {
+		copy(h.history, h.history[1:])
+		h.history = h.history[:watchHistorySize-1]
+	}
+	h.history = append(h.history, ev)
+
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a watcher and returns the events after lastEventID.
+// When those are no longer known, ok is false and last is the ID of the
+// newest event.
+func (h *watchHub) subscribe(lastEventID uint64) (ch chan *TaskEvent, missed []*TaskEvent, last uint64, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch = make(chan *TaskEvent, watchBufferSize)
+	h.subs[ch] = struct{}{}
+
+	ok = true
+	if lastEventID != 0 && lastEventID != h.seq {
+		switch {
+		case lastEventID > h.seq, len(h.history) == 0, lastEventID < h.history[0].ID-1:
+			ok = false
+		default:
+			for _, ev := range h.history {
+				if ev.ID > lastEventID {
+					missed = append(missed, ev)
+				}
+			}
+		}
+	}
+	return ch, missed, h.seq, ok
+}
+
+func (h *watchHub) unsubscribe(ch chan *TaskEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// emit publishes a task change to watchers.
+func (s *service) emit(ev *TaskEvent) {
+	s.watch.publish(ev)
+}
+
+// emitUpdate publishes an event of an update that did not load the task,
+// with the account and workspace of the task looked up once here rather
+// than by every watcher. Without them watchers look the task up themselves.
+func (s *service) emitUpdate(ctx context.Context, ev *TaskEvent) {
+	if t, err := s.repo.GetByID(ctx, ev.TaskID); err == nil && t != nil {
+		ev.OfAccountID, ev.WorkspaceID = t.OfAccountID, t.WorkspaceID
+	}
+	s.emit(ev)
+}
+
+func (s *service) emitStatus(t *Task) {
+	s.emit(&TaskEvent{
+		Type:         TaskEventStatus,
+		TaskID:       t.ID,
+		OfAccountID:  t.OfAccountID,
+		WorkspaceID:  t.WorkspaceID,
+		Status:       t.Status,
+		ErrorMessage: t.ErrorMessage,
+	})
+}
+
+// WatchTasks streams the events of the personal tasks of an account, or of
+// the tasks of a workspace, selected like ListTasks does. With a non-zero
+// lastEventID the events after it are replayed first, or a reset event is
+// sent when they are no longer available. The channel is closed when ctx is
+// done or the watcher falls too far behind.
+func (s *service) WatchTasks(
+	ctx context.Context,
+	ofAccountID, workspaceID, lastEventID uint64,
+) (<-chan *TaskEvent, error) {
+	sub, missed, last, ok := s.watch.subscribe(lastEventID)
+
+	out := make(chan *TaskEvent)
+	go func() {
+		defer close(out)
+		defer s.watch.unsubscribe(sub)
+
+		// Events carry the account and workspace of their task, unless it
+		// could not be looked up when they were emitted; look those tasks up
+		// again.
+		ownedBy := func(ev *TaskEvent) bool {
+			if ev.OfAccountID != 0 {
+				return inScope(&Task{OfAccountID: ev.OfAccountID, WorkspaceID: ev.WorkspaceID}, ofAccountID, workspaceID)
+			}
+			t, err := s.repo.GetByID(ctx, ev.TaskID)
+			return err == nil && t != nil && inScope(t, ofAccountID, workspaceID)
+		}
+		send := func(ev *TaskEvent) bool {
+			select {
+			case out <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if !ok {
+			reset := &TaskEvent{
+				ID:          last,
+				Type:        TaskEventReset,
+				OfAccountID: ofAccountID,
+				WorkspaceID: workspaceID,
+				Time:        time.Now(),
+			}
+			if !send(reset) {
+				return
+			}
+		}
+		for _, ev := range missed {
+			if ownedBy(ev) && !send(ev) {
+				return
+			}
+		}
+		for {
+			select {
+			case ev, open := <-sub:
+				if !open {
+					return
+				}
+				if ownedBy(ev) && !send(ev) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
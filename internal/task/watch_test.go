@@ -0,0 +1,187 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/internal/errors"
+)
+
+func nextEvent(t *testing.T, events <-chan *TaskEvent) *TaskEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		require.True(t, ok, "event stream closed")
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestWatchTasks_StreamsEventsOfAccount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &fakeRepo{task: &Task{ID: 5, OfAccountID: 7}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{})
+
+	events, err := svc.WatchTasks(ctx, 7, 0, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.UpdateTaskProgress(ctx, 5, DownloadProgress{Progress: 10, DownloadedBytes: 1}))
+	svc.(*service).emit(&TaskEvent{Type: TaskEventStatus, TaskID: 9, OfAccountID: 8, Status: StatusPending})
+	require.NoError(t, svc.UpdateTaskStatus(ctx, 5, StatusDownloading))
+
+	progress := nextEvent(t, events)
+	require.Equal(t, TaskEventProgress, progress.Type)
+	require.Equal(t, uint64(5), progress.TaskID)
+	require.Equal(t, 10.0, progress.Progress.Progress)
+
+	status := nextEvent(t, events)
+	require.Equal(t, TaskEventStatus, status.Type)
+	require.Equal(t, StatusDownloading, status.Status)
+	require.Greater(t, status.ID, progress.ID)
+
+	cancel()
+	_, open := <-events
+	require.False(t, open)
+}
+
+func TestWatchTasks_ResumesAfterLastEventID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := &fakeRepo{task: &Task{ID: 5, OfAccountID: 7}}
+	svc := NewService(repo, Publisher{}, fakeTxManager{})
+
+	require.NoError(t, svc.UpdateTaskStatus(ctx, 5, StatusDownloading))
+	require.NoError(t, svc.UpdateTaskProgress(ctx, 5, DownloadProgress{Progress: 50}))
+	require.NoError(t, svc.UpdateTaskError(ctx, 5, context.DeadlineExceeded))
+
+	first := svc.(*service).watch.history[0]
+	events, err := svc.WatchTasks(ctx, 7, 0, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, TaskEventProgress, nextEvent(t, events).Type)
+	failed := nextEvent(t, events)
+	require.Equal(t, StatusFailed, failed.Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), *failed.ErrorMessage)
+
+	// An ID older than the history cannot be resumed.
+	events, err = svc.WatchTasks(ctx, 7, 0, first.ID-10)
+	require.NoError(t, err)
+	reset := nextEvent(t, events)
+	require.Equal(t, TaskEventReset, reset.Type)
+	require.Equal(t, failed.ID, reset.ID)
+}
+
+func TestWatchTasks_StreamsEventsOfWorkspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Task 5 was created by another member of workspace 3.
+	repo := &fakeRepo{task: &Task{ID: 5, OfAccountID: 8, WorkspaceID: 3}}
+	svc := NewService(repo, *NewEventPublisher(&fakeMessagePublisher{}), fakeTxManager{})
+
+	workspace, err := svc.WatchTasks(ctx, 7, 3, 0)
+	require.NoError(t, err)
+	personal, err := svc.WatchTasks(ctx, 8, 0, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.UpdateTaskProgress(ctx, 5, DownloadProgress{Progress: 10, DownloadedBytes: 1}))
+	require.NoError(t, svc.DeleteTask(ctx, 5))
+	svc.(*service).emit(&TaskEvent{Type: TaskEventStatus, TaskID: 9, OfAccountID: 8, Status: StatusPending})
+
+	progress := nextEvent(t, workspace)
+	require.Equal(t, TaskEventProgress, progress.Type)
+	require.Equal(t, uint64(5), progress.TaskID)
+	deleted := nextEvent(t, workspace)
+	require.Equal(t, TaskEventDeleted, deleted.Type)
+	require.Equal(t, uint64(5), deleted.TaskID)
+
+	// Workspace tasks are not part of the personal tasks of their creator.
+	pending := nextEvent(t, personal)
+	require.Equal(t, uint64(9), pending.TaskID)
+}
+
+// flakyRepo fails the first failures lookups of a task.
+type flakyRepo struct {
+	*fakeRepo
+	mu       sync.Mutex
+	failures int
+}
+
+func (r *flakyRepo) GetByID(ctx context.Context, id uint64) (*Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		return nil, errors.ErrNotFound
+	}
+	return r.fakeRepo.GetByID(ctx, id)
+}
+
+func (r *flakyRepo) failing() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures > 0
+}
+
+func TestWatchTasks_LooksUpTaskAgainAfterFailedLookup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The task is not visible yet when its first update is emitted, nor when
+	// the watcher looks it up.
+	repo := &flakyRepo{fakeRepo: &fakeRepo{task: &Task{ID: 5, OfAccountID: 7, WorkspaceID: 3}}, failures: 2}
+	svc := NewService(repo, Publisher{}, fakeTxManager{})
+	events, err := svc.WatchTasks(ctx, 7, 3, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.UpdateTaskProgress(ctx, 5, DownloadProgress{Progress: 10}))
+	require.Eventually(t, func() bool { return !repo.failing() }, time.Second, time.Millisecond)
+
+	svc.(*service).emit(&TaskEvent{Type: TaskEventStatus, TaskID: 5, Status: StatusDownloading})
+	status := nextEvent(t, events)
+	require.Equal(t, TaskEventStatus, status.Type)
+
+	require.NoError(t, svc.UpdateTaskProgress(ctx, 5, DownloadProgress{Progress: 20}))
+	progress := nextEvent(t, events)
+	require.Equal(t, uint64(7), progress.OfAccountID)
+	require.Equal(t, uint64(3), progress.WorkspaceID)
+}
+
+func TestWatchTasks_WorkspaceWatchSkipsPersonalTasksOfMember(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The upcoming run of schedule 1 is a task of workspace 3.
+	repo := &fakeRepo{task: &Task{ID: 50, OfAccountID: 7, WorkspaceID: 3, Status: StatusScheduled}}
+	schedules := newFakeScheduleRepo()
+	schedules.schedules[1] = &Schedule{
+		ID:          1,
+		OfAccountID: 7,
+		Cron:        "0 3 * * *",
+		Enabled:     true,
+		NextTaskID:  50,
+		Template:    &CreateTaskParam{OfAccountID: 7, WorkspaceID: 3, SourceURL: "https://example.com/nightly.zip"},
+	}
+	svc := NewService(repo, Publisher{}, fakeTxManager{}, WithScheduleRepository(schedules))
+	events, err := svc.WatchTasks(ctx, 7, 3, 0)
+	require.NoError(t, err)
+
+	svc.(*service).emitStatus(&Task{ID: 9, OfAccountID: 7, Status: StatusPending})
+	svc.(*service).emitStatus(&Task{ID: 10, OfAccountID: 7, WorkspaceID: 3, Status: StatusPending})
+	require.NoError(t, svc.DeleteSchedule(ctx, 1))
+
+	status := nextEvent(t, events)
+	require.Equal(t, uint64(10), status.TaskID)
+	deleted := nextEvent(t, events)
+	require.Equal(t, TaskEventDeleted, deleted.Type)
+	require.Equal(t, uint64(50), deleted.TaskID)
+	require.Equal(t, uint64(3), deleted.WorkspaceID)
+}
@@ -0,0 +1,576 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
+)
+
+// WebhookEvents are the task events webhooks can subscribe to.
+var WebhookEvents = []events.EventType{
+	events.EventTaskCreated,
+	events.EventTaskCompleted,
+	events.EventTaskFailed,
+	events.EventTaskCancelled,
+	events.EventTaskRetried,
+}
+
+// Webhook receives the task events of an account as signed JSON POST requests.
+type Webhook struct {
+	ID          uint64
+	OfAccountID uint64
+	URL         string
+	// Secret is the HMAC-SHA256 key the payloads are signed with.
+	Secret string
+	// Events the webhook receives; empty means all of WebhookEvents.
+	Events    []events.EventType
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Subscribes reports whether the webhook receives the event.
+func (w *Webhook) Subscribes(event events.EventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "PENDING"
+	DeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	DeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is the delivery of one event to one webhook. Failed attempts
+// are retried with exponential backoff until the delivery succeeds or runs out
+// of attempts.
+type WebhookDelivery struct {
+	ID        uint64
+	WebhookID uint64
+	// EventID identifies the event; it is the same for every webhook receiving it.
+	EventID string
+	Event   events.EventType
+	TaskID  uint64
+	// Payload is the request body, a JSON encoded WebhookPayload.
+	Payload  []byte
+	Status   WebhookDeliveryStatus
+	Attempts uint32
+	// NextAttemptAt is nil once the delivery succeeded or failed for good.
+	NextAttemptAt *time.Time
+	// ResponseCode and LastError describe the last attempt.
+	ResponseCode int32
+	LastError    *string
+	DeliveredAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// WebhookPayload is the body of a webhook request. Data is the event as
+// published on the message bus, without source credentials.
+type WebhookPayload struct {
+	ID          string           `json:"id"`
+	Event       events.EventType `json:"event"`
+	TaskID      uint64           `json:"task_id"`
+	OfAccountID uint64           `json:"of_account_id"`
+	Data        json.RawMessage  `json:"data"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// WebhookNotification is a task event to be sent to the webhooks of the
+// task's account.
+type WebhookNotification struct {
+	// EventID identifies the event, e.g. the ID of the message it arrived in.
+	// Notifications with an ID that was already queued are ignored.
+	EventID string
+	Event   events.EventType
+	TaskID  uint64
+	// OfAccountID is looked up from the task when zero.
+	OfAccountID uint64
+	Data        json.RawMessage
+	Time        time.Time
+}
+
+type CreateWebhookParam struct {
+	OfAccountID uint64
+	URL         string
+	Events      []events.EventType
+	// Secret is generated when empty.
+	Secret string
+}
+
+// UpdateWebhookParam changes a webhook. Nil fields are kept.
+type UpdateWebhookParam struct {
+	ID      uint64
+	URL     *string
+	Events  *[]events.EventType
+	Enabled *bool
+	// RotateSecret replaces the secret with a newly generated one.
+	RotateSecret bool
+}
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, w *Webhook) (*Webhook, error)
+	GetWebhook(ctx context.Context, id uint64) (*Webhook, error)
+	ListWebhooksOfAccount(ctx context.Context, ofAccountID uint64, limit, offset uint32) ([]*Webhook, error)
+	ListEnabledWebhooksOfAccount(ctx context.Context, ofAccountID uint64) ([]*Webhook, error)
+	UpdateWebhook(ctx context.Context, w *Webhook) error
+	// DeleteWebhook deletes the webhook together with its deliveries.
+	DeleteWebhook(ctx context.Context, id uint64) error
+
+	// CreateWebhookDelivery stores d unless the event was already queued for
+	// the webhook and reports whether it did.
+	CreateWebhookDelivery(ctx context.Context, d *WebhookDelivery) (bool, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID uint64, limit, offset uint32) ([]*WebhookDelivery, error)
+	// ListDueWebhookDeliveries returns pending deliveries whose next attempt
+	// is at or before now.
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit uint32) ([]*WebhookDelivery, error)
+	// UpdateWebhookDelivery stores d if the stored next attempt time still
+	// equals prevNextAttemptAt and reports whether it did.
+	UpdateWebhookDelivery(ctx context.Context, d *WebhookDelivery, prevNextAttemptAt *time.Time) (bool, error)
+}
+
+const (
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookRetryDelay  = 10 * time.Second
+	maxWebhookRetryDelay      = time.Hour
+	webhookTimeout            = 10 * time.Second
+	// webhookConcurrency limits the requests in flight per DeliverDueWebhooks call.
+	webhookConcurrency = 8
+	dueWebhooksBatch   = 100
+)
+
+// WithWebhookRepository enables webhooks.
+func WithWebhookRepository(r WebhookRepository) ServiceOption {
+	return func(s *service) { s.webhooks = r }
+}
+
+// WithWebhookRetry sets how often a webhook delivery is attempted and the
+// delay before the first retry, which doubles on every further retry.
+// Defaults to 8 attempts and 10s.
+func WithWebhookRetry(maxAttempts int, delay time.Duration) ServiceOption {
+	return func(s *service) {
+		if maxAttempts > 0 {
+			s.webhookMaxAttempts = uint32(maxAttempts)
+		}
+		if delay > 0 {
+			s.webhookRetryDelay = delay
+		}
+	}
+}
+
+// WithWebhookHTTPClient sets the client webhook requests are sent with. The
+// client is used as is: it is not restricted to public addresses.
+func WithWebhookHTTPClient(c *http.Client) ServiceOption {
+	return func(s *service) { s.webhookClient = c }
+}
+
+// WithWebhookPrivateTargets allows webhooks to loopback, private and
+// link-local addresses, for deployments whose receivers run on the local
+// network. By default only public addresses are accepted.
+func WithWebhookPrivateTargets(allow bool) ServiceOption {
+	return func(s *service) { s.webhookAllowPrivate = allow }
+}
+
+// WebhookSignature returns the value of the X-Goload-Signature header for a
+// payload: "sha256=" followed by the hex encoded HMAC-SHA256 of the body.
+func WebhookSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (s *service) validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "webhook url must be an absolute http or https URL"}
+	}
+	if s.webhookAllowPrivate {
+		return nil
+	}
+	if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+		return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "webhook url must point to a public address", Cause: err}
+	}
+	return nil
+}
+
+func validateWebhookEvents(evs []events.EventType) error {
+	for _, ev := range evs {
+		if !slices.Contains(WebhookEvents, ev) {
+			return &errors.Error{Code: errors.ErrCodeInvalidInput, Message: fmt.Sprintf("unknown webhook event %q", ev)}
+		}
+	}
+	return nil
+}
+
+func (s *service) CreateWebhook(ctx context.Context, param *CreateWebhookParam) (*Webhook, error) {
+	if s.webhooks == nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "webhooks not configured"}
+	}
+	param.URL = strings.TrimSpace(param.URL)
+	if err := s.validateWebhookURL(ctx, param.URL); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookEvents(param.Events); err != nil {
+		return nil, err
+	}
+
+	secret := param.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate webhook secret", Cause: err}
+		}
+	}
+
+	w, err := s.webhooks.CreateWebhook(ctx, &Webhook{
+		OfAccountID: param.OfAccountID,
+		URL:         param.URL,
+		Secret:      secret,
+		Events:      param.Events,
+		Enabled:     true,
+	})
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to create webhook", Cause: err}
+	}
+	return w, nil
+}
+
+func (s *service) ListWebhooks(ctx context.Context, ofAccountID uint64, limit, offset int32) ([]*Webhook, error) {
+	if s.webhooks == nil {
+		return nil, nil
+	}
+	if limit < 0 || offset < 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "limit and offset must be non-negative"}
+	}
+	webhooks, err := s.webhooks.ListWebhooksOfAccount(ctx, ofAccountID, uint32(limit), uint32(offset))
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list webhooks", Cause: err}
+	}
+	return webhooks, nil
+}
+
+func (s *service) GetWebhook(ctx context.Context, id uint64) (*Webhook, error) {
+	if s.webhooks == nil {
+		return nil, &errors.Error{Code: errors.ErrCodeNotFound, Message: "webhook not found"}
+	}
+	w, err := s.webhooks.GetWebhook(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return nil, &errors.Error{Code: errors.ErrCodeNotFound, Message: "webhook not found", Cause: err}
+		}
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get webhook", Cause: err}
+	}
+	return w, nil
+}
+
+func (s *service) UpdateWebhook(ctx context.Context, param *UpdateWebhookParam) (*Webhook, error) {
+	w, err := s.GetWebhook(ctx, param.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if param.URL != nil {
+		u := strings.TrimSpace(*param.URL)
+		if err := s.validateWebhookURL(ctx, u); err != nil {
+			return nil, err
+		}
+		w.URL = u
+	}
+	if param.Events != nil {
+		if err := validateWebhookEvents(*param.Events); err != nil {
+			return nil, err
+		}
+		w.Events = *param.Events
+	}
+	if param.Enabled != nil {
+		w.Enabled = *param.Enabled
+	}
+	if param.RotateSecret {
+		if w.Secret, err = newWebhookSecret(); err != nil {
+			return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to generate webhook secret", Cause: err}
+		}
+	}
+
+	if err := s.webhooks.UpdateWebhook(ctx, w); err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to update webhook", Cause: err}
+	}
+	return w, nil
+}
+
+func (s *service) DeleteWebhook(ctx context.Context, id uint64) error {
+	if _, err := s.GetWebhook(ctx, id); err != nil {
+		return err
+	}
+	if err := s.tx.DoInTx(ctx, func(ctx context.Context) error {
+		return s.webhooks.DeleteWebhook(ctx, id)
+	}); err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to delete webhook", Cause: err}
+	}
+	return nil
+}
+
+func (s *service) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uint64,
+	limit, offset int32,
+) ([]*WebhookDelivery, error) {
+	if s.webhooks == nil {
+		return nil, nil
+	}
+	if limit < 0 || offset < 0 {
+		return nil, &errors.Error{Code: errors.ErrCodeInvalidInput, Message: "limit and offset must be non-negative"}
+	}
+	deliveries, err := s.webhooks.ListWebhookDeliveries(ctx, webhookID, uint32(limit), uint32(offset))
+	if err != nil {
+		return nil, &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list webhook deliveries", Cause: err}
+	}
+	return deliveries, nil
+}
+
+// WebhookRunner queues and sends webhook notifications. It is implemented by
+// the service returned from NewService.
+type WebhookRunner interface {
+	QueueWebhooks(ctx context.Context, n *WebhookNotification) error
+	DeliverDueWebhooks(ctx context.Context, now time.Time) (int, error)
+	// WebhooksQueued receives a value when new deliveries were queued.
+	WebhooksQueued() <-chan struct{}
+}
+
+// QueueWebhooks queues a delivery of n to every enabled webhook of the task's
+// account that subscribes to the event. Events of deleted tasks are dropped.
+func (s *service) QueueWebhooks(ctx context.Context, n *WebhookNotification) error {
+	if s.webhooks == nil {
+		return nil
+	}
+
+	ofAccountID := n.OfAccountID
+	if ofAccountID == 0 {
+		t, err := s.repo.GetByID(ctx, n.TaskID)
+		if err != nil {
+			if stderrors.Is(err, errors.ErrNotFound) {
+				return nil
+			}
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to get task", Cause: err}
+		}
+		ofAccountID = t.OfAccountID
+	}
+
+	webhooks, err := s.webhooks.ListEnabledWebhooksOfAccount(ctx, ofAccountID)
+	if err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to list webhooks", Cause: err}
+	}
+
+	eventTime := n.Time
+	if eventTime.IsZero() {
+		eventTime = time.Now()
+	}
+	payload, err := json.Marshal(WebhookPayload{
+		ID:          n.EventID,
+		Event:       n.Event,
+		TaskID:      n.TaskID,
+		OfAccountID: ofAccountID,
+		Data:        n.Data,
+		CreatedAt:   eventTime.UTC(),
+	})
+	if err != nil {
+		return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to encode webhook payload", Cause: err}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	var queued bool
+	for _, w := range webhooks {
+		if !w.Subscribes(n.Event) {
+			continue
+		}
+		created, err := s.webhooks.CreateWebhookDelivery(ctx, &WebhookDelivery{
+			WebhookID:     w.ID,
+			EventID:       n.EventID,
+			Event:         n.Event,
+			TaskID:        n.TaskID,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: &now,
+		})
+		if err != nil {
+			return &errors.Error{Code: errors.ErrCodeInternal, Message: "failed to queue webhook delivery", Cause: err}
+		}
+		queued = queued || created
+	}
+
+	if queued {
+		select {
+		case s.webhookQueued <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *service) WebhooksQueued() <-chan struct{} {
+	return s.webhookQueued
+}
+
+// DeliverDueWebhooks attempts the deliveries that are due at now and returns
+// the number of attempts made.
+func (s *service) DeliverDueWebhooks(ctx context.Context, now time.Time) (int, error) {
+	if s.webhooks == nil {
+		return 0, nil
+	}
+	due, err := s.webhooks.ListDueWebhookDeliveries(ctx, now, dueWebhooksBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		attempted int
+		sem       = make(chan struct{}, webhookConcurrency)
+	)
+	for _, d := range due {
+		// Claim the delivery for the duration of the attempt so that
+		// concurrent dispatchers send it only once. Should the attempt not
+		// be recorded, the delivery is retried when the lease ends.
+		prev := d.NextAttemptAt
+		lease := now.UTC().Add(2 * webhookTimeout).Truncate(time.Second)
+		d.NextAttemptAt = &lease
+		ok, err := s.webhooks.UpdateWebhookDelivery(ctx, d, prev)
+		if err != nil {
+			level.Error(s.logger).Log("msg", "failed to claim webhook delivery", "delivery_id", d.ID, "err", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(d *WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := s.deliverWebhook(ctx, d); err != nil {
+				level.Error(s.logger).Log("msg", "failed to record webhook delivery", "delivery_id", d.ID, "err", err)
+				return
+			}
+			mu.Lock()
+			attempted++
+			mu.Unlock()
+		}(d)
+	}
+	wg.Wait()
+	return attempted, nil
+}
+
+// deliverWebhook makes one attempt of a claimed delivery and records its outcome.
+func (s *service) deliverWebhook(ctx context.Context, d *WebhookDelivery) error {
+	lease := d.NextAttemptAt
+
+	w, err := s.webhooks.GetWebhook(ctx, d.WebhookID)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			// The webhook and its deliveries were deleted meanwhile.
+			return nil
+		}
+		return err
+	}
+
+	d.Attempts++
+	var attemptErr error
+	if !w.Enabled {
+		attemptErr = stderrors.New("webhook disabled")
+		d.Attempts = max(d.Attempts, s.webhookMaxAttempts)
+	} else {
+		d.ResponseCode, attemptErr = s.postWebhook(ctx, w, d)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	switch {
+	case attemptErr == nil:
+		d.Status = DeliverySucceeded
+		d.NextAttemptAt = nil
+		d.LastError = nil
+		d.DeliveredAt = &now
+	case d.Attempts >= s.webhookMaxAttempts:
+		msg := attemptErr.Error()
+		d.Status = DeliveryFailed
+		d.NextAttemptAt = nil
+		d.LastError = &msg
+	default:
+		msg := attemptErr.Error()
+		next := now.Add(s.webhookBackoff(d.Attempts))
+		d.NextAttemptAt = &next
+		d.LastError = &msg
+	}
+
+	// Record the outcome even when ctx was cancelled during the request.
+	_, err = s.webhooks.UpdateWebhookDelivery(context.WithoutCancel(ctx), d, lease)
+	return err
+}
+
+// webhookBackoff returns the delay after the given number of failed attempts.
+func (s *service) webhookBackoff(attempts uint32) time.Duration {
+	delay := s.webhookRetryDelay
+	for i := uint32(1); i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxWebhookRetryDelay)
+}
+
+// postWebhook sends the payload of d and returns the response status code.
+// Any status other than 2xx is an error.
+func (s *service) postWebhook(ctx context.Context, w *Webhook, d *WebhookDelivery) (int32, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "goload-webhook")
+	req.Header.Set("X-Goload-Event", string(d.Event))
+	req.Header.Set("X-Goload-Delivery", strconv.FormatUint(d.ID, 10))
+	req.Header.Set("X-Goload-Signature", WebhookSignature(w.Secret, d.Payload))
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return int32(resp.StatusCode), fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return int32(resp.StatusCode), nil
+}
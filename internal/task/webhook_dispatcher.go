@@ -0,0 +1,72 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// WebhookDispatcher sends queued webhook deliveries as soon as they are
+// queued and retries failed ones when they are due.
+type WebhookDispatcher struct {
+	runner   WebhookRunner
+	interval time.Duration
+	logger   log.Logger
+}
+
+// WebhookDispatcherOption configures a WebhookDispatcher.
+type WebhookDispatcherOption func(*WebhookDispatcher)
+
+// WithWebhookDispatcherInterval sets how often due retries are polled. Defaults to 5s.
+func WithWebhookDispatcherInterval(d time.Duration) WebhookDispatcherOption {
+	return func(w *WebhookDispatcher) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithWebhookDispatcherLogger configures the dispatcher logger.
+func WithWebhookDispatcherLogger(l log.Logger) WebhookDispatcherOption {
+	return func(w *WebhookDispatcher) { w.logger = l }
+}
+
+func NewWebhookDispatcher(runner WebhookRunner, opts ...WebhookDispatcherOption) *WebhookDispatcher {
+	w := &WebhookDispatcher{
+		runner:   runner,
+		interval: 5 * time.Second,
+		logger:   log.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(w)
+	}
+	return w
+}
+
+// Run delivers due webhooks until ctx is done.
+func (w *WebhookDispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := w.runner.DeliverDueWebhooks(ctx, time.Now())
+		if err != nil {
+			level.Error(w.logger).Log("msg", "failed to deliver webhooks", "err", err)
+		} else if n > 0 {
+			level.Debug(w.logger).Log("msg", "attempted webhook deliveries", "count", n)
+		}
+		if n >= dueWebhooksBatch {
+			// More deliveries may be due; do not wait for the next tick.
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-w.runner.WebhooksQueued():
+		}
+	}
+}
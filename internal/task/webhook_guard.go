@@ -0,0 +1,114 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// blockedWebhookPrefixes are the ranges, on top of the loopback, private,
+// link-local, multicast and unspecified ones, webhooks are not sent to.
+var blockedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64 maps onto IPv4
+}
+
+// webhookAddrAllowed reports whether webhooks may be sent to addr. Loopback,
+// private, link-local (including cloud metadata endpoints such as
+// 169.254.169.254) and other non-public addresses are refused.
+func webhookAddrAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, p := range blockedWebhookPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// internalWebhookHost reports whether host names a machine of the local
+// network rather than a public one.
+func internalWebhookHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".localdomain", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhookHost refuses a webhook host that is, or resolves to, an
+// address webhooks may not be sent to. Names that do not resolve yet are
+// accepted; the dialer checks every address again when delivering.
+func checkWebhookHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !webhookAddrAllowed(addr) {
+			return fmt.Errorf("webhook address %s is not public", host)
+		}
+		return nil
+	}
+	if internalWebhookHost(host) {
+		return fmt.Errorf("webhook host %s is not public", host)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !webhookAddrAllowed(addr) {
+			return fmt.Errorf("webhook host %s resolves to %s, which is not public", host, addr)
+		}
+	}
+	return nil
+}
+
+// guardWebhookDial is a net.Dialer Control func refusing connections to
+// addresses webhooks may not be sent to. It runs after name resolution, so
+// a host that changes its DNS records after registration is still refused.
+func guardWebhookDial(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !webhookAddrAllowed(addrPort.Addr()) {
+		return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+	}
+	return nil
+}
+
+// newWebhookClient returns the client webhook requests are sent with. Unless
+// allowPrivate is set, it only connects to public addresses. Proxies from
+// the environment are ignored so the check applies to the target itself.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = guardWebhookDial
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
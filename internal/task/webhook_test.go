@@ -0,0 +1,384 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/yuisofull/goload/internal/errors"
+	"github.com/yuisofull/goload/internal/events"
+)
+
+type fakeWebhookRepo struct {
+	mu             sync.Mutex
+	webhooks       map[uint64]*Webhook
+	deliveries     map[uint64]*WebhookDelivery
+	nextID         uint64
+	nextDeliveryID uint64
+}
+
+func newFakeWebhookRepo() *fakeWebhookRepo {
+	return &fakeWebhookRepo{webhooks: map[uint64]*Webhook{}, deliveries: map[uint64]*WebhookDelivery{}}
+}
+
+func (r *fakeWebhookRepo) CreateWebhook(ctx context.Context, w *Webhook) (*Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	w.ID = r.nextID
+	stored := *w
+	r.webhooks[w.ID] = &stored
+	return w, nil
+}
+
+func (r *fakeWebhookRepo) GetWebhook(ctx context.Context, id uint64) (*Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	cloned := *w
+	return &cloned, nil
+}
+
+func (r *fakeWebhookRepo) ListWebhooksOfAccount(
+	ctx context.Context,
+	ofAccountID uint64,
+	limit, offset uint32,
+) ([]*Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*Webhook
+	for _, w := range r.webhooks {
+		if w.OfAccountID == ofAccountID {
+			cloned := *w
+			out = append(out, &cloned)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeWebhookRepo) ListEnabledWebhooksOfAccount(ctx context.Context, ofAccountID uint64) ([]*Webhook, error) {
+	all, _ := r.ListWebhooksOfAccount(ctx, ofAccountID, 0, 0)
+	var out []*Webhook
+	for _, w := range all {
+		if w.Enabled {
+			out = append(out, w)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeWebhookRepo) UpdateWebhook(ctx context.Context, w *Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *w
+	r.webhooks[w.ID] = &stored
+	return nil
+}
+
+func (r *fakeWebhookRepo) DeleteWebhook(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.webhooks, id)
+	for did, d := range r.deliveries {
+		if d.WebhookID == id {
+			delete(r.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) CreateWebhookDelivery(ctx context.Context, d *WebhookDelivery) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cur := range r.deliveries {
+		if cur.WebhookID == d.WebhookID && cur.EventID == d.EventID {
+			return false, nil
+		}
+	}
+	r.nextDeliveryID++
+	d.ID = r.nextDeliveryID
+	stored := *d
+	r.deliveries[d.ID] = &stored
+	return true, nil
+}
+
+func (r *fakeWebhookRepo) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uint64,
+	limit, offset uint32,
+) ([]*WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.WebhookID == webhookID {
+			cloned := *d
+			out = append(out, &cloned)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeWebhookRepo) ListDueWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	limit uint32,
+) ([]*WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			cloned := *d
+			out = append(out, &cloned)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeWebhookRepo) UpdateWebhookDelivery(
+	ctx context.Context,
+	d *WebhookDelivery,
+	prevNextAttemptAt *time.Time,
+) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.deliveries[d.ID]
+	if !ok {
+		return false, nil
+	}
+	if (cur.NextAttemptAt == nil) != (prevNextAttemptAt == nil) ||
+		(cur.NextAttemptAt != nil && !cur.NextAttemptAt.Equal(*prevNextAttemptAt)) {
+		return false, nil
+	}
+	stored := *d
+	r.deliveries[d.ID] = &stored
+	return true, nil
+}
+
+func (r *fakeWebhookRepo) delivery(id uint64) WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[id]
+}
+
+func TestDeliverDueWebhooks_SendsSignedPayload(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	var (
+		mu       sync.Mutex
+		received []request
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, request{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	webhooks := newFakeWebhookRepo()
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{},
+		WithWebhookRepository(webhooks),
+		WithWebhookPrivateTargets(true),
+	)
+	ctx := context.Background()
+
+	all, err := svc.CreateWebhook(ctx, &CreateWebhookParam{OfAccountID: 7, URL: srv.URL, Secret: "s3cret"})
+	require.NoError(t, err)
+	_, err = svc.CreateWebhook(ctx, &CreateWebhookParam{
+		OfAccountID: 7,
+		URL:         srv.URL,
+		Events:      []events.EventType{events.EventTaskFailed},
+	})
+	require.NoError(t, err)
+
+	runner := svc.(WebhookRunner)
+	n := &WebhookNotification{
+		EventID:     "evt-1",
+		Event:       events.EventTaskCompleted,
+		TaskID:      50,
+		OfAccountID: 7,
+		Data:        json.RawMessage(`{"task_id":50}`),
+		Time:        time.Date(2025, 1, 31, 3, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, runner.QueueWebhooks(ctx, n))
+	// A redelivered event is queued only once.
+	require.NoError(t, runner.QueueWebhooks(ctx, n))
+	require.Len(t, webhooks.deliveries, 1)
+	select {
+	case <-runner.WebhooksQueued():
+	default:
+		t.Fatal("expected a queued signal")
+	}
+
+	attempted, err := runner.DeliverDueWebhooks(ctx, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, attempted)
+
+	require.Len(t, received, 1)
+	req := received[0]
+	require.Equal(t, WebhookSignature("s3cret", req.body), req.header.Get("X-Goload-Signature"))
+	require.Equal(t, "task.completed", req.header.Get("X-Goload-Event"))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	require.Equal(t, "evt-1", payload.ID)
+	require.Equal(t, uint64(50), payload.TaskID)
+	require.Equal(t, uint64(7), payload.OfAccountID)
+	require.JSONEq(t, `{"task_id":50}`, string(payload.Data))
+
+	d := webhooks.delivery(1)
+	require.Equal(t, all.ID, d.WebhookID)
+	require.Equal(t, DeliverySucceeded, d.Status)
+	require.Equal(t, uint32(1), d.Attempts)
+	require.Equal(t, int32(http.StatusNoContent), d.ResponseCode)
+	require.Nil(t, d.NextAttemptAt)
+	require.NotNil(t, d.DeliveredAt)
+}
+
+func TestDeliverDueWebhooks_RetriesWithBackoffUntilFailed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	webhooks := newFakeWebhookRepo()
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{},
+		WithWebhookRepository(webhooks),
+		WithWebhookRetry(2, time.Minute),
+		WithWebhookPrivateTargets(true),
+	)
+	ctx := context.Background()
+	_, err := svc.CreateWebhook(ctx, &CreateWebhookParam{OfAccountID: 7, URL: srv.URL})
+	require.NoError(t, err)
+
+	runner := svc.(WebhookRunner)
+	require.NoError(t, runner.QueueWebhooks(ctx, &WebhookNotification{
+		EventID:     "evt-1",
+		Event:       events.EventTaskFailed,
+		TaskID:      50,
+		OfAccountID: 7,
+	}))
+
+	before := time.Now()
+	_, err = runner.DeliverDueWebhooks(ctx, before)
+	require.NoError(t, err)
+
+	d := webhooks.delivery(1)
+	require.Equal(t, DeliveryPending, d.Status)
+	require.Equal(t, uint32(1), d.Attempts)
+	require.Equal(t, int32(http.StatusInternalServerError), d.ResponseCode)
+	require.NotNil(t, d.LastError)
+	require.NotNil(t, d.NextAttemptAt)
+	require.False(t, d.NextAttemptAt.Before(before.Add(time.Minute).Truncate(time.Second)))
+
+	// Not due yet.
+	attempted, err := runner.DeliverDueWebhooks(ctx, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, attempted)
+
+	attempted, err = runner.DeliverDueWebhooks(ctx, time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, attempted)
+
+	d = webhooks.delivery(1)
+	require.Equal(t, DeliveryFailed, d.Status)
+	require.Equal(t, uint32(2), d.Attempts)
+	require.Nil(t, d.NextAttemptAt)
+}
+
+func TestWebhookBackoff_DoublesUpToCap(t *testing.T) {
+	s := &service{webhookRetryDelay: 10 * time.Second}
+
+	require.Equal(t, 10*time.Second, s.webhookBackoff(1))
+	require.Equal(t, 20*time.Second, s.webhookBackoff(2))
+	require.Equal(t, 80*time.Second, s.webhookBackoff(4))
+	require.Equal(t, maxWebhookRetryDelay, s.webhookBackoff(30))
+}
+
+func TestCreateWebhook_RejectsInvalidInput(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, WithWebhookRepository(newFakeWebhookRepo()))
+
+	_, err := svc.CreateWebhook(context.Background(), &CreateWebhookParam{URL: "ftp://example.com/hook"})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+
+	_, err = svc.CreateWebhook(context.Background(), &CreateWebhookParam{
+		URL:    "https://example.com/hook",
+		Events: []events.EventType{events.EventTaskProgressUpdated},
+	})
+	require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput))
+}
+
+func TestCreateWebhook_RejectsNonPublicTargets(t *testing.T) {
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, WithWebhookRepository(newFakeWebhookRepo()))
+
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::ffff:169.254.169.254]/",
+		"http://metadata.google.internal/",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := svc.CreateWebhook(context.Background(), &CreateWebhookParam{OfAccountID: 7, URL: u})
+		require.True(t, apperrors.IsError(err, apperrors.ErrCodeInvalidInput), u)
+	}
+
+	_, err := svc.CreateWebhook(context.Background(), &CreateWebhookParam{OfAccountID: 7, URL: "https://203.0.113.10/hook"})
+	require.NoError(t, err)
+}
+
+func TestDeliverDueWebhooks_RefusesToDialNonPublicAddresses(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	// The webhook was registered while its host still resolved to a public
+	// address; delivery must still refuse the loopback listener.
+	webhooks := newFakeWebhookRepo()
+	_, err := webhooks.CreateWebhook(context.Background(), &Webhook{
+		OfAccountID: 7,
+		URL:         srv.URL,
+		Enabled:     true,
+	})
+	require.NoError(t, err)
+
+	svc := NewService(&fakeRepo{}, Publisher{}, fakeTxManager{}, WithWebhookRepository(webhooks))
+	runner := svc.(WebhookRunner)
+	ctx := context.Background()
+	require.NoError(t, runner.QueueWebhooks(ctx, &WebhookNotification{
+		EventID:     "evt-1",
+		Event:       events.EventTaskFailed,
+		TaskID:      50,
+		OfAccountID: 7,
+	}))
+
+	_, err = runner.DeliverDueWebhooks(ctx, time.Now())
+	require.NoError(t, err)
+	require.False(t, called)
+
+	d := webhooks.delivery(1)
+	require.Equal(t, DeliveryPending, d.Status)
+	require.NotNil(t, d.LastError)
+	require.Contains(t, *d.LastError, "not public")
+}
@@ -0,0 +1,16 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS download_queue;
+
+-- +migrate Up
+CREATE TABLE
+    IF NOT EXISTS download_queue (
+        task_id BIGINT UNSIGNED NOT NULL,
+        request JSON NOT NULL,
+        state VARCHAR(32) NOT NULL,
+        downloaded_bytes BIGINT NOT NULL DEFAULT 0,
+        total_bytes BIGINT NOT NULL DEFAULT 0,
+        enqueued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        PRIMARY KEY (task_id),
+        INDEX (enqueued_at)
+    );
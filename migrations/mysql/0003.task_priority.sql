@@ -0,0 +1,20 @@
+-- +migrate Down
+# ALTER TABLE tasks DROP COLUMN priority;
+
+-- +migrate Up
+-- MySQL has no ADD COLUMN IF NOT EXISTS; migrations are re-applied on every
+-- start, so the column is only added when it is missing.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT ''NORMAL''',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND COLUMN_NAME = 'priority'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
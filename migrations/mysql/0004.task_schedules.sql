@@ -0,0 +1,36 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS task_schedules;
+# ALTER TABLE tasks DROP COLUMN schedule_id;
+
+-- +migrate Up
+CREATE TABLE
+    IF NOT EXISTS task_schedules (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        of_account_id BIGINT UNSIGNED NOT NULL,
+        cron_expr VARCHAR(255) NOT NULL DEFAULT '',
+        start_at DATETIME,
+        enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        next_run_at DATETIME,
+        last_run_at DATETIME,
+        next_task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        template JSON NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        INDEX (of_account_id),
+        INDEX (enabled, next_run_at)
+    );
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD COLUMN schedule_id BIGINT UNSIGNED NOT NULL DEFAULT 0',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND COLUMN_NAME = 'schedule_id'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,37 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS task_webhook_deliveries;
+# DROP TABLE IF EXISTS task_webhooks;
+
+-- +migrate Up
+CREATE TABLE
+    IF NOT EXISTS task_webhooks (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        of_account_id BIGINT UNSIGNED NOT NULL,
+        url TEXT NOT NULL,
+        secret VARCHAR(255) NOT NULL,
+        events JSON,
+        enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        INDEX (of_account_id)
+    );
+
+CREATE TABLE
+    IF NOT EXISTS task_webhook_deliveries (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        webhook_id BIGINT UNSIGNED NOT NULL,
+        event_id VARCHAR(64) NOT NULL,
+        event VARCHAR(64) NOT NULL,
+        task_id BIGINT UNSIGNED NOT NULL,
+        payload TEXT NOT NULL, -- the exact bytes that are signed
+        status VARCHAR(16) NOT NULL,
+        attempts INT UNSIGNED NOT NULL DEFAULT 0,
+        next_attempt_at DATETIME,
+        response_code INT NOT NULL DEFAULT 0,
+        last_error TEXT,
+        delivered_at DATETIME,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        UNIQUE (webhook_id, event_id),
+        INDEX (status, next_attempt_at)
+    );
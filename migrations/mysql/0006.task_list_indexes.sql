@@ -0,0 +1,84 @@
+-- +migrate Down
+# ALTER TABLE tasks DROP INDEX idx_tasks_account_created;
+# ALTER TABLE tasks DROP INDEX idx_tasks_account_updated;
+# ALTER TABLE tasks DROP INDEX idx_tasks_account_size;
+# ALTER TABLE tasks DROP INDEX idx_tasks_account_name;
+# ALTER TABLE tasks DROP INDEX idx_tasks_account_status;
+
+-- +migrate Up
+-- Task listings filter by account and sort by one of these columns with the
+-- id as tie breaker for cursors. Indexes are only added when missing.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_account_created (of_account_id, created_at, id)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_account_created'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_account_updated (of_account_id, updated_at, id)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_account_updated'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_account_size (of_account_id, total_bytes, id)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_account_size'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_account_name (of_account_id, file_name(191), id)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_account_name'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_account_status (of_account_id, status, created_at)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_account_status'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
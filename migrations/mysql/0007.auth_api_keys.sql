@@ -0,0 +1,20 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS api_keys;
+
+-- +migrate Up
+CREATE TABLE
+    IF NOT EXISTS api_keys (
+        id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+        of_account_id BIGINT UNSIGNED NOT NULL,
+        name VARCHAR(128) NOT NULL,
+        prefix VARCHAR(32) NOT NULL, -- public part of the key, used for lookup
+        hashed_secret VARCHAR(128) NOT NULL,
+        scopes VARCHAR(255) NOT NULL, -- space separated
+        expires_at DATETIME,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        revoked_at DATETIME,
+        PRIMARY KEY (id),
+        UNIQUE (prefix),
+        INDEX (of_account_id),
+        FOREIGN KEY (of_account_id) REFERENCES accounts (id) ON DELETE CASCADE
+    );
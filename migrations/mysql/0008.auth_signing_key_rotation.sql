@@ -0,0 +1,104 @@
+-- +migrate Down
+# ALTER TABLE token_public_keys
+#     DROP INDEX idx_token_public_keys_state,
+#     DROP COLUMN retired_at,
+#     DROP COLUMN activated_at,
+#     DROP COLUMN created_at,
+#     DROP COLUMN state,
+#     DROP COLUMN private_key;
+
+-- +migrate Up
+-- Keys of replicas from before rotation have no private key. They stay
+-- active, so their tokens verify, until the first rotated key retires them.
+-- private_key is the PEM shared by every auth replica and state is next,
+-- active or retired. Migrations are re-applied on every start, so columns
+-- and the index are only added when they are missing.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE token_public_keys ADD COLUMN private_key TEXT',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'token_public_keys'
+      AND COLUMN_NAME = 'private_key'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE token_public_keys ADD COLUMN state VARCHAR(16) NOT NULL DEFAULT ''active''',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'token_public_keys'
+      AND COLUMN_NAME = 'state'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE token_public_keys ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'token_public_keys'
+      AND COLUMN_NAME = 'created_at'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE token_public_keys ADD COLUMN activated_at DATETIME',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'token_public_keys'
+      AND COLUMN_NAME = 'activated_at'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE token_public_keys ADD COLUMN retired_at DATETIME',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'token_public_keys'
+      AND COLUMN_NAME = 'retired_at'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE token_public_keys ADD INDEX idx_token_public_keys_state (state)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'token_public_keys'
+      AND INDEX_NAME = 'idx_token_public_keys_state'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
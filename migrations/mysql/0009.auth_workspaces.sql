@@ -0,0 +1,24 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS workspace_members;
+# DROP TABLE IF EXISTS workspaces;
+
+-- +migrate Up
+CREATE TABLE
+    IF NOT EXISTS workspaces (
+        id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+        name VARCHAR(128) NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (id)
+    );
+
+CREATE TABLE
+    IF NOT EXISTS workspace_members (
+        workspace_id BIGINT UNSIGNED NOT NULL,
+        account_id BIGINT UNSIGNED NOT NULL,
+        role VARCHAR(16) NOT NULL, -- viewer, operator or admin
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (workspace_id, account_id),
+        INDEX (account_id),
+        FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+        FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+    );
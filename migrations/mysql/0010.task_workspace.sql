@@ -0,0 +1,37 @@
+-- +migrate Down
+# ALTER TABLE tasks DROP INDEX idx_tasks_workspace_created;
+# ALTER TABLE tasks DROP COLUMN workspace_id;
+
+-- +migrate Up
+-- Tasks with workspace_id 0 belong to of_account_id alone; others are shared
+-- with the members of the workspace. Migrations are re-applied on every
+-- start, so the column and index are only added when they are missing.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD COLUMN workspace_id BIGINT UNSIGNED NOT NULL DEFAULT 0',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND COLUMN_NAME = 'workspace_id'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_workspace_created (workspace_id, created_at, id)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_workspace_created'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,14 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS account_identities;
+
+-- +migrate Up
+CREATE TABLE
+    IF NOT EXISTS account_identities (
+        of_account_id BIGINT UNSIGNED NOT NULL,
+        issuer VARCHAR(255) NOT NULL, -- OpenID Connect issuer identifier
+        subject VARCHAR(255) NOT NULL,
+        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (issuer, subject),
+        INDEX (of_account_id),
+        FOREIGN KEY (of_account_id) REFERENCES accounts (id) ON DELETE CASCADE
+    );
@@ -0,0 +1,23 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS task_audit_events;
+
+-- +migrate Up
+-- Append-only: rows are never updated or deleted by the services.
+CREATE TABLE
+    IF NOT EXISTS task_audit_events (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        time DATETIME(3) NOT NULL,
+        actor_account_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        actor_api_key_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        action VARCHAR(64) NOT NULL,
+        task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        of_account_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        workspace_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        source_ip VARCHAR(45) NOT NULL DEFAULT '',
+        outcome VARCHAR(16) NOT NULL,
+        message TEXT,
+        INDEX (actor_account_id, id),
+        INDEX (of_account_id, id),
+        INDEX (workspace_id, id),
+        INDEX (task_id, id)
+    );
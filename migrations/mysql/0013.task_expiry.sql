@@ -0,0 +1,24 @@
+-- +migrate Down
+# ALTER TABLE tasks DROP INDEX idx_tasks_status_accessed;
+# ALTER TABLE tasks MODIFY last_accessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
+
+-- +migrate Up
+-- last_accessed_at now only moves when the stored file is accessed, so the
+-- retention of a task is not restarted by unrelated updates. Tasks created
+-- before expiration_days was written keep NULL and never expire.
+ALTER TABLE tasks MODIFY last_accessed_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_status_accessed (status, last_accessed_at)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_status_accessed'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,54 @@
+-- +migrate Down
+# ALTER TABLE tasks DROP INDEX idx_tasks_storage_path;
+# ALTER TABLE tasks DROP COLUMN source_last_modified;
+# ALTER TABLE tasks DROP COLUMN source_etag;
+
+-- +migrate Up
+-- Completed tasks record the validators their source reported, so a new task
+-- of the same versioned source can reuse the stored file. Reused tasks share
+-- the storage path of the task they reuse, which is counted before the file
+-- is deleted.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD COLUMN source_etag VARCHAR(255) NOT NULL DEFAULT ''''',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND COLUMN_NAME = 'source_etag'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD COLUMN source_last_modified VARCHAR(64) NOT NULL DEFAULT ''''',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND COLUMN_NAME = 'source_last_modified'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD INDEX idx_tasks_storage_path (storage_path(191))',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND INDEX_NAME = 'idx_tasks_storage_path'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,53 @@
+-- +migrate Down
+# ALTER TABLE download_queue DROP INDEX idx_download_queue_lease_owner;
+# ALTER TABLE download_queue DROP COLUMN lease_expires_at;
+# ALTER TABLE download_queue DROP COLUMN lease_owner;
+
+-- +migrate Up
+-- Download workers lease the queued tasks they execute and renew the lease
+-- while they run, so that the tasks of a worker that stopped are taken over
+-- by another one.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE download_queue ADD COLUMN lease_owner VARCHAR(255) NOT NULL DEFAULT ''''',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'download_queue'
+      AND COLUMN_NAME = 'lease_owner'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE download_queue ADD COLUMN lease_expires_at DATETIME(3) NULL',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'download_queue'
+      AND COLUMN_NAME = 'lease_expires_at'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
+
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE download_queue ADD INDEX idx_download_queue_lease_owner (lease_owner)',
+        'SELECT 1'
+    )
+    FROM information_schema.STATISTICS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'download_queue'
+      AND INDEX_NAME = 'idx_download_queue_lease_owner'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
@@ -0,0 +1,20 @@
+-- +migrate Down
+# ALTER TABLE tasks DROP COLUMN worker_id;
+
+-- +migrate Up
+-- Tasks record the download worker that claimed them, so that pause, resume
+-- and cancel requests are published to the topics of that worker.
+SET @stmt = (
+    SELECT IF(
+        COUNT(*) = 0,
+        'ALTER TABLE tasks ADD COLUMN worker_id VARCHAR(255) NOT NULL DEFAULT ''''',
+        'SELECT 1'
+    )
+    FROM information_schema.COLUMNS
+    WHERE TABLE_SCHEMA = DATABASE()
+      AND TABLE_NAME = 'tasks'
+      AND COLUMN_NAME = 'worker_id'
+);
+PREPARE stmt FROM @stmt;
+EXECUTE stmt;
+DEALLOCATE PREPARE stmt;
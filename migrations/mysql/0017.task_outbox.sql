@@ -0,0 +1,23 @@
+-- +migrate Down
+# DROP TABLE IF EXISTS task_outbox;
+
+-- +migrate Up
+-- Task events are stored in the transaction of the task writes they report
+-- and published by the outbox relay, which deletes them once published.
+-- Messages that failed to publish wait until next_attempt_at; messages that
+-- cannot be decoded are kept with dead_at set.
+CREATE TABLE
+    IF NOT EXISTS task_outbox (
+        id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+        topic VARCHAR(255) NOT NULL,
+        task_id BIGINT UNSIGNED NOT NULL DEFAULT 0,
+        message_uuid VARCHAR(64) NOT NULL,
+        payload MEDIUMTEXT NOT NULL,
+        metadata JSON NOT NULL,
+        attempts INT UNSIGNED NOT NULL DEFAULT 0,
+        next_attempt_at DATETIME,
+        last_error TEXT,
+        dead_at DATETIME,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        INDEX (task_id, next_attempt_at)
+    );
@@ -59,16 +59,14 @@ func (c *Cache[K, V]) Delete(_ context.Context, key K) error {
 
 func (c *Cache[K, V]) GetAndDelete(_ context.Context, key K) (V, error) {
 	var zero V
-	val, ok := c.data.Load(key)
+	val, ok := c.data.LoadAndDelete(key)
 	if !ok {
 		return zero, cache.Nil
 	}
 	it := val.(item[V])
 	if !it.expiration.IsZero() && time.Now().After(it.expiration) {
-		c.data.Delete(key)
 		return zero, cache.Nil
 	}
-	c.data.Delete(key)
 	return it.value, nil
 }
 
@@ -3,8 +3,10 @@ package rsa
 import (
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"errors"
+	"math/big"
 )
 
 func SerializePublicKey(publicKey *rsa.PublicKey) (pemBytes []byte, err error) {
@@ -66,3 +68,29 @@ func DeserializePrivateKey(pemBytes []byte) (privateKey *rsa.PrivateKey, err err
 
 	return rsaPriv, nil
 }
+
+// JWKParams returns the modulus and exponent of publicKey encoded for a JSON
+// Web Key (RFC 7518, section 6.3.1).
+func JWKParams(publicKey *rsa.PublicKey) (n, e string) {
+	n = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+	e = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	return n, e
+}
+
+// ParseJWK returns the public key of a JSON Web Key with the given modulus
+// and exponent. It is the inverse of JWKParams.
+func ParseJWK(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(eBytes)
+	if len(nBytes) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA JWK")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,205 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata keys set on messages that were nacked or forwarded to a
+// dead-letter topic.
+const (
+	// DeliveriesKey counts the deliveries of a message that were nacked.
+	DeliveriesKey = "_deliveries"
+	// FailureReasonKey is the reason the last delivery was nacked with.
+	FailureReasonKey = "_failure_reason"
+	// OriginalTopicKey is the topic a dead-lettered message was published to.
+	OriginalTopicKey = "_original_topic"
+	// DeadLetteredAtKey is when a message was forwarded to its dead-letter
+	// topic, in RFC 3339 format.
+	DeadLetteredAtKey = "_dead_lettered_at"
+)
+
+// deadLetterSuffix is appended to a topic to name its dead-letter topic.
+const deadLetterSuffix = ".dlq"
+
+// DeadLetterTopic returns the topic the messages of topic are forwarded to
+// once they were delivered too many times.
+func DeadLetterTopic(topic string) string {
+	return topic + deadLetterSuffix
+}
+
+// IsDeadLetterTopic reports whether topic is a dead-letter topic.
+func IsDeadLetterTopic(topic string) bool {
+	return strings.HasSuffix(topic, deadLetterSuffix)
+}
+
+// NackWithReason nacks the message like Nack, recording reason as the failure
+// reason a dead-lettered message is forwarded with.
+func (m *Message) NackWithReason(reason string) bool {
+	m.ackMutex.Lock()
+	if m.ackSentType == noAckSent {
+		m.Metadata.Set(FailureReasonKey, reason)
+	}
+	m.ackMutex.Unlock()
+	return m.Nack()
+}
+
+// Deliveries returns how many deliveries of msg were nacked.
+func Deliveries(msg *Message) int {
+	n, _ := strconv.Atoi(msg.Metadata.Get(DeliveriesKey))
+	return n
+}
+
+// DeadLetterPolicy forwards messages that were nacked MaxDeliveries times to
+// the dead-letter topic of their topic instead of redelivering them, so that
+// a message no handler can process does not block the messages after it.
+type DeadLetterPolicy struct {
+	// MaxDeliveries is how many times a message is delivered at most. Zero
+	// redelivers nacked messages forever.
+	MaxDeliveries int
+	// Publisher publishes the messages to the dead-letter topics.
+	Publisher Publisher
+}
+
+// Nacked is called after msg, received from topic, was nacked. It returns
+// the message to deliver again, or nil when msg was forwarded to the
+// dead-letter topic. When forwarding fails the message is returned together
+// with the error, to be redelivered.
+func (p *DeadLetterPolicy) Nacked(topic string, msg *Message) (*Message, error) {
+	retry := msg.Copy()
+	deliveries := Deliveries(msg) + 1
+	retry.Metadata.Set(DeliveriesKey, strconv.Itoa(deliveries))
+	if p == nil || p.MaxDeliveries <= 0 || p.Publisher == nil || deliveries < p.MaxDeliveries {
+		return retry, nil
+	}
+
+	dead := retry.Copy()
+	if dead.Metadata.Get(FailureReasonKey) == "" {
+		dead.Metadata.Set(FailureReasonKey, fmt.Sprintf("nacked %d times", deliveries))
+	}
+	dead.Metadata.Set(OriginalTopicKey, topic)
+	dead.Metadata.Set(DeadLetteredAtKey, time.Now().UTC().Format(time.RFC3339))
+	if err := p.Publisher.Publish(DeadLetterTopic(topic), dead); err != nil {
+		return retry, fmt.Errorf("cannot forward message %s to dead-letter topic: %w", msg.UUID, err)
+	}
+	return nil, nil
+}
+
+// DeadLetter is a message received from a dead-letter topic.
+type DeadLetter struct {
+	Message *Message
+	// Topic is the topic the message was published to.
+	Topic      string
+	Reason     string
+	Deliveries int
+	FailedAt   time.Time
+}
+
+func newDeadLetter(msg *Message, topic string) DeadLetter {
+	d := DeadLetter{
+		Message:    msg,
+		Topic:      msg.Metadata.Get(OriginalTopicKey),
+		Reason:     msg.Metadata.Get(FailureReasonKey),
+		Deliveries: Deliveries(msg),
+	}
+	if d.Topic == "" {
+		d.Topic = topic
+	}
+	d.FailedAt, _ = time.Parse(time.RFC3339, msg.Metadata.Get(DeadLetteredAtKey))
+	return d
+}
+
+// replayMessage returns a copy of a dead-lettered message as it was
+// originally published.
+func (d DeadLetter) replayMessage() *Message {
+	msg := d.Message.Copy()
+	for _, key := range []string{DeliveriesKey, FailureReasonKey, OriginalTopicKey, DeadLetteredAtKey} {
+		delete(msg.Metadata, key)
+	}
+	return msg
+}
+
+// DeadLetters inspects the dead-letter topics and replays their messages.
+type DeadLetters struct {
+	subscriber Subscriber
+	publisher  Publisher
+	idle       time.Duration
+}
+
+// NewDeadLetters returns DeadLetters reading dead-letter topics through
+// subscriber and replaying their messages through publisher. A topic is
+// considered drained once no message arrived for idle.
+//
+// Messages are acked once read, so Inspect should be given a subscriber of its
+// own, e.g. a Kafka consumer group used for nothing else.
+func NewDeadLetters(subscriber Subscriber, publisher Publisher, idle time.Duration) *DeadLetters {
+	if idle <= 0 {
+		idle = 5 * time.Second
+	}
+	return &DeadLetters{subscriber: subscriber, publisher: publisher, idle: idle}
+}
+
+// Inspect returns up to limit messages of the dead-letter topic of topic.
+// A limit of zero or less returns all of them.
+func (d *DeadLetters) Inspect(ctx context.Context, topic string, limit int) ([]DeadLetter, error) {
+	var letters []DeadLetter
+	err := d.read(ctx, topic, limit, func(dl DeadLetter) error {
+		letters = append(letters, dl)
+		return nil
+	})
+	return letters, err
+}
+
+// Replay publishes up to limit messages of the dead-letter topic of topic to
+// the topic they were forwarded from, with their delivery count reset, and
+// returns how many were replayed. A limit of zero or less replays all of them.
+func (d *DeadLetters) Replay(ctx context.Context, topic string, limit int) (int, error) {
+	n := 0
+	err := d.read(ctx, topic, limit, func(dl DeadLetter) error {
+		if err := d.publisher.Publish(dl.Topic, dl.replayMessage()); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+func (d *DeadLetters) read(ctx context.Context, topic string, limit int, fn func(DeadLetter) error) error {
+	if IsDeadLetterTopic(topic) {
+		return errors.New("topic must name the topic whose dead letters are read, not the dead-letter topic")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch, err := d.subscriber.Subscribe(ctx, DeadLetterTopic(topic))
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(d.idle)
+	defer timer.Stop()
+	for read := 0; limit <= 0 || read < limit; read++ {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := fn(newDeadLetter(msg, topic)); err != nil {
+				msg.Nack()
+				return err
+			}
+			msg.Ack()
+			timer.Reset(d.idle)
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
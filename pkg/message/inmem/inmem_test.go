@@ -75,3 +75,92 @@ func TestPublishSubscribe_NackRedeliver(t *testing.T) {
 		t.Fatal("timeout waiting for redelivered message")
 	}
 }
+
+func TestPublishSubscribe_NackForwardsToDeadLetterTopic(t *testing.T) {
+	pub, sub := NewPublisherAndSubscriber(log.NewNopLogger(), WithMaxDeliveries(2))
+	ctx := t.Context()
+
+	ch, err := sub.Subscribe(ctx, "topic3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlq, err := sub.Subscribe(ctx, message.DeadLetterTopic("topic3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pub.Publish("topic3", message.NewMessage("3", []byte("poison"))); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 2 {
+		select {
+		case m := <-ch:
+			m.NackWithReason("cannot handle")
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for delivery %d", i+1)
+		}
+	}
+
+	select {
+	case m := <-dlq:
+		assert.Equal(t, "3", m.UUID)
+		assert.Equal(t, "cannot handle", m.Metadata.Get(message.FailureReasonKey))
+		assert.Equal(t, "topic3", m.Metadata.Get(message.OriginalTopicKey))
+		assert.Equal(t, 2, message.Deliveries(m))
+		m.Ack()
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for dead-lettered message")
+	}
+
+	select {
+	case <-ch:
+		t.Fatal("dead-lettered message was redelivered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDeadLetters_Replay(t *testing.T) {
+	pub, sub := NewPublisherAndSubscriber(log.NewNopLogger())
+	ctx := t.Context()
+
+	ch, err := sub.Subscribe(ctx, "topic4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := message.NewDeadLetters(sub, pub, 100*time.Millisecond).Replay(ctx, "topic4", 1)
+		done <- result{n, err}
+	}()
+
+	// Dead letters are only kept while their topic has subscribers.
+	for len(pub.broker.getSubs(message.DeadLetterTopic("topic4"))) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	dead := message.NewMessage("4", []byte("poison"))
+	dead.Metadata.Set("taskID", "7")
+	dead.Metadata.Set(message.FailureReasonKey, "cannot handle")
+	dead.Metadata.Set(message.OriginalTopicKey, "topic4")
+	dead.Metadata.Set(message.DeliveriesKey, "3")
+	if err := pub.Publish(message.DeadLetterTopic("topic4"), dead); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case m := <-ch:
+		assert.Equal(t, "4", m.UUID)
+		assert.Equal(t, message.Metadata{"taskID": "7"}, m.Metadata)
+		m.Ack()
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for replayed message")
+	}
+	r := <-done
+	assert.NoError(t, r.err)
+	assert.Equal(t, 1, r.n)
+}
@@ -47,8 +47,21 @@ func (p *Publisher) Publish(topic string, msgs ...*message.Message) error {
 						return
 					case <-mm.Nacked():
 						level.Warn(p.broker.logger).Log("msg", "inmem.nacked", "msg_index", mi, "sub_index", si)
-						// create a fresh copy and resend
-						mm = mm.Copy()
+						// create a fresh copy and resend, unless it was delivered too often
+						retry, err := p.broker.deadLetters.Nacked(topic, mm)
+						if err != nil {
+							level.Error(p.broker.logger).Log("msg", "inmem.dead_letter_failed", "msg_index", mi, "err", err)
+						}
+						if retry == nil {
+							level.Warn(p.broker.logger).Log(
+								"msg", "inmem.dead_lettered",
+								"msg_index", mi,
+								"sub_index", si,
+								"reason", mm.Metadata.Get(message.FailureReasonKey),
+							)
+							return
+						}
+						mm = retry
 						mm.SetContext(subCtx)
 						select {
 						case ch <- mm:
@@ -16,17 +16,35 @@ type subscription struct {
 }
 
 type broker struct {
-	mu     sync.RWMutex
-	buffer int
-	subs   map[string][]*subscription
-	logger log.Logger
+	mu          sync.RWMutex
+	buffer      int
+	subs        map[string][]*subscription
+	logger      log.Logger
+	deadLetters *message.DeadLetterPolicy
 }
 
-func newBroker(buffer int, logger log.Logger) *broker {
+// BrokerOption configures a broker.
+type BrokerOption func(*broker)
+
+// WithMaxDeliveries forwards messages that were nacked n times to the
+// dead-letter topic of their topic instead of redelivering them. Like any
+// topic, a dead-letter topic only keeps messages while it has subscribers.
+func WithMaxDeliveries(n int) BrokerOption {
+	return func(b *broker) {
+		b.deadLetters.MaxDeliveries = n
+	}
+}
+
+func newBroker(buffer int, logger log.Logger, opts ...BrokerOption) *broker {
 	if logger == nil {
 		logger = log.NewNopLogger()
 	}
-	return &broker{buffer: buffer, subs: make(map[string][]*subscription), logger: logger}
+	b := &broker{buffer: buffer, subs: make(map[string][]*subscription), logger: logger}
+	b.deadLetters = &message.DeadLetterPolicy{Publisher: NewPublisher(b)}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 func (b *broker) Close() error {
@@ -112,9 +130,11 @@ func (s *Subscriber) Close() error {
 }
 
 // helpers to create shared broker + pub/sub
-func NewBroker(buffer int, logger log.Logger) *broker { return newBroker(buffer, logger) }
+func NewBroker(buffer int, logger log.Logger, opts ...BrokerOption) *broker {
+	return newBroker(buffer, logger, opts...)
+}
 
-func NewPublisherAndSubscriber(logger log.Logger) (*Publisher, *Subscriber) {
-	b := newBroker(100, logger) // default buffer size
+func NewPublisherAndSubscriber(logger log.Logger, opts ...BrokerOption) (*Publisher, *Subscriber) {
+	b := newBroker(100, logger, opts...) // default buffer size
 	return NewPublisher(b), NewSubscriber(b)
 }
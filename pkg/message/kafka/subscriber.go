@@ -65,6 +65,9 @@ func NewSubscriber(
 	if len(config.Brokers) == 0 {
 		return nil, errors.New("brokers list is empty")
 	}
+	if config.MaxDeliveries > 0 && config.DeadLetterPublisher == nil {
+		return nil, errors.New("dead-letter publisher is required with max deliveries")
+	}
 
 	sconfig := sarama.NewConfig()
 	{
@@ -113,6 +116,17 @@ type SubscriberConfig struct {
 	// How long about unsuccessful reconnecting next reconnect will occur.
 	ReconnectRetrySleep time.Duration
 
+	// MaxDeliveries is how many times a message is delivered at most before it
+	// is forwarded to the dead-letter topic of its topic and its offset is
+	// committed. Zero redelivers nacked messages forever. Deliveries are
+	// counted in the message metadata, so the count restarts when the
+	// partition is assigned anew.
+	MaxDeliveries int
+
+	// DeadLetterPublisher publishes to the dead-letter topics. Required when
+	// MaxDeliveries is set.
+	DeadLetterPublisher message.Publisher
+
 	InitializeTopicDetails *sarama.TopicDetail
 }
 
@@ -182,7 +196,11 @@ func (s *Subscriber) Subscribe(baseCtx context.Context, topic string) (<-chan *m
 		out:             out,
 		unmarshaler:     s.config.Unmarshaler,
 		nackResendSleep: s.config.NackResendSleep,
-		logger:          logger,
+		deadLetters: &message.DeadLetterPolicy{
+			MaxDeliveries: s.config.MaxDeliveries,
+			Publisher:     s.config.DeadLetterPublisher,
+		},
+		logger: logger,
 	}
 
 	s.wg.Add(1)
@@ -244,6 +262,7 @@ type consumerGroupHandler struct {
 	out             chan *message.Message
 	unmarshaler     Unmarshaler
 	nackResendSleep time.Duration
+	deadLetters     *message.DeadLetterPolicy
 	logger          log.Logger
 }
 
@@ -276,7 +295,7 @@ func (c *consumerGroupHandler) ConsumeClaim(
 
 		msg.SetContext(ctx)
 
-		if err := c.send(ctx, session, msg, logger); err != nil {
+		if err := c.send(ctx, session, claim.Topic(), msg, logger); err != nil {
 			return err
 		}
 		session.MarkMessage(kafkaMsg, "")
@@ -291,6 +310,7 @@ func (c *consumerGroupHandler) ConsumeClaim(
 func (c *consumerGroupHandler) send(
 	msgCtx context.Context,
 	session sarama.ConsumerGroupSession,
+	topic string,
 	msg *message.Message,
 	logger log.Logger,
 ) error {
@@ -313,7 +333,19 @@ func (c *consumerGroupHandler) send(
 			return nil
 		case <-msg.Nacked():
 			// reset acks, etc.
-			msg = msg.Copy()
+			retry, err := c.deadLetters.Nacked(topic, msg)
+			if err != nil {
+				level.Error(logger).Log("msg", "Cannot forward message to dead-letter topic", "err", err)
+			}
+			if retry == nil {
+				level.Warn(logger).Log(
+					"msg", "Message forwarded to dead-letter topic",
+					"message_uuid", msg.UUID,
+					"reason", msg.Metadata.Get(message.FailureReasonKey),
+				)
+				return nil
+			}
+			msg = retry
 			msg.SetContext(msgCtx)
 
 			if c.nackResendSleep != NoSleep {
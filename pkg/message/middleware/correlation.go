@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// CorrelationIDMetadataKey is the metadata key of the correlation ID, which
+// ties the messages published while handling a message to it.
+const CorrelationIDMetadataKey = "correlation_id"
+
+type correlationIDKey struct{}
+
+// MessageCorrelationID returns the correlation ID of msg.
+func MessageCorrelationID(msg *message.Message) string {
+	return msg.Metadata.Get(CorrelationIDMetadataKey)
+}
+
+// SetCorrelationID sets the correlation ID of msg.
+func SetCorrelationID(id string, msg *message.Message) {
+	msg.Metadata.Set(CorrelationIDMetadataKey, id)
+}
+
+// ContextWithCorrelationID returns a copy of ctx carrying id.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID carried by ctx, if any.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// CorrelationID puts the correlation ID of a message, or its UUID when it has
+// none, in the message context. Publishers pass it on to the messages they
+// publish with that context.
+func CorrelationID(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) error {
+		id := MessageCorrelationID(msg)
+		if id == "" {
+			id = msg.UUID
+		}
+		if id != "" {
+			msg.SetContext(ContextWithCorrelationID(msg.Context(), id))
+		}
+		return h(msg)
+	}
+}
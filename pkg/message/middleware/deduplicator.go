@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/yuisofull/goload/pkg/cache"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// Deduplicator skips the messages a handler already handled, by UUID, so that
+// a message published or delivered twice is handled once. Messages without a
+// UUID are always handled.
+type Deduplicator struct {
+	// Seen records the handled messages; a shared cache deduplicates across
+	// replicas.
+	Seen cache.Cache[string, bool]
+	// TTL is how long a handled message is remembered.
+	TTL time.Duration
+}
+
+// Middleware returns the deduplication middleware.
+func (d Deduplicator) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) error {
+		if msg.UUID == "" {
+			return h(msg)
+		}
+		ctx := msg.Context()
+		// The same message may be published to several topics, e.g. to the
+		// worker topic and the shared topic of a control event.
+		key := message.HandlerNameFromCtx(ctx) + "/" + message.SubscribeTopicFromCtx(ctx) + "/" + msg.UUID
+		if seen, err := d.Seen.Has(ctx, key); err == nil && seen {
+			return nil
+		}
+		if err := h(msg); err != nil {
+			return err
+		}
+		_ = d.Seen.Set(ctx, key, true, d.TTL)
+		return nil
+	}
+}
@@ -0,0 +1,3 @@
+// Package middleware provides message.HandlerMiddleware for message.Router
+// handlers.
+package middleware
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// Metrics counts the handled messages and observes how long handling took,
+// in seconds, both labelled with "handler" and "success".
+func Metrics(handled metrics.Counter, duration metrics.Histogram) message.HandlerMiddleware {
+	return func(h message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) error {
+			begin := time.Now()
+			err := h(msg)
+
+			labels := []string{
+				"handler", message.HandlerNameFromCtx(msg.Context()),
+				"success", strconv.FormatBool(err == nil),
+			}
+			handled.With(labels...).Add(1)
+			duration.With(labels...).Observe(time.Since(begin).Seconds())
+			return err
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/pkg/cache/inmem"
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+func TestRetry_RetriesUntilHandled(t *testing.T) {
+	calls := 0
+	h := Retry{MaxRetries: 3, InitialInterval: time.Millisecond}.Middleware(func(msg *message.Message) error {
+		calls++
+		if calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, h(message.NewMessage("1", nil)))
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_SkipsPermanentErrors(t *testing.T) {
+	calls := 0
+	h := Retry{MaxRetries: 3, InitialInterval: time.Millisecond}.Middleware(func(msg *message.Message) error {
+		calls++
+		return message.Permanent(errors.New("malformed"))
+	})
+	require.Error(t, h(message.NewMessage("1", nil)))
+	assert.Equal(t, 1, calls)
+}
+
+func TestTimeout_CancelsHandlerContext(t *testing.T) {
+	h := Timeout(10 * time.Millisecond)(func(msg *message.Message) error {
+		<-msg.Context().Done()
+		return msg.Context().Err()
+	})
+	assert.ErrorIs(t, h(message.NewMessage("1", nil)), context.DeadlineExceeded)
+}
+
+func TestRetry_RetriesTimedOutHandler(t *testing.T) {
+	calls := 0
+	h := Retry{MaxRetries: 3, InitialInterval: time.Millisecond}.Middleware(
+		Timeout(10 * time.Millisecond)(func(msg *message.Message) error {
+			calls++
+			if calls < 3 {
+				<-msg.Context().Done()
+			}
+			return msg.Context().Err()
+		}),
+	)
+	msg := message.NewMessage("1", nil)
+	require.NoError(t, h(msg))
+	assert.Equal(t, 3, calls)
+	assert.NoError(t, msg.Context().Err())
+}
+
+func TestRecoverer_ReturnsPanicAsError(t *testing.T) {
+	h := Recoverer(func(msg *message.Message) error {
+		panic("boom")
+	})
+	err := h(message.NewMessage("1", nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestCorrelationID_PutsIDInContext(t *testing.T) {
+	var got string
+	h := CorrelationID(func(msg *message.Message) error {
+		got = CorrelationIDFromContext(msg.Context())
+		return nil
+	})
+
+	require.NoError(t, h(message.NewMessage("1", nil)))
+	assert.Equal(t, "1", got)
+
+	msg := message.NewMessage("2", nil)
+	SetCorrelationID("request-7", msg)
+	require.NoError(t, h(msg))
+	assert.Equal(t, "request-7", got)
+}
+
+// labelledCounter records the label values counted with.
+type labelledCounter struct {
+	counted *[][]string
+	lvs     []string
+}
+
+func (c labelledCounter) With(lvs ...string) metrics.Counter {
+	return labelledCounter{counted: c.counted, lvs: append(append([]string{}, c.lvs...), lvs...)}
+}
+
+func (c labelledCounter) Add(float64) { *c.counted = append(*c.counted, c.lvs) }
+
+func TestMetrics_CountsHandledMessages(t *testing.T) {
+	var counted [][]string
+	h := Metrics(labelledCounter{counted: &counted}, discard.NewHistogram())(
+		func(msg *message.Message) error {
+			if msg.UUID == "2" {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+	)
+	require.NoError(t, h(message.NewMessage("1", nil)))
+	require.Error(t, h(message.NewMessage("2", nil)))
+	assert.Equal(t, [][]string{
+		{"handler", "", "success", "true"},
+		{"handler", "", "success", "false"},
+	}, counted)
+}
+
+func TestDeduplicator_HandlesMessageOnce(t *testing.T) {
+	seen := inmem.New[string, bool](time.Minute)
+	defer seen.Close()
+
+	calls := 0
+	fail := true
+	h := Deduplicator{Seen: seen, TTL: time.Minute}.Middleware(func(msg *message.Message) error {
+		calls++
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	// A failed message is handled again on redelivery.
+	require.Error(t, h(message.NewMessage("1", nil)))
+	fail = false
+	require.NoError(t, h(message.NewMessage("1", nil)))
+	require.NoError(t, h(message.NewMessage("1", nil)))
+	require.NoError(t, h(message.NewMessage("2", nil)))
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// Recoverer turns a panic of the handler into an error, so that the message
+// is nacked instead of the consumer crashing.
+func Recoverer(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic occurred: %v\n%s", r, debug.Stack())
+			}
+		}()
+		return h(msg)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// Retry handles a message again when the handler fails, up to MaxRetries
+// times, before the message is nacked. Retrying in the handler keeps the
+// delivery count of the message down, so that transient failures do not send
+// it to the dead-letter topic. Permanent errors are not retried.
+type Retry struct {
+	MaxRetries int
+	// InitialInterval is the delay before the first retry; it doubles on
+	// every further retry up to MaxInterval, when set.
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Logger          log.Logger
+}
+
+// Middleware returns the retry middleware.
+func (r Retry) Middleware(h message.HandlerFunc) message.HandlerFunc {
+	return func(msg *message.Message) error {
+		err := h(msg)
+		interval := r.InitialInterval
+		for retry := 1; err != nil && retry <= r.MaxRetries && !message.IsPermanent(err); retry++ {
+			if r.Logger != nil {
+				level.Debug(r.Logger).Log(
+					"msg", "retrying message handler",
+					"handler", message.HandlerNameFromCtx(msg.Context()),
+					"message_uuid", msg.UUID,
+					"retry", retry,
+					"err", err,
+				)
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-msg.Context().Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+			interval *= 2
+			if r.MaxInterval > 0 && interval > r.MaxInterval {
+				interval = r.MaxInterval
+			}
+
+			err = h(msg)
+		}
+		return err
+	}
+}
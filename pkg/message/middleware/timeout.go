@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/yuisofull/goload/pkg/message"
+)
+
+// Timeout cancels the context of the message once the handler ran for d.
+// The previous context is restored when the handler returns, so that
+// middlewares outside Timeout, such as Retry, are not cancelled with it.
+func Timeout(d time.Duration) message.HandlerMiddleware {
+	return func(h message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) error {
+			prev := msg.Context()
+			ctx, cancel := context.WithTimeout(prev, d)
+			defer cancel()
+			msg.SetContext(ctx)
+			defer msg.SetContext(prev)
+			return h(msg)
+		}
+	}
+}
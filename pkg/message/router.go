@@ -0,0 +1,235 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// HandlerFunc handles a message received by a Router. The message is acked
+// when it returns nil and nacked with the error as reason otherwise.
+// Handlers use the context of the message, which is canceled when the
+// subscription ends.
+type HandlerFunc func(msg *Message) error
+
+// HandlerMiddleware wraps a HandlerFunc, e.g. to retry or log it.
+type HandlerMiddleware func(h HandlerFunc) HandlerFunc
+
+// PermanentError wraps an error that handling the message again will not
+// fix, such as a payload that does not decode.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent marks err as permanent.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err was marked permanent.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// JSONHandler returns a HandlerFunc that decodes the JSON payload of a
+// message into a T before calling fn. A payload that does not decode fails
+// the message with a permanent error.
+func JSONHandler[T any](fn func(ctx context.Context, msg *Message, payload T) error) HandlerFunc {
+	return func(msg *Message) error {
+		var payload T
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("cannot decode %T: %w", payload, err))
+		}
+		return fn(msg.Context(), msg, payload)
+	}
+}
+
+type routerContextKey int
+
+const (
+	handlerNameKey routerContextKey = iota
+	subscribeTopicKey
+)
+
+// HandlerNameFromCtx returns the name of the Router handler a message was
+// received by.
+func HandlerNameFromCtx(ctx context.Context) string {
+	name, _ := ctx.Value(handlerNameKey).(string)
+	return name
+}
+
+// SubscribeTopicFromCtx returns the topic a Router handler received a message
+// from.
+func SubscribeTopicFromCtx(ctx context.Context) string {
+	topic, _ := ctx.Value(subscribeTopicKey).(string)
+	return topic
+}
+
+type handler struct {
+	name        string
+	topic       string
+	fn          HandlerFunc
+	middlewares []HandlerMiddleware
+	concurrent  bool
+}
+
+// HandlerOption configures a handler added to a Router.
+type HandlerOption func(*handler)
+
+// WithHandlerMiddleware applies middlewares to the handler only, inside the
+// middlewares of the Router.
+func WithHandlerMiddleware(middlewares ...HandlerMiddleware) HandlerOption {
+	return func(h *handler) {
+		h.middlewares = append(h.middlewares, middlewares...)
+	}
+}
+
+// HandleConcurrently handles every message of the handler in a goroutine of
+// its own, so that a message that takes long to handle does not hold up the
+// next ones. The messages are no longer handled in order.
+func HandleConcurrently() HandlerOption {
+	return func(h *handler) {
+		h.concurrent = true
+	}
+}
+
+// Router subscribes handlers to their topics and acks or nacks each message
+// depending on the outcome of its handler, so that consumers only implement
+// the handling of a message.
+type Router struct {
+	subscriber   Subscriber
+	handlers     []*handler
+	middlewares  []HandlerMiddleware
+	onSubscribed []func(ctx context.Context)
+	logger       log.Logger
+}
+
+// RouterOption configures a Router.
+type RouterOption func(*Router)
+
+// WithRouterLogger configures the router logger.
+func WithRouterLogger(logger log.Logger) RouterOption {
+	return func(r *Router) {
+		if logger != nil {
+			r.logger = logger
+		}
+	}
+}
+
+// NewRouter creates a router receiving the messages of its handlers from
+// subscriber.
+func NewRouter(subscriber Subscriber, opts ...RouterOption) *Router {
+	r := &Router{
+		subscriber: subscriber,
+		logger:     log.NewNopLogger(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Use applies middlewares to all handlers. The first middleware is the
+// outermost one.
+func (r *Router) Use(middlewares ...HandlerMiddleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// AddHandler handles the messages of topic with fn. name identifies the
+// handler in logs and metrics.
+func (r *Router) AddHandler(name, topic string, fn HandlerFunc, opts ...HandlerOption) {
+	h := &handler{name: name, topic: topic, fn: fn}
+	for _, opt := range opts {
+		opt(h)
+	}
+	r.handlers = append(r.handlers, h)
+}
+
+// OnSubscribed calls fn once all handlers are subscribed, before any message
+// is handled.
+func (r *Router) OnSubscribed(fn func(ctx context.Context)) {
+	r.onSubscribed = append(r.onSubscribed, fn)
+}
+
+// Run subscribes the handlers and handles messages until ctx is done. It
+// returns once the messages being handled, concurrently or not, are handled.
+func (r *Router) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chs := make([]<-chan *Message, len(r.handlers))
+	for i, h := range r.handlers {
+		ch, err := r.subscriber.Subscribe(ctx, h.topic)
+		if err != nil {
+			return fmt.Errorf("cannot subscribe handler %s to %s: %w", h.name, h.topic, err)
+		}
+		level.Info(r.logger).Log("msg", "subscription started", "handler", h.name, "topic", h.topic)
+		chs[i] = ch
+	}
+	for _, fn := range r.onSubscribed {
+		fn(ctx)
+	}
+
+	var wg sync.WaitGroup
+	for i, h := range r.handlers {
+		fn := h.fn
+		for j := len(h.middlewares) - 1; j >= 0; j-- {
+			fn = h.middlewares[j](fn)
+		}
+		for j := len(r.middlewares) - 1; j >= 0; j-- {
+			fn = r.middlewares[j](fn)
+		}
+
+		wg.Add(1)
+		go func(h *handler, fn HandlerFunc, ch <-chan *Message) {
+			defer wg.Done()
+			for msg := range ch {
+				if h.concurrent {
+					wg.Add(1)
+					go func(msg *Message) {
+						defer wg.Done()
+						r.handle(h, fn, msg)
+					}(msg)
+					continue
+				}
+				r.handle(h, fn, msg)
+			}
+		}(h, fn, chs[i])
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (r *Router) handle(h *handler, fn HandlerFunc, msg *Message) {
+	ctx := context.WithValue(msg.Context(), handlerNameKey, h.name)
+	ctx = context.WithValue(ctx, subscribeTopicKey, h.topic)
+	msg.SetContext(ctx)
+
+	if err := fn(msg); err != nil {
+		level.Debug(r.logger).Log(
+			"msg", "message handling failed",
+			"handler", h.name,
+			"topic", h.topic,
+			"message_uuid", msg.UUID,
+			"err", err,
+		)
+		msg.NackWithReason(err.Error())
+		return
+	}
+	msg.Ack()
+}
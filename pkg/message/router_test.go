@@ -0,0 +1,167 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/pkg/message"
+	"github.com/yuisofull/goload/pkg/message/inmem"
+)
+
+func runRouter(t *testing.T, r *message.Router) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	subscribed := make(chan struct{})
+	r.OnSubscribed(func(context.Context) { close(subscribed) })
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	select {
+	case <-subscribed:
+	case <-time.After(time.Second):
+		t.Fatal("router did not subscribe")
+	}
+}
+
+func TestRouter_RunWaitsForConcurrentHandlers(t *testing.T) {
+	pub, sub := inmem.NewPublisherAndSubscriber(log.NewNopLogger())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := message.NewRouter(sub)
+	r.AddHandler("h", "topic1", func(msg *message.Message) error {
+		close(started)
+		<-release
+		return nil
+	}, message.HandleConcurrently())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	subscribed := make(chan struct{})
+	r.OnSubscribed(func(context.Context) { close(subscribed) })
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+	<-subscribed
+
+	require.NoError(t, pub.Publish("topic1", message.NewMessage("1", nil)))
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("message not handled")
+	}
+
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("router returned while a handler was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("router did not return")
+	}
+}
+
+func TestRouter_AcksHandledMessages(t *testing.T) {
+	pub, sub := inmem.NewPublisherAndSubscriber(log.NewNopLogger(), inmem.WithMaxDeliveries(2))
+	dead, err := sub.Subscribe(t.Context(), message.DeadLetterTopic("topic1"))
+	require.NoError(t, err)
+
+	type payload struct {
+		Name string `json:"name"`
+	}
+	handled := make(chan string, 1)
+	var deliveries int
+	r := message.NewRouter(sub)
+	r.AddHandler("h", "topic1", message.JSONHandler(func(ctx context.Context, msg *message.Message, p payload) error {
+		assert.Equal(t, "h", message.HandlerNameFromCtx(ctx))
+		assert.Equal(t, "topic1", message.SubscribeTopicFromCtx(ctx))
+		if p.Name == "fail" {
+			deliveries++
+			return errors.New("cannot handle")
+		}
+		handled <- p.Name
+		return nil
+	}))
+	runRouter(t, r)
+
+	require.NoError(t, pub.Publish("topic1", message.NewMessage("1", []byte(`{"name":"ok"}`))))
+	select {
+	case name := <-handled:
+		assert.Equal(t, "ok", name)
+	case <-time.After(time.Second):
+		t.Fatal("message not handled")
+	}
+
+	// A message that keeps failing is nacked until it is dead-lettered.
+	require.NoError(t, pub.Publish("topic1", message.NewMessage("2", []byte(`{"name":"fail"}`))))
+	select {
+	case msg := <-dead:
+		assert.Equal(t, "2", msg.UUID)
+		assert.Equal(t, "cannot handle", msg.Metadata.Get(message.FailureReasonKey))
+		assert.Equal(t, 2, deliveries)
+		msg.Ack()
+	case <-time.After(time.Second):
+		t.Fatal("message not dead-lettered")
+	}
+}
+
+func TestRouter_MiddlewareOrder(t *testing.T) {
+	pub, sub := inmem.NewPublisherAndSubscriber(log.NewNopLogger())
+
+	calls := make(chan string, 4)
+	record := func(name string) message.HandlerMiddleware {
+		return func(h message.HandlerFunc) message.HandlerFunc {
+			return func(msg *message.Message) error {
+				calls <- name
+				return h(msg)
+			}
+		}
+	}
+	r := message.NewRouter(sub)
+	r.Use(record("router1"), record("router2"))
+	r.AddHandler("h", "topic1", func(msg *message.Message) error {
+		calls <- "handler"
+		return nil
+	}, message.WithHandlerMiddleware(record("handler1")))
+	runRouter(t, r)
+
+	msg := message.NewMessage("1", nil)
+	require.NoError(t, pub.Publish("topic1", msg))
+
+	var order []string
+	for range 4 {
+		select {
+		case name := <-calls:
+			order = append(order, name)
+		case <-time.After(time.Second):
+			t.Fatal("message not handled")
+		}
+	}
+	assert.Equal(t, []string{"router1", "router2", "handler1", "handler"}, order)
+}
+
+func TestJSONHandler_UndecodablePayloadIsPermanent(t *testing.T) {
+	h := message.JSONHandler(func(ctx context.Context, msg *message.Message, p struct{}) error {
+		t.Fatal("handler called")
+		return nil
+	})
+	err := h(message.NewMessage("1", []byte("not json")))
+	require.Error(t, err)
+	assert.True(t, message.IsPermanent(err))
+	assert.False(t, message.IsPermanent(errors.New("transient")))
+}
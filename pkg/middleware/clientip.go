@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// ParseTrustedProxies parses addresses and CIDR ranges of trusted proxies,
+// e.g. "10.0.0.0/8" or "192.0.2.10".
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		if strings.Contains(v, "/") {
+			p, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIPHTTPMiddleware resolves the IP address of the client of a request
+// and puts it in the request context, see ClientIPFromContext.
+//
+// X-Forwarded-For is only honored when the request comes from one of the
+// trusted proxies: its hops are then walked from the right, skipping trusted
+// proxies, and the first other address is the client. Otherwise the client
+// is the remote address of the connection.
+func ClientIPHTTPMiddleware(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := resolveClientIP(r, trustedProxies); ip.IsValid() {
+				r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip.String()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIPFromContext returns the client IP resolved by
+// ClientIPHTTPMiddleware.
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(string)
+	return ip, ok
+}
+
+// RemoteIP returns the IP address of the remote end of the connection of r,
+// or the zero Addr when RemoteAddr is not an IP address.
+func RemoteIP(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap().WithZone("")
+}
+
+func resolveClientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	client := RemoteIP(r)
+	if !client.IsValid() || !isTrustedProxy(client, trustedProxies) {
+		return client
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Hops left of a malformed one cannot be attributed to a
+			// trusted proxy.
+			break
+		}
+		client = addr.Unmap().WithZone("")
+		if !isTrustedProxy(client, trustedProxies) {
+			break
+		}
+	}
+	return client
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, p := range trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPHTTPMiddleware(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "no proxy", remoteAddr: "203.0.113.5:4000", want: "203.0.113.5"},
+		{name: "untrusted peer spoofing", remoteAddr: "203.0.113.5:4000", forwarded: "198.51.100.1", want: "203.0.113.5"},
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:4000", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "chain of trusted proxies", remoteAddr: "10.1.2.3:4000", forwarded: "1.1.1.1, 198.51.100.1, 192.0.2.10", want: "198.51.100.1"},
+		{name: "malformed hop", remoteAddr: "10.1.2.3:4000", forwarded: "198.51.100.1, <script>", want: "10.1.2.3"},
+		{name: "ipv6 peer", remoteAddr: "[2001:db8::1]:4000", forwarded: "198.51.100.1", want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := ClientIPHTTPMiddleware(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = ClientIPFromContext(r.Context())
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yuisofull/goload/pkg/oidc"
+	"github.com/yuisofull/goload/pkg/oidc/oidctest"
+)
+
+func newProvider(t *testing.T) *oidc.Provider {
+	t.Helper()
+	issuer, err := oidctest.NewIssuer("goload", "secret")
+	require.NoError(t, err)
+	srv := httptest.NewServer(issuer)
+	t.Cleanup(srv.Close)
+	issuer.URL = srv.URL
+
+	return oidc.NewProvider(oidc.Config{
+		IssuerURL:    srv.URL,
+		ClientID:     "goload",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+	})
+}
+
+// authorize follows the authorization URL like a browser would and returns
+// the query of the redirect back to the client.
+func authorize(t *testing.T, authURL string) url.Values {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL + "&login_hint=bob")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query()
+}
+
+func TestProvider_CodeFlowWithPKCE(t *testing.T) {
+	ctx := context.Background()
+	p := newProvider(t)
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	q := authorize(t, authURL)
+	assert.Equal(t, "state-1", q.Get("state"))
+
+	token, err := p.Exchange(ctx, q.Get("code"), verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "stub|bob", token.Subject)
+	assert.Equal(t, "bob", token.PreferredUsername)
+	assert.Equal(t, "bob@example.com", token.Email)
+	assert.True(t, token.EmailVerified)
+
+	// Codes only work once.
+	_, err = p.Exchange(ctx, q.Get("code"), verifier, "nonce-1")
+	var oidcErr *oidc.ErrorResponse
+	require.ErrorAs(t, err, &oidcErr)
+	assert.Equal(t, "invalid_grant", oidcErr.Code)
+}
+
+func TestProvider_RejectsWrongVerifierAndNonce(t *testing.T) {
+	ctx := context.Background()
+	p := newProvider(t)
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := p.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	other, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	_, err = p.Exchange(ctx, authorize(t, authURL).Get("code"), other, "nonce")
+	var oidcErr *oidc.ErrorResponse
+	require.ErrorAs(t, err, &oidcErr)
+	assert.Equal(t, "invalid_grant", oidcErr.Code)
+
+	_, err = p.Exchange(ctx, authorize(t, authURL).Get("code"), verifier, "another nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
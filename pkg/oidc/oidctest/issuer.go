@@ -0,0 +1,231 @@
+// Package oidctest provides a stub OpenID Connect provider for tests and
+// local development. It signs every user in without asking: the login_hint
+// parameter of the authorization request names the user, "alice" when it is
+// missing.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	pkgrsa "github.com/yuisofull/goload/pkg/crypto/rsa"
+	"github.com/yuisofull/goload/pkg/oidc"
+)
+
+const (
+	keyID         = "stub"
+	codeExpiresIn = time.Minute
+	// TokenExpiresIn is the lifetime of the issued ID tokens.
+	TokenExpiresIn = 5 * time.Minute
+)
+
+type grant struct {
+	user          string
+	nonce         string
+	redirectURI   string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// Issuer is a stub provider. It is an http.Handler serving the discovery
+// document, the authorization, token and JWKS endpoints below URL.
+type Issuer struct {
+	// URL is the issuer identifier and the base URL the handler is served
+	// at. Set it before the first request, for example to the URL of an
+	// httptest.Server running the issuer.
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	key *rsa.PrivateKey
+	mux *http.ServeMux
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+// NewIssuer returns an issuer accepting the given client. An empty secret
+// accepts public clients.
+func NewIssuer(clientID, clientSecret string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	i := &Issuer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		mux:          http.NewServeMux(),
+		grants:       make(map[string]grant),
+	}
+	i.mux.HandleFunc("GET /.well-known/openid-configuration", i.handleDiscovery)
+	i.mux.HandleFunc("GET /authorize", i.handleAuthorize)
+	i.mux.HandleFunc("POST /token", i.handleToken)
+	i.mux.HandleFunc("GET /jwks", i.handleJWKS)
+	return i, nil
+}
+
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.mux.ServeHTTP(w, r)
+}
+
+func (i *Issuer) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                i.URL,
+		"authorization_endpoint":                i.URL + "/authorize",
+		"token_endpoint":                        i.URL + "/token",
+		"jwks_uri":                              i.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+	})
+}
+
+func (i *Issuer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") != i.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	redirect := func(params url.Values) {
+		params.Set("state", q.Get("state"))
+		u := *redirectURI
+		u.RawQuery = params.Encode()
+		http.Redirect(w, r, u.String(), http.StatusFound)
+	}
+	if q.Get("response_type") != "code" {
+		redirect(url.Values{"error": {"unsupported_response_type"}})
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		redirect(url.Values{"error": {"invalid_request"}, "error_description": {"PKCE with S256 is required"}})
+		return
+	}
+
+	user := q.Get("login_hint")
+	if user == "" {
+		user = "alice"
+	}
+	code := randomString()
+	i.mu.Lock()
+	i.grants[code] = grant{
+		user:          user,
+		nonce:         q.Get("nonce"),
+		redirectURI:   redirectURI.String(),
+		codeChallenge: q.Get("code_challenge"),
+		expiresAt:     time.Now().Add(codeExpiresIn),
+	}
+	i.mu.Unlock()
+	redirect(url.Values{"code": {code}})
+}
+
+func (i *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request", err.Error())
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != i.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(i.ClientSecret)) != 1 {
+		tokenError(w, "invalid_client", "")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type", "")
+		return
+	}
+
+	// Codes work once, even when the exchange fails.
+	code := r.PostForm.Get("code")
+	i.mu.Lock()
+	g, ok := i.grants[code]
+	delete(i.grants, code)
+	i.mu.Unlock()
+	switch {
+	case !ok || time.Now().After(g.expiresAt):
+		tokenError(w, "invalid_grant", "unknown or expired code")
+		return
+	case r.PostForm.Get("redirect_uri") != g.redirectURI:
+		tokenError(w, "invalid_grant", "redirect_uri does not match")
+		return
+	case oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.codeChallenge:
+		tokenError(w, "invalid_grant", "code_verifier does not match")
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                i.URL,
+		"sub":                "stub|" + g.user,
+		"aud":                i.ClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(TokenExpiresIn).Unix(),
+		"nonce":              g.nonce,
+		"preferred_username": g.user,
+		"name":               g.user,
+		"email":              g.user + "@example.com",
+		"email_verified":     true,
+	})
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		tokenError(w, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(TokenExpiresIn / time.Second),
+		"id_token":     idToken,
+	})
+}
+
+func (i *Issuer) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	n, e := pkgrsa.JWKParams(&i.key.PublicKey)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{"kty": "RSA", "use": "sig", "alg": "RS256", "kid": keyID, "n": n, "e": e}},
+	})
+}
+
+func tokenError(w http.ResponseWriter, code, description string) {
+	status := http.StatusBadRequest
+	if code == "invalid_client" {
+		status = http.StatusUnauthorized
+	}
+	writeJSON(w, status, oidc.ErrorResponse{Code: code, Description: description})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,11 +1,15 @@
 import axios, { AxiosError, AxiosInstance } from "axios";
 import type {
+  APIKey,
+  CreateAPIKeyRequest,
+  CreateAPIKeyResponse,
   CreateAccountResponse,
   CreateSessionResponse,
   CreateTaskRequest,
   GenerateDownloadURLRequest,
   GenerateDownloadURLResponse,
   GetTaskProgressResponse,
+  ListTasksQuery,
   ListTasksResponse,
   Task,
 } from "./types";
@@ -86,11 +90,45 @@ export const createSession = (account_name: string, password: string) =>
     .post<CreateSessionResponse>("/api/v1/auth/session", { account_name, password })
     .then((r) => r.data);
 
+export const refreshSession = (refresh_token: string) =>
+  api
+    .post<CreateSessionResponse>("/api/v1/auth/refresh", { refresh_token })
+    .then((r) => r.data);
+
+export const logout = () => api.post("/api/v1/auth/logout").then(() => undefined);
+
+export const revokeSessions = (session_id?: string) =>
+  api
+    .post("/api/v1/auth/sessions/revoke", session_id ? { session_id } : {})
+    .then(() => undefined);
+
+// API keys --------------------------------------------------------------
+
+export const createApiKey = (body: CreateAPIKeyRequest) =>
+  api.post<CreateAPIKeyResponse>("/api/v1/api-keys/create", body).then((r) => r.data);
+
+export const listApiKeys = () =>
+  api
+    .get<{ api_keys?: APIKey[] }>("/api/v1/api-keys/list")
+    .then((r) => r.data.api_keys ?? []);
+
+export const revokeApiKey = (id: number) =>
+  api.delete("/api/v1/api-keys/revoke", { params: { id } }).then(() => undefined);
+
 // Tasks -----------------------------------------------------------------
 
-export const listTasks = (offset = 0, limit = 50) =>
+export const listTasks = (offset = 0, limit = 50, query: ListTasksQuery = {}) =>
   api
-    .get<ListTasksResponse>("/api/v1/tasks/list", { params: { offset, limit } })
+    .get<ListTasksResponse>("/api/v1/tasks/list", {
+      params: {
+        offset,
+        limit,
+        ...query,
+        status: query.status?.join(","),
+        tags: query.tags?.join(","),
+        source_type: query.source_type?.join(","),
+      },
+    })
     .then((r) => r.data);
 
 export const createTask = (body: CreateTaskRequest) =>
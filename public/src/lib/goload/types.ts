@@ -37,6 +37,19 @@ export interface AuthAccount {
 export interface ListTasksResponse {
   tasks: Task[];
   total_count: number;
+  next_cursor?: string;
+}
+
+export interface ListTasksQuery {
+  status?: string[];
+  tags?: string[];
+  source_type?: string[];
+  created_from?: string;
+  created_to?: string;
+  search?: string;
+  sort_by?: "created_at" | "updated_at" | "size" | "name";
+  sort_order?: "asc" | "desc";
+  cursor?: string;
 }
 
 export interface CreateTaskRequest {
@@ -51,6 +64,10 @@ export interface CreateTaskRequest {
 export interface CreateSessionResponse {
   token: string;
   account: AuthAccount;
+  /** Lifetime of token in seconds. */
+  expires_in?: number;
+  /** Single-use token for refreshSession; absent when refresh is disabled. */
+  refresh_token?: string;
 }
 
 export interface CreateAccountResponse {
@@ -74,3 +91,27 @@ export interface GenerateDownloadURLResponse {
   url: string;
   direct: boolean;
 }
+
+export type APIKeyScope = "tasks:read" | "tasks:write" | "download";
+
+export interface APIKey {
+  id: number;
+  name: string;
+  prefix: string;
+  scopes: APIKeyScope[];
+  expires_at?: string;
+  created_at: string;
+  revoked_at?: string;
+}
+
+export interface CreateAPIKeyRequest {
+  name: string;
+  scopes: APIKeyScope[];
+  expires_at?: string;
+}
+
+export interface CreateAPIKeyResponse {
+  api_key: APIKey;
+  /** The key itself; it cannot be shown again. */
+  key: string;
+}
@@ -58,4 +58,5 @@ protoc \
 # Generate SQLC code
 print_info "Generating SQLC code..."
 sqlc generate -f configs/auth_svc_sqlc.yaml
-sqlc generate -f configs/task_svc_sqlc.yaml
\ No newline at end of file
+sqlc generate -f configs/task_svc_sqlc.yaml
+sqlc generate -f configs/download_svc_sqlc.yaml